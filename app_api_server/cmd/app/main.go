@@ -1,10 +1,72 @@
 package main
 
 import (
-	"fmt"
+	"context" // context: コンテキスト、待機の制限時間
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"os"      // os: operating system（オペレーティングシステム）、エラー出力と終了コード
+	"time"    // time: 時間操作機能
+
+	"api/internal/database"           // database: データベース接続
+	"api/internal/database/providers" // providers: 認証方式に応じたドライバーのオプション
+	"api/internal/migrations"         // migrations: 起動時のマイグレーションの適用と確認
 )
 
+// databaseWaitTimeout bounds how long startup waits for the database
+// databaseWaitTimeout: 起動時にデータベースを待つ時間の上限
+const databaseWaitTimeout = 2 * time.Minute
+
 func main() {
-	fmt.Println("server start!")
+	os.Exit(run())
+}
+
+// run starts the server and returns the exit code
+// run: サーバーを起動し、終了コードを返す関数
+func run() int {
+	// Load the configuration and wait until the database answers before serving
+	// 設定を読み込み、サービス開始前にデータベースが応答するまで待つ
+	if err := database.LoadDotEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "server: %v\n", err)
+		return 1
+	}
+	config, err := database.LoadDatabaseConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "server: invalid database configuration: %v\n", err)
+		return 1
+	}
+	driverOptions, err := providers.DriverOptions(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "server: %v\n", err)
+		return 1
+	}
+	startup, err := migrations.LoadStartupOptions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "server: %v\n", err)
+		return 1
+	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), databaseWaitTimeout)
+	err = database.WaitForDatabase(ctx, config, database.WaitOptions{DriverOptions: driverOptions})
+	cancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "server: %v\n", err)
+		return 1
+	}
+
+	driver, err := migrations.Connect(config, driverOptions...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "server: %v\n", err)
+		return 1
+	}
+	defer driver.Close()
+
+	// AUTO_MIGRATE applies the embedded migrations; otherwise a schema behind them refuses startup
+	// AUTO_MIGRATEは埋め込みのマイグレーションを適用し、それ以外ではそれより古いスキーマで起動を拒否する
+	logf := func(format string, args ...any) { fmt.Printf("server: "+format+"\n", args...) }
+	if err := migrations.PrepareSchema(context.Background(), driver, migrations.Embedded(), startup, logf); err != nil {
+		fmt.Fprintf(os.Stderr, "server: %v\n", err)
+		return 1
+	}
+
+	fmt.Println("server start!")
+	return 0
 }
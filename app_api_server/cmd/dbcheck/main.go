@@ -0,0 +1,76 @@
+// Command dbcheck diagnoses the database connection the server would open
+// dbcheck: サーバーが開くデータベース接続を診断するコマンド
+// It loads the configuration like the server, prints it redacted, and checks DNS, TCP, TLS and a ping,
+// exiting with status 1 when the database cannot be reached
+// サーバーと同じ方法で設定を読み込んで伏せ字で表示し、DNS、TCP、TLS、pingを確認する
+// データベースに接続できない場合は終了コード1で終了する
+//
+// Usage: dbcheck [--json] [--timeout 30s] [--db-host host] [--db-port port] [--db-user user] [--db-name name] [--sslmode mode]
+package main
+
+import (
+	"context"       // context: コンテキスト、全体の制限時間
+	"encoding/json" // json: --json指定時の出力
+	"flag"          // flag: コマンドライン引数の解析
+	"fmt"           // fmt: format（フォーマット）、文字列フォーマット機能
+	"os"            // os: operating system（オペレーティングシステム）、出力先と終了コード
+	"time"          // time: 時間操作機能
+
+	"api/internal/database"           // database: データベース接続
+	"api/internal/database/providers" // providers: 認証方式に応じたドライバーのオプション
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run executes the check and returns the exit code
+// run: 診断を実行し、終了コードを返す関数
+func run(args []string) int {
+	flags := flag.NewFlagSet("dbcheck", flag.ContinueOnError)
+	jsonOutput := flags.Bool("json", false, "emit the report as JSON for runbooks")            // json output: JSON形式で出力する
+	timeout := flags.Duration("timeout", time.Minute, "overall time limit for all the checks") // timeout: 全体の制限時間
+	dbFlags := database.FlagSet(flags)                                                         // db flags: 環境変数より優先される接続設定
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	// Load the configuration the same way the server does, with the flags taking precedence
+	// サーバーと同じ方法で設定を読み込む、フラグが優先される
+	if err := database.LoadDotEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "dbcheck: %v\n", err)
+		return 1
+	}
+	config, err := dbFlags.Resolve(true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dbcheck: invalid database configuration: %v\n", err)
+		return 1
+	}
+
+	driverOptions, err := providers.DriverOptions(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dbcheck: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	report := database.Diagnose(ctx, config, driverOptions...)
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dbcheck: %v\n", err)
+		return 1
+	}
+
+	if !report.OK {
+		return 1
+	}
+	return 0
+}
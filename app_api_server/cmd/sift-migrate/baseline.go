@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context" // context: コンテキスト
+	"flag"    // flag: コマンドライン引数の解析
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"os"      // os: operating system（オペレーティングシステム）、警告の出力先
+	"strconv" // strconv: string conversion（文字列変換）、バージョンの解析
+	"strings" // strings: 文字列操作
+
+	"api/internal/migrations" // migrations: ベースラインの記録とロック
+)
+
+// baselineCommand records a version in a database whose schema was built before the migrations, without running any SQL
+// baselineCommand: マイグレーション導入前にスキーマを構築したデータベースへ、SQLを実行せずにバージョンを記録するサブコマンド
+// It warns about tables the baselined migrations create that are missing, and refuses a database that already records a
+// version unless --force is passed
+// 取り込むマイグレーションが作成するテーブルが存在しなければ警告し、--forceを指定しない限り既にバージョンを記録したデータベースを拒否する
+func baselineCommand(fs *flag.FlagSet) func(args []string) int {
+	shared := addSharedFlags(fs, true, defaultTimeout)
+	shared.addTarget(fs, false)
+	lockWait := fs.Duration("lock-wait", migrations.DefaultLockWait, migrations.LockWaitUsage)
+	force := fs.Bool("force", false, "overwrite a version the database already records")
+	return func(args []string) int {
+		version, err := parseBaselineVersion(args)
+		if err != nil {
+			return usageError(err)
+		}
+		s, err := shared.resolve()
+		if err != nil {
+			return usageError(err)
+		}
+		list, err := migrations.Load(s.source)
+		if err != nil {
+			return fail(err)
+		}
+		if err := migrations.CheckTarget(list, version); err != nil {
+			return fail(err)
+		}
+		driver, err := s.connect()
+		if err != nil {
+			return fail(err)
+		}
+		defer driver.Close()
+
+		target := s.target(driver)
+		var result migrations.BaselineResult
+		err = migrations.WithLock(target, *lockWait, func() (err error) {
+			result, err = migrations.Baseline(context.Background(), driver, target, s.source, list, version, *force)
+			return err
+		})
+		if err != nil {
+			return fail(err)
+		}
+		if len(result.Missing) > 0 {
+			fmt.Fprintf(os.Stderr, "sift-migrate: warning: tables created by migrations up to version %d are missing: %s\n",
+				version, strings.Join(result.Missing, ", "))
+		}
+		return s.report(result, fmt.Sprintf("baselined at version %d without running any migration", version))
+	}
+}
+
+// parseBaselineVersion reads the version argument, which must name a migration
+// parseBaselineVersion: バージョンの引数を読む関数、マイグレーションを指す必要がある
+func parseBaselineVersion(args []string) (uint, error) {
+	if len(args) == 0 {
+		return 0, fmt.Errorf("missing version: usage: sift-migrate baseline <version>")
+	}
+	if len(args) > 1 {
+		return 0, fmt.Errorf("unexpected arguments: %v", args[1:])
+	}
+	version, err := strconv.ParseUint(args[0], 10, 0)
+	if err != nil || version == 0 {
+		return 0, fmt.Errorf("invalid version %q: expected a positive integer", args[0])
+	}
+	return uint(version), nil
+}
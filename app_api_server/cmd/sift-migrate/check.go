@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context" // context: コンテキスト、読み込みの制限時間
+	"flag"    // flag: コマンドライン引数の解析
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"os"      // os: operating system（オペレーティングシステム）、エラーの表示先
+	"strings" // strings: 文字列操作
+
+	"api/internal/migrations" // migrations: ディレクトリの検証と状態の比較
+)
+
+// exitCheckError is check's exit code when it cannot check, kept apart from the check exit codes
+// exitCheckError: 確認できない場合のcheckの終了コード、確認の終了コードとは区別する
+const exitCheckError = 5
+
+// checkProblem is one problem in the migrations directory of a target
+// checkProblem: 1つのターゲットのマイグレーションのディレクトリの問題
+type checkProblem struct {
+	Target string `json:"target"` // target: ターゲット名
+	migrations.Problem
+}
+
+// checkTarget is how one target's database compares with its migrations
+// checkTarget: 1つのターゲットのデータベースとマイグレーションの比較結果
+type checkTarget struct {
+	Target   string `json:"target"`   // target: ターゲット名
+	Version  uint   `json:"version"`  // version: データベースのバージョン
+	Dirty    bool   `json:"dirty"`    // dirty: データベースがdirtyか
+	Pending  []uint `json:"pending"`  // pending: 未適用のバージョン
+	Modified []uint `json:"modified"` // modified: 適用後にファイルが変更されたバージョン
+}
+
+// checkResult is check's --json output
+// checkResult: checkの--jsonの出力
+type checkResult struct {
+	SourceOnly bool           `json:"source_only"`       // source only: ディレクトリのみを検証したか
+	Problems   []checkProblem `json:"problems"`          // problems: ディレクトリの問題
+	Targets    []checkTarget  `json:"targets,omitempty"` // targets: データベースとの比較、問題がある場合と--source-onlyでは空
+	ExitCode   int            `json:"exit_code"`         // exit code: 終了コード
+}
+
+// checkCommand gates CI: it validates the migrations directory of every declared target unless --target picks one,
+// then compares each with the database unless --source-only is passed
+// checkCommand: CIで判定に使うサブコマンド、--targetで選ばない限り宣言された全てのターゲットのマイグレーションのディレクトリを検証し、
+// --source-onlyを指定しない限り、続けて各ターゲットをデータベースと比較する
+// Exit codes: 0 ok, 1 pending migrations, 2 dirty database, 3 migrations modified after apply, 4 problems in the
+// directory, 5 when it cannot check; with several findings the highest counts
+// 終了コード: 0 問題なし、1 未適用のマイグレーションあり、2 データベースがdirty、3 適用後に変更されたマイグレーションあり、
+// 4 ディレクトリに問題あり、5 確認できない。複数該当する場合は最も大きいものを使う
+func checkCommand(fs *flag.FlagSet) func(args []string) int {
+	shared := addSharedFlags(fs, true, defaultTimeout)
+	shared.addTarget(fs, false)
+	sourceOnly := fs.Bool("source-only", false, "validate the migrations directory without connecting to the database")
+	return func(args []string) int {
+		if len(args) > 0 {
+			return checkError(fmt.Errorf("unexpected arguments: %v", args))
+		}
+		// --source-only resolves like a command that does not connect, so no database configuration is needed
+		// --source-onlyは接続しないコマンドと同じく解決するため、データベースの設定は不要
+		if *sourceOnly {
+			shared.db = nil
+		}
+		s, err := shared.resolve()
+		if err != nil {
+			return checkError(err)
+		}
+		targets := s.targets
+		if *shared.target == "" {
+			targets = s.declared
+		}
+
+		result := checkResult{SourceOnly: *sourceOnly, Problems: []checkProblem{}}
+		var text []string
+		for _, target := range targets {
+			problems, err := migrations.CheckSource(target.Source)
+			if err != nil {
+				return checkError(fmt.Errorf("target %s: %w", target.Name, err))
+			}
+			for _, problem := range problems {
+				result.Problems = append(result.Problems, checkProblem{Target: target.Name, Problem: problem})
+				text = append(text, targetPrefix(targets, target)+problem.String())
+			}
+		}
+		if len(result.Problems) > 0 {
+			result.ExitCode = migrations.ExitInvalid
+			text = append(text, fmt.Sprintf("%d problem(s) in the migrations", len(result.Problems)))
+			return checkReport(s, result, text)
+		}
+		if *sourceOnly {
+			for _, target := range targets {
+				text = append(text, fmt.Sprintf("%s%s is valid", targetPrefix(targets, target), target.Source))
+			}
+			return checkReport(s, result, text)
+		}
+
+		driver, err := s.connect()
+		if err != nil {
+			return checkError(err)
+		}
+		defer driver.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		defer cancel()
+		for _, target := range targets {
+			status, err := readTargetStatus(ctx, s.base(driver), target)
+			if err != nil {
+				return checkError(fmt.Errorf("target %s: %w", target.Name, err))
+			}
+			result.Targets = append(result.Targets, checkTarget{
+				Target:   target.Name,
+				Version:  status.Version,
+				Dirty:    status.Dirty,
+				Pending:  status.Versions(migrations.StatePending),
+				Modified: status.Versions(migrations.StateModified),
+			})
+			result.ExitCode = max(result.ExitCode, status.CheckCode())
+			text = append(text, targetPrefix(targets, target)+status.Summary())
+		}
+		return checkReport(s, result, text)
+	}
+}
+
+// targetPrefix names target at the start of a line when there are several targets
+// targetPrefix: 複数のターゲットがある場合に、行の先頭でtargetの名前を示す関数
+func targetPrefix(targets []migrations.Target, target migrations.Target) string {
+	if len(targets) == 1 {
+		return ""
+	}
+	return "target " + target.Name + ": "
+}
+
+// checkReport prints result and returns its exit code, or exitCheckError when it cannot be printed
+// checkReport: resultを表示してその終了コードを返す関数、表示できない場合はexitCheckError
+func checkReport(s *session, result checkResult, text []string) int {
+	if s.report(result, strings.Join(text, "\n")) != 0 {
+		return exitCheckError
+	}
+	return result.ExitCode
+}
+
+// checkError prints err and returns exitCheckError
+// checkError: errを表示し、exitCheckErrorを返す関数
+func checkError(err error) int {
+	fmt.Fprintf(os.Stderr, "sift-migrate: %v\n", err)
+	return exitCheckError
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"    // flag: コマンドライン引数の解析
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"strings" // strings: 文字列操作
+)
+
+// bashCompletion completes the subcommand, then the flags of the subcommand already typed
+// bashCompletion: サブコマンドを補完し、入力済みのサブコマンドのフラグを補完するbashのスクリプト
+const bashCompletion = `# bash completion for sift-migrate: eval "$(sift-migrate completion bash)"
+_sift_migrate() {
+	local cur=${COMP_WORDS[COMP_CWORD]}
+	if [ "$COMP_CWORD" -eq 1 ]; then
+		COMPREPLY=($(compgen -W "%s" -- "$cur"))
+		return
+	fi
+	case ${COMP_WORDS[1]} in
+%s	esac
+}
+complete -F _sift_migrate sift-migrate
+`
+
+// completionCommand prints a completion script for bash, or for zsh through its bash compatibility
+// completionCommand: bash、またはbash互換機能を使うzshの補完スクリプトを表示するサブコマンド
+func completionCommand(fs *flag.FlagSet) func(args []string) int {
+	return func(args []string) int {
+		if len(args) != 1 {
+			return usageError(fmt.Errorf("usage: sift-migrate completion bash|zsh"))
+		}
+		switch args[0] {
+		case "bash":
+			fmt.Print(completionScript())
+		case "zsh":
+			fmt.Print("autoload -U +X bashcompinit && bashcompinit\n" + completionScript())
+		default:
+			return usageError(fmt.Errorf("unsupported shell %q: expected bash or zsh", args[0]))
+		}
+		return 0
+	}
+}
+
+// completionScript builds the bash script from the subcommands and the flags each one registers
+// completionScript: サブコマンドと各サブコマンドが登録するフラグからbashのスクリプトを組み立てる関数
+func completionScript() string {
+	var names []string
+	var cases strings.Builder
+	for _, c := range commands() {
+		names = append(names, c.name)
+		fs := newFlagSet(c)
+		c.setup(fs)
+		var flags []string
+		fs.VisitAll(func(f *flag.Flag) { flags = append(flags, "--"+f.Name) })
+		if len(flags) > 0 {
+			fmt.Fprintf(&cases, "\t%s) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")) ;;\n", c.name, strings.Join(flags, " "))
+		}
+	}
+	return fmt.Sprintf(bashCompletion, strings.Join(names, " "), cases.String())
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"    // flag: コマンドライン引数の解析
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"strings" // strings: 文字列操作
+
+	"api/internal/migrations" // migrations: マイグレーションファイルの生成
+)
+
+// createResult is create's --json output
+// createResult: createの--jsonの出力
+type createResult struct {
+	Files []string `json:"files"` // files: 作成したファイル
+}
+
+// createCommand generates a new pair of up and down migration files
+// createCommand: 新しいupとdownのマイグレーションファイルの組を生成するサブコマンド
+// The name is slugified and prefixed with a UTC timestamp (or the next sequential number with --numbering sequential);
+// a name or version the directory already has is refused. --template create_table --table name fills the files with
+// a table skeleton in the app schema
+// 名前はスラッグ化し、UTCのタイムスタンプ（--numbering sequentialの場合は次の連番）を前に付ける。ディレクトリに
+// 既にある名前やバージョンは拒否する。--template create_table --table nameでappスキーマのテーブルの雛形を書き込む
+func createCommand(fs *flag.FlagSet) func(args []string) int {
+	shared := addSharedFlags(fs, false, 0)
+	// Files are written to a directory, never to the embedded migrations: ファイルはディレクトリに書き込み、埋め込みには書き込まない
+	fs.Lookup("migrations-path").Usage = "directory to write to (default $MIGRATIONS_PATH, then ./" + migrations.DefaultPath + ")"
+	numbering := fs.String("numbering", string(migrations.NumberingTimestamp), "version numbering: timestamp or sequential")
+	templateName := fs.String("template", "", "fill the files from a template: "+strings.Join(migrations.TemplateNames(), ", "))
+	table := fs.String("table", "", "table name for --template create_table")
+	return func(args []string) int {
+		if len(args) == 0 {
+			return usageError(fmt.Errorf("missing name: usage: sift-migrate create [flags] <name>"))
+		}
+		if len(args) > 1 {
+			return usageError(fmt.Errorf("unexpected arguments: %v; quote a name with spaces", args[1:]))
+		}
+		if err := migrations.LoadEnv(*shared.envFile); err != nil {
+			return usageError(err)
+		}
+		dir, err := migrations.ResolvePath(*shared.migrationsPath)
+		if err != nil {
+			return usageError(err)
+		}
+
+		paths, err := migrations.Create(migrations.OSFileSystem{}, dir, migrations.CreateOptions{
+			Name:      args[0],
+			Numbering: migrations.Numbering(*numbering),
+			Template:  *templateName,
+			Table:     *table,
+		})
+		if err != nil {
+			for _, path := range paths {
+				fmt.Printf("created %s\n", path)
+			}
+			return fail(err)
+		}
+		s := &session{json: *shared.json}
+		var text []string
+		for _, path := range paths {
+			text = append(text, "created "+path)
+		}
+		return s.report(createResult{Files: paths}, strings.Join(text, "\n"))
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors" // errors: エラー操作
+	"flag"   // flag: コマンドライン引数の解析
+	"fmt"    // fmt: format（フォーマット）、文字列フォーマット機能
+
+	"api/internal/migrations" // migrations: マイグレーションの取り消しとロック
+)
+
+// downCommand reverts the newest applied migrations, one by default
+// downCommand: 新しい方から適用済みのマイグレーションを元に戻すサブコマンド、デフォルトは1つ
+// Reverting loses data, so it asks on a terminal and otherwise needs --confirm
+// 元に戻すとデータが失われるため、端末では確認を求め、それ以外では--confirmが必要
+func downCommand(fs *flag.FlagSet) func(args []string) int {
+	shared := addSharedFlags(fs, true, defaultTimeout)
+	shared.addTarget(fs, false)
+	shared.addTimeouts(fs)
+	lockWait := fs.Duration("lock-wait", migrations.DefaultLockWait, migrations.LockWaitUsage)
+	confirm := fs.Bool("confirm", false, "confirm reverting migrations without a prompt")
+	steps := &positiveInt{value: 1}
+	fs.Var(steps, "steps", "revert the newest `N` migrations")
+	all := fs.Bool("all", false, "revert every applied migration")
+	return func(args []string) int {
+		n, err := parseSteps(args, steps, *all)
+		if err != nil {
+			return usageError(err)
+		}
+		s, err := shared.resolve()
+		if err != nil {
+			return usageError(err)
+		}
+		list, err := migrations.Load(s.source)
+		if err != nil {
+			return fail(err)
+		}
+		driver, err := s.connect()
+		if err != nil {
+			return fail(err)
+		}
+		defer driver.Close()
+
+		target := s.target(driver)
+		var plan migrations.Plan
+		err = migrations.WithLock(target, *lockWait, func() (err error) {
+			plan, err = migrations.Down(target, s.source, list, n, s.approveDown(*confirm))
+			return err
+		})
+		if err != nil {
+			return fail(err)
+		}
+		if plan.Direction == migrations.DirectionNone {
+			return s.report(plan, "nothing to revert: no migration is applied")
+		}
+		result := stepsResult{Plan: plan, Requested: n}
+		return s.report(result, describeSteps(result))
+	}
+}
+
+// parseSteps checks down's arguments and returns the number of migrations to revert, 0 for --all
+// parseSteps: downの引数を確認し、元に戻すマイグレーションの数を返す関数、--allの場合は0
+func parseSteps(args []string, steps *positiveInt, all bool) (int, error) {
+	switch {
+	case len(args) > 0:
+		return 0, fmt.Errorf("unexpected arguments: %v", args)
+	case all && steps.set:
+		return 0, errors.New("pass either --steps or --all, not both")
+	case all:
+		return 0, nil
+	}
+	return steps.value, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"    // flag: コマンドライン引数の解析
+	"strings" // strings: 文字列操作
+	"testing" // testing: テスト機能
+
+	"api/internal/migrations" // migrations: 計画とマイグレーション
+)
+
+// TestParseSteps tests down's --steps, its default and --all
+// TestParseSteps: downの--steps、そのデフォルト、--allをテストする関数
+func TestParseSteps(t *testing.T) {
+	testCases := []struct {
+		name         string
+		args         []string
+		expected     int
+		expectError  bool
+		errorContent string
+	}{
+		{name: "One by default", expected: 1},
+		{name: "Steps", args: []string{"--steps", "3"}, expected: 3},
+		{name: "All", args: []string{"--all"}, expected: 0},
+		{name: "Steps and all", args: []string{"--steps", "2", "--all"}, expectError: true, errorContent: "not both"},
+		{name: "Zero steps", args: []string{"--steps", "0"}, expectError: true, errorContent: "expected a positive integer"},
+		{name: "Not a number", args: []string{"--steps", "all"}, expectError: true, errorContent: "expected a positive integer"},
+		{name: "Positional steps", args: []string{"2"}, expectError: true, errorContent: "unexpected arguments: [2]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(&strings.Builder{})
+			steps := &positiveInt{value: 1}
+			fs.Var(steps, "steps", "")
+			all := fs.Bool("all", false, "")
+			positional, err := parseInterspersed(fs, tc.args)
+			n := 0
+			if err == nil {
+				n, err = parseSteps(positional, steps, *all)
+			}
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				return
+			}
+			if err != nil || n != tc.expected {
+				t.Errorf("Expected %d, got: %d, %v", tc.expected, n, err)
+			}
+		})
+	}
+}
+
+// TestDescribeSteps tests the versions listed after a move and the note when fewer than the steps remained
+// TestDescribeSteps: 移動後に列挙するバージョンと、ステップ数より少なく残っていた場合の注記をテストする関数
+func TestDescribeSteps(t *testing.T) {
+	list, err := migrations.Load(migrations.Directory("../../internal/migrations/testdata/gaps"))
+	if err != nil {
+		t.Fatalf("Failed to load fixture: %v", err)
+	}
+	up, err := migrations.UpPlan(list, 3, 5)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	down, err := migrations.DownPlan(list, 8, 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		result   stepsResult
+		expected string
+	}{
+		{name: "Fewer than the steps remained", result: stepsResult{Plan: up, Requested: 5},
+			expected: "applied 5 add orders status\napplied 8 create invoices\nstopped after 2 of 5 steps: no more migrations to apply\nnow at version 8"},
+		{name: "Exact steps", result: stepsResult{Plan: down, Requested: 1}, expected: "reverted 8 create invoices\nnow at version 5"},
+		{name: "All", result: stepsResult{Plan: down}, expected: "reverted 8 create invoices\nnow at version 5"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := describeSteps(tc.result); got != tc.expected {
+				t.Errorf("Expected %q, got: %q", tc.expected, got)
+			}
+		})
+	}
+}
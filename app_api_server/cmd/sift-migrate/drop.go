@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag" // flag: コマンドライン引数の解析
+	"fmt"  // fmt: format（フォーマット）、文字列フォーマット機能
+	"io"   // io: 入出力、確認の入力と表示
+	"os"   // os: operating system（オペレーティングシステム）、環境変数と入出力
+
+	"api/internal/migrations" // migrations: 削除と再作成
+)
+
+// dropResult is drop's --json output
+// dropResult: dropの--jsonの出力
+type dropResult struct {
+	Database  string `json:"database"`          // database: 削除したデータベース
+	Recreated bool   `json:"recreated"`         // recreated: 再作成したか
+	Version   uint   `json:"version,omitempty"` // version: 再作成後のバージョン
+}
+
+// dropCommand drops every table in the database's schema so test suites can start from nothing
+// dropCommand: テストスイートを空の状態から始められるよう、データベースのスキーマの全てのテーブルを削除するサブコマンド
+// It runs only when APP_ENV is test or development unless --i-know-what-im-doing is passed, prints the target host and
+// database first, and on a terminal asks for the database name to be typed. --recreate runs every migration again
+// under the same lock
+// --i-know-what-im-doingを指定しない限りAPP_ENVがtestかdevelopmentの場合のみ実行し、最初に対象のホストとデータベースを表示し、
+// 端末ではデータベース名の入力を求める。--recreateを指定すると、同じロックの下で全てのマイグレーションを再び実行する
+func dropCommand(fs *flag.FlagSet) func(args []string) int {
+	shared := addSharedFlags(fs, true, defaultTimeout)
+	shared.addTimeouts(fs)
+	lockWait := fs.Duration("lock-wait", migrations.DefaultLockWait, migrations.LockWaitUsage)
+	override := fs.Bool("i-know-what-im-doing", false, "drop even when APP_ENV is not test or development")
+	recreate := fs.Bool("recreate", false, "run every migration again after dropping")
+	return func(args []string) int {
+		if len(args) > 0 {
+			return usageError(fmt.Errorf("unexpected arguments: %v", args))
+		}
+		s, err := shared.resolve()
+		if err != nil {
+			return usageError(err)
+		}
+		// APP_ENV is read after resolve, which loads the env file
+		// APP_ENVはenvファイルを読み込むresolveの後に読む
+		if err := guard(s, *override, os.Getenv("APP_ENV"), migrations.Interactive(os.Stdin), os.Stdin, s.prompts()); err != nil {
+			return fail(err)
+		}
+		driver, err := s.connect()
+		if err != nil {
+			return fail(err)
+		}
+		defer driver.Close()
+
+		target := s.target(driver)
+		result := dropResult{Database: s.config.Database, Recreated: *recreate}
+		err = migrations.WithLock(target, *lockWait, func() error {
+			if err := migrations.Drop(target, s.source); err != nil {
+				return err
+			}
+			if !*recreate {
+				return nil
+			}
+			_, after, err := migrations.Up(target, s.source, false)
+			result.Version = after
+			return err
+		})
+		if err != nil {
+			return fail(err)
+		}
+		text := fmt.Sprintf("dropped every table in %s", result.Database)
+		if result.Recreated {
+			text += fmt.Sprintf("\nrecreated the schema at version %d", result.Version)
+		}
+		return s.report(result, text)
+	}
+}
+
+// guard checks APP_ENV, prints the target, and on a terminal asks for the database name to be typed
+// guard: APP_ENVを確認し、対象を表示し、端末ではデータベース名の入力を求める関数
+func guard(s *session, override bool, appEnv string, interactive bool, in io.Reader, out io.Writer) error {
+	if err := migrations.CheckDropAllowed(appEnv, override); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "about to drop every table in database %s on host %s (port %d)\n",
+		s.config.Database, s.config.Host, s.config.Port)
+	if !interactive {
+		return nil
+	}
+
+	ok, err := migrations.Confirm(in, out, fmt.Sprintf("Type the database name (%s) to continue: ", s.config.Database), s.config.Database)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("the answer did not match %s; nothing was dropped", s.config.Database)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings" // strings: 文字列操作
+	"testing" // testing: テスト機能
+
+	"api/internal/database" // database: データベース設定
+)
+
+// TestGuard tests every APP_ENV, override and terminal combination without dropping anything
+// TestGuard: 何も削除せずに、APP_ENV、上書き、端末の各組み合わせをテストする関数
+func TestGuard(t *testing.T) {
+	environments := []struct {
+		appEnv  string
+		allowed bool
+	}{
+		{appEnv: "", allowed: false},
+		{appEnv: "test", allowed: true},
+		{appEnv: "Development", allowed: true},
+		{appEnv: "staging", allowed: false},
+		{appEnv: "production", allowed: false},
+	}
+	terminals := []struct {
+		name        string
+		interactive bool
+		answer      string
+		confirms    bool
+	}{
+		{name: "not a terminal", interactive: false, confirms: true},
+		{name: "terminal typing the name", interactive: true, answer: "sift_test_db\n", confirms: true},
+		{name: "terminal typing yes", interactive: true, answer: "yes\n", confirms: false},
+		{name: "terminal without an answer", interactive: true, confirms: false},
+	}
+
+	for _, env := range environments {
+		for _, override := range []bool{false, true} {
+			for _, terminal := range terminals {
+				name := "APP_ENV=" + env.appEnv + "/override=" + map[bool]string{false: "no", true: "yes"}[override] + "/" + terminal.name
+				t.Run(name, func(t *testing.T) {
+					s := &session{config: &database.DatabaseConfig{Host: "db.internal", Port: 5432, Database: "sift_test_db"}}
+					var out strings.Builder
+					err := guard(s, override, env.appEnv, terminal.interactive, strings.NewReader(terminal.answer), &out)
+
+					switch {
+					case !env.allowed && !override:
+						if err == nil || !strings.Contains(err.Error(), "--i-know-what-im-doing") {
+							t.Errorf("Expected the APP_ENV refusal, got: %v", err)
+						}
+						if out.Len() > 0 {
+							t.Errorf("Expected nothing printed before the refusal, got: %q", out.String())
+						}
+						return
+					case !terminal.confirms:
+						if err == nil || !strings.Contains(err.Error(), "nothing was dropped") {
+							t.Errorf("Expected the confirmation to fail, got: %v", err)
+						}
+					default:
+						if err != nil {
+							t.Errorf("Expected no error, got: %v", err)
+						}
+					}
+					if !strings.Contains(out.String(), "database sift_test_db on host db.internal (port 5432)") {
+						t.Errorf("Expected the target to be printed, got: %q", out.String())
+					}
+				})
+			}
+		}
+	}
+}
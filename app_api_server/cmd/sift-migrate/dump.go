@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context" // context: コンテキスト、読み込みの制限時間
+	"flag"    // flag: コマンドライン引数の解析
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"os"      // os: operating system（オペレーティングシステム）、ダンプの読み書き
+
+	"api/internal/migrations" // migrations: スキーマのダンプと比較
+)
+
+// dumpResult is dump's --json output
+// dumpResult: dumpの--jsonの出力
+type dumpResult struct {
+	Schema  string `json:"schema"`  // schema: ダンプしたスキーマ
+	Out     string `json:"out"`     // out: 書き込んだ、または比較したファイル
+	Checked bool   `json:"checked"` // checked: --checkで比較のみ行ったか
+}
+
+// dumpCommand writes the DDL of the app schema to --out, headed by the schema_migrations version, for committing
+// dumpCommand: コミット用に、appスキーマのDDLをschema_migrationsのバージョンを先頭に付けて--outに書き込むサブコマンド
+// --check writes nothing and exits 1 when --out differs from the database, so CI can require the committed dump to
+// match the migrations
+// --checkは何も書き込まず、--outがデータベースと異なる場合は1で終了する。CIでコミットしたダンプがマイグレーションと
+// 一致することを確認できる
+func dumpCommand(fs *flag.FlagSet) func(args []string) int {
+	shared := addSharedFlags(fs, true, defaultTimeout)
+	out := fs.String("out", migrations.DefaultDumpPath, "file to write the dump to, or - for stdout")
+	schema := fs.String("schema", migrations.DefaultSchema, "schema to dump")
+	check := fs.Bool("check", false, "compare the dump with --out instead of writing it, failing when they differ")
+	return func(args []string) int {
+		if len(args) > 0 {
+			return usageError(fmt.Errorf("unexpected arguments: %v", args))
+		}
+		if *out == "-" && (*check || *shared.json) {
+			return usageError(fmt.Errorf("--out - cannot be combined with --check or --json"))
+		}
+		s, err := shared.resolve()
+		if err != nil {
+			return usageError(err)
+		}
+		driver, err := s.connect()
+		if err != nil {
+			return fail(err)
+		}
+		defer driver.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		defer cancel()
+		dump, err := migrations.Dump(ctx, driver, *schema)
+		if err != nil {
+			return fail(err)
+		}
+		result := dumpResult{Schema: *schema, Out: *out, Checked: *check}
+		switch {
+		case *out == "-":
+			if _, err := os.Stdout.Write(dump); err != nil {
+				return fail(err)
+			}
+			return 0
+		case *check:
+			committed, err := os.ReadFile(*out)
+			if err != nil {
+				return fail(fmt.Errorf("%w; run sift-migrate dump to create it", err))
+			}
+			if err := migrations.CheckDump(committed, dump); err != nil {
+				return fail(fmt.Errorf("%s: %w; run sift-migrate dump and commit the result", *out, err))
+			}
+			return s.report(result, fmt.Sprintf("%s matches schema %s", *out, *schema))
+		}
+		if err := os.WriteFile(*out, dump, 0o644); err != nil {
+			return fail(err)
+		}
+		return s.report(result, fmt.Sprintf("dumped schema %s to %s", *schema, *out))
+	}
+}
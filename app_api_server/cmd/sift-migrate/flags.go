@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"       // context: コンテキスト、待機の制限時間
+	"encoding/json" // json: --json指定時の出力
+	"errors"        // errors: エラー操作
+	"flag"          // flag: コマンドライン引数の解析
+	"fmt"           // fmt: format（フォーマット）、文字列フォーマット機能
+	"io"            // io: 入出力、確認の表示先
+	"log/slog"      // slog: マイグレーションのログの出力
+	"os"            // os: operating system（オペレーティングシステム）、入出力
+	"slices"        // slices: スライス操作
+	"strconv"       // strconv: string conversion（文字列変換）、ステップ数の解析
+	"strings"       // strings: 文字列操作
+	"time"          // time: 時間操作機能
+
+	"api/internal/database"           // database: データベース接続
+	"api/internal/database/providers" // providers: 認証方式に応じたドライバーのオプション
+	"api/internal/migrations"         // migrations: 設定の読み込みとマイグレーションの読み込み元
+)
+
+// defaultTimeout bounds how long a subcommand waits for the database, unless the subcommand sets its own default
+// defaultTimeout: サブコマンドが独自のデフォルトを持たない場合に、データベースを待つ時間の上限
+const defaultTimeout = 30 * time.Second
+
+// errNotConfirmed is returned when reverting migrations was not confirmed
+// errNotConfirmed: マイグレーションの取り消しが確認されなかった場合のエラー
+var errNotConfirmed = errors.New("moving down reverts migrations and may lose data; rerun with --confirm")
+
+// sharedFlags are the flags every subcommand parses the same way
+// sharedFlags: 全てのサブコマンドが同じ方法で解析するフラグ
+type sharedFlags struct {
+	envFile        *string                       // env file: 読み込む.envファイル
+	migrationsPath *string                       // migrations path: マイグレーションのディレクトリ
+	json           *bool                         // json: JSON形式で出力する
+	timeout        *time.Duration                // timeout: データベースを待つ時間の上限、接続しないコマンドではnil
+	db             *database.DatabaseConfigFlags // db: 環境変数より優先される接続設定、接続しないコマンドではnil
+	verbose        *bool                         // verbose: golang-migrateの詳細なログを出す、接続しないコマンドではnil
+	timeouts       *timeoutFlags                 // timeouts: マイグレーションのタイムアウト、マイグレーションを実行しないコマンドではnil
+	target         *string                       // target: 対象のターゲット、ターゲットを選ばないコマンドではnil
+	all            *bool                         // all: 全てのターゲットを対象にする、--allのないコマンドではnil
+}
+
+// timeoutFlags are --lock-timeout and --statement-timeout, which override MIGRATE_LOCK_TIMEOUT and MIGRATE_STATEMENT_TIMEOUT
+// timeoutFlags: MIGRATE_LOCK_TIMEOUTとMIGRATE_STATEMENT_TIMEOUTより優先される--lock-timeoutと--statement-timeout
+type timeoutFlags struct {
+	lock      durationFlag // lock: --lock-timeout
+	statement durationFlag // statement: --statement-timeout
+}
+
+// addSharedFlags registers the shared flags on fs; connects adds --timeout, with timeout as its default, and the --db-* flags
+// addSharedFlags: fsに共有のフラグを登録する関数、connectsの場合はtimeoutをデフォルトとする--timeoutと--db-*フラグも登録する
+func addSharedFlags(fs *flag.FlagSet, connects bool, timeout time.Duration) *sharedFlags {
+	shared := &sharedFlags{
+		envFile:        fs.String("env-file", "", "env file to load (default $DB_ENV_FILE, then .env)"),
+		migrationsPath: fs.String("migrations-path", "", migrations.PathUsage),
+		json:           fs.Bool("json", false, "print the result as JSON"),
+	}
+	if connects {
+		shared.timeout = fs.Duration("timeout", timeout, "how long to wait for the database")
+		shared.db = database.FlagSet(fs)
+		shared.verbose = fs.Bool("verbose", false, "log golang-migrate's debug output as well as each migration's time")
+	}
+	return shared
+}
+
+// addTimeouts registers --lock-timeout and --statement-timeout for a command that runs migrations
+// addTimeouts: マイグレーションを実行するコマンドに--lock-timeoutと--statement-timeoutを登録する関数
+func (f *sharedFlags) addTimeouts(fs *flag.FlagSet) {
+	f.timeouts = &timeoutFlags{}
+	fs.Var(&f.timeouts.lock, "lock-timeout", migrations.LockTimeoutUsage)
+	fs.Var(&f.timeouts.statement, "statement-timeout", migrations.StatementTimeoutUsage)
+}
+
+// addTarget registers --target for a command that works on one migration target, and --all when withAll is set
+// addTarget: 1つのマイグレーションのターゲットを扱うコマンドに--target、withAllの場合は--allも登録する関数
+func (f *sharedFlags) addTarget(fs *flag.FlagSet, withAll bool) {
+	f.target = fs.String("target", "", migrations.TargetUsage)
+	if withAll {
+		f.all = fs.Bool("all", false, "apply every target in the order MIGRATION_TARGETS declares them, after "+migrations.DefaultTarget)
+	}
+}
+
+// session is what the shared flags resolve to
+// session: 共有のフラグを解決した結果
+type session struct {
+	config   *database.DatabaseConfig // config: データベース設定、接続しないコマンドではnil
+	source   migrations.Source        // source: 最初の選んだターゲットのマイグレーションファイルの読み込み元
+	targets  []migrations.Target      // targets: --targetまたは--allで選んだターゲット、デフォルトはappのみ
+	declared []migrations.Target      // declared: 宣言された全てのターゲット
+	timeout  time.Duration            // timeout: データベースを待つ時間の上限
+	timeouts migrations.Timeouts      // timeouts: マイグレーションを実行する接続のタイムアウト
+	json     bool                     // json: JSON形式で出力する
+	logger   database.Logger          // logger: 標準エラー出力へのログ、接続しないコマンドではnil
+	verbose  bool                     // verbose: golang-migrateの詳細なログを出す
+}
+
+// resolve loads the env file, then the connection configuration and the migrations source
+// resolve: envファイルを読み込み、次に接続設定とマイグレーションの読み込み元を解決する関数
+// .env may set MIGRATIONS_PATH and MIGRATION_TARGETS as well as the connection, so all are resolved after it is loaded
+// .envは接続設定と共にMIGRATIONS_PATHとMIGRATION_TARGETSを設定しうるため、読み込んだ後に全てを解決する
+func (f *sharedFlags) resolve() (*session, error) {
+	if err := migrations.LoadEnv(*f.envFile); err != nil {
+		return nil, err
+	}
+	s := &session{json: *f.json}
+	if f.db != nil {
+		config, err := migrations.LoadConfig(f.db)
+		if err != nil {
+			return nil, err
+		}
+		s.config = config
+		s.timeout = *f.timeout
+		// Logs go to stderr, so stdout stays the result: 結果が標準出力に残るよう、ログは標準エラー出力に出す
+		level := slog.LevelInfo
+		if *f.verbose {
+			level = slog.LevelDebug
+		}
+		s.logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+		s.verbose = *f.verbose
+	}
+	if f.timeouts != nil {
+		timeouts, err := migrations.LoadTimeouts()
+		if err != nil {
+			return nil, err
+		}
+		if f.timeouts.lock.set {
+			timeouts.Lock = f.timeouts.lock.value
+		}
+		if f.timeouts.statement.set {
+			timeouts.Statement = f.timeouts.statement.value
+		}
+		s.timeouts = timeouts
+	}
+	source, err := migrations.ResolveSource(*f.migrationsPath)
+	if err != nil {
+		return nil, err
+	}
+	s.declared = []migrations.Target{{Name: migrations.DefaultTarget, Source: source, MigrationsTable: migrations.DefaultMigrationsTable}}
+	s.targets = s.declared
+	if f.target != nil {
+		if s.declared, err = migrations.ParseTargets(os.Getenv("MIGRATION_TARGETS"), source); err != nil {
+			return nil, err
+		}
+		if s.targets, err = migrations.SelectTargets(s.declared, *f.target, f.all != nil && *f.all); err != nil {
+			return nil, err
+		}
+	}
+	s.source = s.targets[0].Source
+	return s, nil
+}
+
+// connect waits up to the timeout for the database, then connects a driver; the caller closes it
+// connect: 制限時間までデータベースを待ってからドライバーを接続する関数、呼び出し元が閉じる
+func (s *session) connect() (*database.PostgreSQLDriver, error) {
+	driverOptions, err := providers.DriverOptions(s.config)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	err = database.WaitForDatabase(ctx, s.config, database.WaitOptions{DriverOptions: driverOptions})
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	return migrations.Connect(s.config, append(driverOptions, database.WithLogger(s.logger))...)
+}
+
+// base returns the connection migrations run on, with the resolved timeouts and the logger
+// base: 解決したタイムアウトとロガーを持つ、マイグレーションを実行する接続を返す関数
+func (s *session) base(driver *database.PostgreSQLDriver) migrations.Database {
+	return migrations.Database{DB: driver.GetDB(), Timeouts: s.timeouts, Logger: s.logger, Verbose: s.verbose}
+}
+
+// target returns base with the tables of the first target selected
+// target: 最初に選んだターゲットのテーブルを設定したbaseを返す関数
+func (s *session) target(driver *database.PostgreSQLDriver) migrations.Database {
+	return s.targets[0].On(s.base(driver))
+}
+
+// report prints result as JSON with --json, and text otherwise, returning the exit code
+// report: --jsonの場合はresultをJSONで、それ以外はtextを表示し、終了コードを返す関数
+func (s *session) report(result any, text string) int {
+	if !s.json {
+		fmt.Println(text)
+		return 0
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+// prompts is where questions go: stderr with --json, so stdout stays valid JSON
+// prompts: 質問の表示先、--jsonの場合は標準出力がJSONのままになるよう標準エラー出力
+func (s *session) prompts() io.Writer {
+	if s.json {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// approveDown prints the plan without --json and asks before reverting migrations unless confirmed is set
+// approveDown: --jsonでなければ計画を表示し、confirmedが指定されない限りマイグレーションを元に戻す前に確認する関数
+// Without a terminal to ask on, reverting needs --confirm: 確認する端末がない場合、元に戻すには--confirmが必要
+func (s *session) approveDown(confirmed bool) migrations.Approve {
+	return func(plan migrations.Plan) error {
+		if !s.json {
+			fmt.Print(plan)
+		}
+		if plan.Direction != migrations.DirectionDown || confirmed {
+			return nil
+		}
+		if !migrations.Interactive(os.Stdin) {
+			return errNotConfirmed
+		}
+		prompt := fmt.Sprintf("Revert %d migration(s) on %s? Type yes to continue: ", len(plan.Migrations), s.config.Database)
+		ok, err := migrations.Confirm(os.Stdin, s.prompts(), prompt, "yes")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errNotConfirmed
+		}
+		return nil
+	}
+}
+
+// positiveInt is a flag that only accepts an integer above zero, so a bad --steps fails at flag parsing
+// positiveInt: 0より大きい整数のみを受け付けるフラグ、誤った--stepsをフラグの解析時に拒否する
+type positiveInt struct {
+	value int  // value: 値、指定されなければデフォルト
+	set   bool // set: コマンドラインで指定された
+}
+
+// String returns the value for the usage: 使い方に表示する値を返す関数
+func (p *positiveInt) String() string {
+	if p == nil {
+		return "0"
+	}
+	return strconv.Itoa(p.value)
+}
+
+// Set parses a positive integer: 正の整数を解析する関数
+func (p *positiveInt) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return errors.New("expected a positive integer")
+	}
+	p.value, p.set = n, true
+	return nil
+}
+
+// durationFlag is a flag that accepts a non-negative duration and records whether it was given, so an environment
+// variable can supply the default after the env file is loaded
+// durationFlag: 0以上の時間を受け付け、指定されたかを記録するフラグ、envファイルの読み込み後に環境変数がデフォルトを与えられる
+type durationFlag struct {
+	value time.Duration // value: 値
+	set   bool          // set: コマンドラインで指定された
+}
+
+// String returns the value for the usage: 使い方に表示する値を返す関数
+func (d *durationFlag) String() string {
+	if d == nil || !d.set {
+		return ""
+	}
+	return d.value.String()
+}
+
+// Set parses a non-negative duration: 0以上の時間を解析する関数
+func (d *durationFlag) Set(s string) error {
+	value, err := time.ParseDuration(s)
+	if err != nil || value < 0 {
+		return errors.New("expected a non-negative duration such as 30s")
+	}
+	d.value, d.set = value, true
+	return nil
+}
+
+// stepsResult is the --json output of a move by --steps
+// stepsResult: --stepsによる移動の--jsonの出力
+type stepsResult struct {
+	migrations.Plan
+	Requested int `json:"requested,omitempty"` // requested: 指定されたステップ数、全ての場合は0
+}
+
+// describeSteps lists the migrations plan ran, then where the schema stopped
+// describeSteps: planが実行したマイグレーションを列挙し、スキーマが止まった位置を説明する関数
+// Running out before the requested steps is reported, not treated as an error
+// 指定されたステップ数の前に尽きた場合は、エラーとせずにその旨を表示する
+func describeSteps(result stepsResult) string {
+	verb, done := "apply", "applied"
+	if result.Direction == migrations.DirectionDown {
+		verb, done = "revert", "reverted"
+	}
+	var b strings.Builder
+	for _, migration := range result.Migrations {
+		fmt.Fprintf(&b, "%s %d %s\n", done, migration.Version, migration.Description)
+	}
+	if result.Requested > len(result.Migrations) {
+		fmt.Fprintf(&b, "stopped after %d of %d steps: no more migrations to %s\n", len(result.Migrations), result.Requested, verb)
+	}
+	fmt.Fprintf(&b, "now at version %d", result.To)
+	return b.String()
+}
+
+// parseInterspersed parses fs from args, letting flags follow the positional arguments, and returns the positional ones
+// parseInterspersed: 位置引数の後にもフラグを置けるようにargsからfsを解析し、位置引数を返す関数
+// Everything after "--" is positional, so a negative version can be passed as in force -- -1
+// "--"以降は全て位置引数のため、force -- -1のように負のバージョンを渡せる
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional, rest []string
+	if i := slices.Index(args, "--"); i >= 0 {
+		args, rest = args[:i], args[i+1:]
+	}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	return append(positional, rest...), nil
+}
+
+// fail prints err and returns exitFailure
+// fail: errを表示し、exitFailureを返す関数
+func fail(err error) int {
+	fmt.Fprintf(os.Stderr, "sift-migrate: %v\n", err)
+	return exitFailure
+}
+
+// usageError prints err and returns exitUsage
+// usageError: errを表示し、exitUsageを返す関数
+func usageError(err error) int {
+	fmt.Fprintf(os.Stderr, "sift-migrate: %v\n", err)
+	return exitUsage
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"          // flag: コマンドライン引数の解析
+	"os"            // os: operating system（オペレーティングシステム）、一時ファイルの作成
+	"path/filepath" // filepath: パス操作
+	"strings"       // strings: 文字列操作
+	"testing"       // testing: テスト機能
+	"time"          // time: 時間操作機能
+
+	"api/internal/migrations" // migrations: マイグレーションのタイムアウト
+)
+
+// environmentKeys lists the variables resolve reads, cleared before each case
+// environmentKeys: resolveが読む環境変数の一覧、各ケースの前に消去する
+var environmentKeys = []string{
+	"MIGRATIONS_PATH", "DB_ENV_FILE",
+	"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME", "DB_SSL_MODE",
+	"PGHOST", "PGPORT", "PGUSER", "PGPASSWORD", "PGDATABASE", "PGSSLMODE",
+}
+
+// TestResolve tests the shared flags: the env file, the migrations path precedence, the connection flags and --timeout
+// TestResolve: 共有のフラグ（envファイル、マイグレーションのパスの優先順位、接続のフラグ、--timeout）をテストする関数
+func TestResolve(t *testing.T) {
+	flagDir := t.TempDir()
+	envDir := t.TempDir()
+	notDir := filepath.Join(t.TempDir(), "000001_init.up.sql")
+	if err := os.WriteFile(notDir, []byte("SELECT 1;"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	envFile := filepath.Join(t.TempDir(), "migrate.env")
+	if err := os.WriteFile(envFile, []byte("DB_HOST=file-host\nMIGRATIONS_PATH="+envDir+"\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+	connection := map[string]string{"DB_USER": "env-user", "DB_PASSWORD": "env-pass", "DB_NAME": "env-db"}
+
+	testCases := []struct {
+		name            string
+		args            []string
+		env             map[string]string
+		expectedPath    string
+		expectedHost    string
+		expectedTimeout time.Duration
+		expectError     bool
+		errorContent    string
+	}{
+		{name: "Path flag overrides the environment", args: []string{"--migrations-path", flagDir}, env: map[string]string{"MIGRATIONS_PATH": envDir},
+			expectedPath: flagDir, expectedHost: "localhost", expectedTimeout: defaultTimeout},
+		{name: "Path from the environment", env: map[string]string{"MIGRATIONS_PATH": envDir},
+			expectedPath: envDir, expectedHost: "localhost", expectedTimeout: defaultTimeout},
+		{name: "Embedded migrations by default", expectedPath: "", expectedHost: "localhost", expectedTimeout: defaultTimeout},
+		{name: "Env file", args: []string{"--env-file", envFile}, expectedPath: envDir, expectedHost: "file-host", expectedTimeout: defaultTimeout},
+		{name: "Environment wins over the env file", args: []string{"--env-file", envFile}, env: map[string]string{"DB_HOST": "env-host"},
+			expectedPath: envDir, expectedHost: "env-host", expectedTimeout: defaultTimeout},
+		{name: "Missing env file", args: []string{"--env-file", filepath.Join(envDir, "missing.env")}, expectError: true, errorContent: "failed to load env file"},
+		{name: "Path is a file", args: []string{"--migrations-path", notDir}, expectError: true, errorContent: "is not a directory"},
+		{name: "Connection flags override the environment", args: []string{"--db-host", "flag-host", "--timeout", "5s"},
+			env: map[string]string{"DB_HOST": "env-host"}, expectedHost: "flag-host", expectedTimeout: 5 * time.Second},
+		{name: "Missing database name", env: map[string]string{"DB_NAME": ""},
+			expectError: true, errorContent: "--db-name flag or DB_NAME (or PGDATABASE) environment variable is required"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Chdir(t.TempDir()) // no .env here: ここには.envがない
+			for _, key := range environmentKeys {
+				t.Setenv(key, "")
+				os.Unsetenv(key) // unset, since .env does not override a variable set empty: .envは空に設定された変数を上書きしないため未設定にする
+			}
+			for key, value := range connection {
+				t.Setenv(key, value)
+			}
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			shared := addSharedFlags(fs, true, defaultTimeout)
+			if err := fs.Parse(tc.args); err != nil {
+				t.Fatalf("Failed to parse flags: %v", err)
+			}
+			s, err := shared.resolve()
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if s.source.Path != tc.expectedPath {
+				t.Errorf("Expected path %q, got: %q", tc.expectedPath, s.source.Path)
+			}
+			if s.config.Host != tc.expectedHost || s.config.User != "env-user" {
+				t.Errorf("Expected host %s as env-user, got: %v", tc.expectedHost, s.config)
+			}
+			if s.timeout != tc.expectedTimeout {
+				t.Errorf("Expected timeout %v, got: %v", tc.expectedTimeout, s.timeout)
+			}
+		})
+	}
+}
+
+// TestAddSharedFlagsWithoutConnection tests that a subcommand that does not connect gets no --timeout or --db-* flags
+// TestAddSharedFlagsWithoutConnection: 接続しないサブコマンドに--timeoutと--db-*フラグがないことをテストする関数
+func TestAddSharedFlagsWithoutConnection(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	addSharedFlags(fs, false, 0)
+	for _, name := range []string{"env-file", "migrations-path", "json"} {
+		if fs.Lookup(name) == nil {
+			t.Errorf("Expected --%s", name)
+		}
+	}
+	for _, name := range []string{"timeout", "db-host"} {
+		if fs.Lookup(name) != nil {
+			t.Errorf("Expected no --%s", name)
+		}
+	}
+}
+
+// TestResolveTimeouts tests that --lock-timeout and --statement-timeout override MIGRATE_LOCK_TIMEOUT and MIGRATE_STATEMENT_TIMEOUT
+// TestResolveTimeouts: --lock-timeoutと--statement-timeoutがMIGRATE_LOCK_TIMEOUTとMIGRATE_STATEMENT_TIMEOUTより優先されることをテストする関数
+func TestResolveTimeouts(t *testing.T) {
+	connection := map[string]string{"DB_USER": "env-user", "DB_PASSWORD": "env-pass", "DB_NAME": "env-db"}
+	testCases := []struct {
+		name         string
+		args         []string
+		env          map[string]string
+		expected     migrations.Timeouts
+		expectError  bool
+		errorContent string
+	}{
+		{name: "Defaults", expected: migrations.Timeouts{Lock: 30 * time.Second}},
+		{name: "Environment", env: map[string]string{"MIGRATE_LOCK_TIMEOUT": "5s", "MIGRATE_STATEMENT_TIMEOUT": "10m"},
+			expected: migrations.Timeouts{Lock: 5 * time.Second, Statement: 10 * time.Minute}},
+		{name: "Flags override the environment", args: []string{"--lock-timeout", "2s", "--statement-timeout", "1m"},
+			env:      map[string]string{"MIGRATE_LOCK_TIMEOUT": "5s", "MIGRATE_STATEMENT_TIMEOUT": "10m"},
+			expected: migrations.Timeouts{Lock: 2 * time.Second, Statement: time.Minute}},
+		{name: "Zero flag overrides the environment", args: []string{"--lock-timeout", "0"}, env: map[string]string{"MIGRATE_LOCK_TIMEOUT": "5s"},
+			expected: migrations.Timeouts{}},
+		{name: "Invalid environment", env: map[string]string{"MIGRATE_STATEMENT_TIMEOUT": "forever"},
+			expectError: true, errorContent: `invalid MIGRATE_STATEMENT_TIMEOUT: "forever"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Chdir(t.TempDir()) // no .env here: ここには.envがない
+			for _, key := range append(environmentKeys, "MIGRATE_LOCK_TIMEOUT", "MIGRATE_STATEMENT_TIMEOUT") {
+				t.Setenv(key, "")
+				os.Unsetenv(key)
+			}
+			for key, value := range connection {
+				t.Setenv(key, value)
+			}
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			shared := addSharedFlags(fs, true, defaultTimeout)
+			shared.addTimeouts(fs)
+			if err := fs.Parse(tc.args); err != nil {
+				t.Fatalf("Failed to parse flags: %v", err)
+			}
+			s, err := shared.resolve()
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if s.timeouts != tc.expected {
+				t.Errorf("Expected %+v, got: %+v", tc.expected, s.timeouts)
+			}
+		})
+	}
+}
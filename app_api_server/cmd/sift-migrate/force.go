@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"    // flag: コマンドライン引数の解析
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"strconv" // strconv: string conversion（文字列変換）、バージョンの解析
+
+	"api/internal/migrations" // migrations: バージョンの照合と強制設定
+)
+
+// forceResult is force's --json output
+// forceResult: forceの--jsonの出力
+type forceResult struct {
+	Version       int  `json:"version"`        // version: 記録したバージョン
+	Previous      uint `json:"previous"`       // previous: 置き換えたバージョン
+	PreviousDirty bool `json:"previous_dirty"` // previous dirty: 置き換えたバージョンがdirtyだったか
+}
+
+// forceCommand records a version without running any migration, clearing the dirty flag
+// forceCommand: マイグレーションを実行せずにバージョンを記録し、dirtyフラグを消すサブコマンド
+// After a migration fails part way, fix the schema by hand and force the version it is now at. The version must have a
+// migration unless --allow-missing is given; -1 records that no migration has been applied and is passed after "--"
+// マイグレーションが途中で失敗した後、スキーマを手作業で修正し、現在のバージョンを強制的に設定する。--allow-missingを
+// 指定しない限りバージョンにはマイグレーションが必要。-1はマイグレーションが1つも適用されていないことを記録し、"--"の後に渡す
+func forceCommand(fs *flag.FlagSet) func(args []string) int {
+	shared := addSharedFlags(fs, true, defaultTimeout)
+	shared.addTarget(fs, false)
+	lockWait := fs.Duration("lock-wait", migrations.DefaultLockWait, migrations.LockWaitUsage)
+	confirm := fs.Bool("confirm", false, "confirm overwriting the recorded version")
+	allowMissing := fs.Bool("allow-missing", false, "allow a version that has no migration in the directory")
+	return func(args []string) int {
+		version, err := parseForceVersion(args, *confirm)
+		if err != nil {
+			return usageError(err)
+		}
+		s, err := shared.resolve()
+		if err != nil {
+			return usageError(err)
+		}
+
+		// The check runs before connecting, so a refused version never touches the database
+		// 照合は接続前に行うため、拒否したバージョンがデータベースに触れることはない
+		list, err := migrations.Load(s.source)
+		if err != nil {
+			return fail(err)
+		}
+		if err := migrations.CheckForceVersion(list, version, *allowMissing); err != nil {
+			return fail(err)
+		}
+		driver, err := s.connect()
+		if err != nil {
+			return fail(err)
+		}
+		defer driver.Close()
+
+		target := s.target(driver)
+		result := forceResult{Version: version}
+		err = migrations.WithLock(target, *lockWait, func() (err error) {
+			result.Previous, result.PreviousDirty, err = migrations.Force(target, s.source, version)
+			return err
+		})
+		if err != nil {
+			return fail(err)
+		}
+		previous := fmt.Sprintf("version %d", result.Previous)
+		if result.PreviousDirty {
+			previous += ", dirty"
+		}
+		return s.report(result, fmt.Sprintf("forced version %d (was %s)", version, previous))
+	}
+}
+
+// parseForceVersion reads the version argument and requires --confirm
+// parseForceVersion: バージョンの引数を読み、--confirmを必須とする関数
+func parseForceVersion(args []string, confirm bool) (int, error) {
+	if len(args) == 0 {
+		return 0, fmt.Errorf("missing version: usage: sift-migrate force --confirm <version>")
+	}
+	if len(args) > 1 {
+		return 0, fmt.Errorf("unexpected arguments: %v", args[1:])
+	}
+	version, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid version %q: expected an integer", args[0])
+	}
+	if !confirm {
+		return 0, fmt.Errorf("force records version %d without running any migration; rerun with --confirm", version)
+	}
+	return version, nil
+}
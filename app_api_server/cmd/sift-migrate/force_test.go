@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings" // strings: 文字列操作
+	"testing" // testing: テスト機能
+)
+
+// TestForceArguments tests the version argument, --confirm and trailing flags
+// TestForceArguments: バージョンの引数、--confirm、後ろに置いたフラグをテストする関数
+func TestForceArguments(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		args                 []string
+		expectedVersion      int
+		expectedAllowMissing bool
+		expectError          bool
+		errorContent         string
+	}{
+		{name: "Flags before the version", args: []string{"--confirm", "--migrations-path", "testdata", "2"}, expectedVersion: 2},
+		{name: "Flags after the version", args: []string{"3", "--confirm", "--allow-missing"}, expectedVersion: 3, expectedAllowMissing: true},
+		{name: "Nil version after --", args: []string{"--confirm", "--", "-1"}, expectedVersion: -1},
+		{name: "Confirm required", args: []string{"2"}, expectError: true, errorContent: "rerun with --confirm"},
+		{name: "Missing version", args: []string{"--confirm"}, expectError: true, errorContent: "missing version"},
+		{name: "Version not an integer", args: []string{"--confirm", "v2"}, expectError: true, errorContent: `invalid version "v2"`},
+		{name: "Extra argument", args: []string{"--confirm", "2", "3"}, expectError: true, errorContent: "unexpected arguments: [3]"},
+	}
+
+	c, _ := findCommand("force")
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := newFlagSet(c)
+			c.setup(fs)
+			positional, err := parseInterspersed(fs, tc.args)
+			if err != nil {
+				t.Fatalf("Failed to parse flags: %v", err)
+			}
+			version, err := parseForceVersion(positional, fs.Lookup("confirm").Value.String() == "true")
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			allowMissing := fs.Lookup("allow-missing").Value.String() == "true"
+			if version != tc.expectedVersion || allowMissing != tc.expectedAllowMissing {
+				t.Errorf("Expected version %d (allow missing %v), got: %d (%v)", tc.expectedVersion, tc.expectedAllowMissing, version, allowMissing)
+			}
+		})
+	}
+}
+
+// TestParseBaselineVersion tests baseline's version argument
+// TestParseBaselineVersion: baselineのバージョンの引数をテストする関数
+func TestParseBaselineVersion(t *testing.T) {
+	testCases := []struct {
+		name         string
+		args         []string
+		expected     uint
+		expectError  bool
+		errorContent string
+	}{
+		{name: "Version", args: []string{"12"}, expected: 12},
+		{name: "Missing version", expectError: true, errorContent: "missing version"},
+		{name: "Zero", args: []string{"0"}, expectError: true, errorContent: "expected a positive integer"},
+		{name: "Negative", args: []string{"-1"}, expectError: true, errorContent: "expected a positive integer"},
+		{name: "Extra argument", args: []string{"1", "2"}, expectError: true, errorContent: "unexpected arguments: [2]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			version, err := parseBaselineVersion(tc.args)
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				return
+			}
+			if err != nil || version != tc.expected {
+				t.Errorf("Expected %d, got: %d, %v", tc.expected, version, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"    // flag: コマンドライン引数の解析
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"strconv" // strconv: string conversion（文字列変換）、バージョンの解析
+
+	"api/internal/migrations" // migrations: 計画の作成と移動
+)
+
+// gotoCommand moves the schema up or down to a version, printing the migrations it applies or reverts first
+// gotoCommand: 適用または元に戻すマイグレーションを先に表示し、スキーマを指定したバージョンまで上げる、または下げるサブコマンド
+// Moving down loses data, so it asks on a terminal and otherwise needs --confirm; a version with no migration is
+// refused before connecting. Moving up stops on checksum drift unless --ignore-checksum-drift is passed, as up does
+// 下げる場合はデータが失われるため、端末では確認を求め、それ以外では--confirmが必要。マイグレーションがないバージョンは接続前に拒否する。
+// 上げる場合はupと同じく、--ignore-checksum-driftを指定しない限りチェックサムのドリフトで中止する
+func gotoCommand(fs *flag.FlagSet) func(args []string) int {
+	shared := addSharedFlags(fs, true, defaultTimeout)
+	shared.addTarget(fs, false)
+	shared.addTimeouts(fs)
+	lockWait := fs.Duration("lock-wait", migrations.DefaultLockWait, migrations.LockWaitUsage)
+	confirm := fs.Bool("confirm", false, "confirm reverting migrations without a prompt")
+	ignoreDrift := fs.Bool("ignore-checksum-drift", false, "move up even when an applied migration was modified after apply")
+	return func(args []string) int {
+		if len(args) == 0 {
+			return usageError(fmt.Errorf("missing version: usage: sift-migrate goto [--confirm] [--ignore-checksum-drift] <version>"))
+		}
+		if len(args) > 1 {
+			return usageError(fmt.Errorf("unexpected arguments: %v", args[1:]))
+		}
+		version, err := strconv.ParseUint(args[0], 10, 0)
+		if err != nil {
+			return usageError(fmt.Errorf("invalid version %q: expected a migration version", args[0]))
+		}
+		s, err := shared.resolve()
+		if err != nil {
+			return usageError(err)
+		}
+
+		list, err := migrations.Load(s.source)
+		if err != nil {
+			return fail(err)
+		}
+		if err := migrations.CheckTarget(list, uint(version)); err != nil {
+			return fail(err)
+		}
+		driver, err := s.connect()
+		if err != nil {
+			return fail(err)
+		}
+		defer driver.Close()
+
+		target := s.target(driver)
+		var plan migrations.Plan
+		err = migrations.WithLock(target, *lockWait, func() (err error) {
+			plan, err = migrations.Goto(target, s.source, list, uint(version), *ignoreDrift, s.approveDown(*confirm))
+			return err
+		})
+		if err != nil {
+			return fail(err)
+		}
+		if plan.Direction == migrations.DirectionNone {
+			return s.report(plan, fmt.Sprintf("database is already at version %d", plan.To))
+		}
+		return s.report(plan, fmt.Sprintf("now at version %d", plan.To))
+	}
+}
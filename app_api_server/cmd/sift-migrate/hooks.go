@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context" // context: コンテキスト
+	"errors"  // errors: エラー操作
+	"flag"    // flag: コマンドライン引数の解析
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"os"      // os: operating system（オペレーティングシステム）、エラーの表示先
+
+	"api/internal/database"   // database: フックを実行する接続
+	"api/internal/migrations" // migrations: フックの読み込みと実行
+)
+
+// Exit codes of up when a hook fails, kept apart from a failed migration
+// フックが失敗した場合のupの終了コード、マイグレーションの失敗とは区別する
+const (
+	exitPreHookFailed  = 3 // pre-hook failed: プレフックの失敗、マイグレーションは適用していない
+	exitPostHookFailed = 4 // post-hook failed: ポストフックの失敗、マイグレーションは適用済み
+)
+
+// hookFlags are --pre-hook, --post-hook and --post-hook-always
+// hookFlags: --pre-hook、--post-hook、--post-hook-always
+type hookFlags struct {
+	pre        *string // pre: --pre-hook
+	post       *string // post: --post-hook
+	postAlways *bool   // post always: --post-hook-always
+}
+
+// addHooks registers the hook flags on fs
+// addHooks: fsにフックのフラグを登録する関数
+func addHooks(fs *flag.FlagSet) *hookFlags {
+	return &hookFlags{
+		pre:        fs.String("pre-hook", "", "`file` to run before the first pending migration: a .sql file, or an executable given the PG* variables"),
+		post:       fs.String("post-hook", "", "`file` to run after the last migration applied: a .sql file, or an executable given the PG* variables"),
+		postAlways: fs.Bool("post-hook-always", false, "run --post-hook even when no migration was applied"),
+	}
+}
+
+// resolve checks the hook files and returns the hooks for s; no flag leaves both hooks nil
+// resolve: フックのファイルを確認し、sのフックを返す関数、フラグがない場合は両方のフックがnil
+func (f *hookFlags) resolve(s *session) (migrations.Hooks, error) {
+	hooks := migrations.Hooks{PostAlways: *f.postAlways, Config: s.config, Out: s.prompts()}
+	var err error
+	if *f.pre != "" {
+		if hooks.Pre, err = migrations.ParseHook(migrations.PreHook, *f.pre); err != nil {
+			return migrations.Hooks{}, err
+		}
+	}
+	if *f.post != "" {
+		if hooks.Post, err = migrations.ParseHook(migrations.PostHook, *f.post); err != nil {
+			return migrations.Hooks{}, err
+		}
+	}
+	if hooks.PostAlways && hooks.Post == nil {
+		return migrations.Hooks{}, errors.New("--post-hook-always needs --post-hook")
+	}
+	return hooks, nil
+}
+
+// aroundHooks runs apply between hooks, running the pre-hook only when one of targets has a pending migration
+// aroundHooks: hooksの間でapplyを実行する関数、プレフックはtargetsのいずれかに未適用のマイグレーションがある場合のみ実行する
+func aroundHooks(hooks migrations.Hooks, driver *database.PostgreSQLDriver, base migrations.Database, targets []migrations.Target, apply func() (applied bool, err error)) error {
+	ctx := context.Background()
+	pending := false
+	if hooks.Pre != nil {
+		var err error
+		if pending, err = migrations.HasPending(ctx, base, targets); err != nil {
+			return err
+		}
+	}
+	return hooks.Around(ctx, driver, pending, apply)
+}
+
+// failHook prints err and returns the exit code of the hook that failed, or exitFailure
+// failHook: errを表示し、失敗したフックの終了コード、またはexitFailureを返す関数
+func failHook(err error) int {
+	var hookErr *migrations.HookError
+	if !errors.As(err, &hookErr) {
+		return fail(err)
+	}
+	fmt.Fprintf(os.Stderr, "sift-migrate: %v\n", err)
+	if hookErr.Phase == migrations.PreHook {
+		return exitPreHookFailed
+	}
+	return exitPostHookFailed
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"  // errors: エラー操作
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"testing" // testing: テスト機能
+
+	"api/internal/migrations" // migrations: フックのエラー
+)
+
+// TestFailHook tests that a failed hook exits with its own code, kept apart from a failed migration
+// TestFailHook: 失敗したフックがマイグレーションの失敗と区別された独自の終了コードで終了することをテストする関数
+func TestFailHook(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "Failed migration", err: errors.New("migration 2 failed"), expected: exitFailure},
+		{name: "Failed pre-hook", err: fmt.Errorf("%w; no migration was applied", &migrations.HookError{Phase: migrations.PreHook, Path: "backup.sh", Err: errors.New("exit status 1")}), expected: exitPreHookFailed},
+		{name: "Failed post-hook", err: &migrations.HookError{Phase: migrations.PostHook, Path: "refresh.sql", Err: errors.New("relation does not exist")}, expected: exitPostHookFailed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := failHook(tc.err); code != tc.expected {
+				t.Errorf("Expected exit code %d, got: %d", tc.expected, code)
+			}
+		})
+	}
+}
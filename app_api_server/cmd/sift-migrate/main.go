@@ -0,0 +1,154 @@
+// Command sift-migrate manages the database schema: it applies, reverts and inspects migrations, generates new ones,
+// seeds data and dumps the schema
+// sift-migrate: データベースのスキーマを管理するコマンド。マイグレーションの適用、取り消し、確認、新規作成、データのシード、スキーマのダンプを行う
+//
+// Every subcommand loads the configuration like the server (--env-file, then the DB_* variables, with the --db-*
+// flags on top) and reads the migrations in --migrations-path (default $MIGRATIONS_PATH, then the migrations
+// embedded in the binary). Commands that change the schema hold a Postgres advisory lock, waiting up to --lock-wait
+// for another run. Further migration targets, each with its own directory and version table, are declared in
+// $MIGRATION_TARGETS and picked with --target. Each migration applied or reverted is logged to stderr with its
+// version, direction and time, and --verbose adds golang-migrate's own output. --json prints the result as JSON. The
+// logic behind each subcommand lives in internal/migrations
+// 全てのサブコマンドはサーバーと同じ方法で設定を読み込み（--env-file、次にDB_*環境変数、その上に--db-*フラグ）、
+// --migrations-path（デフォルトは$MIGRATIONS_PATH、次にバイナリに埋め込んだマイグレーション）のマイグレーションを読む。
+// スキーマを変更するコマンドはPostgreSQLのアドバイザリーロックを保持し、別の実行を--lock-waitまで待つ。
+// 独自のディレクトリとバージョンのテーブルを持つ追加のマイグレーションのターゲットは$MIGRATION_TARGETSで宣言し、--targetで選ぶ。
+// 適用または取り消した各マイグレーションはバージョン、向き、時間と共に標準エラー出力にログを出し、--verboseでgolang-migrate自身の出力を加える。
+// --jsonは結果をJSONで表示する。各サブコマンドの処理はinternal/migrationsにある
+//
+// Exit codes: 0 success, 1 failure, 2 usage error; up exits 3 when --pre-hook fails and 4 when --post-hook fails,
+// and status and check use their own codes (see sift-migrate status -h and check -h)
+// 終了コード: 0 成功、1 失敗、2 使い方の誤り。upは--pre-hookが失敗すると3、--post-hookが失敗すると4で終了し、
+// statusとcheckは独自の終了コードを使う（sift-migrate status -hとcheck -hを参照）
+//
+// Usage: sift-migrate <command> [flags] [arguments]
+package main
+
+import (
+	"errors" // errors: エラー操作
+	"flag"   // flag: コマンドライン引数の解析
+	"fmt"    // fmt: format（フォーマット）、文字列フォーマット機能
+	"io"     // io: 入出力、使い方の表示先
+	"os"     // os: operating system（オペレーティングシステム）、出力先と終了コード
+)
+
+// Exit codes shared by the subcommands
+// サブコマンドで共有する終了コード
+const (
+	exitFailure = 1 // failure: 処理の失敗
+	exitUsage   = 2 // usage: 使い方の誤り
+)
+
+// command is one subcommand of sift-migrate
+// command: sift-migrateの1つのサブコマンド
+type command struct {
+	name      string // name: サブコマンド名
+	arguments string // arguments: 使い方に表示する引数
+	summary   string // summary: 一覧に表示する説明
+	// setup registers the subcommand's flags on fs and returns the function that runs it with the positional arguments
+	// setup: fsにサブコマンドのフラグを登録し、位置引数で実行する関数を返す
+	setup func(fs *flag.FlagSet) func(args []string) int
+	// usageExit is the exit code for a usage error, when exitUsage means something else to the subcommand
+	// usageExit: exitUsageがサブコマンドで別の意味を持つ場合の、使い方の誤りの終了コード
+	usageExit int
+}
+
+// commands lists the subcommands in the order the usage shows them
+// commands: 使い方に表示する順のサブコマンドの一覧
+// It is a function so that completion, which lists the subcommands itself, avoids an initialization cycle
+// サブコマンドを自身で列挙するcompletionが初期化の循環を起こさないよう、関数にしている
+func commands() []command {
+	return []command{
+		{name: "up", summary: "apply every pending migration, or the next --steps of them", setup: upCommand},
+		{name: "down", summary: "revert the newest --steps migrations (default 1; --all for every one)", setup: downCommand},
+		{name: "status", summary: "list applied and pending migrations", setup: statusCommand, usageExit: exitStatusError},
+		{name: "check", summary: "exit non-zero on pending, dirty or modified migrations, or on a broken directory", setup: checkCommand, usageExit: exitCheckError},
+		{name: "version", summary: "print the version recorded in the database", setup: versionCommand},
+		{name: "dump", summary: "write the schema DDL to a file for review diffs, or --check it", setup: dumpCommand},
+		{name: "baseline", arguments: "<version>", summary: "record a version in a database built before the migrations, without running them", setup: baselineCommand},
+		{name: "force", arguments: "<version>", summary: "record a version without running migrations, clearing the dirty flag", setup: forceCommand},
+		{name: "goto", arguments: "<version>", summary: "move the schema up or down to a version", setup: gotoCommand},
+		{name: "create", arguments: "<name>", summary: "generate a new pair of migration files", setup: createCommand},
+		{name: "drop", summary: "drop every table (test and development only)", setup: dropCommand},
+		{name: "seed", summary: "run the seed files and create the admin user", setup: seedCommand},
+		{name: "completion", arguments: "bash|zsh", summary: "print a shell completion script", setup: completionCommand},
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run dispatches to the subcommand named by the first argument and returns its exit code
+// run: 最初の引数で指定されたサブコマンドを実行し、その終了コードを返す関数
+func run(args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stderr)
+		return exitUsage
+	}
+	name, args := args[0], args[1:]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		if len(args) == 0 {
+			printUsage(os.Stdout)
+			return 0
+		}
+		// help <command> prints the subcommand's flags: help <command>はサブコマンドのフラグを表示する
+		name, args = args[0], []string{"-h"}
+	}
+
+	c, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "sift-migrate: unknown command %q\n\n", name)
+		printUsage(os.Stderr)
+		return exitUsage
+	}
+	usageExit := c.usageExit
+	if usageExit == 0 {
+		usageExit = exitUsage
+	}
+
+	fs := newFlagSet(c)
+	runCommand := c.setup(fs)
+	positional, err := parseInterspersed(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		return usageExit
+	}
+	return runCommand(positional)
+}
+
+// findCommand returns the subcommand called name
+// findCommand: nameという名前のサブコマンドを返す関数
+func findCommand(name string) (command, bool) {
+	for _, c := range commands() {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+// newFlagSet returns an empty flag set for c whose usage names the subcommand and its arguments
+// newFlagSet: cの空のフラグセットを返す関数、使い方にサブコマンドと引数を表示する
+func newFlagSet(c command) *flag.FlagSet {
+	fs := flag.NewFlagSet("sift-migrate "+c.name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: sift-migrate %s [flags] %s\n\n%s\n\nFlags:\n", c.name, c.arguments, c.summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// printUsage lists the subcommands
+// printUsage: サブコマンドを一覧表示する関数
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: sift-migrate <command> [flags] [arguments]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, c := range commands() {
+		fmt.Fprintf(w, "  %-11s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w, "\nRun sift-migrate help <command> for the flags of a command.")
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"    // flag: コマンドライン引数の解析
+	"reflect" // reflect: 値の比較
+	"strings" // strings: 文字列操作
+	"testing" // testing: テスト機能
+
+	"api/internal/migrations" // migrations: checkの終了コード
+)
+
+// TestRun tests dispatching and the exit codes of usage errors, none of which reach a database
+// TestRun: サブコマンドの振り分けと、データベースに接続しない使い方の誤りの終了コードをテストする関数
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []string
+		expected int
+	}{
+		{name: "No command", args: nil, expected: exitUsage},
+		{name: "Help", args: []string{"help"}, expected: 0},
+		{name: "Help for a command", args: []string{"help", "up"}, expected: 0},
+		{name: "Command help flag", args: []string{"goto", "-h"}, expected: 0},
+		{name: "Unknown command", args: []string{"migrate"}, expected: exitUsage},
+		{name: "Unknown flag", args: []string{"up", "--quiet"}, expected: exitUsage},
+		{name: "Status keeps its own usage exit code", args: []string{"status", "--quiet"}, expected: exitStatusError},
+		{name: "Unexpected argument", args: []string{"version", "now"}, expected: exitUsage},
+		{name: "Check keeps its own usage exit code", args: []string{"check", "--quiet"}, expected: exitCheckError},
+		{name: "Check a valid directory", args: []string{"check", "--source-only", "--migrations-path", "../../internal/migrations/testdata/check/valid"}, expected: 0},
+		{name: "Check a broken directory", args: []string{"check", "--source-only", "--migrations-path", "../../internal/migrations/testdata/check/gap"}, expected: migrations.ExitInvalid},
+		{name: "Force needs --confirm", args: []string{"force", "2"}, expected: exitUsage},
+		{name: "Baseline needs a version", args: []string{"baseline"}, expected: exitUsage},
+		{name: "Dump to stdout with --check", args: []string{"dump", "--out", "-", "--check"}, expected: exitUsage},
+		{name: "Zero steps", args: []string{"up", "--steps", "0"}, expected: exitUsage},
+		{name: "Negative steps", args: []string{"down", "--steps", "-1"}, expected: exitUsage},
+		{name: "Negative lock timeout", args: []string{"up", "--lock-timeout", "-1s"}, expected: exitUsage},
+		{name: "Steps not a number", args: []string{"up", "--steps=all"}, expected: exitUsage},
+		{name: "Down with steps as an argument", args: []string{"down", "2"}, expected: exitUsage},
+		{name: "Down with --steps and --all", args: []string{"down", "--all", "--steps", "2"}, expected: exitUsage},
+		{name: "Up with --target and --all", args: []string{"up", "--all", "--target", "audit"}, expected: exitUsage},
+		{name: "Up with --steps and --all", args: []string{"up", "--all", "--steps", "1"}, expected: exitUsage},
+		{name: "Unsupported shell", args: []string{"completion", "fish"}, expected: exitUsage},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := run(tc.args); code != tc.expected {
+				t.Errorf("Expected exit code %d, got: %d", tc.expected, code)
+			}
+		})
+	}
+}
+
+// TestParseInterspersed tests flags after positional arguments and the "--" terminator
+// TestParseInterspersed: 位置引数の後のフラグと"--"の終端をテストする関数
+func TestParseInterspersed(t *testing.T) {
+	testCases := []struct {
+		name               string
+		args               []string
+		expectedPositional []string
+		expectedConfirm    bool
+	}{
+		{name: "Flags first", args: []string{"--confirm", "2"}, expectedPositional: []string{"2"}, expectedConfirm: true},
+		{name: "Flags after", args: []string{"2", "--confirm"}, expectedPositional: []string{"2"}, expectedConfirm: true},
+		{name: "Several positional", args: []string{"a", "--confirm", "b"}, expectedPositional: []string{"a", "b"}, expectedConfirm: true},
+		{name: "Negative after --", args: []string{"--confirm", "--", "-1"}, expectedPositional: []string{"-1"}, expectedConfirm: true},
+		{name: "Flag after -- stays positional", args: []string{"--", "--confirm"}, expectedPositional: []string{"--confirm"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			confirm := fs.Bool("confirm", false, "")
+			positional, err := parseInterspersed(fs, tc.args)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(positional, tc.expectedPositional) || *confirm != tc.expectedConfirm {
+				t.Errorf("Expected %v (confirm %v), got: %v (%v)", tc.expectedPositional, tc.expectedConfirm, positional, *confirm)
+			}
+		})
+	}
+}
+
+// TestCompletionScript tests that the script offers every subcommand and each subcommand's flags
+// TestCompletionScript: スクリプトが全てのサブコマンドと各サブコマンドのフラグを補完候補にすることをテストする関数
+func TestCompletionScript(t *testing.T) {
+	script := completionScript()
+	var names []string
+	for _, c := range commands() {
+		names = append(names, c.name)
+	}
+	if !strings.Contains(script, `compgen -W "`+strings.Join(names, " ")+`"`) {
+		t.Errorf("Expected every subcommand in the script, got: %s", script)
+	}
+	for _, expected := range []string{
+		`up) COMPREPLY=($(compgen -W "--all --db-host`,
+		"--lock-wait",
+		"--migrations-path",
+		"--i-know-what-im-doing",
+		"complete -F _sift_migrate sift-migrate",
+	} {
+		if !strings.Contains(script, expected) {
+			t.Errorf("Expected %q in the script", expected)
+		}
+	}
+}
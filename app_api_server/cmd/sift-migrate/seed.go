@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context" // context: コンテキスト
+	"errors"  // errors: エラー操作
+	"flag"    // flag: コマンドライン引数の解析
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"strings" // strings: 文字列操作
+
+	"api/internal/database"   // database: 管理者の設定
+	"api/internal/migrations" // migrations: シードとロック
+)
+
+// seedCommand runs the .sql files in the seeds directory in name order and creates the admin user from ADMIN_EMAIL
+// and ADMIN_PASSWORD, all in one transaction
+// seedCommand: シードのディレクトリの.sqlファイルを名前順に実行し、ADMIN_EMAILとADMIN_PASSWORDから管理者を作成するサブコマンド、
+// 全てを1つのトランザクションで行う
+// Run it after up; it refuses a production APP_ENV unless --force is passed, and --only runs a single file
+// upの後に実行する。--forceを指定しない限り本番のAPP_ENVでは拒否し、--onlyは1つのファイルのみを実行する
+func seedCommand(fs *flag.FlagSet) func(args []string) int {
+	shared := addSharedFlags(fs, true, defaultTimeout)
+	lockWait := fs.Duration("lock-wait", migrations.DefaultLockWait, migrations.LockWaitUsage)
+	seedsPath := fs.String("seeds-path", "", "directory of the .sql seed files (default $SEEDS_PATH, then ./"+migrations.DefaultSeedsPath+" when it exists)")
+	only := fs.String("only", "", "run only the seed file `name` (.sql may be omitted), without the admin user")
+	force := fs.Bool("force", false, "seed even when APP_ENV is a production environment")
+	return func(args []string) int {
+		if len(args) > 0 {
+			return usageError(fmt.Errorf("unexpected arguments: %v", args))
+		}
+		s, err := shared.resolve()
+		if err != nil {
+			return usageError(err)
+		}
+		// APP_ENV is read after resolve, which loads the env file
+		// APP_ENVはenvファイルを読み込むresolveの後に読む
+		if err := migrations.CheckSeedAllowed(*force); err != nil {
+			return usageError(err)
+		}
+		opts := migrations.SeedOptions{Only: *only}
+		if opts.Dir, err = migrations.ResolveSeedsPath(*seedsPath); err != nil {
+			return usageError(err)
+		}
+		if opts.Admin, err = database.LoadAdminBootstrapConfig(); err != nil {
+			return usageError(err)
+		}
+		switch {
+		case opts.Dir == "" && opts.Only != "":
+			return usageError(errors.New("--only needs a seeds directory: pass --seeds-path or set SEEDS_PATH"))
+		case opts.Dir == "" && opts.Admin == nil:
+			return usageError(errors.New("nothing to seed: pass --seeds-path or set ADMIN_EMAIL and ADMIN_PASSWORD"))
+		}
+		driver, err := s.connect()
+		if err != nil {
+			return fail(err)
+		}
+		defer driver.Close()
+
+		var result migrations.SeedResult
+		err = migrations.WithLock(migrations.Database{DB: driver.GetDB()}, *lockWait, func() (err error) {
+			result, err = migrations.Seed(context.Background(), driver, opts)
+			return err
+		})
+		if err != nil {
+			return fail(err)
+		}
+		text := []string{}
+		for _, file := range result.Files {
+			text = append(text, "ran "+file)
+		}
+		if result.Admin != "" {
+			text = append(text, "admin user "+result.Admin+" is in place")
+		}
+		return s.report(result, strings.Join(text, "\n"))
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context" // context: コンテキスト、読み込みの制限時間
+	"flag"    // flag: コマンドライン引数の解析
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"os"      // os: operating system（オペレーティングシステム）、出力先
+	"strings" // strings: 文字列操作
+
+	"api/internal/migrations" // migrations: マイグレーションの読み込みと状態の比較
+)
+
+// exitStatusError is status's exit code when the status cannot be determined, kept apart from the status exit codes
+// exitStatusError: 状態を判定できない場合のstatusの終了コード、状態の終了コードとは区別する
+const exitStatusError = 3
+
+// targetStatus is one target's status, as status lists them when several targets are declared
+// targetStatus: 1つのターゲットの状態、複数のターゲットが宣言されている場合にstatusが列挙する
+type targetStatus struct {
+	Target          string `json:"target"`           // target: ターゲット名
+	MigrationsTable string `json:"migrations_table"` // migrations table: バージョンを記録するテーブル
+	migrations.Status
+}
+
+// statusCommand prints each migration's state followed by a summary line, for every declared target unless --target picks one
+// statusCommand: 各マイグレーションの状態と要約の行を表示するサブコマンド、--targetで選ばない限り宣言された全てのターゲットについて表示する
+// Exit codes: 0 up to date, 1 pending migrations, 2 dirty database, 3 when the status cannot be read; with several
+// targets the worst one counts
+// 終了コード: 0 最新、1 未適用のマイグレーションあり、2 データベースがdirty、3 状態を読み取れない。複数のターゲットでは最も悪いものを使う
+func statusCommand(fs *flag.FlagSet) func(args []string) int {
+	shared := addSharedFlags(fs, true, defaultTimeout)
+	shared.addTarget(fs, false)
+	return func(args []string) int {
+		statuses, s, err := readStatus(shared, args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "sift-migrate: %v\n", err)
+			return exitStatusError
+		}
+
+		var result any = statuses
+		var text strings.Builder
+		exitCode := migrations.ExitUpToDate
+		for i, status := range statuses {
+			if len(statuses) > 1 {
+				if i > 0 {
+					text.WriteString("\n")
+				}
+				fmt.Fprintf(&text, "target %s (%s)\n", status.Target, status.MigrationsTable)
+			}
+			if err := status.WriteText(&text); err != nil {
+				fmt.Fprintf(os.Stderr, "sift-migrate: %v\n", err)
+				return exitStatusError
+			}
+			exitCode = max(exitCode, status.ExitCode())
+		}
+		// A single target keeps the output of a tree without targets: 単一のターゲットはターゲットのない場合と同じ出力にする
+		if len(statuses) == 1 {
+			result = statuses[0].Status
+		}
+		if s.report(result, strings.TrimSuffix(text.String(), "\n")) != 0 {
+			return exitStatusError
+		}
+		return exitCode
+	}
+}
+
+// readStatus loads the migrations of each target and compares them with the version the database records for it
+// readStatus: 各ターゲットのマイグレーションを読み込み、データベースがそのターゲットについて記録したバージョンと比較する関数
+func readStatus(shared *sharedFlags, args []string) ([]targetStatus, *session, error) {
+	if len(args) > 0 {
+		return nil, nil, fmt.Errorf("unexpected arguments: %v", args)
+	}
+	s, err := shared.resolve()
+	if err != nil {
+		return nil, nil, err
+	}
+	targets := s.targets
+	if *shared.target == "" {
+		targets = s.declared
+	}
+	driver, err := s.connect()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer driver.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	var statuses []targetStatus
+	for _, target := range targets {
+		status, err := readTargetStatus(ctx, s.base(driver), target)
+		if err != nil && len(targets) > 1 {
+			return nil, nil, fmt.Errorf("target %s: %w", target.Name, err)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, s, nil
+}
+
+// readTargetStatus loads target's migrations and compares them with the version recorded in its table
+// readTargetStatus: targetのマイグレーションを読み込み、そのテーブルに記録されたバージョンと比較する関数
+func readTargetStatus(ctx context.Context, base migrations.Database, target migrations.Target) (targetStatus, error) {
+	list, err := migrations.Load(target.Source)
+	if err != nil {
+		return targetStatus{}, err
+	}
+	status, err := migrations.ReadStatus(ctx, target.On(base), list)
+	return targetStatus{Target: target.Name, MigrationsTable: target.MigrationsTable, Status: status}, err
+}
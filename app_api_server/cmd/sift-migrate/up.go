@@ -0,0 +1,153 @@
+package main
+
+import (
+	"flag"    // flag: コマンドライン引数の解析
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"strings" // strings: 文字列操作
+	"time"    // time: 時間操作機能
+
+	"api/internal/database"   // database: フックを実行する接続
+	"api/internal/migrations" // migrations: マイグレーションの適用とロック
+)
+
+// upWaitTimeout is up's default --timeout, as long as the server waits for the database at startup
+// upWaitTimeout: upの--timeoutのデフォルト、サーバーが起動時にデータベースを待つ時間と同じ
+const upWaitTimeout = 2 * time.Minute
+
+// upResult is up's --json output
+// upResult: upの--jsonの出力
+type upResult struct {
+	From uint `json:"from"` // from: 適用前のバージョン
+	To   uint `json:"to"`   // to: 適用後のバージョン
+}
+
+// upCommand applies every pending migration of the target, or the next --steps of them, exiting with status 1 when one fails
+// upCommand: ターゲットの未適用のマイグレーションを全て、または次の--steps個を適用するサブコマンド、失敗した場合は終了コード1で終了する
+// --all applies every target in declaration order and stops at the first that fails
+// --allは全てのターゲットを宣言順に適用し、最初に失敗したところで止まる
+// Replicas starting together take turns on the lock; the later ones find nothing left to apply.
+// An applied migration whose file changed since it ran stops up unless --ignore-checksum-drift is passed
+// 同時に起動したレプリカはロックで順番に実行し、後のものは適用するものが残っていないことを確認する。
+// 実行後にファイルが変更された適用済みのマイグレーションがあれば、--ignore-checksum-driftを指定しない限り中止する
+// --pre-hook runs before the first pending migration and --post-hook after the last one applied, under the same lock;
+// a failed pre-hook exits 3 with nothing applied and a failed post-hook exits 4
+// --pre-hookは最初の未適用のマイグレーションの前に、--post-hookは最後に適用したものの後に同じロックの下で実行する。
+// プレフックが失敗すると何も適用せずに3で、ポストフックが失敗すると4で終了する
+func upCommand(fs *flag.FlagSet) func(args []string) int {
+	shared := addSharedFlags(fs, true, upWaitTimeout)
+	shared.addTimeouts(fs)
+	shared.addTarget(fs, true)
+	lockWait := fs.Duration("lock-wait", migrations.DefaultLockWait, migrations.LockWaitUsage)
+	steps := &positiveInt{}
+	fs.Var(steps, "steps", "apply only the next `N` pending migrations (default all)")
+	ignoreDrift := fs.Bool("ignore-checksum-drift", false, "apply even when an applied migration was modified after apply")
+	hookFlags := addHooks(fs)
+	return func(args []string) int {
+		if len(args) > 0 {
+			return usageError(fmt.Errorf("unexpected arguments: %v", args))
+		}
+		if steps.set && *shared.all {
+			return usageError(fmt.Errorf("--steps applies to one target and cannot be combined with --all"))
+		}
+		s, err := shared.resolve()
+		if err != nil {
+			return usageError(err)
+		}
+		hooks, err := hookFlags.resolve(s)
+		if err != nil {
+			return usageError(err)
+		}
+		driver, err := s.connect()
+		if err != nil {
+			return fail(err)
+		}
+		defer driver.Close()
+
+		if *shared.all {
+			return upTargets(s, driver, hooks, *lockWait, *ignoreDrift)
+		}
+		target := s.target(driver)
+		if steps.set {
+			return upSteps(s, driver, hooks, *lockWait, steps.value, *ignoreDrift)
+		}
+		var result upResult
+		err = migrations.WithLock(target, *lockWait, func() error {
+			return aroundHooks(hooks, driver, s.base(driver), s.targets, func() (applied bool, err error) {
+				result.From, result.To, err = migrations.Up(target, s.source, *ignoreDrift)
+				return result.To != result.From, err
+			})
+		})
+		if err != nil {
+			return failHook(err)
+		}
+		return s.report(result, describeUp(result.From, result.To))
+	}
+}
+
+// describeUp describes a move from version from to version to
+// describeUp: バージョンfromからtoへの移動を説明する関数
+func describeUp(from, to uint) string {
+	if from == to {
+		return fmt.Sprintf("no change, database is already at version %d", to)
+	}
+	return fmt.Sprintf("migrated from version %d to %d", from, to)
+}
+
+// upTargetsResult is the --json output of up --all
+// upTargetsResult: up --allの--jsonの出力
+type upTargetsResult struct {
+	Targets []migrations.TargetResult `json:"targets"` // targets: 宣言順のターゲットごとの結果
+}
+
+// upTargets applies every target in declaration order under one lock, between the hooks, stopping at the first that fails
+// upTargets: 1つのロックの下でフックの間に全てのターゲットを宣言順に適用する関数、最初に失敗したところで止まる
+// The targets that completed are listed before the failure: 失敗の前に完了したターゲットを表示する
+func upTargets(s *session, driver *database.PostgreSQLDriver, hooks migrations.Hooks, lockWait time.Duration, ignoreDrift bool) int {
+	base := s.base(driver)
+	var results []migrations.TargetResult
+	err := migrations.WithLock(base, lockWait, func() error {
+		return aroundHooks(hooks, driver, base, s.targets, func() (applied bool, err error) {
+			results, err = migrations.UpTargets(base, s.targets, ignoreDrift)
+			for _, result := range results {
+				applied = applied || result.To != result.From
+			}
+			return applied, err
+		})
+	})
+	var text []string
+	for i, result := range results {
+		if err != nil && i == len(results)-1 {
+			break
+		}
+		text = append(text, result.Target+": "+describeUp(result.From, result.To))
+	}
+	if err != nil {
+		if !s.json && len(text) > 0 {
+			fmt.Println(strings.Join(text, "\n"))
+		}
+		return failHook(err)
+	}
+	return s.report(upTargetsResult{Targets: results}, strings.Join(text, "\n"))
+}
+
+// upSteps applies the next steps pending migrations under the lock, between the hooks, and lists them
+// upSteps: ロックの下でフックの間に次のsteps個の未適用のマイグレーションを適用し、それらを列挙する関数
+func upSteps(s *session, driver *database.PostgreSQLDriver, hooks migrations.Hooks, lockWait time.Duration, steps int, ignoreDrift bool) int {
+	list, err := migrations.Load(s.source)
+	if err != nil {
+		return fail(err)
+	}
+	target := s.target(driver)
+	var plan migrations.Plan
+	err = migrations.WithLock(target, lockWait, func() error {
+		return aroundHooks(hooks, driver, s.base(driver), s.targets, func() (applied bool, err error) {
+			plan, err = migrations.UpSteps(target, s.source, list, steps, ignoreDrift)
+			return plan.To != plan.From, err
+		})
+	})
+	if err != nil {
+		return failHook(err)
+	}
+	result := stepsResult{Plan: plan, Requested: steps}
+	return s.report(result, describeSteps(result))
+}
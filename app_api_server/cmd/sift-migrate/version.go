@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context" // context: コンテキスト、読み込みの制限時間
+	"flag"    // flag: コマンドライン引数の解析
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+
+	"api/internal/migrations" // migrations: バージョンの読み込み
+)
+
+// versionResult is version's --json output
+// versionResult: versionの--jsonの出力
+type versionResult struct {
+	Version uint `json:"version"` // version: 記録されたバージョン、0は未実行
+	Dirty   bool `json:"dirty"`   // dirty: 最後のマイグレーションが途中で失敗したか
+}
+
+// versionCommand prints the migration version the database records for the target and whether it is dirty
+// versionCommand: データベースがターゲットについて記録したマイグレーションのバージョンと、dirtyかどうかを表示するサブコマンド
+func versionCommand(fs *flag.FlagSet) func(args []string) int {
+	shared := addSharedFlags(fs, true, defaultTimeout)
+	shared.addTarget(fs, false)
+	return func(args []string) int {
+		if len(args) > 0 {
+			return usageError(fmt.Errorf("unexpected arguments: %v", args))
+		}
+		s, err := shared.resolve()
+		if err != nil {
+			return usageError(err)
+		}
+		driver, err := s.connect()
+		if err != nil {
+			return fail(err)
+		}
+		defer driver.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		defer cancel()
+		var result versionResult
+		result.Version, result.Dirty, err = migrations.ReadVersion(ctx, s.target(driver))
+		if err != nil {
+			return fail(err)
+		}
+		return s.report(result, formatVersion(result.Version, result.Dirty))
+	}
+}
+
+// formatVersion describes the version for the terminal
+// formatVersion: 端末向けにバージョンを説明する関数
+func formatVersion(version uint, dirty bool) string {
+	switch {
+	case dirty:
+		return fmt.Sprintf("version %d (dirty: the migration failed part way; fix the schema, then run sift-migrate force)", version)
+	case version == 0:
+		return "version 0 (no migration has been applied)"
+	}
+	return fmt.Sprintf("version %d", version)
+}
@@ -3,13 +3,34 @@ module api
 go 1.24.4
 
 require (
+	github.com/aws/aws-sdk-go v1.55.5 // aws sdk: RDSのIAM認証トークン生成（awsiamサブパッケージのみ）
 	github.com/golang-migrate/migrate/v4 v4.18.3
 	github.com/joho/godotenv v1.5.1 // godotenv: 環境変数を.envファイルから読み込むライブラリ
 	github.com/lib/pq v1.10.9 // PostgreSQL driver: PostgreSQLデータベース接続ドライバー
+	github.com/prometheus/client_golang v1.20.5 // prometheus: Prometheusメトリクス公開ライブラリ
+	go.opentelemetry.io/otel v1.31.0 // otel: OpenTelemetry API
+	go.opentelemetry.io/otel/sdk v1.31.0 // otel sdk: OpenTelemetry SDK（テストのスパン記録用）
+	go.opentelemetry.io/otel/trace v1.31.0 // otel trace: OpenTelemetryトレースAPI
+	golang.org/x/crypto v0.36.0 // crypto: bcryptによる管理者パスワードのハッシュ化
 )
 
 require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
 	github.com/hashicorp/errwrap v1.1.0 // indirect
 	github.com/hashicorp/go-multierror v1.1.1 // indirect
+	github.com/jmespath/go-jmespath v0.4.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/kylelemons/godebug v1.1.0 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_model v0.6.1 // indirect
+	github.com/prometheus/common v0.55.0 // indirect
+	github.com/prometheus/procfs v0.15.1 // indirect
+	go.opentelemetry.io/otel/metric v1.31.0 // indirect
 	go.uber.org/atomic v1.7.0 // indirect
+	golang.org/x/sys v0.31.0 // indirect
+	google.golang.org/protobuf v1.34.2 // indirect
 )
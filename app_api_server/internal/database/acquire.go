@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"sync/atomic"  // atomic: アトミック操作
+	"time"         // time: 時間操作機能
+)
+
+// ErrPoolExhausted matches every PoolExhaustedError with errors.Is
+// ErrPoolExhausted: errors.Isで全てのPoolExhaustedErrorに一致するエラー
+// exhausted: 使い果たした
+var ErrPoolExhausted = errors.New("connection pool exhausted")
+
+// PoolExhaustedError is returned by the query helpers when no connection was free within the acquire timeout
+// PoolExhaustedError: 取得タイムアウト内に空き接続がなかった場合にクエリヘルパーが返すエラー
+// It tells a pool that is too small apart from a slow database: プールが小さすぎることと、データベースが遅いことを区別できる
+type PoolExhaustedError struct {
+	Timeout time.Duration   // timeout: 接続の取得を待った時間
+	Stats   ConnectionStats // stats: タイムアウト時点の接続統計
+}
+
+// Error describes the exhausted pool
+// Error: 枯渇したプールを説明する関数
+func (e *PoolExhaustedError) Error() string {
+	return fmt.Sprintf("%v: no connection free within %s (in_use=%d max_open=%d wait_count=%d)",
+		ErrPoolExhausted, e.Timeout, e.Stats.InUse, e.Stats.MaxOpenConnections, e.Stats.WaitCount)
+}
+
+// Is makes errors.Is(err, ErrPoolExhausted) true
+// Is: errors.Is(err, ErrPoolExhausted)をtrueにする関数
+func (e *PoolExhaustedError) Is(target error) bool {
+	return target == ErrPoolExhausted
+}
+
+// WithAcquireTimeout bounds how long the query helpers wait for a free connection (0 waits as long as ctx allows)
+// WithAcquireTimeout: クエリヘルパーが空き接続を待つ時間を制限するオプション（0の場合はctxが許す限り待つ）
+// acquire: 取得する
+func WithAcquireTimeout(timeout time.Duration) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		if timeout > 0 {
+			d.acquireTimeout = timeout
+		}
+	}
+}
+
+// acquireConn takes a connection from db, giving up with a PoolExhaustedError after the acquire timeout
+// acquireConn: dbから接続を取得する関数、取得タイムアウトを過ぎるとPoolExhaustedErrorで諦める
+// The timeout covers only the wait for a connection, not the statement: タイムアウトは接続の待機のみに適用され、文の実行には適用されない
+func (d *PostgreSQLDriver) acquireConn(ctx context.Context, db *sql.DB) (*sql.Conn, error) {
+	acquireCtx, cancel := context.WithTimeout(ctx, d.acquireTimeout)
+	defer cancel()
+
+	conn, err := db.Conn(acquireCtx)
+	if err == nil {
+		return conn, nil
+	}
+	// Only our own deadline means the pool was full; the caller's context ending is reported as is
+	// 自身の期限の場合のみプールが満杯だったことを意味する、呼び出し元のコンテキストの終了はそのまま報告する
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		atomic.AddInt64(&d.poolExhaustions, 1)
+		return nil, &PoolExhaustedError{Timeout: d.acquireTimeout, Stats: d.GetConnectionStats()}
+	}
+	return nil, err
+}
+
+// queryOn runs QueryContext on db, through a bounded acquisition when an acquire timeout is set
+// queryOn: dbでQueryContextを実行する関数、取得タイムアウトが設定されていれば待機時間を制限して取得する
+// The returned release gives back the acquired connection and must be called after the rows are closed
+// 戻り値のreleaseは取得した接続を返却する関数、行を閉じた後に呼び出す必要がある
+// A non-nil tx already holds its connection and runs the statement itself
+// txがnilでなければ既に接続を保持しているため、txでそのまま文を実行する
+func (d *PostgreSQLDriver) queryOn(ctx context.Context, db *sql.DB, tx *sql.Tx, query string, args ...interface{}) (*sql.Rows, func(), error) {
+	if tx != nil {
+		rows, err := tx.QueryContext(ctx, query, args...)
+		return rows, func() {}, err
+	}
+	if d.acquireTimeout <= 0 {
+		rows, err := db.QueryContext(ctx, query, args...)
+		return rows, func() {}, err
+	}
+
+	conn, err := d.acquireConn(ctx, db)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return rows, func() { conn.Close() }, nil
+}
+
+// queryRowOn runs QueryRowContext on db, through a bounded acquisition when an acquire timeout is set
+// queryRowOn: dbでQueryRowContextを実行する関数、取得タイムアウトが設定されていれば待機時間を制限して取得する
+// The returned error is an acquisition failure; statement errors surface on the row
+// 戻り値のエラーは取得の失敗、文のエラーは行で表面化する
+// The returned release gives back the acquired connection and must be called after Scan
+// 戻り値のreleaseは取得した接続を返却する関数、Scanの後に呼び出す必要がある
+// A non-nil tx already holds its connection and runs the statement itself
+// txがnilでなければ既に接続を保持しているため、txでそのまま文を実行する
+func (d *PostgreSQLDriver) queryRowOn(ctx context.Context, db *sql.DB, tx *sql.Tx, query string, args ...interface{}) (*sql.Row, func(), error) {
+	if tx != nil {
+		return tx.QueryRowContext(ctx, query, args...), func() {}, nil
+	}
+	if d.acquireTimeout <= 0 {
+		return db.QueryRowContext(ctx, query, args...), func() {}, nil
+	}
+
+	conn, err := d.acquireConn(ctx, db)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn.QueryRowContext(ctx, query, args...), func() { conn.Close() }, nil
+}
+
+// execOn runs ExecContext on db, through a bounded acquisition when an acquire timeout is set
+// execOn: dbでExecContextを実行する関数、取得タイムアウトが設定されていれば待機時間を制限して取得する
+// A non-nil tx already holds its connection and runs the statement itself
+// txがnilでなければ既に接続を保持しているため、txでそのまま文を実行する
+func (d *PostgreSQLDriver) execOn(ctx context.Context, db *sql.DB, tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
+	if tx != nil {
+		return tx.ExecContext(ctx, query, args...)
+	}
+	if d.acquireTimeout <= 0 {
+		return db.ExecContext(ctx, query, args...)
+	}
+
+	conn, err := d.acquireConn(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.ExecContext(ctx, query, args...)
+}
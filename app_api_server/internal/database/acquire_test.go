@@ -0,0 +1,163 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	"database/sql"                  // sql: データベース操作用パッケージ
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"testing"                       // testing: テスト機能
+	"time"                          // time: 時間操作機能
+)
+
+// newSaturatedDriver returns a driver with MaxOpenConns=1 whose only connection is held by an open transaction
+// newSaturatedDriver: MaxOpenConns=1で唯一の接続を開いたトランザクションが保持しているドライバーを返す関数
+// saturated: 飽和した
+func newSaturatedDriver(t *testing.T, options ...DriverOption) (*PostgreSQLDriver, *sql.Tx) {
+	t.Helper()
+
+	driver, fake := newTestDriver(t)
+	fake.query = func(string, []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		return &fakeRows{columns: []string{"n"}, values: [][]sqldriver.Value{{int64(1)}}}, nil
+	}
+	driver.applyOptions(options)
+	driver.db.SetMaxOpenConns(1)
+
+	tx, err := driver.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	return driver, tx
+}
+
+// TestAcquireTimeoutPoolExhausted tests that every query helper reports a saturated pool as ErrPoolExhausted
+// TestAcquireTimeoutPoolExhausted: 全てのクエリヘルパーが飽和したプールをErrPoolExhaustedとして報告することをテストする関数
+func TestAcquireTimeoutPoolExhausted(t *testing.T) {
+	statements := []struct {
+		name string
+		run  func(d *PostgreSQLDriver) error
+	}{
+		{name: "Exec", run: func(d *PostgreSQLDriver) error {
+			_, err := d.ExecContext(context.Background(), "UPDATE t SET n = 1")
+			return err
+		}},
+		{name: "Query", run: func(d *PostgreSQLDriver) error {
+			rows, err := d.QueryContext(context.Background(), "SELECT n FROM t")
+			if err == nil {
+				rows.Close()
+			}
+			return err
+		}},
+		{name: "QueryRow", run: func(d *PostgreSQLDriver) error {
+			var n int64
+			return d.QueryRowContext(context.Background(), "SELECT n FROM t").Scan(&n)
+		}},
+	}
+
+	for _, statement := range statements {
+		t.Run(statement.name, func(t *testing.T) {
+			driver, tx := newSaturatedDriver(t, WithAcquireTimeout(20*time.Millisecond))
+
+			err := statement.run(driver)
+			if !errors.Is(err, ErrPoolExhausted) {
+				t.Fatalf("Expected ErrPoolExhausted, got: %v", err)
+			}
+			var exhausted *PoolExhaustedError
+			if !errors.As(err, &exhausted) {
+				t.Fatalf("Expected a *PoolExhaustedError, got: %T", err)
+			}
+			if exhausted.Timeout != 20*time.Millisecond {
+				t.Errorf("Expected timeout 20ms, got: %v", exhausted.Timeout)
+			}
+			if exhausted.Stats.InUse != 1 || exhausted.Stats.MaxOpenConnections != 1 {
+				t.Errorf("Expected the stats snapshot of the saturated pool, got: in_use=%d max_open=%d",
+					exhausted.Stats.InUse, exhausted.Stats.MaxOpenConnections)
+			}
+			if got := driver.GetConnectionStats().PoolExhaustions; got != 1 {
+				t.Errorf("Expected 1 pool exhaustion in stats, got: %d", got)
+			}
+
+			// Once the transaction ends the connection is free again: トランザクション終了後は接続が再び空く
+			tx.Rollback()
+			if err := statement.run(driver); err != nil {
+				t.Errorf("Expected no error after the transaction ended, got: %v", err)
+			}
+			// The helper returned its connection, so a second call does not block: ヘルパーが接続を返却したため2回目も待たない
+			if err := statement.run(driver); err != nil {
+				t.Errorf("Expected the connection to be released, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestAcquireTimeoutCallerDeadline tests that the caller's own deadline is not reported as pool exhaustion
+// TestAcquireTimeoutCallerDeadline: 呼び出し元自身の期限がプール枯渇として報告されないことをテストする関数
+func TestAcquireTimeoutCallerDeadline(t *testing.T) {
+	testCases := []struct {
+		name    string
+		options []DriverOption // options: ドライバーのオプション
+	}{
+		{name: "Caller deadline sooner than acquire timeout", options: []DriverOption{WithAcquireTimeout(time.Minute)}},
+		{name: "No acquire timeout", options: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, tx := newSaturatedDriver(t, tc.options...)
+			defer tx.Rollback()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			_, err := driver.ExecContext(ctx, "UPDATE t SET n = 1")
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Expected context.DeadlineExceeded, got: %v", err)
+			}
+			if errors.Is(err, ErrPoolExhausted) {
+				t.Errorf("Expected the caller's deadline not to be reported as ErrPoolExhausted, got: %v", err)
+			}
+			if got := driver.GetConnectionStats().PoolExhaustions; got != 0 {
+				t.Errorf("Expected no pool exhaustion in stats, got: %d", got)
+			}
+		})
+	}
+}
+
+// TestAcquireTimeoutReleasesOnClose tests that a pinned connection goes back to the pool as soon as the rows are closed or scanned
+// TestAcquireTimeoutReleasesOnClose: 取得した接続が、行を閉じるかScanした時点で直ちにプールへ返却されることをテストする関数
+func TestAcquireTimeoutReleasesOnClose(t *testing.T) {
+	statements := []struct {
+		name string
+		run  func(t *testing.T, d *PostgreSQLDriver)
+	}{
+		{name: "Query", run: func(t *testing.T, d *PostgreSQLDriver) {
+			rows, err := d.QueryContext(context.Background(), "SELECT n FROM t")
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if inUse := d.GetConnectionStats().InUse; inUse != 1 {
+				t.Errorf("Expected the connection in use while the rows are open, got: in_use=%d", inUse)
+			}
+			if err := rows.Close(); err != nil {
+				t.Fatalf("Expected no error from Close, got: %v", err)
+			}
+		}},
+		{name: "QueryRow", run: func(t *testing.T, d *PostgreSQLDriver) {
+			var n int64
+			if err := d.QueryRowContext(context.Background(), "SELECT n FROM t").Scan(&n); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+		}},
+	}
+
+	for _, statement := range statements {
+		t.Run(statement.name, func(t *testing.T) {
+			driver, tx := newSaturatedDriver(t, WithAcquireTimeout(time.Second))
+			tx.Rollback()
+
+			statement.run(t, driver)
+			if inUse := driver.GetConnectionStats().InUse; inUse != 0 {
+				t.Errorf("Expected the connection to be returned before the helper returned, got: in_use=%d", inUse)
+			}
+		})
+	}
+}
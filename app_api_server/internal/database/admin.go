@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: 挿入の結果
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"os"           // os: operating system（オペレーティングシステム）、環境変数の読み込み
+	"strings"      // strings: 文字列操作
+
+	"golang.org/x/crypto/bcrypt" // bcrypt: パスワードハッシュ関数
+)
+
+// maxBcryptPasswordBytes is the longest password bcrypt hashes without truncation
+// maxBcryptPasswordBytes: bcryptが切り捨てずにハッシュ化できるパスワードの最大バイト数
+// truncation: 切り捨て
+const maxBcryptPasswordBytes = 72
+
+// insertAdminUserQuery inserts the admin once; an existing row with the same email is left untouched
+// insertAdminUserQuery: 管理者を1回だけ挿入するクエリ、同じメールアドレスの既存行は変更しない
+// untouched: 変更されない
+const insertAdminUserQuery = `
+	INSERT INTO app.users (email, password_hash, first_name, last_name, is_active, is_verified)
+	VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), TRUE, TRUE)
+	ON CONFLICT (email) DO NOTHING`
+
+// AdminBootstrapConfig describes the administrator created when the database is first set up
+// AdminBootstrapConfig: データベースの初回構築時に作成する管理者の設定
+// bootstrap: 初期構築、administrator: 管理者
+type AdminBootstrapConfig struct {
+	Email     string // email: メールアドレス
+	Password  string // password: パスワード（ログに出力しない）
+	FirstName string // first name: 名
+	LastName  string // last name: 姓
+}
+
+// String omits the password so the config can be logged safely
+// String: 設定を安全にログ出力できるよう、パスワードを省いて整形する関数
+func (c AdminBootstrapConfig) String() string {
+	return fmt.Sprintf("AdminBootstrapConfig{Email: %s, Password: %s, FirstName: %s, LastName: %s}",
+		c.Email, redactedMask, c.FirstName, c.LastName)
+}
+
+// GoString makes %#v use the redacted form as well
+// GoString: %#vでも伏せ字の形式を使うための関数
+func (c AdminBootstrapConfig) GoString() string {
+	return c.String()
+}
+
+// Validate checks that the email and password are usable
+// Validate: メールアドレスとパスワードが使用可能かを検証する関数
+func (c AdminBootstrapConfig) Validate() error {
+	if c.Email == "" || !strings.Contains(c.Email, "@") {
+		return fmt.Errorf("admin email %q is not a valid email address", c.Email)
+	}
+	if c.Password == "" {
+		return fmt.Errorf("admin password is required")
+	}
+	if len(c.Password) > maxBcryptPasswordBytes {
+		return fmt.Errorf("admin password must be at most %d bytes", maxBcryptPasswordBytes)
+	}
+	return nil
+}
+
+// LoadAdminBootstrapConfig reads ADMIN_EMAIL, ADMIN_PASSWORD, ADMIN_FIRST_NAME and ADMIN_LAST_NAME
+// LoadAdminBootstrapConfig: ADMIN_EMAIL、ADMIN_PASSWORD、ADMIN_FIRST_NAME、ADMIN_LAST_NAMEを読み込む関数
+// It returns nil when neither ADMIN_EMAIL nor ADMIN_PASSWORD is set, and an error when only one is
+// どちらも未設定の場合はnilを返し、片方だけ設定されている場合はエラーを返す
+func LoadAdminBootstrapConfig() (*AdminBootstrapConfig, error) {
+	email := strings.TrimSpace(os.Getenv("ADMIN_EMAIL"))
+	password := os.Getenv("ADMIN_PASSWORD")
+	if email == "" && password == "" {
+		return nil, nil // bootstrap disabled: 初期構築は無効
+	}
+	if email == "" || password == "" {
+		return nil, fmt.Errorf("ADMIN_EMAIL and ADMIN_PASSWORD must be set together")
+	}
+
+	config := &AdminBootstrapConfig{
+		Email:     email,
+		Password:  password,
+		FirstName: os.Getenv("ADMIN_FIRST_NAME"),
+		LastName:  os.Getenv("ADMIN_LAST_NAME"),
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// EnsureAdminUser inserts the administrator into app.users unless a user with the same email exists
+// EnsureAdminUser: 同じメールアドレスのユーザーが存在しない場合に管理者をapp.usersへ挿入する関数
+// It is idempotent and stores only a bcrypt hash of the password: 冪等であり、パスワードはbcryptハッシュのみを保存する
+// When ctx carries a transaction (see ContextWithTx), the insert runs in it: ctxがトランザクションを持つ場合、挿入はその中で実行する
+// idempotent: 冪等な（何度実行しても同じ結果）
+func EnsureAdminUser(ctx context.Context, driver *PostgreSQLDriver, config AdminBootstrapConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(config.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash admin password: %w", err) // hash: ハッシュ化する
+	}
+
+	// Inside a caller's WithTransaction the insert joins it: 呼び出し元のWithTransaction内では、挿入はそれに参加する
+	var result sql.Result
+	if tx, ok := TxFromContext(ctx); ok {
+		result, err = tx.ExecContext(ctx, insertAdminUserQuery, config.Email, string(hash), config.FirstName, config.LastName)
+	} else {
+		result, err = driver.ExecContext(ctx, insertAdminUserQuery, config.Email, string(hash), config.FirstName, config.LastName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create admin user %s: %w", config.Email, err)
+	}
+
+	created, err := result.RowsAffected()
+	switch {
+	case err != nil:
+		// The insert succeeded; only the count is unknown: 挿入は成功しており、件数のみ不明
+	case created > 0:
+		driver.logger.Info(fmt.Sprintf("Created admin user: %s", config.Email), "email", config.Email) // created: 作成された
+	default:
+		driver.logger.Info(fmt.Sprintf("Admin user already exists: %s", config.Email), "email", config.Email) // already exists: 既に存在する
+	}
+	return nil
+}
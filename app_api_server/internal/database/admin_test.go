@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"fmt"                           // fmt: format（フォーマット）、文字列フォーマット機能
+	"strings"                       // strings: 文字列操作
+	"testing"                       // testing: テスト機能
+
+	"golang.org/x/crypto/bcrypt" // bcrypt: パスワードハッシュ関数
+)
+
+// TestEnsureAdminUser tests that the admin is inserted with a bcrypt hash and the password never reaches the log
+// TestEnsureAdminUser: 管理者がbcryptハッシュで挿入され、パスワードがログに出ないことをテストする関数
+func TestEnsureAdminUser(t *testing.T) {
+	logs := captureLog(t)
+	driver, fake := newTestDriver(t)
+
+	inserted := map[string]bool{}
+	var hashes []string
+	fake.exec = func(query string, args []sqldriver.NamedValue) (sqldriver.Result, error) {
+		email := args[0].Value.(string)
+		hashes = append(hashes, args[1].Value.(string))
+		if inserted[email] {
+			return sqldriver.RowsAffected(0), nil // ON CONFLICT DO NOTHING
+		}
+		inserted[email] = true
+		return sqldriver.RowsAffected(1), nil
+	}
+
+	config := AdminBootstrapConfig{Email: "admin@siftapp.com", Password: "s3cret-admin-pass", FirstName: "Admin", LastName: "User"}
+	for i := 0; i < 2; i++ {
+		if err := EnsureAdminUser(context.Background(), driver, config); err != nil {
+			t.Fatalf("Expected no error on run %d, got: %v", i+1, err)
+		}
+	}
+
+	statements := fake.executed()
+	if len(statements) != 2 || !strings.Contains(statements[0], "ON CONFLICT (email) DO NOTHING") {
+		t.Fatalf("Expected two idempotent inserts, got: %v", statements)
+	}
+	for _, hash := range hashes {
+		if hash == config.Password {
+			t.Fatal("Expected the password to be hashed, got the plain text")
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(config.Password)); err != nil {
+			t.Errorf("Expected a bcrypt hash of the password, got: %v", err)
+		}
+	}
+
+	output := logs.String()
+	if !strings.Contains(output, "Created admin user: admin@siftapp.com") || !strings.Contains(output, "Admin user already exists: admin@siftapp.com") {
+		t.Errorf("Expected created and already exists logs, got: %q", output)
+	}
+	if strings.Contains(output, config.Password) {
+		t.Errorf("Expected password not to be logged, got: %q", output)
+	}
+}
+
+// TestAdminBootstrapConfigValidate tests validation of the email and password
+// TestAdminBootstrapConfigValidate: メールアドレスとパスワードの検証をテストする関数
+func TestAdminBootstrapConfigValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      AdminBootstrapConfig
+		expectError bool
+	}{
+		{name: "Valid", config: AdminBootstrapConfig{Email: "admin@siftapp.com", Password: "pass"}},
+		{name: "Missing email", config: AdminBootstrapConfig{Password: "pass"}, expectError: true},
+		{name: "Malformed email", config: AdminBootstrapConfig{Email: "admin", Password: "pass"}, expectError: true},
+		{name: "Missing password", config: AdminBootstrapConfig{Email: "admin@siftapp.com"}, expectError: true},
+		{name: "Password too long", config: AdminBootstrapConfig{Email: "admin@siftapp.com", Password: strings.Repeat("p", 73)}, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectError && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestLoadAdminBootstrapConfig tests that ADMIN_EMAIL and ADMIN_PASSWORD must be set together
+// TestLoadAdminBootstrapConfig: ADMIN_EMAILとADMIN_PASSWORDを一緒に設定する必要があることをテストする関数
+func TestLoadAdminBootstrapConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		email       string
+		password    string
+		expectNil   bool
+		expectError bool
+	}{
+		{name: "Neither set", expectNil: true},
+		{name: "Both set", email: "admin@siftapp.com", password: "pass"},
+		{name: "Only email", email: "admin@siftapp.com", expectError: true},
+		{name: "Only password", password: "pass", expectError: true},
+		{name: "Invalid email", email: "admin", password: "pass", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("ADMIN_EMAIL", tc.email)
+			t.Setenv("ADMIN_PASSWORD", tc.password)
+			t.Setenv("ADMIN_FIRST_NAME", "Admin")
+			t.Setenv("ADMIN_LAST_NAME", "User")
+
+			config, err := LoadAdminBootstrapConfig()
+			if tc.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if tc.expectNil {
+				if config != nil {
+					t.Errorf("Expected nil config, got: %v", config)
+				}
+				return
+			}
+			if config.Email != tc.email || config.Password != tc.password || config.FirstName != "Admin" || config.LastName != "User" {
+				t.Errorf("Unexpected config: %v", config)
+			}
+		})
+	}
+}
+
+// TestAdminBootstrapConfigString tests that formatting never includes the password
+// TestAdminBootstrapConfigString: 整形結果にパスワードが含まれないことをテストする関数
+func TestAdminBootstrapConfigString(t *testing.T) {
+	config := AdminBootstrapConfig{Email: "admin@siftapp.com", Password: "s3cret-admin-pass"}
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		if output := fmt.Sprintf(format, config); strings.Contains(output, config.Password) {
+			t.Errorf("Expected %s not to include the password, got: %s", format, output)
+		}
+	}
+}
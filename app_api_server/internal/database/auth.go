@@ -0,0 +1,169 @@
+package database
+
+import (
+	"context"             // context: コンテキスト、処理の文脈情報
+	"database/sql/driver" // driver: SQLドライバーインターフェース
+	"fmt"                 // fmt: format（フォーマット）、文字列フォーマット機能
+	"sync"                // sync: 同期処理、トークンの排他制御
+	"time"                // time: 時間操作機能
+)
+
+// Authentication methods accepted in DatabaseConfig.AuthMethod
+// DatabaseConfig.AuthMethodで受け付ける認証方式
+// authentication: 認証、methods: 方式（複数形）
+const (
+	AuthMethodPassword = "password" // password: 静的なパスワードで認証する（デフォルト）
+	AuthMethodAWSIAM   = "aws-iam"  // aws-iam: AWS RDSのIAM認証トークンで認証する
+
+	AuthMethodCredentialProvider = "credential-provider" // credential-provider: CredentialProvider（Vaultなど）から認証情報を取得する
+)
+
+// validAuthMethods lists the accepted authentication methods
+// validAuthMethods: 受け付ける認証方式の一覧
+var validAuthMethods = []string{AuthMethodPassword, AuthMethodAWSIAM, AuthMethodCredentialProvider}
+
+// RDS IAM auth tokens expire 15 minutes after they are issued, but a connection that authenticated stays open.
+// The token is minted on Connect and Reconnect and again by the pool's connector once it is about to expire,
+// so every new connection dials with a valid token; pooled connections are still recycled before the lifetime
+// RDSのIAM認証トークンは発行から15分で失効するが、認証済みの接続は開いたままになる。
+// トークンはConnectとReconnectで発行され、失効が近づくとプールのコネクターが再発行するため、
+// 新しい接続は常に有効なトークンでダイヤルする、プールの接続は引き続き有効期間の前に入れ替える
+// expire: 失効する、issued: 発行された、recycled: 入れ替えられた
+const (
+	iamTokenLifetime   = 15 * time.Minute               // token lifetime: トークンの有効期間
+	iamTokenRenewAfter = iamTokenLifetime - time.Minute // renew after: この経過時間以降の接続では新しいトークンを発行する
+	iamConnMaxLifetime = iamTokenLifetime - time.Minute // connection lifetime cap: IAM認証時の接続寿命の上限
+)
+
+// AuthTokenProvider mints a short-lived token used in place of the password
+// AuthTokenProvider: パスワードの代わりに使う短期間有効なトークンを発行する関数型
+// mints: 発行する、short-lived: 短期間有効な
+// The AWS implementation lives in the awsiam sub-package so non-AWS builds do not depend on the SDK
+// AWSの実装はawsiamサブパッケージにあり、AWSを使わないビルドはSDKに依存しない
+type AuthTokenProvider func(ctx context.Context, config DatabaseConfig) (string, error)
+
+// WithAuthTokenProvider sets the token provider used when AuthMethod is aws-iam
+// WithAuthTokenProvider: AuthMethodがaws-iamの場合に使うトークン発行関数を設定するオプション
+func WithAuthTokenProvider(provider AuthTokenProvider) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		d.authTokenProvider = provider
+	}
+}
+
+// connectionConfig returns the configuration to dial with and the credential lease (0 if it does not expire)
+// connectionConfig: 接続に使う設定と認証情報のリース期間（失効しない場合は0）を返す関数
+// Tokens and provided credentials are only placed in a copy so GetConfig and logs never see them
+// トークンや取得した認証情報はコピーにのみ設定し、GetConfigやログには現れない
+func (d *PostgreSQLDriver) connectionConfig(ctx context.Context) (*DatabaseConfig, time.Duration, error) {
+	config := d.config.Load()
+	switch config.AuthMethod {
+	case AuthMethodAWSIAM:
+		config, err := d.iamConfig(ctx)
+		return config, 0, err // the pool's connector renews the token instead: 代わりにプールのコネクターがトークンを更新する
+	case AuthMethodCredentialProvider:
+		return d.providedConfig(ctx)
+	default:
+		return config, 0, nil
+	}
+}
+
+// iamConfig returns a copy of the configuration with a freshly minted token as the password
+// iamConfig: 新たに発行したトークンをパスワードに設定した設定のコピーを返す関数
+func (d *PostgreSQLDriver) iamConfig(ctx context.Context) (*DatabaseConfig, error) {
+	if d.authTokenProvider == nil {
+		return nil, fmt.Errorf("aws-iam authentication requires an auth token provider (see WithAuthTokenProvider)") // requires: 必要とする
+	}
+
+	active := d.config.Load()
+	token, err := d.authTokenProvider(ctx, *active)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate auth token: %w", err) // generate: 生成する
+	}
+	if token == "" {
+		return nil, fmt.Errorf("auth token provider returned an empty token") // empty: 空の
+	}
+
+	config := *active
+	config.Password = token
+	return &config, nil
+}
+
+// iamConnector opens every connection with a token that has not expired, minting a new one when it is due
+// iamConnector: 失効していないトークンで接続を開き、更新時期になると新しいトークンを発行するコネクター
+// due: 期限が来た
+type iamConnector struct {
+	driver driver.Driver     // driver: 接続文字列から接続を開くドライバー
+	config DatabaseConfig    // config: 単一ホストの設定、Passwordは発行済みのトークン
+	mint   AuthTokenProvider // mint: トークン発行関数
+	now    func() time.Time  // now: 現在時刻を返す関数
+
+	mu       sync.Mutex // mu: トークンの排他制御
+	token    string     // token: 現在のトークン
+	issuedAt time.Time  // issued at: 現在のトークンの発行時刻
+}
+
+// newIAMConnector returns a connector seeded with the token config was opened with
+// newIAMConnector: configを開いた時のトークンを初期値とするコネクターを返す関数
+// seeded: 初期値を与えられた
+func (d *PostgreSQLDriver) newIAMConnector(drv driver.Driver, config *DatabaseConfig) *iamConnector {
+	connector := &iamConnector{driver: drv, config: *config, mint: d.authTokenProvider, now: d.now, token: config.Password, issuedAt: d.now()}
+	connector.config.Password = "" // the provider never sees a token: 発行関数にトークンを渡さない
+	return connector
+}
+
+// currentToken returns the current token, or mints a new one once it is due for renewal
+// currentToken: 現在のトークンを返す関数、更新時期を過ぎていれば新しいトークンを発行する
+func (c *iamConnector) currentToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.now().Sub(c.issuedAt) < iamTokenRenewAfter {
+		return c.token, nil
+	}
+	token, err := c.mint(ctx, c.config)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate auth token: %w", err)
+	}
+	if token == "" {
+		return "", fmt.Errorf("auth token provider returned an empty token")
+	}
+	c.token, c.issuedAt = token, c.now()
+	return token, nil
+}
+
+// Connect implements driver.Connector, building the connection string with the current token
+// Connect: driver.Connectorの実装、現在のトークンで接続文字列を組み立てる
+func (c *iamConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	token, err := c.currentToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	config := c.config
+	config.Password = token
+
+	connector, err := openConnector(c.driver, config.BuildConnectionString())
+	if err != nil {
+		return nil, config.redactError(err)
+	}
+	conn, err := connector.Connect(ctx)
+	if err != nil {
+		return nil, config.redactError(err)
+	}
+	return conn, nil
+}
+
+// Driver implements driver.Connector
+func (c *iamConnector) Driver() driver.Driver { return c.driver }
+
+// connMaxLifetime returns the pool connection lifetime, capped below the token lifetime for aws-iam
+// connMaxLifetime: 接続プールの接続寿命を返す関数、aws-iamの場合はトークンの有効期間未満に制限する
+// capped: 上限を設けた
+func connMaxLifetime(config *DatabaseConfig, lifetime time.Duration) time.Duration {
+	if config.AuthMethod != AuthMethodAWSIAM {
+		return lifetime
+	}
+	if lifetime <= 0 || lifetime > iamConnMaxLifetime {
+		return iamConnMaxLifetime // 0 means unlimited, which a token cannot outlive: 0は無制限を意味し、トークンより長く生きられない
+	}
+	return lifetime
+}
@@ -0,0 +1,376 @@
+package database
+
+import (
+	"context"             // context: コンテキスト
+	"database/sql"        // sql: データベース操作用パッケージ
+	"database/sql/driver" // driver: SQLドライバーインターフェース
+	"errors"              // errors: エラー操作
+	"fmt"                 // fmt: フォーマット
+	"os"                  // os: 環境変数操作
+	"strings"             // strings: 文字列操作
+	"sync"                // sync: 同期処理
+	"testing"             // testing: テストフレームワーク
+	"time"                // time: 時間操作
+)
+
+// newIAMTestDriver creates an aws-iam driver whose pools are fakes and records connection strings
+// newIAMTestDriver: フェイクのプールを使い接続文字列を記録するaws-iamドライバーを作成する関数
+func newIAMTestDriver(t *testing.T, provider AuthTokenProvider) (*PostgreSQLDriver, *[]string) {
+	t.Helper()
+
+	driver, err := NewPostgreSQLDriverWithConfig(&DatabaseConfig{
+		Host:       "mydb.cluster-abc.ap-northeast-1.rds.amazonaws.com",
+		Port:       5432,
+		User:       "iam_user",
+		Database:   "testdb",
+		SSLMode:    "require",
+		AuthMethod: AuthMethodAWSIAM,
+	}, WithAuthTokenProvider(provider), WithResolver(fakeResolver{}))
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	var connectionStrings []string
+	driver.openDB = func(connectionString string) (*sql.DB, error) {
+		connectionStrings = append(connectionStrings, connectionString)
+		_, db := newFakeDB()
+		return db, nil
+	}
+	return driver, &connectionStrings
+}
+
+// TestIAMConnectMintsFreshTokens tests that Connect and Reconnect each use a new token
+// TestIAMConnectMintsFreshTokens: ConnectとReconnectがそれぞれ新しいトークンを使うことをテストする関数
+func TestIAMConnectMintsFreshTokens(t *testing.T) {
+	calls := 0
+	provider := func(ctx context.Context, config DatabaseConfig) (string, error) {
+		calls++
+		if config.User != "iam_user" || config.Port != 5432 {
+			t.Errorf("Expected provider to receive the driver config, got: %v", config)
+		}
+		return fmt.Sprintf("token-%d", calls), nil
+	}
+	driver, connectionStrings := newIAMTestDriver(t, provider)
+	defer driver.Close()
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected connect to succeed, got: %v", err)
+	}
+	if err := driver.Reconnect(); err != nil {
+		t.Fatalf("Expected reconnect to succeed, got: %v", err)
+	}
+
+	if len(*connectionStrings) != 2 {
+		t.Fatalf("Expected 2 pools to be opened, got: %d", len(*connectionStrings))
+	}
+	for i, connectionString := range *connectionStrings {
+		expected := fmt.Sprintf("password=token-%d ", i+1)
+		if !strings.Contains(connectionString, expected) {
+			t.Errorf("Expected connection string %d to contain %q, got: %s", i, expected, connectionString)
+		}
+	}
+
+	if driver.GetConfig().Password != "" {
+		t.Errorf("Expected token to stay out of the driver config, got: %s", driver.GetConfig().Password)
+	}
+	if stats := driver.GetDB().Stats(); stats.MaxOpenConnections != defaultMaxOpenConns {
+		t.Errorf("Expected pool to be configured, got max open: %d", stats.MaxOpenConnections)
+	}
+}
+
+// TestIAMConnectErrors tests token provider failures during Connect
+// TestIAMConnectErrors: Connect中のトークン発行失敗をテストする関数
+func TestIAMConnectErrors(t *testing.T) {
+	errNoCredentials := errors.New("no credentials in chain")
+
+	testCases := []struct {
+		name     string
+		provider AuthTokenProvider
+		errorMsg string
+	}{
+		{
+			name:     "missing provider",
+			provider: nil,
+			errorMsg: "requires an auth token provider",
+		},
+		{
+			name: "provider failure",
+			provider: func(context.Context, DatabaseConfig) (string, error) {
+				return "", errNoCredentials
+			},
+			errorMsg: "failed to generate auth token",
+		},
+		{
+			name: "empty token",
+			provider: func(context.Context, DatabaseConfig) (string, error) {
+				return "", nil
+			},
+			errorMsg: "empty token",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, connectionStrings := newIAMTestDriver(t, tc.provider)
+
+			err := driver.Connect()
+			if err == nil || !strings.Contains(err.Error(), tc.errorMsg) {
+				t.Errorf("Expected error containing %q, got: %v", tc.errorMsg, err)
+			}
+			if len(*connectionStrings) != 0 {
+				t.Errorf("Expected no pool to be opened, got: %d", len(*connectionStrings))
+			}
+			if driver.IsOpen() {
+				t.Error("Expected driver not to be open")
+			}
+		})
+	}
+
+	driver, _ := newIAMTestDriver(t, func(context.Context, DatabaseConfig) (string, error) {
+		return "", errNoCredentials
+	})
+	if err := driver.Connect(); !errors.Is(err, errNoCredentials) {
+		t.Errorf("Expected provider error to be wrapped, got: %v", err)
+	}
+}
+
+// TestIAMTokenRedactedFromErrors tests that a token echoed in an open error is masked
+// TestIAMTokenRedactedFromErrors: 接続エラーに含まれたトークンが伏せ字になることをテストする関数
+func TestIAMTokenRedactedFromErrors(t *testing.T) {
+	driver, _ := newIAMTestDriver(t, func(context.Context, DatabaseConfig) (string, error) {
+		return "secret-token", nil
+	})
+	driver.openDB = func(connectionString string) (*sql.DB, error) {
+		return nil, fmt.Errorf("bad connection string: %s", connectionString)
+	}
+
+	err := driver.Connect()
+	if err == nil {
+		t.Fatal("Expected connect to fail")
+	}
+	if strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("Expected token to be redacted, got: %v", err)
+	}
+}
+
+// dsnRecordingDriver is a fake driver that records the connection string of every connection it opens
+// dsnRecordingDriver: 開く接続ごとに接続文字列を記録するフェイクドライバー
+type dsnRecordingDriver struct {
+	fakeDriver
+	mu   *sync.Mutex // mu: 記録の排他制御
+	dsns *[]string   // dsns: 記録された接続文字列
+}
+
+// OpenConnector records connectionString and connects to the fake database
+// OpenConnector: connectionStringを記録し、フェイクデータベースに接続する
+func (d dsnRecordingDriver) OpenConnector(connectionString string) (driver.Connector, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	*d.dsns = append(*d.dsns, connectionString)
+	return d.db, nil
+}
+
+// dsnRecordingConnector is the connector of a pool opened on a dsnRecordingDriver
+// dsnRecordingConnector: dsnRecordingDriver上で開いたプールのコネクター
+type dsnRecordingConnector struct {
+	*fakeDB
+	driver dsnRecordingDriver // driver: 記録するドライバー
+}
+
+// Driver implements driver.Connector
+func (c dsnRecordingConnector) Driver() driver.Driver { return c.driver }
+
+// TestIAMConnectorRenewsExpiredToken tests that connections opened after the token expired use a new token
+// TestIAMConnectorRenewsExpiredToken: トークン失効後に開いた接続が新しいトークンを使うことをテストする関数
+func TestIAMConnectorRenewsExpiredToken(t *testing.T) {
+	calls := 0
+	driver, _ := newIAMTestDriver(t, func(ctx context.Context, config DatabaseConfig) (string, error) {
+		calls++
+		if config.Password != "" {
+			t.Errorf("Expected provider not to receive a token, got: %s", config.Password)
+		}
+		return fmt.Sprintf("token-%d", calls), nil
+	})
+
+	var mu sync.Mutex
+	var dsns []string
+	driver.openDB = func(string) (*sql.DB, error) {
+		fake := &fakeDB{}
+		return sql.OpenDB(dsnRecordingConnector{fakeDB: fake, driver: dsnRecordingDriver{fakeDriver: fakeDriver{db: fake}, mu: &mu, dsns: &dsns}}), nil
+	}
+
+	// Manual clock: 手動の時計
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	driver.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return current
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		current = current.Add(d)
+		mu.Unlock()
+	}
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected connect to succeed, got: %v", err)
+	}
+	defer driver.Close()
+
+	// Holding a connection forces the pool to open a new one for the next caller
+	// 接続を保持しておくと、次の呼び出し元にはプールが新しい接続を開く
+	var held []*sql.Conn
+	defer func() {
+		for _, conn := range held {
+			conn.Close()
+		}
+	}()
+	lastDSN := func() string {
+		t.Helper()
+		conn, err := driver.pool().Conn(context.Background())
+		if err != nil {
+			t.Fatalf("Expected a new connection, got: %v", err)
+		}
+		held = append(held, conn)
+		mu.Lock()
+		defer mu.Unlock()
+		return dsns[len(dsns)-1]
+	}
+
+	lastDSN() // the connection Connect pinged with: Connectがpingに使った接続
+	advance(iamTokenRenewAfter / 2)
+	if dsn := lastDSN(); !strings.Contains(dsn, "password=token-1 ") || calls != 1 {
+		t.Errorf("Expected the first token to be reused before it is due, got %d calls and: %s", calls, dsn)
+	}
+
+	advance(iamTokenLifetime)
+	if dsn := lastDSN(); !strings.Contains(dsn, "password=token-2 ") {
+		t.Errorf("Expected a new token after %s, got: %s", iamTokenLifetime, dsn)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 tokens to be minted, got: %d", calls)
+	}
+}
+
+// TestConnMaxLifetime tests the lifetime clamp applied for aws-iam
+// TestConnMaxLifetime: aws-iamで適用される接続寿命の制限をテストする関数
+func TestConnMaxLifetime(t *testing.T) {
+	password := &DatabaseConfig{AuthMethod: AuthMethodPassword}
+	iam := &DatabaseConfig{AuthMethod: AuthMethodAWSIAM}
+
+	testCases := []struct {
+		name     string
+		config   *DatabaseConfig
+		lifetime time.Duration
+		expected time.Duration
+	}{
+		{"password keeps lifetime", password, time.Hour, time.Hour},
+		{"password keeps unlimited", password, 0, 0},
+		{"iam keeps short lifetime", iam, defaultConnMaxLifetime, defaultConnMaxLifetime},
+		{"iam clamps long lifetime", iam, time.Hour, iamConnMaxLifetime},
+		{"iam clamps unlimited", iam, 0, iamConnMaxLifetime},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := connMaxLifetime(tc.config, tc.lifetime); got != tc.expected {
+				t.Errorf("Expected %s, got: %s", tc.expected, got)
+			}
+		})
+	}
+
+	if iamConnMaxLifetime >= iamTokenLifetime {
+		t.Errorf("Expected IAM lifetime cap below the token lifetime, got: %s", iamConnMaxLifetime)
+	}
+}
+
+// TestAuthMethodConfig tests loading and validating the auth method
+// TestAuthMethodConfig: 認証方式の読み込みと検証をテストする関数
+func TestAuthMethodConfig(t *testing.T) {
+	envVars := []string{"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME", "DB_SSL_MODE", "DB_AUTH_METHOD"}
+	originalValues := make(map[string]string)
+	for _, envVar := range envVars {
+		originalValues[envVar] = os.Getenv(envVar)
+	}
+	defer func() {
+		for _, envVar := range envVars {
+			os.Setenv(envVar, originalValues[envVar])
+		}
+	}()
+
+	testCases := []struct {
+		name        string
+		envValues   map[string]string
+		expectError bool
+		expected    string
+	}{
+		{
+			name:      "default is password",
+			envValues: map[string]string{"DB_USER": "u", "DB_PASSWORD": "p", "DB_NAME": "d"},
+			expected:  AuthMethodPassword,
+		},
+		{
+			name:      "aws-iam without password",
+			envValues: map[string]string{"DB_USER": "u", "DB_NAME": "d", "DB_AUTH_METHOD": " AWS-IAM "},
+			expected:  AuthMethodAWSIAM,
+		},
+		{
+			name:        "password method requires password",
+			envValues:   map[string]string{"DB_USER": "u", "DB_NAME": "d", "DB_AUTH_METHOD": "password"},
+			expectError: true,
+		},
+		{
+			name:        "unknown method",
+			envValues:   map[string]string{"DB_USER": "u", "DB_PASSWORD": "p", "DB_NAME": "d", "DB_AUTH_METHOD": "kerberos"},
+			expectError: true,
+		},
+		{
+			name:        "aws-iam requires ssl",
+			envValues:   map[string]string{"DB_USER": "u", "DB_NAME": "d", "DB_AUTH_METHOD": "aws-iam", "DB_SSL_MODE": "disable"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, envVar := range envVars {
+				os.Unsetenv(envVar)
+			}
+			for key, value := range tc.envValues {
+				os.Setenv(key, value)
+			}
+
+			config, err := LoadDatabaseConfig()
+			if err == nil {
+				_, err = NewPostgreSQLDriverWithConfig(config)
+			}
+
+			if tc.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if config.AuthMethod != tc.expected {
+				t.Errorf("Expected auth method %s, got: %s", tc.expected, config.AuthMethod)
+			}
+		})
+	}
+}
+
+// TestAuthMethodDefaultForProgrammaticConfig tests that an empty AuthMethod means password
+// TestAuthMethodDefaultForProgrammaticConfig: 空のAuthMethodがpasswordとして扱われることをテストする関数
+func TestAuthMethodDefaultForProgrammaticConfig(t *testing.T) {
+	driver := newFakeConnectingDriver(t)
+
+	if method := driver.GetConfig().AuthMethod; method != AuthMethodPassword {
+		t.Errorf("Expected auth method %s, got: %s", AuthMethodPassword, method)
+	}
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected connect to succeed, got: %v", err)
+	}
+	driver.Close()
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"sync/atomic"  // atomic: アトミック操作
+)
+
+// ExecWithReconnect executes a statement, reconnecting and retrying once after a connection-level error
+// ExecWithReconnect: 文を実行し、接続レベルのエラーの後は再接続して1回だけ再試行する関数
+// connection-level: 接続レベルの
+// When ctx carries a transaction the statement runs on it and is not retried
+// ctxがトランザクションを持つ場合、文はそのトランザクションで実行され、再試行されない
+func (d *PostgreSQLDriver) ExecWithReconnect(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	tx, _ := TxFromContext(ctx)
+	var result sql.Result
+	err := d.withReconnect(ctx, query, func() error {
+		var err error
+		result, err = d.execIn(ctx, tx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+// QueryWithReconnect executes a query, reconnecting and retrying once after a connection-level error
+// QueryWithReconnect: クエリを実行し、接続レベルのエラーの後は再接続して1回だけ再試行する関数
+func (d *PostgreSQLDriver) QueryWithReconnect(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	tx, _ := TxFromContext(ctx)
+	var rows *Rows
+	err := d.withReconnect(ctx, query, func() error {
+		var err error
+		rows, err = d.queryIn(ctx, tx, query, args...)
+		return err
+	})
+	return rows, err
+}
+
+// QueryRowWithReconnect executes a single-row query, reconnecting and retrying once after a connection-level error
+// QueryRowWithReconnect: 単一行クエリを実行し、接続レベルのエラーの後は再接続して1回だけ再試行する関数
+func (d *PostgreSQLDriver) QueryRowWithReconnect(ctx context.Context, query string, args ...interface{}) *Row {
+	tx, _ := TxFromContext(ctx)
+	var row *Row
+	err := d.withReconnect(ctx, query, func() error {
+		if row != nil && row.cancel != nil {
+			row.cancel() // release the failed attempt: 失敗した試行を解放する
+		}
+		row = d.queryRowIn(ctx, tx, query, args...)
+		return row.Err()
+	})
+	if err != nil {
+		if row.cancel != nil {
+			row.cancel()
+		}
+		return &Row{err: err} // keeps a reconnect failure in the message: 再接続の失敗もメッセージに残す
+	}
+	return row
+}
+
+// withReconnect runs op and, after a connection-level error, reconnects and runs it once more
+// withReconnect: opを実行し、接続レベルのエラーの後は再接続してもう1回だけ実行する関数
+// Statement errors are returned as is, and so is any error when ctx carries a transaction,
+// because a retry outside it would lose the transaction's earlier statements
+// 文レベルのエラーはそのまま返す、ctxがトランザクションを持つ場合も同様
+// （トランザクションの外での再試行はそれまでの文を失うため）
+// Transactions on other goroutines do not stop the retry: 他のゴルーチンのトランザクションは再試行を妨げない
+func (d *PostgreSQLDriver) withReconnect(ctx context.Context, query string, op func() error) error {
+	before := d.pool()
+	err := op()
+	if err == nil || !IsTransientError(err) || ctx.Err() != nil {
+		return err
+	}
+	if _, inTx := TxFromContext(ctx); inTx {
+		redacted := d.config.Load().redactError(err)
+		d.logger.Warn(fmt.Sprintf("connection-level error not retried inside a transaction: %v", redacted), "error", redacted.Error())
+		return err
+	}
+
+	reconnect := func() error {
+		d.logger.Warn("connection-level error, reconnecting before retrying once")
+		return d.Reconnect()
+	}
+	if reconnectErr := d.reconnectOnce(before, reconnect); reconnectErr != nil {
+		return fmt.Errorf("%w (reconnect failed: %v)", err, reconnectErr)
+	}
+
+	if err := op(); err != nil {
+		return err
+	}
+	atomic.AddInt64(&d.reconnectRecoveries, 1)
+	d.logger.Info(fmt.Sprintf("Recovered from a connection-level error by reconnecting: %s", truncateQuery(query)), "query", truncateQuery(query)) // recovered: 回復した
+	return nil
+}
+
+// reconnectOnce runs reconnect unless another caller already replaced the failed pool
+// reconnectOnce: 別の呼び出し元が既に失敗したプールを入れ替えていなければreconnectを実行する関数
+// Callers failing together on one pool share a single reconnect, whether they retry a statement or react to a
+// server restart: 同じプールで同時に失敗した呼び出し元は、文の再試行でもサーバー再起動への対応でも1回の再接続を共有する
+func (d *PostgreSQLDriver) reconnectOnce(failed *sql.DB, reconnect func() error) error {
+	d.autoReconnectMu.Lock()
+	defer d.autoReconnectMu.Unlock()
+
+	if current := d.pool(); current != nil && current != failed {
+		return nil // already replaced: 既に入れ替え済み
+	}
+	return reconnect()
+}
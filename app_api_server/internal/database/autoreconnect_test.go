@@ -0,0 +1,217 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	"database/sql"                  // sql: データベース操作用パッケージ
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"fmt"                           // fmt: format（フォーマット）
+	"reflect"                       // reflect: 値の比較
+	"strings"                       // strings: 文字列操作
+	"syscall"                       // syscall: システムコールのエラー番号
+	"testing"                       // testing: テスト機能
+
+	"github.com/lib/pq" // pq: PostgreSQLドライバー、エラー型
+)
+
+// newReconnectTestDriver connects a driver whose nth pool fails every statement with failures[n]
+// newReconnectTestDriver: n番目のプールの全ての文がfailures[n]で失敗するドライバーを接続する関数
+// A pool beyond the list succeeds: リストの範囲外のプールは成功する
+func newReconnectTestDriver(t *testing.T, failures ...error) (*PostgreSQLDriver, *int) {
+	t.Helper()
+
+	driver := newFakeConnectingDriver(t)
+	opens := 0
+	driver.openDB = func(string) (*sql.DB, error) {
+		var failure error
+		if opens < len(failures) {
+			failure = failures[opens]
+		}
+		opens++
+
+		fake, db := newFakeDB()
+		fake.exec = func(string, []sqldriver.NamedValue) (sqldriver.Result, error) {
+			if failure != nil {
+				return nil, failure
+			}
+			return sqldriver.RowsAffected(1), nil
+		}
+		fake.query = func(string, []sqldriver.NamedValue) (sqldriver.Rows, error) {
+			if failure != nil {
+				return nil, failure
+			}
+			return &fakeRows{columns: []string{"n"}, values: [][]sqldriver.Value{{int64(1)}}}, nil
+		}
+		return db, nil
+	}
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	t.Cleanup(func() { driver.Close() })
+	return driver, &opens
+}
+
+// TestWithReconnect tests which errors reconnect and retry once
+// TestWithReconnect: どのエラーで再接続して1回再試行するかをテストする関数
+func TestWithReconnect(t *testing.T) {
+	connReset := fmt.Errorf("read tcp: %w", syscall.ECONNRESET)
+
+	testCases := []struct {
+		name              string
+		failures          []error // failures: プールごとの失敗
+		expectError       bool
+		expectedOpens     int   // expected opens: プールを開いた回数（最初の接続を含む）
+		expectedRecovered int64 // expected recovered: 回復した文の数
+	}{
+		{name: "No error", failures: nil, expectError: false, expectedOpens: 1},
+		{name: "Bad connection recovered", failures: []error{sqldriver.ErrBadConn}, expectError: false, expectedOpens: 2, expectedRecovered: 1},
+		{name: "Connection reset recovered", failures: []error{connReset}, expectError: false, expectedOpens: 2, expectedRecovered: 1},
+		{name: "Admin shutdown recovered", failures: []error{&pq.Error{Code: "57P01"}}, expectError: false, expectedOpens: 2, expectedRecovered: 1},
+		{name: "Gives up after one retry", failures: []error{connReset, connReset}, expectError: true, expectedOpens: 2},
+		{name: "Statement error not retried", failures: []error{&pq.Error{Code: "23505"}}, expectError: true, expectedOpens: 1},
+	}
+
+	statements := []struct {
+		name string
+		run  func(d *PostgreSQLDriver) error
+	}{
+		{name: "Exec", run: func(d *PostgreSQLDriver) error {
+			_, err := d.ExecWithReconnect(context.Background(), "UPDATE t SET n = 1")
+			return err
+		}},
+		{name: "Query", run: func(d *PostgreSQLDriver) error {
+			rows, err := d.QueryWithReconnect(context.Background(), "SELECT n FROM t")
+			if err == nil {
+				rows.Close()
+			}
+			return err
+		}},
+		{name: "QueryRow", run: func(d *PostgreSQLDriver) error {
+			var n int64
+			return d.QueryRowWithReconnect(context.Background(), "SELECT n FROM t").Scan(&n)
+		}},
+	}
+
+	for _, tc := range testCases {
+		for _, statement := range statements {
+			t.Run(tc.name+"/"+statement.name, func(t *testing.T) {
+				driver, opens := newReconnectTestDriver(t, tc.failures...)
+				logs := captureLog(t)
+
+				err := statement.run(driver)
+				if tc.expectError && err == nil {
+					t.Error("Expected error but got none")
+				}
+				if !tc.expectError && err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				if *opens != tc.expectedOpens {
+					t.Errorf("Expected %d pool opens, got: %d", tc.expectedOpens, *opens)
+				}
+				if got := driver.GetConnectionStats().ReconnectRecoveries; got != tc.expectedRecovered {
+					t.Errorf("Expected %d recoveries in stats, got: %d", tc.expectedRecovered, got)
+				}
+				if tc.expectedRecovered > 0 && !strings.Contains(logs.String(), "Recovered from a connection-level error") {
+					t.Errorf("Expected the recovery to be logged, got: %s", logs.String())
+				}
+			})
+		}
+	}
+}
+
+// TestWithReconnectReconnectFails tests that a failed Reconnect is reported with the original error
+// TestWithReconnectReconnectFails: 再接続の失敗が元のエラーと共に報告されることをテストする関数
+func TestWithReconnectReconnectFails(t *testing.T) {
+	newFailingDriver := func() *PostgreSQLDriver {
+		driver, _ := newReconnectTestDriver(t, sqldriver.ErrBadConn)
+		driver.openDB = func(string) (*sql.DB, error) {
+			return nil, errors.New("connection refused")
+		}
+		return driver
+	}
+
+	_, err := newFailingDriver().ExecWithReconnect(context.Background(), "UPDATE t SET n = 1")
+	if !errors.Is(err, sqldriver.ErrBadConn) || !strings.Contains(err.Error(), "reconnect failed") {
+		t.Errorf("Expected the original error with the reconnect failure, got: %v", err)
+	}
+
+	var n int64
+	err = newFailingDriver().QueryRowWithReconnect(context.Background(), "SELECT n FROM t").Scan(&n)
+	if !errors.Is(err, sqldriver.ErrBadConn) || !strings.Contains(err.Error(), "reconnect failed") {
+		t.Errorf("Expected QueryRow to report the reconnect failure, got: %v", err)
+	}
+}
+
+// TestWithReconnectInsideTransaction tests that no reconnect happens for a call whose context carries a transaction
+// TestWithReconnectInsideTransaction: ctxがトランザクションを持つ呼び出しでは再接続しないことをテストする関数
+func TestWithReconnectInsideTransaction(t *testing.T) {
+	driver, opens := newReconnectTestDriver(t, sqldriver.ErrBadConn)
+
+	var execErr error
+	driver.WithTransaction(context.Background(), func(ctx context.Context, tx *sql.Tx) error {
+		_, execErr = driver.ExecWithReconnect(ctx, "UPDATE t SET n = 1")
+		return execErr
+	})
+
+	if !errors.Is(execErr, sqldriver.ErrBadConn) {
+		t.Errorf("Expected the connection error to be returned, got: %v", execErr)
+	}
+	if *opens != 1 {
+		t.Errorf("Expected no reconnect inside a transaction, got: %d opens", *opens)
+	}
+
+	// Once the transaction ends the helper recovers again: トランザクション終了後は再び回復する
+	if _, err := driver.ExecWithReconnect(context.Background(), "UPDATE t SET n = 1"); err != nil {
+		t.Errorf("Expected recovery after the transaction, got: %v", err)
+	}
+}
+
+// TestWithReconnectRunsOnTransaction tests that the helpers run on the transaction carried by ctx
+// TestWithReconnectRunsOnTransaction: ヘルパーがctxの持つトランザクションで実行されることをテストする関数
+func TestWithReconnectRunsOnTransaction(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	fake.query = func(string, []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		return &fakeRows{columns: []string{"n"}, values: [][]sqldriver.Value{{int64(1)}}}, nil
+	}
+
+	err := driver.WithTransaction(context.Background(), func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := driver.ExecWithReconnect(ctx, "UPDATE t SET n = 1"); err != nil {
+			return err
+		}
+		rows, err := driver.QueryWithReconnect(ctx, "SELECT n FROM t")
+		if err != nil {
+			return err
+		}
+		rows.Close()
+		var n int
+		return driver.QueryRowWithReconnect(ctx, "SELECT n FROM t WHERE n = 1").Scan(&n)
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []string{"UPDATE t SET n = 1", "SELECT n FROM t", "SELECT n FROM t WHERE n = 1"}
+	if got := fake.executedInTx(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected the statements to run on the transaction %v, got: %v", expected, got)
+	}
+}
+
+// TestWithReconnectBesideTransaction tests that a transaction open elsewhere does not stop another caller's retry
+// TestWithReconnectBesideTransaction: 別の場所で開いているトランザクションが他の呼び出し元の再試行を妨げないことをテストする関数
+func TestWithReconnectBesideTransaction(t *testing.T) {
+	driver, opens := newReconnectTestDriver(t, sqldriver.ErrBadConn)
+
+	var execErr error
+	driver.WithTransaction(context.Background(), func(ctx context.Context, tx *sql.Tx) error {
+		// A caller without the transaction in its context: トランザクションをctxに持たない呼び出し元
+		_, execErr = driver.ExecWithReconnect(context.Background(), "UPDATE t SET n = 1")
+		return nil
+	})
+
+	if execErr != nil {
+		t.Errorf("Expected the retry to succeed, got: %v", execErr)
+	}
+	if *opens != 2 {
+		t.Errorf("Expected one reconnect, got: %d opens", *opens)
+	}
+}
@@ -0,0 +1,63 @@
+// Package awsiam provides AWS RDS IAM authentication tokens for the database driver
+// awsiam: データベースドライバー向けにAWS RDSのIAM認証トークンを提供するパッケージ
+// The database package does not import it, so only binaries that pick providers by DB_AUTH_METHOD depend on the AWS SDK
+// databaseパッケージはインポートしないため、DB_AUTH_METHODで取得元を選ぶコマンドのみがAWS SDKに依存する
+package awsiam
+
+import (
+	"context" // context: コンテキスト、処理の文脈情報
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"net"     // net: ネットワーク、ホストとポートの結合
+	"strconv" // strconv: string conversion（文字列変換）
+
+	"github.com/aws/aws-sdk-go/aws"                  // aws: AWS SDKの共通型
+	"github.com/aws/aws-sdk-go/aws/session"          // session: 標準の認証情報チェーンとリージョンの読み込み
+	"github.com/aws/aws-sdk-go/service/rds/rdsutils" // rdsutils: RDSのIAM認証トークン生成
+
+	"api/internal/database" // database: データベースドライバー
+)
+
+// tokenBuilder signs a token for an endpoint ("host:port"), region and database user
+// tokenBuilder: エンドポイント（"host:port"）、リージョン、データベースユーザーに対するトークンに署名する関数型
+// signs: 署名する
+type tokenBuilder func(endpoint, region, user string) (string, error)
+
+// NewTokenProvider returns a token provider using the region and credentials from the standard AWS chain
+// NewTokenProvider: 標準のAWSチェーンのリージョンと認証情報を使うトークン発行関数を返す関数
+// chain: チェーン（環境変数、共有設定ファイル、インスタンスロールなどの順に探索）
+// Pass the result to database.WithAuthTokenProvider: 戻り値はdatabase.WithAuthTokenProviderに渡す
+func NewTokenProvider() (database.AuthTokenProvider, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable, // read ~/.aws/config too: ~/.aws/configも読み込む
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err) // failed: 失敗した
+	}
+
+	region := aws.StringValue(sess.Config.Region)
+	if region == "" {
+		return nil, fmt.Errorf("AWS region is not configured (set AWS_REGION)") // region: リージョン
+	}
+
+	return newTokenProvider(region, func(endpoint, region, user string) (string, error) {
+		return rdsutils.BuildAuthToken(endpoint, region, user, sess.Config.Credentials)
+	}), nil
+}
+
+// newTokenProvider adapts a token builder to database.AuthTokenProvider
+// newTokenProvider: トークン生成関数をdatabase.AuthTokenProviderに適合させる関数
+// adapts: 適合させる
+func newTokenProvider(region string, build tokenBuilder) database.AuthTokenProvider {
+	return func(ctx context.Context, config database.DatabaseConfig) (string, error) {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		endpoint := net.JoinHostPort(config.Host, strconv.Itoa(config.Port)) // IPv6 hosts are bracketed: IPv6ホストは角括弧で囲む
+		token, err := build(endpoint, region, config.User)
+		if err != nil {
+			return "", fmt.Errorf("failed to build RDS auth token for %s: %w", endpoint, err)
+		}
+		return token, nil
+	}
+}
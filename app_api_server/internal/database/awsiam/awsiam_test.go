@@ -0,0 +1,65 @@
+package awsiam
+
+import (
+	"context" // context: コンテキスト
+	"errors"  // errors: エラー操作
+	"testing" // testing: テストフレームワーク
+
+	"api/internal/database" // database: データベースドライバー
+)
+
+// TestNewTokenProvider tests the arguments passed to the token builder
+// TestNewTokenProvider: トークン生成関数に渡される引数をテストする関数
+func TestNewTokenProvider(t *testing.T) {
+	testCases := []struct {
+		name     string
+		host     string
+		expected string
+	}{
+		{"hostname", "mydb.abc.ap-northeast-1.rds.amazonaws.com", "mydb.abc.ap-northeast-1.rds.amazonaws.com:5432"},
+		{"ipv6 literal", "::1", "[::1]:5432"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotEndpoint, gotRegion, gotUser string
+			provider := newTokenProvider("ap-northeast-1", func(endpoint, region, user string) (string, error) {
+				gotEndpoint, gotRegion, gotUser = endpoint, region, user
+				return "signed-token", nil
+			})
+
+			token, err := provider(context.Background(), database.DatabaseConfig{Host: tc.host, Port: 5432, User: "iam_user"})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if token != "signed-token" {
+				t.Errorf("Expected signed-token, got: %s", token)
+			}
+			if gotEndpoint != tc.expected {
+				t.Errorf("Expected endpoint %s, got: %s", tc.expected, gotEndpoint)
+			}
+			if gotRegion != "ap-northeast-1" || gotUser != "iam_user" {
+				t.Errorf("Expected region and user to be passed through, got: %s %s", gotRegion, gotUser)
+			}
+		})
+	}
+}
+
+// TestNewTokenProviderErrors tests builder failures and cancelled contexts
+// TestNewTokenProviderErrors: トークン生成の失敗とキャンセル済みコンテキストをテストする関数
+func TestNewTokenProviderErrors(t *testing.T) {
+	errSigning := errors.New("no credentials")
+	provider := newTokenProvider("ap-northeast-1", func(string, string, string) (string, error) {
+		return "", errSigning
+	})
+
+	if _, err := provider(context.Background(), database.DatabaseConfig{Host: "db", Port: 5432}); !errors.Is(err, errSigning) {
+		t.Errorf("Expected builder error to be wrapped, got: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := provider(ctx, database.DatabaseConfig{Host: "db", Port: 5432}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got: %v", err)
+	}
+}
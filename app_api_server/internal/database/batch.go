@@ -0,0 +1,233 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"strconv"      // strconv: string conversion（文字列変換）、プレースホルダー番号の生成
+	"strings"      // strings: 文字列操作
+
+	"github.com/lib/pq" // pq: PostgreSQLドライバー、識別子のクォート
+)
+
+// maxQueryParameters is the PostgreSQL limit on bind parameters in one statement
+// maxQueryParameters: PostgreSQLの1文あたりのバインドパラメーター数の上限
+// bind parameters: バインドパラメーター
+const maxQueryParameters = 65535
+
+// ConflictAction selects the ON CONFLICT clause appended by BatchInsert
+// ConflictAction: BatchInsertが付加するON CONFLICT句を選ぶ型
+// conflict: 競合
+type ConflictAction int
+
+const (
+	ConflictError     ConflictAction = iota // error: 競合時はエラー（ON CONFLICT句なし）
+	ConflictDoNothing                       // do nothing: 競合した行をスキップ
+	ConflictDoUpdate                        // do update: 競合した行を更新
+)
+
+// BatchInsertOptions configures conflict handling and RETURNING collection for BatchInsert
+// BatchInsertOptions: BatchInsertの競合処理とRETURNINGの収集を設定する構造体
+type BatchInsertOptions struct {
+	OnConflict      ConflictAction // on conflict: 競合時の動作
+	ConflictColumns []string       // conflict columns: 競合対象のカラム（DoUpdateでは必須）
+	UpdateColumns   []string       // update columns: DoUpdateでEXCLUDEDから更新するカラム
+	Returning       string         // returning: 挿入した行から収集するカラム（例: "id"）、空なら収集しない
+}
+
+// BatchInsertResult reports the outcome of BatchInsert
+// BatchInsertResult: BatchInsertの結果を表す構造体
+// outcome: 結果
+type BatchInsertResult struct {
+	RowsInserted int64    // rows inserted: 挿入（または更新）された行数
+	Returned     []string // returned: RETURNINGで収集した値（Returning指定時のみ）
+}
+
+// BatchInsert inserts rows with multi-row INSERT statements inside a single transaction
+// BatchInsert: 単一トランザクション内で複数行INSERT文を使って行を挿入する関数
+// Rows are split into chunks that stay under the 65535 parameter limit; nil values are inserted as NULL
+// 行は65535パラメーターの上限を超えないよう分割され、nilの値はNULLとして挿入される
+// table may be schema-qualified (e.g. "app.users"); identifiers are quoted
+// tableはスキーマ修飾可能（例: "app.users"）、識別子はクォートされる
+func (d *PostgreSQLDriver) BatchInsert(ctx context.Context, table string, columns []string, rows [][]interface{}, opts BatchInsertOptions) (BatchInsertResult, error) {
+	var result BatchInsertResult
+	if len(rows) == 0 {
+		return result, nil // nothing to insert: 挿入対象なし
+	}
+
+	if err := validateBatchInsert(table, columns, rows, opts); err != nil {
+		return result, err
+	}
+
+	rowsPerChunk := maxQueryParameters / len(columns) // rows per chunk: 1文あたりの行数
+	suffix := batchInsertSuffix(opts)                 // suffix: ON CONFLICT句とRETURNING句
+
+	err := d.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		for start := 0; start < len(rows); start += rowsPerChunk {
+			end := start + rowsPerChunk
+			if end > len(rows) {
+				end = len(rows)
+			}
+
+			query, args := buildBatchInsert(table, columns, rows[start:end], suffix)
+			inserted, returned, err := d.execBatchChunk(ctx, tx, query, args, opts.Returning != "")
+			if err != nil {
+				return fmt.Errorf("batch insert rows %d-%d: %w", start, end-1, err)
+			}
+
+			result.RowsInserted += inserted
+			result.Returned = append(result.Returned, returned...)
+		}
+		return nil
+	})
+	if err != nil {
+		return BatchInsertResult{}, err // transaction rolled back: ロールバック済み
+	}
+	return result, nil
+}
+
+// validateBatchInsert checks the arguments before any statement is built
+// validateBatchInsert: 文を構築する前に引数を検証する関数
+func validateBatchInsert(table string, columns []string, rows [][]interface{}, opts BatchInsertOptions) error {
+	if table == "" {
+		return fmt.Errorf("batch insert table is required")
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("batch insert requires at least one column")
+	}
+	if len(columns) > maxQueryParameters {
+		return fmt.Errorf("batch insert row has %d columns, exceeding the %d parameter limit", len(columns), maxQueryParameters)
+	}
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return fmt.Errorf("batch insert row %d has %d values, expected %d", i, len(row), len(columns))
+		}
+	}
+	if opts.OnConflict == ConflictDoUpdate && (len(opts.ConflictColumns) == 0 || len(opts.UpdateColumns) == 0) {
+		return fmt.Errorf("batch insert ON CONFLICT DO UPDATE requires conflict and update columns")
+	}
+	return nil
+}
+
+// batchInsertSuffix builds the ON CONFLICT and RETURNING clauses shared by every chunk
+// batchInsertSuffix: 全チャンク共通のON CONFLICT句とRETURNING句を構築する関数
+func batchInsertSuffix(opts BatchInsertOptions) string {
+	suffix := conflictClause(opts.OnConflict, opts.ConflictColumns, opts.UpdateColumns)
+	if opts.Returning != "" {
+		suffix += " RETURNING " + pq.QuoteIdentifier(opts.Returning)
+	}
+	return suffix
+}
+
+// conflictClause builds the ON CONFLICT clause for action, or "" for ConflictError
+// conflictClause: actionに対応するON CONFLICT句を構築する関数、ConflictErrorの場合は空文字列
+func conflictClause(action ConflictAction, conflictColumns, updateColumns []string) string {
+	var b strings.Builder
+
+	switch action {
+	case ConflictDoNothing:
+		b.WriteString(" ON CONFLICT")
+		if len(conflictColumns) > 0 {
+			b.WriteString(" (" + quoteIdentifiers(conflictColumns) + ")")
+		}
+		b.WriteString(" DO NOTHING")
+	case ConflictDoUpdate:
+		b.WriteString(" ON CONFLICT (" + quoteIdentifiers(conflictColumns) + ") DO UPDATE SET ")
+		for i, column := range updateColumns {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			quoted := pq.QuoteIdentifier(column)
+			b.WriteString(quoted + " = EXCLUDED." + quoted)
+		}
+	}
+	return b.String()
+}
+
+// buildBatchInsert builds one multi-row INSERT statement and its flattened arguments
+// buildBatchInsert: 1つの複数行INSERT文と平坦化した引数を構築する関数
+// flattened: 平坦化した
+func buildBatchInsert(table string, columns []string, rows [][]interface{}, suffix string) (string, []interface{}) {
+	var b strings.Builder
+	b.WriteString("INSERT INTO " + quoteQualifiedIdentifier(table) + " (" + quoteIdentifiers(columns) + ") VALUES ")
+
+	args := make([]interface{}, 0, len(rows)*len(columns))
+	for i, row := range rows {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("(")
+		for j, value := range row {
+			if j > 0 {
+				b.WriteString(",")
+			}
+			args = append(args, value)
+			b.WriteString("$" + strconv.Itoa(len(args)))
+		}
+		b.WriteString(")")
+	}
+
+	b.WriteString(suffix)
+	return b.String(), args
+}
+
+// execBatchChunk runs one chunk and returns the affected row count and any RETURNING values
+// execBatchChunk: 1チャンクを実行し、影響を受けた行数とRETURNINGの値を返す関数
+func (d *PostgreSQLDriver) execBatchChunk(ctx context.Context, tx *sql.Tx, query string, args []interface{}, returning bool) (_ int64, _ []string, err error) {
+	op := OpExec
+	if returning {
+		op = OpQuery
+	}
+	ctx = d.beforeStatement(ctx, op, query, args)
+	start := d.now()
+	defer func() { d.afterStatement(ctx, op, query, d.now().Sub(start), err) }()
+
+	if !returning {
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, nil, err
+		}
+		inserted, err := res.RowsAffected()
+		return inserted, nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	var returned []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return 0, nil, err
+		}
+		returned = append(returned, value)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	return int64(len(returned)), returned, nil
+}
+
+// quoteIdentifiers quotes and joins column names
+// quoteIdentifiers: カラム名をクォートして連結する関数
+func quoteIdentifiers(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = pq.QuoteIdentifier(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// quoteQualifiedIdentifier quotes each part of a schema-qualified name
+// quoteQualifiedIdentifier: スキーマ修飾名の各部分をクォートする関数
+// qualified: 修飾された
+func quoteQualifiedIdentifier(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = pq.QuoteIdentifier(part)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,193 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"strconv"                       // strconv: 文字列変換
+	"strings"                       // strings: 文字列操作
+	"testing"                       // testing: テスト機能
+)
+
+// rowsAffectedByArgs makes exec report one affected row per group of columns arguments
+// rowsAffectedByArgs: 引数の数をカラム数で割った行数を影響行数として返すexec関数を作る
+func rowsAffectedByArgs(columns int) func(string, []sqldriver.NamedValue) (sqldriver.Result, error) {
+	return func(query string, args []sqldriver.NamedValue) (sqldriver.Result, error) {
+		return sqldriver.RowsAffected(len(args) / columns), nil
+	}
+}
+
+// insertStatements filters the recorded statements down to INSERTs
+// insertStatements: 記録された文からINSERT文のみを抽出する関数
+func insertStatements(fake *fakeDB) []string {
+	var inserts []string
+	for _, query := range fake.executed() {
+		if strings.HasPrefix(query, "INSERT") {
+			inserts = append(inserts, query)
+		}
+	}
+	return inserts
+}
+
+// TestBatchInsertChunking tests that large batches are split under the parameter limit in one transaction
+// TestBatchInsertChunking: 大きなバッチがパラメーター上限以下に分割され、1トランザクションで実行されることをテストする関数
+func TestBatchInsertChunking(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	fake.exec = rowsAffectedByArgs(3)
+
+	rows := make([][]interface{}, 30000)
+	for i := range rows {
+		rows[i] = []interface{}{"user" + strconv.Itoa(i) + "@example.com", "First", nil}
+	}
+
+	result, err := driver.BatchInsert(context.Background(), "app.users", []string{"email", "first_name", "last_name"}, rows, BatchInsertOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.RowsInserted != 30000 {
+		t.Errorf("Expected 30000 rows inserted, got: %d", result.RowsInserted)
+	}
+
+	inserts := insertStatements(fake)
+	if len(inserts) != 2 {
+		t.Fatalf("Expected 2 chunks for 90000 parameters, got: %d", len(inserts))
+	}
+	if !strings.HasPrefix(inserts[0], `INSERT INTO "app"."users" ("email", "first_name", "last_name") VALUES ($1,$2,$3),($4,$5,$6)`) {
+		t.Errorf("Unexpected statement prefix: %.120s", inserts[0])
+	}
+	if !strings.Contains(inserts[0], "$65535)") || strings.Contains(inserts[0], "$65536") {
+		t.Error("Expected first chunk to use exactly 65535 parameters")
+	}
+
+	executed := fake.executed()
+	if executed[0] != "BEGIN" || executed[len(executed)-1] != "COMMIT" {
+		t.Errorf("Expected chunks inside one transaction, got: BEGIN=%s last=%s", executed[0], executed[len(executed)-1])
+	}
+}
+
+// TestBatchInsertValidation tests edge cases that must not execute any statement
+// TestBatchInsertValidation: 文を実行してはならない境界ケースをテストする関数
+func TestBatchInsertValidation(t *testing.T) {
+	oversized := make([]string, maxQueryParameters+1)
+	for i := range oversized {
+		oversized[i] = "c" + strconv.Itoa(i)
+	}
+
+	testCases := []struct {
+		name        string
+		table       string
+		columns     []string
+		rows        [][]interface{}
+		opts        BatchInsertOptions
+		expectError bool
+	}{
+		{name: "Empty input is a no-op", table: "app.users", columns: []string{"email"}, rows: nil, expectError: false},
+		{name: "Missing table", table: "", columns: []string{"email"}, rows: [][]interface{}{{"a"}}, expectError: true},
+		{name: "No columns", table: "app.users", columns: nil, rows: [][]interface{}{{}}, expectError: true},
+		{name: "Single oversized row", table: "app.t", columns: oversized, rows: [][]interface{}{make([]interface{}, len(oversized))}, expectError: true},
+		{name: "Row length mismatch", table: "app.users", columns: []string{"email", "first_name"}, rows: [][]interface{}{{"a", nil}, {"b"}}, expectError: true},
+		{name: "Do update without columns", table: "app.users", columns: []string{"email"}, rows: [][]interface{}{{"a"}}, opts: BatchInsertOptions{OnConflict: ConflictDoUpdate}, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, fake := newTestDriver(t)
+
+			result, err := driver.BatchInsert(context.Background(), tc.table, tc.columns, tc.rows, tc.opts)
+			if tc.expectError && err == nil {
+				t.Errorf("Expected error for test case '%s', but got none", tc.name)
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Expected no error for test case '%s', got: %v", tc.name, err)
+			}
+			if result.RowsInserted != 0 {
+				t.Errorf("Expected 0 rows inserted, got: %d", result.RowsInserted)
+			}
+			if executed := fake.executed(); len(executed) != 0 {
+				t.Errorf("Expected no statements, got: %v", executed)
+			}
+		})
+	}
+}
+
+// TestBatchInsertConflictAndReturning tests the ON CONFLICT clauses and RETURNING collection
+// TestBatchInsertConflictAndReturning: ON CONFLICT句とRETURNINGの収集をテストする関数
+func TestBatchInsertConflictAndReturning(t *testing.T) {
+	testCases := []struct {
+		name         string
+		opts         BatchInsertOptions
+		expectSuffix string
+	}{
+		{name: "Do nothing", opts: BatchInsertOptions{OnConflict: ConflictDoNothing}, expectSuffix: ` ON CONFLICT DO NOTHING`},
+		{name: "Do nothing on target", opts: BatchInsertOptions{OnConflict: ConflictDoNothing, ConflictColumns: []string{"email"}}, expectSuffix: ` ON CONFLICT ("email") DO NOTHING`},
+		{
+			name:         "Do update returning id",
+			opts:         BatchInsertOptions{OnConflict: ConflictDoUpdate, ConflictColumns: []string{"email"}, UpdateColumns: []string{"first_name", "last_name"}, Returning: "id"},
+			expectSuffix: ` ON CONFLICT ("email") DO UPDATE SET "first_name" = EXCLUDED."first_name", "last_name" = EXCLUDED."last_name" RETURNING "id"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, fake := newTestDriver(t)
+			fake.exec = rowsAffectedByArgs(3)
+			fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+				return &fakeRows{columns: []string{"id"}, values: [][]sqldriver.Value{{"id-1"}, {"id-2"}}}, nil
+			}
+
+			rows := [][]interface{}{{"a@example.com", "A", nil}, {"b@example.com", nil, "B"}}
+			result, err := driver.BatchInsert(context.Background(), "app.users", []string{"email", "first_name", "last_name"}, rows, tc.opts)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			inserts := insertStatements(fake)
+			if len(inserts) != 1 || !strings.HasSuffix(inserts[0], "($1,$2,$3),($4,$5,$6)"+tc.expectSuffix) {
+				t.Errorf("Expected statement ending with %q, got: %v", tc.expectSuffix, inserts)
+			}
+			if result.RowsInserted != 2 {
+				t.Errorf("Expected 2 rows inserted, got: %d", result.RowsInserted)
+			}
+
+			if tc.opts.Returning != "" && strings.Join(result.Returned, ",") != "id-1,id-2" {
+				t.Errorf("Expected returned ids id-1,id-2, got: %v", result.Returned)
+			}
+			if tc.opts.Returning == "" && result.Returned != nil {
+				t.Errorf("Expected no returned values, got: %v", result.Returned)
+			}
+		})
+	}
+}
+
+// TestBatchInsertRollsBackOnChunkFailure tests that a failing chunk rolls back earlier chunks
+// TestBatchInsertRollsBackOnChunkFailure: チャンクの失敗で先行チャンクもロールバックされることをテストする関数
+func TestBatchInsertRollsBackOnChunkFailure(t *testing.T) {
+	driver, fake := newTestDriver(t)
+
+	calls := 0
+	fake.exec = func(query string, args []sqldriver.NamedValue) (sqldriver.Result, error) {
+		calls++
+		if calls == 2 {
+			return nil, errors.New("unique violation")
+		}
+		return sqldriver.RowsAffected(len(args)), nil
+	}
+
+	rows := make([][]interface{}, maxQueryParameters+1)
+	for i := range rows {
+		rows[i] = []interface{}{i}
+	}
+
+	result, err := driver.BatchInsert(context.Background(), "app.numbers", []string{"n"}, rows, BatchInsertOptions{})
+	if err == nil {
+		t.Fatal("Expected error from failing chunk, got none")
+	}
+	if result.RowsInserted != 0 {
+		t.Errorf("Expected 0 rows reported after rollback, got: %d", result.RowsInserted)
+	}
+
+	executed := fake.executed()
+	if executed[len(executed)-1] != "ROLLBACK" {
+		t.Errorf("Expected ROLLBACK as last statement, got: %s", executed[len(executed)-1])
+	}
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"errors" // errors: エラー操作
+	"fmt"    // fmt: format（フォーマット）、文字列フォーマット機能
+	"time"   // time: 時間操作機能
+)
+
+// Circuit breaker defaults for Reconnect
+// Reconnectのサーキットブレーカーのデフォルト値
+const (
+	defaultBreakerThreshold = 5                // threshold: 回路を開くまでの連続失敗回数
+	defaultBreakerCooldown  = 30 * time.Second // cool-down: 回路を開いたままにする時間
+)
+
+// CircuitState is the state of the Reconnect circuit breaker
+// CircuitState: Reconnectのサーキットブレーカーの状態
+type CircuitState string
+
+// Circuit breaker states
+// サーキットブレーカーの状態
+const (
+	CircuitClosed   CircuitState = "closed"    // closed: 通常どおり再接続を試みる
+	CircuitOpen     CircuitState = "open"      // open: 再接続を試みずに即座に失敗する
+	CircuitHalfOpen CircuitState = "half-open" // half-open: 1回の試行で回路を閉じるか判断する
+)
+
+// ErrCircuitOpen is returned by Reconnect while the circuit breaker rejects attempts
+// ErrCircuitOpen: サーキットブレーカーが試行を拒否している間にReconnectが返すエラー
+var ErrCircuitOpen = errors.New("reconnect circuit breaker is open")
+
+// circuitBreaker tracks consecutive Reconnect failures (guarded by the driver's mu)
+// circuitBreaker: Reconnectの連続失敗を追跡する構造体（ドライバーのmuで保護）
+// consecutive: 連続した
+type circuitBreaker struct {
+	threshold int                         // threshold: 回路を開くまでの連続失敗回数、0以下は無効
+	cooldown  time.Duration               // cooldown: 回路を開いたままにする時間
+	hook      func(from, to CircuitState) // hook: 状態遷移時のコールバック
+	state     CircuitState                // state: 現在の状態
+	failures  int                         // failures: 連続失敗回数
+	openedAt  time.Time                   // opened at: 回路を開いた時刻
+}
+
+// WithCircuitBreaker sets how many consecutive Reconnect failures open the circuit and for how long
+// WithCircuitBreaker: 何回連続でReconnectが失敗したら回路を開き、どれだけ開いたままにするかを設定するオプション
+// A threshold of 0 or less disables the breaker: しきい値が0以下の場合はブレーカーを無効にする
+func WithCircuitBreaker(threshold int, cooldown time.Duration) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		d.breaker.threshold = threshold
+		if cooldown > 0 {
+			d.breaker.cooldown = cooldown
+		}
+	}
+}
+
+// WithCircuitStateHook registers a callback for circuit breaker state transitions
+// WithCircuitStateHook: サーキットブレーカーの状態遷移時のコールバックを登録するオプション
+// transitions: 遷移（複数形）
+// The hook runs on the goroutine that called Reconnect: フックはReconnectを呼んだゴルーチンで実行される
+func WithCircuitStateHook(hook func(from, to CircuitState)) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		d.breaker.hook = hook
+	}
+}
+
+// allowReconnectLocked decides whether a Reconnect attempt may run
+// allowReconnectLocked: Reconnectの試行を実行してよいかを判断する関数
+// Once the cool-down has passed a single half-open probe is let through: 待機時間の経過後は半開状態の試行を1回だけ通す
+// probe: 試行、探り
+func (d *PostgreSQLDriver) allowReconnectLocked() (transition func(), err error) {
+	b := &d.breaker
+	if b.threshold <= 0 {
+		return nil, nil
+	}
+
+	switch b.state {
+	case CircuitOpen:
+		remaining := b.cooldown - d.now().Sub(b.openedAt)
+		if remaining > 0 {
+			return nil, fmt.Errorf("%w: retrying in %s", ErrCircuitOpen, remaining.Round(time.Second))
+		}
+		return d.setCircuitStateLocked(CircuitHalfOpen), nil
+	case CircuitHalfOpen:
+		// Another caller is already probing: 別の呼び出し元が既に試行中
+		return nil, fmt.Errorf("%w: a probe is in progress", ErrCircuitOpen)
+	}
+	return nil, nil
+}
+
+// recordReconnectLocked updates the breaker with the outcome of an attempt
+// recordReconnectLocked: 試行の結果でブレーカーを更新する関数
+// outcome: 結果
+func (d *PostgreSQLDriver) recordReconnectLocked(err error) (transition func()) {
+	b := &d.breaker
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	if err == nil {
+		b.failures = 0
+		return d.setCircuitStateLocked(CircuitClosed)
+	}
+
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		b.openedAt = d.now()
+		d.logger.Warn(fmt.Sprintf("reconnect circuit breaker opened after %d consecutive failures; next attempt in %s", b.failures, b.cooldown), "failures", b.failures, "cooldown", b.cooldown)
+		return d.setCircuitStateLocked(CircuitOpen)
+	}
+	return nil
+}
+
+// setCircuitStateLocked changes the state and returns the hook call to run after unlocking
+// setCircuitStateLocked: 状態を変更し、ロック解除後に実行するフック呼び出しを返す関数
+func (d *PostgreSQLDriver) setCircuitStateLocked(to CircuitState) func() {
+	from := d.breaker.state
+	if from == to {
+		return nil
+	}
+	d.breaker.state = to
+
+	hook := d.breaker.hook
+	if hook == nil {
+		return nil
+	}
+	return func() { hook(from, to) }
+}
+
+// runTransition runs a hook call returned by the Locked helpers, if any
+// runTransition: Locked系の関数が返したフック呼び出しがあれば実行する関数
+func runTransition(transition func()) {
+	if transition != nil {
+		transition()
+	}
+}
+
+// GetCircuitState returns the current state of the Reconnect circuit breaker
+// GetCircuitState: Reconnectのサーキットブレーカーの現在の状態を返す関数
+func (d *PostgreSQLDriver) GetCircuitState() CircuitState {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.breaker.state
+}
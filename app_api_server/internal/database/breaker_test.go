@@ -0,0 +1,163 @@
+package database
+
+import (
+	"context"      // context: コンテキスト
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"testing"      // testing: テスト機能
+	"time"         // time: 時間操作機能
+)
+
+// breakerTestDriver wires a driver to a switchable fake server and a manual clock
+// breakerTestDriver: 切り替え可能なフェイクサーバーと手動の時計につないだテスト用ドライバー
+type breakerTestDriver struct {
+	driver      *PostgreSQLDriver
+	down        bool           // down: trueの間は接続に失敗する
+	opens       int            // opens: プールを開こうとした回数
+	current     time.Time      // current: 手動の時計の現在時刻
+	transitions []CircuitState // transitions: フックが受け取った遷移先
+}
+
+func newBreakerTestDriver(t *testing.T, threshold int, cooldown time.Duration) *breakerTestDriver {
+	t.Helper()
+
+	b := &breakerTestDriver{current: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b.driver = newFakeConnectingDriver(t)
+	b.driver.applyOptions([]DriverOption{
+		WithCircuitBreaker(threshold, cooldown),
+		WithCircuitStateHook(func(from, to CircuitState) {
+			b.transitions = append(b.transitions, to)
+		}),
+	})
+	b.driver.now = func() time.Time { return b.current }
+	b.driver.openDB = func(string) (*sql.DB, error) {
+		b.opens++
+		if b.down {
+			return nil, errors.New("connection refused")
+		}
+		_, db := newFakeDB()
+		return db, nil
+	}
+	t.Cleanup(func() { b.driver.Close() })
+	return b
+}
+
+// TestCircuitBreakerStateMachine tests closed, open, half-open and back to closed
+// TestCircuitBreakerStateMachine: closed、open、half-open、そしてclosedへ戻る遷移をテストする関数
+func TestCircuitBreakerStateMachine(t *testing.T) {
+	b := newBreakerTestDriver(t, 3, 30*time.Second)
+	b.down = true
+
+	// Failures below the threshold keep the circuit closed: しきい値未満の失敗では回路は閉じたまま
+	for i := 0; i < 2; i++ {
+		if err := b.driver.Reconnect(); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected a connection error on attempt %d, got: %v", i+1, err)
+		}
+	}
+	if state := b.driver.GetCircuitState(); state != CircuitClosed {
+		t.Errorf("Expected closed after 2 failures, got: %s", state)
+	}
+
+	// The third failure opens it: 3回目の失敗で開く
+	b.driver.Reconnect()
+	if state := b.driver.GetCircuitState(); state != CircuitOpen {
+		t.Fatalf("Expected open after 3 failures, got: %s", state)
+	}
+
+	// While open, attempts fail fast without touching the database: 開いている間はデータベースに触れずに即座に失敗する
+	b.current = b.current.Add(29 * time.Second)
+	if err := b.driver.Reconnect(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen during the cool-down, got: %v", err)
+	}
+	if b.opens != 3 {
+		t.Errorf("Expected no attempt while open, got: %d opens", b.opens)
+	}
+
+	// A failed half-open probe reopens the circuit for another cool-down: 半開状態の試行が失敗すると再び開く
+	b.current = b.current.Add(time.Second)
+	if err := b.driver.Reconnect(); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected the probe to reach the database, got: %v", err)
+	}
+	if state := b.driver.GetCircuitState(); state != CircuitOpen {
+		t.Errorf("Expected open after a failed probe, got: %s", state)
+	}
+	if err := b.driver.Reconnect(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen after a failed probe, got: %v", err)
+	}
+
+	// A successful probe closes it: 試行が成功すると閉じる
+	b.down = false
+	b.current = b.current.Add(30 * time.Second)
+	if err := b.driver.Reconnect(); err != nil {
+		t.Fatalf("Expected the probe to succeed, got: %v", err)
+	}
+	if state := b.driver.GetCircuitState(); state != CircuitClosed {
+		t.Errorf("Expected closed after a successful probe, got: %s", state)
+	}
+
+	expected := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if len(b.transitions) != len(expected) {
+		t.Fatalf("Expected transitions %v, got: %v", expected, b.transitions)
+	}
+	for i := range expected {
+		if b.transitions[i] != expected[i] {
+			t.Errorf("Expected transitions %v, got: %v", expected, b.transitions)
+			break
+		}
+	}
+}
+
+// TestCircuitBreakerSuccessResetsFailures tests that a success clears the consecutive failure count
+// TestCircuitBreakerSuccessResetsFailures: 成功で連続失敗回数がリセットされることをテストする関数
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	b := newBreakerTestDriver(t, 2, time.Minute)
+
+	b.down = true
+	b.driver.Reconnect()
+	b.down = false
+	if err := b.driver.Reconnect(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	b.down = true
+	b.driver.Reconnect()
+
+	if state := b.driver.GetCircuitState(); state != CircuitClosed {
+		t.Errorf("Expected closed when failures are not consecutive, got: %s", state)
+	}
+	if failures := b.driver.GetConnectionStats().ReconnectFailures; failures != 1 {
+		t.Errorf("Expected 1 consecutive failure, got: %d", failures)
+	}
+}
+
+// TestCircuitBreakerObservable tests that the state appears in stats and the health check
+// TestCircuitBreakerObservable: 状態が統計とヘルスチェックに現れることをテストする関数
+func TestCircuitBreakerObservable(t *testing.T) {
+	b := newBreakerTestDriver(t, 1, time.Minute)
+	b.down = true
+	b.driver.Reconnect()
+
+	stats := b.driver.GetConnectionStats()
+	if stats.CircuitState != CircuitOpen || stats.ReconnectFailures != 1 {
+		t.Errorf("Expected open circuit with 1 failure in stats, got: %s %d", stats.CircuitState, stats.ReconnectFailures)
+	}
+
+	status, _ := b.driver.HealthCheck(context.Background())
+	if status.CircuitState != CircuitOpen {
+		t.Errorf("Expected open circuit in health status, got: %s", status.CircuitState)
+	}
+}
+
+// TestCircuitBreakerDisabled tests that a threshold of 0 never opens the circuit
+// TestCircuitBreakerDisabled: しきい値0では回路が開かないことをテストする関数
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b := newBreakerTestDriver(t, 0, time.Minute)
+	b.down = true
+	for i := 0; i < 10; i++ {
+		if err := b.driver.Reconnect(); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected no ErrCircuitOpen with the breaker disabled, got: %v", err)
+		}
+	}
+	if b.opens != 10 {
+		t.Errorf("Expected every attempt to reach the database, got: %d", b.opens)
+	}
+}
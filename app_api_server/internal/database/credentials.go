@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"time"         // time: 時間操作機能
+)
+
+// CredentialProvider issues database credentials, possibly with a limited lease
+// CredentialProvider: データベースの認証情報を発行するインターフェース、リース期間付きの場合もある
+// issues: 発行する、lease: リース、貸与期間
+// A ttl of 0 means the credentials do not expire: ttlが0の場合は失効しない
+// The Vault implementation lives in the vault sub-package: Vaultの実装はvaultサブパッケージにある
+type CredentialProvider interface {
+	GetCredentials(ctx context.Context) (user, password string, ttl time.Duration, err error)
+}
+
+// Credential refresh timing
+// 認証情報の更新タイミング
+const (
+	credentialRefreshTimeout = 30 * time.Second // refresh timeout: 1回の更新（取得、接続、ping）の制限時間
+	credentialRetryInterval  = 30 * time.Second // retry interval: 更新失敗後の再試行間隔
+	defaultPoolDrainDelay    = 5 * time.Second  // drain delay: 入れ替え後に古いプールを閉じるまでの猶予時間
+)
+
+// WithCredentialProvider sets the provider consulted on every connect when AuthMethod is credential-provider
+// WithCredentialProvider: AuthMethodがcredential-providerの場合に接続のたびに参照する認証情報の取得元を設定するオプション
+// consulted: 参照される
+func WithCredentialProvider(provider CredentialProvider) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		d.credentialProvider = provider
+	}
+}
+
+// providedConfig returns a copy of the configuration using credentials from the provider, and their lease
+// providedConfig: 取得元の認証情報を使った設定のコピーとそのリース期間を返す関数
+func (d *PostgreSQLDriver) providedConfig(ctx context.Context) (*DatabaseConfig, time.Duration, error) {
+	if d.credentialProvider == nil {
+		return nil, 0, fmt.Errorf("credential-provider authentication requires a credential provider (see WithCredentialProvider)")
+	}
+
+	user, password, ttl, err := d.credentialProvider.GetCredentials(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get database credentials: %w", err)
+	}
+	if user == "" || password == "" {
+		return nil, 0, fmt.Errorf("credential provider returned empty credentials") // empty: 空の
+	}
+
+	config := *d.config.Load()
+	config.User = user
+	config.Password = password
+	return &config, ttl, nil
+}
+
+// scheduleCredentialRefresh replaces any pending refresh with one due before the lease expires
+// scheduleCredentialRefresh: 予約済みの更新を、リース失効前に実行される更新に置き換える関数
+// due: 予定された、expires: 失効する
+// A lease of 0 only cancels the pending refresh: リース期間が0の場合は予約済みの更新を取り消すだけ
+func (d *PostgreSQLDriver) scheduleCredentialRefresh(lease time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.credentialErr = nil
+	d.stopCredentialRefreshLocked()
+	if lease > 0 {
+		d.scheduleCredentialRefreshLocked(lease * 3 / 4) // leave a quarter of the lease as margin: リースの4分の1を余裕として残す
+	}
+}
+
+// scheduleCredentialRefreshLocked arms the refresh timer for the current generation (d.mu must be held)
+// scheduleCredentialRefreshLocked: 現在の世代で更新タイマーを設定する関数（d.muを保持して呼ぶ）
+func (d *PostgreSQLDriver) scheduleCredentialRefreshLocked(after time.Duration) {
+	generation := d.refreshGen
+	d.refreshTimer = time.AfterFunc(after, func() {
+		d.refreshCredentials(generation)
+	})
+}
+
+// stopCredentialRefreshLocked cancels the pending refresh and invalidates one already running (d.mu must be held)
+// stopCredentialRefreshLocked: 予約済みの更新を取り消し、実行中の更新を無効にする関数（d.muを保持して呼ぶ）
+// invalidates: 無効にする
+func (d *PostgreSQLDriver) stopCredentialRefreshLocked() {
+	d.refreshGen++
+	if d.refreshTimer != nil {
+		d.refreshTimer.Stop()
+		d.refreshTimer = nil
+	}
+}
+
+// refreshCredentials opens a pool with new credentials and swaps it in, draining the old pool
+// refreshCredentials: 新しい認証情報でプールを開いて入れ替え、古いプールを段階的に閉じる関数
+// draining: 使用中の処理の完了を待って閉じる
+// Failures keep the current pool, are reported by HealthCheck and are retried
+// 失敗時は現在のプールを維持し、HealthCheckで報告して再試行する
+func (d *PostgreSQLDriver) refreshCredentials(generation int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), credentialRefreshTimeout)
+	defer cancel()
+
+	db, lease, err := d.openPool(ctx)
+
+	d.mu.Lock()
+	if generation != d.refreshGen {
+		// Closed or reconnected while the new pool was opening
+		// 新しいプールを開いている間にClose、または再接続された
+		d.mu.Unlock()
+		if db != nil {
+			db.Close()
+		}
+		return
+	}
+
+	if err != nil {
+		d.credentialErr = err
+		d.scheduleCredentialRefreshLocked(credentialRetryInterval)
+		d.mu.Unlock()
+		redacted := d.config.Load().redactError(err)
+		d.logger.Warn(fmt.Sprintf("database credential refresh failed, retrying in %s: %v", credentialRetryInterval, redacted), "retry_in", credentialRetryInterval, "error", redacted.Error()) // retrying: 再試行中
+		return
+	}
+
+	previous := d.setPool(db)
+	d.credentialErr = nil
+	d.lastConnectTime = d.now()
+	d.lastReconnectTime = d.lastConnectTime
+	d.totalReconnects++
+	if lease > 0 {
+		d.scheduleCredentialRefreshLocked(lease * 3 / 4)
+	}
+	d.mu.Unlock()
+
+	database := d.config.Load().Database
+	d.logger.Info(fmt.Sprintf("Database credentials refreshed for: %s", database), "database", database) // refreshed: 更新された
+	d.drainPool(previous)
+}
+
+// credentialRefreshError returns the last credential refresh failure, or nil
+// credentialRefreshError: 直近の認証情報更新の失敗を返す関数、失敗がなければnil
+func (d *PostgreSQLDriver) credentialRefreshError() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.credentialErr
+}
+
+// drainPool gracefully shuts down a pool that has been swapped out, after the drain delay
+// drainPool: 入れ替え済みのプールを、猶予時間の後に穏やかに終了させる関数
+// gracefully: 穏やかに、swapped out: 入れ替えられた
+// The delay covers callers that fetched the old pool just before the swap; sql.DB.Close then refuses
+// new work and closes each in-use connection once it is released, so in-flight work finishes normally
+// 猶予時間は入れ替え直前に古いプールを取得した呼び出し元のため、その後sql.DB.Closeは新しい処理を拒否し、
+// 使用中の接続は解放時に閉じるため、実行中の処理は通常どおり完了する
+func (d *PostgreSQLDriver) drainPool(db *sql.DB) {
+	if db == nil {
+		return
+	}
+	time.AfterFunc(d.poolDrainDelay, func() {
+		if err := db.Close(); err != nil {
+			d.logger.Warn(fmt.Sprintf("failed to close previous database pool: %v", err), "error", err.Error())
+		}
+	})
+}
@@ -0,0 +1,240 @@
+package database
+
+import (
+	"context"      // context: コンテキスト
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"strings"      // strings: 文字列操作
+	"sync"         // sync: 同期処理
+	"testing"      // testing: テストフレームワーク
+	"time"         // time: 時間操作
+)
+
+// fakeCredential is one response of fakeCredentialProvider
+// fakeCredential: fakeCredentialProviderの1回分の応答
+type fakeCredential struct {
+	user     string        // user: ユーザー名
+	password string        // password: パスワード
+	ttl      time.Duration // ttl: リース期間
+	err      error         // err: 返すエラー
+}
+
+// fakeCredentialProvider returns queued credentials, repeating the last one
+// fakeCredentialProvider: 順番に認証情報を返し、最後の応答を繰り返すフェイクの取得元
+type fakeCredentialProvider struct {
+	mu        sync.Mutex       // mu: mutex（相互排他ロック）
+	responses []fakeCredential // responses: 応答の列
+	calls     int              // calls: 呼び出し回数
+}
+
+func (p *fakeCredentialProvider) GetCredentials(ctx context.Context) (string, string, time.Duration, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	response := p.responses[len(p.responses)-1]
+	if p.calls < len(p.responses) {
+		response = p.responses[p.calls]
+	}
+	p.calls++
+	return response.user, response.password, response.ttl, response.err
+}
+
+// newProviderTestDriver creates a credential-provider driver whose pools are fakes
+// newProviderTestDriver: フェイクのプールを使うcredential-providerドライバーを作成する関数
+// The returned function lists the connection strings opened so far: 戻り値の関数はこれまでに開いた接続文字列を返す
+func newProviderTestDriver(t *testing.T, provider CredentialProvider) (*PostgreSQLDriver, func() []string) {
+	t.Helper()
+
+	driver, err := NewPostgreSQLDriverWithConfig(&DatabaseConfig{
+		Host:       "localhost",
+		Port:       5432,
+		Database:   "testdb",
+		SSLMode:    "disable",
+		AuthMethod: AuthMethodCredentialProvider,
+	}, WithCredentialProvider(provider))
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+	driver.poolDrainDelay = 0
+
+	var mu sync.Mutex
+	var connectionStrings []string
+	driver.openDB = func(connectionString string) (*sql.DB, error) {
+		mu.Lock()
+		connectionStrings = append(connectionStrings, connectionString)
+		mu.Unlock()
+		_, db := newFakeDB()
+		return db, nil
+	}
+	return driver, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), connectionStrings...)
+	}
+}
+
+// waitFor polls condition until it holds or the deadline passes
+// waitFor: 条件が成立するか期限を過ぎるまでポーリングする関数
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestCredentialProviderConnect tests that Connect dials with the provided credentials
+// TestCredentialProviderConnect: Connectが取得した認証情報で接続することをテストする関数
+func TestCredentialProviderConnect(t *testing.T) {
+	provider := &fakeCredentialProvider{responses: []fakeCredential{{user: "v-role-abc", password: "lease-pass"}}}
+	driver, connectionStrings := newProviderTestDriver(t, provider)
+	defer driver.Close()
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected connect to succeed, got: %v", err)
+	}
+
+	opened := connectionStrings()
+	if len(opened) != 1 || !strings.Contains(opened[0], "user=v-role-abc password=lease-pass ") {
+		t.Errorf("Expected provided credentials in connection string, got: %v", opened)
+	}
+	if config := driver.GetConfig(); config.User != "" || config.Password != "" {
+		t.Errorf("Expected provided credentials to stay out of the driver config, got: %v", config)
+	}
+}
+
+// TestCredentialProviderErrors tests missing providers and provider failures
+// TestCredentialProviderErrors: 取得元の未設定と取得失敗をテストする関数
+func TestCredentialProviderErrors(t *testing.T) {
+	errSealed := errors.New("vault is sealed")
+
+	testCases := []struct {
+		name     string
+		provider CredentialProvider
+		errorMsg string
+	}{
+		{"missing provider", nil, "requires a credential provider"},
+		{"provider failure", &fakeCredentialProvider{responses: []fakeCredential{{err: errSealed}}}, "vault is sealed"},
+		{"empty credentials", &fakeCredentialProvider{responses: []fakeCredential{{user: "u"}}}, "empty credentials"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, connectionStrings := newProviderTestDriver(t, tc.provider)
+
+			err := driver.Connect()
+			if err == nil || !strings.Contains(err.Error(), tc.errorMsg) {
+				t.Errorf("Expected error containing %q, got: %v", tc.errorMsg, err)
+			}
+			if len(connectionStrings()) != 0 {
+				t.Errorf("Expected no pool to be opened, got: %v", connectionStrings())
+			}
+		})
+	}
+}
+
+// TestCredentialRefreshSwapsPool tests that an expiring lease swaps in a new pool without breaking in-flight work
+// TestCredentialRefreshSwapsPool: リースの失効前に新しいプールへ入れ替わり、実行中の処理が中断されないことをテストする関数
+func TestCredentialRefreshSwapsPool(t *testing.T) {
+	provider := &fakeCredentialProvider{responses: []fakeCredential{
+		{user: "v-role-1", password: "pass-1", ttl: 40 * time.Millisecond},
+		{user: "v-role-2", password: "pass-2"}, // no expiry stops further refreshes: 失効なしで以降の更新を止める
+	}}
+	driver, connectionStrings := newProviderTestDriver(t, provider)
+	defer driver.Close()
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected connect to succeed, got: %v", err)
+	}
+	original := driver.GetDB()
+
+	// Hold a transaction open on the original pool across the swap
+	// 入れ替えをまたいで元のプールでトランザクションを保持する
+	tx, err := original.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Expected transaction to begin, got: %v", err)
+	}
+
+	waitFor(t, func() bool { return driver.GetDB() != original })
+
+	if _, err := tx.ExecContext(context.Background(), "UPDATE users SET name = 'a'"); err != nil {
+		t.Errorf("Expected in-flight transaction to keep working, got: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Errorf("Expected in-flight transaction to commit, got: %v", err)
+	}
+	waitFor(t, func() bool { return original.PingContext(context.Background()) != nil }) // old pool is closed: 古いプールは閉じられる
+
+	opened := connectionStrings()
+	if len(opened) != 2 || !strings.Contains(opened[1], "user=v-role-2 password=pass-2 ") {
+		t.Errorf("Expected the refresh to dial with new credentials, got: %v", opened)
+	}
+	if stats := driver.GetConnectionStats(); stats.TotalReconnects != 1 {
+		t.Errorf("Expected 1 reconnect, got: %d", stats.TotalReconnects)
+	}
+	if _, err := driver.HealthCheck(context.Background()); err != nil {
+		t.Errorf("Expected healthy driver, got: %v", err)
+	}
+}
+
+// TestCredentialRefreshFailure tests that a failed refresh keeps the pool and is reported by HealthCheck
+// TestCredentialRefreshFailure: 更新失敗時にプールが維持され、HealthCheckで報告されることをテストする関数
+func TestCredentialRefreshFailure(t *testing.T) {
+	provider := &fakeCredentialProvider{responses: []fakeCredential{
+		{user: "v-role-1", password: "pass-1", ttl: 40 * time.Millisecond},
+		{err: errors.New("permission denied")},
+	}}
+	driver, _ := newProviderTestDriver(t, provider)
+	defer driver.Close()
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected connect to succeed, got: %v", err)
+	}
+	original := driver.GetDB()
+
+	waitFor(t, func() bool { return driver.credentialRefreshError() != nil })
+
+	status, err := driver.HealthCheck(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Expected health check to report the refresh failure, got: %v", err)
+	}
+	if !status.Connected || status.Error == "" {
+		t.Errorf("Expected connected status with an error, got: %+v", status)
+	}
+	if driver.GetDB() != original {
+		t.Error("Expected the original pool to be kept")
+	}
+
+	// A successful reconnect clears the failure
+	// 再接続に成功すると失敗の記録は消える
+	provider.mu.Lock()
+	provider.responses = append(provider.responses, fakeCredential{user: "v-role-2", password: "pass-2"})
+	provider.mu.Unlock()
+	if err := driver.Reconnect(); err != nil {
+		t.Fatalf("Expected reconnect to succeed, got: %v", err)
+	}
+	if _, err := driver.HealthCheck(context.Background()); err != nil {
+		t.Errorf("Expected healthy driver after reconnect, got: %v", err)
+	}
+}
+
+// TestCloseStopsCredentialRefresh tests that Close cancels a pending refresh
+// TestCloseStopsCredentialRefresh: Closeが予約済みの更新を取り消すことをテストする関数
+func TestCloseStopsCredentialRefresh(t *testing.T) {
+	provider := &fakeCredentialProvider{responses: []fakeCredential{{user: "u", password: "p", ttl: 40 * time.Millisecond}}}
+	driver, connectionStrings := newProviderTestDriver(t, provider)
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected connect to succeed, got: %v", err)
+	}
+	driver.Close()
+
+	time.Sleep(80 * time.Millisecond)
+	if opened := connectionStrings(); len(opened) != 1 {
+		t.Errorf("Expected no refresh after Close, got %d pools", len(opened))
+	}
+}
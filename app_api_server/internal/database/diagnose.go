@@ -0,0 +1,409 @@
+package database
+
+import (
+	"context"         // context: コンテキスト、処理の文脈情報
+	"crypto/tls"      // tls: TLSハンドシェイク
+	"crypto/x509"     // x509: 証明書の検証
+	"encoding/binary" // binary: SSLRequestパケットの組み立て
+	"errors"          // errors: エラー操作
+	"fmt"             // fmt: format（フォーマット）、文字列フォーマット機能
+	"io"              // io: 入出力、テキスト形式の出力先
+	"net"             // net: ネットワーク、名前解決とダイヤル
+	"os"              // os: 証明書ファイルの読み込み
+	"path/filepath"   // filepath: UNIXソケットのパス組み立て
+	"strconv"         // strconv: string conversion（文字列変換）
+	"strings"         // strings: 文字列操作
+	"time"            // time: 時間操作機能
+)
+
+// Diagnosis stage names, in the order Diagnose runs them
+// Diagnoseが実行する順の診断段階の名前
+// stage: 段階
+const (
+	DiagnosisStageDNS  = "dns"  // dns: ホスト名の名前解決
+	DiagnosisStageTCP  = "tcp"  // tcp: サーバーへのダイヤル
+	DiagnosisStageTLS  = "tls"  // tls: sslmodeに従ったTLSネゴシエーション
+	DiagnosisStagePing = "ping" // ping: 認証を含む接続とping
+)
+
+// Diagnosis stage statuses
+// 診断段階の状態
+const (
+	DiagnosisOK      = "ok"      // ok: 成功
+	DiagnosisFailed  = "failed"  // failed: 失敗
+	DiagnosisSkipped = "skipped" // skipped: 対象外、または前の段階の失敗により省略
+)
+
+// defaultDiagnoseStageTimeout bounds each stage when ConnectTimeout is not set
+// defaultDiagnoseStageTimeout: ConnectTimeoutが未設定の場合に各段階に適用する制限時間
+const defaultDiagnoseStageTimeout = 10 * time.Second
+
+// sslRequestCode is the protocol code a client sends to ask the server to switch to TLS
+// sslRequestCode: クライアントがサーバーにTLSへの切り替えを要求するプロトコルコード
+const sslRequestCode = 80877103
+
+// DiagnosisStage is the outcome of one connection stage
+// DiagnosisStage: 接続の1段階の結果を表す構造体
+// outcome: 結果
+type DiagnosisStage struct {
+	Name    string        `json:"name"`             // name: 段階の名前
+	Status  string        `json:"status"`           // status: ok、failed、skipped
+	Latency time.Duration `json:"latency_ns"`       // latency: 所要時間（ナノ秒）
+	Detail  string        `json:"detail,omitempty"` // detail: 解決したアドレスやTLSのバージョンなどの補足
+	Error   string        `json:"error,omitempty"`  // error: 失敗の理由（伏せ字済み）
+}
+
+// HostDiagnosis holds the stages run against one listed host
+// HostDiagnosis: 列挙されたホストの1つに対して実行した段階を保持する構造体
+type HostDiagnosis struct {
+	Host   string           `json:"host"`   // host: ホスト
+	Port   int              `json:"port"`   // port: ポート
+	Stages []DiagnosisStage `json:"stages"` // stages: 実行順の段階
+	OK     bool             `json:"ok"`     // ok: 全段階が失敗しなかった
+}
+
+// DiagnosisReport is the result of Diagnose
+// DiagnosisReport: Diagnoseの結果を表す構造体
+// report: 報告
+type DiagnosisReport struct {
+	Config string          `json:"config"` // config: 伏せ字にした有効な設定
+	Hosts  []HostDiagnosis `json:"hosts"`  // hosts: ホストごとの診断
+	OK     bool            `json:"ok"`     // ok: 接続できるホストが少なくとも1つある
+
+	VerboseLogging *VerboseLoggingState `json:"verbose_logging,omitempty"` // verbose logging: 有効な詳細クエリログの期間（ドライバーのDiagnoseのみ）
+}
+
+// diagnoser runs the stages; its network calls are replaceable in tests
+// diagnoser: 各段階を実行する構造体、ネットワーク呼び出しはテストで差し替え可能
+type diagnoser struct {
+	lookupHost func(ctx context.Context, host string) ([]string, error)             // lookup host: 名前解決
+	dial       func(ctx context.Context, network, address string) (net.Conn, error) // dial: ダイヤル
+	ping       func(ctx context.Context, config *DatabaseConfig) (string, error)    // ping: 接続してサーバーの情報を返す
+	opts       []DriverOption                                                       // opts: pingのドライバーに渡すオプション
+	now        func() time.Time                                                     // now: 現在時刻
+}
+
+// Diagnose checks each stage of connecting to the configured database and reports its latency and failure reason
+// Diagnose: 設定されたデータベースへの接続の各段階を確認し、所要時間と失敗の理由を報告する関数
+// The stages are DNS resolution, a TCP dial, TLS negotiation as SSLMode requires, and a Ping through the driver.
+// Each listed host is checked; a stage is skipped once an earlier one fails. opts are passed to the driver used
+// for the ping, for example WithAuthTokenProvider for aws-iam
+// 段階はDNSの名前解決、TCPのダイヤル、SSLModeに従ったTLSネゴシエーション、ドライバー経由のPing
+// 列挙された各ホストを確認し、前の段階が失敗した後の段階は省略する
+// optsはpingに使うドライバーに渡される（aws-iamの場合のWithAuthTokenProviderなど）
+func Diagnose(ctx context.Context, config *DatabaseConfig, opts ...DriverOption) DiagnosisReport {
+	var dialer net.Dialer
+	g := &diagnoser{
+		lookupHost: net.DefaultResolver.LookupHost,
+		dial:       dialer.DialContext,
+		opts:       opts,
+		now:        time.Now,
+	}
+	g.ping = g.pingHost
+	return g.diagnose(ctx, config)
+}
+
+// Diagnose runs Diagnose against the driver's configuration and credential providers, adding the driver's
+// verbose query logging window to the report
+// Diagnose: ドライバーの設定と認証情報の取得元に対してDiagnoseを実行し、ドライバーの詳細クエリログの期間をレポートに加える関数
+func (d *PostgreSQLDriver) Diagnose(ctx context.Context) DiagnosisReport {
+	var opts []DriverOption
+	if d.authTokenProvider != nil {
+		opts = append(opts, WithAuthTokenProvider(d.authTokenProvider))
+	}
+	if d.credentialProvider != nil {
+		opts = append(opts, WithCredentialProvider(d.credentialProvider))
+	}
+	report := Diagnose(ctx, d.GetConfig(), opts...)
+	report.VerboseLogging = d.VerboseLoggingState()
+	return report
+}
+
+// diagnose runs every stage against each listed host
+// diagnose: 列挙された各ホストに対して全段階を実行する関数
+func (g *diagnoser) diagnose(ctx context.Context, config *DatabaseConfig) DiagnosisReport {
+	effective := cloneConfig(config)
+	effective.applyDefaults()
+	report := DiagnosisReport{Config: effective.String()}
+
+	for _, host := range effective.hostConfigs() {
+		diagnosis := g.diagnoseHost(ctx, host)
+		report.OK = report.OK || diagnosis.OK // libpq-style failover needs only one: フェイルオーバーでは1つで足りる
+		report.Hosts = append(report.Hosts, diagnosis)
+	}
+	return report
+}
+
+// diagnoseHost runs the stages against a single-host configuration
+// diagnoseHost: 単一ホストの設定に対して各段階を実行する関数
+func (g *diagnoser) diagnoseHost(ctx context.Context, config *DatabaseConfig) HostDiagnosis {
+	diagnosis := HostDiagnosis{Host: config.Host, Port: config.Port, OK: true}
+	failed := false
+
+	// run times one stage; fn returns its detail, or skip to mark it not applicable
+	// run: 1段階の時間を計測する、fnは補足を返すか、対象外の場合はskipを返す
+	run := func(name string, fn func(ctx context.Context) (string, error)) {
+		stage := DiagnosisStage{Name: name, Status: DiagnosisSkipped}
+		if failed {
+			stage.Detail = "an earlier stage failed"
+			diagnosis.Stages = append(diagnosis.Stages, stage)
+			return
+		}
+
+		stageCtx, cancel := context.WithTimeout(ctx, diagnoseStageTimeout(config))
+		defer cancel()
+		start := g.now()
+		detail, err := fn(stageCtx)
+		stage.Latency = g.now().Sub(start)
+		stage.Detail = detail
+
+		var skip errSkipStage
+		switch {
+		case errors.As(err, &skip):
+			stage.Detail = string(skip)
+			stage.Latency = 0
+		case err != nil:
+			stage.Status = DiagnosisFailed
+			stage.Error = config.redactError(err).Error()
+			failed = true
+			diagnosis.OK = false
+		default:
+			stage.Status = DiagnosisOK
+		}
+		diagnosis.Stages = append(diagnosis.Stages, stage)
+	}
+
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	network, address := dialAddress(config)
+	run(DiagnosisStageDNS, func(ctx context.Context) (string, error) {
+		if network == "unix" {
+			return "", errSkipStage("unix socket")
+		}
+		if net.ParseIP(strings.Trim(config.Host, "[]")) != nil {
+			return "", errSkipStage("IP address")
+		}
+		addrs, err := g.lookupHost(ctx, config.Host)
+		if err != nil {
+			return "", hostResolutionError(config.Host, err)
+		}
+		return strings.Join(addrs, ", "), nil
+	})
+	run(DiagnosisStageTCP, func(ctx context.Context) (string, error) {
+		c, err := g.dial(ctx, network, address)
+		if err != nil {
+			return "", err
+		}
+		conn = c
+		return c.RemoteAddr().String(), nil
+	})
+	run(DiagnosisStageTLS, func(ctx context.Context) (string, error) {
+		if network == "unix" {
+			return "", errSkipStage("not used over unix sockets")
+		}
+		return negotiateTLS(ctx, conn, config)
+	})
+	run(DiagnosisStagePing, func(ctx context.Context) (string, error) {
+		return g.ping(ctx, config)
+	})
+	return diagnosis
+}
+
+// errSkipStage marks a stage that does not apply, with the reason
+// errSkipStage: 対象外の段階を理由と共に示すエラー
+type errSkipStage string
+
+func (e errSkipStage) Error() string { return string(e) }
+
+// diagnoseStageTimeout bounds one stage by ConnectTimeout, or defaultDiagnoseStageTimeout when unset
+// diagnoseStageTimeout: 1段階の制限時間をConnectTimeout、未設定ならdefaultDiagnoseStageTimeoutとする関数
+func diagnoseStageTimeout(config *DatabaseConfig) time.Duration {
+	if config.ConnectTimeout > 0 {
+		return config.ConnectTimeout
+	}
+	return defaultDiagnoseStageTimeout
+}
+
+// dialAddress returns the network and address libpq dials for a single-host configuration
+// dialAddress: 単一ホストの設定に対してlibpqがダイヤルするネットワークとアドレスを返す関数
+// A host starting with a slash is the directory of a unix socket: スラッシュで始まるホストはUNIXソケットのディレクトリ
+func dialAddress(config *DatabaseConfig) (string, string) {
+	port := strconv.Itoa(config.Port)
+	if strings.HasPrefix(config.Host, "/") {
+		return "unix", filepath.Join(config.Host, ".s.PGSQL."+port)
+	}
+	host := config.Host
+	if bare, ok := ipv6Literal(host); ok {
+		host = bare
+	}
+	return "tcp", net.JoinHostPort(host, port)
+}
+
+// negotiateTLS sends an SSLRequest on conn and performs the handshake SSLMode asks for
+// negotiateTLS: connでSSLRequestを送り、SSLModeが求めるハンドシェイクを行う関数
+// negotiate: 交渉する、handshake: ハンドシェイク
+func negotiateTLS(ctx context.Context, conn net.Conn, config *DatabaseConfig) (string, error) {
+	switch config.SSLMode {
+	case "disable":
+		return "", errSkipStage("sslmode=disable")
+	case "allow":
+		return "", errSkipStage("sslmode=allow connects without TLS first")
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], sslRequestCode)
+	if _, err := conn.Write(request); err != nil {
+		return "", fmt.Errorf("failed to send SSLRequest: %w", err)
+	}
+	response := make([]byte, 1)
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return "", fmt.Errorf("failed to read SSLRequest response: %w", err)
+	}
+
+	switch response[0] {
+	case 'S':
+	case 'N':
+		if config.SSLMode == "prefer" {
+			return "server does not support TLS; sslmode=prefer continues without it", nil
+		}
+		return "", fmt.Errorf("server does not support TLS, but sslmode=%s requires it", config.SSLMode)
+	default:
+		return "", fmt.Errorf("unexpected SSLRequest response %q; is this a PostgreSQL server?", response[0])
+	}
+
+	tlsConfig, err := diagnoseTLSConfig(config)
+	if err != nil {
+		return "", err
+	}
+	client := tls.Client(conn, tlsConfig)
+	if err := client.HandshakeContext(ctx); err != nil {
+		return "", fmt.Errorf("TLS handshake failed: %w", err)
+	}
+	state := client.ConnectionState()
+	return fmt.Sprintf("%s %s", tls.VersionName(state.Version), tls.CipherSuiteName(state.CipherSuite)), nil
+}
+
+// diagnoseTLSConfig builds the TLS configuration lib/pq uses for SSLMode and the sslrootcert, sslcert and sslkey options
+// diagnoseTLSConfig: SSLModeとsslrootcert、sslcert、sslkeyオプションに対してlib/pqが使うTLS設定を構築する関数
+// require only verifies the chain when sslrootcert is given, as in libpq
+// requireはlibpqと同じく、sslrootcertが指定された場合のみ証明書チェーンを検証する
+func diagnoseTLSConfig(config *DatabaseConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	var roots *x509.CertPool
+	if path := config.Options["sslrootcert"]; path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sslrootcert: %w", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("sslrootcert %s contains no certificates", path)
+		}
+	}
+
+	if certFile, keyFile := config.Options["sslcert"], config.Options["sslkey"]; certFile != "" && keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load sslcert and sslkey: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	switch {
+	case config.SSLMode == "verify-full":
+		tlsConfig.RootCAs = roots
+		tlsConfig.ServerName = config.Host
+	case config.SSLMode == "verify-ca" || (config.SSLMode == "require" && roots != nil):
+		// Verify the chain but not the host name: ホスト名は確認せず証明書チェーンのみ検証する
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyCertificateChain(rawCerts, roots)
+		}
+	default:
+		tlsConfig.InsecureSkipVerify = true // prefer and require encrypt without verifying: preferとrequireは検証せず暗号化のみ
+	}
+	return tlsConfig, nil
+}
+
+// verifyCertificateChain checks the server's chain against roots (the system pool when nil)
+// verifyCertificateChain: サーバーの証明書チェーンをroots（nilの場合はシステムの証明書）で検証する関数
+func verifyCertificateChain(rawCerts [][]byte, roots *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return errors.New("server sent no certificate")
+	}
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("failed to parse server certificate: %w", err)
+		}
+		certs[i] = cert
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+	return err
+}
+
+// pingHost connects a driver to a single host and returns what it is attached to
+// pingHost: 単一ホストにドライバーで接続し、接続先のサーバーとセッションの情報を返す関数
+func (g *diagnoser) pingHost(ctx context.Context, config *DatabaseConfig) (string, error) {
+	driver, err := NewPostgreSQLDriverWithConfig(config, g.opts...)
+	if err != nil {
+		return "", err
+	}
+	if err := driver.connect(ctx); err != nil {
+		return "", err
+	}
+	defer driver.Close()
+
+	info, err := driver.GetServerInfo(ctx)
+	if err != nil {
+		return "connected", nil // the ping succeeded either way: いずれにせよpingは成功している
+	}
+	return info.String(), nil
+}
+
+// WriteText writes the report in a human-readable form, one line per stage
+// WriteText: 段階ごとに1行の人が読める形式でレポートを書き出す関数
+func (r DiagnosisReport) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Configuration: %s\n", r.Config)
+	for _, host := range r.Hosts {
+		fmt.Fprintf(&b, "\nHost %s port %d\n", host.Host, host.Port)
+		for _, stage := range host.Stages {
+			fmt.Fprintf(&b, "  %-5s %-7s %10s", stage.Name, stage.Status, stage.Latency.Round(time.Microsecond))
+			if stage.Detail != "" {
+				fmt.Fprintf(&b, "  %s", stage.Detail)
+			}
+			if stage.Error != "" {
+				fmt.Fprintf(&b, "  error: %s", stage.Error)
+			}
+			b.WriteString("\n")
+		}
+	}
+	if v := r.VerboseLogging; v != nil {
+		fmt.Fprintf(&b, "\nVerbose query logging: on until %s (%s left), enabled by %s\n", v.Until.Format(time.RFC3339), v.Remaining.Round(time.Second), v.EnabledBy)
+	}
+	if r.OK {
+		b.WriteString("\nResult: OK\n")
+	} else {
+		b.WriteString("\nResult: FAILED\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"bytes"   // bytes: バイト列操作
+	"context" // context: コンテキスト
+	"errors"  // errors: エラー操作
+	"io"      // io: 入出力
+	"net"     // net: ネットワーク、テスト用のリスナー
+	"strings" // strings: 文字列操作
+	"testing" // testing: テスト機能
+	"time"    // time: 時間操作機能
+)
+
+// startSSLRequestServer listens on a local port and answers every SSLRequest with reply
+// startSSLRequestServer: ローカルのポートで待ち受け、SSLRequestにreplyで応答するサーバーを起動する関数
+func startSSLRequestServer(t *testing.T, reply byte) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				request := make([]byte, 8)
+				if _, err := io.ReadFull(conn, request); err != nil {
+					return
+				}
+				conn.Write([]byte{reply})
+			}()
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// newTestDiagnoser returns a diagnoser that resolves every host to 127.0.0.1, dials it locally and pings with ping
+// newTestDiagnoser: すべてのホストを127.0.0.1に解決してローカルにダイヤルし、pingで接続確認する診断器を作成する関数
+func newTestDiagnoser(lookupErr error, ping func(context.Context, *DatabaseConfig) (string, error)) *diagnoser {
+	var dialer net.Dialer
+	return &diagnoser{
+		lookupHost: func(ctx context.Context, host string) ([]string, error) {
+			if lookupErr != nil {
+				return nil, lookupErr
+			}
+			return []string{"127.0.0.1"}, nil
+		},
+		dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			_, port, _ := net.SplitHostPort(address)
+			return dialer.DialContext(ctx, network, net.JoinHostPort("127.0.0.1", port))
+		},
+		ping: ping,
+		now:  fakeClock(time.Millisecond),
+	}
+}
+
+// stageStatuses renders the status of each stage for comparison
+// stageStatuses: 比較用に各段階の状態を文字列にする関数
+func stageStatuses(stages []DiagnosisStage) string {
+	statuses := make([]string, len(stages))
+	for i, stage := range stages {
+		statuses[i] = stage.Name + "=" + stage.Status
+	}
+	return strings.Join(statuses, " ")
+}
+
+// TestDiagnose tests the outcome of each stage for reachable and unreachable servers
+// TestDiagnose: 到達可能・不可能なサーバーに対する各段階の結果をテストする関数
+func TestDiagnose(t *testing.T) {
+	pingOK := func(context.Context, *DatabaseConfig) (string, error) { return "PostgreSQL 16.2", nil }
+
+	testCases := []struct {
+		name             string
+		host             string
+		sslMode          string
+		reply            byte  // reply: SSLRequestへの応答
+		lookupErr        error // lookup error: 名前解決のエラー
+		closedPort       bool  // closed port: 待ち受けていないポートに接続する
+		pingErr          error // ping error: pingのエラー
+		expectedStatuses string
+		expectOK         bool
+		errorContent     string
+	}{
+		{
+			name: "All stages pass", host: "db.example.com", sslMode: "prefer", reply: 'N',
+			expectedStatuses: "dns=ok tcp=ok tls=ok ping=ok", expectOK: true,
+		},
+		{
+			name: "IP address and disabled TLS are skipped", host: "127.0.0.1", sslMode: "disable",
+			expectedStatuses: "dns=skipped tcp=ok tls=skipped ping=ok", expectOK: true,
+		},
+		{
+			name: "DNS failure skips the rest", host: "missing.example.com", sslMode: "disable",
+			lookupErr:        errors.New("no such host"),
+			expectedStatuses: "dns=failed tcp=skipped tls=skipped ping=skipped", errorContent: "no such host",
+		},
+		{
+			name: "Dial failure", host: "db.example.com", sslMode: "disable", closedPort: true,
+			expectedStatuses: "dns=ok tcp=failed tls=skipped ping=skipped", errorContent: "refused",
+		},
+		{
+			name: "Server without TLS fails sslmode=require", host: "db.example.com", sslMode: "require", reply: 'N',
+			expectedStatuses: "dns=ok tcp=ok tls=failed ping=skipped", errorContent: "requires it",
+		},
+		{
+			name: "Not a PostgreSQL server", host: "db.example.com", sslMode: "prefer", reply: 'H',
+			expectedStatuses: "dns=ok tcp=ok tls=failed ping=skipped", errorContent: "unexpected SSLRequest response",
+		},
+		{
+			name: "Ping failure is redacted", host: "db.example.com", sslMode: "disable",
+			pingErr:          errors.New(`pq: password authentication failed: password=s3cret`),
+			expectedStatuses: "dns=ok tcp=ok tls=skipped ping=failed", errorContent: "password=*****",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			port := startSSLRequestServer(t, tc.reply)
+			if tc.closedPort {
+				listener, _ := net.Listen("tcp", "127.0.0.1:0")
+				port = listener.Addr().(*net.TCPAddr).Port
+				listener.Close()
+			}
+			ping := pingOK
+			if tc.pingErr != nil {
+				ping = func(context.Context, *DatabaseConfig) (string, error) { return "", tc.pingErr }
+			}
+			config := &DatabaseConfig{Host: tc.host, Port: port, User: "user", Password: "s3cret", Database: "db", SSLMode: tc.sslMode, ConnectTimeout: time.Second}
+
+			report := newTestDiagnoser(tc.lookupErr, ping).diagnose(context.Background(), config)
+
+			if len(report.Hosts) != 1 {
+				t.Fatalf("Expected 1 host, got: %d", len(report.Hosts))
+			}
+			stages := report.Hosts[0].Stages
+			if got := stageStatuses(stages); got != tc.expectedStatuses {
+				t.Errorf("Expected stages '%s', got: %s", tc.expectedStatuses, got)
+			}
+			if report.OK != tc.expectOK {
+				t.Errorf("Expected OK %v, got: %v", tc.expectOK, report.OK)
+			}
+			if strings.Contains(report.Config, "s3cret") {
+				t.Errorf("Expected a redacted configuration, got: %s", report.Config)
+			}
+
+			for _, stage := range stages {
+				if stage.Status == DiagnosisFailed {
+					if !strings.Contains(stage.Error, tc.errorContent) || strings.Contains(stage.Error, "s3cret") {
+						t.Errorf("Expected redacted error containing '%s', got: %s", tc.errorContent, stage.Error)
+					}
+				}
+				if stage.Status == DiagnosisOK && stage.Latency != time.Millisecond {
+					t.Errorf("Expected a latency of 1ms for %s, got: %s", stage.Name, stage.Latency)
+				}
+			}
+		})
+	}
+}
+
+// TestDiagnoseMultiHost tests that one reachable host is enough for the report to pass
+// TestDiagnoseMultiHost: 到達可能なホストが1つあればレポートが成功となることをテストする関数
+func TestDiagnoseMultiHost(t *testing.T) {
+	port := startSSLRequestServer(t, 'N')
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedPort := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	config := &DatabaseConfig{Host: "db1.example.com,db2.example.com", Ports: []int{closedPort, port}, Port: port, User: "user", Database: "db", SSLMode: "disable"}
+	ping := func(context.Context, *DatabaseConfig) (string, error) { return "", nil }
+	report := newTestDiagnoser(nil, ping).diagnose(context.Background(), config)
+
+	if len(report.Hosts) != 2 || report.Hosts[0].OK || !report.Hosts[1].OK {
+		t.Fatalf("Expected the first host to fail and the second to pass, got: %+v", report.Hosts)
+	}
+	if !report.OK {
+		t.Error("Expected the report to pass with one reachable host")
+	}
+}
+
+// TestDiagnosisReportWriteText tests the human-readable output
+// TestDiagnosisReportWriteText: 人が読める形式の出力をテストする関数
+func TestDiagnosisReportWriteText(t *testing.T) {
+	report := DiagnosisReport{
+		Config: "DatabaseConfig{Host: db}",
+		Hosts: []HostDiagnosis{{
+			Host: "db", Port: 5432,
+			Stages: []DiagnosisStage{
+				{Name: DiagnosisStageDNS, Status: DiagnosisOK, Latency: 2 * time.Millisecond, Detail: "10.0.0.1"},
+				{Name: DiagnosisStageTCP, Status: DiagnosisFailed, Latency: time.Second, Error: "connection refused"},
+			},
+		}},
+		VerboseLogging: &VerboseLoggingState{Until: time.Date(2024, 1, 1, 0, 10, 0, 0, time.UTC), Remaining: 4 * time.Minute, EnabledBy: "alice"},
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteText(&buf); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for _, expected := range []string{"Host db port 5432", "10.0.0.1", "error: connection refused",
+		"Verbose query logging: on until 2024-01-01T00:10:00Z (4m0s left), enabled by alice", "Result: FAILED"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Expected output to contain '%s', got: %s", expected, buf.String())
+		}
+	}
+}
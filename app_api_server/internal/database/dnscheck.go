@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context" // context: コンテキスト、処理の文脈情報
+	"errors"  // errors: エラー操作
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"net"     // net: ネットワーク、名前解決
+	"strings" // strings: 文字列操作
+	"time"    // time: 時間操作機能
+)
+
+// dnsCheckTimeout bounds the pre-flight hostname resolution done before opening a pool
+// dnsCheckTimeout: プールを開く前に行うホスト名の事前解決の制限時間
+// pre-flight: 事前確認
+const dnsCheckTimeout = 2 * time.Second
+
+// Resolver looks up the addresses of a hostname; *net.Resolver implements it
+// Resolver: ホスト名のアドレスを解決するインターフェース、*net.Resolverが実装する
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// WithResolver replaces net.DefaultResolver for the pre-flight hostname check
+// WithResolver: ホスト名の事前確認に使うnet.DefaultResolverを置き換えるオプション
+func WithResolver(resolver Resolver) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		if resolver != nil {
+			d.resolver = resolver
+		}
+	}
+}
+
+// needsResolution reports whether host is a name to resolve rather than an IP literal or a Unix socket directory
+// needsResolution: hostがIPリテラルやUNIXソケットのディレクトリではなく、解決すべき名前かどうかを判定する関数
+func needsResolution(host string) bool {
+	if host == "" || strings.HasPrefix(host, "/") {
+		return false
+	}
+	return net.ParseIP(strings.Trim(host, "[]")) == nil
+}
+
+// hostResolutionError explains a failed lookup of host with the literal value and where it came from
+// hostResolutionError: hostの解決失敗を、設定された値そのものと設定元と共に説明するエラーを作る関数
+func hostResolutionError(host string, err error) error {
+	return fmt.Errorf("database hostname %q could not be resolved; check DB_HOST (or PGHOST) for typos: %w", host, err)
+}
+
+// checkHostResolves resolves the host of a single-host config before a pool is opened for it
+// checkHostResolves: 単一ホストの設定に対してプールを開く前にホストを名前解決する関数
+// A name that does not exist fails at once with an actionable error instead of a generic dial error after pool setup.
+// Resolver timeouts and temporary failures are left to the dial, which has its own timeout
+// 存在しない名前は、プール設定後の一般的なダイヤルエラーではなく、対処の分かるエラーですぐに失敗させる
+// リゾルバーのタイムアウトや一時的な失敗は、独自のタイムアウトを持つダイヤルに任せる
+// actionable: 対処の分かる
+func (d *PostgreSQLDriver) checkHostResolves(ctx context.Context, config *DatabaseConfig) error {
+	if !needsResolution(config.Host) {
+		return nil
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, dnsCheckTimeout)
+	defer cancel()
+	_, err := d.resolver.LookupHost(lookupCtx, config.Host)
+	if err == nil {
+		return nil
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && (dnsErr.IsTimeout || dnsErr.IsTemporary) {
+		d.logger.Warn(fmt.Sprintf("Skipping the DNS check of %s: %v", config.Host, err), "host", config.Host)
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err() // the caller gave up, not the resolver: リゾルバーではなく呼び出し元が諦めた
+	}
+	return hostResolutionError(config.Host, err)
+}
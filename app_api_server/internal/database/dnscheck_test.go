@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"      // context: コンテキスト
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"net"          // net: ネットワーク、DNSエラー
+	"strings"      // strings: 文字列操作
+	"testing"      // testing: テスト機能
+)
+
+// fakeResolver resolves every host except those mapped to an error, without touching DNS
+// fakeResolver: DNSに触れずに、エラーを割り当てたホスト以外のすべてのホストを解決するフェイクのリゾルバー
+type fakeResolver map[string]error
+
+func (r fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if err, ok := r[host]; ok {
+		return nil, err
+	}
+	return []string{"192.0.2.10"}, nil
+}
+
+// resolverFunc adapts a function to Resolver
+// resolverFunc: 関数をResolverに適合させる型
+type resolverFunc func(ctx context.Context, host string) ([]string, error)
+
+func (f resolverFunc) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return f(ctx, host)
+}
+
+// TestCheckHostResolves tests the pre-flight hostname check, including the skipped cases
+// TestCheckHostResolves: ホスト名の事前確認を、確認を省略する場合も含めてテストする関数
+func TestCheckHostResolves(t *testing.T) {
+	notFound := &net.DNSError{Err: "no such host", Name: "db.exmaple.com", IsNotFound: true}
+	resolver := fakeResolver{
+		"db.exmaple.com": notFound,
+		"slow.internal":  &net.DNSError{Err: "i/o timeout", Name: "slow.internal", IsTimeout: true},
+		"flaky.internal": &net.DNSError{Err: "server misbehaving", Name: "flaky.internal", IsTemporary: true},
+	}
+
+	testCases := []struct {
+		name         string
+		host         string
+		skipped      bool // skipped: 名前解決を行わない
+		expectError  bool
+		errorContent string
+	}{
+		{name: "Resolvable name", host: "db.example.com"},
+		{name: "Mistyped name", host: "db.exmaple.com", expectError: true, errorContent: `database hostname "db.exmaple.com" could not be resolved; check DB_HOST`},
+		{name: "IPv4 literal", host: "10.0.0.5", skipped: true},
+		{name: "IPv6 literal", host: "[::1]", skipped: true},
+		{name: "Unix socket", host: "/var/run/postgresql", skipped: true},
+		{name: "Resolver timeout", host: "slow.internal"},
+		{name: "Temporary failure", host: "flaky.internal"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, _ := newTestDriver(t)
+			driver.logger = &capturingLogger{}
+			var lookups []string
+			driver.resolver = resolverFunc(func(ctx context.Context, host string) ([]string, error) {
+				lookups = append(lookups, host)
+				return resolver.LookupHost(ctx, host)
+			})
+
+			err := driver.checkHostResolves(context.Background(), &DatabaseConfig{Host: tc.host})
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				if !errors.Is(err, notFound) {
+					t.Errorf("Expected the DNS error to be wrapped, got: %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+			if skipped := len(lookups) == 0; skipped != tc.skipped {
+				t.Errorf("Expected skipped %t for %s, got lookups: %v", tc.skipped, tc.host, lookups)
+			}
+		})
+	}
+}
+
+// TestConnectUnresolvableHost tests that Connect fails before opening a pool when the host does not resolve
+// TestConnectUnresolvableHost: ホストが解決できない場合、Connectがプールを開く前に失敗することをテストする関数
+func TestConnectUnresolvableHost(t *testing.T) {
+	driver := newFakeConnectingDriver(t)
+	driver.resolver = fakeResolver{"localhost": &net.DNSError{Err: "no such host", Name: "localhost", IsNotFound: true}}
+	opened := false
+	driver.openDB = func(string) (*sql.DB, error) {
+		opened = true
+		return nil, errors.New("dial tcp: lookup localhost: no such host")
+	}
+
+	err := driver.Connect()
+	if err == nil || !strings.Contains(err.Error(), `database hostname "localhost" could not be resolved`) {
+		t.Errorf("Expected a hostname resolution error, got: %v", err)
+	}
+	if opened {
+		t.Error("Expected no pool to be opened")
+	}
+}
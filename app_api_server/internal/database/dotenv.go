@@ -0,0 +1,71 @@
+package database
+
+import (
+	"errors"  // errors: エラー操作
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"io/fs"   // fs: ファイルシステム、ファイル不存在エラーの判定
+	"log"     // log: ログ出力機能
+	"os"      // os: operating system（オペレーティングシステム）、環境変数の読み込み
+	"strings" // strings: 文字列操作
+
+	"github.com/joho/godotenv" // godotenv: 環境変数読み込み
+)
+
+// defaultDotEnvFile is the file loaded when neither paths nor DB_ENV_FILE are given
+// defaultDotEnvFile: パスもDB_ENV_FILEも指定されない場合に読み込むファイル
+const defaultDotEnvFile = ".env"
+
+// dotEnvPaths returns paths, or DB_ENV_FILE, or .env in the working directory
+// dotEnvPaths: paths、DB_ENV_FILE、作業ディレクトリの.envの順に読み込むファイルを決める関数
+func dotEnvPaths(paths []string) []string {
+	if len(paths) > 0 {
+		return paths
+	}
+	if envFile := strings.TrimSpace(os.Getenv("DB_ENV_FILE")); envFile != "" {
+		return []string{envFile}
+	}
+	return []string{defaultDotEnvFile}
+}
+
+// LoadDotEnv loads variables from .env files into the process environment, skipping missing files
+// LoadDotEnv: .envファイルの変数をプロセスの環境変数に読み込む関数、存在しないファイルは読み飛ばす
+// skipping: 読み飛ばす、missing: 存在しない
+// Without paths it reads DB_ENV_FILE, or .env in the working directory; variables already set are kept
+// pathsがない場合はDB_ENV_FILE、または作業ディレクトリの.envを読む、設定済みの変数は上書きしない
+func LoadDotEnv(paths ...string) error {
+	return loadDotEnv(dotEnvPaths(paths), false)
+}
+
+// LoadDotEnvStrict is LoadDotEnv but returns an error when a file is missing
+// LoadDotEnvStrict: LoadDotEnvと同じだが、ファイルが存在しない場合はエラーを返す関数
+// strict: 厳格な
+func LoadDotEnvStrict(paths ...string) error {
+	return loadDotEnv(dotEnvPaths(paths), true)
+}
+
+// loadDotEnv loads each file in order, so earlier files win for a repeated variable
+// loadDotEnv: 各ファイルを順に読み込む関数、重複する変数は先のファイルが優先される
+func loadDotEnv(paths []string, strict bool) error {
+	for _, path := range paths {
+		err := godotenv.Load(path)
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, fs.ErrNotExist) && !strict {
+			continue
+		}
+		return fmt.Errorf("failed to load env file %s: %w", path, err) // failed: 失敗した
+	}
+	return nil
+}
+
+// LoadDatabaseConfigWithDotEnv keeps the previous behavior of loading .env before reading the configuration
+// LoadDatabaseConfigWithDotEnv: 設定の読み込み前に.envを読み込む従来の動作を維持する関数
+// Deprecated: call LoadDotEnv explicitly and then LoadDatabaseConfig
+// 非推奨: LoadDotEnvを明示的に呼んでからLoadDatabaseConfigを呼ぶ
+func LoadDatabaseConfigWithDotEnv() (*DatabaseConfig, error) {
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found: %v", err) // warning: 警告、found: 見つかった
+	}
+	return LoadDatabaseConfig()
+}
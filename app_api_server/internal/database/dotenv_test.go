@@ -0,0 +1,113 @@
+package database
+
+import (
+	"os"            // os: 環境変数操作
+	"path/filepath" // filepath: ファイルパス操作
+	"testing"       // testing: テストフレームワーク
+)
+
+// writeEnvFile writes a .env file into a temporary directory and returns its path
+// writeEnvFile: 一時ディレクトリに.envファイルを書き込み、そのパスを返す関数
+func writeEnvFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+	return path
+}
+
+// TestLoadDotEnv tests explicit, DB_ENV_FILE and missing-file loading
+// TestLoadDotEnv: 明示的な指定、DB_ENV_FILE、ファイルが存在しない場合の読み込みをテストする関数
+func TestLoadDotEnv(t *testing.T) {
+	t.Setenv("DOTENV_TEST_VALUE", "")
+	os.Unsetenv("DOTENV_TEST_VALUE")
+	t.Setenv("DB_ENV_FILE", "")
+
+	path := writeEnvFile(t, "app.env", "DOTENV_TEST_VALUE=from-file\n")
+	if err := LoadDotEnv(path); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got := os.Getenv("DOTENV_TEST_VALUE"); got != "from-file" {
+		t.Errorf("Expected value from file, got: %q", got)
+	}
+
+	// Variables already in the environment win over the file
+	// 環境変数に既にある値がファイルより優先される
+	t.Setenv("DOTENV_TEST_VALUE", "from-env")
+	if err := LoadDotEnv(path); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got := os.Getenv("DOTENV_TEST_VALUE"); got != "from-env" {
+		t.Errorf("Expected existing value to be kept, got: %q", got)
+	}
+
+	// Missing files are skipped in the default mode
+	// 通常モードでは存在しないファイルを読み飛ばす
+	if err := LoadDotEnv(filepath.Join(t.TempDir(), "missing.env")); err != nil {
+		t.Errorf("Expected missing file to be skipped, got: %v", err)
+	}
+}
+
+// TestLoadDotEnvFromDBEnvFile tests that DB_ENV_FILE selects the file when no paths are given
+// TestLoadDotEnvFromDBEnvFile: パス未指定時にDB_ENV_FILEでファイルが選ばれることをテストする関数
+func TestLoadDotEnvFromDBEnvFile(t *testing.T) {
+	t.Setenv("DOTENV_TEST_VALUE", "")
+	os.Unsetenv("DOTENV_TEST_VALUE")
+	t.Setenv("DB_ENV_FILE", writeEnvFile(t, "selected.env", "DOTENV_TEST_VALUE=selected\n"))
+
+	if err := LoadDotEnvStrict(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got := os.Getenv("DOTENV_TEST_VALUE"); got != "selected" {
+		t.Errorf("Expected value from DB_ENV_FILE, got: %q", got)
+	}
+}
+
+// TestLoadDotEnvStrict tests that strict mode reports missing and malformed files
+// TestLoadDotEnvStrict: 厳格モードで存在しないファイルと不正なファイルが報告されることをテストする関数
+func TestLoadDotEnvStrict(t *testing.T) {
+	t.Setenv("DB_ENV_FILE", filepath.Join(t.TempDir(), "missing.env"))
+
+	if err := LoadDotEnvStrict(); err == nil {
+		t.Error("Expected error for missing DB_ENV_FILE in strict mode")
+	}
+	if err := LoadDotEnv(); err != nil {
+		t.Errorf("Expected lenient mode to skip the missing file, got: %v", err)
+	}
+
+	malformed := writeEnvFile(t, "bad.env", "THIS LINE IS NOT AN ASSIGNMENT\n")
+	if err := LoadDotEnv(malformed); err == nil {
+		t.Error("Expected error for a malformed file even in lenient mode")
+	}
+}
+
+// TestLoadDatabaseConfigIgnoresDotEnv tests that LoadDatabaseConfig only reads the process environment
+// TestLoadDatabaseConfigIgnoresDotEnv: LoadDatabaseConfigがプロセスの環境変数のみを読むことをテストする関数
+func TestLoadDatabaseConfigIgnoresDotEnv(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("DB_NAME=from_dotenv\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+	t.Chdir(dir)
+
+	t.Setenv("DB_USER", "u")
+	t.Setenv("DB_PASSWORD", "p")
+	t.Setenv("DB_NAME", "")
+	os.Unsetenv("DB_NAME")
+
+	if _, err := LoadDatabaseConfig(); err == nil {
+		t.Error("Expected DB_NAME from .env to be ignored")
+	}
+
+	// The compatibility loader keeps reading ./.env
+	// 互換用の読み込み関数は引き続き./.envを読む
+	config, err := LoadDatabaseConfigWithDotEnv()
+	if err != nil {
+		t.Fatalf("Expected compatibility loader to succeed, got: %v", err)
+	}
+	if config.Database != "from_dotenv" {
+		t.Errorf("Expected database from .env, got: %s", config.Database)
+	}
+}
@@ -1,90 +1,490 @@
 package database
 
 import (
-	"database/sql" // sql: データベース操作用パッケージ、Structured Query Language（構造化照会言語）
-	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
-	"log"          // log: ログ出力機能
-	"os"           // os: operating system（オペレーティングシステム）、OS操作機能
-	"strconv"      // strconv: string conversion（文字列変換）、文字列と数値の変換
-	"time"         // time: 時間操作機能
-
-	"github.com/joho/godotenv" // godotenv: 環境変数読み込み
-	_ "github.com/lib/pq"      // pq: PostgreSQLドライバー（blank import）
+	"context"             // context: コンテキスト、処理の文脈情報
+	"database/sql"        // sql: データベース操作用パッケージ、Structured Query Language（構造化照会言語）
+	"database/sql/driver" // driver: SQLドライバーインターフェース
+	"fmt"                 // fmt: format（フォーマット）、文字列フォーマット機能
+	"net"                 // net: ネットワーク、ホスト名の解決
+	"os"                  // os: operating system（オペレーティングシステム）、OS操作機能
+	"strconv"             // strconv: string conversion（文字列変換）、文字列と数値の変換
+	"strings"             // strings: 文字列操作
+	"sync"                // sync: 同期処理、排他制御
+	"sync/atomic"         // atomic: 設定の入れ替えと読み込みを不可分に行う
+	"time"                // time: 時間操作機能
+
+	_ "github.com/lib/pq"            // pq: PostgreSQLドライバー（blank import）
+	"go.opentelemetry.io/otel/trace" // trace: OpenTelemetryトレースAPI
 )
 
 // DatabaseConfig represents database configuration settings
 // DatabaseConfig: データベース設定を表す構造体
 // represents: 表現する、configuration: 設定、settings: 設定（複数形）
 type DatabaseConfig struct {
-	Host     string // host: ホスト、データベースサーバーのアドレス
-	Port     int    // port: ポート、接続用のポート番号
+	Host     string // host: ホスト、データベースサーバーのアドレス（カンマ区切りで複数指定可）
+	Port     int    // port: ポート、接続用のポート番号（Portsがない場合は全ホスト共通）
 	User     string // user: ユーザー、データベースユーザー名
 	Password string // password: パスワード、認証用パスワード
 	Database string // database: データベース、データベース名
 	SSLMode  string // sslmode: SSL mode（セキュリティ層）、SSL接続モード
+
+	// SlowQueryThreshold flags statements slower than this duration (0 disables)
+	// slow: 遅い、threshold: しきい値、0の場合は無効
+	SlowQueryThreshold time.Duration
+
+	// ConnMaxIdleTime closes connections idle longer than this duration (0 keeps them)
+	// conn max idle time: アイドル接続を閉じるまでの時間、0の場合は閉じない
+	ConnMaxIdleTime time.Duration
+
+	// MaxOpenConns, MaxIdleConns and ConnMaxLifetime size the pool (0 uses the defaults of 25, 5 and 5m)
+	// max open conns, max idle conns, conn max lifetime: プールの大きさと接続の寿命（0の場合はデフォルトの25、5、5分）
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+
+	// ConnMaxLifetimeJitter spreads connection expiry by giving each connection ConnMaxLifetime ± this fraction (0.1 is ±10%, 0 disables)
+	// conn max lifetime jitter: 各接続の寿命をConnMaxLifetime ± この割合にして期限切れを分散させる（0.1は±10%、0の場合は無効）
+	ConnMaxLifetimeJitter float64
+
+	// TimeZone is the session time zone sent as the timezone run-time parameter (IANA name such as "UTC")
+	// time zone: セッションのタイムゾーン、run-time parameterとして送信される（"UTC"などのIANA名）
+	TimeZone string
+
+	// ConnectTimeout bounds each connection attempt, sent as connect_timeout in whole seconds (0 waits indefinitely)
+	// connect timeout: 接続試行の制限時間、connect_timeoutとして秒単位で送信される（0の場合は無期限）
+	ConnectTimeout time.Duration
+
+	// DefaultQueryTimeout bounds statements run through the driver helpers when the caller's context has no deadline
+	// default query timeout: 呼び出し元のコンテキストに期限がない場合に、ドライバーのヘルパー経由の文に適用する制限時間
+	DefaultQueryTimeout time.Duration
+
+	// MinServerVersion is the oldest PostgreSQL version Connect accepts, such as "13.0" (empty skips the check)
+	// min server version: Connectが受け付ける最も古いPostgreSQLのバージョン（"13.0"など、空の場合はチェックしない）
+	MinServerVersion string
+
+	// Options holds extra libpq parameters not modeled as fields, such as application_name (DB_OPTIONS)
+	// options: フィールドとして扱わない追加のlibpqパラメータ（application_nameなど、DB_OPTIONS）
+	Options map[string]string
+
+	// Ports gives one port per host when Host lists several (empty uses Port for every host)
+	// ports: Hostが複数のホストを列挙する場合のホストごとのポート（空の場合は全ホストでPortを使用）
+	Ports []int
+
+	// TargetSessionAttrs picks which listed host to use, as libpq's target_session_attrs: "any" (default), "read-write", "read-only", "primary" or "standby"
+	// target session attrs: 列挙されたホストのどれを使うか、libpqのtarget_session_attrsと同じ（"any"（デフォルト）、"read-write"、"read-only"、"primary"、"standby"）
+	TargetSessionAttrs string
+
+	// AuthMethod selects how the driver authenticates: "password" (default), "aws-iam" or "credential-provider"
+	// auth method: 認証方式、"password"（デフォルト）、"aws-iam"または"credential-provider"
+	AuthMethod string
+
+	// LockTimeout and IdleInTransactionTimeout are sent as the session's lock_timeout and
+	// idle_in_transaction_session_timeout (0 leaves the server setting)
+	// lock timeout, idle in transaction timeout: セッションのlock_timeoutとidle_in_transaction_session_timeoutとして送信される（0の場合はサーバーの設定のまま）
+	LockTimeout              time.Duration
+	IdleInTransactionTimeout time.Duration
+
+	// ReadOnly makes every session default to read-only transactions (default_transaction_read_only=on)
+	// and WithTransaction begin read-only transactions, as defense in depth for reporting deployments
+	// read only: 全セッションのトランザクションを既定で読み取り専用にし（default_transaction_read_only=on）、
+	// WithTransactionも読み取り専用で開始する、レポート用途の多層防御
+	ReadOnly bool
+
+	// FallbackApplicationName names the connection in pg_stat_activity unless Options sets application_name
+	// fallback application name: Optionsでapplication_nameを指定しない場合にpg_stat_activityに表示される接続名
+	FallbackApplicationName string
+
+	// Keepalive settings tune the TCP keepalive probes as libpq's keepalives parameters, so connections left idle
+	// behind a NAT gateway are not dropped. DisableKeepalives turns the probes off; KeepalivesIdle and
+	// KeepalivesInterval are sent in whole seconds (0 leaves Go's default of 15s for each, and 9 probes for KeepalivesCount)
+	// キープアライブ設定はlibpqのkeepalivesパラメータと同じくTCPキープアライブのプローブを調整し、NATゲートウェイ越しの
+	// アイドル接続が切断されないようにする。DisableKeepalivesはプローブを無効にし、KeepalivesIdleとKeepalivesIntervalは
+	// 秒単位で送信される（0の場合はGoのデフォルトの各15秒、KeepalivesCountは9回）
+	DisableKeepalives  bool
+	KeepalivesIdle     time.Duration
+	KeepalivesInterval time.Duration
+	KeepalivesCount    int
 }
 
+// defaultTimeZone is the session time zone used when none is configured
+// defaultTimeZone: 未設定時に使用するセッションのタイムゾーン
+// The application assumes UTC timestamps: アプリケーションはUTCのタイムスタンプを前提とする
+const defaultTimeZone = "UTC"
+
+// validSSLModes lists the sslmode values accepted by libpq
+// validSSLModes: libpqが受け付けるsslmodeの値の一覧
+// valid: 有効な、modes: モード（複数形）
+var validSSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// Connection pool defaults applied by Connect
+// Connectで適用される接続プールのデフォルト値
+// defaults: デフォルト値（複数形）
+const (
+	defaultMaxOpenConns    = 25              // maximum open connections: 最大接続数
+	defaultMaxIdleConns    = 5               // maximum idle connections: 最大アイドル接続数
+	defaultConnMaxLifetime = 5 * time.Minute // connection lifetime: 接続の寿命
+)
+
 // PostgreSQLDriver represents PostgreSQL database driver
 // PostgreSQLDriver: PostgreSQLデータベースドライバーを表す構造体
 // represents: 表現する、driver: ドライバー
 type PostgreSQLDriver struct {
-	config *DatabaseConfig // config: 設定、configuration: 構成
-	db     *sql.DB         // db: database（データベース）、データベース接続
+	// config is replaced as a whole by Store and never modified in place, so queries can Load it without a lock
+	// config: 設定、Storeで丸ごと置き換え、その場では変更しないため、クエリはロックなしでLoadできる
+	config atomic.Pointer[DatabaseConfig]
+	db     *sql.DB      // db: database（データベース）、データベース接続（poolMuで保護）
+	poolMu sync.RWMutex // pool mutex: dbの入れ替えを保護する読み書きロック
+
+	queryStats     queryCounters    // query: クエリ、stats: 統計、クエリ統計カウンター
+	now            func() time.Time // now: 現在時刻、テスト用に差し替え可能な時計
+	tracer         trace.Tracer     // tracer: トレーサー、nilの場合トレース無効
+	statementHooks []StatementHook  // statement hooks: 文の前後で実行する登録済みフック
+	logger         Logger           // logger: ログの出力先、既定は標準ロガー
+
+	// openDB opens a pool for a connection string (replaceable in tests)
+	// openDB: 接続文字列からプールを開く関数（テストで差し替え可能）
+	openDB func(connectionString string) (*sql.DB, error)
+
+	mu                sync.Mutex // mu: mutex（相互排他ロック）、以下の接続履歴を保護
+	lastConnectTime   time.Time  // last connect: 最後の接続成功時刻
+	lastReconnectTime time.Time  // last reconnect: 最後の再接続成功時刻
+	totalReconnects   int64      // total reconnects: 再接続成功の累計回数
+	lastRotationTime  time.Time  // last rotation: 最後の認証情報ローテーション時刻
+
+	// pendingConfig is staged by UpdateConfig and applied on the next connect (guarded by mu)
+	// pendingConfig: UpdateConfigで準備され、次回接続時に適用される設定（muで保護）
+	pendingConfig *DatabaseConfig
+
+	// closing is closed by Close to stop background goroutines
+	// closing: Closeで閉じられ、バックグラウンド処理を停止させるチャネル
+	closing chan struct{}
+	closed  bool // closed: Closeが呼ばれた、以降の操作はErrDriverClosedを返す（muで保護）
+
+	pressure     poolPressureDetector  // pressure: プール逼迫の検出状態（muで保護）
+	pressureHook func(ConnectionStats) // pressure hook: 逼迫開始時のコールバック
+
+	retryPolicy RetryPolicy   // retry policy: 一時的なエラーの再試行ポリシー
+	pingTimeout time.Duration // ping timeout: IsConnectedのpingタイムアウト
+
+	// newListener creates a LISTEN connection (replaceable in tests)
+	// newListener: LISTEN用の接続を作成する関数（テストで差し替え可能）
+	newListener       listenerFactory
+	listenerErrorHook func(channel string, err error) // listener error hook: リスナー障害時のコールバック
+
+	verboseUntil     int64                  // verbose until: 詳細クエリログの期限（UnixNano、0は無効、アトミックに操作）
+	verboseMaxWindow time.Duration          // verbose max window: 詳細クエリログ有効期間の上限
+	verboseActor     atomic.Pointer[string] // verbose actor: 詳細クエリログを最後に有効化した操作者
+
+	authTokenProvider AuthTokenProvider // auth token provider: aws-iam認証時のトークン発行関数
+
+	credentialProvider CredentialProvider // credential provider: credential-provider認証時の認証情報の取得元
+	refreshTimer       *time.Timer        // refresh timer: リース失効前の認証情報更新タイマー（muで保護）
+	refreshGen         int64              // refresh generation: 予約済み更新の世代、停止や再接続で進む（muで保護）
+	credentialErr      error              // credential error: 直近の認証情報更新の失敗（muで保護）
+	poolDrainDelay     time.Duration      // pool drain delay: 入れ替え後に古いプールを閉じるまでの猶予時間
+
+	serverVersionNum int // server version: server_version_numのキャッシュ、0は未取得（muで保護）
+
+	breaker circuitBreaker // breaker: Reconnectのサーキットブレーカー（muで保護）
+
+	lazyConnect bool             // lazy connect: 初回使用時に接続する
+	lazy        lazyConnectState // lazy: 遅延接続の試行状態
+
+	watchdog           watchdogState // watchdog: 統計ロガーの生存信号（muで保護）
+	readinessMigration uint          // readiness migration: Readinessで必須とするマイグレーションバージョン、0は確認なし
+
+	statsHistory statsHistory // stats history: 接続統計の履歴
+
+	hooks lifecycleHooks // hooks: 接続・切断・再接続のライフサイクルフック
+
+	events       eventLog // events: 接続・切断・再接続などのイベントログ
+	healthEvents int      // health events: HealthCheckの結果に含める直近のイベント数、0は含めない
+
+	connectedHost string // connected host: 複数ホスト指定時に接続したホスト（muで保護）
+	connectedPort int    // connected port: 複数ホスト指定時に接続したポート（muで保護）
+
+	reconnectRecoveries int64      // reconnect recoveries: 再接続と再試行で回復した文の数（アトミックに操作）
+	autoReconnectMu     sync.Mutex // auto reconnect mutex: 同時に失敗した呼び出し元の再接続をまとめるロック
+	restartReconnecting int32      // restart reconnecting: サーバー再起動の検出によるバックグラウンド再接続の実行中は1（アトミックに操作）
+
+	acquireTimeout  time.Duration // acquire timeout: クエリヘルパーが空き接続を待つ上限、0は無制限
+	poolExhaustions int64         // pool exhaustions: 取得タイムアウトでErrPoolExhaustedを返した回数（アトミックに操作）
+
+	resolver Resolver // resolver: 接続前のホスト名の事前確認に使うリゾルバー
+
+	logSessionSettings bool // log session settings: 接続時に設定したrun-time parameterの実際の値をデバッグログに出す
+
+	configLoader func() (*DatabaseConfig, error) // config loader: ReloadConfigが読み込む設定の取得元、nilならLoadDatabaseConfig
+	reloadMu     sync.Mutex                      // reload mutex: 同時のReloadConfigを直列化するロック
 }
 
 // LoadDatabaseConfig loads database configuration from environment variables
 // LoadDatabaseConfig: 環境変数からデータベース設定を読み込む関数
 // loads: 読み込む、environment: 環境、variables: 変数（複数形）
+// Only the process environment is read; call LoadDotEnv first to use a .env file
+// プロセスの環境変数のみを読む、.envファイルを使う場合は先にLoadDotEnvを呼ぶ
 func LoadDatabaseConfig() (*DatabaseConfig, error) {
-	// Load environment variables from .env file
-	// environment: 環境、variables: 変数、from: から
-	if err := godotenv.Load(); err != nil {
-		log.Printf("Warning: .env file not found: %v", err) // warning: 警告、found: 見つかった
-	}
+	return loadDatabaseConfig(&configSources{})
+}
 
-	// Get database configuration from environment variables
+// loadDatabaseConfig loads the configuration, reading the connection fields through sources
+// loadDatabaseConfig: 接続フィールドをsources経由で読み込み、設定を構築する関数
+// sources may carry command-line flag values that take precedence over the environment
+// sourcesはコマンドラインフラグの値を持つことがあり、環境変数より優先される
+func loadDatabaseConfig(sources *configSources) (*DatabaseConfig, error) {
+	// Get database configuration from environment variables, falling back to libpq's PG* variables
+	// 環境変数から設定を取得する、未設定の場合はlibpqのPG*環境変数を使う
 	// configuration: 設定
-	host := os.Getenv("DB_HOST")
+	host := sources.lookupWithFallback("host", "DB_HOST", "PGHOST")
 	if host == "" {
 		host = "localhost" // default: デフォルト、既定値
 	}
 
-	portStr := os.Getenv("DB_PORT")
+	// A single port, or one port per host such as "5432,5433"
+	// 単一のポート、または"5432,5433"のようなホストごとのポート
+	portStr := sources.lookupWithFallback("port", "DB_PORT", "PGPORT")
 	if portStr == "" {
 		portStr = "5432" // default PostgreSQL port
 	}
-	port, err := strconv.Atoi(portStr) // Atoi: ASCII to integer（ASCII文字列から整数へ）
+	port, ports, err := parsePortList(portStr)
 	if err != nil {
 		return nil, fmt.Errorf("invalid port number: %v", err) // invalid: 無効な、number: 数
 	}
 
-	user := os.Getenv("DB_USER")
-	if user == "" {
-		return nil, fmt.Errorf("DB_USER environment variable is required") // required: 必要な
+	authMethod := strings.ToLower(strings.TrimSpace(os.Getenv("DB_AUTH_METHOD"))) // auth method: 認証方式
+	if authMethod == "" {
+		authMethod = AuthMethodPassword
 	}
 
-	password := os.Getenv("DB_PASSWORD")
-	if password == "" {
-		return nil, fmt.Errorf("DB_PASSWORD environment variable is required")
+	// A credential provider supplies both the user and the password
+	// CredentialProviderはユーザー名とパスワードの両方を提供する
+	user := sources.lookupWithFallback("user", "DB_USER", "PGUSER")
+	if user == "" && authMethod != AuthMethodCredentialProvider {
+		return nil, sources.requiredError("user", "DB_USER", "PGUSER")
 	}
 
-	database := os.Getenv("DB_NAME")
+	// IAM authentication mints a token per connect instead of using a password
+	// IAM認証はパスワードの代わりに接続ごとにトークンを発行する
+	password := sources.lookupWithFallback("password", "DB_PASSWORD", "PGPASSWORD")
+
+	database := sources.lookupWithFallback("database", "DB_NAME", "PGDATABASE")
 	if database == "" {
-		return nil, fmt.Errorf("DB_NAME environment variable is required")
+		return nil, sources.requiredError("database", "DB_NAME", "PGDATABASE")
 	}
 
-	sslMode := os.Getenv("DB_SSL_MODE")
+	if password == "" && authMethod == AuthMethodPassword {
+		// With DB_USE_PGPASS=true the pgpass file supplies a password no variable provides
+		// DB_USE_PGPASS=trueの場合、どの環境変数にもないパスワードをpgpassファイルから取得する
+		usePgpass, err := pgpassEnabled()
+		if err != nil {
+			return nil, err
+		}
+		if !usePgpass {
+			return nil, requiredEnvError("DB_PASSWORD", "PGPASSWORD")
+		}
+		path, err := pgpassPath()
+		if err == nil {
+			password, err = lookupPgpass(path, &DatabaseConfig{Host: host, Port: port, Ports: ports, User: user, Database: database})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%v and the pgpass file did not supply one: %w", requiredEnvError("DB_PASSWORD", "PGPASSWORD"), err)
+		}
+		sources.override("password", "pgpass")
+	}
+
+	sslMode := strings.ToLower(strings.TrimSpace(sources.lookupWithFallback("sslmode", "DB_SSL_MODE", "PGSSLMODE"))) // normalize case: 大文字小文字を正規化
 	if sslMode == "" {
 		sslMode = "require" // default: secure SSL mode
 	}
+	if err := checkProductionSSLMode(sslMode); err != nil {
+		return nil, err
+	}
+
+	// Slow query threshold in milliseconds (0 disables detection)
+	// slow: 遅い、threshold: しきい値、milliseconds: ミリ秒
+	var slowQueryThreshold time.Duration
+	if slowQueryStr := os.Getenv("DB_SLOW_QUERY_MS"); slowQueryStr != "" {
+		slowQueryMS, err := strconv.Atoi(slowQueryStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slow query threshold: %v", err)
+		}
+		slowQueryThreshold = time.Duration(slowQueryMS) * time.Millisecond
+	}
+
+	// Maximum idle time per connection as a duration string such as "45s"
+	// 接続ごとの最大アイドル時間（"45s"などの時間文字列）
+	var connMaxIdleTime time.Duration
+	if idleTimeStr := os.Getenv("DB_CONN_MAX_IDLE_TIME"); idleTimeStr != "" {
+		connMaxIdleTime, err = time.ParseDuration(idleTimeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid connection max idle time: %v", err)
+		}
+	}
+
+	// Pool size and connection lifetime, unset keeps the defaults
+	// プールの大きさと接続の寿命、未設定の場合はデフォルトのまま
+	var maxOpenConns, maxIdleConns int
+	if maxOpenStr := os.Getenv("DB_MAX_OPEN_CONNS"); maxOpenStr != "" {
+		maxOpenConns, err = strconv.Atoi(maxOpenStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max open connections: %v", err)
+		}
+	}
+	if maxIdleStr := os.Getenv("DB_MAX_IDLE_CONNS"); maxIdleStr != "" {
+		maxIdleConns, err = strconv.Atoi(maxIdleStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max idle connections: %v", err)
+		}
+	}
+	var connMaxLifetime time.Duration
+	if lifetimeStr := os.Getenv("DB_CONN_MAX_LIFETIME"); lifetimeStr != "" {
+		connMaxLifetime, err = time.ParseDuration(lifetimeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid connection max lifetime: %v", err)
+		}
+	}
+	var connMaxLifetimeJitter float64
+	if jitterStr := os.Getenv("DB_CONN_MAX_LIFETIME_JITTER"); jitterStr != "" {
+		connMaxLifetimeJitter, err = strconv.ParseFloat(jitterStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid connection max lifetime jitter: %v", err) // jitter: ゆらぎ
+		}
+	}
+
+	// Connection attempt timeout as a duration string such as "10s"
+	// 接続試行の制限時間（"10s"などの時間文字列）
+	var connectTimeout time.Duration
+	if connectTimeoutStr := os.Getenv("DB_CONNECT_TIMEOUT"); connectTimeoutStr != "" {
+		connectTimeout, err = time.ParseDuration(connectTimeoutStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid connect timeout: %v", err)
+		}
+	}
+
+	// Statement timeout for contexts without a deadline as a duration string such as "30s"
+	// 期限のないコンテキストで実行する文の制限時間（"30s"などの時間文字列）
+	queryTimeout := defaultQueryTimeout
+	if queryTimeoutStr := os.Getenv("DB_QUERY_TIMEOUT"); queryTimeoutStr != "" {
+		queryTimeout, err = time.ParseDuration(queryTimeoutStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query timeout: %v", err)
+		}
+		if queryTimeout <= 0 {
+			return nil, fmt.Errorf("invalid query timeout: must be positive") // positive: 正の
+		}
+	}
+
+	// Extra libpq parameters in "key=value key2=value2" form
+	// "key=value key2=value2"形式の追加のlibpqパラメータ
+	var options map[string]string
+	if optionsStr := os.Getenv("DB_OPTIONS"); optionsStr != "" {
+		options, err = ParseOptions(optionsStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DB_OPTIONS: %v", err)
+		}
+	}
+
+	// Session lock and idle-in-transaction timeouts as duration strings such as "5s"
+	// セッションのロック待ちとトランザクション内アイドルの制限時間（"5s"などの時間文字列）
+	var lockTimeout, idleInTransactionTimeout time.Duration
+	if lockTimeoutStr := os.Getenv("DB_LOCK_TIMEOUT"); lockTimeoutStr != "" {
+		lockTimeout, err = time.ParseDuration(lockTimeoutStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lock timeout: %v", err)
+		}
+	}
+	if idleTxStr := os.Getenv("DB_IDLE_IN_TX_TIMEOUT"); idleTxStr != "" {
+		idleInTransactionTimeout, err = time.ParseDuration(idleTxStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid idle in transaction timeout: %v", err)
+		}
+	}
+
+	// Read-only mode as a boolean such as "true"
+	// 読み取り専用モード（"true"などの真偽値）
+	var readOnly bool
+	if readOnlyStr := strings.TrimSpace(os.Getenv("DB_READ_ONLY")); readOnlyStr != "" {
+		readOnly, err = strconv.ParseBool(readOnlyStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid read only mode: %v", err)
+		}
+	}
+
+	// TCP keepalives: DB_KEEPALIVES as a boolean, idle time and interval as duration strings such as "30s", and a probe count
+	// TCPキープアライブ: DB_KEEPALIVESは真偽値、アイドル時間と間隔は"30s"などの時間文字列、プローブ回数は整数
+	var disableKeepalives bool
+	if keepalivesStr := strings.TrimSpace(os.Getenv("DB_KEEPALIVES")); keepalivesStr != "" {
+		keepalives, err := strconv.ParseBool(keepalivesStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keepalives: %v", err)
+		}
+		disableKeepalives = !keepalives
+	}
+	var keepalivesIdle, keepalivesInterval time.Duration
+	if idleStr := os.Getenv("DB_KEEPALIVES_IDLE"); idleStr != "" {
+		keepalivesIdle, err = time.ParseDuration(idleStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keepalives idle: %v", err)
+		}
+	}
+	if intervalStr := os.Getenv("DB_KEEPALIVES_INTERVAL"); intervalStr != "" {
+		keepalivesInterval, err = time.ParseDuration(intervalStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keepalives interval: %v", err)
+		}
+	}
+	var keepalivesCount int
+	if countStr := os.Getenv("DB_KEEPALIVES_COUNT"); countStr != "" {
+		keepalivesCount, err = strconv.Atoi(countStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keepalives count: %v", err)
+		}
+	}
+
+	fallbackApplicationName := strings.TrimSpace(os.Getenv("DB_FALLBACK_APPLICATION_NAME")) // e.g. "sift-api": 例 "sift-api"
+
+	minServerVersion := strings.TrimSpace(os.Getenv("DB_MIN_SERVER_VERSION")) // e.g. "13.0": 例 "13.0"
+
+	targetSessionAttrs := strings.ToLower(strings.TrimSpace(os.Getenv("DB_TARGET_SESSION_ATTRS"))) // e.g. "read-write": 例 "read-write"
+
+	timeZone := os.Getenv("DB_TIMEZONE")
+	if timeZone == "" {
+		timeZone = defaultTimeZone
+	}
+
+	debugf("Database configuration sources: %s", sources)
 
 	return &DatabaseConfig{
-		Host:     host,
-		Port:     port,
-		User:     user,
-		Password: password,
-		Database: database,
-		SSLMode:  sslMode,
+		Host:                host,
+		Port:                port,
+		Ports:               ports,
+		User:                user,
+		Password:            password,
+		Database:            database,
+		SSLMode:             sslMode,
+		SlowQueryThreshold:  slowQueryThreshold,
+		ConnMaxIdleTime:     connMaxIdleTime,
+		MaxOpenConns:        maxOpenConns,
+		MaxIdleConns:        maxIdleConns,
+		ConnMaxLifetime:     connMaxLifetime,
+		TimeZone:            timeZone,
+		ConnectTimeout:      connectTimeout,
+		DefaultQueryTimeout: queryTimeout,
+		MinServerVersion:    minServerVersion,
+		Options:             options,
+		TargetSessionAttrs:  targetSessionAttrs,
+		AuthMethod:          authMethod,
+
+		LockTimeout:              lockTimeout,
+		IdleInTransactionTimeout: idleInTransactionTimeout,
+		ReadOnly:                 readOnly,
+		ConnMaxLifetimeJitter:    connMaxLifetimeJitter,
+
+		FallbackApplicationName: fallbackApplicationName,
+		DisableKeepalives:       disableKeepalives,
+		KeepalivesIdle:          keepalivesIdle,
+		KeepalivesInterval:      keepalivesInterval,
+		KeepalivesCount:         keepalivesCount,
 	}, nil
 }
 
@@ -92,56 +492,137 @@ func LoadDatabaseConfig() (*DatabaseConfig, error) {
 // BuildConnectionString: 設定からPostgreSQL接続文字列を構築する関数
 // builds: 構築する、connection: 接続、string: 文字列
 func (c *DatabaseConfig) BuildConnectionString() string {
-	return fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		c.Host,
-		c.Port,
-		c.User,
-		c.Password,
-		c.Database,
+	host, port := c.formatHosts() // IPv6 literals are quoted: IPv6リテラルは引用符で囲む
+	connectionString := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		host,
+		port,
+		escapeDSNValue(c.User),
+		escapeDSNValue(c.Password), // passwords may contain spaces or quotes: パスワードは空白や引用符を含むことがある
+		escapeDSNValue(c.Database),
 		c.SSLMode,
 	)
+
+	// Unknown keys are sent by lib/pq as run-time parameters
+	// lib/pqは未知のキーをrun-time parameterとして送信する
+	if c.TimeZone != "" {
+		connectionString += " timezone=" + escapeDSNValue(c.TimeZone)
+	}
+	if c.ConnectTimeout > 0 {
+		connectionString += " connect_timeout=" + c.connectTimeoutSeconds()
+	}
+	if c.TargetSessionAttrs != "" {
+		connectionString += " target_session_attrs=" + c.TargetSessionAttrs
+	}
+	for _, param := range c.sessionParameters() {
+		connectionString += " " + param.key + "=" + param.value
+	}
+	if c.FallbackApplicationName != "" {
+		connectionString += " fallback_application_name=" + escapeDSNValue(c.FallbackApplicationName)
+	}
+	for _, param := range c.keepaliveParameters() {
+		connectionString += " " + param.key + "=" + param.value
+	}
+
+	// Extra parameters follow the structured fields
+	// 追加のパラメータは構造化フィールドの後に続ける
+	for _, key := range sortedOptionKeys(c.Options) {
+		connectionString += " " + key + "=" + escapeDSNValue(c.Options[key])
+	}
+	return connectionString
 }
 
 // NewPostgreSQLDriver creates a new PostgreSQL driver instance
 // NewPostgreSQLDriver: 新しいPostgreSQLドライバーインスタンスを作成するファクトリー関数
 // creates: 作成する、instance: インスタンス
-func NewPostgreSQLDriver() (*PostgreSQLDriver, error) {
+func NewPostgreSQLDriver(opts ...DriverOption) (*PostgreSQLDriver, error) {
 	// Load database configuration
 	// load: 読み込む
 	config, err := LoadDatabaseConfig()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load database configuration: %w", err) // failed: 失敗した
+		return nil, fmt.Errorf("%w: %w", ErrConfigInvalid, err) // failed: 失敗した
 	}
 
-	// Create PostgreSQL driver instance
-	// create: 作成する
-	driver := &PostgreSQLDriver{
-		config: config,
+	// Validate configuration like NewPostgreSQLDriverWithConfig
+	// NewPostgreSQLDriverWithConfigと同様に設定を検証する
+	if err := validateDatabaseConfig(config); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrConfigInvalid, err)
 	}
 
-	return driver, nil
+	// Create PostgreSQL driver instance
+	// create: 作成する
+	return newDriver(config, opts), nil
 }
 
 // NewPostgreSQLDriverWithConfig creates a new PostgreSQL driver with custom configuration
 // NewPostgreSQLDriverWithConfig: カスタム設定で新しいPostgreSQLドライバーを作成するファクトリー関数
 // custom: カスタム、独自の
-func NewPostgreSQLDriverWithConfig(config *DatabaseConfig) (*PostgreSQLDriver, error) {
+func NewPostgreSQLDriverWithConfig(config *DatabaseConfig, opts ...DriverOption) (*PostgreSQLDriver, error) {
 	if config == nil {
-		return nil, fmt.Errorf("database configuration cannot be nil") // cannot: できない、nil: ヌル値
+		return nil, fmt.Errorf("%w: configuration cannot be nil", ErrConfigInvalid) // cannot: できない、nil: ヌル値
 	}
 
 	// Validate configuration
 	// validate: 検証する
 	if err := validateDatabaseConfig(config); err != nil {
-		return nil, fmt.Errorf("invalid database configuration: %w", err) // invalid: 無効な
+		return nil, fmt.Errorf("%w: %w", ErrConfigInvalid, err) // invalid: 無効な
 	}
 
+	return newDriver(config, opts), nil
+}
+
+// newDriver builds a driver with defaults and applies the options
+// newDriver: デフォルト値でドライバーを構築し、オプションを適用する関数
+func newDriver(config *DatabaseConfig, opts []DriverOption) *PostgreSQLDriver {
+	owned := cloneConfig(config) // copy so the caller cannot change settings behind the driver's back: 呼び出し元が設定を裏で変更できないようコピー
+	owned.applyDefaults()
 	driver := &PostgreSQLDriver{
-		config: config,
+		now:    time.Now,
+		openDB: openPostgres,
+		pressure: poolPressureDetector{
+			saturationDuration: defaultPoolSaturationDuration,
+		},
+		retryPolicy:      DefaultRetryPolicy,
+		resolver:         net.DefaultResolver,
+		logger:           stdLogger{},
+		pingTimeout:      defaultPingTimeout,
+		newListener:      newPQListener,
+		verboseMaxWindow: defaultVerboseLogMaxWindow,
+		poolDrainDelay:   defaultPoolDrainDelay,
+		breaker: circuitBreaker{
+			threshold: defaultBreakerThreshold,
+			cooldown:  defaultBreakerCooldown,
+			state:     CircuitClosed,
+		},
 	}
+	driver.config.Store(&owned)
+	driver.applyOptions(opts)
+
+	return driver
+}
 
-	return driver, nil
+// applyDefaults fills optional fields left empty in programmatic configurations
+// applyDefaults: プログラムで作成された設定の未設定の任意フィールドを埋める関数
+// programmatic: プログラムによる
+func (c *DatabaseConfig) applyDefaults() {
+	if c.TimeZone == "" {
+		c.TimeZone = defaultTimeZone // sessions default to UTC: セッションはデフォルトでUTC
+	}
+	if c.AuthMethod == "" {
+		c.AuthMethod = AuthMethodPassword
+	}
+	if c.DefaultQueryTimeout == 0 {
+		c.DefaultQueryTimeout = defaultQueryTimeout
+	}
+	if c.MaxOpenConns == 0 {
+		c.MaxOpenConns = defaultMaxOpenConns
+	}
+	if c.MaxIdleConns == 0 {
+		c.MaxIdleConns = min(defaultMaxIdleConns, c.MaxOpenConns) // never more idle than open: アイドル数は最大接続数を超えない
+	}
+	if c.ConnMaxLifetime == 0 {
+		c.ConnMaxLifetime = defaultConnMaxLifetime
+	}
 }
 
 // validateDatabaseConfig validates database configuration
@@ -152,15 +633,34 @@ func validateDatabaseConfig(config *DatabaseConfig) error {
 		return fmt.Errorf("host cannot be empty") // empty: 空の
 	}
 
+	if err := validateHostList(config); err != nil {
+		return err
+	}
+
 	if config.Port <= 0 || config.Port > 65535 {
 		return fmt.Errorf("port must be between 1 and 65535") // between: 間に、must: しなければならない
 	}
 
-	if config.User == "" {
+	authMethod := config.AuthMethod
+	if authMethod == "" {
+		authMethod = AuthMethodPassword
+	}
+	validAuth := false
+	for _, method := range validAuthMethods {
+		if authMethod == method {
+			validAuth = true
+			break
+		}
+	}
+	if !validAuth {
+		return fmt.Errorf("invalid auth method: %s (accepted: %s)", config.AuthMethod, strings.Join(validAuthMethods, ", "))
+	}
+
+	if config.User == "" && authMethod != AuthMethodCredentialProvider {
 		return fmt.Errorf("user cannot be empty")
 	}
 
-	if config.Password == "" {
+	if config.Password == "" && authMethod == AuthMethodPassword {
 		return fmt.Errorf("password cannot be empty")
 	}
 
@@ -168,9 +668,6 @@ func validateDatabaseConfig(config *DatabaseConfig) error {
 		return fmt.Errorf("database name cannot be empty") // name: 名前
 	}
 
-	// Valid SSL modes for PostgreSQL
-	// valid: 有効な、modes: モード（複数形）
-	validSSLModes := []string{"disable", "require", "verify-ca", "verify-full"}
 	validMode := false
 	for _, mode := range validSSLModes {
 		if config.SSLMode == mode {
@@ -180,108 +677,471 @@ func validateDatabaseConfig(config *DatabaseConfig) error {
 	}
 
 	if !validMode {
-		return fmt.Errorf("invalid SSL mode: %s", config.SSLMode)
+		return fmt.Errorf("invalid SSL mode: %s (accepted: %s)", config.SSLMode, strings.Join(validSSLModes, ", ")) // accepted: 受け付ける
+	}
+
+	// A development sslmode copied into production is refused
+	// 開発用のsslmodeが本番環境に持ち込まれた場合は拒否する
+	if err := checkProductionSSLMode(config.SSLMode); err != nil {
+		return err
+	}
+
+	// RDS rejects IAM tokens sent over unencrypted connections
+	// RDSは暗号化されていない接続で送られたIAMトークンを拒否する
+	if authMethod == AuthMethodAWSIAM && config.SSLMode == "disable" {
+		return fmt.Errorf("aws-iam authentication requires SSL (sslmode cannot be disable)")
+	}
+
+	// An IAM token is signed for a single endpoint
+	// IAMトークンは単一のエンドポイントに対して署名される
+	if authMethod == AuthMethodAWSIAM && config.isMultiHost() {
+		return fmt.Errorf("aws-iam authentication supports a single host only")
+	}
+
+	if config.SlowQueryThreshold < 0 {
+		return fmt.Errorf("slow query threshold cannot be negative") // negative: 負の
+	}
+
+	if err := validatePoolSettings(config); err != nil {
+		return err
+	}
+
+	if config.ConnectTimeout < 0 {
+		return fmt.Errorf("connect timeout cannot be negative")
+	}
+
+	if config.DefaultQueryTimeout < 0 {
+		return fmt.Errorf("default query timeout cannot be negative")
+	}
+
+	if config.LockTimeout < 0 || config.IdleInTransactionTimeout < 0 {
+		return fmt.Errorf("lock and idle in transaction timeouts cannot be negative")
+	}
+
+	if err := validateKeepalives(config); err != nil {
+		return err
+	}
+
+	if err := validateOptions(config.Options); err != nil {
+		return err
+	}
+
+	if config.MinServerVersion != "" {
+		if _, err := parseMinServerVersion(config.MinServerVersion); err != nil {
+			return err
+		}
+	}
+
+	if config.TimeZone != "" {
+		// "Local" loads in Go but is not a name PostgreSQL understands
+		// "Local"はGoでは読み込めるがPostgreSQLが理解できる名前ではない
+		if _, err := time.LoadLocation(config.TimeZone); err != nil || config.TimeZone == "Local" {
+			return fmt.Errorf("invalid time zone: %s", config.TimeZone) // time zone: タイムゾーン
+		}
 	}
 
 	return nil
 }
 
+// validatePoolSettings checks the pool size and connection lifetimes
+// validatePoolSettings: プールの大きさと接続の寿命を検証する関数
+// A zero MaxOpenConns is the default and leaves MaxIdleConns unchecked until defaults are applied
+// MaxOpenConnsが0の場合は既定値を意味し、既定値の適用まではMaxIdleConnsを比較しない
+func validatePoolSettings(config *DatabaseConfig) error {
+	if config.ConnMaxIdleTime < 0 {
+		return fmt.Errorf("connection max idle time cannot be negative")
+	}
+
+	if config.MaxOpenConns < 0 || config.MaxIdleConns < 0 {
+		return fmt.Errorf("pool connection limits cannot be negative")
+	}
+
+	if config.MaxOpenConns > 0 && config.MaxIdleConns > config.MaxOpenConns {
+		return fmt.Errorf("max idle connections (%d) cannot exceed max open connections (%d)", config.MaxIdleConns, config.MaxOpenConns) // exceed: 超える
+	}
+
+	if config.ConnMaxLifetime < 0 {
+		return fmt.Errorf("connection max lifetime cannot be negative")
+	}
+
+	if config.ConnMaxLifetimeJitter < 0 || config.ConnMaxLifetimeJitter >= 1 {
+		return fmt.Errorf("connection max lifetime jitter must be at least 0 and below 1, got %g", config.ConnMaxLifetimeJitter)
+	}
+	return nil
+}
+
 // Connect establishes a connection to the PostgreSQL database
 // Connect: PostgreSQLデータベースへの接続を確立する関数
 // establishes: 確立する、connection: 接続
-func (d *PostgreSQLDriver) Connect() error {
+func (d *PostgreSQLDriver) Connect() (err error) {
+	ctx, span := d.startSpan(context.Background(), "db.Connect", "")
+	defer func() { endSpan(span, err) }()
+
+	if d.isClosed() {
+		return ErrDriverClosed
+	}
+	if d.pool() != nil {
+		return fmt.Errorf("%w: use Reconnect to replace the pool", ErrAlreadyConnected)
+	}
+	if err = d.config.Load().redactError(d.connect(ctx)); err != nil {
+		d.recordEvent(EventConnectFailed, "", err)
+		return err
+	}
+	d.recordEvent(EventConnect, "", nil)
+	return nil
+}
+
+// connect opens and verifies the connection pool
+// connect: 接続プールを開いて検証する内部関数
+// verifies: 検証する
+func (d *PostgreSQLDriver) connect(ctx context.Context) error {
+	// Apply a configuration staged by UpdateConfig
+	// UpdateConfigで準備された設定を適用する
+	d.mu.Lock()
+	if d.pendingConfig != nil {
+		d.config.Store(d.pendingConfig)
+		d.pendingConfig = nil
+	}
+	d.mu.Unlock()
+
+	db, lease, err := d.openPool(ctx)
+	if err != nil {
+		return err
+	}
+
+	d.setPool(db)
+	d.mu.Lock()
+	d.lastConnectTime = d.now()
+	d.mu.Unlock()
+	d.scheduleCredentialRefresh(lease)
+	if d.logSessionSettings {
+		d.logEffectiveSettings(ctx, db)
+	}
+	database := d.config.Load().Database
+	d.logger.Info(fmt.Sprintf("Successfully connected to PostgreSQL database: %s", database), "database", database) // successfully: 成功して
+	d.fireConnect()
+	return nil
+}
+
+// openPool opens a new pool with freshly resolved credentials
+// openPool: 新たに解決した認証情報で新しいプールを開く内部関数
+// It returns the credential lease (0 when credentials do not expire): 認証情報のリース期間も返す（失効しない場合は0）
+func (d *PostgreSQLDriver) openPool(ctx context.Context) (*sql.DB, time.Duration, error) {
+	// Resolve credentials, minting a fresh IAM token or lease on every connect and reconnect
+	// 認証情報を解決する、接続と再接続のたびに新しいIAMトークンやリースを取得する
+	config, lease, err := d.connectionConfig(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	db, err := d.openPoolWith(ctx, config)
+	if err != nil {
+		return nil, 0, err
+	}
+	return db, lease, nil
+}
+
+// openPoolOn opens, configures and pings a new pool for a single-host config
+// openPoolOn: 単一ホストのconfigで新しいプールを開き、設定し、pingする内部関数
+// Errors are redacted with config's password: エラーはconfigのパスワードで伏せ字にする
+func (d *PostgreSQLDriver) openPoolOn(ctx context.Context, config *DatabaseConfig) (*sql.DB, error) {
+	// Catch a mistyped hostname before the pool hides it behind a dial error
+	// プールがダイヤルエラーの陰に隠す前に、ホスト名の打ち間違いを検出する
+	if err := d.checkHostResolves(ctx, config); err != nil {
+		return nil, err
+	}
+
 	// Build connection string
 	// build: 構築する
-	connectionString := d.config.BuildConnectionString()
+	connectionString := config.BuildConnectionString()
 
 	// Open database connection
 	// open: 開く
-	db, err := sql.Open("postgres", connectionString)
+	db, err := d.openDB(connectionString)
 	if err != nil {
-		return fmt.Errorf("failed to open database connection: %w", err)
+		return nil, config.redactError(fmt.Errorf("failed to open database connection: %w", err)) // token is secret too: トークンも秘密情報
+	}
+	if config.AuthMethod == AuthMethodAWSIAM || config.ConnMaxLifetimeJitter > 0 {
+		// Mint tokens per connection (see auth.go) and give every connection its own lifetime (see jitter.go)
+		// 接続ごとにトークンを発行し（auth.goを参照）、接続ごとに寿命を持たせる（jitter.goを参照）
+		if db, err = d.reopenPool(db, connectionString, config); err != nil {
+			return nil, config.redactError(fmt.Errorf("failed to open database connection: %w", err))
+		}
 	}
 
 	// Configure connection pool
 	// configure: 設定する、pool: プール、接続プール
-	db.SetMaxOpenConns(25)                 // maximum: 最大の、open: 開いている、connections: 接続（複数形）
-	db.SetMaxIdleConns(5)                  // idle: アイドル、待機中の
-	db.SetConnMaxLifetime(5 * time.Minute) // lifetime: 寿命、minute: 分
+	applyPoolSettings(db, config)
 
 	// Test database connection
 	// test: テスト、試験
-	if err := db.Ping(); err != nil {
-		db.Close()                                            // Close database if ping fails
-		return fmt.Errorf("failed to ping database: %w", err) // ping: 接続確認
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()                                                                     // Close database if ping fails
+		return nil, config.redactError(fmt.Errorf("failed to ping database: %w", err)) // ping: 接続確認
 	}
 
+	// Refuse servers older than MinServerVersion
+	// MinServerVersionより古いサーバーを拒否する
+	version, err := checkServerVersion(ctx, db, config)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	d.mu.Lock()
+	d.serverVersionNum = version // 0 until GetServerVersion queries it: GetServerVersionが問い合わせるまで0
+	d.mu.Unlock()
+
+	return db, nil
+}
+
+// reopenPool reopens db, a pool that has not connected yet, through the connectors config asks for
+// reopenPool: まだ接続していないプールdbを、configが必要とするコネクター経由で開き直す関数
+func (d *PostgreSQLDriver) reopenPool(db *sql.DB, connectionString string, config *DatabaseConfig) (*sql.DB, error) {
+	var connector driver.Connector
+	if config.AuthMethod == AuthMethodAWSIAM {
+		connector = d.newIAMConnector(db.Driver(), config)
+	} else {
+		var err error
+		if connector, err = openConnector(db.Driver(), connectionString); err != nil {
+			db.Close() // do not leak the pool it came from: 元のプールをリークさせない
+			return nil, err
+		}
+	}
+	db.Close()
+
+	if config.ConnMaxLifetimeJitter > 0 {
+		connector = d.withLifetimeJitter(connector)
+	}
+	return sql.OpenDB(connector), nil
+}
+
+// pool returns the current connection pool, or nil before Connect
+// pool: 現在の接続プールを返す関数、Connect前はnil
+// The pool can be swapped by a credential refresh, so reads go through poolMu
+// 認証情報の更新でプールが入れ替わるため、読み取りはpoolMuを通す
+func (d *PostgreSQLDriver) pool() *sql.DB {
+	d.poolMu.RLock()
+	defer d.poolMu.RUnlock()
+	return d.db
+}
+
+// usablePool returns the current pool, or ErrDriverClosed after Close and ErrNotConnected before Connect
+// usablePool: 現在のプールを返す関数、Close後はErrDriverClosed、Connect前はErrNotConnectedを返す
+func (d *PostgreSQLDriver) usablePool() (*sql.DB, error) {
+	if d.isClosed() {
+		return nil, ErrDriverClosed
+	}
+	db := d.pool()
+	if db == nil {
+		return nil, fmt.Errorf("%w: connection is not established", ErrNotConnected)
+	}
+	return db, nil
+}
+
+// isClosed reports whether Close has been called
+// isClosed: Closeが呼ばれたかどうかを判定する関数
+func (d *PostgreSQLDriver) isClosed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+// setPool replaces the current connection pool and returns the previous one
+// setPool: 現在の接続プールを置き換え、以前のプールを返す関数
+func (d *PostgreSQLDriver) setPool(db *sql.DB) *sql.DB {
+	d.poolMu.Lock()
+	defer d.poolMu.Unlock()
+	previous := d.db
 	d.db = db
-	log.Printf("Successfully connected to PostgreSQL database: %s", d.config.Database) // successfully: 成功して
-	return nil
+	return previous
 }
 
 // GetDB returns the database connection
 // GetDB: データベース接続を返す関数
 // returns: 返す
+// With WithLazyConnect the first call connects: WithLazyConnectの場合は初回呼び出しで接続する
 func (d *PostgreSQLDriver) GetDB() *sql.DB {
-	return d.db
+	db, err := d.connectedPool()
+	if err != nil {
+		return nil // nil when not connected, as before: 従来どおり未接続ならnil
+	}
+	return db
 }
 
-// GetConfig returns the database configuration
-// GetConfig: データベース設定を返す関数
+// GetConfig returns a copy of the configuration the driver is using
+// GetConfig: ドライバーが使用中の設定のコピーを返す関数
+// Mutating the copy does not affect the driver; use UpdateConfig instead
+// コピーを変更してもドライバーには影響しない、変更にはUpdateConfigを使用する
 func (d *PostgreSQLDriver) GetConfig() *DatabaseConfig {
-	return d.config
+	config := cloneConfig(d.config.Load())
+	return &config
+}
+
+// GetTimeZone returns the session time zone the driver connects with
+// GetTimeZone: ドライバーが接続時に使用するセッションのタイムゾーンを返す関数
+func (d *PostgreSQLDriver) GetTimeZone() string {
+	return d.config.Load().TimeZone
+}
+
+// UpdateConfig validates a new configuration and stages it for the next Connect or Reconnect
+// UpdateConfig: 新しい設定を検証し、次回のConnectまたはReconnectで使われるよう準備する関数
+// stages: 準備する、適用待ちにする
+func (d *PostgreSQLDriver) UpdateConfig(config *DatabaseConfig) error {
+	if config == nil {
+		return fmt.Errorf("%w: configuration cannot be nil", ErrConfigInvalid)
+	}
+	if err := validateDatabaseConfig(config); err != nil {
+		return fmt.Errorf("%w: %w", ErrConfigInvalid, err)
+	}
+
+	staged := cloneConfig(config) // copy so later caller mutations are ignored: 呼び出し元の後からの変更を無視するためコピー
+	staged.applyDefaults()
+	d.mu.Lock()
+	d.pendingConfig = &staged
+	d.mu.Unlock()
+	return nil
 }
 
 // Close closes the database connection
 // Close: データベース接続を閉じる関数
 // closes: 閉じる
+// The driver cannot be connected again afterwards; calling Close again does nothing
+// 以降ドライバーを再び接続することはできない、Closeを再度呼んでも何もしない
 func (d *PostgreSQLDriver) Close() error {
-	if d.db != nil {
-		if err := d.db.Close(); err != nil {
+	// Stop background goroutines started on this driver
+	// background: バックグラウンド、goroutines: ゴルーチン（複数形）
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	// The closed channel stays, so goroutines started after Close see it closed and stop
+	// 閉じたチャネルは残すため、Close後に開始したゴルーチンも閉じていると判断して停止する
+	if d.closing == nil {
+		d.closing = make(chan struct{})
+	}
+	close(d.closing)
+	d.stopCredentialRefreshLocked()
+	db := d.setPool(nil) // IsOpen and IsConnected report false from here: 以降IsOpenとIsConnectedはfalseを返す
+	d.mu.Unlock()
+
+	if db != nil {
+		d.fireDisconnect()
+		if err := db.Close(); err != nil {
 			return fmt.Errorf("failed to close database connection: %w", err) // close: 閉じる
 		}
-		log.Println("Database connection closed successfully")
+		d.logger.Info("Database connection closed successfully")
 	}
 	return nil
 }
 
-// IsConnected checks if the database connection is active
-// IsConnected: データベース接続がアクティブかどうかを確認する関数
+// IsConnected checks if the database connection is active, giving up after the ping timeout
+// IsConnected: データベース接続がアクティブかどうかを確認する関数、pingタイムアウトで打ち切る
 // checks: 確認する、active: アクティブ、活発な
 func (d *PostgreSQLDriver) IsConnected() bool {
-	if d.db == nil {
+	ctx, cancel := context.WithTimeout(context.Background(), d.pingTimeout)
+	defer cancel()
+
+	return d.IsConnectedContext(ctx)
+}
+
+// IsConnectedContext checks if the database answers a ping before ctx ends
+// IsConnectedContext: ctxが終了する前にデータベースがpingに応答するかを確認する関数
+func (d *PostgreSQLDriver) IsConnectedContext(ctx context.Context) bool {
+	db := d.pool()
+	if db == nil {
 		return false
 	}
 
 	// Test connection with ping
-	if err := d.db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		return false
 	}
 
 	return true
 }
 
+// IsOpen reports whether a connection pool has been assigned, without any network round trip
+// IsOpen: ネットワーク通信なしで接続プールが割り当て済みかどうかを判定する関数
+// assigned: 割り当てられた
+func (d *PostgreSQLDriver) IsOpen() bool {
+	return d.pool() != nil
+}
+
 // Reconnect attempts to reconnect to the database
 // Reconnect: データベースへの再接続を試行する関数
 // attempts: 試行する、reconnect: 再接続
-func (d *PostgreSQLDriver) Reconnect() error {
+func (d *PostgreSQLDriver) Reconnect() (err error) {
+	ctx, span := d.startSpan(context.Background(), "db.Reconnect", "")
+	defer func() { endSpan(span, err) }()
+
+	return d.reconnect(ctx)
+}
+
+// reconnect makes a single reconnect attempt bounded by ctx
+// reconnect: ctxの範囲内で1回だけ再接続を試行する内部関数
+func (d *PostgreSQLDriver) reconnect(ctx context.Context) error {
+	if d.isClosed() {
+		return ErrDriverClosed
+	}
+
+	// Fail fast while the circuit breaker is open
+	// サーキットブレーカーが開いている間は即座に失敗する
+	d.mu.Lock()
+	transition, err := d.allowReconnectLocked()
+	d.mu.Unlock()
+	runTransition(transition)
+	if err != nil {
+		return err
+	}
+	d.recordEvent(EventReconnectAttempt, "", nil)
+
 	// Close existing connection if any
 	// existing: 既存の、if: もし、any: 何らかの
-	if d.db != nil {
-		d.db.Close()
+	if db := d.pool(); db != nil {
+		d.fireDisconnect()
+		db.Close()
 	}
 
 	// Attempt to reconnect
 	// attempt: 試行する
-	return d.Connect()
+	connectErr := d.connect(ctx)
+
+	// Record the outcome, and a successful reconnect
+	// 結果と、成功した再接続を記録する
+	d.mu.Lock()
+	transition = d.recordReconnectLocked(connectErr)
+	if connectErr == nil {
+		d.lastReconnectTime = d.lastConnectTime
+		d.totalReconnects++
+	}
+	d.mu.Unlock()
+	runTransition(transition)
+
+	if connectErr != nil {
+		d.recordEvent(EventReconnectFailure, "", connectErr)
+		return d.config.Load().redactError(connectErr)
+	}
+	d.recordEvent(EventReconnectSuccess, "", nil)
+	d.fireReconnect()
+	return nil
 }
 
-// GetConnectionStats returns database connection statistics
-// GetConnectionStats: データベース接続統計を返す関数
-// statistics: 統計
-func (d *PostgreSQLDriver) GetConnectionStats() sql.DBStats {
-	if d.db == nil {
-		return sql.DBStats{}
+// closeSignal returns a channel that is closed by Close, already closed once Close has run
+// closeSignal: Closeで閉じられるチャネルを返す関数、Close後は既に閉じている
+func (d *PostgreSQLDriver) closeSignal() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closing == nil {
+		d.closing = make(chan struct{})
 	}
-	return d.db.Stats()
+	return d.closing
+}
+
+// openPostgres opens a lib/pq connection pool, dialing with the connection string's keepalive settings
+// openPostgres: 接続文字列のキープアライブ設定でダイヤルするlib/pqの接続プールを開く関数
+func openPostgres(connectionString string) (*sql.DB, error) {
+	return sql.OpenDB(dsnConnector{dsn: connectionString, driver: keepaliveDriver{}}), nil
 }
@@ -1,8 +1,12 @@
 package database
 
 import (
-	"os"      // os: operating system（オペレーティングシステム）
-	"testing" // testing: テスト機能
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラーの判定
+	"os"           // os: operating system（オペレーティングシステム）
+	"strings"      // strings: 文字列操作
+	"testing"      // testing: テスト機能
+	"time"         // time: 時間操作機能
 )
 
 // TestLoadDatabaseConfig tests database configuration loading
@@ -301,6 +305,56 @@ func TestValidateDatabaseConfig(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "Negative slow query threshold",
+			config: &DatabaseConfig{
+				Host:               "localhost",
+				Port:               5432,
+				User:               "user",
+				Password:           "pass",
+				Database:           "db",
+				SSLMode:            "require",
+				SlowQueryThreshold: -time.Second,
+			},
+			expectError: true,
+		},
+		{
+			name: "Negative connection max idle time",
+			config: &DatabaseConfig{
+				Host:            "localhost",
+				Port:            5432,
+				User:            "user",
+				Password:        "pass",
+				Database:        "db",
+				SSLMode:         "require",
+				ConnMaxIdleTime: -time.Second,
+			},
+			expectError: true,
+		},
+		{
+			name: "Allow SSL mode",
+			config: &DatabaseConfig{
+				Host:     "localhost",
+				Port:     5432,
+				User:     "user",
+				Password: "pass",
+				Database: "db",
+				SSLMode:  "allow",
+			},
+			expectError: false,
+		},
+		{
+			name: "Prefer SSL mode",
+			config: &DatabaseConfig{
+				Host:     "localhost",
+				Port:     5432,
+				User:     "user",
+				Password: "pass",
+				Database: "db",
+				SSLMode:  "prefer",
+			},
+			expectError: false,
+		},
 		{
 			name: "Invalid SSL mode",
 			config: &DatabaseConfig{
@@ -374,6 +428,58 @@ func TestNewPostgreSQLDriver(t *testing.T) {
 	}
 }
 
+// TestNewPostgreSQLDriverValidatesEnvironment tests that a configuration read from the environment is validated
+// TestNewPostgreSQLDriverValidatesEnvironment: 環境変数から読み込んだ設定が検証されることをテストする関数
+func TestNewPostgreSQLDriverValidatesEnvironment(t *testing.T) {
+	testCases := []struct {
+		name         string
+		envVars      map[string]string // env vars: 基本の設定に追加する環境変数
+		expectError  bool
+		errorContent string
+	}{
+		{name: "Valid", envVars: map[string]string{}},
+		{name: "Options overriding the password and database", envVars: map[string]string{"DB_OPTIONS": "password=evil dbname=other"}, expectError: true, errorContent: "conflicts"},
+		{name: "Local time zone", envVars: map[string]string{"DB_TIMEZONE": "Local"}, expectError: true, errorContent: "invalid time zone"},
+		{name: "More idle than open connections", envVars: map[string]string{"DB_MAX_OPEN_CONNS": "5", "DB_MAX_IDLE_CONNS": "10"}, expectError: true, errorContent: "idle"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			envVars := map[string]string{
+				"DB_HOST":     "localhost",
+				"DB_PORT":     "5432",
+				"DB_USER":     "testuser",
+				"DB_PASSWORD": "testpass",
+				"DB_NAME":     "testdb",
+				"DB_SSL_MODE": "disable",
+			}
+			for key, value := range tc.envVars {
+				envVars[key] = value
+			}
+			for key, value := range envVars {
+				t.Setenv(key, value)
+			}
+
+			driver, err := NewPostgreSQLDriver()
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("Expected error but got none, config: %+v", driver.GetConfig())
+				}
+				if !errors.Is(err, ErrConfigInvalid) {
+					t.Errorf("Expected ErrConfigInvalid, got: %v", err)
+				}
+				if !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error to contain '%s', got: %v", tc.errorContent, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
 // TestNewPostgreSQLDriverWithConfig tests PostgreSQL driver factory with custom config
 // TestNewPostgreSQLDriverWithConfig: カスタム設定でのPostgreSQLドライバーファクトリーをテスト
 // custom: カスタム、独自の
@@ -470,3 +576,385 @@ func TestDriverMethods(t *testing.T) {
 		t.Errorf("Expected 0 open connections, got: %d", stats.OpenConnections)
 	}
 }
+
+// TestLoadDatabaseConfigSlowQueryThreshold tests DB_SLOW_QUERY_MS parsing
+// TestLoadDatabaseConfigSlowQueryThreshold: DB_SLOW_QUERY_MSの解析をテストする関数
+// parsing: 解析
+func TestLoadDatabaseConfigSlowQueryThreshold(t *testing.T) {
+	testCases := []struct {
+		name        string
+		value       string        // value: 値
+		expected    time.Duration // expected: 期待値
+		expectError bool
+	}{
+		{name: "Unset disables detection", value: "", expected: 0},
+		{name: "Milliseconds", value: "250", expected: 250 * time.Millisecond},
+		{name: "Invalid number", value: "fast", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			envVars := map[string]string{
+				"DB_USER":          "user",
+				"DB_PASSWORD":      "pass",
+				"DB_NAME":          "db",
+				"DB_SLOW_QUERY_MS": tc.value,
+			}
+			for key, value := range envVars {
+				os.Setenv(key, value)
+			}
+			defer func() {
+				for key := range envVars {
+					os.Unsetenv(key)
+				}
+			}()
+
+			config, err := LoadDatabaseConfig()
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error for test case '%s', but got none", tc.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if config.SlowQueryThreshold != tc.expected {
+				t.Errorf("Expected threshold %s, got: %s", tc.expected, config.SlowQueryThreshold)
+			}
+		})
+	}
+}
+
+// TestLoadDatabaseConfigConnMaxIdleTime tests DB_CONN_MAX_IDLE_TIME parsing
+// TestLoadDatabaseConfigConnMaxIdleTime: DB_CONN_MAX_IDLE_TIMEの解析をテストする関数
+func TestLoadDatabaseConfigConnMaxIdleTime(t *testing.T) {
+	testCases := []struct {
+		name        string
+		value       string        // value: 値
+		expected    time.Duration // expected: 期待値
+		expectError bool
+	}{
+		{name: "Unset keeps idle connections", value: "", expected: 0},
+		{name: "Duration string", value: "45s", expected: 45 * time.Second},
+		{name: "Bare number", value: "45", expectError: true},
+		{name: "Negative duration", value: "-1m", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			envVars := map[string]string{
+				"DB_USER":               "user",
+				"DB_PASSWORD":           "pass",
+				"DB_NAME":               "db",
+				"DB_CONN_MAX_IDLE_TIME": tc.value,
+			}
+			for key, value := range envVars {
+				os.Setenv(key, value)
+			}
+			defer func() {
+				for key := range envVars {
+					os.Unsetenv(key)
+				}
+			}()
+
+			config, err := LoadDatabaseConfig()
+			if err == nil {
+				err = validateDatabaseConfig(config)
+			}
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error for test case '%s', but got none", tc.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if config.ConnMaxIdleTime != tc.expected {
+				t.Errorf("Expected idle time %s, got: %s", tc.expected, config.ConnMaxIdleTime)
+			}
+		})
+	}
+}
+
+// TestLoadDatabaseConfigPoolSettings tests DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS and DB_CONN_MAX_LIFETIME parsing
+// TestLoadDatabaseConfigPoolSettings: DB_MAX_OPEN_CONNS、DB_MAX_IDLE_CONNS、DB_CONN_MAX_LIFETIMEの解析をテストする関数
+func TestLoadDatabaseConfigPoolSettings(t *testing.T) {
+	testCases := []struct {
+		name             string
+		maxOpen          string        // max open: DB_MAX_OPEN_CONNSの値
+		maxIdle          string        // max idle: DB_MAX_IDLE_CONNSの値
+		lifetime         string        // lifetime: DB_CONN_MAX_LIFETIMEの値
+		expectedOpen     int           // expected open: デフォルト適用後の最大接続数
+		expectedIdle     int           // expected idle: デフォルト適用後の最大アイドル数
+		expectedLifetime time.Duration // expected lifetime: デフォルト適用後の接続の寿命
+		expectError      bool
+	}{
+		{name: "Unset keeps the defaults", expectedOpen: 25, expectedIdle: 5, expectedLifetime: 5 * time.Minute},
+		{name: "All set", maxOpen: "50", maxIdle: "10", lifetime: "10m", expectedOpen: 50, expectedIdle: 10, expectedLifetime: 10 * time.Minute},
+		{name: "Small pool caps the default idle", maxOpen: "3", expectedOpen: 3, expectedIdle: 3, expectedLifetime: 5 * time.Minute},
+		{name: "Idle above open", maxOpen: "5", maxIdle: "10", expectError: true},
+		{name: "Not a number", maxOpen: "many", expectError: true},
+		{name: "Negative idle", maxIdle: "-1", expectError: true},
+		{name: "Bare lifetime", lifetime: "300", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			envVars := map[string]string{
+				"DB_USER":              "user",
+				"DB_PASSWORD":          "pass",
+				"DB_NAME":              "db",
+				"DB_MAX_OPEN_CONNS":    tc.maxOpen,
+				"DB_MAX_IDLE_CONNS":    tc.maxIdle,
+				"DB_CONN_MAX_LIFETIME": tc.lifetime,
+			}
+			for key, value := range envVars {
+				os.Setenv(key, value)
+			}
+			defer func() {
+				for key := range envVars {
+					os.Unsetenv(key)
+				}
+			}()
+
+			config, err := LoadDatabaseConfig()
+			if err == nil {
+				err = validateDatabaseConfig(config)
+			}
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error for test case '%s', but got none", tc.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			config.applyDefaults()
+			if config.MaxOpenConns != tc.expectedOpen || config.MaxIdleConns != tc.expectedIdle || config.ConnMaxLifetime != tc.expectedLifetime {
+				t.Errorf("Expected %d/%d/%s, got: %d/%d/%s", tc.expectedOpen, tc.expectedIdle, tc.expectedLifetime,
+					config.MaxOpenConns, config.MaxIdleConns, config.ConnMaxLifetime)
+			}
+		})
+	}
+}
+
+// TestLoadDatabaseConfigSSLModeNormalization tests that DB_SSL_MODE is case-insensitive
+// TestLoadDatabaseConfigSSLModeNormalization: DB_SSL_MODEが大文字小文字を区別しないことをテストする関数
+// normalization: 正規化
+func TestLoadDatabaseConfigSSLModeNormalization(t *testing.T) {
+	testCases := []struct {
+		name        string
+		value       string // value: 環境変数の値
+		expected    string // expected: 正規化後の値
+		expectError bool
+	}{
+		{name: "Title case", value: "Require", expected: "require"},
+		{name: "Upper case with spaces", value: " VERIFY-FULL ", expected: "verify-full"},
+		{name: "Mixed case prefer", value: "Prefer", expected: "prefer"},
+		{name: "Allow", value: "allow", expected: "allow"},
+		{name: "Unknown mode", value: "Sometimes", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			envVars := map[string]string{
+				"DB_USER":     "user",
+				"DB_PASSWORD": "pass",
+				"DB_NAME":     "db",
+				"DB_SSL_MODE": tc.value,
+			}
+			for key, value := range envVars {
+				os.Setenv(key, value)
+			}
+			defer func() {
+				for key := range envVars {
+					os.Unsetenv(key)
+				}
+			}()
+
+			config, err := LoadDatabaseConfig()
+			if err != nil {
+				t.Fatalf("Expected no error loading config, got: %v", err)
+			}
+
+			err = validateDatabaseConfig(config)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("Expected error for test case '%s', but got none", tc.name)
+				}
+				// The message should list the accepted values
+				// エラーメッセージに受け付ける値が列挙されていること
+				for _, mode := range []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"} {
+					if !strings.Contains(err.Error(), mode) {
+						t.Errorf("Expected error to list '%s', got: %v", mode, err)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if config.SSLMode != tc.expected {
+				t.Errorf("Expected SSL mode '%s', got: '%s'", tc.expected, config.SSLMode)
+			}
+		})
+	}
+}
+
+// TestTimeZoneConfig tests DB_TIMEZONE loading, validation and DSN emission
+// TestTimeZoneConfig: DB_TIMEZONEの読み込み、検証、接続文字列への出力をテストする関数
+// emission: 出力
+func TestTimeZoneConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		value       string // value: 環境変数の値
+		expected    string // expected: 期待するタイムゾーン
+		expectError bool
+	}{
+		{name: "Unset defaults to UTC", value: "", expected: "UTC"},
+		{name: "IANA name", value: "Asia/Tokyo", expected: "Asia/Tokyo"},
+		{name: "Unknown zone", value: "Mars/Olympus_Mons", expectError: true},
+		{name: "Local is rejected", value: "Local", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			envVars := map[string]string{
+				"DB_USER":     "user",
+				"DB_PASSWORD": "pass",
+				"DB_NAME":     "db",
+				"DB_TIMEZONE": tc.value,
+			}
+			for key, value := range envVars {
+				os.Setenv(key, value)
+			}
+			defer func() {
+				for key := range envVars {
+					os.Unsetenv(key)
+				}
+			}()
+
+			config, err := LoadDatabaseConfig()
+			if err != nil {
+				t.Fatalf("Expected no error loading config, got: %v", err)
+			}
+
+			driver, err := NewPostgreSQLDriverWithConfig(config)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error for test case '%s', but got none", tc.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if zone := driver.GetTimeZone(); zone != tc.expected {
+				t.Errorf("Expected time zone '%s', got: '%s'", tc.expected, zone)
+			}
+			if dsn := driver.GetConfig().BuildConnectionString(); !strings.HasSuffix(dsn, " timezone="+tc.expected) {
+				t.Errorf("Expected connection string to end with timezone=%s, got: %s", tc.expected, dsn)
+			}
+		})
+	}
+}
+
+// TestTimeZoneDefaultForProgrammaticConfig tests that configs built in code also default to UTC
+// TestTimeZoneDefaultForProgrammaticConfig: コードで構築した設定もUTCがデフォルトになることをテストする関数
+// programmatic: コードによる
+func TestTimeZoneDefaultForProgrammaticConfig(t *testing.T) {
+	driver, err := NewPostgreSQLDriverWithConfig(&DatabaseConfig{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "testuser",
+		Password: "testpass",
+		Database: "testdb",
+		SSLMode:  "disable",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	if zone := driver.GetTimeZone(); zone != "UTC" {
+		t.Errorf("Expected time zone 'UTC', got: '%s'", zone)
+	}
+}
+
+// TestConfigIsolation tests that mutating caller-held configurations does not affect the driver
+// TestConfigIsolation: 呼び出し元が保持する設定を変更してもドライバーに影響しないことをテストする関数
+// isolation: 分離
+func TestConfigIsolation(t *testing.T) {
+	config := &DatabaseConfig{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "testuser",
+		Password: "testpass",
+		Database: "testdb",
+		SSLMode:  "disable",
+	}
+
+	driver, err := NewPostgreSQLDriverWithConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	// Mutate both the constructor argument and the returned copy
+	// コンストラクターの引数と返されたコピーの両方を変更する
+	config.Host = "constructor.example.com"
+	driver.GetConfig().Host = "getconfig.example.com"
+
+	if host := driver.GetConfig().Host; host != "localhost" {
+		t.Errorf("Expected host 'localhost', got: '%s'", host)
+	}
+}
+
+// TestUpdateConfig tests that UpdateConfig validates and takes effect on Reconnect
+// TestUpdateConfig: UpdateConfigが検証を行い、Reconnectで反映されることをテストする関数
+// takes effect: 反映される
+func TestUpdateConfig(t *testing.T) {
+	captureLog(t)
+	driver := newFakeConnectingDriver(t)
+
+	var connectionStrings []string
+	driver.openDB = func(connectionString string) (*sql.DB, error) {
+		connectionStrings = append(connectionStrings, connectionString)
+		_, db := newFakeDB()
+		return db, nil
+	}
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected no error on Connect, got: %v", err)
+	}
+
+	if err := driver.UpdateConfig(nil); err == nil {
+		t.Error("Expected error for nil configuration, got none")
+	}
+	if err := driver.UpdateConfig(&DatabaseConfig{Host: "db.example.com"}); err == nil {
+		t.Error("Expected error for invalid configuration, got none")
+	}
+
+	updated := driver.GetConfig()
+	updated.Host = "db.example.com"
+	if err := driver.UpdateConfig(updated); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	updated.Host = "mutated.example.com" // ignored after UpdateConfig: UpdateConfig後の変更は無視される
+
+	if host := driver.GetConfig().Host; host != "localhost" {
+		t.Errorf("Expected update to wait for Reconnect, got host: '%s'", host)
+	}
+
+	if err := driver.Reconnect(); err != nil {
+		t.Fatalf("Expected no error on Reconnect, got: %v", err)
+	}
+	if host := driver.GetConfig().Host; host != "db.example.com" {
+		t.Errorf("Expected host 'db.example.com' after Reconnect, got: '%s'", host)
+	}
+	if len(connectionStrings) != 2 || !strings.Contains(connectionStrings[1], "host=db.example.com ") {
+		t.Errorf("Expected Reconnect to use the updated host, got: %v", connectionStrings)
+	}
+}
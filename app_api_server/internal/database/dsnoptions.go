@@ -0,0 +1,152 @@
+package database
+
+import (
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"maps"    // maps: マップ操作
+	"slices"  // slices: スライス操作
+	"sort"    // sort: 並べ替え
+	"strings" // strings: 文字列操作
+)
+
+// reservedOptionKeys are the connection parameters DatabaseConfig models as fields
+// reservedOptionKeys: DatabaseConfigがフィールドとして持つ接続パラメータ
+// Options may not set them, so a structured field is never overridden silently
+// Optionsでは設定できないため、構造化フィールドが暗黙に上書きされることはない
+var reservedOptionKeys = map[string]bool{
+	"host":                 true,
+	"hostaddr":             true,
+	"port":                 true,
+	"user":                 true,
+	"password":             true,
+	"dbname":               true,
+	"sslmode":              true,
+	"timezone":             true,
+	"connect_timeout":      true,
+	"target_session_attrs": true,
+
+	// Driver settings modeled as fields: フィールドとして持つドライバーの設定
+	"fallback_application_name": true,
+	"keepalives":                true,
+	"keepalives_idle":           true,
+	"keepalives_interval":       true,
+	"keepalives_count":          true,
+
+	// Session defaults modeled as fields: フィールドとして持つセッションのデフォルト
+	"lock_timeout":                        true,
+	"idle_in_transaction_session_timeout": true,
+	"default_transaction_read_only":       true,
+}
+
+// escapeDSNValue quotes a key/value connection string value when libpq needs it to
+// escapeDSNValue: libpqが必要とする場合にキー/値形式の接続文字列の値を引用符で囲む関数
+// Empty values and values with spaces, quotes or backslashes are quoted, with ' and \ escaped
+// 空の値や空白、引用符、バックスラッシュを含む値は引用符で囲み、'と\をエスケープする
+func escapeDSNValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\r'\\") {
+		return value
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	return "'" + escaped + "'"
+}
+
+// ParseOptions parses "key=value key2=value2" into a map, accepting libpq-style quoted values
+// ParseOptions: "key=value key2=value2"形式をマップに解析する関数、libpq形式の引用符付きの値も受け付ける
+// Values may be written as 'a value with spaces' with \' and \\ escapes. Keys DatabaseConfig models as fields
+// are rejected, so DB_OPTIONS cannot override the password or database name
+// 値は'空白を含む値'のように書け、\'と\\でエスケープできる。DatabaseConfigがフィールドとして持つキーは拒否するため、
+// DB_OPTIONSでパスワードやデータベース名を上書きできない
+func ParseOptions(raw string) (map[string]string, error) {
+	options, err := parseKeyValues(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateOptions(options); err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// parseKeyValues parses a key/value connection string into a map without checking the keys
+// parseKeyValues: キー/値形式の接続文字列をキーを検査せずにマップに解析する関数
+func parseKeyValues(raw string) (map[string]string, error) {
+	options := map[string]string{}
+	rest := strings.TrimSpace(raw)
+
+	for rest != "" {
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("invalid option %q: expected key=value", strings.Fields(rest)[0]) // expected: 期待される
+		}
+		key := rest[:eq]
+		if strings.ContainsAny(key, " \t'") {
+			return nil, fmt.Errorf("invalid option key %q", key)
+		}
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, "'") {
+			var b strings.Builder
+			i := 1
+			for ; i < len(rest) && rest[i] != '\''; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				b.WriteByte(rest[i])
+			}
+			if i >= len(rest) {
+				return nil, fmt.Errorf("unterminated quoted value for option %q", key) // unterminated: 閉じられていない
+			}
+			value = b.String()
+			rest = rest[i+1:]
+		} else {
+			end := strings.IndexAny(rest, " \t")
+			if end < 0 {
+				end = len(rest)
+			}
+			value = rest[:end]
+			rest = rest[end:]
+		}
+
+		if rest != "" && !strings.ContainsAny(rest[:1], " \t") {
+			return nil, fmt.Errorf("invalid option %q: expected whitespace after value", key) // whitespace: 空白
+		}
+		options[key] = value
+		rest = strings.TrimSpace(rest)
+	}
+	return options, nil
+}
+
+// validateOptions rejects option keys that are empty or collide with structured fields
+// validateOptions: 空のキーや構造化フィールドと衝突するキーを拒否する関数
+// collide: 衝突する
+func validateOptions(options map[string]string) error {
+	for key := range options {
+		if key == "" || strings.ContainsAny(key, " \t'=\\") {
+			return fmt.Errorf("invalid option key %q", key)
+		}
+		if reservedOptionKeys[strings.ToLower(key)] {
+			return fmt.Errorf("option %q conflicts with a DatabaseConfig field; set the field instead", key) // conflicts: 衝突する
+		}
+	}
+	return nil
+}
+
+// sortedOptionKeys returns the option keys in a stable order for the connection string
+// sortedOptionKeys: 接続文字列用にオプションのキーを安定した順序で返す関数
+func sortedOptionKeys(options map[string]string) []string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// cloneConfig copies a configuration including its Options map
+// cloneConfig: Optionsマップを含めて設定をコピーする関数
+func cloneConfig(config *DatabaseConfig) DatabaseConfig {
+	cloned := *config
+	cloned.Options = maps.Clone(config.Options)
+	cloned.Ports = slices.Clone(config.Ports)
+	return cloned
+}
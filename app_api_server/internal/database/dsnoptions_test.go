@@ -0,0 +1,129 @@
+package database
+
+import (
+	"os"      // os: 環境変数操作
+	"reflect" // reflect: マップの比較
+	"strings" // strings: 文字列操作
+	"testing" // testing: テストフレームワーク
+)
+
+// TestParseOptions tests parsing the DB_OPTIONS format
+// TestParseOptions: DB_OPTIONS形式の解析をテストする関数
+func TestParseOptions(t *testing.T) {
+	testCases := []struct {
+		name        string
+		raw         string
+		expected    map[string]string
+		expectError bool
+	}{
+		{"empty", "  ", map[string]string{}, false},
+		{"single", "statement_timeout=30s", map[string]string{"statement_timeout": "30s"}, false},
+		{"several with extra spaces", " krbsrvname=postgres \t application_name=sift ", map[string]string{"krbsrvname": "postgres", "application_name": "sift"}, false},
+		{"quoted value", `application_name='sift api' options='-c search_path=app\'s'`, map[string]string{"application_name": "sift api", "options": "-c search_path=app's"}, false},
+		{"empty quoted value", "sslrootcert=''", map[string]string{"sslrootcert": ""}, false},
+		{"last occurrence wins", "search_path=a search_path=b", map[string]string{"search_path": "b"}, false},
+		{"missing equals", "keepalives_idle", nil, true},
+		{"missing key", "=30", nil, true},
+		{"unterminated quote", "application_name='sift", nil, true},
+		{"text after quote", "application_name='sift'api", nil, true},
+		{"password and database name", "password=evil dbname=other", nil, true},
+		{"reserved key in another case", "SSLMode=disable", nil, true},
+		{"field modeled setting", "keepalives_idle=30", nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options, err := ParseOptions(tc.raw)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got: %v", options)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(options, tc.expected) {
+				t.Errorf("Expected %v, got: %v", tc.expected, options)
+			}
+		})
+	}
+}
+
+// TestOptionsCollisions tests that options cannot override structured fields
+// TestOptionsCollisions: オプションが構造化フィールドを上書きできないことをテストする関数
+func TestOptionsCollisions(t *testing.T) {
+	for _, key := range []string{"host", "port", "user", "password", "dbname", "sslmode", "timezone", "connect_timeout", "SSLMode"} {
+		t.Run(key, func(t *testing.T) {
+			config := &DatabaseConfig{
+				Host: "localhost", Port: 5432, User: "u", Password: "p", Database: "db", SSLMode: "require",
+				Options: map[string]string{key: "x"},
+			}
+			err := validateDatabaseConfig(config)
+			if err == nil || !strings.Contains(err.Error(), "conflicts") {
+				t.Errorf("Expected collision error, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestOptionsConnectionString tests that options are appended escaped after the structured fields
+// TestOptionsConnectionString: オプションがエスケープされて構造化フィールドの後に追加されることをテストする関数
+func TestOptionsConnectionString(t *testing.T) {
+	config := &DatabaseConfig{
+		Host: "localhost", Port: 5432, User: "u", Password: "it's a secret", Database: "db", SSLMode: "require",
+		Options: map[string]string{"target_session_attrs": "read-write", "application_name": "sift api"},
+	}
+
+	expected := `host=localhost port=5432 user=u password='it\'s a secret' dbname=db sslmode=require` +
+		` application_name='sift api' target_session_attrs=read-write`
+	if got := config.BuildConnectionString(); got != expected {
+		t.Errorf("Expected %s, got: %s", expected, got)
+	}
+}
+
+// TestOptionsFromEnvironment tests loading DB_OPTIONS and isolating the map from the caller
+// TestOptionsFromEnvironment: DB_OPTIONSの読み込みと呼び出し元からのマップの分離をテストする関数
+func TestOptionsFromEnvironment(t *testing.T) {
+	t.Setenv("DB_USER", "u")
+	t.Setenv("DB_PASSWORD", "p")
+	t.Setenv("DB_NAME", "db")
+	t.Setenv("DB_OPTIONS", "statement_timeout=30000 application_name='sift api'")
+
+	config, err := LoadDatabaseConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if config.Options["statement_timeout"] != "30000" || config.Options["application_name"] != "sift api" {
+		t.Errorf("Expected options from DB_OPTIONS, got: %v", config.Options)
+	}
+
+	driver, err := NewPostgreSQLDriverWithConfig(config)
+	if err != nil {
+		t.Fatalf("Expected valid config, got: %v", err)
+	}
+	config.Options["statement_timeout"] = "1"
+	driver.GetConfig().Options["statement_timeout"] = "2"
+	if got := driver.GetConfig().Options["statement_timeout"]; got != "30000" {
+		t.Errorf("Expected driver options to be isolated, got: %s", got)
+	}
+
+	os.Setenv("DB_OPTIONS", "statement_timeout")
+	if _, err := LoadDatabaseConfig(); err == nil {
+		t.Error("Expected error for malformed DB_OPTIONS")
+	}
+}
+
+// TestOptionsRedacted tests that password-like options are masked
+// TestOptionsRedacted: パスワードに類するオプションが伏せ字になることをテストする関数
+func TestOptionsRedacted(t *testing.T) {
+	config := DatabaseConfig{Password: "p", Options: map[string]string{"sslpassword": "keypass", "application_name": "sift"}}
+
+	printed := config.String()
+	if strings.Contains(printed, "keypass") || !strings.Contains(printed, "application_name:sift") {
+		t.Errorf("Expected sslpassword to be masked, got: %s", printed)
+	}
+	if config.Options["sslpassword"] != "keypass" {
+		t.Error("Expected original options to be unchanged")
+	}
+}
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"errors" // errors: エラー操作
+)
+
+// Driver state errors; match them with errors.Is, never by message
+// ドライバーの状態を表すエラー、メッセージではなくerrors.Isで判定する
+//
+// Which methods return which sentinel:
+// どのメソッドがどのエラーを返すか:
+//
+//   - ErrNotConnected: the query helpers (QueryContext, QueryRowContext, ExecContext and those built on them),
+//     WithTransaction, HealthCheck, Readiness, MeasureLatency, GetServerInfo, GetSessionSettings, GetServerVersion and RotateCredentials
+//     before Connect; Readiness also when the ping fails
+//   - ErrAlreadyConnected: Connect when a pool is already open; use Reconnect to replace it
+//   - ErrDriverClosed: Connect, Reconnect, ReconnectContext and the methods listed for ErrNotConnected after Close
+//   - ErrConfigInvalid: NewPostgreSQLDriver, NewPostgreSQLDriverWithConfig, UpdateConfig and ReloadConfig
+//     when the configuration fails validation
+//   - ErrPoolExhausted: the query helpers when WithAcquireTimeout is set and no connection frees up in time
+//   - ErrCircuitOpen: Reconnect and ReconnectContext while the circuit breaker rejects attempts
+var (
+	// ErrNotConnected is returned when the driver has no connection pool, or by Readiness when the pool does not answer a ping
+	// ErrNotConnected: ドライバーに接続プールがない場合、またはプールがpingに応答しない場合にReadinessが返すエラー
+	ErrNotConnected = errors.New("database is not connected")
+
+	// ErrAlreadyConnected is returned by Connect when the driver already has a connection pool
+	// ErrAlreadyConnected: ドライバーが既に接続プールを持っている場合にConnectが返すエラー
+	ErrAlreadyConnected = errors.New("database is already connected")
+
+	// ErrDriverClosed is returned by operations on a driver after Close
+	// ErrDriverClosed: Close後のドライバーに対する操作が返すエラー
+	ErrDriverClosed = errors.New("database driver is closed")
+
+	// ErrConfigInvalid is returned when a database configuration fails validation
+	// ErrConfigInvalid: データベース設定が検証に失敗した場合に返すエラー
+	ErrConfigInvalid = errors.New("invalid database configuration")
+)
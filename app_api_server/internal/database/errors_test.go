@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"      // context: コンテキスト
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"testing"      // testing: テスト機能
+	"time"         // time: 時間操作機能
+)
+
+// TestSentinelErrors tests that each driver state surfaces its sentinel through the usual fmt.Errorf wrapping
+// TestSentinelErrors: 各ドライバーの状態が通常のfmt.Errorfのラップを通して対応するエラーを返すことをテストする関数
+func TestSentinelErrors(t *testing.T) {
+	ctx := context.Background()
+
+	// notConnected has never connected; closed was connected and then closed
+	// notConnected: 一度も接続していない、closed: 接続後に閉じた
+	notConnected := newFakeConnectingDriver(t)
+	connected := newFakeConnectingDriver(t)
+	if err := connected.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	closed := newFakeConnectingDriver(t)
+	if err := closed.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	closed.Close()
+
+	exhausted := newFakeConnectingDriver(t)
+	exhausted.acquireTimeout = 10 * time.Millisecond
+	if err := exhausted.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	exhausted.GetDB().SetMaxOpenConns(1)
+	held, err := exhausted.GetDB().Conn(ctx)
+	if err != nil {
+		t.Fatalf("Failed to take the only connection: %v", err)
+	}
+	defer held.Close()
+
+	open := newFakeConnectingDriver(t)
+	open.breaker.threshold = 1
+	open.openDB = func(string) (*sql.DB, error) { return nil, errors.New("connection refused") }
+	open.Reconnect() // opens the breaker: ブレーカーを開く
+
+	testCases := []struct {
+		name     string
+		call     func() error
+		expected error
+	}{
+		{name: "Query before Connect", call: func() error { _, err := notConnected.QueryContext(ctx, "SELECT 1"); return err }, expected: ErrNotConnected},
+		{name: "WithTransaction before Connect", call: func() error {
+			return notConnected.WithTransaction(ctx, func(context.Context, *sql.Tx) error { return nil })
+		}, expected: ErrNotConnected},
+		{name: "HealthCheck before Connect", call: func() error { _, err := notConnected.HealthCheck(ctx); return err }, expected: ErrNotConnected},
+		{name: "GetServerVersion before Connect", call: func() error { _, err := notConnected.GetServerVersion(); return err }, expected: ErrNotConnected},
+		{name: "RotateCredentials before Connect", call: func() error { return notConnected.RotateCredentials(ctx, "u", "p") }, expected: ErrNotConnected},
+		{name: "Connect twice", call: connected.Connect, expected: ErrAlreadyConnected},
+		{name: "Query after Close", call: func() error { _, err := closed.QueryContext(ctx, "SELECT 1"); return err }, expected: ErrDriverClosed},
+		{name: "Readiness after Close", call: func() error { return closed.Readiness(ctx) }, expected: ErrDriverClosed},
+		{name: "Connect after Close", call: closed.Connect, expected: ErrDriverClosed},
+		{name: "Reconnect after Close", call: closed.Reconnect, expected: ErrDriverClosed},
+		{name: "Invalid configuration", call: func() error {
+			_, err := NewPostgreSQLDriverWithConfig(&DatabaseConfig{Host: "localhost", Port: 0})
+			return err
+		}, expected: ErrConfigInvalid},
+		{name: "Nil configuration update", call: func() error { return connected.UpdateConfig(nil) }, expected: ErrConfigInvalid},
+		{name: "Pool exhausted", call: func() error { _, err := exhausted.QueryContext(ctx, "SELECT 1"); return err }, expected: ErrPoolExhausted},
+		{name: "Circuit open", call: open.Reconnect, expected: ErrCircuitOpen},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.call()
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("Expected errors.Is(err, %v), got: %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+// TestCloseTwice tests that a second Close is a no-op
+// TestCloseTwice: 2回目のCloseが何もしないことをテストする関数
+func TestCloseTwice(t *testing.T) {
+	driver := newFakeConnectingDriver(t)
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if err := driver.Close(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := driver.Close(); err != nil {
+		t.Errorf("Expected no error on the second Close, got: %v", err)
+	}
+	if events := driver.Events(0); len(events) != 2 || events[1].Type != EventDisconnect {
+		t.Errorf("Expected a single disconnect event, got: %+v", events)
+	}
+}
+
+// TestCloseReleasesPoolAndSignal tests that Close drops the pool and that later goroutines still see the close signal
+// TestCloseReleasesPoolAndSignal: Closeがプールを手放し、後から開始したゴルーチンも終了の合図を受け取ることをテストする関数
+func TestCloseReleasesPoolAndSignal(t *testing.T) {
+	driver := newFakeConnectingDriver(t)
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if err := driver.Close(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if driver.IsOpen() || driver.IsConnected() {
+		t.Error("Expected IsOpen and IsConnected to report false after Close")
+	}
+	select {
+	case <-driver.closeSignal():
+	default:
+		t.Error("Expected the close signal requested after Close to be closed")
+	}
+}
@@ -0,0 +1,110 @@
+package database
+
+import (
+	"sync" // sync: 同期処理
+	"time" // time: 時間操作機能
+)
+
+// defaultEventLogSize is how many lifecycle events the driver keeps by default
+// defaultEventLogSize: ドライバーが既定で保持するライフサイクルイベントの件数
+const defaultEventLogSize = 100
+
+// LifecycleEventType identifies what happened to the connection
+// LifecycleEventType: 接続に起きた出来事を識別する型
+type LifecycleEventType string
+
+// Lifecycle event types recorded by the driver
+// ドライバーが記録するライフサイクルイベントの種類
+const (
+	EventConnect            LifecycleEventType = "connect"             // connect: 接続成功
+	EventConnectFailed      LifecycleEventType = "connect_failed"      // connect failed: 接続失敗
+	EventDisconnect         LifecycleEventType = "disconnect"          // disconnect: プールを閉じた
+	EventReconnectAttempt   LifecycleEventType = "reconnect_attempt"   // reconnect attempt: 再接続の試行開始
+	EventReconnectSuccess   LifecycleEventType = "reconnect_success"   // reconnect success: 再接続成功
+	EventReconnectFailure   LifecycleEventType = "reconnect_failure"   // reconnect failure: 再接続失敗
+	EventConfigReload       LifecycleEventType = "config_reload"       // config reload: ReloadConfigの実行
+	EventCredentialRotation LifecycleEventType = "credential_rotation" // credential rotation: RotateCredentialsの実行
+
+	EventVerboseLoggingEnabled  LifecycleEventType = "verbose_logging_enabled"  // verbose logging enabled: 詳細クエリログの有効化、Detailは操作者と期限
+	EventVerboseLoggingDisabled LifecycleEventType = "verbose_logging_disabled" // verbose logging disabled: 詳細クエリログの無効化、Detailは操作者
+	EventServerRestartDetected  LifecycleEventType = "server_restart_detected"  // server restart detected: 文のエラーからサーバーの再起動を検出した、DetailはSQLSTATE
+)
+
+// LifecycleEvent is one entry of the driver's event log
+// LifecycleEvent: ドライバーのイベントログの1件
+type LifecycleEvent struct {
+	Time   time.Time          `json:"time"`             // time: 発生時刻
+	Type   LifecycleEventType `json:"type"`             // type: イベントの種類
+	Detail string             `json:"detail,omitempty"` // detail: 補足情報（ReloadConfigの変更内容など）
+	Error  string             `json:"error,omitempty"`  // error: 失敗時のエラー内容、パスワードは伏せ字
+}
+
+// eventLog is the bounded ring buffer of lifecycle events
+// eventLog: ライフサイクルイベントの固定長のリングバッファ
+// bounded: 上限のある
+type eventLog struct {
+	mu     sync.Mutex       // mu: mutex（相互排他ロック）、以下を保護
+	events []LifecycleEvent // events: 固定長のバッファ、容量を超えると古いものから上書きする
+	next   int              // next: 次に書き込む位置
+	count  int              // count: 保持しているイベント数
+	size   int              // size: バッファの容量、0はdefaultEventLogSize
+}
+
+// WithEventLogSize sets how many lifecycle events Events can return
+// WithEventLogSize: Eventsが返せるライフサイクルイベントの件数を設定するオプション
+// Older events are overwritten once the log is full: 上限に達すると古いイベントから上書きする
+func WithEventLogSize(size int) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		if size > 0 {
+			d.events.size = size
+		}
+	}
+}
+
+// recordEvent appends an event, with the password removed from err
+// recordEvent: errからパスワードを取り除いてイベントを追加する関数
+func (d *PostgreSQLDriver) recordEvent(eventType LifecycleEventType, detail string, err error) {
+	event := LifecycleEvent{Time: d.now(), Type: eventType, Detail: detail}
+	if err != nil {
+		event.Error = d.GetConfig().redactError(err).Error()
+	}
+
+	l := &d.events
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.events == nil {
+		size := l.size
+		if size <= 0 {
+			size = defaultEventLogSize
+		}
+		l.events = make([]LifecycleEvent, size)
+	}
+	l.events[l.next] = event
+	l.next = (l.next + 1) % len(l.events)
+	if l.count < len(l.events) {
+		l.count++
+	}
+}
+
+// Events returns up to limit of the most recent lifecycle events, oldest first
+// Events: 直近のライフサイクルイベントを最大limit件、古い順に返す関数
+// A limit of 0 or less returns every retained event: limitが0以下の場合は保持している全てのイベントを返す
+func (d *PostgreSQLDriver) Events(limit int) []LifecycleEvent {
+	l := &d.events
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	count := l.count
+	if limit > 0 && limit < count {
+		count = limit
+	}
+	events := make([]LifecycleEvent, 0, count)
+	if count == 0 {
+		return events
+	}
+	start := (l.next - count + len(l.events)) % len(l.events)
+	for i := 0; i < count; i++ {
+		events = append(events, l.events[(start+i)%len(l.events)])
+	}
+	return events
+}
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"      // context: コンテキスト
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"reflect"      // reflect: リフレクション、値の比較
+	"strings"      // strings: 文字列操作
+	"sync"         // sync: 同期処理
+	"testing"      // testing: テスト機能
+	"time"         // time: 時間操作機能
+)
+
+// eventTypes returns the types of events, in order
+// eventTypes: イベントの種類を順番に返す関数
+func eventTypes(events []LifecycleEvent) []LifecycleEventType {
+	types := make([]LifecycleEventType, 0, len(events))
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+// TestEventsReconnectCycle tests the event sequence of a connect, a successful and a failed reconnect, and a close
+// TestEventsReconnectCycle: 接続、再接続の成功と失敗、切断のイベントの順序をテストする関数
+func TestEventsReconnectCycle(t *testing.T) {
+	driver := newFakeConnectingDriver(t)
+	driver.now = fakeClock(time.Second)
+	captureLog(t)
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected no error on Connect, got: %v", err)
+	}
+	if err := driver.Reconnect(); err != nil {
+		t.Fatalf("Expected no error on Reconnect, got: %v", err)
+	}
+	driver.openDB = func(string) (*sql.DB, error) {
+		return nil, errors.New(`dial failed for "host=localhost password=testpass"`)
+	}
+	if err := driver.Reconnect(); err == nil {
+		t.Fatal("Expected an error on the failing Reconnect")
+	}
+	if err := driver.Close(); err != nil {
+		t.Fatalf("Expected no error on Close, got: %v", err)
+	}
+
+	events := driver.Events(0)
+	expected := []LifecycleEventType{
+		EventConnect,
+		EventReconnectAttempt, EventDisconnect, EventReconnectSuccess,
+		EventReconnectAttempt, EventDisconnect, EventReconnectFailure,
+	}
+	if got := eventTypes(events); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected events %v, got: %v", expected, got)
+	}
+	for i := 1; i < len(events); i++ {
+		if !events[i].Time.After(events[i-1].Time) {
+			t.Errorf("Expected increasing timestamps, got: %v then %v", events[i-1].Time, events[i].Time)
+		}
+	}
+	failure := events[len(events)-1]
+	if !strings.Contains(failure.Error, "dial failed") {
+		t.Errorf("Expected the failure's error string, got: %q", failure.Error)
+	}
+	if strings.Contains(failure.Error, "testpass") {
+		t.Errorf("Expected the password to be redacted, got: %q", failure.Error)
+	}
+}
+
+// TestEventsBounded tests that the log keeps only the newest events and that limit selects the most recent ones
+// TestEventsBounded: ログが最新のイベントのみを保持し、limitで直近のイベントを選べることをテストする関数
+func TestEventsBounded(t *testing.T) {
+	driver, _ := newTestDriver(t)
+	WithEventLogSize(3)(driver)
+
+	for _, eventType := range []LifecycleEventType{EventConnect, EventDisconnect, EventReconnectAttempt, EventReconnectSuccess, EventConfigReload} {
+		driver.recordEvent(eventType, "", nil)
+	}
+
+	testCases := []struct {
+		name     string
+		limit    int
+		expected []LifecycleEventType
+	}{
+		{name: "All", limit: 0, expected: []LifecycleEventType{EventReconnectAttempt, EventReconnectSuccess, EventConfigReload}},
+		{name: "Limit", limit: 2, expected: []LifecycleEventType{EventReconnectSuccess, EventConfigReload}},
+		{name: "Limit above size", limit: 10, expected: []LifecycleEventType{EventReconnectAttempt, EventReconnectSuccess, EventConfigReload}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := eventTypes(driver.Events(tc.limit)); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected events %v, got: %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestEventsConcurrent tests that recording and reading events from several goroutines is safe
+// TestEventsConcurrent: 複数のゴルーチンからのイベントの記録と読み出しが安全であることをテストする関数
+func TestEventsConcurrent(t *testing.T) {
+	driver, _ := newTestDriver(t)
+	WithEventLogSize(10)(driver)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				driver.recordEvent(EventConnect, "", nil)
+				driver.Events(5)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(driver.Events(0)); got != 10 {
+		t.Errorf("Expected 10 retained events, got: %d", got)
+	}
+}
+
+// TestHealthCheckEvents tests that HealthCheck includes recent events only when WithHealthEvents is set
+// TestHealthCheckEvents: WithHealthEvents指定時のみHealthCheckが直近のイベントを含むことをテストする関数
+func TestHealthCheckEvents(t *testing.T) {
+	driver, _ := newTestDriver(t)
+	driver.recordEvent(EventConnect, "", nil)
+	driver.recordEvent(EventConfigReload, "MaxOpenConns", nil)
+
+	status, err := driver.HealthCheck(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if status.Events != nil {
+		t.Errorf("Expected no events by default, got: %v", status.Events)
+	}
+
+	WithHealthEvents(1)(driver)
+	status, err = driver.HealthCheck(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(status.Events) != 1 || status.Events[0].Type != EventConfigReload || status.Events[0].Detail != "MaxOpenConns" {
+		t.Errorf("Expected the config reload event, got: %+v", status.Events)
+	}
+}
@@ -1,13 +1,27 @@
 package database
 
 import (
-	"log" // log: ログ出力機能
+	"context"       // context: コンテキスト、処理の文脈情報
+	"encoding/json" // json: 診断結果のJSON出力
+	"log"           // log: ログ出力機能
+	"net/http"      // http: HTTPサーバー機能
+	"time"          // time: 時間操作機能
+
+	"github.com/prometheus/client_golang/prometheus"          // prometheus: Prometheusメトリクスライブラリ
+	"github.com/prometheus/client_golang/prometheus/promhttp" // promhttp: PrometheusのHTTPハンドラー
 )
 
 // ExampleUsage demonstrates how to use the PostgreSQL driver
 // ExampleUsage: PostgreSQLドライバーの使用方法を示すサンプル関数
 // demonstrates: 実演する、使用方法を示す
 func ExampleUsage() {
+	// Load a .env file if present (DB_ENV_FILE or ./.env); the driver itself only reads the environment
+	// .envファイルがあれば読み込む（DB_ENV_FILEまたは./.env）、ドライバー自体は環境変数のみを読む
+	if err := LoadDotEnv(); err != nil {
+		log.Printf("Failed to load .env file: %v", err)
+		return
+	}
+
 	// Method 1: Create driver with environment variables
 	// method: 方法、create: 作成する、environment: 環境、variables: 変数
 	driver, err := NewPostgreSQLDriver()
@@ -114,15 +128,15 @@ func ExampleReconnection() {
 		return
 	}
 
-	// Simulate connection loss and recovery
-	// simulate: シミュレートする、loss: 損失、recovery: 回復
+	// Simulate connection loss and recovery; closing the driver itself would rule out reconnecting
+	// simulate: シミュレートする、loss: 損失、recovery: 回復、ドライバー自体を閉じると再接続できなくなる
 	log.Println("Simulating connection loss...") // simulating: シミュレートしている
-	driver.Close()
+	driver.GetDB().Close()
 
-	// Attempt to reconnect
-	// attempt: 試行する、reconnect: 再接続
+	// Keep trying to reconnect for up to 30 seconds
+	// attempt: 試行する、reconnect: 再接続、最大30秒間再接続を試み続ける
 	log.Println("Attempting to reconnect...") // attempting: 試行している
-	if err := driver.Reconnect(); err != nil {
+	if err := driver.ReconnectWithBudget(30 * time.Second); err != nil {
 		log.Printf("Failed to reconnect: %v", err)
 		return
 	}
@@ -131,17 +145,133 @@ func ExampleReconnection() {
 	driver.Close()
 }
 
+// ExampleMetricsEndpoint demonstrates exposing pool statistics to Prometheus
+// ExampleMetricsEndpoint: プール統計をPrometheusに公開する方法を示すサンプル関数
+// exposing: 公開する、endpoint: エンドポイント
+func ExampleMetricsEndpoint() {
+	// Statement durations come from a hook, pool gauges from the collector
+	// 文の所要時間はフックから、プールのゲージはコレクターから取得する
+	statementMetrics := NewMetricsHook(prometheus.Labels{"service": "sift_api"})
+	driver, err := NewPostgreSQLDriver(WithStatementHooks(statementMetrics))
+	if err != nil {
+		log.Printf("Failed to create driver: %v", err)
+		return
+	}
+
+	// The collector is safe to register before Connect
+	// register: 登録する、before: 前に
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewStatsCollector(driver, prometheus.Labels{"service": "sift_api"}), statementMetrics)
+
+	if err := driver.Connect(); err != nil {
+		log.Printf("Failed to connect to database: %v", err)
+		return
+	}
+	defer driver.Close()
+
+	// Serve metrics for scraping
+	// serve: 提供する、scraping: 収集
+	http.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	if err := http.ListenAndServe(":9090", nil); err != nil {
+		log.Printf("Metrics server stopped: %v", err) // stopped: 停止した
+	}
+}
+
+// ExampleMigrationCheck demonstrates refusing to start against an outdated schema
+// ExampleMigrationCheck: 古いスキーマに対して起動を拒否する方法を示すサンプル関数
+// refusing: 拒否する、outdated: 古い
+func ExampleMigrationCheck() {
+	driver, err := NewPostgreSQLDriver()
+	if err != nil {
+		log.Printf("Failed to create driver: %v", err)
+		return
+	}
+
+	if err := driver.Connect(); err != nil {
+		log.Printf("Failed to connect to database: %v", err)
+		return
+	}
+	defer driver.Close()
+
+	// The version of the newest migration this build depends on
+	// このビルドが依存する最新のマイグレーションのバージョン
+	const requiredMigration = 1
+	if err := driver.CheckMigrationVersion(context.Background(), requiredMigration); err != nil {
+		log.Printf("Refusing to start: %v", err) // refusing: 拒否している
+		return
+	}
+
+	log.Println("Database schema is current") // current: 最新の
+}
+
+// ExampleDiagnosticsEndpoint demonstrates an admin endpoint reporting what the app is connected to
+// ExampleDiagnosticsEndpoint: アプリケーションの接続先を報告する管理用エンドポイントを示すサンプル関数
+// admin: 管理用
+func ExampleDiagnosticsEndpoint() {
+	driver, err := NewPostgreSQLDriver()
+	if err != nil {
+		log.Printf("Failed to create driver: %v", err)
+		return
+	}
+
+	if err := driver.Connect(); err != nil {
+		log.Printf("Failed to connect to database: %v", err)
+		return
+	}
+	defer driver.Close()
+
+	// Keep this endpoint on an internal listener: it names the server and user
+	// サーバーとユーザーを示すため、このエンドポイントは内部向けのリスナーに置く
+	http.HandleFunc("/admin/db", func(w http.ResponseWriter, r *http.Request) {
+		info, err := driver.GetServerInfo(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(info)
+	})
+	if err := http.ListenAndServe("127.0.0.1:9091", nil); err != nil {
+		log.Printf("Admin server stopped: %v", err)
+	}
+}
+
 // ExampleEnvironmentVariables shows required environment variables
 // ExampleEnvironmentVariables: 必要な環境変数を示すサンプル関数
 // shows: 示す、required: 必要な
 func ExampleEnvironmentVariables() {
-	log.Println("Required environment variables:")                     // required: 必要な
-	log.Println("DB_HOST=localhost (optional, defaults to localhost)") // optional: オプション、defaults: デフォルト
-	log.Println("DB_PORT=5432 (optional, defaults to 5432)")
-	log.Println("DB_USER=your_username (required)")
-	log.Println("DB_PASSWORD=your_password (required)")
+	log.Println("Required environment variables:")                                                                          // required: 必要な
+	log.Println("DB_HOST=localhost (optional, defaults to localhost; a comma-separated list such as db-a,db-b fails over)") // optional: オプション、defaults: デフォルト
+	log.Println("DB_PORT=5432 (optional, defaults to 5432; a list such as 5432,5433 gives one port per host)")
+	log.Println("DB_USER=your_username (required unless DB_AUTH_METHOD=credential-provider)")
+	log.Println("DB_PASSWORD=your_password (required when DB_AUTH_METHOD=password)")
 	log.Println("DB_NAME=your_database (required)")
 	log.Println("DB_SSL_MODE=require (optional, defaults to require)")
+	log.Println("DB_SLOW_QUERY_MS=500 (optional, 0 or unset disables slow query warnings)") // slow: 遅い、query: クエリ
+	log.Println("DB_CONN_MAX_IDLE_TIME=45s (optional, unset keeps idle connections open)")  // idle: アイドル
+	log.Println("DB_TIMEZONE=UTC (optional, defaults to UTC)")                              // timezone: タイムゾーン
+	log.Println("DB_MAX_OPEN_CONNS=25 (optional, defaults to 25; reloaded in place by ReloadConfig; at least 3 when migrations run)")
+	log.Println("DB_MAX_IDLE_CONNS=5 (optional, defaults to 5)")
+	log.Println("DB_CONN_MAX_LIFETIME=5m (optional, defaults to 5m)")
+	log.Println("DB_CONN_MAX_LIFETIME_JITTER=0.1 (optional, gives each connection the lifetime ±10% so they do not expire together)")
+	log.Println("DB_CONNECT_TIMEOUT=10s (optional, unset waits indefinitely)")
+	log.Println("DB_QUERY_TIMEOUT=30s (optional, defaults to 30s for calls whose context has no deadline)")
+	log.Println("DB_MIN_SERVER_VERSION=13.0 (optional, unset skips the server version check)")
+	log.Println("DB_TARGET_SESSION_ATTRS=read-write (optional, any, read-write, read-only, primary or standby; picks a host from DB_HOST)")
+	log.Println("DB_OPTIONS=application_name=sift statement_timeout=30000 (optional, extra libpq parameters)")
+	log.Println("DB_FALLBACK_APPLICATION_NAME=sift-api (optional, connection name when DB_OPTIONS sets no application_name)")
+	log.Println("DB_KEEPALIVES_IDLE=30s (optional, TCP keepalive idle time; also DB_KEEPALIVES_INTERVAL, DB_KEEPALIVES_COUNT and DB_KEEPALIVES=false)")
+	log.Println("DB_AUTH_METHOD=password (optional, password, aws-iam or credential-provider)")
+	log.Println("DB_LOCK_TIMEOUT=5s (optional, session lock_timeout; unset keeps the server setting)")
+	log.Println("DB_IDLE_IN_TX_TIMEOUT=1m (optional, session idle_in_transaction_session_timeout; unset keeps the server setting)")
+	log.Println("DB_READ_ONLY=true (optional, sessions default to read-only transactions)")
+	log.Println("DB_ENV_FILE=/path/to/app.env (optional, file read by LoadDotEnv instead of ./.env)")
+	log.Println("PGHOST, PGPORT, PGUSER, PGPASSWORD, PGDATABASE, PGSSLMODE (optional, used when the DB_* variable is unset)")
+	log.Println("DB_USE_PGPASS=true (optional, reads the password from PGPASSFILE or ~/.pgpass when no variable sets one)")
+	log.Println("DB_DEBUG=true (optional, logs which variable supplied each connection setting)")
+	log.Println("APP_ENV=production (optional; in production, disable, allow and prefer SSL modes are refused)")
+	log.Println("DB_PRODUCTION_ENVS=production,staging (optional, APP_ENV values treated as production)")
+	log.Println("DB_ALLOW_INSECURE=true (optional, allows an insecure SSL mode in production)")
 	log.Println("")
-	log.Println("Valid SSL modes: disable, require, verify-ca, verify-full") // valid: 有効な, modes: モード
+	log.Println("Valid SSL modes: disable, allow, prefer, require, verify-ca, verify-full (case-insensitive)") // valid: 有効な, modes: モード
 }
@@ -0,0 +1,188 @@
+package database
+
+import (
+	"context"       // context: コンテキスト、処理の文脈情報
+	"database/sql"  // sql: データベース操作用パッケージ
+	"encoding/json" // json: JSONエンコード/デコード
+	"errors"        // errors: エラー操作
+	"fmt"           // fmt: format（フォーマット）、文字列フォーマット機能
+	"strings"       // strings: 文字列操作
+	"time"          // time: 時間操作機能
+)
+
+// ErrExplainAnalyzeWrite is returned when ExplainAnalyze is asked to run a statement that may write
+// ErrExplainAnalyzeWrite: 書き込む可能性のある文をExplainAnalyzeで実行しようとした場合のエラー
+var ErrExplainAnalyzeWrite = errors.New("EXPLAIN ANALYZE executes the statement; set AllowWrites to analyze a statement that is not a SELECT")
+
+// ExplainOptions controls ExplainAnalyze
+// ExplainOptions: ExplainAnalyzeの動作を制御するオプション
+type ExplainOptions struct {
+	// AllowWrites lets ANALYZE run INSERT, UPDATE, DELETE and other writing statements, which really execute
+	// AllowWrites: INSERT、UPDATE、DELETEなど書き込む文のANALYZEを許可する（実際に実行される）
+	AllowWrites bool
+}
+
+// PlanNode is one node of an EXPLAIN (FORMAT JSON) plan tree
+// PlanNode: EXPLAIN (FORMAT JSON)の実行計画ツリーの1ノード
+// Times are in milliseconds, as PostgreSQL reports them; Actual* fields are zero without ANALYZE
+// 時間はPostgreSQLの報告どおりミリ秒、Actual系のフィールドはANALYZEなしではゼロ
+type PlanNode struct {
+	NodeType         string     `json:"Node Type"`                    // node type: ノードの種類（Seq Scanなど）
+	RelationName     string     `json:"Relation Name,omitempty"`      // relation name: 対象のテーブル名
+	IndexName        string     `json:"Index Name,omitempty"`         // index name: 使用したインデックス名
+	StartupCost      float64    `json:"Startup Cost"`                 // startup cost: 最初の行を返すまでの推定コスト
+	TotalCost        float64    `json:"Total Cost"`                   // total cost: 全行を返すまでの推定コスト
+	PlanRows         float64    `json:"Plan Rows"`                    // plan rows: 推定行数
+	ActualTotalTime  float64    `json:"Actual Total Time,omitempty"`  // actual total time: 実際の所要時間（ミリ秒、1ループあたり）
+	ActualRows       float64    `json:"Actual Rows,omitempty"`        // actual rows: 実際の行数（1ループあたり）
+	ActualLoops      float64    `json:"Actual Loops,omitempty"`       // actual loops: 実際のループ回数
+	SharedHitBlocks  int64      `json:"Shared Hit Blocks,omitempty"`  // shared hit blocks: 共有バッファでヒットしたブロック数
+	SharedReadBlocks int64      `json:"Shared Read Blocks,omitempty"` // shared read blocks: ディスクから読んだブロック数
+	Plans            []PlanNode `json:"Plans,omitempty"`              // plans: 子ノード
+}
+
+// ExplainResult is a parsed EXPLAIN plan together with the raw JSON
+// ExplainResult: 解析済みのEXPLAIN実行計画と元のJSON
+type ExplainResult struct {
+	Plan          PlanNode        // plan: 実行計画のルートノード
+	TotalCost     float64         // total cost: ルートノードの推定総コスト
+	PlanningTime  time.Duration   // planning time: 計画作成時間（ANALYZEのみ）
+	ExecutionTime time.Duration   // execution time: 実際の実行時間（ANALYZEのみ）
+	Analyzed      bool            // analyzed: ANALYZEで実行したかどうか
+	Raw           json.RawMessage // raw: PostgreSQLが返したJSONそのもの
+}
+
+// explainDocument mirrors one element of the EXPLAIN (FORMAT JSON) output array
+// explainDocument: EXPLAIN (FORMAT JSON)の出力配列の1要素を写した構造体
+type explainDocument struct {
+	Plan          PlanNode `json:"Plan"`
+	PlanningTime  float64  `json:"Planning Time"`
+	ExecutionTime float64  `json:"Execution Time"`
+}
+
+// Explain returns the planner's estimated plan for query without running it
+// Explain: クエリを実行せずにプランナーの推定実行計画を返す関数
+// planner: プランナー、estimated: 推定された
+func (d *PostgreSQLDriver) Explain(ctx context.Context, query string, args ...interface{}) (ExplainResult, error) {
+	var raw string
+	if err := d.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+query, args...).Scan(&raw); err != nil {
+		return ExplainResult{}, fmt.Errorf("failed to explain query: %w", err)
+	}
+	return parseExplain(raw, false)
+}
+
+// ExplainAnalyze runs query under EXPLAIN (ANALYZE, BUFFERS) and returns the plan with actual timings
+// ExplainAnalyze: EXPLAIN (ANALYZE, BUFFERS)でクエリを実行し、実測時間付きの実行計画を返す関数
+// Without AllowWrites only read-only statements are accepted, and they run in a READ ONLY transaction that is rolled back
+// AllowWritesがない場合は読み取り専用の文のみ受け付け、ロールバックされるREAD ONLYトランザクション内で実行する
+func (d *PostgreSQLDriver) ExplainAnalyze(ctx context.Context, opts ExplainOptions, query string, args ...interface{}) (ExplainResult, error) {
+	explain := "EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) " + query
+
+	var raw string
+	if opts.AllowWrites {
+		if err := d.QueryRowContext(ctx, explain, args...).Scan(&raw); err != nil {
+			return ExplainResult{}, fmt.Errorf("failed to explain analyze query: %w", err)
+		}
+		return parseExplain(raw, true)
+	}
+
+	if !isReadOnlyStatement(query) {
+		return ExplainResult{}, ErrExplainAnalyzeWrite
+	}
+
+	db, err := d.connectedPool()
+	if err != nil {
+		return ExplainResult{}, err
+	}
+	ctx, cancel := d.withQueryTimeout(ctx)
+	defer cancel()
+
+	// A read-only transaction also stops functions called from a SELECT from writing
+	// 読み取り専用トランザクションはSELECTから呼ばれた関数の書き込みも防ぐ
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return ExplainResult{}, fmt.Errorf("failed to begin read-only transaction: %w", err)
+	}
+	defer tx.Rollback() // nothing is kept: 何も残さない
+
+	if err := tx.QueryRowContext(ctx, explain, args...).Scan(&raw); err != nil {
+		return ExplainResult{}, fmt.Errorf("failed to explain analyze query: %w", err)
+	}
+	return parseExplain(raw, true)
+}
+
+// parseExplain decodes the JSON returned by EXPLAIN (FORMAT JSON)
+// parseExplain: EXPLAIN (FORMAT JSON)が返したJSONをデコードする関数
+func parseExplain(raw string, analyzed bool) (ExplainResult, error) {
+	var documents []explainDocument
+	if err := json.Unmarshal([]byte(raw), &documents); err != nil {
+		return ExplainResult{}, fmt.Errorf("failed to parse explain output: %w", err)
+	}
+	if len(documents) == 0 {
+		return ExplainResult{}, fmt.Errorf("failed to parse explain output: no plan returned")
+	}
+
+	document := documents[0]
+	return ExplainResult{
+		Plan:          document.Plan,
+		TotalCost:     document.Plan.TotalCost,
+		PlanningTime:  millisecondsToDuration(document.PlanningTime),
+		ExecutionTime: millisecondsToDuration(document.ExecutionTime),
+		Analyzed:      analyzed,
+		Raw:           json.RawMessage(raw),
+	}, nil
+}
+
+// millisecondsToDuration converts PostgreSQL's fractional milliseconds to a time.Duration
+// millisecondsToDuration: PostgreSQLの小数ミリ秒をtime.Durationに変換する関数
+func millisecondsToDuration(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// isReadOnlyStatement reports whether query starts as a statement that only reads
+// isReadOnlyStatement: クエリが読み取りのみの文として始まるかを判定する関数
+// A WITH query is refused when any CTE writes: WITH句のいずれかが書き込む場合は拒否する
+func isReadOnlyStatement(query string) bool {
+	words := strings.Fields(strings.ToUpper(stripLeadingComments(query)))
+	if len(words) == 0 {
+		return false
+	}
+
+	switch strings.TrimLeft(words[0], "(") {
+	case "SELECT", "VALUES", "TABLE":
+		return true
+	case "WITH":
+		for _, word := range words {
+			switch strings.Trim(word, "(),;") {
+			case "INSERT", "UPDATE", "DELETE", "MERGE":
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// stripLeadingComments removes whitespace and SQL comments before the first keyword
+// stripLeadingComments: 最初のキーワードの前にある空白とSQLコメントを取り除く関数
+func stripLeadingComments(query string) string {
+	for {
+		query = strings.TrimSpace(query)
+		switch {
+		case strings.HasPrefix(query, "--"):
+			end := strings.IndexByte(query, '\n')
+			if end < 0 {
+				return ""
+			}
+			query = query[end+1:]
+		case strings.HasPrefix(query, "/*"):
+			end := strings.Index(query, "*/")
+			if end < 0 {
+				return ""
+			}
+			query = query[end+2:]
+		default:
+			return query
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"strings"                       // strings: 文字列操作
+	"testing"                       // testing: テスト機能
+	"time"                          // time: 時間操作機能
+)
+
+// samplePlanJSON is an abridged EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) output with one child node
+// samplePlanJSON: 子ノードを1つ持つEXPLAIN (ANALYZE, BUFFERS, FORMAT JSON)出力の抜粋
+const samplePlanJSON = `[
+  {
+    "Plan": {
+      "Node Type": "Limit",
+      "Startup Cost": 0.00,
+      "Total Cost": 0.35,
+      "Plan Rows": 10,
+      "Actual Total Time": 0.021,
+      "Actual Rows": 3,
+      "Actual Loops": 1,
+      "Shared Hit Blocks": 1,
+      "Plans": [
+        {
+          "Node Type": "Seq Scan",
+          "Relation Name": "users",
+          "Startup Cost": 0.00,
+          "Total Cost": 12.50,
+          "Plan Rows": 250,
+          "Actual Total Time": 0.015,
+          "Actual Rows": 3,
+          "Actual Loops": 1,
+          "Shared Hit Blocks": 1
+        }
+      ]
+    },
+    "Planning Time": 0.120,
+    "Execution Time": 0.045
+  }
+]`
+
+// newExplainTestDriver returns a driver whose queries answer with samplePlanJSON
+// newExplainTestDriver: クエリがsamplePlanJSONを返すドライバーを作成する関数
+func newExplainTestDriver(t *testing.T) (*PostgreSQLDriver, *fakeDB) {
+	t.Helper()
+
+	driver, fake := newTestDriver(t)
+	fake.query = func(string, []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		return &fakeRows{columns: []string{"QUERY PLAN"}, values: [][]sqldriver.Value{{samplePlanJSON}}}, nil
+	}
+	return driver, fake
+}
+
+// TestExplain tests that Explain prefixes the statement and parses the plan tree
+// TestExplain: Explainが文に接頭辞を付け、実行計画ツリーを解析することをテストする関数
+func TestExplain(t *testing.T) {
+	driver, fake := newExplainTestDriver(t)
+
+	result, err := driver.Explain(context.Background(), "SELECT * FROM app.users LIMIT $1", 10)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	executed := fake.executed()
+	if len(executed) != 1 || executed[0] != "EXPLAIN (FORMAT JSON) SELECT * FROM app.users LIMIT $1" {
+		t.Errorf("Expected a single EXPLAIN statement, got: %v", executed)
+	}
+	if result.Analyzed {
+		t.Error("Expected Analyzed to be false")
+	}
+	if result.Plan.NodeType != "Limit" || result.TotalCost != 0.35 {
+		t.Errorf("Expected the Limit root with cost 0.35, got: %s %v", result.Plan.NodeType, result.TotalCost)
+	}
+	if len(result.Plan.Plans) != 1 || result.Plan.Plans[0].RelationName != "users" {
+		t.Errorf("Expected a Seq Scan child on users, got: %+v", result.Plan.Plans)
+	}
+	if string(result.Raw) != samplePlanJSON {
+		t.Error("Expected the raw JSON to be returned unchanged")
+	}
+}
+
+// TestExplainAnalyze tests the read-only guard and the transaction ANALYZE runs in
+// TestExplainAnalyze: 読み取り専用の確認とANALYZEを実行するトランザクションをテストする関数
+func TestExplainAnalyze(t *testing.T) {
+	testCases := []struct {
+		name             string
+		query            string
+		opts             ExplainOptions
+		expectError      bool
+		expectedExecuted []string // expected executed: 実行されるべき文
+	}{
+		{
+			name:  "Select runs in a rolled back transaction",
+			query: "SELECT 1",
+			expectedExecuted: []string{
+				"BEGIN", "EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) SELECT 1", "ROLLBACK",
+			},
+		},
+		{
+			name:        "Update refused",
+			query:       "UPDATE app.users SET is_active = FALSE",
+			expectError: true,
+		},
+		{
+			name:        "Writing CTE refused",
+			query:       "WITH gone AS (DELETE FROM app.users RETURNING id) SELECT count(*) FROM gone",
+			expectError: true,
+		},
+		{
+			name:             "Update allowed with AllowWrites",
+			query:            "UPDATE app.users SET is_active = FALSE",
+			opts:             ExplainOptions{AllowWrites: true},
+			expectedExecuted: []string{"EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) UPDATE app.users SET is_active = FALSE"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, fake := newExplainTestDriver(t)
+
+			result, err := driver.ExplainAnalyze(context.Background(), tc.opts, tc.query)
+			if tc.expectError {
+				if !errors.Is(err, ErrExplainAnalyzeWrite) {
+					t.Errorf("Expected ErrExplainAnalyzeWrite, got: %v", err)
+				}
+				if executed := fake.executed(); len(executed) != 0 {
+					t.Errorf("Expected nothing to be executed, got: %v", executed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if got := strings.Join(fake.executed(), "; "); got != strings.Join(tc.expectedExecuted, "; ") {
+				t.Errorf("Expected statements %v, got: %s", tc.expectedExecuted, got)
+			}
+			if !result.Analyzed {
+				t.Error("Expected Analyzed to be true")
+			}
+			if result.ExecutionTime != 45*time.Microsecond || result.PlanningTime != 120*time.Microsecond {
+				t.Errorf("Expected 45µs execution and 120µs planning, got: %v and %v", result.ExecutionTime, result.PlanningTime)
+			}
+			if result.Plan.ActualRows != 3 || result.Plan.SharedHitBlocks != 1 {
+				t.Errorf("Expected actual rows and buffers on the root node, got: %+v", result.Plan)
+			}
+		})
+	}
+}
+
+// TestParseExplainInvalid tests that malformed EXPLAIN output is reported
+// TestParseExplainInvalid: 不正なEXPLAIN出力がエラーになることをテストする関数
+func TestParseExplainInvalid(t *testing.T) {
+	for _, raw := range []string{"", "not json", "[]"} {
+		if _, err := parseExplain(raw, false); err == nil {
+			t.Errorf("Expected error for %q but got none", raw)
+		}
+	}
+}
+
+// TestIsReadOnlyStatement tests the statement classification used by ExplainAnalyze
+// TestIsReadOnlyStatement: ExplainAnalyzeが使う文の分類をテストする関数
+func TestIsReadOnlyStatement(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected bool
+	}{
+		{query: "SELECT 1", expected: true},
+		{query: "  select * from t", expected: true},
+		{query: "-- lookup\n/* by id */ SELECT * FROM t WHERE id = $1", expected: true},
+		{query: "(SELECT 1) UNION (SELECT 2)", expected: true},
+		{query: "VALUES (1), (2)", expected: true},
+		{query: "TABLE app.users", expected: true},
+		{query: "WITH recent AS (SELECT * FROM t) SELECT * FROM recent", expected: true},
+		{query: "WITH moved AS (UPDATE t SET n = 1 RETURNING *) SELECT * FROM moved", expected: false},
+		{query: "INSERT INTO t VALUES (1)", expected: false},
+		{query: "DELETE FROM t", expected: false},
+		{query: "CREATE TABLE t2 AS SELECT * FROM t", expected: false},
+		{query: "-- only a comment", expected: false},
+		{query: "", expected: false},
+	}
+
+	for _, tc := range testCases {
+		if got := isReadOnlyStatement(tc.query); got != tc.expected {
+			t.Errorf("Expected %v for %q, got: %v", tc.expected, tc.query, got)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"strconv"      // strconv: string conversion（文字列変換）
+	"strings"      // strings: 文字列操作
+)
+
+// Target session attributes accepted in TargetSessionAttrs, following libpq's target_session_attrs
+// TargetSessionAttrsで受け付けるセッション属性、libpqのtarget_session_attrsに従う
+const (
+	TargetSessionAny       = "any"        // any: どのサーバーでもよい（デフォルト）
+	TargetSessionReadWrite = "read-write" // read-write: 書き込み可能なサーバー
+	TargetSessionReadOnly  = "read-only"  // read-only: 読み取り専用のサーバー
+	TargetSessionPrimary   = "primary"    // primary: リカバリ中でないプライマリ
+	TargetSessionStandby   = "standby"    // standby: リカバリ中のスタンバイ
+)
+
+// validTargetSessionAttrs lists the accepted TargetSessionAttrs values
+// validTargetSessionAttrs: 受け付けるTargetSessionAttrsの値の一覧
+var validTargetSessionAttrs = []string{TargetSessionAny, TargetSessionReadWrite, TargetSessionReadOnly, TargetSessionPrimary, TargetSessionStandby}
+
+// hosts splits Host into its comma-separated entries
+// hosts: Hostをカンマ区切りの各要素に分割する関数
+func (c *DatabaseConfig) hosts() []string {
+	hosts := strings.Split(c.Host, ",")
+	for i := range hosts {
+		hosts[i] = strings.TrimSpace(hosts[i])
+	}
+	return hosts
+}
+
+// hostPort returns the port for the i-th host: its entry in Ports, or Port for every host
+// hostPort: i番目のホストのポートを返す関数、Portsの対応する要素、またはすべてのホストに共通のPort
+func (c *DatabaseConfig) hostPort(i int) int {
+	if len(c.Ports) > 0 {
+		return c.Ports[i]
+	}
+	return c.Port
+}
+
+// isMultiHost reports whether Host lists more than one host
+// isMultiHost: Hostが複数のホストを列挙しているかどうかを判定する関数
+func (c *DatabaseConfig) isMultiHost() bool {
+	return strings.Contains(c.Host, ",")
+}
+
+// hostConfigs returns one single-host configuration per listed host, in order
+// hostConfigs: 列挙されたホストごとに単一ホストの設定を順に返す関数
+// lib/pq dials a single host, so the driver tries each one itself: lib/pqは単一のホストにしか接続しないため、ドライバー自身が順に試す
+func (c *DatabaseConfig) hostConfigs() []*DatabaseConfig {
+	hosts := c.hosts()
+	configs := make([]*DatabaseConfig, len(hosts))
+	for i, host := range hosts {
+		single := cloneConfig(c)
+		single.Host = host
+		single.Port = c.hostPort(i)
+		single.Ports = nil
+		single.TargetSessionAttrs = "" // checked by the driver: ドライバーが確認する
+		configs[i] = &single
+	}
+	return configs
+}
+
+// formatHosts renders the host= and port= values of a key/value connection string
+// formatHosts: キー/値形式の接続文字列のhost=とport=の値を整形する関数
+func (c *DatabaseConfig) formatHosts() (string, string) {
+	if !c.isMultiHost() {
+		return formatHost(c.Host), strconv.Itoa(c.Port)
+	}
+
+	hosts := c.hosts()
+	ports := make([]string, len(hosts))
+	quote := false
+	for i, host := range hosts {
+		if bare, ok := ipv6Literal(host); ok {
+			hosts[i] = bare
+			quote = true // colons in a list are quoted as one value: リスト中のコロンは値全体を引用符で囲む
+		}
+		ports[i] = strconv.Itoa(c.hostPort(i))
+	}
+
+	hostList := strings.Join(hosts, ",")
+	if quote {
+		hostList = "'" + hostList + "'"
+	}
+	if len(c.Ports) == 0 {
+		return hostList, strconv.Itoa(c.Port)
+	}
+	return hostList, strings.Join(ports, ",")
+}
+
+// HostPortLists returns the hosts and their ports as the comma-separated lists libpq takes in PGHOST and PGPORT
+// HostPortLists: ホストとそのポートを、libpqがPGHOSTとPGPORTで受け付けるカンマ区切りのリストとして返す関数
+// IPv6 addresses lose their brackets; without Ports the port is Port for every host, or empty when it is 0
+// IPv6アドレスは角括弧を外す、Portsがない場合のポートは全ホスト共通のPort、0の場合は空
+func (c *DatabaseConfig) HostPortLists() (string, string) {
+	hosts := c.hosts()
+	for i, host := range hosts {
+		if bare, ok := ipv6Literal(host); ok {
+			hosts[i] = bare
+		}
+	}
+	if len(c.Ports) == 0 {
+		if c.Port == 0 {
+			return strings.Join(hosts, ","), ""
+		}
+		return strings.Join(hosts, ","), strconv.Itoa(c.Port)
+	}
+
+	ports := make([]string, len(c.Ports))
+	for i, port := range c.Ports {
+		ports[i] = strconv.Itoa(port)
+	}
+	return strings.Join(hosts, ","), strings.Join(ports, ",")
+}
+
+// validateHostList checks every listed host, the port list and the target session attributes
+// validateHostList: 列挙された各ホスト、ポートのリスト、ターゲットセッション属性を検証する関数
+func validateHostList(config *DatabaseConfig) error {
+	hosts := config.hosts()
+	for _, host := range hosts {
+		if host == "" {
+			return fmt.Errorf("host list %q contains an empty entry", config.Host) // entry: 要素
+		}
+		if err := validateHost(host); err != nil {
+			return err
+		}
+	}
+
+	if len(config.Ports) > 0 && len(config.Ports) != len(hosts) {
+		return fmt.Errorf("port list has %d entries but the host list has %d; give one port per host or a single port for all", len(config.Ports), len(hosts))
+	}
+	for _, port := range config.Ports {
+		if port <= 0 || port > 65535 {
+			return fmt.Errorf("port must be between 1 and 65535")
+		}
+	}
+
+	if config.TargetSessionAttrs != "" {
+		valid := false
+		for _, attrs := range validTargetSessionAttrs {
+			if config.TargetSessionAttrs == attrs {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid target session attrs: %s (accepted: %s)", config.TargetSessionAttrs, strings.Join(validTargetSessionAttrs, ", "))
+		}
+	}
+	return nil
+}
+
+// parsePortList parses DB_PORT, which is a single port or a comma-separated list
+// parsePortList: 単一のポートまたはカンマ区切りのリストであるDB_PORTを解析する関数
+// A single port returns a nil list: 単一のポートの場合はリストとしてnilを返す
+func parsePortList(value string) (int, []int, error) {
+	if !strings.Contains(value, ",") {
+		port, err := strconv.Atoi(value)
+		return port, nil, err
+	}
+
+	var ports []int
+	for _, entry := range strings.Split(value, ",") {
+		port, err := strconv.Atoi(strings.TrimSpace(entry))
+		if err != nil {
+			return 0, nil, err
+		}
+		ports = append(ports, port)
+	}
+	return ports[0], ports, nil
+}
+
+// openPoolWith opens a pool on the first listed host that accepts the connection and matches TargetSessionAttrs
+// openPoolWith: 接続を受け付け、TargetSessionAttrsに合う最初のホストでプールを開く内部関数
+// Hosts are tried in order, as libpq does: libpqと同じくホストを順に試す
+func (d *PostgreSQLDriver) openPoolWith(ctx context.Context, config *DatabaseConfig) (*sql.DB, error) {
+	candidates := config.hostConfigs()
+	if len(candidates) == 1 && !requiresSessionCheck(config.TargetSessionAttrs) {
+		return d.openPoolOn(ctx, candidates[0])
+	}
+
+	var errs []error
+	for _, candidate := range candidates {
+		db, err := d.openPoolOn(ctx, candidate)
+		if err == nil {
+			if err = checkTargetSessionAttrs(ctx, db, config.TargetSessionAttrs); err != nil {
+				db.Close()
+			}
+		}
+		if err == nil {
+			if len(candidates) > 1 {
+				d.mu.Lock()
+				d.connectedHost = candidate.Host
+				d.connectedPort = candidate.Port
+				d.mu.Unlock()
+			}
+			return db, nil
+		}
+
+		errs = append(errs, fmt.Errorf("host %s: %w", candidate.Host, err))
+		if ctx.Err() != nil {
+			break // give up on the remaining hosts: 残りのホストは諦める
+		}
+	}
+	return nil, fmt.Errorf("no host matched target_session_attrs=%s: %w", targetSessionAttrsOrAny(config.TargetSessionAttrs), errors.Join(errs...))
+}
+
+// requiresSessionCheck reports whether attrs needs a query after connecting
+// requiresSessionCheck: 接続後にattrsの確認クエリが必要かどうかを判定する関数
+func requiresSessionCheck(attrs string) bool {
+	return attrs != "" && attrs != TargetSessionAny
+}
+
+// targetSessionAttrsOrAny returns attrs, or "any" when it is empty
+// targetSessionAttrsOrAny: attrsを返す関数、空の場合は"any"を返す
+func targetSessionAttrsOrAny(attrs string) string {
+	if attrs == "" {
+		return TargetSessionAny
+	}
+	return attrs
+}
+
+// checkTargetSessionAttrs verifies that the server behind db matches attrs
+// checkTargetSessionAttrs: dbの接続先サーバーがattrsに合うことを確認する関数
+func checkTargetSessionAttrs(ctx context.Context, db *sql.DB, attrs string) error {
+	switch attrs {
+	case TargetSessionReadWrite, TargetSessionReadOnly:
+		var readOnly string
+		if err := db.QueryRowContext(ctx, "SHOW transaction_read_only").Scan(&readOnly); err != nil {
+			return fmt.Errorf("failed to check transaction_read_only: %w", err)
+		}
+		if (readOnly == "on") != (attrs == TargetSessionReadOnly) {
+			return fmt.Errorf("server is not %s (transaction_read_only=%s)", attrs, readOnly)
+		}
+	case TargetSessionPrimary, TargetSessionStandby:
+		var inRecovery bool
+		if err := db.QueryRowContext(ctx, "SELECT pg_is_in_recovery()").Scan(&inRecovery); err != nil {
+			return fmt.Errorf("failed to check pg_is_in_recovery: %w", err)
+		}
+		if inRecovery != (attrs == TargetSessionStandby) {
+			return fmt.Errorf("server is not a %s", attrs)
+		}
+	}
+	return nil
+}
+
+// activeHostConfig returns the configuration of the host the pool connected to
+// activeHostConfig: プールが接続したホストの設定を返す関数
+// Used for extra connections, such as LISTEN, that must reach the same server: LISTENなど同じサーバーに接続すべき追加の接続に使う
+func (d *PostgreSQLDriver) activeHostConfig() *DatabaseConfig {
+	config := d.config.Load()
+	if !config.isMultiHost() {
+		return config
+	}
+
+	d.mu.Lock()
+	host, port := d.connectedHost, d.connectedPort
+	d.mu.Unlock()
+
+	candidates := config.hostConfigs()
+	for _, candidate := range candidates {
+		if candidate.Host == host && candidate.Port == port {
+			return candidate
+		}
+	}
+	return candidates[0] // not connected yet: まだ接続していない
+}
+
+// queryServerAddr returns inet_server_addr() for the connection db answers on
+// queryServerAddr: dbが応答した接続のinet_server_addr()を返す関数
+// It is empty over a Unix-domain socket: Unixドメインソケット経由の場合は空
+func queryServerAddr(ctx context.Context, db *sql.DB) (string, error) {
+	var addr sql.NullString
+	if err := db.QueryRowContext(ctx, "SELECT host(inet_server_addr())").Scan(&addr); err != nil {
+		return "", err
+	}
+	return addr.String, nil
+}
@@ -0,0 +1,327 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	"database/sql"                  // sql: データベース操作用パッケージ
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"os"                            // os: 環境変数操作
+	"reflect"                       // reflect: リフレクション、値の比較
+	"strings"                       // strings: 文字列操作
+	"testing"                       // testing: テスト機能
+)
+
+// TestMultiHostConnectionString tests the host list, port list and target_session_attrs in the DSN
+// TestMultiHostConnectionString: DSNのホストのリスト、ポートのリスト、target_session_attrsをテストする関数
+func TestMultiHostConnectionString(t *testing.T) {
+	testCases := []struct {
+		name     string
+		host     string
+		port     int
+		ports    []int
+		attrs    string
+		expected string // expected: 期待する接続文字列の先頭
+		suffix   string // suffix: 期待する接続文字列の末尾
+	}{
+		{name: "Single host is unchanged", host: "db", port: 5432, expected: "host=db port=5432 "},
+		{name: "Host list with one port", host: "db-a,db-b", port: 5432, expected: "host=db-a,db-b port=5432 "},
+		{name: "Host list with ports", host: "db-a, db-b", port: 5432, ports: []int{5432, 5433}, expected: "host=db-a,db-b port=5432,5433 "},
+		{name: "IPv6 in a host list", host: "::1,db-b", port: 5432, expected: "host='::1,db-b' port=5432 "},
+		{name: "Target session attrs", host: "db-a,db-b", port: 5432, attrs: TargetSessionReadWrite, expected: "host=db-a,db-b port=5432 ", suffix: " target_session_attrs=read-write"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &DatabaseConfig{
+				Host:               tc.host,
+				Port:               tc.port,
+				Ports:              tc.ports,
+				User:               "user",
+				Password:           "pass",
+				Database:           "db",
+				SSLMode:            "disable",
+				TargetSessionAttrs: tc.attrs,
+			}
+			if err := validateDatabaseConfig(config); err != nil {
+				t.Fatalf("Expected a valid configuration, got: %v", err)
+			}
+
+			actual := config.BuildConnectionString()
+			if !strings.HasPrefix(actual, tc.expected) || !strings.HasSuffix(actual, tc.suffix) {
+				t.Errorf("Expected connection string %q...%q, got: %q", tc.expected, tc.suffix, actual)
+			}
+		})
+	}
+}
+
+// TestHostPortLists tests the PGHOST and PGPORT values for single and multi-host configurations
+// TestHostPortLists: 単一ホストと複数ホストの設定に対するPGHOSTとPGPORTの値をテストする関数
+func TestHostPortLists(t *testing.T) {
+	testCases := []struct {
+		name          string
+		host          string
+		port          int
+		ports         []int
+		expectedHosts string
+		expectedPorts string
+	}{
+		{name: "Single host", host: "db", port: 5432, expectedHosts: "db", expectedPorts: "5432"},
+		{name: "Single host without port", host: "db", expectedHosts: "db", expectedPorts: ""},
+		{name: "Host list with one port", host: "db-a, db-b", port: 5433, expectedHosts: "db-a,db-b", expectedPorts: "5433"},
+		{name: "Host list with ports", host: "db-a,db-b", port: 5432, ports: []int{5432, 5433}, expectedHosts: "db-a,db-b", expectedPorts: "5432,5433"},
+		{name: "IPv6 in a host list", host: "[::1],db-b", port: 5432, expectedHosts: "::1,db-b", expectedPorts: "5432"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &DatabaseConfig{Host: tc.host, Port: tc.port, Ports: tc.ports}
+			hosts, ports := config.HostPortLists()
+			if hosts != tc.expectedHosts || ports != tc.expectedPorts {
+				t.Errorf("Expected %q and %q, got: %q and %q", tc.expectedHosts, tc.expectedPorts, hosts, ports)
+			}
+		})
+	}
+}
+
+// TestValidateHostList tests list lengths, entries and target session attrs
+// TestValidateHostList: リストの長さ、各要素、ターゲットセッション属性の検証をテストする関数
+func TestValidateHostList(t *testing.T) {
+	testCases := []struct {
+		name         string
+		config       DatabaseConfig
+		expectError  bool
+		errorContent string // error content: エラーに含まれるべき文字列
+	}{
+		{name: "Matching port list", config: DatabaseConfig{Host: "a,b", Port: 5432, Ports: []int{5432, 5433}}, expectError: false},
+		{name: "Single port for all hosts", config: DatabaseConfig{Host: "a,b,c", Port: 5432}, expectError: false},
+		{name: "Port list too short", config: DatabaseConfig{Host: "a,b,c", Port: 5432, Ports: []int{5432, 5433}}, expectError: true, errorContent: "has 2 entries but the host list has 3"},
+		{name: "Port list too long", config: DatabaseConfig{Host: "a", Port: 5432, Ports: []int{5432, 5433}}, expectError: true, errorContent: "has 2 entries but the host list has 1"},
+		{name: "Port out of range in list", config: DatabaseConfig{Host: "a,b", Port: 5432, Ports: []int{5432, 70000}}, expectError: true, errorContent: "between 1 and 65535"},
+		{name: "Empty entry", config: DatabaseConfig{Host: "a,,b", Port: 5432}, expectError: true, errorContent: "empty entry"},
+		{name: "Stray port in list", config: DatabaseConfig{Host: "a,b:5433", Port: 5432}, expectError: true, errorContent: "DB_PORT"},
+		{name: "Valid target session attrs", config: DatabaseConfig{Host: "a,b", Port: 5432, TargetSessionAttrs: TargetSessionStandby}, expectError: false},
+		{name: "Invalid target session attrs", config: DatabaseConfig{Host: "a,b", Port: 5432, TargetSessionAttrs: "writable"}, expectError: true, errorContent: "invalid target session attrs"},
+		{name: "IAM with a host list", config: DatabaseConfig{Host: "a,b", Port: 5432, SSLMode: "require", AuthMethod: AuthMethodAWSIAM}, expectError: true, errorContent: "single host"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := tc.config
+			config.User = "user"
+			config.Password = "pass"
+			config.Database = "db"
+			if config.SSLMode == "" {
+				config.SSLMode = "disable"
+			}
+
+			err := validateDatabaseConfig(&config)
+			if !tc.expectError {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+				t.Errorf("Expected error containing %q, got: %v", tc.errorContent, err)
+			}
+		})
+	}
+}
+
+// TestLoadDatabaseConfigHostList tests DB_HOST, DB_PORT lists and DB_TARGET_SESSION_ATTRS
+// TestLoadDatabaseConfigHostList: DB_HOSTとDB_PORTのリスト、DB_TARGET_SESSION_ATTRSの読み込みをテストする関数
+func TestLoadDatabaseConfigHostList(t *testing.T) {
+	envVars := map[string]string{
+		"DB_USER":                 "user",
+		"DB_PASSWORD":             "pass",
+		"DB_NAME":                 "db",
+		"DB_HOST":                 "db-a,db-b",
+		"DB_PORT":                 "5432, 5433",
+		"DB_TARGET_SESSION_ATTRS": " Read-Write ",
+	}
+	for key, value := range envVars {
+		os.Setenv(key, value)
+	}
+	defer func() {
+		for key := range envVars {
+			os.Unsetenv(key)
+		}
+	}()
+
+	config, err := LoadDatabaseConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if config.Host != "db-a,db-b" || config.Port != 5432 || !reflect.DeepEqual(config.Ports, []int{5432, 5433}) {
+		t.Errorf("Expected the host and port lists, got: %v", config)
+	}
+	if config.TargetSessionAttrs != TargetSessionReadWrite {
+		t.Errorf("Expected normalized target session attrs, got: %s", config.TargetSessionAttrs)
+	}
+
+	os.Setenv("DB_PORT", "5432,x")
+	if _, err := LoadDatabaseConfig(); err == nil {
+		t.Error("Expected error for an invalid port list")
+	}
+}
+
+// TestMultiHostURLRoundTrip tests that a host list survives BuildConnectionURL and ConfigFromURL
+// TestMultiHostURLRoundTrip: ホストのリストがBuildConnectionURLとConfigFromURLを往復できることをテストする関数
+func TestMultiHostURLRoundTrip(t *testing.T) {
+	original := DatabaseConfig{
+		Host:               "db-a,db-b",
+		Port:               5432,
+		Ports:              []int{5432, 5433},
+		User:               "user",
+		Password:           "pass",
+		Database:           "app",
+		SSLMode:            "require",
+		TimeZone:           defaultTimeZone,
+		TargetSessionAttrs: TargetSessionReadWrite,
+		AuthMethod:         AuthMethodPassword,
+	}
+
+	built := original.BuildConnectionURL()
+	if !strings.Contains(built, "@db-a:5432,db-b:5433/app") {
+		t.Errorf("Expected the host list in the URL, got: %s", built)
+	}
+	parsed, err := ConfigFromURL(built)
+	if err != nil {
+		t.Fatalf("Expected URL to parse, got: %v", err)
+	}
+	if !reflect.DeepEqual(*parsed, original) {
+		t.Errorf("Expected %#v, got: %#v", original, *parsed)
+	}
+}
+
+// fakeHost describes one server of a multi-host test
+// fakeHost: 複数ホストのテストにおける1台のサーバー
+type fakeHost struct {
+	down       bool   // down: 接続できない
+	readOnly   string // read only: transaction_read_onlyの値
+	serverAddr string // server address: inet_server_addr()の値
+}
+
+// newMultiHostTestDriver creates a driver over fake servers keyed by host name
+// newMultiHostTestDriver: ホスト名をキーとするフェイクサーバーに接続するテスト用ドライバーを作成する関数
+func newMultiHostTestDriver(t *testing.T, attrs string, servers map[string]fakeHost) (*PostgreSQLDriver, *[]string) {
+	t.Helper()
+
+	driver, err := NewPostgreSQLDriverWithConfig(&DatabaseConfig{
+		Host:               "db-a,db-b",
+		Port:               5432,
+		User:               "testuser",
+		Password:           "testpass",
+		Database:           "testdb",
+		SSLMode:            "disable",
+		TargetSessionAttrs: attrs,
+	}, WithResolver(fakeResolver{}))
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	var dialed []string
+	driver.openDB = func(connectionString string) (*sql.DB, error) {
+		host := strings.TrimPrefix(strings.Fields(connectionString)[0], "host=")
+		dialed = append(dialed, host)
+		server := servers[host]
+
+		fake, db := newFakeDB()
+		fake.ping = func(context.Context) error {
+			if server.down {
+				return errors.New("connection refused")
+			}
+			return nil
+		}
+		fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+			value := server.readOnly
+			if strings.Contains(query, "inet_server_addr") {
+				value = server.serverAddr
+			}
+			return &fakeRows{columns: []string{"value"}, values: [][]sqldriver.Value{{value}}}, nil
+		}
+		return db, nil
+	}
+	t.Cleanup(func() { driver.Close() })
+	return driver, &dialed
+}
+
+// TestMultiHostFailover tests that hosts are tried in order until one matches target_session_attrs
+// TestMultiHostFailover: target_session_attrsに合うホストが見つかるまで順に試すことをテストする関数
+func TestMultiHostFailover(t *testing.T) {
+	testCases := []struct {
+		name           string
+		attrs          string
+		servers        map[string]fakeHost
+		expectError    bool
+		expectedDialed []string // expected dialed: 接続を試みたホスト
+		expectedAddr   string   // expected address: ヘルスチェックが報告するアドレス
+	}{
+		{
+			name:           "First host answers",
+			servers:        map[string]fakeHost{"db-a": {readOnly: "on", serverAddr: "10.0.0.1"}, "db-b": {readOnly: "off", serverAddr: "10.0.0.2"}},
+			expectedDialed: []string{"db-a"},
+			expectedAddr:   "10.0.0.1",
+		},
+		{
+			name:           "First host down",
+			servers:        map[string]fakeHost{"db-a": {down: true}, "db-b": {readOnly: "off", serverAddr: "10.0.0.2"}},
+			expectedDialed: []string{"db-a", "db-b"},
+			expectedAddr:   "10.0.0.2",
+		},
+		{
+			name:           "Read-write skips the standby",
+			attrs:          TargetSessionReadWrite,
+			servers:        map[string]fakeHost{"db-a": {readOnly: "on", serverAddr: "10.0.0.1"}, "db-b": {readOnly: "off", serverAddr: "10.0.0.2"}},
+			expectedDialed: []string{"db-a", "db-b"},
+			expectedAddr:   "10.0.0.2",
+		},
+		{
+			name:           "Read-only picks the standby",
+			attrs:          TargetSessionReadOnly,
+			servers:        map[string]fakeHost{"db-a": {readOnly: "on", serverAddr: "10.0.0.1"}, "db-b": {readOnly: "off", serverAddr: "10.0.0.2"}},
+			expectedDialed: []string{"db-a"},
+			expectedAddr:   "10.0.0.1",
+		},
+		{
+			name:           "No writable host",
+			attrs:          TargetSessionReadWrite,
+			servers:        map[string]fakeHost{"db-a": {readOnly: "on"}, "db-b": {down: true}},
+			expectError:    true,
+			expectedDialed: []string{"db-a", "db-b"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, dialed := newMultiHostTestDriver(t, tc.attrs, tc.servers)
+
+			err := driver.Connect()
+			if !reflect.DeepEqual(*dialed, tc.expectedDialed) {
+				t.Errorf("Expected hosts %v to be tried, got: %v", tc.expectedDialed, *dialed)
+			}
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), "host db-a") || !strings.Contains(err.Error(), "host db-b") {
+					t.Errorf("Expected an error naming every host, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			status, err := driver.HealthCheck(context.Background())
+			if err != nil {
+				t.Fatalf("Expected healthy, got: %v", err)
+			}
+			if status.ServerAddr != tc.expectedAddr {
+				t.Errorf("Expected server address %s, got: %s", tc.expectedAddr, status.ServerAddr)
+			}
+
+			reached := tc.expectedDialed[len(tc.expectedDialed)-1]
+			if active := driver.activeHostConfig(); active.Host != reached || active.isMultiHost() {
+				t.Errorf("Expected extra connections to target %s, got: %s", reached, active.Host)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"             // context: コンテキスト
+	"database/sql"        // sql: データベース操作用パッケージ
+	"database/sql/driver" // driver: SQLドライバーインターフェース
+	"errors"              // errors: エラー操作
+	"io"                  // io: 入出力
+	"sync"                // sync: 同期処理
+)
+
+// fakeDB is an in-memory database/sql driver used by unit tests
+// fakeDB: ユニットテスト用のメモリ上のdatabase/sqlドライバー
+// in-memory: メモリ上の
+type fakeDB struct {
+	mu sync.Mutex // mu: mutex（相互排他ロック）
+
+	// exec and query decide how each statement behaves
+	// exec, query: 各文の振る舞いを決める関数
+	exec  func(query string, args []driver.NamedValue) (driver.Result, error)
+	query func(query string, args []driver.NamedValue) (driver.Rows, error)
+	ping  func(ctx context.Context) error // ping: Pingの振る舞い、nilなら常に成功
+
+	// observe sees the context of every statement, e.g. to check its deadline
+	// observe: 各文のコンテキストを受け取る関数（期限の確認などに使う）
+	observe func(ctx context.Context)
+
+	statements   []string           // statements: 実行された文の記録
+	txStatements []string           // tx statements: トランザクション内で実行された文の記録
+	txOptions    []driver.TxOptions // tx options: 開始したトランザクションのオプションの記録
+}
+
+// newFakeDB creates a fake driver and a *sql.DB backed by it
+// newFakeDB: フェイクドライバーとそれを使う*sql.DBを作成する関数
+func newFakeDB() (*fakeDB, *sql.DB) {
+	fake := &fakeDB{}
+	return fake, sql.OpenDB(fake)
+}
+
+// executed returns a copy of the recorded statements
+// executed: 記録された文のコピーを返す関数
+func (f *fakeDB) executed() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.statements...)
+}
+
+// executedInTx returns a copy of the statements recorded inside a transaction
+// executedInTx: トランザクション内で記録された文のコピーを返す関数
+func (f *fakeDB) executedInTx() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.txStatements...)
+}
+
+func (f *fakeDB) record(query string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statements = append(f.statements, query)
+}
+
+func (c *fakeConn) record(query string) {
+	c.db.record(query)
+	if c.inTx {
+		c.db.mu.Lock()
+		c.db.txStatements = append(c.db.txStatements, query)
+		c.db.mu.Unlock()
+	}
+}
+
+// Connect implements driver.Connector
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+
+// Driver implements driver.Connector
+func (f *fakeDB) Driver() driver.Driver { return fakeDriver{db: f} }
+
+type fakeDriver struct {
+	db *fakeDB
+}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("fakeDriver: use sql.OpenDB")
+}
+
+// OpenConnector implements driver.DriverContext so a pool can be reopened from its driver
+// OpenConnector: driver.DriverContextの実装、ドライバーからプールを開き直せるようにする
+func (d fakeDriver) OpenConnector(string) (driver.Connector, error) { return d.db, nil }
+
+// fakeConn is a single fake connection
+// fakeConn: フェイクの単一接続
+type fakeConn struct {
+	db   *fakeDB
+	inTx bool // inTx: トランザクション中かどうか
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("fakeConn: Prepare is not supported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.db.record("BEGIN")
+	c.db.mu.Lock()
+	c.db.txOptions = append(c.db.txOptions, opts)
+	c.db.mu.Unlock()
+	c.inTx = true
+	return &fakeTx{db: c.db, conn: c}, nil
+}
+
+func (c *fakeConn) Ping(ctx context.Context) error {
+	if c.db.ping == nil {
+		return nil
+	}
+	return c.db.ping(ctx)
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.record(query)
+	if c.db.observe != nil {
+		c.db.observe(ctx)
+	}
+	if c.db.exec == nil {
+		return driver.RowsAffected(0), nil
+	}
+	return c.db.exec(query, args)
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.record(query)
+	if c.db.observe != nil {
+		c.db.observe(ctx)
+	}
+	if c.db.query == nil {
+		return &fakeRows{}, nil
+	}
+	return c.db.query(query, args)
+}
+
+// fakeTx records COMMIT and ROLLBACK as statements
+// fakeTx: COMMITとROLLBACKを文として記録するフェイクトランザクション
+type fakeTx struct {
+	db   *fakeDB
+	conn *fakeConn // conn: トランザクションを開始した接続
+}
+
+func (t *fakeTx) Commit() error {
+	t.conn.inTx = false
+	t.db.record("COMMIT")
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.conn.inTx = false
+	t.db.record("ROLLBACK")
+	return nil
+}
+
+// fakeRows returns a fixed set of rows
+// fakeRows: 固定の行を返すフェイク結果セット
+type fakeRows struct {
+	columns []string         // columns: カラム名（複数形）
+	values  [][]driver.Value // values: 行の値
+	pos     int              // pos: position（位置）
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.pos])
+	r.pos++
+	return nil
+}
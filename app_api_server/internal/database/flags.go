@@ -0,0 +1,60 @@
+package database
+
+import (
+	"flag" // flag: コマンドライン引数の解析
+)
+
+// configFlagNames maps the connection fields to their command-line flag names
+// configFlagNames: 接続フィールドとコマンドラインフラグ名の対応表
+var configFlagNames = map[string]string{
+	"host":     "db-host",
+	"port":     "db-port",
+	"user":     "db-user",
+	"database": "db-name",
+	"sslmode":  "sslmode",
+}
+
+// DatabaseConfigFlags holds the connection flags registered by FlagSet
+// DatabaseConfigFlags: FlagSetが登録した接続用のフラグの値を保持する構造体
+// The password has no flag so it never shows up in process listings or shell history
+// パスワードはプロセス一覧やシェルの履歴に残らないようフラグを持たない
+type DatabaseConfigFlags struct {
+	host     string // host: --db-host
+	port     string // port: --db-port、"5432,5433"のようなホストごとのポートも可
+	user     string // user: --db-user
+	database string // database: --db-name
+	sslMode  string // ssl mode: --sslmode
+}
+
+// FlagSet registers --db-host, --db-port, --db-user, --db-name and --sslmode on fs
+// FlagSet: fsに--db-host、--db-port、--db-user、--db-name、--sslmodeを登録する関数
+// Call Resolve after fs.Parse to build the configuration: fs.Parseの後にResolveを呼んで設定を構築する
+func FlagSet(fs *flag.FlagSet) *DatabaseConfigFlags {
+	f := &DatabaseConfigFlags{}
+	fs.StringVar(&f.host, configFlagNames["host"], "", "database host, or a comma-separated host list (overrides DB_HOST)")
+	fs.StringVar(&f.port, configFlagNames["port"], "", "database port, or one port per host (overrides DB_PORT)")
+	fs.StringVar(&f.user, configFlagNames["user"], "", "database user (overrides DB_USER)")
+	fs.StringVar(&f.database, configFlagNames["database"], "", "database name (overrides DB_NAME)")
+	fs.StringVar(&f.sslMode, configFlagNames["sslmode"], "", "SSL mode such as disable or verify-full (overrides DB_SSL_MODE)")
+	return f
+}
+
+// Resolve builds the configuration with the precedence flags > environment > defaults
+// Resolve: フラグ > 環境変数 > デフォルト値の優先順位で設定を構築する関数
+// precedence: 優先順位
+// With envFallback false the flagged fields ignore DB_* and PG* and use their defaults when not given;
+// settings without a flag, such as the password and pool tuning, are still read from the environment
+// envFallbackがfalseの場合、フラグのあるフィールドはDB_*とPG*を読まず、未指定ならデフォルト値を使う
+// パスワードやプールの設定などフラグのない設定は引き続き環境変数から読む
+func (f *DatabaseConfigFlags) Resolve(envFallback bool) (*DatabaseConfig, error) {
+	return loadDatabaseConfig(&configSources{
+		flags: map[string]string{
+			"host":     f.host,
+			"port":     f.port,
+			"user":     f.user,
+			"database": f.database,
+			"sslmode":  f.sslMode,
+		},
+		ignoreEnv: !envFallback,
+	})
+}
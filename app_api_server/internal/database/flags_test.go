@@ -0,0 +1,113 @@
+package database
+
+import (
+	"flag"    // flag: コマンドライン引数の解析
+	"io"      // io: 入出力、使い方の出力を捨てる
+	"strings" // strings: 文字列操作
+	"testing" // testing: テスト機能
+)
+
+// TestResolveFlags tests the flags > environment > defaults precedence and partial overrides
+// TestResolveFlags: フラグ > 環境変数 > デフォルト値の優先順位と一部のみの上書きをテストする関数
+func TestResolveFlags(t *testing.T) {
+	testCases := []struct {
+		name            string
+		args            []string
+		env             map[string]string
+		envFallback     bool
+		expected        DatabaseConfig
+		expectedSources string
+		expectError     bool
+		errorContent    string
+	}{
+		{
+			name:            "Flags override the environment",
+			args:            []string{"--db-host", "flag-host", "--db-port", "6543", "--db-user", "flag-user", "--db-name", "flag-db", "--sslmode", "disable"},
+			env:             map[string]string{"DB_HOST": "env-host", "DB_PORT": "5433", "DB_USER": "env-user", "DB_PASSWORD": "env-pass", "DB_NAME": "env-db", "DB_SSL_MODE": "verify-full"},
+			envFallback:     true,
+			expected:        DatabaseConfig{Host: "flag-host", Port: 6543, User: "flag-user", Password: "env-pass", Database: "flag-db", SSLMode: "disable"},
+			expectedSources: "host=flag port=flag user=flag password=DB_PASSWORD database=flag sslmode=flag",
+		},
+		{
+			name:            "Partial override",
+			args:            []string{"--db-host=flag-host"},
+			env:             map[string]string{"DB_PORT": "5433", "PGUSER": "pg-user", "DB_PASSWORD": "env-pass", "DB_NAME": "env-db"},
+			envFallback:     true,
+			expected:        DatabaseConfig{Host: "flag-host", Port: 5433, User: "pg-user", Password: "env-pass", Database: "env-db", SSLMode: "require"},
+			expectedSources: "host=flag port=DB_PORT user=PGUSER password=DB_PASSWORD database=DB_NAME sslmode=default",
+		},
+		{
+			name:        "Flags supply required fields",
+			args:        []string{"--db-user", "flag-user", "--db-name", "flag-db"},
+			env:         map[string]string{"DB_PASSWORD": "env-pass"},
+			envFallback: true,
+			expected:    DatabaseConfig{Host: "localhost", Port: 5432, User: "flag-user", Password: "env-pass", Database: "flag-db", SSLMode: "require"},
+		},
+		{
+			name:            "Without environment fallback",
+			args:            []string{"--db-user", "flag-user", "--db-name", "flag-db"},
+			env:             map[string]string{"DB_HOST": "env-host", "DB_PORT": "5433", "DB_PASSWORD": "env-pass", "DB_SSL_MODE": "disable"},
+			envFallback:     false,
+			expected:        DatabaseConfig{Host: "localhost", Port: 5432, User: "flag-user", Password: "env-pass", Database: "flag-db", SSLMode: "require"},
+			expectedSources: "host=default port=default user=flag password=DB_PASSWORD database=flag sslmode=default",
+		},
+		{
+			name:         "Missing user with fallback",
+			env:          map[string]string{"DB_PASSWORD": "env-pass", "DB_NAME": "env-db"},
+			envFallback:  true,
+			expectError:  true,
+			errorContent: "--db-user flag or DB_USER (or PGUSER) environment variable is required",
+		},
+		{
+			name:         "Missing database without fallback",
+			args:         []string{"--db-user", "flag-user"},
+			env:          map[string]string{"DB_PASSWORD": "env-pass", "DB_NAME": "env-db"},
+			envFallback:  false,
+			expectError:  true,
+			errorContent: "--db-name flag is required",
+		},
+		{
+			name:         "Invalid port flag",
+			args:         []string{"--db-port", "abc"},
+			env:          map[string]string{"DB_USER": "u", "DB_PASSWORD": "p", "DB_NAME": "d"},
+			envFallback:  true,
+			expectError:  true,
+			errorContent: "invalid port number",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, key := range connectionEnvKeys {
+				t.Setenv(key, tc.env[key])
+			}
+			t.Setenv("DB_DEBUG", "true")
+			logs := captureLog(t)
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			flags := FlagSet(fs)
+			if err := fs.Parse(tc.args); err != nil {
+				t.Fatalf("Failed to parse flags: %v", err)
+			}
+
+			config, err := flags.Resolve(tc.envFallback)
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if config.Host != tc.expected.Host || config.Port != tc.expected.Port || config.User != tc.expected.User ||
+				config.Password != tc.expected.Password || config.Database != tc.expected.Database || config.SSLMode != tc.expected.SSLMode {
+				t.Errorf("Expected %v, got: %v", tc.expected, config)
+			}
+			if tc.expectedSources != "" && !strings.Contains(logs.String(), "Debug: Database configuration sources: "+tc.expectedSources) {
+				t.Errorf("Expected sources '%s' to be logged, got: %s", tc.expectedSources, logs.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context" // context: コンテキスト、処理の文脈情報
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"time"    // time: 時間操作機能
+)
+
+// defaultPingTimeout bounds the ping done by IsConnected
+// defaultPingTimeout: IsConnectedが行うpingの制限時間のデフォルト値
+// bounds: 制限する
+const defaultPingTimeout = 2 * time.Second
+
+// WithPingTimeout sets how long IsConnected waits for a ping
+// WithPingTimeout: IsConnectedがpingを待つ時間を設定するオプション
+func WithPingTimeout(timeout time.Duration) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		if timeout > 0 {
+			d.pingTimeout = timeout
+		}
+	}
+}
+
+// WithHealthEvents includes up to limit of the most recent lifecycle events in HealthCheck results
+// WithHealthEvents: HealthCheckの結果に直近のライフサイクルイベントを最大limit件含めるオプション
+func WithHealthEvents(limit int) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		if limit > 0 {
+			d.healthEvents = limit
+		}
+	}
+}
+
+// HealthStatus represents the result of a database health check
+// HealthStatus: データベースのヘルスチェック結果を表す構造体
+// health: 健康、status: 状態
+type HealthStatus struct {
+	Connected bool          `json:"connected"`       // connected: 接続済み
+	Latency   time.Duration `json:"latency"`         // latency: 応答時間
+	CheckedAt time.Time     `json:"checked_at"`      // checked: 確認された
+	Error     string        `json:"error,omitempty"` // error: エラー内容
+
+	CircuitState CircuitState `json:"circuit_state"`         // circuit state: Reconnectのサーキットブレーカーの状態
+	ServerAddr   string       `json:"server_addr,omitempty"` // server address: 実際に接続したサーバーのアドレス（inet_server_addr()）
+
+	Events []LifecycleEvent `json:"events,omitempty"` // events: 直近のライフサイクルイベント（WithHealthEvents指定時のみ）
+
+	VerboseLogging *VerboseLoggingState `json:"verbose_logging,omitempty"` // verbose logging: 有効な詳細クエリログの期間（有効な場合のみ）
+}
+
+// HealthCheck pings the database within ctx and reports the round-trip latency
+// HealthCheck: ctxの範囲内でデータベースにpingし、往復の応答時間を報告する関数
+// round-trip: 往復
+func (d *PostgreSQLDriver) HealthCheck(ctx context.Context) (status HealthStatus, err error) {
+	ctx, span := d.startSpan(ctx, "db.HealthCheck", "")
+	defer func() { endSpan(span, err) }()
+
+	status.CheckedAt = d.now()
+	status.CircuitState = d.GetCircuitState()
+	status.VerboseLogging = d.VerboseLoggingState()
+	if d.healthEvents > 0 {
+		status.Events = d.Events(d.healthEvents)
+	}
+	db, err := d.usablePool()
+	if err != nil {
+		status.Error = err.Error()
+		return status, err
+	}
+
+	start := d.now()
+	if err = db.PingContext(ctx); err != nil {
+		status.Error = err.Error()
+		return status, fmt.Errorf("health check failed: %w", err)
+	}
+
+	status.Connected = true
+	status.Latency = d.now().Sub(start)
+
+	// Report which of the listed hosts answered; a failure here does not fail the check
+	// 列挙されたホストのどれが応答したかを報告する、ここでの失敗はチェックを失敗させない
+	if addr, addrErr := queryServerAddr(ctx, db); addrErr == nil {
+		status.ServerAddr = addr
+	}
+
+	// The pool still works, but it will stop once the current credential lease expires
+	// プールはまだ動作するが、現在の認証情報のリースが失効すると使えなくなる
+	if refreshErr := d.credentialRefreshError(); refreshErr != nil {
+		err = fmt.Errorf("credential refresh failed: %w", refreshErr) // refresh: 更新
+		status.Error = err.Error()
+		return status, err
+	}
+	return status, nil
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context" // context: コンテキスト
+	"testing" // testing: テスト機能
+	"time"    // time: 時間操作機能
+)
+
+// TestIsOpen tests that IsOpen reflects pool assignment without pinging
+// TestIsOpen: IsOpenがpingせずにプールの割り当て状態を返すことをテストする関数
+func TestIsOpen(t *testing.T) {
+	captureLog(t)
+	driver := newFakeConnectingDriver(t)
+	if driver.IsOpen() {
+		t.Error("Expected IsOpen to be false before Connect")
+	}
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected no error on Connect, got: %v", err)
+	}
+
+	// Make any ping fail so a true result proves no round trip happened
+	// pingを失敗させ、trueなら通信していないことを確認する
+	driver.GetDB().Close()
+	if !driver.IsOpen() {
+		t.Error("Expected IsOpen to be true after Connect")
+	}
+	if driver.IsConnected() {
+		t.Error("Expected IsConnected to be false for a closed pool")
+	}
+}
+
+// TestIsConnectedTimeout tests that a wedged ping gives up after the ping timeout
+// TestIsConnectedTimeout: 応答しないpingがタイムアウトで打ち切られることをテストする関数
+// wedged: 詰まった
+func TestIsConnectedTimeout(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	driver.pingTimeout = 50 * time.Millisecond
+	fake.ping = func(ctx context.Context) error {
+		<-ctx.Done() // never answers: 応答しない
+		return ctx.Err()
+	}
+
+	start := time.Now()
+	if driver.IsConnected() {
+		t.Error("Expected IsConnected to be false when ping hangs")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected IsConnected to give up near the timeout, took: %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if driver.IsConnectedContext(ctx) {
+		t.Error("Expected IsConnectedContext to be false for a cancelled context")
+	}
+}
+
+// TestIsConnectedUnroutableAddress tests the timeout path against an address that never answers
+// TestIsConnectedUnroutableAddress: 応答しないアドレスに対するタイムアウトをテストする関数
+// unroutable: 到達不能な
+func TestIsConnectedUnroutableAddress(t *testing.T) {
+	driver, err := NewPostgreSQLDriverWithConfig(&DatabaseConfig{
+		Host:     "192.0.2.1", // TEST-NET-1 (RFC 5737): 到達不能なドキュメント用アドレス
+		Port:     5432,
+		User:     "testuser",
+		Password: "testpass",
+		Database: "testdb",
+		SSLMode:  "disable",
+	}, WithPingTimeout(200*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	// sql.Open does not dial, so the pool can be assigned without a reachable server
+	// sql.Openは接続しないため、到達可能なサーバーなしでプールを割り当てられる
+	db, err := openPostgres(driver.config.Load().BuildConnectionString())
+	if err != nil {
+		t.Fatalf("Failed to open pool: %v", err)
+	}
+	driver.db = db
+	defer driver.Close()
+
+	start := time.Now()
+	if driver.IsConnected() {
+		t.Error("Expected IsConnected to be false for an unroutable address")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected IsConnected to give up near the 200ms timeout, took: %s", elapsed)
+	}
+}
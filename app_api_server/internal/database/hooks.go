@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"     // context: コンテキスト、処理の文脈情報
+	"fmt"         // fmt: format（フォーマット）、文字列フォーマット機能
+	"sync/atomic" // atomic: アトミック操作、ロックなしのカウンター
+	"time"        // time: 時間操作機能
+)
+
+// Operation names passed to statement hooks; they double as the span names
+// 文のフックに渡す操作名、スパン名としても使う
+const (
+	OpQuery       = "db.Query"       // rows-returning statement: 行を返す文
+	OpQueryRow    = "db.QueryRow"    // single-row query: 1行のクエリ
+	OpExec        = "db.Exec"        // statement without rows: 行を返さない文
+	OpTransaction = "db.Transaction" // a whole WithTransaction call, query is empty: WithTransaction全体、queryは空
+)
+
+// StatementHook observes every statement run through the driver helpers
+// StatementHook: ドライバーのヘルパー経由で実行される全ての文を観測するインターフェース
+// observes: 観測する
+// Before may return a derived context (for example carrying a span) that is used for the statement and passed to After;
+// returning nil keeps ctx. Hooks cannot change the statement's result or error.
+// Beforeは文の実行とAfterに使われる派生コンテキスト（スパンを持つものなど）を返せる、nilを返すとctxのまま。
+// フックは文の結果やエラーを変更できない
+type StatementHook interface {
+	Before(ctx context.Context, op, query string, args []interface{}) context.Context
+	After(ctx context.Context, op, query string, duration time.Duration, err error)
+}
+
+// WithStatementHooks registers hooks that run, in the given order, around every statement
+// WithStatementHooks: 全ての文の前後で指定順に実行されるフックを登録するオプション
+// A panicking hook is recovered and logged, and the remaining hooks still run
+// パニックしたフックは回復してログに出力し、残りのフックは引き続き実行する
+func WithStatementHooks(hooks ...StatementHook) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		for _, hook := range hooks {
+			if hook != nil {
+				d.statementHooks = append(d.statementHooks, hook)
+			}
+		}
+	}
+}
+
+// beforeStatement starts the built-in span and runs the registered hooks' Before
+// beforeStatement: 組み込みのスパンを開始し、登録されたフックのBeforeを実行する関数
+func (d *PostgreSQLDriver) beforeStatement(ctx context.Context, op, query string, args []interface{}) context.Context {
+	ctx = TracingHook{Tracer: d.tracer, Database: d.config.Load().Database}.Before(ctx, op, query, args)
+	for _, hook := range d.statementHooks {
+		ctx = d.callBefore(hook, ctx, op, query, args)
+	}
+	return ctx
+}
+
+// afterStatement checks for a server restart, runs the built-in query logging, ends the span and runs the registered hooks' After
+// afterStatement: サーバーの再起動を確認し、組み込みのクエリログを実行し、スパンを終了し、登録されたフックのAfterを実行する関数
+func (d *PostgreSQLDriver) afterStatement(ctx context.Context, op, query string, duration time.Duration, err error) {
+	d.detectServerRestart(err)
+	if op != OpTransaction {
+		d.observeQuery(ctx, op, query, duration, err)
+	}
+	TracingHook{Tracer: d.tracer}.After(ctx, op, query, duration, err)
+	for _, hook := range d.statementHooks {
+		d.callAfter(hook, ctx, op, query, duration, err)
+	}
+}
+
+// observeQuery counts a finished statement, warns when it exceeded the slow query threshold and logs it during a verbose window
+// observeQuery: 完了した文を数え、しきい値を超えた場合に警告を出し、詳細ログ期間中はログ出力する関数
+// exceeded: 超えた
+func (d *PostgreSQLDriver) observeQuery(ctx context.Context, op, query string, duration time.Duration, err error) {
+	atomic.AddInt64(&d.queryStats.total, 1)
+
+	slow := SlowQueryHook{Threshold: d.config.Load().SlowQueryThreshold, Logger: d.logger}
+	if slow.exceeded(duration) {
+		atomic.AddInt64(&d.queryStats.slow, 1)
+		slow.After(ctx, op, query, duration, err)
+	}
+
+	// Verbose logging costs a clock read only while a window has been set; a slow statement gets both lines
+	// 詳細ログは期限が設定されている間のみ時刻を取得する、遅い文は両方の行を出力する
+	if atomic.LoadInt64(&d.verboseUntil) != 0 {
+		if active, _ := d.VerboseQueryLogging(); active {
+			QueryLogHook{Logger: d.logger}.After(ctx, op, query, duration, err)
+		}
+	}
+}
+
+// callBefore runs hook.Before, keeping ctx when the hook returns nil or panics
+// callBefore: hook.Beforeを実行する関数、フックがnilを返すかパニックした場合はctxのまま
+func (d *PostgreSQLDriver) callBefore(hook StatementHook, ctx context.Context, op, query string, args []interface{}) (next context.Context) {
+	defer func() {
+		if p := recover(); p != nil {
+			d.logger.Warn(fmt.Sprintf("statement hook %T panicked in Before: %v", hook, p), "hook", fmt.Sprintf("%T", hook), "panic", fmt.Sprint(p))
+			next = ctx
+		}
+	}()
+	if next = hook.Before(ctx, op, query, args); next == nil {
+		next = ctx
+	}
+	return next
+}
+
+// callAfter runs hook.After, recovering a panic
+// callAfter: hook.Afterを実行し、パニックを回復する関数
+func (d *PostgreSQLDriver) callAfter(hook StatementHook, ctx context.Context, op, query string, duration time.Duration, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			d.logger.Warn(fmt.Sprintf("statement hook %T panicked in After: %v", hook, p), "hook", fmt.Sprintf("%T", hook), "panic", fmt.Sprint(p))
+		}
+	}()
+	hook.After(ctx, op, query, duration, err)
+}
+
+// SlowQueryHook warns about statements that ran for at least Threshold
+// SlowQueryHook: Threshold以上かかった文を警告するフック
+// A zero Threshold disables the warning: Thresholdが0の場合は警告しない
+type SlowQueryHook struct {
+	Threshold time.Duration // threshold: しきい値
+	Logger    Logger        // logger: ログの出力先、nilの場合は標準ロガー
+}
+
+// Before implements StatementHook
+func (h SlowQueryHook) Before(ctx context.Context, op, query string, args []interface{}) context.Context {
+	return ctx
+}
+
+// After implements StatementHook
+func (h SlowQueryHook) After(ctx context.Context, op, query string, duration time.Duration, err error) {
+	if h.exceeded(duration) {
+		location := callerLocation()
+		loggerOrDefault(h.Logger).Warn(fmt.Sprintf("slow query took %s (threshold %s) at %s: %s", duration, h.Threshold, location, truncateQuery(query)),
+			"op", op, "duration", duration, "threshold", h.Threshold, "caller", location, "query", truncateQuery(query))
+	}
+}
+
+// exceeded reports whether duration reached the threshold
+// exceeded: durationがしきい値に達したかどうかを判定する関数
+func (h SlowQueryHook) exceeded(duration time.Duration) bool {
+	return h.Threshold > 0 && duration >= h.Threshold
+}
+
+// QueryLogHook logs every statement with its duration and the calling location
+// QueryLogHook: 全ての文を所要時間と呼び出し位置とともにログ出力するフック
+// Arguments are never logged: 引数はログに出力しない
+type QueryLogHook struct {
+	Logger Logger // logger: ログの出力先、nilの場合は標準ロガー
+}
+
+// Before implements StatementHook
+func (h QueryLogHook) Before(ctx context.Context, op, query string, args []interface{}) context.Context {
+	return ctx
+}
+
+// After implements StatementHook
+func (h QueryLogHook) After(ctx context.Context, op, query string, duration time.Duration, err error) {
+	location := callerLocation()
+	if op == OpTransaction {
+		loggerOrDefault(h.Logger).Info(fmt.Sprintf("Transaction took %s at %s", duration, location), "op", op, "duration", duration, "caller", location)
+		return
+	}
+	loggerOrDefault(h.Logger).Info(fmt.Sprintf("Query took %s at %s: %s", duration, location, truncateQuery(query)),
+		"op", op, "duration", duration, "caller", location, "query", truncateQuery(query))
+}
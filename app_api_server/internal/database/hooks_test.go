@@ -0,0 +1,187 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	"database/sql"                  // sql: データベース操作用パッケージ
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"reflect"                       // reflect: リフレクション、値の比較
+	"strings"                       // strings: 文字列操作
+	"sync"                          // sync: 同期処理
+	"testing"                       // testing: テスト機能
+	"time"                          // time: 時間操作機能
+)
+
+// hookEvents collects the calls made to recordingHooks
+// hookEvents: recordingHookへの呼び出しを集める構造体
+type hookEvents struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (e *hookEvents) add(event string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, event)
+}
+
+func (e *hookEvents) list() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.events...)
+}
+
+// hookMarkKey marks the context returned by a recordingHook's Before
+// hookMarkKey: recordingHookのBeforeが返したコンテキストの目印のキー
+type hookMarkKey struct{ name string }
+
+// recordingHook records "name.before op" and "name.after op" and checks that After sees its own context
+// recordingHook: 「name.before op」「name.after op」を記録し、Afterが自身のコンテキストを受け取ることを確認するフック
+type recordingHook struct {
+	name   string
+	events *hookEvents
+	errs   []error // errs: Afterが受け取ったエラー
+}
+
+func (h *recordingHook) Before(ctx context.Context, op, query string, args []interface{}) context.Context {
+	h.events.add(h.name + ".before " + op)
+	return context.WithValue(ctx, hookMarkKey{h.name}, true)
+}
+
+func (h *recordingHook) After(ctx context.Context, op, query string, duration time.Duration, err error) {
+	if ctx.Value(hookMarkKey{h.name}) == nil {
+		h.events.add(h.name + ".after without its context")
+	}
+	h.events.add(h.name + ".after " + op)
+	h.errs = append(h.errs, err)
+}
+
+// panickingHook panics in Before or After
+// panickingHook: BeforeまたはAfterでパニックするフック
+type panickingHook struct {
+	inBefore bool // in before: Beforeでパニックする、falseならAfterでパニックする
+}
+
+func (h panickingHook) Before(ctx context.Context, op, query string, args []interface{}) context.Context {
+	if h.inBefore {
+		panic("before went wrong")
+	}
+	return nil // nil keeps the context: nilはコンテキストを維持する
+}
+
+func (h panickingHook) After(ctx context.Context, op, query string, duration time.Duration, err error) {
+	if !h.inBefore {
+		panic("after went wrong")
+	}
+}
+
+// TestStatementHooksOrder tests that hooks run in registration order around statements and transactions
+// TestStatementHooksOrder: フックが文とトランザクションの前後で登録順に実行されることをテストする関数
+func TestStatementHooksOrder(t *testing.T) {
+	driver, _ := newTestDriver(t)
+	events := &hookEvents{}
+	WithStatementHooks(&recordingHook{name: "a", events: events}, nil, &recordingHook{name: "b", events: events})(driver)
+	ctx := context.Background()
+
+	err := driver.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := driver.ExecContext(ctx, "UPDATE t SET x = 1")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, err := driver.QueryContext(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []string{
+		"a.before db.Transaction", "b.before db.Transaction",
+		"a.before db.Exec", "b.before db.Exec", "a.after db.Exec", "b.after db.Exec",
+		"a.after db.Transaction", "b.after db.Transaction",
+		"a.before db.Query", "b.before db.Query", "a.after db.Query", "b.after db.Query",
+	}
+	if got := events.list(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected events %q, got: %q", expected, got)
+	}
+}
+
+// TestStatementHooksSeeErrors tests that After receives the statement's error and the caller still gets it
+// TestStatementHooksSeeErrors: Afterが文のエラーを受け取り、呼び出し元にも同じエラーが返ることをテストする関数
+func TestStatementHooksSeeErrors(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	failure := errors.New("relation does not exist")
+	fake.exec = func(string, []sqldriver.NamedValue) (sqldriver.Result, error) { return nil, failure }
+	hook := &recordingHook{name: "a", events: &hookEvents{}}
+	WithStatementHooks(hook)(driver)
+
+	if _, err := driver.ExecContext(context.Background(), "DELETE FROM missing"); !errors.Is(err, failure) {
+		t.Errorf("Expected the statement error, got: %v", err)
+	}
+	if len(hook.errs) != 1 || !errors.Is(hook.errs[0], failure) {
+		t.Errorf("Expected After to see the statement error, got: %v", hook.errs)
+	}
+}
+
+// TestStatementHookPanicIsolation tests that a panicking hook is logged and neither the statement nor other hooks are affected
+// TestStatementHookPanicIsolation: パニックしたフックがログに出力され、文と他のフックに影響しないことをテストする関数
+func TestStatementHookPanicIsolation(t *testing.T) {
+	testCases := []struct {
+		name         string
+		hook         panickingHook
+		errorContent string
+	}{
+		{name: "Before", hook: panickingHook{inBefore: true}, errorContent: "panicked in Before: before went wrong"},
+		{name: "After", hook: panickingHook{}, errorContent: "panicked in After: after went wrong"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, fake := newTestDriver(t)
+			fake.exec = func(string, []sqldriver.NamedValue) (sqldriver.Result, error) { return sqldriver.RowsAffected(3), nil }
+			events := &hookEvents{}
+			WithStatementHooks(&recordingHook{name: "a", events: events}, tc.hook, &recordingHook{name: "b", events: events})(driver)
+			logs := captureLog(t)
+
+			result, err := driver.ExecContext(context.Background(), "UPDATE t SET x = 1")
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if affected, _ := result.RowsAffected(); affected != 3 {
+				t.Errorf("Expected 3 rows affected, got: %d", affected)
+			}
+			if !strings.Contains(logs.String(), tc.errorContent) {
+				t.Errorf("Expected log containing '%s', got: %s", tc.errorContent, logs.String())
+			}
+			expected := []string{"a.before db.Exec", "b.before db.Exec", "a.after db.Exec", "b.after db.Exec"}
+			if got := events.list(); !reflect.DeepEqual(got, expected) {
+				t.Errorf("Expected events %q, got: %q", expected, got)
+			}
+		})
+	}
+}
+
+// BenchmarkExecContextNoHooks measures the statement path without registered hooks
+// BenchmarkExecContextNoHooks: フック未登録時の文の実行経路を計測するベンチマーク
+func BenchmarkExecContextNoHooks(b *testing.B) {
+	driver, err := NewPostgreSQLDriverWithConfig(&DatabaseConfig{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "testuser",
+		Password: "testpass",
+		Database: "testdb",
+		SSLMode:  "disable",
+	})
+	if err != nil {
+		b.Fatalf("Failed to create driver: %v", err)
+	}
+	_, db := newFakeDB()
+	driver.db = db
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := driver.ExecContext(ctx, "UPDATE t SET x = 1"); err != nil {
+			b.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+}
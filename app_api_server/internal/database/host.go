@@ -0,0 +1,56 @@
+package database
+
+import (
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"net"     // net: ネットワーク、IPアドレスの解析
+	"strconv" // strconv: string conversion（文字列変換）、ポート番号の解析
+	"strings" // strings: 文字列操作
+)
+
+// ipv6Literal returns the bare address when host is an IPv6 literal, with or without brackets
+// ipv6Literal: hostがIPv6リテラル（角括弧の有無を問わない）の場合に括弧を除いたアドレスを返す関数
+// literal: リテラル、bare: 装飾のない
+func ipv6Literal(host string) (string, bool) {
+	bare := strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if net.ParseIP(bare) == nil || !strings.Contains(bare, ":") {
+		return "", false
+	}
+	return bare, true
+}
+
+// formatHost renders the host for a key/value connection string
+// formatHost: キー/値形式の接続文字列用にホストを整形する関数
+// renders: 整形する
+// libpq takes IPv6 addresses without brackets; quoting keeps the colons unambiguous
+// libpqはIPv6アドレスを角括弧なしで受け付ける、コロンが曖昧にならないよう引用符で囲む
+func formatHost(host string) string {
+	if bare, ok := ipv6Literal(host); ok {
+		return "'" + bare + "'"
+	}
+	return host
+}
+
+// validateHost rejects hosts that cannot be dialed, such as a host:port pasted into DB_HOST
+// validateHost: DB_HOSTに貼り付けられたhost:portなど、接続できないホストを拒否する関数
+// pasted: 貼り付けられた
+func validateHost(host string) error {
+	if _, ok := ipv6Literal(host); ok {
+		return nil
+	}
+
+	if strings.ContainsAny(host, ":[]") {
+		// A parsable port means the value is host:port rather than an address
+		// ポートを解析できる場合は、アドレスではなくhost:portである
+		if bareHost, port, err := net.SplitHostPort(host); err == nil {
+			if _, err := strconv.Atoi(port); err == nil {
+				return fmt.Errorf("host %q includes a port; set the host to %q and the port (DB_PORT) to %s", host, bareHost, port)
+			}
+		}
+		return fmt.Errorf("invalid host %q: not a hostname, IPv4 address or IPv6 address", host)
+	}
+
+	if strings.ContainsAny(host, " \t'\\") {
+		return fmt.Errorf("invalid host %q: contains whitespace or quote characters", host) // whitespace: 空白
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"strings" // strings: 文字列操作
+	"testing" // testing: テスト機能
+)
+
+// TestIPv6ConnectionString tests that IPv6 literals are emitted in the form libpq expects
+// TestIPv6ConnectionString: IPv6リテラルがlibpqの期待する形式で出力されることをテストする関数
+func TestIPv6ConnectionString(t *testing.T) {
+	testCases := []struct {
+		name     string
+		host     string
+		expected string // expected: 期待するhost=の値
+	}{
+		{name: "Loopback", host: "::1", expected: "host='::1' "},
+		{name: "Full address", host: "2001:db8::5", expected: "host='2001:db8::5' "},
+		{name: "Bracketed address", host: "[2001:db8::5]", expected: "host='2001:db8::5' "},
+		{name: "IPv4-mapped address", host: "::ffff:192.0.2.1", expected: "host='::ffff:192.0.2.1' "},
+		{name: "IPv4 address is unchanged", host: "192.0.2.1", expected: "host=192.0.2.1 "},
+		{name: "Hostname is unchanged", host: "db.example.com", expected: "host=db.example.com "},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &DatabaseConfig{
+				Host:     tc.host,
+				Port:     5432,
+				User:     "user",
+				Password: "pass",
+				Database: "db",
+				SSLMode:  "disable",
+			}
+
+			if err := validateDatabaseConfig(config); err != nil {
+				t.Fatalf("Expected host '%s' to be valid, got: %v", tc.host, err)
+			}
+			if actual := config.BuildConnectionString(); !strings.HasPrefix(actual, tc.expected) {
+				t.Errorf("Expected connection string to start with %q, got: %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+// TestValidateHost tests rejection of hosts with a stray port and malformed values
+// TestValidateHost: ポート付きのホストや不正な値の拒否をテストする関数
+// stray: 紛れ込んだ、malformed: 不正な形式の
+func TestValidateHost(t *testing.T) {
+	testCases := []struct {
+		name         string
+		host         string
+		expectError  bool
+		errorContent string // error content: エラーに含まれるべき文字列
+	}{
+		{name: "Hostname", host: "localhost", expectError: false},
+		{name: "Hostname with stray port", host: "db.example.com:5432", expectError: true, errorContent: "DB_PORT"},
+		{name: "IPv4 with stray port", host: "192.0.2.1:6543", expectError: true, errorContent: `"192.0.2.1"`},
+		{name: "Bracketed IPv6 with stray port", host: "[::1]:5432", expectError: true, errorContent: `"::1"`},
+		{name: "Malformed IPv6", host: "2001:db8:::5", expectError: true, errorContent: "invalid host"},
+		{name: "Whitespace", host: "db example", expectError: true, errorContent: "whitespace"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateHost(tc.host)
+			if !tc.expectError {
+				if err != nil {
+					t.Errorf("Expected no error for test case '%s', but got: %v", tc.name, err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected error for test case '%s', but got none", tc.name)
+			}
+			if !strings.Contains(err.Error(), tc.errorContent) {
+				t.Errorf("Expected error to contain %s, got: %v", tc.errorContent, err)
+			}
+		})
+	}
+}
@@ -1,10 +1,15 @@
 package database
 
 import (
-	"context" // context: コンテキスト、処理の文脈情報
-	"os"      // os: operating system（オペレーティングシステム）
-	"testing" // testing: テスト機能
-	"time"    // time: 時間操作機能
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"os"           // os: operating system（オペレーティングシステム）
+	"slices"       // slices: スライス操作
+	"sort"         // sort: ソート
+	"strings"      // strings: 文字列操作
+	"testing"      // testing: テスト機能
+	"time"         // time: 時間操作機能
 )
 
 // TestPostgreSQLDriverIntegration tests PostgreSQL driver with actual database
@@ -26,6 +31,7 @@ func TestPostgreSQLDriverIntegration(t *testing.T) {
 		"DB_PASSWORD": "sift_password_2024",
 		"DB_NAME":     "sift_app_db",
 		"DB_SSL_MODE": "disable",
+		"DB_TIMEZONE": "Asia/Tokyo", // timezone: タイムゾーン、UTC以外で設定が反映されることを確認
 	}
 
 	// Set environment variables for test
@@ -78,6 +84,132 @@ func TestPostgreSQLDriverIntegration(t *testing.T) {
 	t.Run("TestConnectionStats", func(t *testing.T) {
 		testConnectionStatistics(t, driver)
 	})
+
+	// Test slow query detection
+	// slow: 遅い、detection: 検出
+	t.Run("TestSlowQueryDetection", func(t *testing.T) {
+		testSlowQueryDetection(t, driver)
+	})
+
+	// Test unique violation classification
+	// unique: 一意の、violation: 違反、classification: 分類
+	t.Run("TestUniqueViolation", func(t *testing.T) {
+		testUniqueViolation(t, driver)
+	})
+
+	// Test session time zone
+	// session: セッション、time zone: タイムゾーン
+	t.Run("TestSessionTimeZone", func(t *testing.T) {
+		testSessionTimeZone(t, driver)
+	})
+
+	// Test LISTEN/NOTIFY delivery
+	// delivery: 配信
+	t.Run("TestListenNotify", func(t *testing.T) {
+		testListenNotify(t, driver)
+	})
+
+	// Test credential rotation
+	// credential: 認証情報、rotation: ローテーション
+	t.Run("TestRotateCredentials", func(t *testing.T) {
+		testRotateCredentials(t, driver)
+	})
+
+	// Test admin bootstrap idempotency
+	// bootstrap: 初期構築、idempotency: 冪等性
+	t.Run("TestEnsureAdminUser", func(t *testing.T) {
+		testEnsureAdminUser(t, driver)
+	})
+
+	// Test EXPLAIN plans
+	// plan: 実行計画
+	t.Run("TestExplain", func(t *testing.T) {
+		testExplain(t, driver)
+	})
+
+	// Test schema introspection
+	// introspection: イントロスペクション、スキーマの調査
+	t.Run("TestIntrospection", func(t *testing.T) {
+		testIntrospection(t, driver)
+	})
+
+	// Test the schema export
+	// export: 出力
+	t.Run("TestExportSchema", func(t *testing.T) {
+		testExportSchema(t, driver)
+	})
+
+	// Test the idle-in-transaction session timeout
+	// idle in transaction: トランザクション内でアイドル状態
+	t.Run("TestIdleInTransactionTimeout", func(t *testing.T) {
+		testIdleInTransactionTimeout(t, driver)
+	})
+
+	// Test read-only mode
+	// read-only: 読み取り専用
+	t.Run("TestReadOnlyMode", func(t *testing.T) {
+		testReadOnlyMode(t, driver)
+	})
+
+	// Test transaction isolation levels
+	// isolation level: 分離レベル
+	t.Run("TestTransactionIsolation", func(t *testing.T) {
+		testTransactionIsolation(t, driver)
+	})
+
+	// Test streaming rows with and without a server-side cursor
+	// streaming: ストリーミング、cursor: カーソル
+	t.Run("TestStream", func(t *testing.T) {
+		testStream(t, driver)
+	})
+
+	// Test upserting the same email twice
+	// upsert: 挿入または更新
+	t.Run("TestUpsert", func(t *testing.T) {
+		testUpsert(t, driver)
+	})
+
+	// Test running a multi-statement script
+	// script: スクリプト、複数の文
+	t.Run("TestExecScript", func(t *testing.T) {
+		testExecScript(t, driver)
+	})
+
+	// Test measuring the round-trip latency
+	// latency: 応答時間
+	t.Run("TestMeasureLatency", func(t *testing.T) {
+		testMeasureLatency(t, driver)
+	})
+
+	// Test reporting the server and session details
+	// server info: サーバーの情報
+	t.Run("TestGetServerInfo", func(t *testing.T) {
+		testGetServerInfo(t, driver)
+	})
+
+	// Test that configured run-time parameters reach the server
+	// session settings: セッションの設定
+	t.Run("TestGetSessionSettings", func(t *testing.T) {
+		testGetSessionSettings(t, driver)
+	})
+}
+
+// newIntegrationDriver connects a second driver to the same database with a configuration changed by edit
+// newIntegrationDriver: editで変更した設定で同じデータベースに2つ目のドライバーを接続する関数
+func newIntegrationDriver(t *testing.T, driver *PostgreSQLDriver, edit func(*DatabaseConfig)) *PostgreSQLDriver {
+	t.Helper()
+
+	config := driver.GetConfig()
+	edit(config)
+	second, err := NewPostgreSQLDriverWithConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+	if err := second.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { second.Close() })
+	return second
 }
 
 // testBasicDatabaseOperations tests basic CRUD operations
@@ -111,14 +243,7 @@ func testBasicDatabaseOperations(t *testing.T, driver *PostgreSQLDriver) {
 
 	// Test table existence in app schema
 	// table: テーブル、existence: 存在、schema: スキーマ
-	var tableExists bool
-	query := `
-		SELECT EXISTS (
-			SELECT 1 FROM information_schema.tables 
-			WHERE table_schema = 'app' AND table_name = 'users'
-		)` // exists: 存在する、information: 情報、schema: スキーマ、where: どこで
-
-	err = db.QueryRowContext(ctx, query).Scan(&tableExists)
+	tableExists, err := driver.HasTable(ctx, "app", "users")
 	if err != nil {
 		t.Errorf("Failed to check table existence: %v", err) // check: 確認する
 		return
@@ -128,6 +253,16 @@ func testBasicDatabaseOperations(t *testing.T, driver *PostgreSQLDriver) {
 		t.Error("Expected users table to exist in app schema")
 	}
 
+	// Test schema verification, including a table that does not exist
+	// verification: 検証
+	if err := driver.EnsureSchema(ctx, "app", "users", "sessions", "application_logs"); err != nil {
+		t.Errorf("Expected app schema to be complete, got: %v", err) // complete: 完全な
+	}
+	err = driver.EnsureSchema(ctx, "app", "users", "no_such_table")
+	if !errors.Is(err, ErrMissingTables) || !strings.Contains(err.Error(), "no_such_table") || strings.Contains(err.Error(), "users") {
+		t.Errorf("Expected only no_such_table to be reported missing, got: %v", err)
+	}
+
 	// Test insert operation (if table exists)
 	// insert: 挿入、operation: 操作
 	if tableExists {
@@ -250,6 +385,513 @@ func testConnectionStatistics(t *testing.T, driver *PostgreSQLDriver) {
 	}
 }
 
+// testSlowQueryDetection tests that pg_sleep is flagged as a slow query
+// testSlowQueryDetection: pg_sleepが低速クエリとして検出されることをテストする関数
+func testSlowQueryDetection(t *testing.T, driver *PostgreSQLDriver) {
+	previous := updateTestConfig(driver, func(config *DatabaseConfig) { config.SlowQueryThreshold = 100 * time.Millisecond })
+	defer driver.config.Store(previous)
+
+	before := driver.GetQueryStats().SlowQueries
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := driver.ExecContext(ctx, "SELECT pg_sleep(0.2)"); err != nil {
+		t.Fatalf("Failed to execute pg_sleep: %v", err)
+	}
+	if _, err := driver.ExecContext(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("Failed to execute fast query: %v", err)
+	}
+
+	if slow := driver.GetQueryStats().SlowQueries - before; slow != 1 {
+		t.Errorf("Expected exactly 1 slow query, got: %d", slow)
+	}
+}
+
+// testUniqueViolation tests that a real unique index violation is classified with its constraint name
+// testUniqueViolation: 実際の一意インデックス違反が制約名付きで分類されることをテストする関数
+func testUniqueViolation(t *testing.T, driver *PostgreSQLDriver) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Use a temporary table so the test leaves no data behind
+	// データを残さないよう一時テーブルを使用する
+	err := driver.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "CREATE TEMP TABLE unique_violation_test (email TEXT CONSTRAINT unique_violation_test_email_key UNIQUE) ON COMMIT DROP"); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO unique_violation_test (email) VALUES ('dup@example.com')"); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, "INSERT INTO unique_violation_test (email) VALUES ('dup@example.com')")
+		return err
+	})
+
+	if !IsUniqueViolation(err) {
+		t.Fatalf("Expected unique violation, got: %v", err)
+	}
+	if name := ConstraintName(err); name != "unique_violation_test_email_key" {
+		t.Errorf("Expected constraint 'unique_violation_test_email_key', got: '%s'", name)
+	}
+}
+
+// testSessionTimeZone tests that the server reports the configured session time zone
+// testSessionTimeZone: サーバーが設定したセッションのタイムゾーンを報告することをテストする関数
+func testSessionTimeZone(t *testing.T, driver *PostgreSQLDriver) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var zone string
+	if err := driver.QueryRowContext(ctx, "SHOW timezone").Scan(&zone); err != nil {
+		t.Fatalf("Failed to query time zone: %v", err)
+	}
+	if zone != driver.GetTimeZone() {
+		t.Errorf("Expected session time zone '%s', got: '%s'", driver.GetTimeZone(), zone)
+	}
+}
+
+// testListenNotify tests that a NOTIFY sent through the pool reaches a listener on its own connection
+// testListenNotify: プール経由のNOTIFYが専用接続のリスナーに届くことをテストする関数
+func testListenNotify(t *testing.T, driver *PostgreSQLDriver) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	notifications, err := driver.Listen(ctx, "integration_test_channel")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	// LISTEN completes asynchronously, so keep notifying until one arrives
+	// LISTENは非同期に完了するため、受信するまで通知を繰り返す
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case n := <-notifications:
+			if n.Channel != "integration_test_channel" || n.Payload != "hello" {
+				t.Errorf("Expected integration_test_channel/hello, got: %+v", n)
+			}
+			return
+		case <-ticker.C:
+			if _, err := driver.ExecContext(ctx, "SELECT pg_notify('integration_test_channel', 'hello')"); err != nil {
+				t.Fatalf("Failed to notify: %v", err)
+			}
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for notification")
+		}
+	}
+}
+
+// testRotateCredentials tests rotating to a throwaway role while queries keep running
+// testRotateCredentials: クエリを実行し続けながら使い捨てのロールへローテーションする処理をテストする関数
+// throwaway: 使い捨ての
+func testRotateCredentials(t *testing.T, driver *PostgreSQLDriver) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	original := driver.GetConfig()
+	role := "rotation_test_role"
+	if _, err := driver.ExecContext(ctx, "CREATE ROLE "+role+" LOGIN PASSWORD 'rotation_pass_2024'"); err != nil {
+		t.Fatalf("Failed to create role: %v", err)
+	}
+	defer func() {
+		if _, err := driver.ExecContext(context.Background(), "DROP ROLE IF EXISTS "+role); err != nil {
+			t.Errorf("Failed to drop role: %v", err)
+		}
+	}()
+	if _, err := driver.ExecContext(ctx, "GRANT CONNECT ON DATABASE "+original.Database+" TO "+role); err != nil {
+		t.Fatalf("Failed to grant connect: %v", err)
+	}
+
+	// Keep querying from another goroutine across the swap
+	// 入れ替えをまたいで別のゴルーチンからクエリを実行し続ける
+	stop := make(chan struct{})
+	failures := make(chan error, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			var one int
+			if err := driver.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+				select {
+				case failures <- err:
+				default:
+				}
+				return
+			}
+		}
+	}()
+
+	if err := driver.RotateCredentials(ctx, role, "rotation_pass_2024"); err != nil {
+		t.Fatalf("Failed to rotate credentials: %v", err)
+	}
+	var current string
+	if err := driver.QueryRowContext(ctx, "SELECT current_user").Scan(&current); err != nil {
+		t.Fatalf("Failed to query current user: %v", err)
+	}
+	if current != role {
+		t.Errorf("Expected current user '%s', got: '%s'", role, current)
+	}
+
+	// Rotate back so the role can be dropped and later tests run as before
+	// ロールを削除し、後続のテストが従来どおり動くよう元に戻す
+	if err := driver.RotateCredentials(ctx, original.User, original.Password); err != nil {
+		t.Fatalf("Failed to rotate back: %v", err)
+	}
+
+	close(stop)
+	<-done
+	select {
+	case err := <-failures:
+		t.Errorf("Expected queries to keep succeeding across the swap, got: %v", err)
+	default:
+	}
+}
+
+// testEnsureAdminUser tests that running the admin bootstrap twice leaves exactly one row
+// testEnsureAdminUser: 管理者の初期構築を2回実行しても行が1つだけであることをテストする関数
+func testEnsureAdminUser(t *testing.T, driver *PostgreSQLDriver) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	config := AdminBootstrapConfig{
+		Email:     "bootstrap-admin-" + time.Now().Format("20060102150405") + "@test.com",
+		Password:  "bootstrap_password",
+		FirstName: "Bootstrap",
+		LastName:  "Admin",
+	}
+	defer driver.ExecContext(context.Background(), "DELETE FROM app.users WHERE email = $1", config.Email)
+
+	for i := 0; i < 2; i++ {
+		if err := EnsureAdminUser(ctx, driver, config); err != nil {
+			t.Fatalf("Expected no error on run %d, got: %v", i+1, err)
+		}
+	}
+
+	var count int
+	if err := driver.QueryRowContext(ctx, "SELECT COUNT(*) FROM app.users WHERE email = $1", config.Email).Scan(&count); err != nil {
+		t.Fatalf("Failed to count admin users: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected exactly one admin row, got: %d", count)
+	}
+}
+
+// testExplain tests that real EXPLAIN output parses into a plan tree
+// testExplain: 実際のEXPLAIN出力が実行計画ツリーとして解析されることをテストする関数
+func testExplain(t *testing.T, driver *PostgreSQLDriver) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := driver.Explain(ctx, "SELECT id, email FROM app.users WHERE email = $1", "nobody@test.com")
+	if err != nil {
+		t.Fatalf("Failed to explain query: %v", err)
+	}
+	if result.Plan.NodeType == "" || result.TotalCost <= 0 {
+		t.Errorf("Expected a root node with a positive cost, got: %+v", result.Plan)
+	}
+
+	analyzed, err := driver.ExplainAnalyze(ctx, ExplainOptions{}, "SELECT count(*) FROM app.users")
+	if err != nil {
+		t.Fatalf("Failed to explain analyze query: %v", err)
+	}
+	if !analyzed.Analyzed || analyzed.ExecutionTime <= 0 || analyzed.Plan.ActualLoops < 1 {
+		t.Errorf("Expected actual timings from ANALYZE, got: %+v", analyzed)
+	}
+
+	if _, err := driver.ExplainAnalyze(ctx, ExplainOptions{}, "DELETE FROM app.users WHERE FALSE"); !errors.Is(err, ErrExplainAnalyzeWrite) {
+		t.Errorf("Expected ErrExplainAnalyzeWrite, got: %v", err)
+	}
+}
+
+// testIntrospection tests the listed shape of app.users created by scripts/postgres/init.sql
+// testIntrospection: scripts/postgres/init.sqlで作成されるapp.usersの構造が列挙されることをテストする関数
+func testIntrospection(t *testing.T, driver *PostgreSQLDriver) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tables, err := driver.ListTables(ctx, "app")
+	if err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+	var names []string
+	for _, table := range tables {
+		names = append(names, table.Name)
+	}
+	if !sort.StringsAreSorted(names) || !slices.Contains(names, "users") || !slices.Contains(names, "sessions") {
+		t.Errorf("Expected sorted tables including users and sessions, got: %v", names)
+	}
+
+	columns, err := driver.ListColumns(ctx, "app", "users")
+	if err != nil {
+		t.Fatalf("Failed to list columns: %v", err)
+	}
+	var columnNames []string
+	for _, column := range columns {
+		columnNames = append(columnNames, column.Name)
+	}
+	expected := []string{"id", "email", "password_hash", "first_name", "last_name", "is_active", "is_verified", "created_at", "updated_at"}
+	if !slices.Equal(columnNames, expected) {
+		t.Errorf("Expected columns %v, got: %v", expected, columnNames)
+	}
+	if len(columns) > 1 && (columns[1].DataType != "character varying" || columns[1].Nullable) {
+		t.Errorf("Expected email to be a non-null varchar, got: %+v", columns[1])
+	}
+
+	indexes, err := driver.ListIndexes(ctx, "app", "users")
+	if err != nil {
+		t.Fatalf("Failed to list indexes: %v", err)
+	}
+	var indexNames []string
+	for _, index := range indexes {
+		indexNames = append(indexNames, index.Name)
+	}
+	for _, name := range []string{"idx_users_active", "idx_users_email", "users_email_key", "users_pkey"} {
+		if !slices.Contains(indexNames, name) {
+			t.Errorf("Expected index %s, got: %v", name, indexNames)
+		}
+	}
+	if !sort.StringsAreSorted(indexNames) {
+		t.Errorf("Expected indexes ordered by name, got: %v", indexNames)
+	}
+}
+
+// testExportSchema tests that the exported app schema contains the users table with its columns, twice alike
+// testExportSchema: 出力したappスキーマがusersテーブルとそのカラムを含み、2回の出力が同一であることをテストする関数
+func testExportSchema(t *testing.T, driver *PostgreSQLDriver) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var first, second strings.Builder
+	if err := driver.ExportSchema(ctx, "app", &first); err != nil {
+		t.Fatalf("Failed to export schema: %v", err)
+	}
+	if err := driver.ExportSchema(ctx, "app", &second); err != nil {
+		t.Fatalf("Failed to export schema again: %v", err)
+	}
+	dump := first.String()
+	if dump != second.String() {
+		t.Errorf("Expected identical dumps, got:\n%s\nand:\n%s", dump, second.String())
+	}
+
+	for _, expected := range []string{
+		"CREATE TABLE app.users (",
+		"    id uuid DEFAULT uuid_generate_v4() NOT NULL,",
+		"    email character varying(255) NOT NULL,",
+		"    is_active boolean DEFAULT true,",
+		"    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP\n);",
+		"ALTER TABLE ONLY app.users ADD CONSTRAINT users_pkey PRIMARY KEY (id);",
+		"ALTER TABLE ONLY app.users ADD CONSTRAINT users_email_key UNIQUE (email);",
+		"CREATE INDEX idx_users_email ON app.users USING btree (email);",
+	} {
+		if !strings.Contains(dump, expected) {
+			t.Errorf("Expected the dump to contain %q, got:\n%s", expected, dump)
+		}
+	}
+}
+
+// testIdleInTransactionTimeout tests that the server terminates a session left idle inside a transaction
+// testIdleInTransactionTimeout: トランザクション内でアイドル状態のセッションをサーバーが終了させることをテストする関数
+func testIdleInTransactionTimeout(t *testing.T, driver *PostgreSQLDriver) {
+	idle := newIntegrationDriver(t, driver, func(c *DatabaseConfig) { c.IdleInTransactionTimeout = 500 * time.Millisecond })
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var timeout string
+	if err := idle.QueryRowContext(ctx, "SHOW idle_in_transaction_session_timeout").Scan(&timeout); err != nil {
+		t.Fatalf("Failed to query the timeout: %v", err)
+	}
+	if timeout != "500ms" {
+		t.Errorf("Expected idle_in_transaction_session_timeout 500ms, got: %s", timeout)
+	}
+
+	tx, err := idle.GetDB().BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("Failed to run the first statement: %v", err)
+	}
+
+	time.Sleep(1500 * time.Millisecond) // past the timeout: タイムアウトを過ぎるまで待つ
+
+	if _, err := tx.ExecContext(ctx, "SELECT 1"); err == nil {
+		t.Error("Expected the server to have terminated the idle transaction")
+	}
+}
+
+// testReadOnlyMode tests that a read-only driver can SELECT but every write fails with a read-only violation
+// testReadOnlyMode: 読み取り専用のドライバーでSELECTはでき、書き込みは読み取り専用違反で失敗することをテストする関数
+func testReadOnlyMode(t *testing.T, driver *PostgreSQLDriver) {
+	readOnly := newIntegrationDriver(t, driver, func(c *DatabaseConfig) { c.ReadOnly = true })
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var count int
+	if err := readOnly.QueryRowContext(ctx, "SELECT COUNT(*) FROM app.users").Scan(&count); err != nil {
+		t.Fatalf("Expected SELECT to succeed, got: %v", err)
+	}
+
+	insert := "INSERT INTO app.users (email, password_hash, first_name, last_name) VALUES ('readonly@example.com', 'x', 'Read', 'Only')"
+	_, err := readOnly.ExecContext(ctx, insert)
+	if !IsReadOnlyViolation(err) {
+		t.Errorf("Expected a read-only violation, got: %v", err)
+	}
+
+	err = readOnly.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, insert)
+		return err
+	})
+	if !IsReadOnlyViolation(err) {
+		t.Errorf("Expected a read-only violation inside WithTransaction, got: %v", err)
+	}
+}
+
+// testTransactionIsolation tests that SHOW transaction_isolation reflects the requested level
+// testTransactionIsolation: SHOW transaction_isolationが要求した分離レベルを反映することをテストする関数
+func testTransactionIsolation(t *testing.T, driver *PostgreSQLDriver) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	testCases := []struct {
+		name     string
+		opts     []TxOption
+		expected string
+	}{
+		{name: "Default", expected: "read committed"},
+		{name: "Repeatable read", opts: []TxOption{TxIsolation(sql.LevelRepeatableRead)}, expected: "repeatable read"},
+		{name: "Serializable", opts: []TxOption{TxIsolation(sql.LevelSerializable)}, expected: "serializable"},
+	}
+
+	for _, tc := range testCases {
+		var level string
+		err := driver.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+			return tx.QueryRowContext(ctx, "SHOW transaction_isolation").Scan(&level)
+		}, tc.opts...)
+		if err != nil {
+			t.Fatalf("%s: failed to query the isolation level: %v", tc.name, err)
+		}
+		if level != tc.expected {
+			t.Errorf("%s: expected isolation '%s', got: '%s'", tc.name, tc.expected, level)
+		}
+	}
+
+	var level string
+	err := driver.WithSerializableRetry(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		return tx.QueryRowContext(ctx, "SHOW transaction_isolation").Scan(&level)
+	})
+	if err != nil || level != "serializable" {
+		t.Errorf("Expected WithSerializableRetry to default to serializable, got: '%s' (%v)", level, err)
+	}
+}
+
+// testStream tests that Stream visits every row of generate_series, with bind parameters in the cursor query
+// testStream: Streamがgenerate_seriesのすべての行を処理し、カーソルのクエリでもバインドパラメーターが使えることをテストする関数
+func testStream(t *testing.T, driver *PostgreSQLDriver) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, batchSize := range []int{0, 7} {
+		var sum, calls int64
+		err := driver.Stream(ctx, "SELECT n FROM generate_series(1, $1::int) AS n", []interface{}{100}, func(rows *sql.Rows) error {
+			var n int64
+			if err := rows.Scan(&n); err != nil {
+				return err
+			}
+			sum += n
+			calls++
+			return nil
+		}, StreamBatchSize(batchSize))
+		if err != nil {
+			t.Fatalf("batch size %d: failed to stream rows: %v", batchSize, err)
+		}
+		if calls != 100 || sum != 5050 {
+			t.Errorf("batch size %d: expected 100 rows summing to 5050, got: %d rows summing to %d", batchSize, calls, sum)
+		}
+	}
+}
+
+// testUpsert tests that upserting the same email twice leaves one row with the updated fields
+// testUpsert: 同じメールアドレスを2回upsertすると、更新された値を持つ1行だけが残ることをテストする関数
+func testUpsert(t *testing.T, driver *PostgreSQLDriver) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	const email = "upsert@test.com"
+	defer driver.ExecContext(context.Background(), "DELETE FROM app.users WHERE email = $1", email)
+
+	columns := []string{"email", "password_hash", "first_name", "last_name"}
+	opts := UpsertOptions{ConflictColumns: []string{"email"}, UpdateColumns: []string{"first_name", "last_name"}, Returning: []string{"id"}}
+
+	var firstID, secondID string
+	if _, err := driver.Upsert(ctx, "app.users", columns, []interface{}{email, "hash", "First", "Name"}, opts, &firstID); err != nil {
+		t.Fatalf("Failed to insert user: %v", err)
+	}
+	if _, err := driver.Upsert(ctx, "app.users", columns, []interface{}{email, "other-hash", "Updated", "User"}, opts, &secondID); err != nil {
+		t.Fatalf("Failed to update user: %v", err)
+	}
+	if firstID != secondID {
+		t.Errorf("Expected the same row to be updated, got ids %s and %s", firstID, secondID)
+	}
+
+	var count int
+	var firstName, lastName, passwordHash string
+	err := driver.QueryRowContext(ctx, "SELECT COUNT(*) OVER (), first_name, last_name, password_hash FROM app.users WHERE email = $1", email).Scan(&count, &firstName, &lastName, &passwordHash)
+	if err != nil {
+		t.Fatalf("Failed to read user: %v", err)
+	}
+	if count != 1 || firstName != "Updated" || lastName != "User" || passwordHash != "hash" {
+		t.Errorf("Expected one row named Updated User with the original hash, got: %d rows, %s %s, %s", count, firstName, lastName, passwordHash)
+	}
+
+	affected, err := driver.Upsert(ctx, "app.users", columns, []interface{}{email, "hash", "Ignored", "Ignored"}, UpsertOptions{ConflictColumns: []string{"email"}, DoNothing: true})
+	if err != nil || affected != 0 {
+		t.Errorf("Expected DO NOTHING to skip the existing row, got: %d rows (%v)", affected, err)
+	}
+}
+
+// testExecScript tests a script with a function body, and that a failing script leaves nothing behind
+// testExecScript: 関数本体を含むスクリプトと、失敗したスクリプトが何も残さないことをテストする関数
+func testExecScript(t *testing.T, driver *PostgreSQLDriver) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	defer driver.ExecScript(context.Background(), "DROP TABLE IF EXISTS script_test; DROP FUNCTION IF EXISTS script_test_double(int);")
+
+	script := `
+-- seed; with a function
+CREATE TABLE script_test (n int);
+CREATE FUNCTION script_test_double(n int) RETURNS int AS $$
+BEGIN
+  RETURN n * 2; -- semicolons inside the body
+END;
+$$ LANGUAGE plpgsql;
+INSERT INTO script_test VALUES (script_test_double(21)), (length('a;b'));
+`
+	if err := driver.ExecScript(ctx, script); err != nil {
+		t.Fatalf("Failed to run script: %v", err)
+	}
+	var sum int
+	if err := driver.QueryRowContext(ctx, "SELECT sum(n) FROM script_test").Scan(&sum); err != nil || sum != 45 {
+		t.Errorf("Expected the script to insert 42 and 3, got sum %d (%v)", sum, err)
+	}
+
+	err := driver.ExecScript(ctx, "INSERT INTO script_test VALUES (1);\nINSERT INTO no_such_table VALUES (1);")
+	var scriptErr *ScriptError
+	if !errors.As(err, &scriptErr) || scriptErr.Number != 2 || scriptErr.Line != 2 {
+		t.Fatalf("Expected statement 2 on line 2 to fail, got: %v", err)
+	}
+	var count int
+	if err := driver.QueryRowContext(ctx, "SELECT count(*) FROM script_test").Scan(&count); err != nil || count != 2 {
+		t.Errorf("Expected the failed script to roll back, got %d rows (%v)", count, err)
+	}
+}
+
 // TestDriverWithDockerCompose tests driver integration with Docker Compose setup
 // TestDriverWithDockerCompose: Docker Compose設定でのドライバー統合をテストする関数
 func TestDriverWithDockerCompose(t *testing.T) {
@@ -287,21 +929,16 @@ func TestDriverWithDockerCompose(t *testing.T) {
 		t.Fatalf("Failed to create driver for Docker Compose test: %v", err)
 	}
 
-	// Test connection with retry logic for Docker Compose startup
-	// retry: 再試行、logic: ロジック、startup: 起動
-	var connected bool
-	maxRetries := 10 // maximum: 最大、retries: 再試行（複数形）
-	for i := 0; i < maxRetries; i++ {
-		if err := driver.Connect(); err == nil {
-			connected = true
-			break
-		}
-		t.Logf("Connection attempt %d failed, retrying...", i+1) // attempt: 試行、retrying: 再試行
-		time.Sleep(2 * time.Second)
+	// Wait for PostgreSQL to finish starting under Docker Compose
+	// Docker ComposeでPostgreSQLの起動が完了するまで待つ
+	// startup: 起動
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer waitCancel()
+	if err := WaitForDatabase(waitCtx, driver.GetConfig(), WaitOptions{InitialBackoff: time.Second, MaxBackoff: 2 * time.Second}); err != nil {
+		t.Fatalf("PostgreSQL in Docker Compose did not become ready: %v", err)
 	}
-
-	if !connected {
-		t.Fatalf("Failed to connect to PostgreSQL in Docker Compose after %d attempts", maxRetries) // attempts: 試行（複数形）
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Failed to connect to PostgreSQL in Docker Compose: %v", err)
 	}
 
 	defer driver.Close()
@@ -328,3 +965,67 @@ func TestDriverWithDockerCompose(t *testing.T) {
 
 	t.Log("Docker Compose integration test completed successfully") // completed: 完了した、successfully: 成功して
 }
+
+// testMeasureLatency tests that every sample succeeds and the figures are ordered
+// testMeasureLatency: すべてのサンプルが成功し、集計値の大小関係が正しいことをテストする関数
+func testMeasureLatency(t *testing.T, driver *PostgreSQLDriver) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	report, err := driver.MeasureLatency(ctx, 5)
+	if err != nil {
+		t.Fatalf("Failed to measure latency: %v", err)
+	}
+	if len(report.Samples) != 5 || report.Succeeded != 5 {
+		t.Errorf("Expected 5 successful samples, got: %d of %d", report.Succeeded, len(report.Samples))
+	}
+	if report.Min <= 0 || report.Min > report.Avg || report.Avg > report.Max || report.P95 > report.Max {
+		t.Errorf("Expected 0 < min <= avg <= max and p95 <= max, got: %+v", report)
+	}
+}
+
+// testGetServerInfo tests that the reported details match the configuration
+// testGetServerInfo: 報告された情報が設定と一致することをテストする関数
+func testGetServerInfo(t *testing.T, driver *PostgreSQLDriver) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	info, err := driver.GetServerInfo(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get server info: %v", err)
+	}
+	config := driver.GetConfig()
+	if info.CurrentDatabase != config.Database || info.CurrentUser != config.User {
+		t.Errorf("Expected database %s as %s, got: %+v", config.Database, config.User, info)
+	}
+	if info.ServerVersion == "" || info.BackendPID <= 0 {
+		t.Errorf("Expected a server version and backend PID, got: %+v", info)
+	}
+	if config.SSLMode == "disable" && info.SSL {
+		t.Errorf("Expected no SSL with sslmode=disable, got: %+v", info)
+	}
+}
+
+// testGetSessionSettings tests that the configured search_path and time zone round-trip through the server
+// testGetSessionSettings: 設定したsearch_pathとタイムゾーンがサーバーで有効になっていることをテストする関数
+func testGetSessionSettings(t *testing.T, driver *PostgreSQLDriver) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	configured := newIntegrationDriver(t, driver, func(config *DatabaseConfig) {
+		config.TimeZone = "Asia/Tokyo"
+		config.Options = map[string]string{"search_path": "app, public"}
+	})
+
+	settings, err := configured.GetSessionSettings(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get session settings: %v", err)
+	}
+	if settings["timezone"] != "Asia/Tokyo" || settings["search_path"] != "app, public" {
+		t.Errorf("Expected timezone Asia/Tokyo and search_path \"app, public\", got: %v", settings)
+	}
+
+	if _, err := configured.GetSessionSettings(ctx, "TimeZone", "no_such_setting"); err == nil || !strings.Contains(err.Error(), "no_such_setting") {
+		t.Errorf("Expected the unknown setting in the error, got: %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+
+	"github.com/lib/pq" // pq: PostgreSQLドライバー、配列型
+)
+
+// TableInfo describes a table or view in a schema
+// TableInfo: スキーマ内のテーブルまたはビューを表す構造体
+type TableInfo struct {
+	Schema        string `json:"schema"`         // schema: スキーマ名
+	Name          string `json:"name"`           // name: テーブル名
+	Kind          string `json:"kind"`           // kind: 種類（table、view、materialized view、partitioned table、foreign table）
+	EstimatedRows int64  `json:"estimated_rows"` // estimated rows: 統計上の推定行数、-1は未解析
+}
+
+// ColumnInfo describes a column of a table
+// ColumnInfo: テーブルのカラムを表す構造体
+type ColumnInfo struct {
+	Name     string  `json:"name"`              // name: カラム名
+	Position int     `json:"position"`          // position: テーブル内の位置（1から）
+	DataType string  `json:"data_type"`         // data type: データ型
+	Nullable bool    `json:"nullable"`          // nullable: NULLを許可するか
+	Default  *string `json:"default,omitempty"` // default: デフォルト式、nilはデフォルトなし
+}
+
+// IndexInfo describes an index of a table
+// IndexInfo: テーブルのインデックスを表す構造体
+type IndexInfo struct {
+	Name       string   `json:"name"`       // name: インデックス名
+	Columns    []string `json:"columns"`    // columns: 対象カラム、式インデックスの式は含まない
+	Unique     bool     `json:"unique"`     // unique: 一意インデックスか
+	Primary    bool     `json:"primary"`    // primary: 主キーか
+	Definition string   `json:"definition"` // definition: CREATE INDEX文
+}
+
+// listTablesQuery lists the relations of schema $1 by name
+// listTablesQuery: スキーマ$1のリレーションを名前順に列挙するクエリ
+const listTablesQuery = `
+	SELECT c.relname,
+		CASE c.relkind
+			WHEN 'r' THEN 'table'
+			WHEN 'p' THEN 'partitioned table'
+			WHEN 'v' THEN 'view'
+			WHEN 'm' THEN 'materialized view'
+			WHEN 'f' THEN 'foreign table'
+		END,
+		c.reltuples::bigint
+	FROM pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = $1 AND c.relkind IN ('r', 'p', 'v', 'm', 'f')
+	ORDER BY c.relname`
+
+// listColumnsQuery lists the columns of table $1.$2 in table order
+// listColumnsQuery: テーブル$1.$2のカラムをテーブル内の順序で列挙するクエリ
+const listColumnsQuery = `
+	SELECT column_name, ordinal_position, data_type, is_nullable = 'YES', column_default
+	FROM information_schema.columns
+	WHERE table_schema = $1 AND table_name = $2
+	ORDER BY ordinal_position`
+
+// listIndexesQuery lists the indexes of table $1.$2 by name, with their key columns in key order
+// listIndexesQuery: テーブル$1.$2のインデックスを名前順に、キーカラムをキー順に列挙するクエリ
+const listIndexesQuery = `
+	SELECT i.relname,
+		ARRAY(
+			SELECT a.attname
+			FROM unnest(ix.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+			JOIN pg_catalog.pg_attribute a ON a.attrelid = ix.indrelid AND a.attnum = k.attnum
+			ORDER BY k.ord
+		),
+		ix.indisunique,
+		ix.indisprimary,
+		pg_catalog.pg_get_indexdef(ix.indexrelid)
+	FROM pg_catalog.pg_index ix
+	JOIN pg_catalog.pg_class i ON i.oid = ix.indexrelid
+	JOIN pg_catalog.pg_class t ON t.oid = ix.indrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+	WHERE n.nspname = $1 AND t.relname = $2
+	ORDER BY i.relname`
+
+// ListTables returns the tables and views of schema ordered by name
+// ListTables: スキーマのテーブルとビューを名前順に返す関数
+func (d *PostgreSQLDriver) ListTables(ctx context.Context, schema string) ([]TableInfo, error) {
+	tables := []TableInfo{}
+	err := d.introspect(ctx, listTablesQuery, []interface{}{schema}, func(rows *sql.Rows) error {
+		table := TableInfo{Schema: schema}
+		if err := rows.Scan(&table.Name, &table.Kind, &table.EstimatedRows); err != nil {
+			return err
+		}
+		tables = append(tables, table)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables in schema %s: %w", schema, err)
+	}
+	return tables, nil
+}
+
+// ListColumns returns the columns of schema.table in table order; an unknown table has no columns
+// ListColumns: schema.tableのカラムをテーブル内の順序で返す関数、存在しないテーブルはカラムなし
+func (d *PostgreSQLDriver) ListColumns(ctx context.Context, schema, table string) ([]ColumnInfo, error) {
+	columns := []ColumnInfo{}
+	err := d.introspect(ctx, listColumnsQuery, []interface{}{schema, table}, func(rows *sql.Rows) error {
+		var column ColumnInfo
+		var columnDefault sql.NullString
+		if err := rows.Scan(&column.Name, &column.Position, &column.DataType, &column.Nullable, &columnDefault); err != nil {
+			return err
+		}
+		if columnDefault.Valid {
+			column.Default = &columnDefault.String
+		}
+		columns = append(columns, column)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list columns of %s.%s: %w", schema, table, err)
+	}
+	return columns, nil
+}
+
+// ListIndexes returns the indexes of schema.table ordered by name
+// ListIndexes: schema.tableのインデックスを名前順に返す関数
+func (d *PostgreSQLDriver) ListIndexes(ctx context.Context, schema, table string) ([]IndexInfo, error) {
+	indexes := []IndexInfo{}
+	err := d.introspect(ctx, listIndexesQuery, []interface{}{schema, table}, func(rows *sql.Rows) error {
+		var index IndexInfo
+		var columns pq.StringArray
+		if err := rows.Scan(&index.Name, &columns, &index.Unique, &index.Primary, &index.Definition); err != nil {
+			return err
+		}
+		index.Columns = columns
+		indexes = append(indexes, index)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes of %s.%s: %w", schema, table, err)
+	}
+	return indexes, nil
+}
+
+// introspect runs a catalog query bounded by schemaCheckTimeout and passes every row to scan
+// introspect: schemaCheckTimeoutで制限したカタログクエリを実行し、各行をscanに渡す内部関数
+// catalog: システムカタログ
+func (d *PostgreSQLDriver) introspect(ctx context.Context, query string, args []interface{}, scan func(*sql.Rows) error) error {
+	ctx, cancel := context.WithTimeout(ctx, schemaCheckTimeout)
+	defer cancel()
+
+	rows, err := d.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows.Rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
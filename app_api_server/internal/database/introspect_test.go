@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"reflect"                       // reflect: 値の比較
+	"testing"                       // testing: テスト機能
+	"time"                          // time: 時間操作機能
+)
+
+// newIntrospectTestDriver returns a driver whose queries answer with rows and record their arguments
+// newIntrospectTestDriver: クエリがrowsを返し、引数を記録するドライバーを作成する関数
+func newIntrospectTestDriver(t *testing.T, rows *fakeRows) (*PostgreSQLDriver, *[]sqldriver.NamedValue) {
+	t.Helper()
+
+	driver, fake := newTestDriver(t)
+	var gotArgs []sqldriver.NamedValue
+	fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		gotArgs = args
+		return rows, nil
+	}
+	return driver, &gotArgs
+}
+
+// TestListTables tests that tables are scanned with their schema and kind
+// TestListTables: テーブルがスキーマと種類と共に読み取られることをテストする関数
+func TestListTables(t *testing.T) {
+	driver, gotArgs := newIntrospectTestDriver(t, &fakeRows{
+		columns: []string{"relname", "kind", "reltuples"},
+		values: [][]sqldriver.Value{
+			{"sessions", "table", int64(-1)},
+			{"users", "table", int64(42)},
+		},
+	})
+
+	tables, err := driver.ListTables(context.Background(), "app")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	expected := []TableInfo{
+		{Schema: "app", Name: "sessions", Kind: "table", EstimatedRows: -1},
+		{Schema: "app", Name: "users", Kind: "table", EstimatedRows: 42},
+	}
+	if !reflect.DeepEqual(tables, expected) {
+		t.Errorf("Expected %+v, got: %+v", expected, tables)
+	}
+	if len(*gotArgs) != 1 || (*gotArgs)[0].Value != "app" {
+		t.Errorf("Expected the schema as the only argument, got: %v", *gotArgs)
+	}
+}
+
+// TestListColumns tests nullability and the optional default of columns
+// TestListColumns: カラムのNULL許可と省略可能なデフォルトをテストする関数
+func TestListColumns(t *testing.T) {
+	driver, gotArgs := newIntrospectTestDriver(t, &fakeRows{
+		columns: []string{"column_name", "ordinal_position", "data_type", "nullable", "column_default"},
+		values: [][]sqldriver.Value{
+			{"id", int64(1), "uuid", false, "uuid_generate_v4()"},
+			{"first_name", int64(4), "character varying", true, nil},
+		},
+	})
+
+	columns, err := driver.ListColumns(context.Background(), "app", "users")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(columns) != 2 {
+		t.Fatalf("Expected 2 columns, got: %+v", columns)
+	}
+	if columns[0].Name != "id" || columns[0].Nullable || columns[0].Default == nil || *columns[0].Default != "uuid_generate_v4()" {
+		t.Errorf("Expected a non-null id with a default, got: %+v", columns[0])
+	}
+	if columns[1].Position != 4 || !columns[1].Nullable || columns[1].Default != nil {
+		t.Errorf("Expected a nullable first_name without a default, got: %+v", columns[1])
+	}
+	if len(*gotArgs) != 2 || (*gotArgs)[0].Value != "app" || (*gotArgs)[1].Value != "users" {
+		t.Errorf("Expected schema and table arguments, got: %v", *gotArgs)
+	}
+}
+
+// TestListIndexes tests that index key columns are decoded from the array
+// TestListIndexes: インデックスのキーカラムが配列からデコードされることをテストする関数
+func TestListIndexes(t *testing.T) {
+	driver, _ := newIntrospectTestDriver(t, &fakeRows{
+		columns: []string{"relname", "columns", "indisunique", "indisprimary", "definition"},
+		values: [][]sqldriver.Value{
+			{"idx_users_email", "{email}", false, false, "CREATE INDEX idx_users_email ON app.users USING btree (email)"},
+			{"users_pkey", "{id}", true, true, "CREATE UNIQUE INDEX users_pkey ON app.users USING btree (id)"},
+		},
+	})
+
+	indexes, err := driver.ListIndexes(context.Background(), "app", "users")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(indexes) != 2 {
+		t.Fatalf("Expected 2 indexes, got: %+v", indexes)
+	}
+	if !reflect.DeepEqual(indexes[0].Columns, []string{"email"}) || indexes[0].Unique {
+		t.Errorf("Expected a non-unique index on email, got: %+v", indexes[0])
+	}
+	if !indexes[1].Primary || !indexes[1].Unique {
+		t.Errorf("Expected the primary key, got: %+v", indexes[1])
+	}
+}
+
+// TestIntrospectionBoundedContext tests that the catalog queries run under a deadline and report failures
+// TestIntrospectionBoundedContext: カタログクエリが期限付きで実行され、失敗を報告することをテストする関数
+func TestIntrospectionBoundedContext(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	var deadline time.Time
+	fake.observe = func(ctx context.Context) {
+		deadline, _ = ctx.Deadline()
+	}
+	fake.query = func(string, []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		return nil, errors.New("permission denied")
+	}
+
+	_, err := driver.ListTables(context.Background(), "app")
+	if err == nil {
+		t.Error("Expected error but got none")
+	}
+	if deadline.IsZero() || time.Until(deadline) > schemaCheckTimeout {
+		t.Errorf("Expected a deadline within %s, got: %v", schemaCheckTimeout, deadline)
+	}
+}
@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"             // context: コンテキスト、処理の文脈情報
+	"database/sql"        // sql: データベース操作用パッケージ
+	"database/sql/driver" // driver: SQLドライバーインターフェース
+	"fmt"                 // fmt: format（フォーマット）、文字列フォーマット機能
+	"math/rand/v2"        // rand: 乱数、接続ごとの寿命のゆらぎ
+	"time"                // time: 時間操作機能
+)
+
+// sql.DB takes a single ConnMaxLifetime for the whole pool, so connections opened together at startup
+// all expire in the same cleaner pass. Changing that one value over time does not help: connections of
+// the same age still cross it together. Instead the pool is opened through jitterConnector, which gives
+// every connection its own deadline; past it the connection reports itself invalid and database/sql
+// discards it the next time it is reused or returned. ConnMaxLifetime on the sql.DB is raised to the
+// upper bound of the window as a backstop for connections that sit idle.
+// sql.DBはプール全体で1つのConnMaxLifetimeしか持たないため、起動時にまとめて開いた接続は同じ掃除のタイミングで
+// 一斉に期限切れになる。その値を時間とともに変えても、同じ年齢の接続は同時に値を超えるため効果がない。
+// そこでjitterConnector経由でプールを開き、接続ごとに期限を持たせる。期限を過ぎた接続は自身を無効と報告し、
+// database/sqlが次の再利用時または返却時に破棄する。sql.DBのConnMaxLifetimeは、アイドルのままの接続への
+// 保険として期間の上限に設定する
+
+// poolConnMaxLifetime returns the lifetime set on the sql.DB: the upper bound of the jitter window, capped for aws-iam
+// poolConnMaxLifetime: sql.DBに設定する寿命を返す関数、ゆらぎの期間の上限（aws-iamの場合は上限を設ける）
+func poolConnMaxLifetime(config *DatabaseConfig) time.Duration {
+	lifetime := config.ConnMaxLifetime
+	if config.ConnMaxLifetimeJitter > 0 {
+		lifetime += time.Duration(float64(lifetime) * config.ConnMaxLifetimeJitter)
+	}
+	return connMaxLifetime(config, lifetime)
+}
+
+// jitteredLifetime picks a lifetime in ConnMaxLifetime ± ConnMaxLifetimeJitter for one connection
+// jitteredLifetime: 1つの接続の寿命をConnMaxLifetime ± ConnMaxLifetimeJitterの範囲から選ぶ関数
+// r is a uniform random number in [0, 1): rは[0, 1)の一様乱数
+func jitteredLifetime(config *DatabaseConfig, r float64) time.Duration {
+	scale := 1 + config.ConnMaxLifetimeJitter*(2*r-1)
+	return connMaxLifetime(config, time.Duration(float64(config.ConnMaxLifetime)*scale))
+}
+
+// connectionLifetime draws a lifetime for a new connection from the active configuration
+// connectionLifetime: 使用中の設定から新しい接続の寿命を選ぶ関数
+// SetPoolConfig can change ConnMaxLifetime at run time, so it is read on every connect
+// SetPoolConfigが実行時にConnMaxLifetimeを変更しうるため、接続のたびに読み取る
+func (d *PostgreSQLDriver) connectionLifetime() time.Duration {
+	return jitteredLifetime(d.config.Load(), rand.Float64())
+}
+
+// openConnector returns a connector for connectionString on drv
+// openConnector: drv上でconnectionStringに接続するコネクターを返す関数
+func openConnector(drv driver.Driver, connectionString string) (driver.Connector, error) {
+	if withConnector, ok := drv.(driver.DriverContext); ok {
+		return withConnector.OpenConnector(connectionString)
+	}
+	// lib/pq has no OpenConnector; database/sql wraps such drivers the same way
+	// lib/pqはOpenConnectorを持たない、database/sqlもこのようなドライバーを同じ方法で包む
+	return dsnConnector{dsn: connectionString, driver: drv}, nil
+}
+
+// withLifetimeJitter wraps connector so every connection it opens gets its own deadline
+// withLifetimeJitter: connectorを包み、開く接続ごとに期限を持たせる関数
+func (d *PostgreSQLDriver) withLifetimeJitter(connector driver.Connector) driver.Connector {
+	return &jitterConnector{connector: connector, lifetime: d.connectionLifetime, now: d.now}
+}
+
+// dsnConnector adapts a driver without a connector to driver.Connector
+// dsnConnector: コネクターを持たないドライバーをdriver.Connectorに適合させる型
+type dsnConnector struct {
+	dsn    string        // dsn: 接続文字列
+	driver driver.Driver // driver: ドライバー
+}
+
+// Connect implements driver.Connector
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open(c.dsn) }
+
+// Driver implements driver.Connector
+func (c dsnConnector) Driver() driver.Driver { return c.driver }
+
+// jitterConnector gives every connection it opens a deadline drawn by lifetime
+// jitterConnector: 開く接続ごとにlifetimeで選んだ期限を与えるコネクター
+type jitterConnector struct {
+	connector driver.Connector     // connector: 元のコネクター
+	lifetime  func() time.Duration // lifetime: 接続ごとの寿命を選ぶ関数
+	now       func() time.Time     // now: 現在時刻を返す関数
+}
+
+// Connect implements driver.Connector
+func (c *jitterConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &jitterConn{Conn: conn, deadline: c.now().Add(c.lifetime()), now: c.now}, nil
+}
+
+// Driver implements driver.Connector
+func (c *jitterConnector) Driver() driver.Driver { return c.connector.Driver() }
+
+// jitterConn forwards to the wrapped connection and reports itself invalid after its deadline
+// jitterConn: 包んだ接続へ処理を転送し、期限を過ぎると自身を無効と報告する接続
+type jitterConn struct {
+	driver.Conn                  // conn: 元の接続
+	deadline    time.Time        // deadline: この接続の期限
+	now         func() time.Time // now: 現在時刻を返す関数
+}
+
+// expired reports whether the connection outlived its deadline
+// expired: 接続が期限を過ぎたかどうかを判定する関数
+func (c *jitterConn) expired() bool {
+	return !c.now().Before(c.deadline)
+}
+
+// IsValid implements driver.Validator; database/sql discards an invalid connection when it is returned
+// IsValid: driver.Validatorの実装、database/sqlは返却時に無効な接続を破棄する
+func (c *jitterConn) IsValid() bool {
+	if c.expired() {
+		return false
+	}
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// ResetSession implements driver.SessionResetter; an expired connection is discarded before reuse
+// ResetSession: driver.SessionResetterの実装、期限切れの接続は再利用の前に破棄される
+func (c *jitterConn) ResetSession(ctx context.Context) error {
+	if c.expired() {
+		return driver.ErrBadConn
+	}
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+// QueryContext implements driver.QueryerContext
+func (c *jitterConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if queryer, ok := c.Conn.(driver.QueryerContext); ok {
+		return queryer.QueryContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip // database/sql falls back to a prepared statement: database/sqlはプリペアドステートメントで代替する
+}
+
+// ExecContext implements driver.ExecerContext
+func (c *jitterConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if execer, ok := c.Conn.(driver.ExecerContext); ok {
+		return execer.ExecContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+// PrepareContext implements driver.ConnPrepareContext
+func (c *jitterConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+// BeginTx implements driver.ConnBeginTx
+func (c *jitterConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) || opts.ReadOnly {
+		return nil, fmt.Errorf("driver does not support transaction options")
+	}
+	return c.Conn.Begin() //nolint:staticcheck // the only way to begin on a driver without BeginTx: BeginTxを持たないドライバーで開始する唯一の方法
+}
+
+// Ping implements driver.Pinger
+func (c *jitterConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package database
+
+import (
+	"context"      // context: コンテキスト
+	"database/sql" // sql: データベース操作用パッケージ
+	"os"           // os: 環境変数の操作
+	"sync"         // sync: 同期処理
+	"testing"      // testing: テスト機能
+	"time"         // time: 時間操作機能
+)
+
+// TestJitteredLifetime tests the bounds of the per-connection lifetime and the pool backstop
+// TestJitteredLifetime: 接続ごとの寿命の範囲とプールの保険の値をテストする関数
+func TestJitteredLifetime(t *testing.T) {
+	config := &DatabaseConfig{ConnMaxLifetime: 10 * time.Minute, ConnMaxLifetimeJitter: 0.1}
+
+	testCases := []struct {
+		r        float64       // r: 乱数
+		expected time.Duration // expected: 期待値
+	}{
+		{r: 0, expected: 9 * time.Minute},
+		{r: 0.5, expected: 10 * time.Minute},
+		{r: 0.75, expected: 10*time.Minute + 30*time.Second},
+	}
+	for _, tc := range testCases {
+		if got := jitteredLifetime(config, tc.r); got != tc.expected {
+			t.Errorf("Expected %s for r=%g, got: %s", tc.expected, tc.r, got)
+		}
+	}
+
+	if got := poolConnMaxLifetime(config); got != 11*time.Minute {
+		t.Errorf("Expected the backstop at the top of the window, got: %s", got)
+	}
+	config.ConnMaxLifetimeJitter = 0
+	if got := poolConnMaxLifetime(config); got != 10*time.Minute {
+		t.Errorf("Expected the plain lifetime without jitter, got: %s", got)
+	}
+}
+
+// TestLoadDatabaseConfigConnMaxLifetimeJitter tests DB_CONN_MAX_LIFETIME_JITTER parsing and validation
+// TestLoadDatabaseConfigConnMaxLifetimeJitter: DB_CONN_MAX_LIFETIME_JITTERの解析と検証をテストする関数
+func TestLoadDatabaseConfigConnMaxLifetimeJitter(t *testing.T) {
+	testCases := []struct {
+		name        string
+		value       string  // value: 値
+		expected    float64 // expected: 期待値
+		expectError bool
+	}{
+		{name: "Unset disables jitter", value: "", expected: 0},
+		{name: "Fraction", value: "0.1", expected: 0.1},
+		{name: "Not a number", value: "10%", expectError: true},
+		{name: "Negative", value: "-0.1", expectError: true},
+		{name: "Whole lifetime", value: "1", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			envVars := map[string]string{
+				"DB_USER":                     "user",
+				"DB_PASSWORD":                 "pass",
+				"DB_NAME":                     "db",
+				"DB_CONN_MAX_LIFETIME_JITTER": tc.value,
+			}
+			for key, value := range envVars {
+				os.Setenv(key, value)
+			}
+			defer func() {
+				for key := range envVars {
+					os.Unsetenv(key)
+				}
+			}()
+
+			config, err := LoadDatabaseConfig()
+			if err == nil {
+				err = validateDatabaseConfig(config)
+			}
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error for test case '%s', but got none", tc.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if config.ConnMaxLifetimeJitter != tc.expected {
+				t.Errorf("Expected jitter %g, got: %g", tc.expected, config.ConnMaxLifetimeJitter)
+			}
+		})
+	}
+}
+
+// TestConnMaxLifetimeJitterSpreadsExpiry opens a full pool at once and samples the pool stats while a manual
+// clock moves through the lifetime window: the connections must expire over several samples, not all together
+// TestConnMaxLifetimeJitterSpreadsExpiry: プールを一度に満たし、手動の時計で寿命の期間を進めながらプールの統計を取得する。
+// 接続は一斉にではなく、複数回の取得にわたって期限切れになる必要がある
+func TestConnMaxLifetimeJitterSpreadsExpiry(t *testing.T) {
+	const (
+		poolSize = 20
+		lifetime = time.Minute
+		jitter   = 0.2 // window 48s–72s: 期間は48秒〜72秒
+		step     = 2 * time.Second
+	)
+
+	driver := newFakeConnectingDriver(t)
+	updateTestConfig(driver, func(config *DatabaseConfig) {
+		config.MaxOpenConns = poolSize
+		config.MaxIdleConns = poolSize
+		config.ConnMaxLifetime = lifetime
+		config.ConnMaxLifetimeJitter = jitter
+	})
+
+	// Manual clock: 手動の時計
+	var clockMu sync.Mutex
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	current := start
+	driver.now = func() time.Time {
+		clockMu.Lock()
+		defer clockMu.Unlock()
+		return current
+	}
+	advance := func(d time.Duration) {
+		clockMu.Lock()
+		current = current.Add(d)
+		clockMu.Unlock()
+	}
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected no error on Connect, got: %v", err)
+	}
+	defer driver.Close()
+	db := driver.pool()
+
+	// Every connection is checked out and returned at each step, like a busy service
+	// 忙しいサービスのように、各ステップですべての接続を取得して返却する
+	acquire := func() []*sql.Conn {
+		t.Helper()
+		conns := make([]*sql.Conn, poolSize)
+		for i := range conns {
+			conn, err := db.Conn(context.Background())
+			if err != nil {
+				t.Fatalf("Expected no error acquiring a connection, got: %v", err)
+			}
+			conns[i] = conn
+		}
+		return conns
+	}
+	held := acquire()
+
+	expiredAt := map[time.Duration]int{} // expired at: 経過時間ごとの期限切れになった接続数
+	total := 0
+	for elapsed := step; elapsed <= lifetime*3/2; elapsed += step {
+		advance(step)
+		for _, conn := range held {
+			conn.Close()
+		}
+
+		// Expired connections are discarded on return; replacements open at or after 48s and outlive the window
+		// 期限切れの接続は返却時に破棄される、置き換えの接続は48秒以降に開かれ期間より長く残る
+		if expired := poolSize - db.Stats().OpenConnections; expired > 0 && elapsed <= lifetime*6/5 {
+			expiredAt[elapsed] = expired
+			total += expired
+		}
+		held = acquire()
+	}
+	for _, conn := range held {
+		conn.Close()
+	}
+
+	for elapsed := range expiredAt {
+		if elapsed < lifetime*4/5 {
+			t.Errorf("Expected no expiry before %s, got %d at %s", lifetime*4/5, expiredAt[elapsed], elapsed)
+		}
+	}
+	if total != poolSize {
+		t.Errorf("Expected all %d connections to expire by %s, got: %d (%v)", poolSize, lifetime*6/5, total, expiredAt)
+	}
+	if len(expiredAt) < 3 {
+		t.Errorf("Expected expiry spread over several samples, got: %v", expiredAt)
+	}
+	if closed := db.Stats().MaxLifetimeClosed; closed != 0 {
+		t.Errorf("Expected the jitter to close connections before the pool backstop, got %d backstop closes", closed)
+	}
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"             // context: コンテキスト、処理の文脈情報
+	"database/sql/driver" // driver: SQLドライバーインターフェース
+	"fmt"                 // fmt: format（フォーマット）、文字列フォーマット機能
+	"net"                 // net: ネットワーク、TCPのダイヤル
+	"strconv"             // strconv: string conversion（文字列変換）
+	"strings"             // strings: 文字列操作
+	"time"                // time: 時間操作機能
+
+	"github.com/lib/pq" // pq: PostgreSQLドライバー
+)
+
+// keepaliveKeys are libpq's TCP keepalive parameters, which lib/pq would otherwise send to the server as run-time parameters
+// keepaliveKeys: libpqのTCPキープアライブのパラメータ、そのままではlib/pqがrun-time parameterとしてサーバーに送信してしまう
+var keepaliveKeys = []string{"keepalives", "keepalives_idle", "keepalives_interval", "keepalives_count"}
+
+// keepaliveParameters returns the keepalive settings modeled as DatabaseConfig fields, in connection string order
+// keepaliveParameters: DatabaseConfigのフィールドとして持つキープアライブ設定を接続文字列の順に返す関数
+func (c *DatabaseConfig) keepaliveParameters() []sessionParameter {
+	var params []sessionParameter
+	if c.DisableKeepalives {
+		params = append(params, sessionParameter{"keepalives", "0"})
+	}
+	if c.KeepalivesIdle > 0 {
+		params = append(params, sessionParameter{"keepalives_idle", durationSeconds(c.KeepalivesIdle)})
+	}
+	if c.KeepalivesInterval > 0 {
+		params = append(params, sessionParameter{"keepalives_interval", durationSeconds(c.KeepalivesInterval)})
+	}
+	if c.KeepalivesCount > 0 {
+		params = append(params, sessionParameter{"keepalives_count", strconv.Itoa(c.KeepalivesCount)})
+	}
+	return params
+}
+
+// validateKeepalives checks the keepalive tuning
+// validateKeepalives: キープアライブの設定を検証する関数
+func validateKeepalives(config *DatabaseConfig) error {
+	if config.KeepalivesIdle < 0 || config.KeepalivesInterval < 0 {
+		return fmt.Errorf("keepalive idle time and interval cannot be negative")
+	}
+	if config.KeepalivesCount < 0 {
+		return fmt.Errorf("keepalive count cannot be negative")
+	}
+	if config.DisableKeepalives && (config.KeepalivesIdle > 0 || config.KeepalivesInterval > 0 || config.KeepalivesCount > 0) {
+		return fmt.Errorf("keepalive tuning cannot be combined with disabled keepalives") // combined: 組み合わせる
+	}
+	return nil
+}
+
+// keepaliveDriver opens lib/pq connections, applying the keepalive parameters of the connection string to the TCP dial
+// keepaliveDriver: 接続文字列のキープアライブのパラメータをTCPのダイヤルに適用してlib/pqの接続を開くドライバー
+// lib/pq does not implement them itself: lib/pq自体はこれらを実装していない
+type keepaliveDriver struct{}
+
+// Open implements driver.Driver
+func (keepaliveDriver) Open(connectionString string) (driver.Conn, error) {
+	connectionString, dialer, err := splitKeepalives(connectionString)
+	if err != nil {
+		return nil, err
+	}
+	return pq.DialOpen(keepaliveDialer{dialer}, connectionString)
+}
+
+// splitKeepalives removes the keepalive parameters from a key/value connection string and returns a dialer applying them
+// splitKeepalives: キー/値形式の接続文字列からキープアライブのパラメータを取り除き、それを適用するダイヤラーを返す関数
+// Without keepalive parameters the string is returned as is with lib/pq's default dialer
+// キープアライブのパラメータがない場合は文字列をそのまま、lib/pqのデフォルトと同じダイヤラーと共に返す
+func splitKeepalives(connectionString string) (string, net.Dialer, error) {
+	var dialer net.Dialer
+	if !strings.Contains(connectionString, "keepalives") {
+		return connectionString, dialer, nil
+	}
+
+	params, err := parseKeyValues(connectionString)
+	if err != nil {
+		return "", dialer, fmt.Errorf("invalid connection string: %w", err)
+	}
+
+	config := net.KeepAliveConfig{Enable: true} // zero fields keep Go's defaults: 0のフィールドはGoのデフォルト値
+	found := false
+	for _, key := range keepaliveKeys {
+		value, ok := params[key]
+		if !ok {
+			continue
+		}
+		found = true
+		delete(params, key)
+
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return "", dialer, fmt.Errorf("invalid %s: %q is not a non-negative integer", key, value)
+		}
+		switch key {
+		case "keepalives":
+			config.Enable = n != 0
+		case "keepalives_idle":
+			config.Idle = time.Duration(n) * time.Second
+		case "keepalives_interval":
+			config.Interval = time.Duration(n) * time.Second
+		case "keepalives_count":
+			config.Count = n
+		}
+	}
+	if !found {
+		return connectionString, dialer, nil
+	}
+
+	if config.Enable {
+		dialer.KeepAliveConfig = config
+	} else {
+		dialer.KeepAlive = -1 // disables the probes: プローブを無効にする
+	}
+
+	rebuilt := make([]string, 0, len(params))
+	for _, key := range sortedOptionKeys(params) {
+		rebuilt = append(rebuilt, key+"="+escapeDSNValue(params[key]))
+	}
+	return strings.Join(rebuilt, " "), dialer, nil
+}
+
+// keepaliveDialer adapts a net.Dialer to lib/pq's Dialer and DialerContext
+// keepaliveDialer: net.Dialerをlib/pqのDialerとDialerContextに適合させる型
+type keepaliveDialer struct {
+	dialer net.Dialer // dialer: キープアライブを設定したダイヤラー
+}
+
+// Dial implements pq.Dialer
+func (d keepaliveDialer) Dial(network, address string) (net.Conn, error) {
+	return d.dialer.Dial(network, address)
+}
+
+// DialTimeout implements pq.Dialer
+func (d keepaliveDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d.dialer.DialContext(ctx, network, address)
+}
+
+// DialContext implements pq.DialerContext
+func (d keepaliveDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return d.dialer.DialContext(ctx, network, address)
+}
@@ -0,0 +1,155 @@
+package database
+
+import (
+	"net"     // net: ネットワーク、キープアライブ設定
+	"strings" // strings: 文字列操作
+	"testing" // testing: テスト機能
+	"time"    // time: 時間操作機能
+)
+
+// TestKeepaliveConnectionString tests that the fallback application name and keepalive fields are emitted only when set
+// TestKeepaliveConnectionString: フォールバックのアプリケーション名とキープアライブのフィールドが設定時のみ出力されることをテストする関数
+func TestKeepaliveConnectionString(t *testing.T) {
+	base := DatabaseConfig{Host: "localhost", Port: 5432, User: "u", Password: "p", Database: "db", SSLMode: "disable"}
+
+	testCases := []struct {
+		name     string
+		modify   func(*DatabaseConfig)
+		expected string // expected: 基本の接続文字列に続く部分
+	}{
+		{name: "Defaults", modify: func(*DatabaseConfig) {}, expected: ""},
+		{name: "Fallback application name", modify: func(c *DatabaseConfig) { c.FallbackApplicationName = "sift api" }, expected: " fallback_application_name='sift api'"},
+		{name: "Tuned keepalives", modify: func(c *DatabaseConfig) {
+			c.KeepalivesIdle = 30 * time.Second
+			c.KeepalivesInterval = 1500 * time.Millisecond // rounded up: 切り上げられる
+			c.KeepalivesCount = 3
+		}, expected: " keepalives_idle=30 keepalives_interval=2 keepalives_count=3"},
+		{name: "Disabled keepalives", modify: func(c *DatabaseConfig) { c.DisableKeepalives = true }, expected: " keepalives=0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := base
+			tc.modify(&config)
+			expected := "host=localhost port=5432 user=u password=p dbname=db sslmode=disable" + tc.expected
+			if got := config.BuildConnectionString(); got != expected {
+				t.Errorf("Expected '%s', got: '%s'", expected, got)
+			}
+		})
+	}
+}
+
+// TestValidateKeepalives tests the range checks of the keepalive fields
+// TestValidateKeepalives: キープアライブのフィールドの範囲チェックをテストする関数
+func TestValidateKeepalives(t *testing.T) {
+	testCases := []struct {
+		name         string
+		config       DatabaseConfig
+		expectError  bool
+		errorContent string
+	}{
+		{name: "Defaults", config: DatabaseConfig{}},
+		{name: "Tuned", config: DatabaseConfig{KeepalivesIdle: time.Minute, KeepalivesInterval: 10 * time.Second, KeepalivesCount: 5}},
+		{name: "Negative idle", config: DatabaseConfig{KeepalivesIdle: -time.Second}, expectError: true, errorContent: "cannot be negative"},
+		{name: "Negative interval", config: DatabaseConfig{KeepalivesInterval: -time.Second}, expectError: true, errorContent: "cannot be negative"},
+		{name: "Negative count", config: DatabaseConfig{KeepalivesCount: -1}, expectError: true, errorContent: "keepalive count cannot be negative"},
+		{name: "Tuning disabled keepalives", config: DatabaseConfig{DisableKeepalives: true, KeepalivesCount: 3}, expectError: true, errorContent: "cannot be combined"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateKeepalives(&tc.config)
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestLoadDatabaseConfigKeepalives tests the keepalive and fallback application name environment variables
+// TestLoadDatabaseConfigKeepalives: キープアライブとフォールバックのアプリケーション名の環境変数をテストする関数
+func TestLoadDatabaseConfigKeepalives(t *testing.T) {
+	t.Setenv("DB_USER", "u")
+	t.Setenv("DB_PASSWORD", "p")
+	t.Setenv("DB_NAME", "db")
+	t.Setenv("DB_FALLBACK_APPLICATION_NAME", "sift-api")
+	t.Setenv("DB_KEEPALIVES_IDLE", "30s")
+	t.Setenv("DB_KEEPALIVES_INTERVAL", "10s")
+	t.Setenv("DB_KEEPALIVES_COUNT", "4")
+
+	config, err := LoadDatabaseConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if config.FallbackApplicationName != "sift-api" || config.KeepalivesIdle != 30*time.Second ||
+		config.KeepalivesInterval != 10*time.Second || config.KeepalivesCount != 4 || config.DisableKeepalives {
+		t.Errorf("Expected the keepalive settings from the environment, got: %v", config)
+	}
+
+	t.Setenv("DB_KEEPALIVES", "false")
+	if config, err = LoadDatabaseConfig(); err != nil || !config.DisableKeepalives {
+		t.Errorf("Expected keepalives to be disabled, got: %v, %v", config, err)
+	}
+
+	t.Setenv("DB_KEEPALIVES_COUNT", "many")
+	if _, err := LoadDatabaseConfig(); err == nil || !strings.Contains(err.Error(), "invalid keepalives count") {
+		t.Errorf("Expected an invalid keepalives count error, got: %v", err)
+	}
+}
+
+// TestSplitKeepalives tests that keepalive parameters become dialer settings and are not sent to the server
+// TestSplitKeepalives: キープアライブのパラメータがダイヤラーの設定になり、サーバーに送信されないことをテストする関数
+func TestSplitKeepalives(t *testing.T) {
+	testCases := []struct {
+		name              string
+		connectionString  string
+		expectedString    string
+		expectedKeepAlive time.Duration
+		expectedConfig    net.KeepAliveConfig
+		expectError       bool
+	}{
+		{
+			name:             "Without keepalives",
+			connectionString: "host=localhost password='a b' sslmode=disable",
+			expectedString:   "host=localhost password='a b' sslmode=disable",
+		},
+		{
+			name:             "Tuned",
+			connectionString: "host=localhost password='a b' keepalives_idle=30 keepalives_interval=10 keepalives_count=3",
+			expectedString:   "host=localhost password='a b'",
+			expectedConfig:   net.KeepAliveConfig{Enable: true, Idle: 30 * time.Second, Interval: 10 * time.Second, Count: 3},
+		},
+		{
+			name:              "Disabled",
+			connectionString:  "host=localhost keepalives=0",
+			expectedString:    "host=localhost",
+			expectedKeepAlive: -1,
+		},
+		{name: "Invalid", connectionString: "host=localhost keepalives_idle=soon", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			connectionString, dialer, err := splitKeepalives(tc.connectionString)
+			if tc.expectError {
+				if err == nil {
+					t.Error("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if connectionString != tc.expectedString {
+				t.Errorf("Expected '%s', got: '%s'", tc.expectedString, connectionString)
+			}
+			if dialer.KeepAlive != tc.expectedKeepAlive || dialer.KeepAliveConfig != tc.expectedConfig {
+				t.Errorf("Expected keepalive %v %+v, got: %v %+v", tc.expectedKeepAlive, tc.expectedConfig, dialer.KeepAlive, dialer.KeepAliveConfig)
+			}
+		})
+	}
+}
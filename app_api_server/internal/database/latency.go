@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"math"         // math: パーセンタイルの順位計算
+	"sort"         // sort: 応答時間の並べ替え
+	"time"         // time: 時間操作機能
+)
+
+// maxLatencySamples caps the samples of one MeasureLatency call
+// maxLatencySamples: MeasureLatencyの1回の呼び出しで取得するサンプル数の上限
+const maxLatencySamples = 100
+
+// LatencySample is the outcome of one SELECT 1 round trip
+// LatencySample: SELECT 1の1往復の結果を表す構造体
+type LatencySample struct {
+	Duration time.Duration `json:"duration_ns"`     // duration: 所要時間（ナノ秒）、失敗時は0
+	Error    string        `json:"error,omitempty"` // error: 失敗の理由（伏せ字済み）
+}
+
+// LatencyReport summarizes the samples taken by MeasureLatency
+// LatencyReport: MeasureLatencyが取得したサンプルの集計結果を表す構造体
+// The figures cover only the successful samples: 集計値は成功したサンプルのみを対象とする
+type LatencyReport struct {
+	Samples   []LatencySample `json:"samples"`   // samples: 取得順のサンプル
+	Succeeded int             `json:"succeeded"` // succeeded: 成功したサンプル数
+	Min       time.Duration   `json:"min_ns"`    // min: 最小値
+	Avg       time.Duration   `json:"avg_ns"`    // avg: average（平均値）
+	Max       time.Duration   `json:"max_ns"`    // max: 最大値
+	P95       time.Duration   `json:"p95_ns"`    // p95: 95パーセンタイル
+}
+
+// MeasureLatency runs SELECT 1 samples times and reports the min/avg/max/p95 round-trip times
+// MeasureLatency: SELECT 1をsamples回実行し、往復時間の最小・平均・最大・95パーセンタイルを報告する関数
+// Samples are spread over distinct pool connections where possible, keeping one connection free for the application
+// サンプルは可能な限り別々のプールの接続で取得し、アプリケーション用に1接続を空けておく
+// samples above maxLatencySamples are capped; when ctx ends the partial report is returned with its error
+// maxLatencySamplesを超える数は切り詰める、ctxが終了した場合は途中までの結果をエラーと共に返す
+func (d *PostgreSQLDriver) MeasureLatency(ctx context.Context, samples int) (report LatencyReport, err error) {
+	ctx, span := d.startSpan(ctx, "db.MeasureLatency", "SELECT 1")
+	defer func() { endSpan(span, err) }()
+
+	if samples <= 0 {
+		return report, fmt.Errorf("latency samples must be positive, got %d", samples) // positive: 正の
+	}
+	if samples > maxLatencySamples {
+		samples = maxLatencySamples
+	}
+	db, err := d.usablePool()
+	if err != nil {
+		return report, err
+	}
+
+	// Connections are held until the end so the next sample gets another one, up to holdLimit
+	// 次のサンプルが別の接続を使うよう、holdLimitまで接続を最後まで保持する
+	holdLimit := samples
+	if maxOpen := db.Stats().MaxOpenConnections; maxOpen > 0 && maxOpen-1 < holdLimit {
+		holdLimit = maxOpen - 1
+	}
+	var held []*sql.Conn
+	defer func() {
+		for _, conn := range held {
+			conn.Close()
+		}
+	}()
+
+	report.Samples = make([]LatencySample, 0, samples)
+	for i := 0; i < samples; i++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			report.summarize()
+			return report, fmt.Errorf("latency measurement stopped after %d of %d samples: %w", i, samples, ctxErr)
+		}
+
+		conn, sampleErr := db.Conn(ctx)
+		var sample LatencySample
+		if sampleErr == nil {
+			sample.Duration, sampleErr = d.selectOne(ctx, conn)
+			if len(held) < holdLimit {
+				held = append(held, conn)
+			} else {
+				conn.Close()
+			}
+		}
+		if sampleErr != nil {
+			sample = LatencySample{Error: d.config.Load().redactError(sampleErr).Error()}
+		}
+		report.Samples = append(report.Samples, sample)
+	}
+
+	report.summarize()
+	if report.Succeeded == 0 {
+		return report, fmt.Errorf("all %d latency samples failed: %s", samples, report.Samples[samples-1].Error)
+	}
+	return report, nil
+}
+
+// selectOne times a single SELECT 1 on conn
+// selectOne: conn上でSELECT 1を1回実行し、その時間を計測する関数
+func (d *PostgreSQLDriver) selectOne(ctx context.Context, conn *sql.Conn) (time.Duration, error) {
+	var one int
+	start := d.now()
+	if err := conn.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		return 0, err
+	}
+	return d.now().Sub(start), nil
+}
+
+// summarize fills the counts and figures of the report from its samples
+// summarize: サンプルからレポートの件数と集計値を設定する関数
+// P95 uses the nearest-rank method, so it is always one of the measured durations
+// P95は最近順位法を使うため、常に計測した値のいずれかになる
+// nearest-rank: 最近順位法
+func (r *LatencyReport) summarize() {
+	durations := make([]time.Duration, 0, len(r.Samples))
+	for _, sample := range r.Samples {
+		if sample.Error == "" {
+			durations = append(durations, sample.Duration)
+		}
+	}
+	r.Succeeded = len(durations)
+	r.Min, r.Avg, r.Max, r.P95 = 0, 0, 0, 0
+	if len(durations) == 0 {
+		return
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	var total time.Duration
+	for _, duration := range durations {
+		total += duration
+	}
+	r.Min = durations[0]
+	r.Max = durations[len(durations)-1]
+	r.Avg = total / time.Duration(len(durations))
+	rank := int(math.Ceil(0.95 * float64(len(durations))))
+	r.P95 = durations[rank-1]
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"strings"                       // strings: 文字列操作
+	"sync/atomic"                   // atomic: 呼び出し回数の数え上げ
+	"testing"                       // testing: テスト機能
+	"time"                          // time: 時間操作機能
+)
+
+// TestLatencySummarize tests the min/avg/max/p95 math over the successful samples
+// TestLatencySummarize: 成功したサンプルに対する最小・平均・最大・95パーセンタイルの計算をテストする関数
+func TestLatencySummarize(t *testing.T) {
+	ms := time.Millisecond
+	ok := func(durations ...time.Duration) []LatencySample {
+		samples := make([]LatencySample, len(durations))
+		for i, duration := range durations {
+			samples[i] = LatencySample{Duration: duration}
+		}
+		return samples
+	}
+
+	// 1ms to 20ms: the 95th percentile by nearest rank is the 19th value
+	// 1msから20ms: 最近順位法の95パーセンタイルは19番目の値
+	var twenty []time.Duration
+	for i := 20; i >= 1; i-- {
+		twenty = append(twenty, time.Duration(i)*ms)
+	}
+
+	testCases := []struct {
+		name      string
+		samples   []LatencySample
+		succeeded int
+		expected  [4]time.Duration // expected: min, avg, max, p95
+	}{
+		{name: "Single sample", samples: ok(7 * ms), succeeded: 1, expected: [4]time.Duration{7 * ms, 7 * ms, 7 * ms, 7 * ms}},
+		{name: "Unordered samples", samples: ok(3*ms, 1*ms, 2*ms), succeeded: 3, expected: [4]time.Duration{1 * ms, 2 * ms, 3 * ms, 3 * ms}},
+		{name: "Twenty samples", samples: ok(twenty...), succeeded: 20, expected: [4]time.Duration{1 * ms, 10500 * time.Microsecond, 20 * ms, 19 * ms}},
+		{name: "Failures are excluded", samples: append(ok(2*ms, 4*ms), LatencySample{Error: "timeout"}), succeeded: 2, expected: [4]time.Duration{2 * ms, 3 * ms, 4 * ms, 4 * ms}},
+		{name: "All failed", samples: []LatencySample{{Error: "timeout"}}, succeeded: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report := LatencyReport{Samples: tc.samples}
+			report.summarize()
+			got := [4]time.Duration{report.Min, report.Avg, report.Max, report.P95}
+			if report.Succeeded != tc.succeeded || got != tc.expected {
+				t.Errorf("Expected %d succeeded with %v, got: %d with %v", tc.succeeded, tc.expected, report.Succeeded, got)
+			}
+		})
+	}
+}
+
+// TestMeasureLatency tests sampling, the sample cap, per-sample errors and context expiry against the fake database
+// TestMeasureLatency: フェイクデータベースに対するサンプル取得、サンプル数の上限、サンプルごとのエラー、コンテキストの終了をテストする関数
+func TestMeasureLatency(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	driver.now = fakeClock(time.Millisecond)
+	var calls int64
+	fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		if atomic.AddInt64(&calls, 1)%2 == 0 {
+			return nil, errors.New("connection reset by peer")
+		}
+		return &fakeRows{columns: []string{"?column?"}, values: [][]sqldriver.Value{{int64(1)}}}, nil
+	}
+
+	report, err := driver.MeasureLatency(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(report.Samples) != 4 || report.Succeeded != 2 || report.Avg != time.Millisecond {
+		t.Errorf("Expected 2 of 4 samples at 1ms, got: %+v", report)
+	}
+	if report.Samples[1].Error != "connection reset by peer" {
+		t.Errorf("Expected the second sample to carry its error, got: %+v", report.Samples[1])
+	}
+	if inUse := driver.GetDB().Stats().InUse; inUse != 0 {
+		t.Errorf("Expected every connection to be released, got: %d in use", inUse)
+	}
+
+	report, _ = driver.MeasureLatency(context.Background(), 500)
+	if len(report.Samples) != maxLatencySamples {
+		t.Errorf("Expected samples to be capped at %d, got: %d", maxLatencySamples, len(report.Samples))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report, err = driver.MeasureLatency(ctx, 3)
+	if !errors.Is(err, context.Canceled) || len(report.Samples) != 0 {
+		t.Errorf("Expected a canceled error and no samples, got: %v, %+v", err, report)
+	}
+
+	fake.query = func(string, []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		return nil, errors.New("server closed the connection")
+	}
+	if _, err := driver.MeasureLatency(context.Background(), 2); err == nil || !strings.Contains(err.Error(), "all 2 latency samples failed") {
+		t.Errorf("Expected an all failed error, got: %v", err)
+	}
+	if _, err := driver.MeasureLatency(context.Background(), 0); err == nil {
+		t.Error("Expected an error for zero samples")
+	}
+}
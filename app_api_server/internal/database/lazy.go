@@ -0,0 +1,102 @@
+package database
+
+import (
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"sync"         // sync: 同期処理
+	"time"         // time: 時間操作機能
+)
+
+// lazyConnectRetryDelay is how long a failed lazy connect is reported again before the next attempt
+// lazyConnectRetryDelay: 遅延接続の失敗を次の試行まで再報告する期間
+// A burst of calls while the database is down makes one attempt instead of one each
+// データベース停止中の大量の呼び出しが、それぞれではなく1回の試行で済む
+const lazyConnectRetryDelay = time.Second
+
+// lazyConnectState holds the in-flight attempt and the cached failure of lazy connects
+// lazyConnectState: 遅延接続の実行中の試行とキャッシュされた失敗を保持する構造体
+// in-flight: 実行中の
+type lazyConnectState struct {
+	mu       sync.Mutex    // mu: mutex（相互排他ロック）、以下を保護
+	inFlight chan struct{} // in flight: 実行中の試行の完了を知らせるチャネル、nilなら試行なし
+	err      error         // err: 直近の試行の失敗
+	failedAt time.Time     // failed at: 直近の試行が失敗した時刻
+}
+
+// WithLazyConnect defers Connect until GetDB or a query helper first needs the pool
+// WithLazyConnect: GetDBやクエリヘルパーが初めてプールを必要とするまでConnectを遅らせるオプション
+// defers: 遅らせる
+// IsConnected stays false until that first connect succeeds; an explicit Connect still works
+// 最初の接続が成功するまでIsConnectedはfalseのまま、明示的なConnectも引き続き使える
+func WithLazyConnect() DriverOption {
+	return func(d *PostgreSQLDriver) {
+		d.lazyConnect = true
+	}
+}
+
+// connectedPool returns the pool, connecting first when lazy connect is enabled
+// connectedPool: プールを返す関数、遅延接続が有効な場合は先に接続する
+func (d *PostgreSQLDriver) connectedPool() (*sql.DB, error) {
+	db, err := d.usablePool()
+	if err == nil || !d.lazyConnect || errors.Is(err, ErrDriverClosed) {
+		return db, err
+	}
+	return d.lazyConnectPool()
+}
+
+// lazyConnectPool runs a single Connect for all concurrent first users
+// lazyConnectPool: 同時に初回使用する全ての呼び出し元に対して1回だけConnectを実行する関数
+// concurrent: 同時の
+// Callers that arrive during an attempt share its result, and a failure is retried after lazyConnectRetryDelay
+// 試行中に来た呼び出し元はその結果を共有し、失敗はlazyConnectRetryDelay後に再試行される
+func (d *PostgreSQLDriver) lazyConnectPool() (*sql.DB, error) {
+	state := &d.lazy
+	state.mu.Lock()
+	if db := d.pool(); db != nil {
+		state.mu.Unlock()
+		return db, nil
+	}
+	if done := state.inFlight; done != nil {
+		// Wait for the attempt already running: 既に実行中の試行を待つ
+		state.mu.Unlock()
+		<-done
+		return d.lazyResult()
+	}
+	if state.err != nil && d.now().Sub(state.failedAt) < lazyConnectRetryDelay {
+		err := state.err
+		state.mu.Unlock()
+		return nil, err
+	}
+	done := make(chan struct{})
+	state.inFlight = done
+	state.mu.Unlock()
+
+	err := d.Connect()
+
+	state.mu.Lock()
+	state.inFlight = nil
+	state.err = nil
+	if err != nil {
+		state.err = fmt.Errorf("lazy connect failed: %w", err)
+		state.failedAt = d.now()
+		d.logger.Warn(state.err.Error(), "error", state.err.Error())
+	}
+	state.mu.Unlock()
+	close(done)
+	return d.lazyResult()
+}
+
+// lazyResult reports the pool or the cached failure after an attempt finished
+// lazyResult: 試行の完了後にプールまたはキャッシュされた失敗を返す関数
+func (d *PostgreSQLDriver) lazyResult() (*sql.DB, error) {
+	if db := d.pool(); db != nil {
+		return db, nil
+	}
+	d.lazy.mu.Lock()
+	defer d.lazy.mu.Unlock()
+	if d.lazy.err != nil {
+		return nil, d.lazy.err
+	}
+	return nil, fmt.Errorf("%w: connection is not established", ErrNotConnected)
+}
@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"      // context: コンテキスト
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"sync"         // sync: 同期処理
+	"sync/atomic"  // atomic: アトミック操作
+	"testing"      // testing: テスト機能
+	"time"         // time: 時間操作機能
+)
+
+// newLazyTestDriver creates a lazily connecting driver whose pool opens are counted
+// newLazyTestDriver: プールを開いた回数を数える遅延接続のテスト用ドライバーを作成する関数
+func newLazyTestDriver(t *testing.T, open func() error) (*PostgreSQLDriver, *int64) {
+	t.Helper()
+
+	driver := newFakeConnectingDriver(t)
+	WithLazyConnect()(driver)
+
+	var opens int64
+	driver.openDB = func(string) (*sql.DB, error) {
+		atomic.AddInt64(&opens, 1)
+		time.Sleep(20 * time.Millisecond) // widen the race window: 競合の時間幅を広げる
+		if err := open(); err != nil {
+			return nil, err
+		}
+		_, db := newFakeDB()
+		return db, nil
+	}
+	t.Cleanup(func() { driver.Close() })
+	return driver, &opens
+}
+
+// TestLazyConnectSingleConnect tests that parallel first use connects exactly once
+// TestLazyConnectSingleConnect: 並行した初回使用で接続が1回だけ行われることをテストする関数
+func TestLazyConnectSingleConnect(t *testing.T) {
+	driver, opens := newLazyTestDriver(t, func() error { return nil })
+
+	if driver.IsConnected() {
+		t.Error("Expected IsConnected to be false before first use")
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				if driver.GetDB() == nil {
+					errs <- errors.New("GetDB returned nil")
+				}
+				return
+			}
+			if _, err := driver.ExecContext(context.Background(), "SELECT 1"); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if got := atomic.LoadInt64(opens); got != 1 {
+		t.Errorf("Expected exactly one connect, got: %d", got)
+	}
+	if !driver.IsConnected() {
+		t.Error("Expected IsConnected to be true after first use")
+	}
+}
+
+// TestLazyConnectFailureRetry tests that a failure is shared, cached briefly and then retried
+// TestLazyConnectFailureRetry: 失敗が共有され、短時間キャッシュされた後に再試行されることをテストする関数
+func TestLazyConnectFailureRetry(t *testing.T) {
+	var down atomic.Bool
+	down.Store(true)
+	driver, opens := newLazyTestDriver(t, func() error {
+		if down.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var clockMu sync.Mutex
+	driver.now = func() time.Time {
+		clockMu.Lock()
+		defer clockMu.Unlock()
+		return current
+	}
+
+	// Parallel callers share one failed attempt: 並行した呼び出し元は1回の失敗した試行を共有する
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := driver.QueryContext(context.Background(), "SELECT 1"); err == nil {
+				t.Error("Expected error while the database is down")
+			}
+		}()
+	}
+	wg.Wait()
+	if got := atomic.LoadInt64(opens); got != 1 {
+		t.Errorf("Expected one failed attempt, got: %d", got)
+	}
+
+	// Within the retry delay the cached error is returned: 再試行までの間はキャッシュされたエラーを返す
+	down.Store(false)
+	if err := driver.QueryRowContext(context.Background(), "SELECT 1").Err(); err == nil {
+		t.Error("Expected cached error within the retry delay")
+	}
+	if driver.GetDB() != nil {
+		t.Error("Expected GetDB to be nil within the retry delay")
+	}
+	if got := atomic.LoadInt64(opens); got != 1 {
+		t.Errorf("Expected no new attempt within the retry delay, got: %d", got)
+	}
+
+	// After the delay the next use retries and succeeds: 期間経過後の次の使用で再試行して成功する
+	clockMu.Lock()
+	current = current.Add(lazyConnectRetryDelay)
+	clockMu.Unlock()
+	if driver.GetDB() == nil {
+		t.Fatal("Expected GetDB to connect after the retry delay")
+	}
+	if got := atomic.LoadInt64(opens); got != 2 {
+		t.Errorf("Expected a second attempt, got: %d", got)
+	}
+}
+
+// TestLazyConnectExplicitConnect tests that an explicit Connect is used and not repeated
+// TestLazyConnectExplicitConnect: 明示的なConnectが使われ、繰り返されないことをテストする関数
+func TestLazyConnectExplicitConnect(t *testing.T) {
+	driver, opens := newLazyTestDriver(t, func() error { return nil })
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := driver.WithTransaction(context.Background(), func(ctx context.Context, tx *sql.Tx) error { return nil }); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if got := atomic.LoadInt64(opens); got != 1 {
+		t.Errorf("Expected only the explicit connect, got: %d", got)
+	}
+}
+
+// TestWithoutLazyConnect tests that helpers still fail fast without the option
+// TestWithoutLazyConnect: オプションなしではヘルパーが従来どおり即座に失敗することをテストする関数
+func TestWithoutLazyConnect(t *testing.T) {
+	driver := newFakeConnectingDriver(t)
+	if driver.GetDB() != nil {
+		t.Error("Expected nil DB before Connect")
+	}
+	if _, err := driver.ExecContext(context.Background(), "SELECT 1"); err == nil {
+		t.Error("Expected error before Connect")
+	}
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"fmt"    // fmt: format（フォーマット）、文字列フォーマット機能
+	"slices" // slices: スライス操作
+	"sync"   // sync: 同期処理
+	"time"   // time: 時間操作機能
+)
+
+// ConnectionInfo describes the connection passed to lifecycle hooks
+// ConnectionInfo: ライフサイクルフックに渡される接続の情報
+// lifecycle: ライフサイクル、接続から切断までの一連の流れ
+type ConnectionInfo struct {
+	Host          string    // host: 接続先ホスト
+	Database      string    // database: データベース名
+	ServerVersion string    // server version: サーバーのバージョン（"15.4"など）、取得できなければ空
+	Time          time.Time // time: イベントの発生時刻
+}
+
+// lifecycleHooks holds the registered hooks in registration order
+// lifecycleHooks: 登録順に登録されたフックを保持する構造体
+type lifecycleHooks struct {
+	mu         sync.Mutex             // mu: mutex（相互排他ロック）、以下を保護
+	connect    []func(ConnectionInfo) // connect: 接続時のフック
+	disconnect []func(ConnectionInfo) // disconnect: 切断時のフック
+	reconnect  []func(ConnectionInfo) // reconnect: 再接続時のフック
+	connected  bool                   // connected: 接続イベント後で切断イベント前か、切断の二重通知を防ぐ
+}
+
+// OnConnect registers a hook that runs after every successful connect, including reconnects
+// OnConnect: 再接続を含む全ての接続成功後に実行されるフックを登録する関数
+// Hooks run synchronously in registration order and are not called for connects that already happened
+// フックは登録順に同期的に実行され、既に行われた接続に対しては呼ばれない
+// synchronously: 同期的に
+func (d *PostgreSQLDriver) OnConnect(fn func(ConnectionInfo)) {
+	d.hooks.mu.Lock()
+	defer d.hooks.mu.Unlock()
+	d.hooks.connect = append(d.hooks.connect, fn)
+}
+
+// OnDisconnect registers a hook that runs before the pool is closed by Close or Reconnect
+// OnDisconnect: CloseまたはReconnectでプールを閉じる前に実行されるフックを登録する関数
+func (d *PostgreSQLDriver) OnDisconnect(fn func(ConnectionInfo)) {
+	d.hooks.mu.Lock()
+	defer d.hooks.mu.Unlock()
+	d.hooks.disconnect = append(d.hooks.disconnect, fn)
+}
+
+// OnReconnect registers a hook that runs after a successful Reconnect, following the connect hooks
+// OnReconnect: Reconnectの成功後、接続時のフックに続いて実行されるフックを登録する関数
+// following: 〜に続いて
+func (d *PostgreSQLDriver) OnReconnect(fn func(ConnectionInfo)) {
+	d.hooks.mu.Lock()
+	defer d.hooks.mu.Unlock()
+	d.hooks.reconnect = append(d.hooks.reconnect, fn)
+}
+
+// fireConnect runs the connect hooks for a freshly opened pool
+// fireConnect: 新しく開いたプールに対して接続時のフックを実行する関数
+func (d *PostgreSQLDriver) fireConnect() {
+	d.hooks.mu.Lock()
+	hooks := slices.Clone(d.hooks.connect)
+	d.hooks.connected = true
+	d.hooks.mu.Unlock()
+
+	if len(hooks) == 0 {
+		return
+	}
+	d.runLifecycleHooks("connect", hooks, d.connectionInfo(true))
+}
+
+// fireDisconnect runs the disconnect hooks once per connect
+// fireDisconnect: 接続ごとに1回だけ切断時のフックを実行する関数
+func (d *PostgreSQLDriver) fireDisconnect() {
+	d.hooks.mu.Lock()
+	if !d.hooks.connected {
+		d.hooks.mu.Unlock()
+		return
+	}
+	d.hooks.connected = false
+	hooks := slices.Clone(d.hooks.disconnect)
+	d.hooks.mu.Unlock()
+	d.recordEvent(EventDisconnect, "", nil)
+
+	if len(hooks) == 0 {
+		return
+	}
+	d.runLifecycleHooks("disconnect", hooks, d.connectionInfo(false))
+}
+
+// fireReconnect runs the reconnect hooks
+// fireReconnect: 再接続時のフックを実行する関数
+func (d *PostgreSQLDriver) fireReconnect() {
+	d.hooks.mu.Lock()
+	hooks := slices.Clone(d.hooks.reconnect)
+	d.hooks.mu.Unlock()
+
+	if len(hooks) == 0 {
+		return
+	}
+	d.runLifecycleHooks("reconnect", hooks, d.connectionInfo(false))
+}
+
+// connectionInfo builds the hook argument, querying the server version only when asked to
+// connectionInfo: フックの引数を作成する関数、指定された場合のみサーバーのバージョンを問い合わせる
+// A disconnect must not touch the pool being closed: 切断時は閉じようとしているプールに触れてはならない
+func (d *PostgreSQLDriver) connectionInfo(query bool) ConnectionInfo {
+	config := d.GetConfig()
+	info := ConnectionInfo{Host: config.Host, Database: config.Database, Time: d.now()}
+
+	if query {
+		version, err := d.GetServerVersion()
+		if err != nil {
+			d.logger.Warn(fmt.Sprintf("server version unavailable for lifecycle hooks: %v", err), "error", err.Error())
+		}
+		info.ServerVersion = version
+		return info
+	}
+
+	d.mu.Lock()
+	num := d.serverVersionNum
+	d.mu.Unlock()
+	if num != 0 {
+		info.ServerVersion = formatServerVersion(num)
+	}
+	return info
+}
+
+// runLifecycleHooks calls each hook in order, recovering from panics so the rest still run
+// runLifecycleHooks: 各フックを順に呼び出す関数、パニックから回復して残りのフックも実行する
+// recovering: 回復する
+func (d *PostgreSQLDriver) runLifecycleHooks(event string, hooks []func(ConnectionInfo), info ConnectionInfo) {
+	for i, hook := range hooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					d.logger.Warn(fmt.Sprintf("%s hook %d panicked: %v", event, i, r), "event", event, "hook", i, "panic", fmt.Sprint(r)) // panicked: パニックした
+				}
+			}()
+			hook(info)
+		}()
+	}
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"database/sql"                  // sql: データベース操作用パッケージ
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"reflect"                       // reflect: リフレクション、値の比較
+	"strings"                       // strings: 文字列操作
+	"testing"                       // testing: テスト機能
+	"time"                          // time: 時間操作機能
+)
+
+// newLifecycleTestDriver creates a driver whose pools report server version 15.4
+// newLifecycleTestDriver: サーバーバージョン15.4を返すプールを開くテスト用ドライバーを作成する関数
+func newLifecycleTestDriver(t *testing.T) *PostgreSQLDriver {
+	t.Helper()
+
+	driver := newFakeConnectingDriver(t)
+	driver.openDB = func(string) (*sql.DB, error) {
+		fake, db := newFakeDB()
+		fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+			return &fakeRows{columns: []string{"server_version_num"}, values: [][]sqldriver.Value{{"150004"}}}, nil
+		}
+		return db, nil
+	}
+	t.Cleanup(func() { driver.Close() })
+	return driver
+}
+
+// TestLifecycleHookOrder tests registration order and disconnect, connect, reconnect on Reconnect
+// TestLifecycleHookOrder: 登録順と、Reconnectでの切断・接続・再接続の順序をテストする関数
+func TestLifecycleHookOrder(t *testing.T) {
+	driver := newLifecycleTestDriver(t)
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	driver.now = func() time.Time { return current }
+
+	var events []string
+	var infos []ConnectionInfo
+	record := func(name string) func(ConnectionInfo) {
+		return func(info ConnectionInfo) {
+			events = append(events, name)
+			infos = append(infos, info)
+		}
+	}
+	driver.OnConnect(record("connect 1"))
+	driver.OnConnect(record("connect 2"))
+	driver.OnDisconnect(record("disconnect"))
+	driver.OnReconnect(record("reconnect"))
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := driver.Reconnect(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	driver.Close()
+	driver.Close() // a second Close must not fire again: 2回目のCloseでは再度通知しない
+
+	expected := []string{
+		"connect 1", "connect 2",
+		"disconnect", "connect 1", "connect 2", "reconnect",
+		"disconnect",
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected events %v, got: %v", expected, events)
+	}
+
+	if info := infos[0]; info.ServerVersion != "15.4" || !info.Time.Equal(current) || info.Database != "testdb" || info.Host != "localhost" {
+		t.Errorf("Expected connection info for testdb on 15.4, got: %+v", info)
+	}
+	if info := infos[len(infos)-1]; info.ServerVersion != "15.4" {
+		t.Errorf("Expected the disconnect to carry the cached server version, got: %+v", info)
+	}
+}
+
+// TestLifecycleHookPanicIsolation tests that a panicking hook does not stop the others or the driver
+// TestLifecycleHookPanicIsolation: パニックしたフックが他のフックやドライバーを止めないことをテストする関数
+func TestLifecycleHookPanicIsolation(t *testing.T) {
+	driver := newLifecycleTestDriver(t)
+	logs := captureLog(t)
+
+	var calls []int
+	driver.OnConnect(func(ConnectionInfo) { calls = append(calls, 1) })
+	driver.OnConnect(func(ConnectionInfo) { panic("boom") })
+	driver.OnConnect(func(ConnectionInfo) { calls = append(calls, 3) })
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected no error despite the panic, got: %v", err)
+	}
+	if !reflect.DeepEqual(calls, []int{1, 3}) {
+		t.Errorf("Expected hooks 1 and 3 to run, got: %v", calls)
+	}
+	if !driver.IsOpen() {
+		t.Error("Expected the driver to stay connected")
+	}
+	if !strings.Contains(logs.String(), "connect hook 1 panicked: boom") {
+		t.Errorf("Expected the panic to be logged, got: %s", logs.String())
+	}
+}
+
+// TestLifecycleHookNotRetroactive tests that hooks registered after Connect wait for the next event
+// TestLifecycleHookNotRetroactive: Connect後に登録したフックが次のイベントまで呼ばれないことをテストする関数
+// retroactive: 遡及的な
+func TestLifecycleHookNotRetroactive(t *testing.T) {
+	driver := newLifecycleTestDriver(t)
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	connects := 0
+	driver.OnConnect(func(ConnectionInfo) { connects++ })
+	if connects != 0 {
+		t.Errorf("Expected no retroactive call, got: %d", connects)
+	}
+
+	if err := driver.Reconnect(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if connects != 1 {
+		t.Errorf("Expected the hook to fire on the next connect, got: %d calls", connects)
+	}
+}
+
+// TestLifecycleHookFailedReconnect tests that a failed Reconnect fires only the disconnect hooks
+// TestLifecycleHookFailedReconnect: 失敗したReconnectでは切断時のフックのみが呼ばれることをテストする関数
+func TestLifecycleHookFailedReconnect(t *testing.T) {
+	b := newBreakerTestDriver(t, 0, time.Minute)
+	if err := b.driver.Connect(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	var events []string
+	b.driver.OnConnect(func(ConnectionInfo) { events = append(events, "connect") })
+	b.driver.OnDisconnect(func(ConnectionInfo) { events = append(events, "disconnect") })
+	b.driver.OnReconnect(func(ConnectionInfo) { events = append(events, "reconnect") })
+
+	b.down = true
+	if err := b.driver.Reconnect(); err == nil {
+		t.Fatal("Expected the reconnect to fail")
+	}
+	b.driver.Close() // already disconnected: 既に切断済み
+
+	if !reflect.DeepEqual(events, []string{"disconnect"}) {
+		t.Errorf("Expected only one disconnect, got: %v", events)
+	}
+}
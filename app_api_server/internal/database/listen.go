@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context" // context: コンテキスト、処理の文脈情報
+	"errors"  // errors: エラー操作
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"time"    // time: 時間操作機能
+
+	"github.com/lib/pq" // pq: PostgreSQLドライバー、LISTEN/NOTIFY用リスナー
+)
+
+// Listener reconnect bounds and keep-alive interval
+// リスナーの再接続間隔の範囲とキープアライブ間隔
+const (
+	listenerMinReconnectInterval = 100 * time.Millisecond // min reconnect: 最小再接続間隔
+	listenerMaxReconnectInterval = 10 * time.Second       // max reconnect: 最大再接続間隔
+	listenerPingInterval         = 90 * time.Second       // ping: 死活確認の間隔
+)
+
+// ErrNotificationsMissed is reported after the listener reconnects, since NOTIFY events sent while disconnected are lost
+// ErrNotificationsMissed: 切断中に送られたNOTIFYは失われるため、リスナー再接続後に報告されるエラー
+// missed: 取りこぼした
+var ErrNotificationsMissed = errors.New("listener reconnected, notifications may have been missed")
+
+// Notification represents a NOTIFY event received on a channel
+// Notification: チャネルで受信したNOTIFYイベントを表す構造体
+// received: 受信した
+type Notification struct {
+	Channel string // channel: チャネル名
+	Payload string // payload: 通知の内容
+}
+
+// notificationListener is the subset of pq.Listener used by Listen
+// notificationListener: Listenで使用するpq.Listenerの一部のメソッド
+// subset: 部分集合
+type notificationListener interface {
+	Listen(channel string) error
+	NotificationChannel() <-chan *pq.Notification
+	Ping() error
+	Close() error
+}
+
+// listenerFactory creates a notificationListener for a connection string
+// listenerFactory: 接続文字列からnotificationListenerを作成する関数型
+type listenerFactory func(connectionString string, callback pq.EventCallbackType) notificationListener
+
+// newPQListener creates a lib/pq listener that reconnects on its own
+// newPQListener: 自動で再接続するlib/pqのリスナーを作成する関数
+func newPQListener(connectionString string, callback pq.EventCallbackType) notificationListener {
+	return pq.NewListener(connectionString, listenerMinReconnectInterval, listenerMaxReconnectInterval, callback)
+}
+
+// WithListenerErrorHook registers a callback for listener connection problems
+// WithListenerErrorHook: リスナーの接続障害を受け取るコールバックを登録するオプション
+// Reconnects are reported as ErrNotificationsMissed so caches can be invalidated wholesale
+// 再接続はErrNotificationsMissedとして報告され、キャッシュ全体を無効化できる
+func WithListenerErrorHook(hook func(channel string, err error)) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		d.listenerErrorHook = hook
+	}
+}
+
+// Listen subscribes to a NOTIFY channel on a dedicated connection built from the driver configuration
+// Listen: ドライバー設定から構築した専用接続でNOTIFYチャネルを購読する関数
+// subscribes: 購読する、dedicated: 専用の
+// Notifications are delivered until ctx is cancelled or the driver is closed, then the channel is closed
+// 通知はctxのキャンセルまたはドライバーのCloseまで配信され、その後チャネルは閉じられる
+// Call Listen once per channel to subscribe to several channels
+// 複数チャネルを購読する場合はチャネルごとにListenを呼び出す
+func (d *PostgreSQLDriver) Listen(ctx context.Context, channel string) (<-chan Notification, error) {
+	if channel == "" {
+		return nil, fmt.Errorf("listen channel is required") // required: 必須
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	listener := d.newListener(d.activeHostConfig().BuildConnectionString(), func(event pq.ListenerEventType, err error) {
+		d.reportListenerEvent(channel, event, err)
+	})
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on channel %s: %w", channel, d.config.Load().redactError(err))
+	}
+
+	out := make(chan Notification) // out: 呼び出し元への配信チャネル
+	closing := d.closeSignal()
+
+	go func() {
+		defer close(out)
+		defer listener.Close()
+
+		ping := time.NewTicker(listenerPingInterval) // ping: 接続の死活確認用タイマー
+		defer ping.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-closing:
+				return
+			case <-ping.C:
+				// Ping detects a dead connection so pq can reconnect
+				// Pingで切断を検出し、pqに再接続させる
+				go listener.Ping()
+			case n := <-listener.NotificationChannel():
+				if n == nil {
+					continue // pq sends nil after a reconnect, reported via the event callback: 再接続後のnilはイベントで報告済み
+				}
+
+				select {
+				case out <- Notification{Channel: n.Channel, Payload: n.Extra}:
+				case <-ctx.Done():
+					return
+				case <-closing:
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// reportListenerEvent logs listener connection problems and passes them to the error hook
+// reportListenerEvent: リスナーの接続障害をログ出力し、エラーフックに渡す関数
+func (d *PostgreSQLDriver) reportListenerEvent(channel string, event pq.ListenerEventType, err error) {
+	switch event {
+	case pq.ListenerEventDisconnected, pq.ListenerEventConnectionAttemptFailed:
+		if err == nil {
+			err = fmt.Errorf("listener connection lost") // lost: 失われた
+		}
+	case pq.ListenerEventReconnected:
+		err = ErrNotificationsMissed
+	default:
+		return // connected: 初回接続は報告不要
+	}
+
+	err = d.config.Load().redactError(err)
+	d.logger.Warn(fmt.Sprintf("listener on channel %s: %v", channel, err), "channel", channel, "error", err.Error())
+	if d.listenerErrorHook != nil {
+		d.listenerErrorHook(channel, err)
+	}
+}
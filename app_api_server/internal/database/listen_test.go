@@ -0,0 +1,198 @@
+package database
+
+import (
+	"context" // context: コンテキスト
+	"errors"  // errors: エラー操作
+	"sync"    // sync: 同期処理
+	"testing" // testing: テスト機能
+	"time"    // time: 時間操作機能
+
+	"github.com/lib/pq" // pq: PostgreSQLドライバー、リスナーイベント
+)
+
+// fakeListener is an in-memory notificationListener
+// fakeListener: メモリ上で動作するnotificationListenerのフェイク実装
+type fakeListener struct {
+	notify   chan *pq.Notification // notify: 通知チャネル
+	callback pq.EventCallbackType  // callback: イベントコールバック
+	listen   error                 // listen: Listenが返すエラー
+
+	mu       sync.Mutex
+	channels []string // channels: 購読したチャネル
+	closed   bool     // closed: Close済み
+}
+
+func (l *fakeListener) Listen(channel string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.channels = append(l.channels, channel)
+	return l.listen
+}
+
+func (l *fakeListener) NotificationChannel() <-chan *pq.Notification { return l.notify }
+func (l *fakeListener) Ping() error                                  { return nil }
+
+func (l *fakeListener) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	return nil
+}
+
+func (l *fakeListener) isClosed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closed
+}
+
+// newListeningDriver creates a driver whose listeners are fakes
+// newListeningDriver: リスナーがフェイクのドライバーを作成するテスト用関数
+func newListeningDriver(t *testing.T, opts ...DriverOption) (*PostgreSQLDriver, *fakeListener) {
+	t.Helper()
+
+	driver, err := NewPostgreSQLDriverWithConfig(&DatabaseConfig{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "testuser",
+		Password: "testpass",
+		Database: "testdb",
+		SSLMode:  "disable",
+	}, opts...)
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	fake := &fakeListener{notify: make(chan *pq.Notification)}
+	driver.newListener = func(connectionString string, callback pq.EventCallbackType) notificationListener {
+		fake.callback = callback
+		return fake
+	}
+	return driver, fake
+}
+
+// receive waits for the next notification or fails the test
+// receive: 次の通知を待ち、届かなければテストを失敗させる関数
+func receive(t *testing.T, ch <-chan Notification) (Notification, bool) {
+	t.Helper()
+
+	select {
+	case n, ok := <-ch:
+		return n, ok
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for notification")
+		return Notification{}, false
+	}
+}
+
+// TestListenDeliversNotifications tests delivery and shutdown on context cancellation
+// TestListenDeliversNotifications: 通知の配信とコンテキストキャンセルでの停止をテストする関数
+func TestListenDeliversNotifications(t *testing.T) {
+	captureLog(t)
+	driver, fake := newListeningDriver(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	notifications, err := driver.Listen(ctx, "users_changed")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	for _, expected := range []string{"42", "43"} {
+		fake.notify <- nil // reconnect marker is skipped: 再接続の目印は配信しない
+		fake.notify <- &pq.Notification{Channel: "users_changed", Extra: expected}
+
+		n, _ := receive(t, notifications)
+		if n.Channel != "users_changed" || n.Payload != expected {
+			t.Errorf("Expected users_changed/%s, got: %+v", expected, n)
+		}
+	}
+
+	cancel()
+	if _, ok := receive(t, notifications); ok {
+		t.Error("Expected channel to be closed after cancel")
+	}
+	if !fake.isClosed() {
+		t.Error("Expected listener to be closed after cancel")
+	}
+}
+
+// TestListenStopsOnClose tests that closing the driver ends the subscription
+// TestListenStopsOnClose: ドライバーのCloseで購読が終了することをテストする関数
+func TestListenStopsOnClose(t *testing.T) {
+	captureLog(t)
+	driver, _ := newListeningDriver(t)
+
+	notifications, err := driver.Listen(context.Background(), "shifts_changed")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	driver.Close()
+	if _, ok := receive(t, notifications); ok {
+		t.Error("Expected channel to be closed after Close")
+	}
+}
+
+// TestListenValidation tests argument and listen errors
+// TestListenValidation: 引数とLISTENのエラーをテストする関数
+func TestListenValidation(t *testing.T) {
+	driver, fake := newListeningDriver(t)
+
+	if _, err := driver.Listen(context.Background(), ""); err == nil {
+		t.Error("Expected error for empty channel, got none")
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := driver.Listen(cancelled, "users_changed"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got: %v", err)
+	}
+
+	fake.listen = errors.New("boom")
+	if _, err := driver.Listen(context.Background(), "users_changed"); err == nil {
+		t.Error("Expected error when LISTEN fails, got none")
+	}
+	if !fake.isClosed() {
+		t.Error("Expected listener to be closed when LISTEN fails")
+	}
+}
+
+// TestListenErrorHook tests that connection problems reach the error hook
+// TestListenErrorHook: 接続障害がエラーフックに届くことをテストする関数
+func TestListenErrorHook(t *testing.T) {
+	captureLog(t)
+
+	var mu sync.Mutex
+	var reported []error
+	driver, fake := newListeningDriver(t, WithListenerErrorHook(func(channel string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if channel != "users_changed" {
+			t.Errorf("Expected channel users_changed, got: %s", channel)
+		}
+		reported = append(reported, err)
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if _, err := driver.Listen(ctx, "users_changed"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	connErr := errors.New("connection refused")
+	fake.callback(pq.ListenerEventConnected, nil)
+	fake.callback(pq.ListenerEventDisconnected, connErr)
+	fake.callback(pq.ListenerEventConnectionAttemptFailed, connErr)
+	fake.callback(pq.ListenerEventReconnected, nil)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 3 {
+		t.Fatalf("Expected 3 reported errors, got: %v", reported)
+	}
+	if !errors.Is(reported[0], connErr) || !errors.Is(reported[1], connErr) {
+		t.Errorf("Expected connection errors to be passed through, got: %v", reported)
+	}
+	if !errors.Is(reported[2], ErrNotificationsMissed) {
+		t.Errorf("Expected ErrNotificationsMissed after reconnect, got: %v", reported[2])
+	}
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"log" // log: ログ出力機能
+)
+
+// Logger receives the driver's log messages
+// Logger: ドライバーのログメッセージを受け取るインターフェース
+// Messages are complete sentences; keysAndValues repeat the important values as key-value pairs for structured
+// loggers. *slog.Logger satisfies Logger.
+// メッセージは完結した文、keysAndValuesは構造化ロガー向けに重要な値をキーと値の組で繰り返す。*slog.LoggerはLoggerを満たす
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// WithLogger sends the driver's log messages to logger instead of the standard logger
+// WithLogger: ドライバーのログメッセージを標準ロガーではなくloggerへ送るオプション
+// When logger is nil the standard logger stays in use: loggerがnilの場合は標準ロガーのまま
+func WithLogger(logger Logger) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		if logger != nil {
+			d.logger = logger
+		}
+	}
+}
+
+// stdLogger writes to the standard logger in the driver's original format
+// stdLogger: ドライバー本来の形式で標準ロガーへ出力するロガー
+// The message already carries the values, so the pairs are not repeated
+// メッセージが値を含むため、キーと値の組は繰り返さない
+type stdLogger struct{}
+
+// Debug implements Logger
+func (stdLogger) Debug(msg string, keysAndValues ...interface{}) { log.Print("Debug: " + msg) }
+
+// Info implements Logger
+func (stdLogger) Info(msg string, keysAndValues ...interface{}) { log.Print(msg) }
+
+// Warn implements Logger
+func (stdLogger) Warn(msg string, keysAndValues ...interface{}) { log.Print("Warning: " + msg) }
+
+// Error implements Logger
+func (stdLogger) Error(msg string, keysAndValues ...interface{}) { log.Print("Error: " + msg) }
+
+// loggerOrDefault returns logger, or the standard logger when it is nil
+// loggerOrDefault: loggerを返す関数、nilの場合は標準ロガーを返す
+func loggerOrDefault(logger Logger) Logger {
+	if logger == nil {
+		return stdLogger{}
+	}
+	return logger
+}
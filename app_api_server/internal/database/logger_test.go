@@ -0,0 +1,142 @@
+package database
+
+import (
+	"bytes"    // bytes: バイト列操作
+	"context"  // context: コンテキスト
+	"errors"   // errors: エラー操作
+	"fmt"      // fmt: フォーマット
+	"log/slog" // slog: 構造化ログ
+	"strings"  // strings: 文字列操作
+	"sync"     // sync: 同期処理
+	"testing"  // testing: テスト機能
+	"time"     // time: 時間操作機能
+)
+
+// The standard library's structured logger can be injected directly
+// 標準ライブラリの構造化ロガーはそのまま注入できる
+var _ Logger = (*slog.Logger)(nil)
+
+// logEntry is one call made to a capturingLogger
+// logEntry: capturingLoggerへの1回の呼び出し
+type logEntry struct {
+	level         string
+	msg           string
+	keysAndValues []interface{}
+}
+
+// attr returns the value logged for key
+// attr: keyに対して記録された値を返す関数
+func (e logEntry) attr(key string) (interface{}, bool) {
+	for i := 0; i+1 < len(e.keysAndValues); i += 2 {
+		if e.keysAndValues[i] == key {
+			return e.keysAndValues[i+1], true
+		}
+	}
+	return nil, false
+}
+
+// capturingLogger records every call for assertions
+// capturingLogger: 検証のために全ての呼び出しを記録するロガー
+type capturingLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *capturingLogger) record(level, msg string, keysAndValues []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, logEntry{level: level, msg: msg, keysAndValues: keysAndValues})
+}
+
+func (l *capturingLogger) Debug(msg string, kv ...interface{}) { l.record("debug", msg, kv) }
+func (l *capturingLogger) Info(msg string, kv ...interface{})  { l.record("info", msg, kv) }
+func (l *capturingLogger) Warn(msg string, kv ...interface{})  { l.record("warn", msg, kv) }
+func (l *capturingLogger) Error(msg string, kv ...interface{}) { l.record("error", msg, kv) }
+
+// find returns the first entry whose message contains text
+// find: メッセージにtextを含む最初の記録を返す関数
+func (l *capturingLogger) find(text string) (logEntry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, entry := range l.entries {
+		if strings.Contains(entry.msg, text) {
+			return entry, true
+		}
+	}
+	return logEntry{}, false
+}
+
+// TestWithLogger tests that connect and close messages go to the injected logger with attributes and not to the standard logger
+// TestWithLogger: 接続と切断のメッセージが属性付きで注入したロガーへ送られ、標準ロガーには出力されないことをテストする関数
+func TestWithLogger(t *testing.T) {
+	driver := newFakeConnectingDriver(t)
+	logger := &capturingLogger{}
+	WithLogger(logger)(driver)
+	standard := captureLog(t)
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected no error on Connect, got: %v", err)
+	}
+	if err := driver.Close(); err != nil {
+		t.Fatalf("Expected no error on Close, got: %v", err)
+	}
+
+	connected, ok := logger.find("Successfully connected to PostgreSQL database")
+	if !ok || connected.level != "info" {
+		t.Fatalf("Expected an info entry for the connect, got: %+v", logger.entries)
+	}
+	if database, _ := connected.attr("database"); database != "testdb" {
+		t.Errorf("Expected database attribute 'testdb', got: %v", database)
+	}
+	if _, ok := logger.find("Database connection closed successfully"); !ok {
+		t.Errorf("Expected an entry for the close, got: %+v", logger.entries)
+	}
+	if standard.Len() != 0 {
+		t.Errorf("Expected nothing on the standard logger, got: %s", standard.String())
+	}
+}
+
+// TestWithLoggerRedactsPasswords tests that warnings about errors carrying the password never log it
+// TestWithLoggerRedactsPasswords: パスワードを含むエラーの警告でパスワードが記録されないことをテストする関数
+func TestWithLoggerRedactsPasswords(t *testing.T) {
+	driver := newFakeConnectingDriver(t)
+	logger := &capturingLogger{}
+	WithLogger(logger)(driver)
+	driver.retryPolicy = RetryPolicy{MaxRetries: 1, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	failure := errors.New(`dial failed for "host=localhost password=testpass"`)
+	err := driver.retryWhen(context.Background(), "connection refused", func(error) bool { return true }, func() error { return failure })
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected the operation error, got: %v", err)
+	}
+
+	warning, ok := logger.find("connection refused, retrying")
+	if !ok || warning.level != "warn" {
+		t.Fatalf("Expected a retry warning, got: %+v", logger.entries)
+	}
+	if attempt, _ := warning.attr("attempt"); attempt != 1 {
+		t.Errorf("Expected attempt attribute 1, got: %v", attempt)
+	}
+	for _, entry := range logger.entries {
+		if logged := fmt.Sprint(entry.msg, entry.keysAndValues); strings.Contains(logged, "testpass") {
+			t.Errorf("Expected the password to be redacted, got: %s", logged)
+		}
+	}
+}
+
+// TestWithLoggerSlog tests that an *slog.Logger receives the values as JSON attributes
+// TestWithLoggerSlog: *slog.Loggerが値をJSONの属性として受け取ることをテストする関数
+func TestWithLoggerSlog(t *testing.T) {
+	var buf bytes.Buffer
+	driver := newFakeConnectingDriver(t)
+	WithLogger(slog.New(slog.NewJSONHandler(&buf, nil)))(driver)
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected no error on Connect, got: %v", err)
+	}
+	defer driver.Close()
+
+	if output := buf.String(); !strings.Contains(output, `"level":"INFO"`) || !strings.Contains(output, `"database":"testdb"`) {
+		t.Errorf("Expected a JSON info record with the database attribute, got: %s", output)
+	}
+}
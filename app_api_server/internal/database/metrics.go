@@ -0,0 +1,212 @@
+package database
+
+import (
+	"context" // context: コンテキスト、処理の文脈情報
+	"sync"    // sync: 同期処理、排他制御
+	"time"    // time: 時間操作機能
+
+	"github.com/prometheus/client_golang/prometheus" // prometheus: Prometheusメトリクスライブラリ
+)
+
+// metricsNamespace and metricsSubsystem prefix every exported metric name
+// metricsNamespace, metricsSubsystem: 全メトリクス名の接頭辞
+// prefix: 接頭辞を付ける
+const (
+	metricsNamespace = "sift"
+	metricsSubsystem = "db"
+)
+
+// StatsCollector exports connection pool statistics as Prometheus gauges
+// StatsCollector: 接続プール統計をPrometheusのゲージとして公開するコレクター
+// exports: 公開する、gauges: ゲージ（複数形）
+type StatsCollector struct {
+	driver *PostgreSQLDriver // driver: 統計の取得元ドライバー
+
+	openConnections   *prometheus.Desc // open: 開いている、connections: 接続
+	inUse             *prometheus.Desc // in use: 使用中
+	idle              *prometheus.Desc // idle: アイドル、待機中
+	waitCount         *prometheus.Desc // wait: 待機、count: 回数
+	waitDuration      *prometheus.Desc // duration: 時間
+	maxIdleClosed     *prometheus.Desc // closed: 閉じられた
+	maxLifetimeClosed *prometheus.Desc // lifetime: 寿命
+	connectionUp      *prometheus.Desc // up: 稼働中
+}
+
+// NewStatsCollector creates a collector for the driver with optional constant labels
+// NewStatsCollector: 固定ラベル付きでドライバー用のコレクターを作成するファクトリー関数
+// constant: 固定の、labels: ラベル（複数形）
+func NewStatsCollector(driver *PostgreSQLDriver, labels prometheus.Labels) *StatsCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, metricsSubsystem, name), help, nil, labels)
+	}
+
+	return &StatsCollector{
+		driver:            driver,
+		openConnections:   desc("open_connections", "Number of established connections both in use and idle."),
+		inUse:             desc("in_use_connections", "Number of connections currently in use."),
+		idle:              desc("idle_connections", "Number of idle connections."),
+		waitCount:         desc("wait_count", "Total number of connections waited for."),
+		waitDuration:      desc("wait_duration_seconds", "Total time blocked waiting for a new connection."),
+		maxIdleClosed:     desc("max_idle_closed", "Total number of connections closed due to SetMaxIdleConns."),
+		maxLifetimeClosed: desc("max_lifetime_closed", "Total number of connections closed due to SetConnMaxLifetime."),
+		connectionUp:      desc("connection_up", "Whether the database answered a ping (1) or not (0)."),
+	}
+}
+
+// Describe sends the descriptors of all metrics to the channel
+// Describe: 全メトリクスの記述子をチャネルに送る関数
+// descriptors: 記述子（複数形）、channel: チャネル
+func (c *StatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.openConnections
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.maxIdleClosed
+	ch <- c.maxLifetimeClosed
+	ch <- c.connectionUp
+}
+
+// Collect reads the current pool statistics and sends them as metrics
+// Collect: 現在のプール統計を読み取りメトリクスとして送る関数
+// Before Connect all gauges report zero: 接続前は全て0を報告する
+func (c *StatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.driver.GetConnectionStats()
+
+	up := 0.0
+	if c.driver.IsConnected() {
+		up = 1.0
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.openConnections, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.GaugeValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.GaugeValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.maxIdleClosed, prometheus.GaugeValue, float64(stats.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(c.maxLifetimeClosed, prometheus.GaugeValue, float64(stats.MaxLifetimeClosed))
+	ch <- prometheus.MustNewConstMetric(c.connectionUp, prometheus.GaugeValue, up)
+}
+
+// Operation labels outside the caller's names: 呼び出し元の名前以外の操作ラベル
+const (
+	unknownOperation     = "unknown" // unknown: WithOperationNameが設定されていない文
+	otherOperation       = "other"   // other: 上限を超えた新しい操作名
+	defaultMaxOperations = 100       // default max operations: 記録する操作名の既定の上限
+)
+
+// operationNameKey carries the name set by WithOperationName
+// operationNameKey: WithOperationNameで設定した名前を運ぶコンテキストのキー
+type operationNameKey struct{}
+
+// WithOperationName names the logical operation, such as "users.GetByEmail", for statements run with ctx
+// WithOperationName: ctxで実行される文の論理的な操作名（"users.GetByEmail"など）を設定する関数
+// logical: 論理的な
+func WithOperationName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationNameKey{}, name)
+}
+
+// OperationName returns the name set by WithOperationName, if any
+// OperationName: WithOperationNameで設定した名前を返す関数
+func OperationName(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(operationNameKey{}).(string)
+	return name, ok && name != ""
+}
+
+// MetricsHook records statement durations in a Prometheus histogram labeled by statement kind (op),
+// operation name (from WithOperationName) and outcome ("ok" or "error")
+// MetricsHook: 文の所要時間を文の種類（op）、操作名（WithOperationNameから）、結果（"ok"または"error"）の
+// ラベル付きPrometheusヒストグラムに記録するフック
+// histogram: ヒストグラム、outcome: 結果
+// Statements without a name are labeled "unknown"; once the configured number of names has been seen, new names
+// are labeled "other" so label cardinality stays bounded
+// 名前のない文は"unknown"とする、設定した数の名前を記録した後の新しい名前は"other"とし、ラベルの種類数を制限する
+// cardinality: ラベル値の種類数
+// Register it as a collector and pass it to WithStatementHooks: コレクターとして登録し、WithStatementHooksに渡す
+type MetricsHook struct {
+	duration      *prometheus.HistogramVec // duration: 所要時間のヒストグラム
+	maxOperations int                      // max operations: 記録する操作名の上限
+
+	mu         sync.Mutex          // mu: operationsを保護する
+	operations map[string]struct{} // operations: 記録済みの操作名
+}
+
+// MetricsHookOption configures NewMetricsHook
+// MetricsHookOption: NewMetricsHookを設定するオプション
+type MetricsHookOption func(*MetricsHook)
+
+// MetricsMaxOperations sets how many distinct operation names get their own label (0 or less keeps the default of 100)
+// MetricsMaxOperations: 個別のラベルを持つ操作名の数を設定するオプション（0以下の場合は既定値の100）
+// distinct: 異なる
+func MetricsMaxOperations(n int) MetricsHookOption {
+	return func(h *MetricsHook) {
+		if n > 0 {
+			h.maxOperations = n
+		}
+	}
+}
+
+// NewMetricsHook creates a metrics hook with optional constant labels
+// NewMetricsHook: 固定ラベル付きでメトリクスフックを作成するファクトリー関数
+func NewMetricsHook(labels prometheus.Labels, opts ...MetricsHookOption) *MetricsHook {
+	h := &MetricsHook{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   metricsNamespace,
+			Subsystem:   metricsSubsystem,
+			Name:        "statement_duration_seconds",
+			Help:        "Duration of statements run through the driver helpers.",
+			Buckets:     prometheus.DefBuckets,
+			ConstLabels: labels,
+		}, []string{"op", "operation", "outcome"}),
+		maxOperations: defaultMaxOperations,
+		operations:    map[string]struct{}{},
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Before implements StatementHook
+func (h *MetricsHook) Before(ctx context.Context, op, query string, args []interface{}) context.Context {
+	return ctx
+}
+
+// After implements StatementHook
+func (h *MetricsHook) After(ctx context.Context, op, query string, duration time.Duration, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	h.duration.WithLabelValues(op, h.operationLabel(ctx), outcome).Observe(duration.Seconds())
+}
+
+// operationLabel returns the operation label for ctx, keeping the number of distinct names within maxOperations
+// operationLabel: ctxの操作ラベルを返す関数、異なる名前の数をmaxOperations以内に保つ
+func (h *MetricsHook) operationLabel(ctx context.Context) string {
+	name, ok := OperationName(ctx)
+	if !ok {
+		return unknownOperation
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, seen := h.operations[name]; seen {
+		return name
+	}
+	if len(h.operations) >= h.maxOperations {
+		return otherOperation
+	}
+	h.operations[name] = struct{}{}
+	return name
+}
+
+// Describe implements prometheus.Collector
+func (h *MetricsHook) Describe(ch chan<- *prometheus.Desc) {
+	h.duration.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (h *MetricsHook) Collect(ch chan<- prometheus.Metric) {
+	h.duration.Collect(ch)
+}
@@ -0,0 +1,195 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"reflect"                       // reflect: リフレクション、値の比較
+	"strconv"                       // strconv: string conversion（文字列変換）
+	"strings"                       // strings: 文字列操作
+	"testing"                       // testing: テスト機能
+
+	"github.com/prometheus/client_golang/prometheus"          // prometheus: Prometheusメトリクスライブラリ
+	"github.com/prometheus/client_golang/prometheus/testutil" // testutil: Prometheusテスト補助
+)
+
+// expectedPoolMetrics renders the exposition text for the given values
+// expectedPoolMetrics: 指定値に対するPrometheusテキスト形式を生成するテスト用関数
+// exposition: 公開形式
+func expectedPoolMetrics(open, idle, up int) string {
+	replacer := strings.NewReplacer("OPEN", strconv.Itoa(open), "IDLE", strconv.Itoa(idle), "UP", strconv.Itoa(up))
+	return replacer.Replace(`
+# HELP sift_db_connection_up Whether the database answered a ping (1) or not (0).
+# TYPE sift_db_connection_up gauge
+sift_db_connection_up{service="api"} UP
+# HELP sift_db_idle_connections Number of idle connections.
+# TYPE sift_db_idle_connections gauge
+sift_db_idle_connections{service="api"} IDLE
+# HELP sift_db_in_use_connections Number of connections currently in use.
+# TYPE sift_db_in_use_connections gauge
+sift_db_in_use_connections{service="api"} 0
+# HELP sift_db_max_idle_closed Total number of connections closed due to SetMaxIdleConns.
+# TYPE sift_db_max_idle_closed gauge
+sift_db_max_idle_closed{service="api"} 0
+# HELP sift_db_max_lifetime_closed Total number of connections closed due to SetConnMaxLifetime.
+# TYPE sift_db_max_lifetime_closed gauge
+sift_db_max_lifetime_closed{service="api"} 0
+# HELP sift_db_open_connections Number of established connections both in use and idle.
+# TYPE sift_db_open_connections gauge
+sift_db_open_connections{service="api"} OPEN
+# HELP sift_db_wait_count Total number of connections waited for.
+# TYPE sift_db_wait_count gauge
+sift_db_wait_count{service="api"} 0
+# HELP sift_db_wait_duration_seconds Total time blocked waiting for a new connection.
+# TYPE sift_db_wait_duration_seconds gauge
+sift_db_wait_duration_seconds{service="api"} 0
+`)
+}
+
+// TestStatsCollectorNotConnected tests that the collector is safe before Connect
+// TestStatsCollectorNotConnected: 接続前でもコレクターが安全に動作することをテストする関数
+func TestStatsCollectorNotConnected(t *testing.T) {
+	driver, err := NewPostgreSQLDriverWithConfig(&DatabaseConfig{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "testuser",
+		Password: "testpass",
+		Database: "testdb",
+		SSLMode:  "disable",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	collector := NewStatsCollector(driver, prometheus.Labels{"service": "api"})
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expectedPoolMetrics(0, 0, 0))); err != nil {
+		t.Errorf("Unexpected metrics: %v", err)
+	}
+}
+
+// TestStatsCollectorConnected tests gauges for a connected pool
+// TestStatsCollectorConnected: 接続済みプールのゲージをテストする関数
+func TestStatsCollectorConnected(t *testing.T) {
+	driver, _ := newTestDriver(t)
+
+	// Open one idle connection so the pool numbers are deterministic
+	// deterministic: 決定的な、毎回同じ結果になる
+	if !driver.IsConnected() {
+		t.Fatal("Expected fake database to be connected")
+	}
+
+	collector := NewStatsCollector(driver, prometheus.Labels{"service": "api"})
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expectedPoolMetrics(1, 1, 1))); err != nil {
+		t.Errorf("Unexpected metrics: %v", err)
+	}
+}
+
+// TestStatsCollectorRegister tests registration on a fresh registry
+// TestStatsCollectorRegister: 新しいレジストリへの登録をテストする関数
+// registration: 登録、registry: レジストリ
+func TestStatsCollectorRegister(t *testing.T) {
+	driver, _ := newTestDriver(t)
+
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(NewStatsCollector(driver, nil)); err != nil {
+		t.Errorf("Expected collector to register, got: %v", err)
+	}
+}
+
+// statementSampleCounts gathers the hook's histogram and returns the sample count per "op/operation/outcome"
+// statementSampleCounts: フックのヒストグラムを収集し、「op/operation/outcome」ごとの記録数を返すテスト用関数
+func statementSampleCounts(t *testing.T, hook *MetricsHook) map[string]uint64 {
+	t.Helper()
+
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(hook); err != nil {
+		t.Fatalf("Expected the hook to register, got: %v", err)
+	}
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Expected no error gathering, got: %v", err)
+	}
+
+	counts := map[string]uint64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			values := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				values[label.GetName()] = label.GetValue()
+			}
+			counts[values["op"]+"/"+values["operation"]+"/"+values["outcome"]] = metric.GetHistogram().GetSampleCount()
+		}
+	}
+	return counts
+}
+
+// TestMetricsHook tests that statements are observed under their operation and outcome labels
+// TestMetricsHook: 文が操作名と結果のラベルで記録されることをテストする関数
+func TestMetricsHook(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	fake.exec = func(query string, args []sqldriver.NamedValue) (sqldriver.Result, error) {
+		if strings.Contains(query, "missing") {
+			return nil, errors.New(`relation "missing" does not exist`)
+		}
+		return sqldriver.RowsAffected(1), nil
+	}
+	hook := NewMetricsHook(prometheus.Labels{"service": "api"})
+	WithStatementHooks(hook)(driver)
+
+	named := WithOperationName(context.Background(), "users.GetByEmail")
+	for i := 0; i < 2; i++ {
+		if _, err := driver.ExecContext(named, "UPDATE users SET seen = now()"); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+	if _, err := driver.ExecContext(named, "UPDATE missing SET x = 1"); err == nil {
+		t.Fatal("Expected an error from the missing table, got none")
+	}
+	rows, err := driver.QueryContext(context.Background(), "SELECT 1")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	rows.Close()
+
+	expected := map[string]uint64{
+		"db.Exec/users.GetByEmail/ok":    2,
+		"db.Exec/users.GetByEmail/error": 1,
+		"db.Query/unknown/ok":            1,
+	}
+	if counts := statementSampleCounts(t, hook); !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Expected sample counts %v, got: %v", expected, counts)
+	}
+	if count := testutil.CollectAndCount(hook, "sift_db_statement_duration_seconds"); count != 3 {
+		t.Errorf("Expected 3 labeled series, got: %d", count)
+	}
+	if problems, err := testutil.CollectAndLint(hook); err != nil || len(problems) > 0 {
+		t.Errorf("Expected no lint problems, got: %v (%v)", problems, err)
+	}
+}
+
+// TestMetricsHookBoundedOperations tests that names beyond the configured set are labeled "other"
+// TestMetricsHookBoundedOperations: 設定した数を超える名前が"other"として記録されることをテストする関数
+func TestMetricsHookBoundedOperations(t *testing.T) {
+	driver, _ := newTestDriver(t)
+	hook := NewMetricsHook(nil, MetricsMaxOperations(2))
+	WithStatementHooks(hook)(driver)
+
+	for _, name := range []string{"a", "b", "c", "a", "d", ""} {
+		if _, err := driver.ExecContext(WithOperationName(context.Background(), name), "SELECT 1"); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+
+	expected := map[string]uint64{
+		"db.Exec/a/ok":       2,
+		"db.Exec/b/ok":       1,
+		"db.Exec/other/ok":   2,
+		"db.Exec/unknown/ok": 1,
+	}
+	if counts := statementSampleCounts(t, hook); !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Expected sample counts %v, got: %v", expected, counts)
+	}
+	if count := testutil.CollectAndCount(hook); count != 4 {
+		t.Errorf("Expected the series to stay bounded at 4, got: %d", count)
+	}
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+)
+
+// migrationTable is the table golang-migrate records the applied version in
+// migrationTable: golang-migrateが適用済みのバージョンを記録するテーブル
+const migrationTable = "schema_migrations"
+
+// ErrMigrationBehind is returned by CheckMigrationVersion when the database is older than required
+// ErrMigrationBehind: データベースが必要なバージョンより古い場合にCheckMigrationVersionが返すエラー
+// behind: 遅れている
+var ErrMigrationBehind = errors.New("database schema is behind the required migration version")
+
+// ErrMigrationDirty is returned by CheckMigrationVersion when the last migration failed part way
+// ErrMigrationDirty: 最後のマイグレーションが途中で失敗した場合にCheckMigrationVersionが返すエラー
+// dirty: 不完全な状態、part way: 途中で
+var ErrMigrationDirty = errors.New("database schema is dirty")
+
+// GetMigrationVersion reads the version and dirty flag from golang-migrate's schema_migrations table
+// GetMigrationVersion: golang-migrateのschema_migrationsテーブルからバージョンとdirtyフラグを読み込む関数
+// A missing or empty table reports version 0: テーブルが存在しないか空の場合はバージョン0を返す
+func (d *PostgreSQLDriver) GetMigrationVersion(ctx context.Context) (version uint, dirty bool, err error) {
+	ctx, span := d.startSpan(ctx, "db.GetMigrationVersion", "")
+	defer func() { endSpan(span, err) }()
+
+	var current int64
+	err = d.QueryRowContext(ctx, "SELECT version, dirty FROM "+migrationTable+" LIMIT 1").Scan(&current, &dirty)
+	switch {
+	case errors.Is(err, sql.ErrNoRows), hasSQLState(err, sqlStateUndefinedTable):
+		// No migration has run yet
+		// マイグレーションがまだ一度も実行されていない
+		return 0, false, nil
+	case err != nil:
+		return 0, false, fmt.Errorf("failed to read migration version: %w", err)
+	case current < 0:
+		return 0, false, fmt.Errorf("invalid migration version %d in %s", current, migrationTable)
+	}
+	return uint(current), dirty, nil
+}
+
+// CheckMigrationVersion fails unless the schema is clean and at least at the required version
+// CheckMigrationVersion: スキーマがクリーンで必要なバージョン以上でなければエラーを返す関数
+// Call it at startup to refuse serving against an outdated schema: 起動時に呼び出し、古いスキーマでのサービス提供を拒否する
+// outdated: 古い
+func (d *PostgreSQLDriver) CheckMigrationVersion(ctx context.Context, required uint) error {
+	version, dirty, err := d.GetMigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("%w: migration %d did not complete; fix the schema and force the version before starting", ErrMigrationDirty, version)
+	}
+	if version < required {
+		return fmt.Errorf("%w: database is at version %d, required %d; run the pending migrations", ErrMigrationBehind, version, required) // pending: 未適用の
+	}
+	return nil
+}
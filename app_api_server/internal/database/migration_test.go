@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"strings"                       // strings: 文字列操作
+	"testing"                       // testing: テスト機能
+
+	"github.com/lib/pq" // pq: PostgreSQLドライバー、エラー型
+)
+
+// TestCheckMigrationVersion tests the version and dirty checks against schema_migrations
+// TestCheckMigrationVersion: schema_migrationsに対するバージョンとdirtyの確認をテストする関数
+func TestCheckMigrationVersion(t *testing.T) {
+	testCases := []struct {
+		name            string
+		rows            [][]sqldriver.Value
+		queryErr        error
+		required        uint
+		expectedVersion uint
+		expectedDirty   bool
+		expectedErr     error // expected: CheckMigrationVersionが返すべきエラー、nilなら成功
+	}{
+		{name: "Current", rows: [][]sqldriver.Value{{int64(5), false}}, required: 5, expectedVersion: 5},
+		{name: "Ahead", rows: [][]sqldriver.Value{{int64(7), false}}, required: 5, expectedVersion: 7},
+		{name: "Behind", rows: [][]sqldriver.Value{{int64(3), false}}, required: 5, expectedVersion: 3, expectedErr: ErrMigrationBehind},
+		{name: "Dirty", rows: [][]sqldriver.Value{{int64(5), true}}, required: 5, expectedVersion: 5, expectedDirty: true, expectedErr: ErrMigrationDirty},
+		{name: "Empty table", rows: nil, required: 1, expectedErr: ErrMigrationBehind},
+		{name: "Missing table", queryErr: &pq.Error{Code: "42P01"}, required: 1, expectedErr: ErrMigrationBehind},
+		{name: "Missing table with nothing required", queryErr: &pq.Error{Code: "42P01"}, required: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, fake := newTestDriver(t)
+			fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+				if tc.queryErr != nil {
+					return nil, tc.queryErr
+				}
+				return &fakeRows{columns: []string{"version", "dirty"}, values: tc.rows}, nil
+			}
+
+			version, dirty, err := driver.GetMigrationVersion(context.Background())
+			if err != nil {
+				t.Fatalf("Expected no error from GetMigrationVersion, got: %v", err)
+			}
+			if version != tc.expectedVersion || dirty != tc.expectedDirty {
+				t.Errorf("Expected version %d dirty %v, got: %d %v", tc.expectedVersion, tc.expectedDirty, version, dirty)
+			}
+
+			err = driver.CheckMigrationVersion(context.Background(), tc.required)
+			if tc.expectedErr == nil {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected %v, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+// TestCheckMigrationVersionMessage tests that the error names both versions
+// TestCheckMigrationVersionMessage: エラーに両方のバージョンが含まれることをテストする関数
+func TestCheckMigrationVersionMessage(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		return &fakeRows{columns: []string{"version", "dirty"}, values: [][]sqldriver.Value{{int64(3), false}}}, nil
+	}
+
+	err := driver.CheckMigrationVersion(context.Background(), 12)
+	if err == nil || !strings.Contains(err.Error(), "version 3") || !strings.Contains(err.Error(), "required 12") {
+		t.Errorf("Expected error naming versions 3 and 12, got: %v", err)
+	}
+}
+
+// TestGetMigrationVersionErrors tests that other failures are not mistaken for version 0
+// TestGetMigrationVersionErrors: 他の失敗がバージョン0と誤認されないことをテストする関数
+// mistaken: 誤認される
+func TestGetMigrationVersionErrors(t *testing.T) {
+	t.Run("Not connected", func(t *testing.T) {
+		driver := newFakeConnectingDriver(t)
+		if _, _, err := driver.GetMigrationVersion(context.Background()); err == nil {
+			t.Error("Expected error before Connect")
+		}
+	})
+
+	t.Run("Permission denied", func(t *testing.T) {
+		driver, fake := newTestDriver(t)
+		fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+			return nil, &pq.Error{Code: "42501"} // insufficient_privilege: 権限不足
+		}
+		if _, _, err := driver.GetMigrationVersion(context.Background()); err == nil {
+			t.Error("Expected error for insufficient privilege")
+		}
+	})
+
+	t.Run("Negative version", func(t *testing.T) {
+		driver, fake := newTestDriver(t)
+		fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+			return &fakeRows{columns: []string{"version", "dirty"}, values: [][]sqldriver.Value{{int64(-1), false}}}, nil
+		}
+		if _, _, err := driver.GetMigrationVersion(context.Background()); err == nil {
+			t.Error("Expected error for negative version")
+		}
+	})
+}
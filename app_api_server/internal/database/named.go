@@ -0,0 +1,168 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"strconv"      // strconv: string conversion（文字列変換）、プレースホルダー番号の生成
+	"strings"      // strings: 文字列操作
+)
+
+// BindNamed rewrites :name placeholders to $N and returns the matching positional arguments
+// BindNamed: :name形式のプレースホルダーを$Nに書き換え、対応する位置引数を返す関数
+// placeholders: プレースホルダー、positional: 位置による
+// A repeated name reuses the same $N. Placeholders inside string literals, quoted identifiers,
+// dollar-quoted strings and comments are left alone, as are :: casts.
+// 同じ名前は同じ$Nを再利用する。文字列リテラル、クォート識別子、ドル引用符文字列、コメント内と::キャストは書き換えない
+// Every name missing from arg is listed in the returned error
+// argに存在しない名前は全てエラーに列挙される
+// Array slices with identifier bounds must be spaced (arr[lo : hi]) so hi is not read as a name
+// 識別子を境界に使う配列スライスは、hiが名前と解釈されないよう空白を入れる（arr[lo : hi]）
+func BindNamed(query string, arg map[string]interface{}) (string, []interface{}, error) {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	var args []interface{}
+	positions := make(map[string]int) // positions: 名前から$N番号への対応
+	var missing []string              // missing: argに存在しない名前
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := skipQuoted(query, i, c, c == '\'' && isEscapeStringPrefix(query, i))
+			b.WriteString(query[i:end])
+			i = end
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				end = len(query) - i
+			} else {
+				end += 4 // include both delimiters: 両端の区切り文字を含める
+			}
+			b.WriteString(query[i : i+end])
+			i += end
+		case c == '$' && (i == 0 || !isNameChar(query[i-1])):
+			end := skipDollarQuoted(query, i) // a $ inside an identifier never opens a body: 識別子中の$は本文を開始しない
+			b.WriteString(query[i:end])
+			i = end
+		case c == ':' && i+1 < len(query) && query[i+1] == ':':
+			b.WriteString("::") // type cast: 型キャスト
+			i += 2
+		case c == ':' && i+1 < len(query) && isNameStart(query[i+1]):
+			end := i + 1
+			for end < len(query) && isNameChar(query[end]) {
+				end++
+			}
+			name := query[i+1 : end]
+
+			position, seen := positions[name]
+			if !seen {
+				value, ok := arg[name]
+				if !ok {
+					missing = append(missing, name)
+				}
+				args = append(args, value)
+				position = len(args)
+				positions[name] = position
+			}
+			b.WriteString("$" + strconv.Itoa(position))
+			i = end
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+
+	if len(missing) > 0 {
+		return "", nil, fmt.Errorf("missing named parameters: %s", strings.Join(missing, ", "))
+	}
+	return b.String(), args, nil
+}
+
+// NamedExec executes a statement with :name placeholders bound from arg
+// NamedExec: argから値を割り当てた:nameプレースホルダー付きの文を実行する関数
+func (d *PostgreSQLDriver) NamedExec(ctx context.Context, query string, arg map[string]interface{}) (sql.Result, error) {
+	bound, args, err := BindNamed(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return d.ExecContext(ctx, bound, args...)
+}
+
+// NamedQuery executes a query with :name placeholders bound from arg
+// NamedQuery: argから値を割り当てた:nameプレースホルダー付きのクエリを実行する関数
+func (d *PostgreSQLDriver) NamedQuery(ctx context.Context, query string, arg map[string]interface{}) (*Rows, error) {
+	bound, args, err := BindNamed(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return d.QueryContext(ctx, bound, args...)
+}
+
+// skipQuoted returns the index just past the quoted section starting at start
+// skipQuoted: startから始まる引用部分の直後のインデックスを返す関数
+// Doubled quotes escape the quote; backslashes also escape in E'...' strings
+// 引用符の二重化でエスケープし、E'...'文字列ではバックスラッシュもエスケープとして扱う
+func skipQuoted(query string, start int, quote byte, backslashEscapes bool) int {
+	for i := start + 1; i < len(query); i++ {
+		switch {
+		case backslashEscapes && query[i] == '\\':
+			i++ // skip escaped character: エスケープされた文字を飛ばす
+		case query[i] == quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++ // doubled quote: 二重化された引用符
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(query) // unterminated: 閉じられていない
+}
+
+// skipDollarQuoted returns the index past a $tag$...$tag$ string, or past the $ when it is not one
+// skipDollarQuoted: $tag$...$tag$文字列の直後のインデックスを返す関数、該当しない場合は$の直後
+func skipDollarQuoted(query string, start int) int {
+	end := start + 1
+	for end < len(query) && isNameChar(query[end]) && !(end == start+1 && query[end] >= '0' && query[end] <= '9') {
+		end++
+	}
+	if end >= len(query) || query[end] != '$' {
+		return start + 1 // positional parameter or plain $: 位置パラメーターまたは単独の$
+	}
+
+	tag := query[start : end+1]
+	closing := strings.Index(query[end+1:], tag)
+	if closing < 0 {
+		return len(query)
+	}
+	return end + 1 + closing + len(tag)
+}
+
+// isEscapeStringPrefix reports whether the quote at i opens an E'...' string (E not part of a longer word)
+// isEscapeStringPrefix: 位置iの引用符がE'...'文字列の開始かどうかを判定する関数（Eが単語の一部でない場合）
+func isEscapeStringPrefix(query string, i int) bool {
+	if i == 0 || (query[i-1] != 'E' && query[i-1] != 'e') {
+		return false
+	}
+	return i == 1 || !isNameChar(query[i-2])
+}
+
+// isNameStart reports whether c can begin a parameter name
+// isNameStart: cがパラメーター名の先頭に使えるかどうかを判定する関数
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isNameChar reports whether c can continue a parameter name
+// isNameChar: cがパラメーター名の2文字目以降に使えるかどうかを判定する関数
+func isNameChar(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
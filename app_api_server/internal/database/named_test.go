@@ -0,0 +1,165 @@
+package database
+
+import (
+	"context" // context: コンテキスト
+	"reflect" // reflect: リフレクション、値の比較
+	"strings" // strings: 文字列操作
+	"testing" // testing: テスト機能
+)
+
+// TestBindNamed tests rewriting of :name placeholders
+// TestBindNamed: :nameプレースホルダーの書き換えをテストする関数
+func TestBindNamed(t *testing.T) {
+	arg := map[string]interface{}{
+		"id":     "u-1",
+		"email":  "user@example.com",
+		"active": true,
+		"name_2": nil,
+	}
+
+	testCases := []struct {
+		name         string
+		query        string
+		expectQuery  string
+		expectArgs   []interface{}
+		expectError  bool
+		errorContent []string // error content: エラーに含まれるべき名前
+	}{
+		{
+			name:        "Simple placeholders",
+			query:       "SELECT * FROM app.users WHERE id = :id AND email = :email",
+			expectQuery: "SELECT * FROM app.users WHERE id = $1 AND email = $2",
+			expectArgs:  []interface{}{"u-1", "user@example.com"},
+		},
+		{
+			name:        "Repeated name reuses position",
+			query:       "UPDATE app.users SET email = :email WHERE email <> :email OR id = :id",
+			expectQuery: "UPDATE app.users SET email = $1 WHERE email <> $1 OR id = $2",
+			expectArgs:  []interface{}{"user@example.com", "u-1"},
+		},
+		{
+			name:        "Nil value and digits in name",
+			query:       "UPDATE app.users SET last_name = :name_2 WHERE id=:id",
+			expectQuery: "UPDATE app.users SET last_name = $1 WHERE id=$2",
+			expectArgs:  []interface{}{nil, "u-1"},
+		},
+		{
+			name:        "Type casts are kept",
+			query:       "SELECT created_at::date FROM app.users WHERE id = :id::uuid",
+			expectQuery: "SELECT created_at::date FROM app.users WHERE id = $1::uuid",
+			expectArgs:  []interface{}{"u-1"},
+		},
+		{
+			name:        "Names inside string literals are ignored",
+			query:       "SELECT ':id', 'it''s :email' FROM app.users WHERE id = :id",
+			expectQuery: "SELECT ':id', 'it''s :email' FROM app.users WHERE id = $1",
+			expectArgs:  []interface{}{"u-1"},
+		},
+		{
+			name:        "Escape string with backslash quote",
+			query:       `SELECT E'a\':id' WHERE id = :id`,
+			expectQuery: `SELECT E'a\':id' WHERE id = $1`,
+			expectArgs:  []interface{}{"u-1"},
+		},
+		{
+			name:        "Backslash in regular string is literal",
+			query:       `SELECT 'C:\' WHERE type='x\' AND id = :id`,
+			expectQuery: `SELECT 'C:\' WHERE type='x\' AND id = $1`,
+			expectArgs:  []interface{}{"u-1"},
+		},
+		{
+			name:        "Quoted identifiers are ignored",
+			query:       `SELECT "col:id" FROM app.users WHERE id = :id`,
+			expectQuery: `SELECT "col:id" FROM app.users WHERE id = $1`,
+			expectArgs:  []interface{}{"u-1"},
+		},
+		{
+			name:        "Dollar-quoted strings are ignored",
+			query:       "SELECT $$ :id $$, $tag$ :email $tag$ WHERE id = :id",
+			expectQuery: "SELECT $$ :id $$, $tag$ :email $tag$ WHERE id = $1",
+			expectArgs:  []interface{}{"u-1"},
+		},
+		{
+			name:        "Dollar signs inside identifiers",
+			query:       "SELECT a$b$ FROM t WHERE x = :id",
+			expectQuery: "SELECT a$b$ FROM t WHERE x = $1",
+			expectArgs:  []interface{}{"u-1"},
+		},
+		{
+			name:        "Comments are ignored",
+			query:       "SELECT 1 -- :email\nWHERE /* :email */ id = :id",
+			expectQuery: "SELECT 1 -- :email\nWHERE /* :email */ id = $1",
+			expectArgs:  []interface{}{"u-1"},
+		},
+		{
+			name:        "No placeholders",
+			query:       "SELECT 1",
+			expectQuery: "SELECT 1",
+			expectArgs:  nil,
+		},
+		{
+			name:        "Colon not followed by a name",
+			query:       "SELECT arr[1:2], ': ' WHERE id = :id",
+			expectQuery: "SELECT arr[1:2], ': ' WHERE id = $1",
+			expectArgs:  []interface{}{"u-1"},
+		},
+		{
+			name:         "Missing keys are listed",
+			query:        "SELECT * FROM app.users WHERE id = :id AND org = :org AND team = :team AND org2 = :org",
+			expectError:  true,
+			errorContent: []string{"org", "team"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, args, err := BindNamed(tc.query, arg)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("Expected error for test case '%s', but got none", tc.name)
+				}
+				for _, content := range tc.errorContent {
+					if !strings.Contains(err.Error(), content) {
+						t.Errorf("Expected error to mention '%s', got: %v", content, err)
+					}
+				}
+				if strings.Count(err.Error(), "org") != 1 {
+					t.Errorf("Expected each missing name once, got: %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if query != tc.expectQuery {
+				t.Errorf("Expected query %q, got: %q", tc.expectQuery, query)
+			}
+			if !reflect.DeepEqual(args, tc.expectArgs) {
+				t.Errorf("Expected args %v, got: %v", tc.expectArgs, args)
+			}
+		})
+	}
+}
+
+// TestNamedExec tests that NamedExec runs the rewritten statement
+// TestNamedExec: NamedExecが書き換え後の文を実行することをテストする関数
+func TestNamedExec(t *testing.T) {
+	driver, fake := newTestDriver(t)
+
+	if _, err := driver.NamedExec(context.Background(), "UPDATE app.users SET is_active = :active WHERE id = :id", map[string]interface{}{"active": true, "id": "u-1"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	executed := fake.executed()
+	if len(executed) != 1 || executed[0] != "UPDATE app.users SET is_active = $1 WHERE id = $2" {
+		t.Errorf("Expected rewritten statement, got: %v", executed)
+	}
+
+	if _, err := driver.NamedQuery(context.Background(), "SELECT :missing", nil); err == nil {
+		t.Error("Expected error for missing parameter, got none")
+	}
+	if len(fake.executed()) != 1 {
+		t.Error("Expected no statement to run when binding fails")
+	}
+}
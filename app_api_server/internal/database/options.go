@@ -0,0 +1,17 @@
+package database
+
+// DriverOption configures optional driver behavior at construction time
+// DriverOption: 生成時にドライバーの任意の動作を設定するオプション関数
+// optional: 任意の、construction: 生成
+type DriverOption func(*PostgreSQLDriver)
+
+// applyOptions applies each option to the driver in order
+// applyOptions: オプションを順番にドライバーへ適用する関数
+// applies: 適用する、order: 順番
+func (d *PostgreSQLDriver) applyOptions(opts []DriverOption) {
+	for _, opt := range opts {
+		if opt != nil {
+			opt(d)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"log"     // log: ログ出力機能
+	"os"      // os: operating system（オペレーティングシステム）、環境変数の読み込み
+	"strconv" // strconv: string conversion（文字列変換）、真偽値の解析
+	"strings" // strings: 文字列操作
+)
+
+// configSourceDefault names a field left to its default value in the source log
+// configSourceDefault: 取得元のログでデフォルト値のままのフィールドを表す名前
+const configSourceDefault = "default"
+
+// configSourceFlag names a field set by a command-line flag in the source log
+// configSourceFlag: 取得元のログでコマンドラインフラグから設定されたフィールドを表す名前
+const configSourceFlag = "flag"
+
+// configSources records which environment variable supplied each connection field
+// configSources: 各接続フィールドをどの環境変数から取得したかを記録する構造体
+// sources: 取得元（複数形）
+type configSources struct {
+	fields  []string // fields: 記録順のフィールド名
+	sources []string // sources: フィールドごとの取得元
+
+	flags     map[string]string // flags: フィールドごとのコマンドラインフラグの値、空文字列は未指定
+	ignoreEnv bool              // ignore env: フラグのあるフィールドで環境変数を読まずデフォルト値を使う
+}
+
+// lookupWithFallback reads dbKey, falling back to the libpq variable pgKey when dbKey is unset
+// lookupWithFallback: dbKeyを読み、未設定の場合はlibpqの環境変数pgKeyを読む関数
+// DB_* always takes precedence so an explicit service setting wins over shell defaults
+// 明示的なサービスの設定がシェルのデフォルトより優先されるよう、DB_*が常に優先される
+// precedence: 優先
+func (s *configSources) lookupWithFallback(field, dbKey, pgKey string) string {
+	// A command-line flag wins over both variables
+	// コマンドラインフラグは両方の環境変数より優先される
+	if value, flagged := s.flags[field]; flagged && (value != "" || s.ignoreEnv) {
+		source := configSourceDefault
+		if value != "" {
+			source = configSourceFlag
+		}
+		s.fields = append(s.fields, field)
+		s.sources = append(s.sources, source)
+		return value
+	}
+
+	source := configSourceDefault
+	value := os.Getenv(dbKey)
+	if value != "" {
+		source = dbKey
+	} else if value = os.Getenv(pgKey); value != "" {
+		source = pgKey
+	}
+	s.fields = append(s.fields, field)
+	s.sources = append(s.sources, source)
+	return value
+}
+
+// override replaces the recorded source of field
+// override: fieldの記録済みの取得元を置き換える関数
+func (s *configSources) override(field, source string) {
+	for i, recorded := range s.fields {
+		if recorded == field {
+			s.sources[i] = source
+		}
+	}
+}
+
+// String lists field=source pairs for the debug log
+// String: デバッグログ用にfield=sourceの組を列挙する関数
+func (s *configSources) String() string {
+	pairs := make([]string, len(s.fields))
+	for i, field := range s.fields {
+		pairs[i] = field + "=" + s.sources[i]
+	}
+	return strings.Join(pairs, " ")
+}
+
+// debugEnabled reports whether DB_DEBUG turns on debug logging
+// debugEnabled: DB_DEBUGでデバッグログが有効かどうかを判定する関数
+func debugEnabled() bool {
+	enabled, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv("DB_DEBUG")))
+	return enabled
+}
+
+// debugf logs a debug message when DB_DEBUG is true
+// debugf: DB_DEBUGがtrueの場合にデバッグメッセージをログ出力する関数
+func debugf(format string, args ...interface{}) {
+	if debugEnabled() {
+		log.Printf("Debug: "+format, args...)
+	}
+}
+
+// requiredEnvError reports a missing required variable with both accepted names
+// requiredEnvError: 必須の環境変数が未設定であることを、受け付ける両方の名前と共に報告する関数
+func requiredEnvError(dbKey, pgKey string) error {
+	return fmt.Errorf("%s (or %s) environment variable is required", dbKey, pgKey) // required: 必要な
+}
+
+// requiredError reports a missing required field, naming its flag too when flags were registered
+// requiredError: 必須のフィールドが未設定であることを報告する関数、フラグが登録されていればフラグ名も示す
+func (s *configSources) requiredError(field, dbKey, pgKey string) error {
+	if _, flagged := s.flags[field]; !flagged {
+		return requiredEnvError(dbKey, pgKey)
+	}
+	if s.ignoreEnv {
+		return fmt.Errorf("--%s flag is required", configFlagNames[field])
+	}
+	return fmt.Errorf("--%s flag or %s (or %s) environment variable is required", configFlagNames[field], dbKey, pgKey)
+}
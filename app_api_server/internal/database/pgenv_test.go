@@ -0,0 +1,122 @@
+package database
+
+import (
+	"strings" // strings: 文字列操作
+	"testing" // testing: テスト機能
+)
+
+// connectionEnvKeys lists the DB_* and PG* variables the fallback tests control
+// connectionEnvKeys: フォールバックのテストで制御するDB_*とPG*の環境変数の一覧
+var connectionEnvKeys = []string{
+	"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME", "DB_SSL_MODE",
+	"PGHOST", "PGPORT", "PGUSER", "PGPASSWORD", "PGDATABASE", "PGSSLMODE",
+}
+
+// TestLoadDatabaseConfigPGFallback tests precedence between DB_* and PG* variables and mixed sources
+// TestLoadDatabaseConfigPGFallback: DB_*とPG*の環境変数の優先順位と取得元の混在をテストする関数
+func TestLoadDatabaseConfigPGFallback(t *testing.T) {
+	testCases := []struct {
+		name            string
+		env             map[string]string
+		expected        DatabaseConfig
+		expectedSources string
+	}{
+		{
+			name: "PG variables only",
+			env: map[string]string{
+				"PGHOST": "pg-host", "PGPORT": "6432", "PGUSER": "pg-user", "PGPASSWORD": "pg-pass", "PGDATABASE": "pg-db", "PGSSLMODE": "verify-full",
+			},
+			expected:        DatabaseConfig{Host: "pg-host", Port: 6432, User: "pg-user", Password: "pg-pass", Database: "pg-db", SSLMode: "verify-full"},
+			expectedSources: "host=PGHOST port=PGPORT user=PGUSER password=PGPASSWORD database=PGDATABASE sslmode=PGSSLMODE",
+		},
+		{
+			name: "DB variables take precedence",
+			env: map[string]string{
+				"DB_HOST": "db-host", "DB_PORT": "5433", "DB_USER": "db-user", "DB_PASSWORD": "db-pass", "DB_NAME": "db-db", "DB_SSL_MODE": "disable",
+				"PGHOST": "pg-host", "PGPORT": "6432", "PGUSER": "pg-user", "PGPASSWORD": "pg-pass", "PGDATABASE": "pg-db", "PGSSLMODE": "verify-full",
+			},
+			expected:        DatabaseConfig{Host: "db-host", Port: 5433, User: "db-user", Password: "db-pass", Database: "db-db", SSLMode: "disable"},
+			expectedSources: "host=DB_HOST port=DB_PORT user=DB_USER password=DB_PASSWORD database=DB_NAME sslmode=DB_SSL_MODE",
+		},
+		{
+			name: "Mixed sources and defaults",
+			env: map[string]string{
+				"DB_USER": "db-user", "PGUSER": "pg-user", "PGPASSWORD": "pg-pass", "DB_NAME": "db-db", "PGHOST": "pg-host",
+			},
+			expected:        DatabaseConfig{Host: "pg-host", Port: 5432, User: "db-user", Password: "pg-pass", Database: "db-db", SSLMode: "require"},
+			expectedSources: "host=PGHOST port=default user=DB_USER password=PGPASSWORD database=DB_NAME sslmode=default",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, key := range connectionEnvKeys {
+				t.Setenv(key, tc.env[key])
+			}
+			t.Setenv("DB_DEBUG", "true")
+			logs := captureLog(t)
+
+			config, err := LoadDatabaseConfig()
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if config.Host != tc.expected.Host || config.Port != tc.expected.Port || config.User != tc.expected.User ||
+				config.Password != tc.expected.Password || config.Database != tc.expected.Database || config.SSLMode != tc.expected.SSLMode {
+				t.Errorf("Expected %v, got: %v", tc.expected, config)
+			}
+			if !strings.Contains(logs.String(), "Debug: Database configuration sources: "+tc.expectedSources) {
+				t.Errorf("Expected sources '%s' to be logged, got: %s", tc.expectedSources, logs.String())
+			}
+			if strings.Contains(logs.String(), "pg-pass") || strings.Contains(logs.String(), "db-pass") {
+				t.Errorf("Expected no password values in the log, got: %s", logs.String())
+			}
+		})
+	}
+}
+
+// TestLoadDatabaseConfigRequiredNames tests that required-variable errors name both accepted variables
+// TestLoadDatabaseConfigRequiredNames: 必須の環境変数のエラーが受け付ける両方の名前を含むことをテストする関数
+func TestLoadDatabaseConfigRequiredNames(t *testing.T) {
+	testCases := []struct {
+		name         string
+		env          map[string]string
+		errorContent string
+	}{
+		{name: "Missing user", env: map[string]string{"PGPASSWORD": "p", "PGDATABASE": "d"}, errorContent: "DB_USER (or PGUSER)"},
+		{name: "Missing password", env: map[string]string{"PGUSER": "u", "PGDATABASE": "d"}, errorContent: "DB_PASSWORD (or PGPASSWORD)"},
+		{name: "Missing database", env: map[string]string{"PGUSER": "u", "PGPASSWORD": "p"}, errorContent: "DB_NAME (or PGDATABASE)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, key := range connectionEnvKeys {
+				t.Setenv(key, tc.env[key])
+			}
+
+			_, err := LoadDatabaseConfig()
+			if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+				t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+			}
+		})
+	}
+}
+
+// TestDebugLogDisabledByDefault tests that the source log is only written when DB_DEBUG is set
+// TestDebugLogDisabledByDefault: DB_DEBUGが設定された場合のみ取得元のログが出力されることをテストする関数
+func TestDebugLogDisabledByDefault(t *testing.T) {
+	for _, key := range connectionEnvKeys {
+		t.Setenv(key, "")
+	}
+	t.Setenv("DB_DEBUG", "")
+	t.Setenv("PGUSER", "u")
+	t.Setenv("PGPASSWORD", "p")
+	t.Setenv("PGDATABASE", "d")
+	logs := captureLog(t)
+
+	if _, err := LoadDatabaseConfig(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if strings.Contains(logs.String(), "Debug:") {
+		t.Errorf("Expected no debug log, got: %s", logs.String())
+	}
+}
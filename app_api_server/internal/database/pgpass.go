@@ -0,0 +1,139 @@
+package database
+
+import (
+	"bufio"         // bufio: 行単位の読み込み
+	"errors"        // errors: エラー操作
+	"fmt"           // fmt: format（フォーマット）、文字列フォーマット機能
+	"os"            // os: operating system（オペレーティングシステム）、ファイルと環境変数
+	"path/filepath" // filepath: ファイルパス操作
+	"strconv"       // strconv: string conversion（文字列変換）
+	"strings"       // strings: 文字列操作
+)
+
+// errPgpassNoMatch is returned when no line of the pgpass file matches the connection
+// errPgpassNoMatch: pgpassファイルのどの行も接続に一致しない場合のエラー
+var errPgpassNoMatch = errors.New("no matching entry")
+
+// pgpassEnabled reports whether DB_USE_PGPASS opts in to reading the pgpass file
+// pgpassEnabled: DB_USE_PGPASSでpgpassファイルの読み込みが有効かどうかを判定する関数
+func pgpassEnabled() (bool, error) {
+	value := strings.TrimSpace(os.Getenv("DB_USE_PGPASS"))
+	if value == "" {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid DB_USE_PGPASS: %q is not a boolean", value)
+	}
+	return enabled, nil
+}
+
+// pgpassPath returns PGPASSFILE, or ~/.pgpass as libpq does
+// pgpassPath: PGPASSFILE、またはlibpqと同じく~/.pgpassを返す関数
+func pgpassPath() (string, error) {
+	if path := os.Getenv("PGPASSFILE"); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate the pgpass file: %w", err) // locate: 場所を特定する
+	}
+	return filepath.Join(home, ".pgpass"), nil
+}
+
+// lookupPgpass returns the password of the first pgpass line matching one of config's hosts, its port, database and user
+// lookupPgpass: configのホスト（いずれか）、ポート、データベース、ユーザーに一致する最初のpgpassの行のパスワードを返す関数
+// Like libpq, a file with any group or world access is refused, and * matches any value in the first four fields.
+// A missing file wraps fs.ErrNotExist and no matching line returns errPgpassNoMatch
+// libpqと同じく、グループや他のユーザーがアクセスできるファイルは拒否し、最初の4つのフィールドの*は任意の値に一致する
+// ファイルが存在しない場合はfs.ErrNotExistを包んだエラー、一致する行がない場合はerrPgpassNoMatchを返す
+func lookupPgpass(path string, config *DatabaseConfig) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("pgpass file %s: %w", path, err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("pgpass file %s is not a regular file", path)
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return "", fmt.Errorf("pgpass file %s has group or world access (%s); permissions should be u=rw (0600) or less", path, info.Mode().Perm())
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("pgpass file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	hosts := config.hosts()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields, ok := parsePgpassLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		for i, host := range hosts {
+			if pgpassMatches(fields, pgpassHost(host), strconv.Itoa(config.hostPort(i)), config.Database, config.User) {
+				return fields[4], nil
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read pgpass file %s: %w", path, err)
+	}
+	return "", fmt.Errorf("pgpass file %s: %w for user %s", path, errPgpassNoMatch, config.User)
+}
+
+// pgpassHost returns the host name libpq matches: unix socket directories match localhost
+// pgpassHost: libpqが照合するホスト名を返す関数、UNIXソケットのディレクトリはlocalhostとして照合する
+func pgpassHost(host string) string {
+	if strings.HasPrefix(host, "/") {
+		return "localhost"
+	}
+	if bare, ok := ipv6Literal(host); ok {
+		return bare
+	}
+	return host
+}
+
+// parsePgpassLine splits hostname:port:database:username:password, honoring \: and \\ escapes
+// parsePgpassLine: \:と\\のエスケープを考慮してhostname:port:database:username:passwordに分割する関数
+// Comments, blank lines and lines with fewer than five fields are skipped, as in libpq
+// libpqと同じく、コメント、空行、5つ未満のフィールドの行は読み飛ばす
+func parsePgpassLine(line string) ([]string, bool) {
+	if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+		return nil, false
+	}
+
+	var fields []string
+	var current strings.Builder
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line):
+			i++
+			current.WriteByte(line[i])
+		case c == ':' && len(fields) < 4:
+			fields = append(fields, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	fields = append(fields, current.String())
+	if len(fields) != 5 {
+		return nil, false
+	}
+	return fields, true
+}
+
+// pgpassMatches compares the first four fields, where * matches anything
+// pgpassMatches: 最初の4つのフィールドを比較する関数、*は任意の値に一致する
+func pgpassMatches(fields []string, host, port, database, user string) bool {
+	for i, value := range []string{host, port, database, user} {
+		if fields[i] != "*" && fields[i] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"errors"        // errors: エラー操作
+	"io/fs"         // fs: ファイル不存在エラーの判定
+	"os"            // os: ファイル操作
+	"path/filepath" // filepath: ファイルパス操作
+	"strings"       // strings: 文字列操作
+	"testing"       // testing: テスト機能
+)
+
+// writePgpass writes a pgpass fixture with the given permissions and returns its path
+// writePgpass: 指定した権限でpgpassのフィクスチャを書き込み、そのパスを返す関数
+func writePgpass(t *testing.T, content string, perm os.FileMode) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "pgpass")
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := os.Chmod(path, perm); err != nil { // not masked by umask: umaskの影響を受けない
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	return path
+}
+
+// TestLookupPgpass tests line matching, wildcards, escapes and permission checks
+// TestLookupPgpass: 行の照合、ワイルドカード、エスケープ、権限の確認をテストする関数
+func TestLookupPgpass(t *testing.T) {
+	const fixture = `# comment line
+db.example.com:5432:app:alice:exact
+*:5432:app:bob:any-host
+db.example.com:*:*:carol:any-port-and-db
+localhost:5432:app:dave:socket
+weird\:host:5432:app:erin:pa\:ss\\word
+incomplete:line
+*:*:*:*:fallback
+db.example.com:5432:app:alice:shadowed
+`
+	testCases := []struct {
+		name        string
+		config      DatabaseConfig
+		perm        os.FileMode
+		expected    string
+		expectError bool
+	}{
+		{name: "Exact match", config: DatabaseConfig{Host: "db.example.com", Port: 5432, Database: "app", User: "alice"}, perm: 0o600, expected: "exact"},
+		{name: "Wildcard host", config: DatabaseConfig{Host: "other", Port: 5432, Database: "app", User: "bob"}, perm: 0o600, expected: "any-host"},
+		{name: "Wildcard port and database", config: DatabaseConfig{Host: "db.example.com", Port: 6432, Database: "reports", User: "carol"}, perm: 0o600, expected: "any-port-and-db"},
+		{name: "Unix socket matches localhost", config: DatabaseConfig{Host: "/var/run/postgresql", Port: 5432, Database: "app", User: "dave"}, perm: 0o600, expected: "socket"},
+		{name: "Escaped colons and backslashes", config: DatabaseConfig{Host: "weird:host", Port: 5432, Database: "app", User: "erin"}, perm: 0o600, expected: `pa:ss\word`},
+		{name: "Catch-all line", config: DatabaseConfig{Host: "db.example.com", Port: 5432, Database: "app", User: "frank"}, perm: 0o600, expected: "fallback"},
+		{name: "Second listed host", config: DatabaseConfig{Host: "db-a,db.example.com", Ports: []int{5433, 5432}, Database: "app", User: "alice"}, perm: 0o600, expected: "exact"},
+		{name: "Owner read-only is accepted", config: DatabaseConfig{Host: "db.example.com", Port: 5432, Database: "app", User: "alice"}, perm: 0o400, expected: "exact"},
+		{name: "World-readable file is refused", config: DatabaseConfig{Host: "db.example.com", Port: 5432, Database: "app", User: "alice"}, perm: 0o644, expectError: true},
+		{name: "Group-readable file is refused", config: DatabaseConfig{Host: "db.example.com", Port: 5432, Database: "app", User: "alice"}, perm: 0o640, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writePgpass(t, fixture, tc.perm)
+
+			password, err := lookupPgpass(path, &tc.config)
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), "group or world access") {
+					t.Errorf("Expected a permission error, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if password != tc.expected {
+				t.Errorf("Expected password '%s', got: %s", tc.expected, password)
+			}
+		})
+	}
+}
+
+// TestLookupPgpassMissing tests the errors for an absent file and a file without a matching line
+// TestLookupPgpassMissing: ファイルが存在しない場合と一致する行がない場合のエラーをテストする関数
+func TestLookupPgpassMissing(t *testing.T) {
+	config := &DatabaseConfig{Host: "db", Port: 5432, Database: "app", User: "alice"}
+
+	_, err := lookupPgpass(filepath.Join(t.TempDir(), "missing"), config)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist, got: %v", err)
+	}
+
+	path := writePgpass(t, "other:5432:app:bob:secret\n", 0o600)
+	_, err = lookupPgpass(path, config)
+	if !errors.Is(err, errPgpassNoMatch) {
+		t.Errorf("Expected errPgpassNoMatch, got: %v", err)
+	}
+}
+
+// TestLoadDatabaseConfigPgpass tests precedence between variables and the pgpass file in LoadDatabaseConfig
+// TestLoadDatabaseConfigPgpass: LoadDatabaseConfigでの環境変数とpgpassファイルの優先順位をテストする関数
+func TestLoadDatabaseConfigPgpass(t *testing.T) {
+	testCases := []struct {
+		name         string
+		env          map[string]string
+		noFile       bool // no file: pgpassファイルを作成しない
+		expected     string
+		expectError  bool
+		errorContent string
+	}{
+		{name: "Used when opted in", env: map[string]string{"DB_USE_PGPASS": "true"}, expected: "from-pgpass"},
+		{name: "DB_PASSWORD wins", env: map[string]string{"DB_USE_PGPASS": "true", "DB_PASSWORD": "from-env"}, expected: "from-env"},
+		{name: "PGPASSWORD wins", env: map[string]string{"DB_USE_PGPASS": "true", "PGPASSWORD": "from-pg"}, expected: "from-pg"},
+		{name: "Not read without opting in", env: map[string]string{}, expectError: true, errorContent: "DB_PASSWORD (or PGPASSWORD) environment variable is required"},
+		{name: "Absent file", env: map[string]string{"DB_USE_PGPASS": "true"}, noFile: true, expectError: true, errorContent: "no such file"},
+		{name: "Invalid opt-in value", env: map[string]string{"DB_USE_PGPASS": "maybe"}, expectError: true, errorContent: "invalid DB_USE_PGPASS"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, key := range connectionEnvKeys {
+				t.Setenv(key, tc.env[key])
+			}
+			t.Setenv("DB_USE_PGPASS", tc.env["DB_USE_PGPASS"])
+			t.Setenv("DB_HOST", "db.example.com")
+			t.Setenv("DB_USER", "alice")
+			t.Setenv("DB_NAME", "app")
+
+			path := filepath.Join(t.TempDir(), "missing")
+			if !tc.noFile {
+				path = writePgpass(t, "db.example.com:5432:app:alice:from-pgpass\n", 0o600)
+			}
+			t.Setenv("PGPASSFILE", path)
+
+			config, err := LoadDatabaseConfig()
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if config.Password != tc.expected {
+				t.Errorf("Expected password '%s', got: %s", tc.expected, config.Password)
+			}
+		})
+	}
+}
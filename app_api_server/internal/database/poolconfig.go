@@ -0,0 +1,61 @@
+package database
+
+import (
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"strings" // strings: 文字列操作
+	"time"    // time: 時間操作機能
+)
+
+// PoolConfig holds the connection pool settings that can be changed at run time
+// PoolConfig: 実行時に変更できる接続プールの設定を表す構造体
+// Zero values mean the same defaults as in DatabaseConfig: ゼロ値はDatabaseConfigと同じ既定値を意味する
+type PoolConfig struct {
+	MaxOpenConns    int           // maximum open: 最大接続数（0は既定値の25）
+	MaxIdleConns    int           // maximum idle: 最大アイドル数（0は既定値の5、最大接続数以下）
+	ConnMaxLifetime time.Duration // lifetime: 接続の寿命（0は既定値の5分）
+	ConnMaxIdleTime time.Duration // idle time: 最大アイドル時間（0は無制限）
+}
+
+// SetPoolConfig validates pool and applies it to the live pool, for example to shrink the pool during an incident
+// SetPoolConfig: poolを検証して稼働中のプールに適用する関数（障害時にプールを縮小する場合など）
+// incident: 障害
+// The stored configuration is updated too, so Reconnect keeps the change; ReloadConfig replaces it with the reloaded values
+// 保存された設定も更新するためReconnect後も変更は維持される、ReloadConfigは再読み込みした値で置き換える
+func (d *PostgreSQLDriver) SetPoolConfig(pool PoolConfig) error {
+	d.reloadMu.Lock()
+	defer d.reloadMu.Unlock()
+
+	active := d.GetConfig()
+	updated := cloneConfig(active)
+	updated.MaxOpenConns = pool.MaxOpenConns
+	updated.MaxIdleConns = pool.MaxIdleConns
+	updated.ConnMaxLifetime = pool.ConnMaxLifetime
+	updated.ConnMaxIdleTime = pool.ConnMaxIdleTime
+	updated.applyDefaults()
+	if err := validatePoolSettings(&updated); err != nil {
+		return fmt.Errorf("invalid pool configuration: %w", err)
+	}
+
+	changes := diffConfig(active, &updated).pool
+	if len(changes) == 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	if db := d.pool(); db != nil {
+		applyPoolSettings(db, &updated)
+	}
+	d.config.Store(&updated)
+	if d.pendingConfig != nil {
+		// Keep a staged configuration from reverting the change
+		// 準備済みの設定で変更が元に戻らないようにする
+		d.pendingConfig.MaxOpenConns = updated.MaxOpenConns
+		d.pendingConfig.MaxIdleConns = updated.MaxIdleConns
+		d.pendingConfig.ConnMaxLifetime = updated.ConnMaxLifetime
+		d.pendingConfig.ConnMaxIdleTime = updated.ConnMaxIdleTime
+	}
+	d.mu.Unlock()
+
+	d.logger.Info(fmt.Sprintf("Database pool settings changed: %s", strings.Join(changes, ", ")), "changes", changes)
+	return nil
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"strings" // strings: 文字列操作
+	"testing" // testing: テスト機能
+	"time"    // time: 時間操作機能
+)
+
+// TestSetPoolConfigValidation tests that invalid pool settings are rejected and leave the configuration unchanged
+// TestSetPoolConfigValidation: 無効なプール設定が拒否され、設定が変更されないことをテストする関数
+func TestSetPoolConfigValidation(t *testing.T) {
+	testCases := []struct {
+		name         string
+		pool         PoolConfig
+		errorContent string
+	}{
+		{name: "Negative max open", pool: PoolConfig{MaxOpenConns: -1}, errorContent: "cannot be negative"},
+		{name: "Negative max idle", pool: PoolConfig{MaxIdleConns: -1}, errorContent: "cannot be negative"},
+		{name: "Idle above open", pool: PoolConfig{MaxOpenConns: 5, MaxIdleConns: 10}, errorContent: "max idle connections (10) cannot exceed max open connections (5)"},
+		{name: "Idle above the default open", pool: PoolConfig{MaxIdleConns: 30}, errorContent: "cannot exceed max open connections (25)"},
+		{name: "Negative lifetime", pool: PoolConfig{ConnMaxLifetime: -time.Second}, errorContent: "max lifetime cannot be negative"},
+		{name: "Negative idle time", pool: PoolConfig{ConnMaxIdleTime: -time.Second}, errorContent: "max idle time cannot be negative"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver := newFakeConnectingDriver(t)
+
+			err := driver.SetPoolConfig(tc.pool)
+			if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+				t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+			}
+			if stats := driver.GetConnectionStats(); stats.ConfiguredMaxOpen != defaultMaxOpenConns || stats.ConfiguredMaxIdle != defaultMaxIdleConns {
+				t.Errorf("Expected the default pool to be kept, got: %+v", stats)
+			}
+		})
+	}
+}
+
+// TestSetPoolConfig tests that the live pool, the stats and a later Reconnect all use the new settings
+// TestSetPoolConfig: 稼働中のプール、統計、その後のReconnectが新しい設定を使うことをテストする関数
+func TestSetPoolConfig(t *testing.T) {
+	driver := newFakeConnectingDriver(t)
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected no error on Connect, got: %v", err)
+	}
+	defer driver.Close()
+	logs := captureLog(t)
+
+	pool := PoolConfig{MaxOpenConns: 4, MaxIdleConns: 2, ConnMaxLifetime: time.Minute, ConnMaxIdleTime: 30 * time.Second}
+	if err := driver.SetPoolConfig(pool); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !strings.Contains(logs.String(), "MaxOpenConns 25→4") || !strings.Contains(logs.String(), "ConnMaxIdleTime 0s→30s") {
+		t.Errorf("Expected old→new values in the log, got: %s", logs.String())
+	}
+
+	check := func(when string) {
+		t.Helper()
+		stats := driver.GetConnectionStats()
+		if stats.MaxOpenConnections != 4 {
+			t.Errorf("%s: expected the live pool to allow 4 connections, got: %d", when, stats.MaxOpenConnections)
+		}
+		if stats.ConfiguredMaxOpen != 4 || stats.ConfiguredMaxIdle != 2 || stats.ConfiguredMaxLifetime != time.Minute || stats.ConfiguredMaxIdleTime != 30*time.Second {
+			t.Errorf("%s: expected the configured values to follow, got: %+v", when, stats)
+		}
+	}
+	check("after SetPoolConfig")
+
+	if err := driver.Reconnect(); err != nil {
+		t.Fatalf("Expected no error on Reconnect, got: %v", err)
+	}
+	check("after Reconnect")
+
+	// Zero values restore the defaults: ゼロ値は既定値に戻す
+	if err := driver.SetPoolConfig(PoolConfig{}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if stats := driver.GetConnectionStats(); stats.MaxOpenConnections != defaultMaxOpenConns || stats.ConfiguredMaxLifetime != defaultConnMaxLifetime {
+		t.Errorf("Expected the defaults to be restored, got: %+v", stats)
+	}
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"errors" // errors: エラー操作
+
+	"github.com/lib/pq" // pq: PostgreSQLドライバー、エラー型
+)
+
+// SQLSTATE codes classified by the helpers below
+// 以下のヘルパーで分類するSQLSTATEコード
+const (
+	sqlStateUniqueViolation      = "23505" // unique_violation: 一意制約違反
+	sqlStateForeignKeyViolation  = "23503" // foreign_key_violation: 外部キー制約違反
+	sqlStateNotNullViolation     = "23502" // not_null_violation: NOT NULL制約違反
+	sqlStateCheckViolation       = "23514" // check_violation: CHECK制約違反
+	sqlStateSerializationFailure = "40001" // serialization_failure: 直列化の失敗
+	sqlStateUndefinedTable       = "42P01" // undefined_table: テーブルが存在しない
+	sqlStateReadOnlyTransaction  = "25006" // read_only_sql_transaction: 読み取り専用トランザクションでの書き込み
+
+	sqlClassInvalidAuthorization = "28" // invalid_authorization_specification: 認証の失敗（28000、28P01）
+)
+
+// asPQError unwraps err to a *pq.Error, returning nil when there is none
+// asPQError: errを*pq.Errorに展開し、含まれない場合はnilを返す関数
+// unwraps: 展開する
+func asPQError(err error) *pq.Error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr
+	}
+	return nil
+}
+
+// hasSQLState reports whether err wraps a *pq.Error with the given SQLSTATE code
+// hasSQLState: errが指定したSQLSTATEコードの*pq.Errorを含むかどうかを判定する関数
+func hasSQLState(err error, code string) bool {
+	pqErr := asPQError(err)
+	return pqErr != nil && string(pqErr.Code) == code
+}
+
+// IsUniqueViolation reports whether err is a unique constraint violation
+// IsUniqueViolation: errが一意制約違反かどうかを判定する関数
+// unique: 一意の、constraint: 制約、violation: 違反
+func IsUniqueViolation(err error) bool {
+	return hasSQLState(err, sqlStateUniqueViolation)
+}
+
+// IsForeignKeyViolation reports whether err is a foreign key constraint violation
+// IsForeignKeyViolation: errが外部キー制約違反かどうかを判定する関数
+// foreign key: 外部キー
+func IsForeignKeyViolation(err error) bool {
+	return hasSQLState(err, sqlStateForeignKeyViolation)
+}
+
+// IsNotNullViolation reports whether err is a NOT NULL constraint violation
+// IsNotNullViolation: errがNOT NULL制約違反かどうかを判定する関数
+func IsNotNullViolation(err error) bool {
+	return hasSQLState(err, sqlStateNotNullViolation)
+}
+
+// IsCheckViolation reports whether err is a CHECK constraint violation
+// IsCheckViolation: errがCHECK制約違反かどうかを判定する関数
+func IsCheckViolation(err error) bool {
+	return hasSQLState(err, sqlStateCheckViolation)
+}
+
+// IsSerializationFailure reports whether err is a serialization failure that can be retried as a whole transaction
+// IsSerializationFailure: errがトランザクション全体を再実行できる直列化の失敗かどうかを判定する関数
+// serialization: 直列化、failure: 失敗
+func IsSerializationFailure(err error) bool {
+	return hasSQLState(err, sqlStateSerializationFailure)
+}
+
+// IsReadOnlyViolation reports whether err is a write attempted in a read-only transaction
+// IsReadOnlyViolation: errが読み取り専用トランザクションでの書き込みの試みかどうかを判定する関数
+// The server reports it as "cannot execute ... in a read-only transaction": サーバーは"cannot execute ... in a read-only transaction"と報告する
+func IsReadOnlyViolation(err error) bool {
+	return hasSQLState(err, sqlStateReadOnlyTransaction)
+}
+
+// IsAuthenticationError reports whether err is a rejected login, such as a wrong password or a missing pg_hba.conf entry
+// IsAuthenticationError: errがパスワード誤りやpg_hba.confのエントリ不足などのログイン拒否かどうかを判定する関数
+// Retrying does not help until the credentials or the server configuration change
+// 認証情報かサーバーの設定が変わるまで再試行しても解決しない
+func IsAuthenticationError(err error) bool {
+	pqErr := asPQError(err)
+	return pqErr != nil && string(pqErr.Code.Class()) == sqlClassInvalidAuthorization
+}
+
+// ConstraintName returns the name of the constraint that fired, or "" when unknown
+// ConstraintName: 違反した制約の名前を返す関数、不明な場合は空文字列
+// fired: 発動した
+func ConstraintName(err error) string {
+	if pqErr := asPQError(err); pqErr != nil {
+		return pqErr.Constraint
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"errors"  // errors: エラー操作
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"testing" // testing: テスト機能
+
+	"github.com/lib/pq" // pq: PostgreSQLドライバー、エラー型
+)
+
+// TestPQErrorClassification tests the SQLSTATE helpers against constructed errors
+// TestPQErrorClassification: 生成したエラーでSQLSTATEヘルパーをテストする関数
+// constructed: 生成した
+func TestPQErrorClassification(t *testing.T) {
+	unique := &pq.Error{Code: "23505", Constraint: "users_email_key"}
+
+	helpers := map[string]func(error) bool{
+		"unique":        IsUniqueViolation,
+		"foreign_key":   IsForeignKeyViolation,
+		"not_null":      IsNotNullViolation,
+		"check":         IsCheckViolation,
+		"serialization": IsSerializationFailure,
+		"read_only":     IsReadOnlyViolation,
+		"auth":          IsAuthenticationError,
+	}
+
+	testCases := []struct {
+		name       string
+		err        error
+		expected   string // expected: trueを返すべきヘルパー、空なら全てfalse
+		constraint string
+	}{
+		{name: "Nil error", err: nil},
+		{name: "Non-pq error", err: errors.New("duplicate key value violates unique constraint")},
+		{name: "Unique violation", err: unique, expected: "unique", constraint: "users_email_key"},
+		{name: "Wrapped unique violation", err: fmt.Errorf("create user: %w", unique), expected: "unique", constraint: "users_email_key"},
+		{name: "Double wrapped unique violation", err: fmt.Errorf("handler: %w", fmt.Errorf("repo: %w", unique)), expected: "unique", constraint: "users_email_key"},
+		{name: "Foreign key violation", err: &pq.Error{Code: "23503", Constraint: "shifts_user_id_fkey"}, expected: "foreign_key", constraint: "shifts_user_id_fkey"},
+		{name: "Not null violation", err: &pq.Error{Code: "23502"}, expected: "not_null"},
+		{name: "Check violation", err: &pq.Error{Code: "23514", Constraint: "shifts_time_check"}, expected: "check", constraint: "shifts_time_check"},
+		{name: "Serialization failure", err: &pq.Error{Code: "40001"}, expected: "serialization"},
+		{name: "Read-only violation", err: fmt.Errorf("insert: %w", &pq.Error{Code: "25006", Message: "cannot execute INSERT in a read-only transaction"}), expected: "read_only"},
+		{name: "Password authentication failed", err: fmt.Errorf("connect: %w", &pq.Error{Code: "28P01"}), expected: "auth"},
+		{name: "No pg_hba.conf entry", err: &pq.Error{Code: "28000"}, expected: "auth"},
+		{name: "Other pq error", err: &pq.Error{Code: "42P01"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for name, helper := range helpers {
+				if got := helper(tc.err); got != (name == tc.expected) {
+					t.Errorf("Expected %s helper to return %v, got: %v", name, name == tc.expected, got)
+				}
+			}
+
+			if got := ConstraintName(tc.err); got != tc.constraint {
+				t.Errorf("Expected constraint '%s', got: '%s'", tc.constraint, got)
+			}
+		})
+	}
+}
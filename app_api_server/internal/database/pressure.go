@@ -0,0 +1,116 @@
+package database
+
+import (
+	"fmt"  // fmt: format（フォーマット）、文字列フォーマット機能
+	"time" // time: 時間操作機能
+)
+
+// defaultPoolSaturationDuration is how long InUse may sit at the pool limit before warning
+// defaultPoolSaturationDuration: 警告までに使用中接続数が上限に留まってよい時間のデフォルト値
+const defaultPoolSaturationDuration = 10 * time.Second
+
+// WithPoolPressureHook registers a callback invoked once each time pool pressure starts
+// WithPoolPressureHook: プール逼迫が始まるたびに1回呼ばれるコールバックを登録するオプション
+// pressure: 圧力、逼迫
+func WithPoolPressureHook(hook func(ConnectionStats)) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		d.pressureHook = hook
+	}
+}
+
+// WithPoolSaturationDuration sets how long the pool may stay fully in use before it counts as pressure
+// WithPoolSaturationDuration: 全接続使用中の状態が逼迫と見なされるまでの時間を設定するオプション
+// saturation: 飽和
+func WithPoolSaturationDuration(duration time.Duration) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		if duration > 0 {
+			d.pressure.saturationDuration = duration
+		}
+	}
+}
+
+// pressureTransition describes a change in pool pressure state
+// pressureTransition: プール逼迫状態の変化を表す型
+// transition: 遷移
+type pressureTransition int
+
+const (
+	pressureUnchanged pressureTransition = iota // unchanged: 変化なし
+	pressureStarted                             // started: 逼迫開始
+	pressureCleared                             // cleared: 逼迫解消
+)
+
+// poolPressureDetector tracks pool pressure across checks with hysteresis
+// poolPressureDetector: ヒステリシス付きでチェック間のプール逼迫を追跡する構造体
+// hysteresis: ヒステリシス、開始時と解消時にのみ通知する
+type poolPressureDetector struct {
+	saturationDuration time.Duration // saturation duration: 飽和とみなす継続時間
+
+	initialized    bool      // initialized: 初回チェック済み
+	lastWaitCount  int64     // last wait count: 前回の待機回数
+	saturatedSince time.Time // saturated since: 上限到達の開始時刻
+	underPressure  bool      // under pressure: 逼迫中
+}
+
+// observe evaluates a stats snapshot and reports whether pressure started or cleared
+// observe: 統計スナップショットを評価し、逼迫の開始または解消を報告する関数
+// evaluates: 評価する
+func (p *poolPressureDetector) observe(stats ConnectionStats, now time.Time) (pressureTransition, string) {
+	// WaitCount growth between checks; a drop means the pool was replaced
+	// 待機回数の増加を確認、減少はプールの再作成を意味する
+	waitGrew := p.initialized && stats.WaitCount > p.lastWaitCount
+	p.lastWaitCount = stats.WaitCount
+	p.initialized = true
+
+	// Sustained saturation: InUse at the limit for longer than saturationDuration
+	// 継続的な飽和: 使用中接続数が一定時間以上上限に達している
+	saturated := stats.MaxOpenConnections > 0 && stats.InUse >= stats.MaxOpenConnections
+	if !saturated {
+		p.saturatedSince = time.Time{}
+	} else if p.saturatedSince.IsZero() {
+		p.saturatedSince = now
+	}
+	sustained := saturated && now.Sub(p.saturatedSince) >= p.saturationDuration
+
+	reason := ""
+	switch {
+	case waitGrew:
+		reason = "callers waited for a free connection"
+	case sustained:
+		reason = "all connections in use for " + now.Sub(p.saturatedSince).String()
+	}
+
+	pressure := reason != ""
+	switch {
+	case pressure && !p.underPressure:
+		p.underPressure = true
+		return pressureStarted, reason
+	case !pressure && p.underPressure:
+		p.underPressure = false
+		return pressureCleared, ""
+	}
+	return pressureUnchanged, ""
+}
+
+// checkPoolPressure runs the detector against current stats, logging and notifying on transitions
+// checkPoolPressure: 現在の統計で検出器を実行し、状態遷移時にログ出力と通知を行う関数
+func (d *PostgreSQLDriver) checkPoolPressure() {
+	stats := d.GetConnectionStats()
+
+	d.mu.Lock()
+	transition, reason := d.pressure.observe(stats, d.now())
+	hook := d.pressureHook
+	d.mu.Unlock()
+
+	switch transition {
+	case pressureStarted:
+		d.logger.Warn(fmt.Sprintf("connection pool under pressure (%s): in_use=%d/%d wait_count=%d wait_duration=%s",
+			reason, stats.InUse, stats.MaxOpenConnections, stats.WaitCount, stats.WaitDuration),
+			"reason", reason, "in_use", stats.InUse, "max_open", stats.MaxOpenConnections, "wait_count", stats.WaitCount, "wait_duration", stats.WaitDuration)
+		if hook != nil {
+			hook(stats)
+		}
+	case pressureCleared:
+		d.logger.Info(fmt.Sprintf("Connection pool pressure cleared: in_use=%d/%d", stats.InUse, stats.MaxOpenConnections), "in_use", stats.InUse, "max_open", stats.MaxOpenConnections) // cleared: 解消した
+	}
+}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context" // context: コンテキスト
+	"strings" // strings: 文字列操作
+	"testing" // testing: テスト機能
+	"time"    // time: 時間操作機能
+)
+
+// TestPoolPressureDetector drives the detector with synthetic snapshots
+// TestPoolPressureDetector: 合成したスナップショットで検出器を動かすテスト関数
+// synthetic: 合成の
+func TestPoolPressureDetector(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+
+	type step struct {
+		stats    ConnectionStats
+		at       time.Time
+		expected pressureTransition
+	}
+
+	testCases := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "Existing wait count on first check is not pressure",
+			steps: []step{
+				{stats: ConnectionStats{MaxOpenConnections: 5, WaitCount: 40}, at: at(0), expected: pressureUnchanged},
+			},
+		},
+		{
+			name: "Wait growth starts and clears once",
+			steps: []step{
+				{stats: ConnectionStats{MaxOpenConnections: 5, WaitCount: 0}, at: at(0), expected: pressureUnchanged},
+				{stats: ConnectionStats{MaxOpenConnections: 5, WaitCount: 3}, at: at(1), expected: pressureStarted},
+				{stats: ConnectionStats{MaxOpenConnections: 5, WaitCount: 7}, at: at(2), expected: pressureUnchanged},
+				{stats: ConnectionStats{MaxOpenConnections: 5, WaitCount: 7}, at: at(3), expected: pressureCleared},
+				{stats: ConnectionStats{MaxOpenConnections: 5, WaitCount: 7}, at: at(4), expected: pressureUnchanged},
+			},
+		},
+		{
+			name: "Short saturation is tolerated",
+			steps: []step{
+				{stats: ConnectionStats{MaxOpenConnections: 5, InUse: 5}, at: at(0), expected: pressureUnchanged},
+				{stats: ConnectionStats{MaxOpenConnections: 5, InUse: 5}, at: at(5), expected: pressureUnchanged},
+				{stats: ConnectionStats{MaxOpenConnections: 5, InUse: 2}, at: at(6), expected: pressureUnchanged},
+				{stats: ConnectionStats{MaxOpenConnections: 5, InUse: 5}, at: at(12), expected: pressureUnchanged},
+			},
+		},
+		{
+			name: "Sustained saturation starts and clears once",
+			steps: []step{
+				{stats: ConnectionStats{MaxOpenConnections: 5, InUse: 5}, at: at(0), expected: pressureUnchanged},
+				{stats: ConnectionStats{MaxOpenConnections: 5, InUse: 5}, at: at(10), expected: pressureStarted},
+				{stats: ConnectionStats{MaxOpenConnections: 5, InUse: 5}, at: at(20), expected: pressureUnchanged},
+				{stats: ConnectionStats{MaxOpenConnections: 5, InUse: 1}, at: at(21), expected: pressureCleared},
+			},
+		},
+		{
+			name: "Wait count reset after reconnect is not pressure",
+			steps: []step{
+				{stats: ConnectionStats{MaxOpenConnections: 5, WaitCount: 10}, at: at(0), expected: pressureUnchanged},
+				{stats: ConnectionStats{MaxOpenConnections: 5, WaitCount: 0}, at: at(1), expected: pressureUnchanged},
+			},
+		},
+		{
+			name: "Unlimited pool is never saturated",
+			steps: []step{
+				{stats: ConnectionStats{MaxOpenConnections: 0, InUse: 100}, at: at(0), expected: pressureUnchanged},
+				{stats: ConnectionStats{MaxOpenConnections: 0, InUse: 100}, at: at(60), expected: pressureUnchanged},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			detector := poolPressureDetector{saturationDuration: 10 * time.Second}
+			for i, s := range tc.steps {
+				transition, _ := detector.observe(s.stats, s.at)
+				if transition != s.expected {
+					t.Errorf("Step %d: expected transition %d, got: %d", i, s.expected, transition)
+				}
+			}
+		})
+	}
+}
+
+// TestCheckPoolPressureHook tests the warning log and hook on a saturated fake pool
+// TestCheckPoolPressureHook: 飽和したフェイクプールでの警告ログとフックをテストする関数
+func TestCheckPoolPressureHook(t *testing.T) {
+	logs := captureLog(t)
+
+	var hookCalls []ConnectionStats
+	driver, _ := newTestDriver(t)
+	WithPoolPressureHook(func(stats ConnectionStats) { hookCalls = append(hookCalls, stats) })(driver)
+	WithPoolSaturationDuration(time.Second)(driver)
+	driver.now = fakeClock(time.Second)
+
+	// Hold the only connection to saturate the pool
+	// hold: 保持する、saturate: 飽和させる
+	driver.db.SetMaxOpenConns(1)
+	conn, err := driver.db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to acquire connection: %v", err)
+	}
+
+	driver.checkPoolPressure() // saturation begins: 飽和開始
+	driver.checkPoolPressure() // sustained for 1s: 1秒継続
+	driver.checkPoolPressure() // still saturated: 継続中
+
+	if len(hookCalls) != 1 {
+		t.Fatalf("Expected hook to be called once, got: %d", len(hookCalls))
+	}
+	if hookCalls[0].InUse != 1 || hookCalls[0].MaxOpenConnections != 1 {
+		t.Errorf("Expected saturated stats in hook, got: %+v", hookCalls[0])
+	}
+	if count := strings.Count(logs.String(), "under pressure"); count != 1 {
+		t.Errorf("Expected one pressure warning, got %d: %q", count, logs.String())
+	}
+
+	conn.Close()
+	driver.checkPoolPressure()
+	driver.checkPoolPressure()
+
+	if count := strings.Count(logs.String(), "pressure cleared"); count != 1 {
+		t.Errorf("Expected one cleared message, got %d: %q", count, logs.String())
+	}
+	if len(hookCalls) != 1 {
+		t.Errorf("Expected hook not to be called on clear, got: %d calls", len(hookCalls))
+	}
+}
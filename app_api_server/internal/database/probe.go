@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context" // context: コンテキスト、処理の文脈情報
+	"errors"  // errors: エラー操作
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"time"    // time: 時間操作機能
+)
+
+// watchdogStallFactor is how many missed intervals mark the stats logger as stalled
+// watchdogStallFactor: 統計ロガーを停止状態とみなすまでに取りこぼす間隔の数
+// stalled: 止まった、応答しない
+const watchdogStallFactor = 3
+
+// Probe errors, so an HTTP layer can map each failure to a status code; Readiness also returns ErrNotConnected
+// プローブのエラー、HTTP層が失敗ごとにステータスコードへ対応付けられるようにする、ReadinessはErrNotConnectedも返す
+// probe: 探査、死活監視
+var (
+	// ErrSchemaBehind is returned by Readiness when the schema is older than the required migration
+	// ErrSchemaBehind: スキーマが必要なマイグレーションより古い場合にReadinessが返すエラー
+	ErrSchemaBehind = errors.New("database schema is behind")
+
+	// ErrSchemaDirty is returned by Readiness when the last migration did not complete
+	// ErrSchemaDirty: 最後のマイグレーションが完了していない場合にReadinessが返すエラー
+	ErrSchemaDirty = errors.New("database schema is dirty")
+
+	// ErrSchemaUnknown is returned by Readiness when the migration version cannot be read
+	// ErrSchemaUnknown: マイグレーションバージョンを読み込めない場合にReadinessが返すエラー
+	ErrSchemaUnknown = errors.New("database schema version is unknown")
+
+	// ErrWatchdogStalled is returned by Liveness when the stats logger goroutine stopped ticking
+	// ErrWatchdogStalled: 統計ロガーのゴルーチンが動かなくなった場合にLivenessが返すエラー
+	ErrWatchdogStalled = errors.New("database watchdog goroutine is stalled")
+)
+
+// watchdogState records the heartbeat of the stats logger goroutine (guarded by the driver's mu)
+// watchdogState: 統計ロガーのゴルーチンの生存信号を記録する構造体（ドライバーのmuで保護）
+// heartbeat: 生存信号
+type watchdogState struct {
+	generation int64         // generation: 起動ごとに進む世代、古いゴルーチンの終了を無視するため
+	running    bool          // running: ゴルーチンが動作中
+	interval   time.Duration // interval: ティックの間隔
+	lastBeat   time.Time     // last beat: 最後の生存信号の時刻
+}
+
+// WithReadinessMigration makes Readiness require at least the given migration version
+// WithReadinessMigration: Readinessで指定したマイグレーションバージョン以上を必須にするオプション
+// A version of 0 (the default) skips the check: 0（デフォルト）の場合は確認を省略する
+func WithReadinessMigration(required uint) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		d.readinessMigration = required
+	}
+}
+
+// startWatchdog marks a new stats logger as running and returns its generation
+// startWatchdog: 新しい統計ロガーを動作中として記録し、その世代を返す関数
+func (d *PostgreSQLDriver) startWatchdog(interval time.Duration) int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	w := &d.watchdog
+	w.generation++
+	w.running = true
+	w.interval = interval
+	w.lastBeat = d.now()
+	return w.generation
+}
+
+// beatWatchdog records a heartbeat from the stats logger of the given generation
+// beatWatchdog: 指定した世代の統計ロガーからの生存信号を記録する関数
+func (d *PostgreSQLDriver) beatWatchdog(generation int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.watchdog.generation == generation {
+		d.watchdog.lastBeat = d.now()
+	}
+}
+
+// stopWatchdog marks the stats logger of the given generation as stopped on purpose
+// stopWatchdog: 指定した世代の統計ロガーが意図的に停止したことを記録する関数
+func (d *PostgreSQLDriver) stopWatchdog(generation int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.watchdog.generation == generation {
+		d.watchdog.running = false
+	}
+}
+
+// Liveness reports whether the driver itself is healthy, without touching the network
+// Liveness: ネットワークに触れずにドライバー自体が正常かどうかを報告する関数
+// A database outage is not a liveness failure, since restarting the process would not fix it
+// データベースの停止はlivenessの失敗ではない、プロセスを再起動しても直らないため
+// outage: 停止、障害
+func (d *PostgreSQLDriver) Liveness() error {
+	d.mu.Lock()
+	w := d.watchdog
+	now := d.now()
+	d.mu.Unlock()
+
+	if !w.running {
+		return nil
+	}
+	if silent := now.Sub(w.lastBeat); silent > watchdogStallFactor*w.interval {
+		return fmt.Errorf("%w: no heartbeat for %s (interval %s)", ErrWatchdogStalled, silent.Round(time.Second), w.interval)
+	}
+	return nil
+}
+
+// Readiness reports whether the driver can serve queries right now
+// Readiness: ドライバーが今すぐクエリを処理できるかどうかを報告する関数
+// It pings within the ping timeout and, if WithReadinessMigration is set, checks the schema version
+// pingタイムアウト内でpingし、WithReadinessMigrationが設定されていればスキーマのバージョンも確認する
+func (d *PostgreSQLDriver) Readiness(ctx context.Context) error {
+	db, err := d.usablePool()
+	if err != nil {
+		return err
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, d.pingTimeout)
+	defer cancel()
+	if err := db.PingContext(pingCtx); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotConnected, err)
+	}
+
+	if d.readinessMigration == 0 {
+		return nil
+	}
+	err = d.CheckMigrationVersion(ctx, d.readinessMigration)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrMigrationBehind):
+		return fmt.Errorf("%w: %w", ErrSchemaBehind, err)
+	case errors.Is(err, ErrMigrationDirty):
+		return fmt.Errorf("%w: %w", ErrSchemaDirty, err)
+	}
+	return fmt.Errorf("%w: %w", ErrSchemaUnknown, err)
+}
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"testing"                       // testing: テスト機能
+	"time"                          // time: 時間操作機能
+)
+
+// TestReadiness tests every combination of connection and schema state
+// TestReadiness: 接続状態とスキーマ状態の全ての組み合わせをテストする関数
+func TestReadiness(t *testing.T) {
+	versionRows := func(version int64, dirty bool) [][]sqldriver.Value {
+		return [][]sqldriver.Value{{version, dirty}}
+	}
+
+	testCases := []struct {
+		name        string
+		noPool      bool                // no pool: プールなし
+		pingErr     error               // ping error: pingの失敗
+		required    uint                // required: 必須のマイグレーションバージョン
+		rows        [][]sqldriver.Value // rows: schema_migrationsの内容
+		queryErr    error               // query error: バージョン取得の失敗
+		expectedErr error               // expected: Readinessが返すべきエラー、nilなら成功
+	}{
+		{name: "Not connected", noPool: true, expectedErr: ErrNotConnected},
+		{name: "Not connected with migration required", noPool: true, required: 5, expectedErr: ErrNotConnected},
+		{name: "Ping fails", pingErr: errors.New("connection refused"), expectedErr: ErrNotConnected},
+		{name: "Ping fails with migration required", pingErr: errors.New("connection refused"), required: 5, expectedErr: ErrNotConnected},
+		{name: "Connected without migration check", rows: versionRows(1, false)},
+		{name: "Connected and schema current", required: 5, rows: versionRows(5, false)},
+		{name: "Connected and schema ahead", required: 5, rows: versionRows(6, false)},
+		{name: "Connected but schema behind", required: 5, rows: versionRows(4, false), expectedErr: ErrSchemaBehind},
+		{name: "Connected but no migration applied", required: 5, expectedErr: ErrSchemaBehind},
+		{name: "Connected but schema dirty", required: 5, rows: versionRows(5, true), expectedErr: ErrSchemaDirty},
+		{name: "Connected but version unreadable", required: 5, queryErr: errors.New("permission denied"), expectedErr: ErrSchemaUnknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, fake := newTestDriver(t)
+			WithReadinessMigration(tc.required)(driver)
+			fake.ping = func(ctx context.Context) error { return tc.pingErr }
+			fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+				if tc.queryErr != nil {
+					return nil, tc.queryErr
+				}
+				return &fakeRows{columns: []string{"version", "dirty"}, values: tc.rows}, nil
+			}
+			if tc.noPool {
+				driver.db.Close()
+				driver.db = nil
+			}
+
+			err := driver.Readiness(context.Background())
+			if tc.expectedErr == nil {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.required == 0 && len(fake.executed()) != 0 {
+				t.Errorf("Expected no migration query without a required version, got: %v", fake.executed())
+			}
+		})
+	}
+}
+
+// TestReadinessPingTimeout tests that a hanging ping is bounded by the ping timeout
+// TestReadinessPingTimeout: 応答しないpingがpingタイムアウトで打ち切られることをテストする関数
+func TestReadinessPingTimeout(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	driver.pingTimeout = 20 * time.Millisecond
+	fake.ping = func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	start := time.Now()
+	err := driver.Readiness(context.Background())
+	if !errors.Is(err, ErrNotConnected) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected ErrNotConnected wrapping the deadline, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Readiness to give up quickly, took: %s", elapsed)
+	}
+}
+
+// TestLiveness tests the watchdog states without any network access
+// TestLiveness: ネットワークに触れずにウォッチドッグの各状態をテストする関数
+func TestLiveness(t *testing.T) {
+	testCases := []struct {
+		name        string
+		start       bool          // start: 統計ロガーを起動する
+		stop        bool          // stop: 統計ロガーを停止する
+		silence     time.Duration // silence: 最後の生存信号からの経過時間
+		dbDown      bool          // db down: データベースが応答しない
+		expectError bool
+	}{
+		{name: "Watchdog not started", expectError: false},
+		{name: "Watchdog beating", start: true, silence: time.Hour, expectError: false},
+		{name: "Watchdog beating while the database is down", start: true, silence: time.Hour, dbDown: true, expectError: false},
+		{name: "Watchdog stalled", start: true, silence: 4 * time.Hour, expectError: true},
+		{name: "Watchdog stopped on purpose", start: true, stop: true, silence: 4 * time.Hour, expectError: false},
+		{name: "Not connected", dbDown: true, expectError: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, fake := newTestDriver(t)
+			pinged := false
+			fake.ping = func(ctx context.Context) error {
+				pinged = true
+				return errors.New("connection refused")
+			}
+			if tc.dbDown {
+				driver.db.Close()
+				driver.db = nil
+			}
+			current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			driver.now = func() time.Time { return current }
+
+			if tc.start {
+				// An hour-long interval never ticks during the test: 1時間の間隔はテスト中にティックしない
+				ctx, cancel := context.WithCancel(context.Background())
+				stopped := driver.StartStatsLogger(ctx, time.Hour)
+				if tc.stop {
+					cancel()
+					<-stopped
+				} else {
+					t.Cleanup(func() {
+						cancel()
+						<-stopped
+					})
+				}
+			}
+
+			driver.mu.Lock()
+			current = current.Add(tc.silence)
+			driver.mu.Unlock()
+
+			err := driver.Liveness()
+			if tc.expectError {
+				if !errors.Is(err, ErrWatchdogStalled) {
+					t.Errorf("Expected ErrWatchdogStalled, got: %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+			if pinged {
+				t.Error("Expected Liveness not to touch the database")
+			}
+		})
+	}
+}
+
+// TestLivenessHeartbeat tests that ticks of the stats logger keep the watchdog alive
+// TestLivenessHeartbeat: 統計ロガーのティックがウォッチドッグを生かし続けることをテストする関数
+func TestLivenessHeartbeat(t *testing.T) {
+	driver, _ := newTestDriver(t)
+	captureLog(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := driver.StartStatsLogger(ctx, 2*time.Millisecond)
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if err := driver.Liveness(); err != nil {
+		t.Errorf("Expected no error while the logger ticks, got: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+// Package providers builds the driver options for the configured authentication method
+// providers: 設定された認証方式に応じたドライバーのオプションを組み立てるパッケージ
+// The binaries pass the result to every driver they open, so DB_AUTH_METHOD works without each one
+// importing the provider packages
+// 各コマンドは戻り値を開くすべてのドライバーに渡すため、取得元のパッケージを個別にインポートせずにDB_AUTH_METHODが機能する
+package providers
+
+import (
+	"fmt" // fmt: format（フォーマット）、文字列フォーマット機能
+
+	"api/internal/database"        // database: データベースドライバー
+	"api/internal/database/awsiam" // awsiam: aws-iam認証のトークン発行
+	"api/internal/database/vault"  // vault: credential-provider認証の認証情報の発行
+)
+
+// DriverOptions returns the options config's auth method needs: WithAuthTokenProvider for aws-iam,
+// WithCredentialProvider with Vault (configured by VAULT_*) for credential-provider and nothing for password
+// DriverOptions: configの認証方式が必要とするオプションを返す関数、aws-iamはWithAuthTokenProvider、
+// credential-providerはVAULT_*で設定したVaultのWithCredentialProvider、passwordは不要
+func DriverOptions(config *database.DatabaseConfig) ([]database.DriverOption, error) {
+	switch config.AuthMethod {
+	case database.AuthMethodAWSIAM:
+		provider, err := awsiam.NewTokenProvider()
+		if err != nil {
+			return nil, fmt.Errorf("auth method %s: %w", config.AuthMethod, err)
+		}
+		return []database.DriverOption{database.WithAuthTokenProvider(provider)}, nil
+	case database.AuthMethodCredentialProvider:
+		provider, err := vault.NewProviderFromEnv()
+		if err != nil {
+			return nil, fmt.Errorf("auth method %s: %w", config.AuthMethod, err)
+		}
+		return []database.DriverOption{database.WithCredentialProvider(provider)}, nil
+	}
+	return nil, nil
+}
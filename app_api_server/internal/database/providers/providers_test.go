@@ -0,0 +1,64 @@
+package providers
+
+import (
+	"path/filepath" // filepath: 存在しないAWS設定ファイルのパス
+	"testing"       // testing: テストフレームワーク
+
+	"api/internal/database" // database: データベースドライバー
+)
+
+// isolateAWS points the AWS SDK at missing config files so the host's settings do not leak in
+// isolateAWS: ホストの設定が入り込まないよう、AWS SDKの設定ファイルを存在しないパスに向ける関数
+func isolateAWS(t *testing.T, region string) {
+	t.Helper()
+
+	missing := filepath.Join(t.TempDir(), "missing")
+	t.Setenv("AWS_CONFIG_FILE", missing)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", missing)
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_REGION", region)
+}
+
+// TestDriverOptions tests the options built for each auth method
+// TestDriverOptions: 認証方式ごとに組み立てられるオプションをテストする関数
+func TestDriverOptions(t *testing.T) {
+	testCases := []struct {
+		name          string
+		authMethod    string
+		region        string
+		vaultAddr     string
+		expectedCount int
+		expectError   bool
+	}{
+		{name: "Default", expectedCount: 0},
+		{name: "Password", authMethod: database.AuthMethodPassword, expectedCount: 0},
+		{name: "AWS IAM", authMethod: database.AuthMethodAWSIAM, region: "ap-northeast-1", expectedCount: 1},
+		{name: "AWS IAM without a region", authMethod: database.AuthMethodAWSIAM, expectError: true},
+		{name: "Credential provider", authMethod: database.AuthMethodCredentialProvider, vaultAddr: "https://vault.example.com", expectedCount: 1},
+		{name: "Credential provider without Vault", authMethod: database.AuthMethodCredentialProvider, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			isolateAWS(t, tc.region)
+			t.Setenv("VAULT_ADDR", tc.vaultAddr)
+			t.Setenv("VAULT_TOKEN", "s.token")
+			t.Setenv("VAULT_ROLE", "sift-app")
+
+			opts, err := DriverOptions(&database.DatabaseConfig{AuthMethod: tc.authMethod})
+			if tc.expectError {
+				if err == nil {
+					t.Error("Expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if len(opts) != tc.expectedCount {
+				t.Errorf("Expected %d options, got: %d", tc.expectedCount, len(opts))
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package database
+
+import (
+	"context"       // context: コンテキスト、処理の文脈情報
+	"database/sql"  // sql: データベース操作用パッケージ
+	"fmt"           // fmt: format（フォーマット）、文字列フォーマット機能
+	"path/filepath" // filepath: ファイルパス操作
+	"runtime"       // runtime: 実行時情報、呼び出し元の取得
+	"strings"       // strings: 文字列操作
+	"sync/atomic"   // atomic: アトミック操作、ロックなしのカウンター
+	"time"          // time: 時間操作機能
+	"unicode/utf8"  // utf8: UTF-8の文字境界の判定
+)
+
+// maxLoggedQueryLength limits how much SQL text is written to the log
+// maxLoggedQueryLength: ログに出力するSQL文の最大長
+// limits: 制限する
+const maxLoggedQueryLength = 200
+
+// defaultQueryTimeout bounds statements run through the driver helpers when the caller set no deadline
+// defaultQueryTimeout: 呼び出し元が期限を設定していない場合に、ドライバーのヘルパー経由の文に適用する制限時間
+const defaultQueryTimeout = 30 * time.Second
+
+// QueryStats represents counters for statements executed through the driver helpers
+// QueryStats: ドライバーのクエリヘルパー経由で実行された文の統計を表す構造体
+// counters: カウンター（複数形）、executed: 実行された
+type QueryStats struct {
+	TotalQueries       int64         // total: 合計、queries: クエリ（複数形）
+	SlowQueries        int64         // slow: 遅い、しきい値を超えたクエリ数
+	SlowQueryThreshold time.Duration // threshold: しきい値
+}
+
+// queryCounters holds the atomic counters behind QueryStats
+// queryCounters: QueryStatsの元になるアトミックカウンター
+type queryCounters struct {
+	total int64 // total: 合計
+	slow  int64 // slow: 遅い
+}
+
+// Row wraps sql.Row so that errors raised before execution surface on Scan
+// Row: 実行前に発生したエラーをScanで返すためのsql.Rowラッパー
+// wraps: 包む、raised: 発生した、surface: 表面化する
+type Row struct {
+	row    *sql.Row // row: 行
+	err    error    // err: 実行前のエラー
+	cancel func()   // cancel: 既定の制限時間と取得した接続を解放する関数、Scanで呼び出す
+}
+
+// Scan copies the columns of the row into dest
+// Scan: 行のカラムをdestへコピーする関数
+// copies: コピーする、columns: カラム（複数形）
+func (r *Row) Scan(dest ...interface{}) error {
+	if r.cancel != nil {
+		defer r.cancel() // the row is read once Scan returns: Scanが返った時点で行は読み終えている
+	}
+	if r.err != nil {
+		return r.err
+	}
+	return r.row.Scan(dest...)
+}
+
+// Err returns the error, if any, that was encountered while running the query
+// Err: クエリ実行中に発生したエラーを返す関数
+// encountered: 遭遇した
+func (r *Row) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.row.Err()
+}
+
+// Rows wraps sql.Rows so that Close also releases the default query timeout and the acquired connection
+// Rows: Closeで既定の制限時間と取得した接続も解放するためのsql.Rowsラッパー
+// The embedded *sql.Rows is passed to helpers that take *sql.Rows, such as CollectRows
+// 埋め込んだ*sql.RowsはCollectRowsなど*sql.Rowsを受け取るヘルパーに渡す
+type Rows struct {
+	*sql.Rows        // rows: 結果の行
+	cancel    func() // cancel: 既定の制限時間と取得した接続を解放する関数、Closeで呼び出す
+}
+
+// Close closes the rows and releases the default query timeout and the acquired connection
+// Close: 行を閉じ、既定の制限時間と取得した接続を解放する関数
+func (r *Rows) Close() error {
+	err := r.Rows.Close()
+	if r.cancel != nil {
+		r.cancel()
+	}
+	return err
+}
+
+// withQueryTimeout derives a context with DefaultQueryTimeout when ctx has no deadline
+// withQueryTimeout: ctxに期限がない場合にDefaultQueryTimeoutの期限を持つ子コンテキストを作る関数
+// derives: 派生させる
+// A deadline the caller already set is kept, whether sooner or later: 呼び出し元が設定した期限は、早くても遅くてもそのまま使う
+func (d *PostgreSQLDriver) withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := d.config.Load().DefaultQueryTimeout
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// QueryContext executes a query that returns rows
+// QueryContext: 行を返すクエリを実行する関数
+// executes: 実行する、returns: 返す
+// The caller must Close the rows, which also releases the default query timeout and the acquired connection
+// 呼び出し元は行をCloseする必要がある、Closeで既定の制限時間と取得した接続も解放される
+func (d *PostgreSQLDriver) QueryContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	return d.queryIn(ctx, nil, query, args...)
+}
+
+// queryIn executes a query on tx, or on the pool when tx is nil
+// queryIn: txでクエリを実行する関数、txがnilならプールで実行する
+func (d *PostgreSQLDriver) queryIn(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (*Rows, error) {
+	db, err := d.statementPool(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := d.withQueryTimeout(ctx)
+	ctx = d.beforeStatement(ctx, OpQuery, query, args)
+	start := d.now()
+	rows, release, err := d.queryOn(ctx, db, tx, query, args...)
+	d.afterStatement(ctx, OpQuery, query, d.now().Sub(start), err)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &Rows{Rows: rows, cancel: func() { release(); cancel() }}, nil
+}
+
+// QueryRowContext executes a query that is expected to return at most one row
+// QueryRowContext: 最大1行を返すクエリを実行する関数
+// expected: 期待される、at most: 最大で
+func (d *PostgreSQLDriver) QueryRowContext(ctx context.Context, query string, args ...interface{}) *Row {
+	return d.queryRowIn(ctx, nil, query, args...)
+}
+
+// queryRowIn executes a single-row query on tx, or on the pool when tx is nil
+// queryRowIn: txで単一行クエリを実行する関数、txがnilならプールで実行する
+func (d *PostgreSQLDriver) queryRowIn(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) *Row {
+	db, err := d.statementPool(tx)
+	if err != nil {
+		return &Row{err: err}
+	}
+
+	ctx, cancel := d.withQueryTimeout(ctx)
+	ctx = d.beforeStatement(ctx, OpQueryRow, query, args)
+	start := d.now()
+	row, release, err := d.queryRowOn(ctx, db, tx, query, args...)
+	duration := d.now().Sub(start)
+	if err != nil {
+		d.afterStatement(ctx, OpQueryRow, query, duration, err)
+		return &Row{err: err, cancel: cancel}
+	}
+	d.afterStatement(ctx, OpQueryRow, query, duration, row.Err())
+	return &Row{row: row, cancel: func() { release(); cancel() }}
+}
+
+// ExecContext executes a statement without returning any rows
+// ExecContext: 行を返さない文を実行する関数
+// statement: 文、SQL文
+func (d *PostgreSQLDriver) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return d.execIn(ctx, nil, query, args...)
+}
+
+// execIn executes a statement on tx, or on the pool when tx is nil
+// execIn: txで文を実行する関数、txがnilならプールで実行する
+func (d *PostgreSQLDriver) execIn(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
+	db, err := d.statementPool(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := d.withQueryTimeout(ctx)
+	defer cancel()
+
+	ctx = d.beforeStatement(ctx, OpExec, query, args)
+	start := d.now()
+	result, err := d.execOn(ctx, db, tx, query, args...)
+	d.afterStatement(ctx, OpExec, query, d.now().Sub(start), err)
+	return result, err
+}
+
+// statementPool returns the connected pool, or nil when tx already holds a connection
+// statementPool: 接続済みのプールを返す関数、txが既に接続を保持していればnilを返す
+func (d *PostgreSQLDriver) statementPool(tx *sql.Tx) (*sql.DB, error) {
+	if tx != nil {
+		return nil, nil
+	}
+	return d.connectedPool()
+}
+
+// GetQueryStats returns counters for statements executed through the driver helpers
+// GetQueryStats: ドライバーのクエリヘルパーで実行された文の統計を返す関数
+func (d *PostgreSQLDriver) GetQueryStats() QueryStats {
+	return QueryStats{
+		TotalQueries:       atomic.LoadInt64(&d.queryStats.total),
+		SlowQueries:        atomic.LoadInt64(&d.queryStats.slow),
+		SlowQueryThreshold: d.config.Load().SlowQueryThreshold,
+	}
+}
+
+// truncateQuery collapses whitespace and shortens SQL text for logging
+// truncateQuery: ログ出力用にSQL文の空白をまとめて短縮する関数
+// collapses: まとめる、shortens: 短くする
+// The cut backs up to a rune boundary so multi-byte text such as Japanese stays valid UTF-8
+// 日本語などのマルチバイト文字が不正なUTF-8にならないよう、文字の境界まで戻って切る
+func truncateQuery(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > maxLoggedQueryLength {
+		cut := maxLoggedQueryLength
+		for cut > 0 && !utf8.RuneStart(query[cut]) {
+			cut--
+		}
+		return query[:cut] + "..."
+	}
+	return query
+}
+
+// callerLocation returns "file:line" of the first caller outside this package's source files
+// callerLocation: このパッケージのソースファイル外にある最初の呼び出し元の「ファイル:行番号」を返す関数
+// outside: 外側の
+func callerLocation() string {
+	_, self, _, ok := runtime.Caller(0)
+	if !ok {
+		return "unknown" // unknown: 不明
+	}
+	packageDir := filepath.Dir(self)
+
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		// Test files live in the same directory but count as callers
+		// テストファイルは同じディレクトリにあるが呼び出し元として扱う
+		if filepath.Dir(frame.File) != packageDir || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,366 @@
+package database
+
+import (
+	"bytes"                         // bytes: バイト列操作
+	"context"                       // context: コンテキスト
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"log"                           // log: ログ出力機能
+	"os"                            // os: operating system（オペレーティングシステム）
+	"strings"                       // strings: 文字列操作
+	"testing"                       // testing: テスト機能
+	"time"                          // time: 時間操作機能
+	"unicode/utf8"                  // utf8: UTF-8の妥当性の確認
+)
+
+// newTestDriver creates a driver connected to a fake database
+// newTestDriver: フェイクデータベースに接続されたドライバーを作成するテスト用関数
+func newTestDriver(t testing.TB) (*PostgreSQLDriver, *fakeDB) {
+	t.Helper()
+
+	driver, err := NewPostgreSQLDriverWithConfig(&DatabaseConfig{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "testuser",
+		Password: "testpass",
+		Database: "testdb",
+		SSLMode:  "disable",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	fake, db := newFakeDB()
+	driver.db = db
+	return driver, fake
+}
+
+// updateTestConfig stores a copy of the driver's configuration with update applied and returns the previous one
+// updateTestConfig: ドライバーの設定のコピーにupdateを適用して格納し、以前の設定を返す関数
+// The stored configuration is never changed in place: 格納済みの設定はその場で変更しない
+func updateTestConfig(driver *PostgreSQLDriver, update func(config *DatabaseConfig)) *DatabaseConfig {
+	previous := driver.config.Load()
+	updated := *previous
+	update(&updated)
+	driver.config.Store(&updated)
+	return previous
+}
+
+// fakeClock returns a clock that advances by step on every call
+// fakeClock: 呼び出しごとにstepずつ進むフェイク時計を返す関数
+// advances: 進む
+func fakeClock(step time.Duration) func() time.Time {
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		current = current.Add(step)
+		return current
+	}
+}
+
+// captureLog redirects the standard logger into a buffer for the duration of the test
+// captureLog: テスト中の標準ログ出力をバッファに切り替える関数
+// redirects: 切り替える、buffer: バッファ
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+// TestSlowQueryDetection tests that only statements above the threshold are flagged
+// TestSlowQueryDetection: しきい値を超えた文のみが検出されることをテストする関数
+func TestSlowQueryDetection(t *testing.T) {
+	testCases := []struct {
+		name        string
+		threshold   time.Duration // threshold: しきい値
+		duration    time.Duration // duration: 実行時間
+		expectSlow  bool
+		expectTotal int64
+	}{
+		{name: "Disabled threshold", threshold: 0, duration: time.Hour, expectSlow: false, expectTotal: 1},
+		{name: "Below threshold", threshold: 100 * time.Millisecond, duration: 50 * time.Millisecond, expectSlow: false, expectTotal: 1},
+		{name: "Equal to threshold", threshold: 100 * time.Millisecond, duration: 100 * time.Millisecond, expectSlow: true, expectTotal: 1},
+		{name: "Above threshold", threshold: 100 * time.Millisecond, duration: time.Second, expectSlow: true, expectTotal: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logs := captureLog(t)
+			driver, _ := newTestDriver(t)
+			updateTestConfig(driver, func(config *DatabaseConfig) { config.SlowQueryThreshold = tc.threshold })
+			driver.now = fakeClock(tc.duration)
+
+			if _, err := driver.ExecContext(context.Background(), "UPDATE app.users SET is_active = true"); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			stats := driver.GetQueryStats()
+			if stats.TotalQueries != tc.expectTotal {
+				t.Errorf("Expected %d total queries, got: %d", tc.expectTotal, stats.TotalQueries)
+			}
+
+			logged := strings.Contains(logs.String(), "slow query")
+			if tc.expectSlow != logged {
+				t.Errorf("Expected slow query logged=%v, got log: %q", tc.expectSlow, logs.String())
+			}
+
+			expectedSlow := int64(0)
+			if tc.expectSlow {
+				expectedSlow = 1
+			}
+			if stats.SlowQueries != expectedSlow {
+				t.Errorf("Expected %d slow queries, got: %d", expectedSlow, stats.SlowQueries)
+			}
+		})
+	}
+}
+
+// TestSlowQueryLogContents tests the duration, caller and truncated SQL in the warning
+// TestSlowQueryLogContents: 警告に実行時間、呼び出し元、短縮SQLが含まれることをテストする関数
+func TestSlowQueryLogContents(t *testing.T) {
+	logs := captureLog(t)
+	driver, fake := newTestDriver(t)
+	updateTestConfig(driver, func(config *DatabaseConfig) { config.SlowQueryThreshold = 10 * time.Millisecond })
+	driver.now = fakeClock(250 * time.Millisecond)
+	fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		return &fakeRows{columns: []string{"n"}, values: [][]sqldriver.Value{{int64(1)}}}, nil
+	}
+
+	longQuery := "SELECT 1 WHERE\n\t'" + strings.Repeat("x", 500) + "' <> ''"
+	var n int64
+	if err := driver.QueryRowContext(context.Background(), longQuery).Scan(&n); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	output := logs.String()
+	if !strings.Contains(output, "250ms") {
+		t.Errorf("Expected duration in log, got: %q", output)
+	}
+	if !strings.Contains(output, "query_test.go:") {
+		t.Errorf("Expected caller location in log, got: %q", output)
+	}
+	if !strings.Contains(output, "SELECT 1 WHERE 'xxx") {
+		t.Errorf("Expected whitespace-collapsed SQL in log, got: %q", output)
+	}
+	if strings.Contains(output, strings.Repeat("x", 300)) {
+		t.Errorf("Expected SQL text to be truncated, got: %q", output)
+	}
+}
+
+// TestTruncateQuery tests that truncated SQL ends on a rune boundary
+// TestTruncateQuery: 短縮したSQLが文字の境界で終わることをテストする関数
+func TestTruncateQuery(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{name: "Short query", query: "SELECT  1", expected: "SELECT 1"},
+		{name: "ASCII cut", query: strings.Repeat("x", 250), expected: strings.Repeat("x", 200) + "..."},
+		// 3-byte runes: the 200th byte falls inside the 67th rune: 3バイトの文字、200バイト目は67文字目の途中
+		{name: "Japanese cut", query: strings.Repeat("あ", 100), expected: strings.Repeat("あ", 66) + "..."},
+		{name: "Japanese after ASCII", query: "SELECT '" + strings.Repeat("日本", 100) + "'", expected: "SELECT '" + strings.Repeat("日本", 32) + "..."},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := truncateQuery(tc.query)
+			if got != tc.expected {
+				t.Errorf("Expected %q, got: %q", tc.expected, got)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("Expected valid UTF-8, got: %q", got)
+			}
+		})
+	}
+}
+
+// TestQueryHelpersWithoutConnection tests helper errors before Connect
+// TestQueryHelpersWithoutConnection: 接続前のヘルパーのエラーをテストする関数
+func TestQueryHelpersWithoutConnection(t *testing.T) {
+	driver, err := NewPostgreSQLDriverWithConfig(&DatabaseConfig{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "testuser",
+		Password: "testpass",
+		Database: "testdb",
+		SSLMode:  "disable",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := driver.ExecContext(ctx, "SELECT 1"); err == nil {
+		t.Error("Expected error from ExecContext without connection, got none")
+	}
+	if _, err := driver.QueryContext(ctx, "SELECT 1"); err == nil {
+		t.Error("Expected error from QueryContext without connection, got none")
+	}
+	var n int
+	if err := driver.QueryRowContext(ctx, "SELECT 1").Scan(&n); err == nil {
+		t.Error("Expected error from QueryRowContext without connection, got none")
+	}
+}
+
+// TestDefaultQueryTimeout tests that the helpers add DefaultQueryTimeout only when the caller set no deadline
+// TestDefaultQueryTimeout: 呼び出し元が期限を設定していない場合にのみヘルパーがDefaultQueryTimeoutを付与することをテストする関数
+func TestDefaultQueryTimeout(t *testing.T) {
+	helpers := map[string]func(driver *PostgreSQLDriver, ctx context.Context) error{
+		"Exec": func(driver *PostgreSQLDriver, ctx context.Context) error {
+			_, err := driver.ExecContext(ctx, "UPDATE t SET x = 1")
+			return err
+		},
+		"Query": func(driver *PostgreSQLDriver, ctx context.Context) error {
+			rows, err := driver.QueryContext(ctx, "SELECT 1")
+			if err == nil {
+				rows.Close()
+			}
+			return err
+		},
+		"QueryRow": func(driver *PostgreSQLDriver, ctx context.Context) error {
+			var n int64
+			return driver.QueryRowContext(ctx, "SELECT 1").Scan(&n)
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		callerDeadline time.Duration // caller deadline: 呼び出し元の期限、0なら期限なし
+		expected       time.Duration // expected: 文のコンテキストに期待する残り時間
+	}{
+		{name: "No deadline", expected: 2 * time.Second},
+		{name: "Sooner caller deadline", callerDeadline: 500 * time.Millisecond, expected: 500 * time.Millisecond},
+		{name: "Later caller deadline", callerDeadline: time.Minute, expected: time.Minute},
+	}
+
+	for helperName, helper := range helpers {
+		for _, tc := range testCases {
+			t.Run(helperName+"/"+tc.name, func(t *testing.T) {
+				driver, fake := newTestDriver(t)
+				updateTestConfig(driver, func(config *DatabaseConfig) { config.DefaultQueryTimeout = 2 * time.Second })
+
+				var remaining time.Duration
+				var hasDeadline bool
+				fake.observe = func(ctx context.Context) {
+					var deadline time.Time
+					deadline, hasDeadline = ctx.Deadline()
+					remaining = time.Until(deadline)
+				}
+				fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+					return &fakeRows{columns: []string{"n"}, values: [][]sqldriver.Value{{int64(1)}}}, nil
+				}
+
+				ctx := context.Background()
+				if tc.callerDeadline > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, tc.callerDeadline)
+					defer cancel()
+				}
+
+				if err := helper(driver, ctx); err != nil {
+					t.Fatalf("Expected no error, got: %v", err)
+				}
+				if !hasDeadline {
+					t.Fatal("Expected the statement context to have a deadline")
+				}
+				if remaining > tc.expected || remaining < tc.expected-time.Second/4 {
+					t.Errorf("Expected about %s until the deadline, got: %s", tc.expected, remaining)
+				}
+			})
+		}
+	}
+}
+
+// TestQueryTimeoutKeepsRowsReadable tests that the injected deadline does not cut off rows the caller is still reading
+// TestQueryTimeoutKeepsRowsReadable: 付与された期限が、呼び出し元が読み取り中の行を打ち切らないことをテストする関数
+func TestQueryTimeoutKeepsRowsReadable(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		return &fakeRows{columns: []string{"n"}, values: [][]sqldriver.Value{{int64(1)}, {int64(2)}, {int64(3)}}}, nil
+	}
+
+	rows, err := driver.QueryContext(context.Background(), "SELECT n FROM t")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		count++
+	}
+	if err := rows.Err(); err != nil || count != 3 {
+		t.Errorf("Expected 3 rows without error, got: %d, %v", count, err)
+	}
+}
+
+// TestQueryRowsCloseReleasesTimeout tests that closing the rows cancels the injected deadline instead of leaving it to expire
+// TestQueryRowsCloseReleasesTimeout: 行を閉じると、付与された期限が満了を待たずに解放されることをテストする関数
+func TestQueryRowsCloseReleasesTimeout(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	var statementCtx context.Context
+	fake.observe = func(ctx context.Context) {
+		statementCtx = ctx
+	}
+	fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		return &fakeRows{columns: []string{"n"}, values: [][]sqldriver.Value{{int64(1)}}}, nil
+	}
+
+	rows, err := driver.QueryContext(context.Background(), "SELECT n FROM t")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := statementCtx.Err(); err != nil {
+		t.Fatalf("Expected the statement context to be live while the rows are open, got: %v", err)
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatalf("Expected no error from Close, got: %v", err)
+	}
+	if err := statementCtx.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the statement context to be canceled by Close, got: %v", err)
+	}
+}
+
+// TestLoadDefaultQueryTimeout tests DB_QUERY_TIMEOUT parsing and its 30s default
+// TestLoadDefaultQueryTimeout: DB_QUERY_TIMEOUTの解析と30秒のデフォルト値をテストする関数
+func TestLoadDefaultQueryTimeout(t *testing.T) {
+	t.Setenv("DB_USER", "testuser")
+	t.Setenv("DB_PASSWORD", "testpass")
+	t.Setenv("DB_NAME", "testdb")
+
+	testCases := []struct {
+		name        string
+		value       string
+		expected    time.Duration
+		expectError bool
+	}{
+		{name: "Unset", value: "", expected: 30 * time.Second},
+		{name: "Custom", value: "5s", expected: 5 * time.Second},
+		{name: "Invalid", value: "soon", expectError: true},
+		{name: "Negative", value: "-1s", expectError: true},
+		{name: "Zero", value: "0", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("DB_QUERY_TIMEOUT", tc.value)
+
+			driver, err := NewPostgreSQLDriver()
+			if tc.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if got := driver.GetConfig().DefaultQueryTimeout; got != tc.expected {
+				t.Errorf("Expected %s, got: %s", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"bytes"               // bytes: バイト列操作
+	"database/sql/driver" // driver: SQLドライバーインターフェース
+	"encoding/json"       // json: JSONエンコード
+	"fmt"                 // fmt: format（フォーマット）、文字列フォーマット機能
+)
+
+// RawJSON holds a jsonb/json column value as raw bytes for verbatim pass-through
+// RawJSON: jsonb/jsonカラムの値をそのまま受け渡すために生のバイト列として保持する型
+// verbatim: そのまま、pass-through: 受け渡し
+// A nil RawJSON represents SQL NULL and marshals as JSON null
+// nilのRawJSONはSQLのNULLを表し、JSONのnullとして出力される
+type RawJSON json.RawMessage
+
+// InvalidJSONError reports a stored value that is not well-formed JSON
+// InvalidJSONError: 保存された値が正しいJSONでないことを報告するエラー型
+// well-formed: 正しい形式の
+// Row is empty when raised by Scan; repositories set it to identify the offending row
+// Scanから返る時点ではRowは空で、リポジトリが該当行の識別子を設定する
+type InvalidJSONError struct {
+	Row string // row: 行の識別子
+	Err error  // err: JSON検証のエラー
+}
+
+// Error implements the error interface
+// Error: errorインターフェースの実装
+func (e *InvalidJSONError) Error() string {
+	if e.Row == "" {
+		return fmt.Sprintf("invalid JSON in column: %v", e.Err)
+	}
+	return fmt.Sprintf("invalid JSON in column for row %s: %v", e.Row, e.Err)
+}
+
+// Unwrap returns the underlying validation error
+// Unwrap: 元の検証エラーを返す関数
+func (e *InvalidJSONError) Unwrap() error {
+	return e.Err
+}
+
+// Scan implements sql.Scanner, validating the bytes without decoding them
+// Scan: sql.Scannerの実装、デコードせずにバイト列を検証する関数
+// validating: 検証する、decoding: デコード
+func (r *RawJSON) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*r = nil // SQL NULL
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into RawJSON", src)
+	}
+
+	if !json.Valid(data) {
+		var probe interface{}
+		return &InvalidJSONError{Err: json.Unmarshal(data, &probe)}
+	}
+
+	// database/sql may reuse src after Scan returns, so keep our own copy
+	// database/sqlはScan後にsrcを再利用する可能性があるため、コピーを保持する
+	*r = append((*r)[:0], data...)
+	return nil
+}
+
+// Value implements driver.Valuer
+// Value: driver.Valuerの実装
+func (r RawJSON) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil // SQL NULL
+	}
+	if !json.Valid(r) {
+		return nil, fmt.Errorf("RawJSON value is not valid JSON")
+	}
+	return []byte(r), nil
+}
+
+// MarshalJSON embeds the stored bytes verbatim, writing null for SQL NULL
+// MarshalJSON: 保存されたバイト列をそのまま埋め込み、SQLのNULLはnullとして出力する関数
+// embeds: 埋め込む
+func (r RawJSON) MarshalJSON() ([]byte, error) {
+	if r == nil {
+		return []byte("null"), nil
+	}
+	return r, nil
+}
+
+// UnmarshalJSON stores a copy of data
+// UnmarshalJSON: dataのコピーを保存する関数
+func (r *RawJSON) UnmarshalJSON(data []byte) error {
+	if r == nil {
+		return fmt.Errorf("RawJSON: UnmarshalJSON on nil pointer")
+	}
+	*r = append((*r)[:0], data...)
+	return nil
+}
+
+// IsNull reports whether the value is SQL NULL or JSON null
+// IsNull: 値がSQLのNULLまたはJSONのnullかどうかを判定する関数
+func (r RawJSON) IsNull() bool {
+	return r == nil || bytes.Equal(bytes.TrimSpace(r), []byte("null"))
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"encoding/json" // json: JSONエンコード
+	"errors"        // errors: エラー操作
+	"strings"       // strings: 文字列操作
+	"testing"       // testing: テスト機能
+)
+
+// TestRawJSONScan tests scanning valid, null and invalid values
+// TestRawJSONScan: 正しい値、NULL、不正な値の読み取りをテストする関数
+func TestRawJSONScan(t *testing.T) {
+	testCases := []struct {
+		name        string
+		src         interface{}
+		expected    string
+		expectNull  bool
+		expectError bool
+	}{
+		{name: "Object bytes keep key order", src: []byte(`{"z":1,"a":[true,null]}`), expected: `{"z":1,"a":[true,null]}`},
+		{name: "String source", src: `"text"`, expected: `"text"`},
+		{name: "SQL NULL", src: nil, expectNull: true},
+		{name: "JSON null", src: []byte(`null`), expected: `null`, expectNull: true},
+		{name: "Invalid JSON", src: []byte(`{"a":`), expectError: true},
+		{name: "Unsupported type", src: 42, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var value RawJSON
+			err := value.Scan(tc.src)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error for test case '%s', but got none", tc.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if value.IsNull() != tc.expectNull {
+				t.Errorf("Expected IsNull=%v, got: %v", tc.expectNull, value.IsNull())
+			}
+			if !tc.expectNull && string(value) != tc.expected {
+				t.Errorf("Expected '%s', got: '%s'", tc.expected, string(value))
+			}
+		})
+	}
+}
+
+// TestRawJSONScanCopiesSource tests that Scan does not alias the driver buffer
+// TestRawJSONScanCopiesSource: Scanがドライバーのバッファを共有しないことをテストする関数
+// alias: 同じメモリを共有する
+func TestRawJSONScanCopiesSource(t *testing.T) {
+	src := []byte(`{"a":1}`)
+	var value RawJSON
+	if err := value.Scan(src); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	src[2] = 'b'
+	if string(value) != `{"a":1}` {
+		t.Errorf("Expected copy to be unaffected by buffer reuse, got: %s", string(value))
+	}
+}
+
+// TestRawJSONInvalidError tests the typed error and row identity
+// TestRawJSONInvalidError: 型付きエラーと行の識別子をテストする関数
+func TestRawJSONInvalidError(t *testing.T) {
+	var value RawJSON
+	err := value.Scan([]byte(`not json`))
+
+	var invalid *InvalidJSONError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Expected *InvalidJSONError, got: %T", err)
+	}
+
+	invalid.Row = "audit_log id=42"
+	if got := invalid.Error(); !strings.Contains(got, "audit_log id=42") {
+		t.Errorf("Expected row identity in error, got: %s", got)
+	}
+}
+
+// TestRawJSONMarshalEmbedsVerbatim tests embedding in a response struct
+// TestRawJSONMarshalEmbedsVerbatim: レスポンス構造体への埋め込みをテストする関数
+func TestRawJSONMarshalEmbedsVerbatim(t *testing.T) {
+	response := struct {
+		ID      int     `json:"id"`
+		Payload RawJSON `json:"payload"`
+		Diff    RawJSON `json:"diff"`
+	}{ID: 1, Payload: RawJSON(`{"z":1,"a":2}`)}
+
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := `{"id":1,"payload":{"z":1,"a":2},"diff":null}`
+	if string(encoded) != expected {
+		t.Errorf("Expected %s, got: %s", expected, string(encoded))
+	}
+}
+
+// TestRawJSONValue tests the driver.Valuer implementation
+// TestRawJSONValue: driver.Valuerの実装をテストする関数
+func TestRawJSONValue(t *testing.T) {
+	if v, err := RawJSON(nil).Value(); v != nil || err != nil {
+		t.Errorf("Expected nil value for NULL, got: %v, %v", v, err)
+	}
+	if _, err := RawJSON(`{"a":`).Value(); err == nil {
+		t.Error("Expected error for invalid JSON value, got none")
+	}
+	if v, err := RawJSON(`[1,2]`).Value(); err != nil || string(v.([]byte)) != `[1,2]` {
+		t.Errorf("Expected bytes value, got: %v, %v", v, err)
+	}
+}
+
+var benchmarkPayload = []byte(`{"event":"user.updated","changes":{"first_name":["Old","New"],"last_name":["A","B"]},"actor":"admin@siftapp.com","tags":["audit","users","profile"]}`)
+
+// BenchmarkRawJSONPassThrough measures scanning and re-encoding through RawJSON
+// BenchmarkRawJSONPassThrough: RawJSON経由の読み取りと再エンコードを計測するベンチマーク
+func BenchmarkRawJSONPassThrough(b *testing.B) {
+	b.ReportAllocs()
+	var value RawJSON
+	for i := 0; i < b.N; i++ {
+		if err := value.Scan(benchmarkPayload); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := json.Marshal(value); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkMapRoundTrip measures the map[string]any round trip RawJSON replaces
+// BenchmarkMapRoundTrip: RawJSONが置き換えるmap[string]any経由の往復を計測するベンチマーク
+func BenchmarkMapRoundTrip(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var value map[string]interface{}
+		if err := json.Unmarshal(benchmarkPayload, &value); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := json.Marshal(value); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
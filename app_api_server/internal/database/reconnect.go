@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context" // context: コンテキスト、処理の文脈情報
+	"errors"  // errors: エラー操作
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"time"    // time: 時間操作機能
+)
+
+// defaultReconnectBackoff is the first wait of ReconnectContext when the retry policy sets none
+// defaultReconnectBackoff: リトライポリシーに指定がない場合のReconnectContextの最初の待機時間
+const defaultReconnectBackoff = 100 * time.Millisecond
+
+// ReconnectContext retries Reconnect with backoff until it succeeds or ctx ends
+// ReconnectContext: Reconnectが成功するかctxが終了するまで、バックオフを挟んで再試行する関数
+// The backoff follows the retry policy's InitialBackoff and MaxBackoff; MaxRetries does not apply
+// バックオフはリトライポリシーのInitialBackoffとMaxBackoffに従う、MaxRetriesは適用しない
+// It gives up at once on ErrCircuitOpen, ErrDriverClosed and rejected credentials, and each attempt's
+// dial and ping are bounded by ctx, so the call returns shortly after the deadline
+// ErrCircuitOpen、ErrDriverClosed、認証の拒否の場合はすぐに諦める、各試行のダイヤルとpingもctxで制限されるため期限の直後に戻る
+// give up: 諦める
+func (d *PostgreSQLDriver) ReconnectContext(ctx context.Context) (err error) {
+	ctx, span := d.startSpan(ctx, "db.ReconnectContext", "")
+	defer func() { endSpan(span, err) }()
+
+	start := d.now()
+	backoff := d.retryPolicy.InitialBackoff
+	if backoff <= 0 {
+		backoff = defaultReconnectBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := d.reconnect(ctx)
+		if err == nil {
+			if attempt > 1 {
+				d.logger.Info(fmt.Sprintf("Reconnected after %d attempt(s)", attempt), "attempts", attempt)
+			}
+			return nil
+		}
+		if errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrDriverClosed) || IsAuthenticationError(err) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return d.reconnectGaveUp(ctx, attempt, start, err)
+		}
+
+		d.logger.Warn(fmt.Sprintf("reconnect attempt %d failed, retrying in %s: %v", attempt, backoff, err),
+			"attempt", attempt, "backoff", backoff, "error", err.Error())
+
+		// Wait for the backoff unless the context ends first
+		// コンテキストが先に終了しない限りバックオフ時間だけ待つ
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return d.reconnectGaveUp(ctx, attempt, start, err)
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if maxBackoff := d.retryPolicy.MaxBackoff; maxBackoff > 0 && backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// reconnectGaveUp builds the error ReconnectContext returns when ctx ends, matching both ctx.Err() and the last failure
+// reconnectGaveUp: ctxの終了時にReconnectContextが返すエラーを作る関数、ctx.Err()と最後の失敗の両方に一致する
+func (d *PostgreSQLDriver) reconnectGaveUp(ctx context.Context, attempts int, start time.Time, last error) error {
+	elapsed := d.now().Sub(start).Round(time.Millisecond)
+	return fmt.Errorf("reconnect gave up after %d attempt(s) in %s: %w: %w", attempts, elapsed, ctx.Err(), last)
+}
+
+// ReconnectWithBudget is ReconnectContext bounded by maxElapsed
+// ReconnectWithBudget: maxElapsedで時間を制限したReconnectContext
+// budget: 予算、使える時間
+func (d *PostgreSQLDriver) ReconnectWithBudget(maxElapsed time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), maxElapsed)
+	defer cancel()
+	return d.ReconnectContext(ctx)
+}
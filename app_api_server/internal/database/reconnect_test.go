@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"      // context: コンテキスト
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"sync"         // sync: 開いたプールの記録の保護
+	"testing"      // testing: テスト機能
+	"time"         // time: 時間操作機能
+
+	"github.com/lib/pq" // pq: PostgreSQLドライバー、エラー型
+)
+
+// TestReconnectWithBudgetGivesUp tests that an unreachable host gives up shortly after the budget
+// TestReconnectWithBudgetGivesUp: 到達できないホストでは予算の直後に諦めることをテストする関数
+func TestReconnectWithBudgetGivesUp(t *testing.T) {
+	driver, err := NewPostgreSQLDriverWithConfig(&DatabaseConfig{
+		Host:           "127.0.0.1",
+		Port:           1, // nothing listens here: ここでは何も待ち受けていない
+		User:           "testuser",
+		Password:       "testpass",
+		Database:       "testdb",
+		SSLMode:        "disable",
+		ConnectTimeout: time.Second,
+	},
+		WithRetryPolicy(RetryPolicy{InitialBackoff: 20 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}),
+		WithCircuitBreaker(0, 0)) // keep retrying until the budget runs out: 予算が尽きるまで再試行を続ける
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	start := time.Now()
+	err = driver.ReconnectWithBudget(300 * time.Millisecond)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected errors.Is(err, context.DeadlineExceeded), got: %v", err)
+	}
+	if elapsed > time.Second {
+		t.Errorf("Expected to give up within 1s of a 300ms budget, took: %v", elapsed)
+	}
+	if driver.GetDB() != nil {
+		t.Errorf("Expected no pool after giving up, got one")
+	}
+}
+
+// TestReconnectContextClosesFailedPools tests that every pool opened by a failed attempt is closed
+// TestReconnectContextClosesFailedPools: 失敗した試行で開いたプールがすべて閉じられることをテストする関数
+func TestReconnectContextClosesFailedPools(t *testing.T) {
+	driver := newFakeConnectingDriver(t)
+	driver.retryPolicy = RetryPolicy{InitialBackoff: 5 * time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+	driver.breaker.threshold = 0
+
+	var mu sync.Mutex
+	var pools []*sql.DB
+	driver.openDB = func(string) (*sql.DB, error) {
+		fake, db := newFakeDB()
+		fake.ping = func(context.Context) error { return errors.New("connection refused") }
+		mu.Lock()
+		pools = append(pools, db)
+		mu.Unlock()
+		return db, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := driver.ReconnectContext(ctx); err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(pools) < 2 {
+		t.Fatalf("Expected several attempts, got: %d", len(pools))
+	}
+	for i, db := range pools {
+		if err := db.Ping(); err == nil || err.Error() != "sql: database is closed" {
+			t.Errorf("Expected pool %d to be closed, got: %v", i, err)
+		}
+	}
+}
+
+// TestReconnectContext tests retrying until success and giving up early when retrying cannot help
+// TestReconnectContext: 成功するまでの再試行と、再試行が無意味な場合にすぐ諦めることをテストする関数
+func TestReconnectContext(t *testing.T) {
+	testCases := []struct {
+		name         string
+		failures     int   // failures: 成功するまでに失敗する回数
+		openErr      error // open err: 常に返すオープンエラー
+		threshold    int   // threshold: サーキットブレーカーの閾値、0なら無効
+		expectError  error
+		expectOpened int
+	}{
+		{name: "Succeeds after failures", failures: 2, expectOpened: 3},
+		{name: "Circuit open", openErr: errors.New("connection refused"), threshold: 1, expectError: ErrCircuitOpen, expectOpened: 1},
+		{name: "Authentication rejected", openErr: &pq.Error{Code: "28P01"}, expectOpened: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver := newFakeConnectingDriver(t)
+			driver.retryPolicy = RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+			driver.breaker.threshold = tc.threshold
+
+			opened := 0
+			driver.openDB = func(string) (*sql.DB, error) {
+				opened++
+				if tc.openErr != nil {
+					return nil, tc.openErr
+				}
+				fake, db := newFakeDB()
+				if opened <= tc.failures {
+					fake.ping = func(context.Context) error { return errors.New("connection refused") }
+				}
+				return db, nil
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			err := driver.ReconnectContext(ctx)
+
+			switch {
+			case tc.expectError != nil && !errors.Is(err, tc.expectError):
+				t.Errorf("Expected errors.Is(err, %v), got: %v", tc.expectError, err)
+			case tc.openErr == nil && err != nil:
+				t.Errorf("Expected no error, got: %v", err)
+			case tc.openErr != nil && err == nil:
+				t.Error("Expected an error, got nil")
+			}
+			if errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Expected to stop before the deadline, got: %v", err)
+			}
+			if opened != tc.expectOpened {
+				t.Errorf("Expected %d attempt(s), got: %d", tc.expectOpened, opened)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"strings" // strings: 文字列操作
+)
+
+// redactedMask replaces secrets in logged or printed output
+// redactedMask: ログや表示で秘密情報を置き換える文字列
+// redacted: 伏せ字にされた、mask: 覆い隠すもの
+const redactedMask = "*****"
+
+// Redacted returns a copy of the configuration with secrets masked
+// Redacted: 秘密情報を伏せ字にした設定のコピーを返す関数
+// New secret fields must be masked here so String and logs stay safe
+// 新しい秘密情報のフィールドはここで伏せ字にし、Stringやログを安全に保つ
+func (c DatabaseConfig) Redacted() DatabaseConfig {
+	if c.Password != "" {
+		c.Password = redactedMask
+	}
+	if len(c.Options) > 0 {
+		// Options such as sslpassword carry secrets too: sslpasswordなどのオプションも秘密情報を含む
+		options := make(map[string]string, len(c.Options))
+		for key, value := range c.Options {
+			if strings.Contains(strings.ToLower(key), "password") {
+				value = redactedMask
+			}
+			options[key] = value
+		}
+		c.Options = options
+	}
+	return c
+}
+
+// String formats the configuration with secrets masked, so %v and %+v never leak them
+// String: 秘密情報を伏せ字にして設定を整形する関数、%vや%+vでも漏洩しない
+// leak: 漏洩する
+func (c DatabaseConfig) String() string {
+	r := c.Redacted()
+	return fmt.Sprintf(
+		"DatabaseConfig{Host: %s, Port: %d, User: %s, Password: %s, Database: %s, SSLMode: %s, SlowQueryThreshold: %s, ConnMaxIdleTime: %s, MaxOpenConns: %d, MaxIdleConns: %d, ConnMaxLifetime: %s, TimeZone: %s, ConnectTimeout: %s, DefaultQueryTimeout: %s, MinServerVersion: %s, Options: %v, Ports: %v, TargetSessionAttrs: %s, AuthMethod: %s, LockTimeout: %s, IdleInTransactionTimeout: %s, ReadOnly: %t, ConnMaxLifetimeJitter: %g, FallbackApplicationName: %s, DisableKeepalives: %t, KeepalivesIdle: %s, KeepalivesInterval: %s, KeepalivesCount: %d}",
+		r.Host, r.Port, r.User, r.Password, r.Database, r.SSLMode, r.SlowQueryThreshold, r.ConnMaxIdleTime, r.MaxOpenConns, r.MaxIdleConns, r.ConnMaxLifetime, r.TimeZone, r.ConnectTimeout, r.DefaultQueryTimeout, r.MinServerVersion, r.Options, r.Ports, r.TargetSessionAttrs, r.AuthMethod, r.LockTimeout, r.IdleInTransactionTimeout, r.ReadOnly, r.ConnMaxLifetimeJitter, r.FallbackApplicationName, r.DisableKeepalives, r.KeepalivesIdle, r.KeepalivesInterval, r.KeepalivesCount,
+	)
+}
+
+// GoString makes %#v use the redacted form as well
+// GoString: %#vでも伏せ字の形式を使うための関数
+func (c DatabaseConfig) GoString() string {
+	return c.String()
+}
+
+// RedactedConnectionString builds the connection string with the password masked
+// RedactedConnectionString: パスワードを伏せ字にした接続文字列を構築する関数
+func (c *DatabaseConfig) RedactedConnectionString() string {
+	redacted := c.Redacted()
+	return redacted.BuildConnectionString()
+}
+
+// redactedError hides secrets in an error message while keeping the original for errors.Is/As
+// redactedError: errors.Is/As用に元のエラーを保持しつつ、メッセージから秘密情報を隠すエラー型
+type redactedError struct {
+	message string // message: 伏せ字にしたメッセージ
+	err     error  // err: 元のエラー
+}
+
+func (e *redactedError) Error() string { return e.message }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// redactError masks the configured password if it appears in err's message
+// redactError: errのメッセージに設定のパスワードが含まれる場合に伏せ字にする関数
+// Driver errors can echo parts of the connection string, e.g. on parse failures
+// ドライバーのエラーは解析失敗時などに接続文字列の一部を含むことがある
+func (c *DatabaseConfig) redactError(err error) error {
+	if err == nil || c.Password == "" {
+		return err
+	}
+
+	// The connection string carries the escaped form when the password needs quoting
+	// パスワードに引用符が必要な場合、接続文字列にはエスケープ後の形式が含まれる
+	message := err.Error()
+	for _, secret := range []string{escapeDSNValue(c.Password), c.Password} {
+		message = strings.ReplaceAll(message, secret, redactedMask)
+	}
+	if message == err.Error() {
+		return err
+	}
+	return &redactedError{
+		message: message,
+		err:     err,
+	}
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"log"          // log: ログ出力機能
+	"strings"      // strings: 文字列操作
+	"testing"      // testing: テスト機能
+)
+
+// secretPassword is distinctive so any leak is easy to spot
+// secretPassword: 漏洩を見つけやすい特徴的なパスワード
+const secretPassword = "s3cr3t-P@ss"
+
+// newSecretConfig returns a valid configuration holding secretPassword
+// newSecretConfig: secretPasswordを含む有効な設定を返すテスト用関数
+func newSecretConfig() *DatabaseConfig {
+	return &DatabaseConfig{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "testuser",
+		Password: secretPassword,
+		Database: "testdb",
+		SSLMode:  "disable",
+	}
+}
+
+// TestConfigRedaction tests that formatted configurations never include the password
+// TestConfigRedaction: 整形した設定にパスワードが含まれないことをテストする関数
+func TestConfigRedaction(t *testing.T) {
+	config := newSecretConfig()
+
+	testCases := []struct {
+		name   string
+		output string
+	}{
+		{name: "String", output: config.String()},
+		{name: "Redacted", output: config.Redacted().Password},
+		{name: "Verb v on value", output: fmt.Sprintf("%v", *config)},
+		{name: "Verb +v on pointer", output: fmt.Sprintf("%+v", config)},
+		{name: "Verb #v", output: fmt.Sprintf("%#v", *config)},
+		{name: "Connection string", output: config.RedactedConnectionString()},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if strings.Contains(tc.output, secretPassword) {
+				t.Errorf("Expected password to be redacted, got: %s", tc.output)
+			}
+			if !strings.Contains(tc.output, redactedMask) {
+				t.Errorf("Expected mask in output, got: %s", tc.output)
+			}
+		})
+	}
+
+	if config.Password != secretPassword {
+		t.Error("Expected Redacted not to modify the original configuration")
+	}
+
+	expected := "host=localhost port=5432 user=testuser password=***** dbname=testdb sslmode=disable"
+	if actual := config.RedactedConnectionString(); actual != expected {
+		t.Errorf("Expected connection string '%s', got: '%s'", expected, actual)
+	}
+}
+
+// TestConnectFailureRedactsPassword tests that errors echoing the connection string are redacted
+// TestConnectFailureRedactsPassword: 接続文字列を含むエラーが伏せ字にされることをテストする関数
+// echoing: そのまま含む
+func TestConnectFailureRedactsPassword(t *testing.T) {
+	logs := captureLog(t)
+
+	driver, err := NewPostgreSQLDriverWithConfig(newSecretConfig())
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	cause := errors.New("parse error") // cause: 元のエラー
+	driver.openDB = func(connectionString string) (*sql.DB, error) {
+		return nil, fmt.Errorf("invalid connection string %q: %w", connectionString, cause)
+	}
+
+	connectErr := driver.Connect()
+	reconnectErr := driver.Reconnect()
+	for _, err := range []error{connectErr, reconnectErr} {
+		if err == nil {
+			t.Fatal("Expected connection error, got none")
+		}
+		if strings.Contains(err.Error(), secretPassword) {
+			t.Errorf("Expected password to be redacted from error, got: %v", err)
+		}
+		if !errors.Is(err, cause) {
+			t.Errorf("Expected redacted error to unwrap to the cause, got: %v", err)
+		}
+		log.Printf("Failed to connect to database: %v", err) // log as callers do: 呼び出し元と同様にログ出力する
+	}
+
+	if strings.Contains(logs.String(), secretPassword) {
+		t.Errorf("Expected no password in log output, got: %q", logs.String())
+	}
+}
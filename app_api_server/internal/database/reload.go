@@ -0,0 +1,254 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"maps"         // maps: マップ操作
+	"os"           // os: operating system（オペレーティングシステム）、シグナル型
+	"os/signal"    // signal: シグナルの受信
+	"slices"       // slices: スライス操作
+	"strings"      // strings: 文字列操作
+	"syscall"      // syscall: シグナル番号
+	"time"         // time: 時間操作機能
+)
+
+// reloadTimeout bounds a reload triggered by SIGHUP, including opening a new pool
+// reloadTimeout: SIGHUPによる再読み込み（新しいプールを開く処理を含む）の制限時間
+const reloadTimeout = 30 * time.Second
+
+// WithConfigLoader replaces LoadDatabaseConfig as the source ReloadConfig reads, for example to re-read a secret file
+// WithConfigLoader: ReloadConfigが読み込む設定の取得元をLoadDatabaseConfigから置き換えるオプション（シークレットファイルの再読み込みなど）
+func WithConfigLoader(loader func() (*DatabaseConfig, error)) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		d.configLoader = loader
+	}
+}
+
+// configDiff lists the fields that differ between two configurations, by how they are applied
+// configDiff: 2つの設定間で異なるフィールドを適用方法ごとに列挙する構造体
+type configDiff struct {
+	pool       []string // pool: プール設定、稼働中のプールにそのまま適用できる（旧→新の値）
+	runtime    []string // runtime: ドライバーが次の呼び出しから参照する設定
+	connection []string // connection: 接続の識別情報、新しいプールが必要（値は秘密情報を含みうるため名前のみ）
+}
+
+// empty reports whether nothing changed
+// empty: 何も変更されていないかを判定する関数
+func (c configDiff) empty() bool {
+	return len(c.pool) == 0 && len(c.runtime) == 0 && len(c.connection) == 0
+}
+
+// String lists the changed fields for the log
+// String: ログ出力用に変更されたフィールドを列挙する関数
+func (c configDiff) String() string {
+	var parts []string
+	parts = append(parts, c.pool...)
+	parts = append(parts, c.runtime...)
+	parts = append(parts, c.connection...)
+	return strings.Join(parts, ", ")
+}
+
+// diffConfig compares the active configuration with a reloaded one
+// diffConfig: 使用中の設定と再読み込みした設定を比較する関数
+func diffConfig(active, reloaded *DatabaseConfig) configDiff {
+	var diff configDiff
+	pool := func(name string, old, new interface{}) {
+		if old != new {
+			diff.pool = append(diff.pool, fmt.Sprintf("%s %v→%v", name, old, new))
+		}
+	}
+	runtime := func(name string, changed bool) {
+		if changed {
+			diff.runtime = append(diff.runtime, name)
+		}
+	}
+	connection := func(name string, changed bool) {
+		if changed {
+			diff.connection = append(diff.connection, name)
+		}
+	}
+
+	pool("MaxOpenConns", active.MaxOpenConns, reloaded.MaxOpenConns)
+	pool("MaxIdleConns", active.MaxIdleConns, reloaded.MaxIdleConns)
+	pool("ConnMaxLifetime", active.ConnMaxLifetime, reloaded.ConnMaxLifetime)
+	pool("ConnMaxIdleTime", active.ConnMaxIdleTime, reloaded.ConnMaxIdleTime)
+
+	runtime("SlowQueryThreshold", active.SlowQueryThreshold != reloaded.SlowQueryThreshold)
+	runtime("DefaultQueryTimeout", active.DefaultQueryTimeout != reloaded.DefaultQueryTimeout)
+	runtime("MinServerVersion", active.MinServerVersion != reloaded.MinServerVersion)
+
+	// Everything sent in the connection string needs new connections
+	// 接続文字列で送られる設定は全て新しい接続が必要
+	connection("Host", active.Host != reloaded.Host)
+	connection("Port", active.Port != reloaded.Port)
+	connection("Ports", !slices.Equal(active.Ports, reloaded.Ports))
+	connection("User", active.User != reloaded.User)
+	connection("Password", active.Password != reloaded.Password)
+	connection("Database", active.Database != reloaded.Database)
+	connection("SSLMode", active.SSLMode != reloaded.SSLMode)
+	connection("TimeZone", active.TimeZone != reloaded.TimeZone)
+	connection("ConnectTimeout", active.ConnectTimeout != reloaded.ConnectTimeout)
+	connection("Options", !maps.Equal(active.Options, reloaded.Options))
+	connection("TargetSessionAttrs", active.TargetSessionAttrs != reloaded.TargetSessionAttrs)
+	connection("AuthMethod", active.AuthMethod != reloaded.AuthMethod)
+	connection("LockTimeout", active.LockTimeout != reloaded.LockTimeout)
+	connection("IdleInTransactionTimeout", active.IdleInTransactionTimeout != reloaded.IdleInTransactionTimeout)
+	connection("ReadOnly", active.ReadOnly != reloaded.ReadOnly)
+	// Jitter is applied by the pool's connector, so it takes a new pool as well
+	// ゆらぎはプールのコネクターで適用されるため、同じく新しいプールが必要
+	connection("ConnMaxLifetimeJitter", active.ConnMaxLifetimeJitter != reloaded.ConnMaxLifetimeJitter)
+	connection("FallbackApplicationName", active.FallbackApplicationName != reloaded.FallbackApplicationName)
+	connection("Keepalives", active.DisableKeepalives != reloaded.DisableKeepalives ||
+		active.KeepalivesIdle != reloaded.KeepalivesIdle || active.KeepalivesInterval != reloaded.KeepalivesInterval ||
+		active.KeepalivesCount != reloaded.KeepalivesCount)
+	return diff
+}
+
+// ReloadConfig re-reads the configuration and applies what changed
+// ReloadConfig: 設定を再読み込みし、変更された部分を適用する関数
+// Pool settings are applied to the live pool in place; connection settings open a new pool that is
+// swapped in while the old one drains, as RotateCredentials does. On any error the active pool and
+// configuration are kept. Credentials rotated with RotateCredentials are replaced by the reloaded ones,
+// so the environment or secret file must be updated as well
+// プール設定は稼働中のプールにそのまま適用し、接続設定はRotateCredentialsと同じく新しいプールを開いて入れ替え、
+// 古いプールは段階的に閉じる。エラー時は使用中のプールと設定を維持する。
+// RotateCredentialsで変更した認証情報は再読み込みした値で置き換わるため、環境変数やシークレットファイルも更新すること
+func (d *PostgreSQLDriver) ReloadConfig(ctx context.Context) (err error) {
+	ctx, span := d.startSpan(ctx, "db.ReloadConfig", "")
+	defer func() { endSpan(span, err) }()
+
+	var changes string // changes: イベントログに記録する変更内容
+	defer func() { d.recordEvent(EventConfigReload, changes, err) }()
+
+	d.reloadMu.Lock()
+	defer d.reloadMu.Unlock()
+
+	loader := d.configLoader
+	if loader == nil {
+		loader = LoadDatabaseConfig
+	}
+	loaded, err := loader()
+	if err != nil {
+		return fmt.Errorf("failed to load database configuration: %w", err)
+	}
+	if err := validateDatabaseConfig(loaded); err != nil {
+		return fmt.Errorf("%w: %w", ErrConfigInvalid, err)
+	}
+	reloaded := cloneConfig(loaded)
+	reloaded.applyDefaults()
+
+	diff := diffConfig(d.GetConfig(), &reloaded)
+	changes = diff.String()
+	if diff.empty() {
+		d.logger.Info("Database configuration reloaded: no changes")
+		return nil
+	}
+
+	db := d.pool()
+	if db == nil {
+		// Not connected yet: the next Connect uses it
+		// 未接続の場合は次回のConnectで使われる
+		d.mu.Lock()
+		d.pendingConfig = &reloaded
+		d.mu.Unlock()
+		d.logger.Info(fmt.Sprintf("Database configuration reloaded for the next connect: %s", diff), "changes", diff.String())
+		return nil
+	}
+
+	if len(diff.connection) > 0 {
+		if d.config.Load().AuthMethod != AuthMethodPassword || reloaded.AuthMethod != AuthMethodPassword {
+			return fmt.Errorf("reloading connection settings (%s) requires password authentication; restart the service instead", strings.Join(diff.connection, ", "))
+		}
+		// A staged configuration is superseded by the reloaded one
+		// 準備済みの設定は再読み込みした設定で置き換わる
+		if err := d.swapPool(ctx, &reloaded, func() { d.pendingConfig = nil }); err != nil {
+			return fmt.Errorf("failed to open a pool with the reloaded configuration: %w", err)
+		}
+		d.logger.Info(fmt.Sprintf("Database configuration reloaded with a new pool: %s", diff), "changes", diff.String())
+		return nil
+	}
+
+	// Pool-settings-only fast path: no new connections are needed
+	// プール設定のみの高速な経路、新しい接続は不要
+	applyPoolSettings(db, &reloaded)
+	d.mu.Lock()
+	d.config.Store(&reloaded)
+	d.pendingConfig = nil
+	d.mu.Unlock()
+	d.logger.Info(fmt.Sprintf("Database configuration reloaded in place: %s", diff), "changes", diff.String())
+	return nil
+}
+
+// swapPool opens and verifies a pool for config, swaps it in with config and drains the previous pool
+// swapPool: configで新しいプールを開いて検証し、configと共に入れ替えて、以前のプールを段階的に閉じる関数
+// update runs under mu together with the swap; on error nothing is changed
+// updateは入れ替えと共にmuの保護下で実行される、エラー時は何も変更しない
+func (d *PostgreSQLDriver) swapPool(ctx context.Context, config *DatabaseConfig, update func()) error {
+	db, err := d.openPoolWith(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	previous := d.setPool(db)
+	d.config.Store(config)
+	if update != nil {
+		update()
+	}
+	d.lastRotationTime = d.now() // the new pool restarts its counters: 新しいプールはカウンターを0から始める
+	d.mu.Unlock()
+
+	d.drainPool(previous)
+	return nil
+}
+
+// applyPoolSettings sets the pool size and connection lifetimes from config
+// applyPoolSettings: configからプールの大きさと接続の寿命を設定する関数
+func applyPoolSettings(db *sql.DB, config *DatabaseConfig) {
+	db.SetMaxOpenConns(config.MaxOpenConns)            // maximum: 最大の、open: 開いている、connections: 接続（複数形）
+	db.SetMaxIdleConns(config.MaxIdleConns)            // idle: アイドル、待機中の
+	db.SetConnMaxLifetime(poolConnMaxLifetime(config)) // lifetime: 寿命、IAMトークンの有効期間未満に制限
+	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)      // idle time: アイドル時間、プロキシに切断される前に閉じる（0は無制限）
+}
+
+// EnableSIGHUPReload calls ReloadConfig on every SIGHUP until ctx is cancelled or the driver is closed
+// EnableSIGHUPReload: ctxのキャンセルまたはドライバーのCloseまで、SIGHUPを受けるたびにReloadConfigを呼び出す関数
+// A failed reload is logged and the active configuration is kept; the returned channel is closed when listening stops
+// 再読み込みの失敗はログに出力し使用中の設定を維持する、受信停止時に戻り値のチャネルを閉じる
+func (d *PostgreSQLDriver) EnableSIGHUPReload(ctx context.Context) <-chan struct{} {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	return d.reloadOnSignal(ctx, signals, func() { signal.Stop(signals) })
+}
+
+// reloadOnSignal runs ReloadConfig for every value received on signals, calling stop when it ends
+// reloadOnSignal: signalsで受信するたびにReloadConfigを実行し、終了時にstopを呼び出す関数
+func (d *PostgreSQLDriver) reloadOnSignal(ctx context.Context, signals <-chan os.Signal, stop func()) <-chan struct{} {
+	stopped := make(chan struct{}) // stopped: 停止済み通知
+	closing := d.closeSignal()
+
+	go func() {
+		defer close(stopped)
+		defer stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-closing:
+				return
+			case sig := <-signals:
+				d.logger.Info(fmt.Sprintf("Received %v, reloading database configuration", sig), "signal", sig.String())
+				reloadCtx, cancel := context.WithTimeout(ctx, reloadTimeout)
+				if err := d.ReloadConfig(reloadCtx); err != nil {
+					redacted := d.config.Load().redactError(err)
+					d.logger.Warn(fmt.Sprintf("database configuration reload failed, keeping the active configuration: %v", redacted), "error", redacted.Error())
+				}
+				cancel()
+			}
+		}
+	}()
+
+	return stopped
+}
@@ -0,0 +1,284 @@
+package database
+
+import (
+	"context"      // context: コンテキスト
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"os"           // os: シグナル型
+	"strings"      // strings: 文字列操作
+	"syscall"      // syscall: シグナル番号
+	"testing"      // testing: テスト機能
+	"time"         // time: 時間操作機能
+)
+
+// newReloadTestDriver connects a fake driver whose ReloadConfig reads a copy of its config changed by edit
+// newReloadTestDriver: ReloadConfigがeditで変更した設定のコピーを読み込む、接続済みのフェイクドライバーを作成する関数
+func newReloadTestDriver(t *testing.T, edit func(*DatabaseConfig)) (*PostgreSQLDriver, *int) {
+	t.Helper()
+
+	driver := newFakeConnectingDriver(t)
+	opens := 0
+	driver.openDB = func(string) (*sql.DB, error) {
+		opens++
+		_, db := newFakeDB()
+		return db, nil
+	}
+	driver.poolDrainDelay = 0
+	driver.configLoader = func() (*DatabaseConfig, error) {
+		config := driver.GetConfig()
+		edit(config)
+		return config, nil
+	}
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	t.Cleanup(func() { driver.Close() })
+	return driver, &opens
+}
+
+// TestDiffConfig tests how changed fields are classified
+// TestDiffConfig: 変更されたフィールドの分類をテストする関数
+func TestDiffConfig(t *testing.T) {
+	testCases := []struct {
+		name               string
+		edit               func(*DatabaseConfig)
+		expectedPool       int // expected pool: プール設定の変更数
+		expectedRuntime    int // expected runtime: 実行時設定の変更数
+		expectedConnection int // expected connection: 接続設定の変更数
+	}{
+		{name: "No change", edit: func(c *DatabaseConfig) {}},
+		{name: "Pool sizes", edit: func(c *DatabaseConfig) { c.MaxOpenConns = 50; c.MaxIdleConns = 10 }, expectedPool: 2},
+		{name: "Lifetimes", edit: func(c *DatabaseConfig) { c.ConnMaxLifetime = time.Minute; c.ConnMaxIdleTime = time.Second }, expectedPool: 2},
+		{name: "Slow query threshold", edit: func(c *DatabaseConfig) { c.SlowQueryThreshold = time.Second }, expectedRuntime: 1},
+		{name: "Host", edit: func(c *DatabaseConfig) { c.Host = "db2.example.com" }, expectedConnection: 1},
+		{name: "Password", edit: func(c *DatabaseConfig) { c.Password = "rotated" }, expectedConnection: 1},
+		{name: "Options", edit: func(c *DatabaseConfig) { c.Options = map[string]string{"application_name": "sift"} }, expectedConnection: 1},
+		{name: "Mixed", edit: func(c *DatabaseConfig) { c.MaxOpenConns = 5; c.SSLMode = "require" }, expectedPool: 1, expectedConnection: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			active := &DatabaseConfig{Host: "localhost", Port: 5432, User: "user", Password: "secret", Database: "db", SSLMode: "disable"}
+			active.applyDefaults()
+			reloaded := cloneConfig(active)
+			tc.edit(&reloaded)
+
+			diff := diffConfig(active, &reloaded)
+			if len(diff.pool) != tc.expectedPool || len(diff.runtime) != tc.expectedRuntime || len(diff.connection) != tc.expectedConnection {
+				t.Errorf("Expected %d pool, %d runtime and %d connection changes, got: %+v",
+					tc.expectedPool, tc.expectedRuntime, tc.expectedConnection, diff)
+			}
+			if strings.Contains(diff.String(), "secret") || strings.Contains(diff.String(), "rotated") {
+				t.Errorf("Expected no password in the diff, got: %s", diff)
+			}
+		})
+	}
+}
+
+// TestReloadConfigPoolSettingsOnly tests that pool settings are applied to the live pool without a new one
+// TestReloadConfigPoolSettingsOnly: プール設定が新しいプールなしで稼働中のプールに適用されることをテストする関数
+func TestReloadConfigPoolSettingsOnly(t *testing.T) {
+	driver, opens := newReloadTestDriver(t, func(c *DatabaseConfig) {
+		c.MaxOpenConns = 7
+		c.MaxIdleConns = 3
+		c.SlowQueryThreshold = time.Second
+	})
+	logs := captureLog(t)
+	before := driver.pool()
+
+	if err := driver.ReloadConfig(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if *opens != 1 || driver.pool() != before {
+		t.Errorf("Expected the live pool to be kept, got %d opens", *opens)
+	}
+	if got := driver.pool().Stats().MaxOpenConnections; got != 7 {
+		t.Errorf("Expected max open connections 7 on the live pool, got: %d", got)
+	}
+	stats := driver.GetConnectionStats()
+	if stats.ConfiguredMaxOpen != 7 || stats.ConfiguredMaxIdle != 3 {
+		t.Errorf("Expected configured 7/3, got: %d/%d", stats.ConfiguredMaxOpen, stats.ConfiguredMaxIdle)
+	}
+	if driver.GetConfig().SlowQueryThreshold != time.Second {
+		t.Errorf("Expected the slow query threshold to be reloaded, got: %s", driver.GetConfig().SlowQueryThreshold)
+	}
+	if !strings.Contains(logs.String(), "MaxOpenConns 25→7") {
+		t.Errorf("Expected the old and new values to be logged, got: %s", logs.String())
+	}
+}
+
+// TestReloadConfigSwapsPool tests that a connection setting change swaps in a new pool
+// TestReloadConfigSwapsPool: 接続設定の変更で新しいプールに入れ替わることをテストする関数
+func TestReloadConfigSwapsPool(t *testing.T) {
+	driver, opens := newReloadTestDriver(t, func(c *DatabaseConfig) { c.Host = "db2.example.com" })
+	before := driver.pool()
+
+	if err := driver.ReloadConfig(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if *opens != 2 || driver.pool() == before {
+		t.Errorf("Expected a new pool, got %d opens", *opens)
+	}
+	if got := driver.GetConfig().Host; got != "db2.example.com" {
+		t.Errorf("Expected host db2.example.com, got: %s", got)
+	}
+	if driver.GetConnectionStats().LastRotationTime.IsZero() {
+		t.Error("Expected the pool swap to be recorded")
+	}
+
+	// The previous pool is drained: 以前のプールは段階的に閉じられる
+	deadline := time.Now().Add(time.Second)
+	for before.Ping() == nil && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if before.Ping() == nil {
+		t.Error("Expected the previous pool to be closed")
+	}
+}
+
+// TestReloadConfigConcurrentQueries tests that queries read the configuration safely while ReloadConfig replaces it
+// TestReloadConfigConcurrentQueries: ReloadConfigが設定を置き換える間もクエリが安全に設定を読み込めることをテストする関数
+// Run with -race: -raceで実行する
+func TestReloadConfigConcurrentQueries(t *testing.T) {
+	reloads := 0
+	driver, _ := newReloadTestDriver(t, func(c *DatabaseConfig) {
+		// Alternate between an in-place change and one that swaps the pool: その場での変更とプールの入れ替えを交互に行う
+		reloads++
+		c.SlowQueryThreshold = time.Duration(reloads) * time.Millisecond
+		if reloads%2 == 0 {
+			c.Password = fmt.Sprintf("rotated-%d", reloads)
+		}
+	})
+
+	// Give queries that picked up a previous pool time to finish: 以前のプールを取得したクエリが完了する時間を与える
+	driver.poolDrainDelay = time.Second
+
+	started := make(chan struct{})
+	done := make(chan struct{})
+	queried := make(chan struct{})
+	go func() {
+		defer close(queried)
+		for i := 0; ; i++ {
+			if _, err := driver.ExecContext(context.Background(), "SELECT 1"); err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+				return
+			}
+			driver.GetTimeZone()
+			driver.GetQueryStats()
+			if i == 0 {
+				close(started)
+			}
+			select {
+			case <-done:
+				return
+			default:
+			}
+		}
+	}()
+
+	<-started
+	for i := 0; i < 20; i++ {
+		if err := driver.ReloadConfig(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+	close(done)
+	<-queried
+
+	if got := driver.GetConfig().SlowQueryThreshold; got != 20*time.Millisecond {
+		t.Errorf("Expected the last reload to be active, got: %s", got)
+	}
+}
+
+// TestReloadConfigKeepsActiveOnError tests that a failed reload changes nothing
+// TestReloadConfigKeepsActiveOnError: 再読み込みに失敗した場合に何も変更されないことをテストする関数
+func TestReloadConfigKeepsActiveOnError(t *testing.T) {
+	testCases := []struct {
+		name         string
+		edit         func(*DatabaseConfig)
+		failOpen     bool // fail open: 新しいプールを開く処理を失敗させる
+		errorContent string
+	}{
+		{name: "Invalid configuration", edit: func(c *DatabaseConfig) { c.Host = "db2"; c.MaxIdleConns = 50 }, errorContent: "cannot exceed"},
+		{name: "New pool fails", edit: func(c *DatabaseConfig) { c.Host = "db2" }, failOpen: true, errorContent: "connection refused"},
+		{name: "Non-password auth", edit: func(c *DatabaseConfig) { c.Host = "db2"; c.AuthMethod = AuthMethodAWSIAM; c.SSLMode = "require" }, errorContent: "requires password authentication"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, _ := newReloadTestDriver(t, tc.edit)
+			if tc.failOpen {
+				driver.openDB = func(string) (*sql.DB, error) { return nil, errors.New("connection refused") }
+			}
+			before := driver.pool()
+
+			err := driver.ReloadConfig(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+				t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+			}
+			if driver.pool() != before || driver.GetConfig().Host != "localhost" {
+				t.Errorf("Expected the active pool and configuration to be kept, got host: %s", driver.GetConfig().Host)
+			}
+			if err := before.Ping(); err != nil {
+				t.Errorf("Expected the active pool to stay open, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestReloadConfigBeforeConnect tests that a reload before Connect is used by the next Connect
+// TestReloadConfigBeforeConnect: Connect前の再読み込みが次のConnectで使われることをテストする関数
+func TestReloadConfigBeforeConnect(t *testing.T) {
+	driver := newFakeConnectingDriver(t)
+	driver.configLoader = func() (*DatabaseConfig, error) {
+		config := driver.GetConfig()
+		config.MaxOpenConns = 4
+		config.MaxIdleConns = 2
+		return config, nil
+	}
+
+	if err := driver.ReloadConfig(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer driver.Close()
+
+	if got := driver.pool().Stats().MaxOpenConnections; got != 4 {
+		t.Errorf("Expected max open connections 4, got: %d", got)
+	}
+}
+
+// TestReloadOnSignal tests that each signal triggers a reload and that listening stops on Close
+// TestReloadOnSignal: シグナルごとに再読み込みが行われ、Closeで受信を停止することをテストする関数
+func TestReloadOnSignal(t *testing.T) {
+	driver, _ := newReloadTestDriver(t, func(c *DatabaseConfig) { c.MaxOpenConns = 9 })
+	signals := make(chan os.Signal)
+	stopCalled := make(chan struct{})
+	stopped := driver.reloadOnSignal(context.Background(), signals, func() { close(stopCalled) })
+
+	signals <- syscall.SIGHUP
+	deadline := time.Now().Add(time.Second)
+	for driver.GetConfig().MaxOpenConns != 9 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := driver.GetConfig().MaxOpenConns; got != 9 {
+		t.Errorf("Expected the signal to reload max open connections 9, got: %d", got)
+	}
+
+	driver.Close()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the listener to stop on Close")
+	}
+	select {
+	case <-stopCalled:
+	default:
+		t.Error("Expected signal delivery to be stopped")
+	}
+}
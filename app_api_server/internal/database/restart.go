@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"strings"      // strings: 文字列操作
+	"sync/atomic"  // atomic: アトミック操作、再接続中の印
+
+	"github.com/lib/pq" // pq: PostgreSQLドライバー、エラー型
+)
+
+// A server restart shows up as admin/crash shutdown errors on the connections it killed, and after a
+// minor-version upgrade as "cached plan must not change result type" on connections that survived.
+// Either way the cached server version may be stale and the pool's sessions are not worth keeping, so
+// the statement pipeline clears the version cache, records the restart and reconnects in the background.
+// Prepared statements live in the pool's sessions, so swapping the pool flushes them with it.
+// サーバーの再起動は、切断された接続ではadmin/crash shutdownのエラーとして、マイナーバージョンの更新後に
+// 残った接続では"cached plan must not change result type"として現れる。いずれの場合もキャッシュした
+// サーバーバージョンが古い可能性があり、プールのセッションを残す価値はないため、文のパイプラインで
+// バージョンのキャッシュを消去し、再起動を記録してバックグラウンドで再接続する。
+// プリペアドステートメントはプールのセッションに属するため、プールの入れ替えで一緒に破棄される
+
+// sqlStateFeatureNotSupported is the class 0A code the server reports for a stale cached plan
+// sqlStateFeatureNotSupported: 古いキャッシュ済みプランに対してサーバーが報告するクラス0Aのコード
+const sqlStateFeatureNotSupported = "0A000"
+
+// cachedPlanChanged is the message that distinguishes a stale plan from other 0A000 errors
+// cachedPlanChanged: 古いプランを他の0A000エラーと区別するメッセージ
+const cachedPlanChanged = "cached plan must not change result type"
+
+// isServerRestartError reports whether err shows the server restarted under the pool
+// isServerRestartError: errがプールの下でサーバーが再起動したことを示すかどうかを判定する関数
+func isServerRestartError(err error) bool {
+	pqErr := asPQError(err)
+	if pqErr == nil {
+		return false
+	}
+	switch pqErr.Code {
+	case "57P01", "57P02", "57P03": // admin_shutdown, crash_shutdown, cannot_connect_now
+		return true
+	case sqlStateFeatureNotSupported:
+		return isCachedPlanChanged(pqErr)
+	}
+	return false
+}
+
+// isCachedPlanChanged reports whether pqErr is the stale cached plan error
+// isCachedPlanChanged: pqErrが古いキャッシュ済みプランのエラーかどうかを判定する関数
+func isCachedPlanChanged(pqErr *pq.Error) bool {
+	return pqErr.Code == sqlStateFeatureNotSupported && strings.Contains(pqErr.Message, cachedPlanChanged)
+}
+
+// detectServerRestart clears the server version cache, records the restart and starts a background
+// reconnect when err shows the server restarted; concurrent detections share one reconnect
+// detectServerRestart: errがサーバーの再起動を示す場合、サーバーバージョンのキャッシュを消去し、再起動を記録して
+// バックグラウンドで再接続を開始する関数、同時に検出した場合は1回の再接続を共有する
+func (d *PostgreSQLDriver) detectServerRestart(err error) {
+	if !isServerRestartError(err) {
+		return
+	}
+	failed := d.pool()
+	if failed == nil || !atomic.CompareAndSwapInt32(&d.restartReconnecting, 0, 1) {
+		return // closed, or already reconnecting: 閉じている、または再接続中
+	}
+
+	d.mu.Lock()
+	d.serverVersionNum = 0 // queried again on next use: 次の使用時に再度問い合わせる
+	d.mu.Unlock()
+	code := string(asPQError(err).Code)
+	d.recordEvent(EventServerRestartDetected, code, err)
+	redacted := d.config.Load().redactError(err)
+	d.logger.Warn(fmt.Sprintf("database server restart detected (%s), reconnecting in the background: %v", code, redacted), "sqlstate", code, "error", redacted.Error())
+
+	go func() {
+		defer atomic.StoreInt32(&d.restartReconnecting, 0)
+		err := d.reconnectOnce(failed, func() error { return d.reconnectAfterRestart(failed) })
+		if err != nil && !d.isClosed() {
+			redacted := d.config.Load().redactError(err)
+			d.logger.Warn(fmt.Sprintf("background reconnect after server restart failed: %v", redacted), "error", redacted.Error())
+		}
+	}()
+}
+
+// reconnectAfterRestart replaces failed with a new pool, draining failed instead of closing it so statements
+// already running on it are not cut off; callers run it through reconnectOnce
+// reconnectAfterRestart: failedを新しいプールに置き換える関数、実行中の文を中断しないよう、failedは閉じずに段階的に閉じる
+// 呼び出し元はreconnectOnceを通して実行する
+func (d *PostgreSQLDriver) reconnectAfterRestart(failed *sql.DB) error {
+	d.mu.Lock()
+	if d.closed || d.pool() != failed {
+		d.mu.Unlock()
+		return nil // closed or already replaced: 閉じている、または既に入れ替え済み
+	}
+	generation := d.refreshGen
+	transition, err := d.allowReconnectLocked()
+	d.mu.Unlock()
+	runTransition(transition)
+	if err != nil {
+		return err
+	}
+	d.recordEvent(EventReconnectAttempt, "server restart", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), credentialRefreshTimeout)
+	defer cancel()
+	db, lease, err := d.openPool(ctx)
+
+	d.mu.Lock()
+	if err == nil && (d.closed || generation != d.refreshGen) {
+		// Closed or reconnected while the new pool was opening
+		// 新しいプールを開いている間にClose、または再接続された
+		d.mu.Unlock()
+		db.Close()
+		return nil
+	}
+	transition = d.recordReconnectLocked(err)
+	var previous *sql.DB
+	if err == nil {
+		previous = d.setPool(db)
+		d.lastConnectTime = d.now()
+		d.lastReconnectTime = d.lastConnectTime
+		d.totalReconnects++
+	}
+	d.mu.Unlock()
+	runTransition(transition)
+
+	if err != nil {
+		d.recordEvent(EventReconnectFailure, "server restart", err)
+		return err
+	}
+	d.scheduleCredentialRefresh(lease)
+	d.recordEvent(EventReconnectSuccess, "server restart", nil)
+	d.fireReconnect()
+	d.drainPool(previous)
+	return nil
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"sync/atomic"                   // atomic: アトミック操作
+	"testing"                       // testing: テスト機能
+	"time"                          // time: 時間操作
+
+	"github.com/lib/pq" // pq: PostgreSQLドライバー、エラー型
+)
+
+// TestIsServerRestartError tests which errors are taken as a server restart
+// TestIsServerRestartError: どのエラーをサーバーの再起動とみなすかをテストする関数
+func TestIsServerRestartError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"admin shutdown", &pq.Error{Code: "57P01"}, true},
+		{"crash shutdown", &pq.Error{Code: "57P02"}, true},
+		{"cannot connect now", &pq.Error{Code: "57P03"}, true},
+		{"cached plan changed", &pq.Error{Code: "0A000", Message: "cached plan must not change result type"}, true},
+		{"other feature not supported", &pq.Error{Code: "0A000", Message: "cannot use window function here"}, false},
+		{"unique violation", &pq.Error{Code: "23505"}, false},
+		{"bad connection", sqldriver.ErrBadConn, false},
+		{"nil", nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isServerRestartError(tc.err); got != tc.expected {
+				t.Errorf("Expected %v, got: %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// waitForEvent waits until the driver recorded an event of eventType
+// waitForEvent: ドライバーがeventTypeのイベントを記録するまで待つ関数
+func waitForEvent(t *testing.T, driver *PostgreSQLDriver, eventType LifecycleEventType) LifecycleEvent {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, event := range driver.Events(0) {
+			if event.Type == eventType {
+				return event
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Expected a %s event, got: %v", eventType, eventTypes(driver.Events(0)))
+	return LifecycleEvent{}
+}
+
+// TestServerRestartReconnectsInBackground tests that a stale plan clears the version cache and reconnects
+// TestServerRestartReconnectsInBackground: 古いプランでバージョンのキャッシュが消去され再接続されることをテストする関数
+func TestServerRestartReconnectsInBackground(t *testing.T) {
+	stalePlan := &pq.Error{Code: "0A000", Message: "cached plan must not change result type"}
+	driver, opens := newReconnectTestDriver(t, stalePlan)
+	driver.mu.Lock()
+	driver.serverVersionNum = 150004
+	driver.mu.Unlock()
+
+	if _, err := driver.ExecContext(context.Background(), "UPDATE t SET n = 1"); err == nil {
+		t.Fatal("Expected the statement to fail")
+	}
+	driver.mu.Lock()
+	version := driver.serverVersionNum
+	driver.mu.Unlock()
+	if version != 0 {
+		t.Errorf("Expected the server version cache to be cleared, got: %d", version)
+	}
+
+	detected := waitForEvent(t, driver, EventServerRestartDetected)
+	if detected.Detail != "0A000" {
+		t.Errorf("Expected the SQLSTATE in the event detail, got: %q", detected.Detail)
+	}
+	waitForEvent(t, driver, EventReconnectSuccess)
+	if *opens != 2 {
+		t.Errorf("Expected one background reconnect, got %d pool opens", *opens)
+	}
+
+	if _, err := driver.ExecContext(context.Background(), "UPDATE t SET n = 1"); err != nil {
+		t.Errorf("Expected the new pool to succeed, got: %v", err)
+	}
+}
+
+// TestServerRestartRetriesOnce tests that a retrying helper shares the reconnect and retries once
+// TestServerRestartRetriesOnce: 再試行するヘルパーが再接続を共有し1回だけ再試行することをテストする関数
+func TestServerRestartRetriesOnce(t *testing.T) {
+	driver, opens := newReconnectTestDriver(t, &pq.Error{Code: "57P01"})
+
+	if _, err := driver.ExecWithReconnect(context.Background(), "UPDATE t SET n = 1"); err != nil {
+		t.Fatalf("Expected the retry to succeed, got: %v", err)
+	}
+	waitForEvent(t, driver, EventServerRestartDetected)
+
+	// Whichever of the retry and the background reconnect runs second finds the pool already replaced
+	// 再試行とバックグラウンドの再接続のうち後に実行された方は、プールが入れ替え済みだと判断する
+	for i := 0; i < 1000 && atomic.LoadInt32(&driver.restartReconnecting) != 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if *opens != 2 {
+		t.Errorf("Expected a single reconnect, got %d pool opens", *opens)
+	}
+	if got := driver.GetConnectionStats().ReconnectRecoveries; got != 1 {
+		t.Errorf("Expected 1 recovered statement, got: %d", got)
+	}
+}
+
+// TestStatementErrorIsNotRestart tests that ordinary statement errors leave the pool alone
+// TestStatementErrorIsNotRestart: 通常の文のエラーではプールに触れないことをテストする関数
+func TestStatementErrorIsNotRestart(t *testing.T) {
+	driver, opens := newReconnectTestDriver(t, &pq.Error{Code: "23505"})
+
+	if _, err := driver.ExecContext(context.Background(), "INSERT INTO t VALUES (1)"); err == nil {
+		t.Fatal("Expected the statement to fail")
+	}
+	for _, event := range driver.Events(0) {
+		if event.Type == EventServerRestartDetected {
+			t.Errorf("Expected no restart event, got: %v", event)
+		}
+	}
+	if *opens != 1 {
+		t.Errorf("Expected no reconnect, got %d pool opens", *opens)
+	}
+}
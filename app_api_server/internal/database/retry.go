@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"             // context: コンテキスト、処理の文脈情報
+	"database/sql"        // sql: データベース操作用パッケージ
+	"database/sql/driver" // driver: SQLドライバーインターフェース
+	"errors"              // errors: エラー操作
+	"fmt"                 // fmt: format（フォーマット）、文字列フォーマット機能
+	"io"                  // io: 入出力、EOFエラー
+	"syscall"             // syscall: システムコールのエラー番号
+	"time"                // time: 時間操作機能
+)
+
+// RetryPolicy controls how the retry helpers retry transient errors
+// RetryPolicy: 一時的なエラーの再試行方法を制御する構造体
+// transient: 一時的な
+type RetryPolicy struct {
+	MaxRetries     int           // max retries: 最大再試行回数（初回実行を含まない）
+	InitialBackoff time.Duration // initial backoff: 最初の待機時間
+	MaxBackoff     time.Duration // max backoff: 待機時間の上限
+}
+
+// DefaultRetryPolicy retries three times starting at 100ms and doubling up to 1s
+// DefaultRetryPolicy: 100msから倍々で最大1秒まで、3回再試行するデフォルトポリシー
+// doubling: 倍増する
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:     3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     time.Second,
+}
+
+// WithRetryPolicy sets the policy used by ExecWithRetry and QueryRowWithRetry
+// WithRetryPolicy: ExecWithRetryとQueryRowWithRetryで使うポリシーを設定するオプション
+func WithRetryPolicy(policy RetryPolicy) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		d.retryPolicy = policy
+	}
+}
+
+// IsTransientError reports whether err is a connection-level failure worth retrying
+// IsTransientError: errが再試行に値する接続レベルの障害かどうかを判定する関数
+// worth: 値する
+// Context cancellation and statement errors such as constraint violations are never transient
+// コンテキストのキャンセルや制約違反などの文レベルのエラーは一時的とみなさない
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false // caller gave up: 呼び出し側が中止した
+	}
+
+	if pqErr := asPQError(err); pqErr != nil {
+		switch pqErr.Code {
+		case "57P01", "57P02", "57P03": // admin_shutdown, crash_shutdown, cannot_connect_now
+			return true
+		case sqlStateFeatureNotSupported: // a plan cached before a server upgrade: サーバー更新前にキャッシュされたプラン
+			return isCachedPlanChanged(pqErr)
+		}
+		return false // any other server error is statement-level: その他は文レベルのエラー
+	}
+
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// ExecWithRetry executes a statement, retrying transient errors according to the retry policy
+// ExecWithRetry: 文を実行し、一時的なエラーはリトライポリシーに従って再試行する関数
+// according to: に従って
+func (d *PostgreSQLDriver) ExecWithRetry(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := d.retry(ctx, func() error {
+		var err error
+		result, err = d.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+// QueryRowWithRetry executes a single-row query, retrying transient errors according to the retry policy
+// QueryRowWithRetry: 単一行クエリを実行し、一時的なエラーはリトライポリシーに従って再試行する関数
+func (d *PostgreSQLDriver) QueryRowWithRetry(ctx context.Context, query string, args ...interface{}) *Row {
+	var row *Row
+	err := d.retry(ctx, func() error {
+		if row != nil && row.cancel != nil {
+			row.cancel() // release the failed attempt: 失敗した試行を解放する
+		}
+		row = d.QueryRowContext(ctx, query, args...)
+		return row.Err()
+	})
+	if row == nil {
+		return &Row{err: err} // cancelled before the first attempt: 初回実行前にキャンセル
+	}
+	return row
+}
+
+// retry runs op until it succeeds, fails with a non-transient error, or retries are exhausted
+// retry: opが成功するか、一時的でないエラーか、再試行回数を使い切るまで実行する関数
+// exhausted: 使い切った
+func (d *PostgreSQLDriver) retry(ctx context.Context, op func() error) error {
+	return d.retryWhen(ctx, "transient database error", IsTransientError, op)
+}
+
+// retryWhen runs op until it succeeds, fails with an error retryable rejects, or retries are exhausted
+// retryWhen: opが成功するか、retryableが拒否するエラーか、再試行回数を使い切るまで実行する関数
+// what names the retried error in the warning: whatは警告で再試行するエラーを示す
+func (d *PostgreSQLDriver) retryWhen(ctx context.Context, what string, retryable func(error) bool, op func() error) error {
+	policy := d.retryPolicy
+	backoff := policy.InitialBackoff
+
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := op()
+		if err == nil || !retryable(err) || attempt >= policy.MaxRetries {
+			return err
+		}
+
+		redacted := d.config.Load().redactError(err)
+		d.logger.Warn(fmt.Sprintf("%s, retrying in %s (attempt %d/%d): %v", what, backoff, attempt+1, policy.MaxRetries, redacted),
+			"retrying", what, "backoff", backoff, "attempt", attempt+1, "max_retries", policy.MaxRetries, "error", redacted.Error())
+
+		// Wait for the backoff unless the context ends first
+		// コンテキストが先に終了しない限りバックオフ時間だけ待つ
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"fmt"                           // fmt: フォーマット
+	"net"                           // net: ネットワーク
+	"os"                            // os: OSエラー
+	"syscall"                       // syscall: システムコールのエラー番号
+	"testing"                       // testing: テスト機能
+	"time"                          // time: 時間操作機能
+
+	"github.com/lib/pq" // pq: PostgreSQLドライバー、エラー型
+)
+
+// dialError builds the error net returns for a failed TCP dial
+// dialError: TCP接続失敗時にnetが返すエラーを構築するテスト用関数
+func dialError(errno syscall.Errno) error {
+	return &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", errno)}
+}
+
+// TestIsTransientError tests classification of each error class
+// TestIsTransientError: 各エラー分類の判定をテストする関数
+// classification: 分類
+func TestIsTransientError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "Nil error", err: nil, expected: false},
+		{name: "Connection refused", err: dialError(syscall.ECONNREFUSED), expected: true},
+		{name: "Connection reset", err: &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, expected: true},
+		{name: "Bad connection", err: sqldriver.ErrBadConn, expected: true},
+		{name: "Wrapped bad connection", err: fmt.Errorf("query failed: %w", sqldriver.ErrBadConn), expected: true},
+		{name: "Admin shutdown", err: &pq.Error{Code: "57P01"}, expected: true},
+		{name: "Cannot connect now", err: &pq.Error{Code: "57P03"}, expected: true},
+		{name: "Unique violation", err: &pq.Error{Code: "23505"}, expected: false},
+		{name: "Syntax error", err: &pq.Error{Code: "42601"}, expected: false},
+		{name: "Context canceled", err: context.Canceled, expected: false},
+		{name: "Deadline exceeded", err: fmt.Errorf("query: %w", context.DeadlineExceeded), expected: false},
+		{name: "Plain error", err: errors.New("something else"), expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := IsTransientError(tc.err); actual != tc.expected {
+				t.Errorf("Expected IsTransientError=%v for %v, got: %v", tc.expected, tc.err, actual)
+			}
+		})
+	}
+}
+
+// newRetryTestDriver creates a fake-backed driver with a fast retry policy
+// newRetryTestDriver: 短い再試行ポリシーのフェイクドライバーを作成するテスト用関数
+func newRetryTestDriver(t *testing.T) (*PostgreSQLDriver, *fakeDB) {
+	t.Helper()
+
+	captureLog(t)
+	driver, fake := newTestDriver(t)
+	WithRetryPolicy(RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond})(driver)
+	return driver, fake
+}
+
+// failingExec returns an exec func that fails with errs in order, then succeeds
+// failingExec: errsを順に返した後に成功するexec関数を返すテスト用関数
+func failingExec(calls *int, errs ...error) func(string, []sqldriver.NamedValue) (sqldriver.Result, error) {
+	return func(string, []sqldriver.NamedValue) (sqldriver.Result, error) {
+		*calls++
+		if *calls <= len(errs) {
+			return nil, errs[*calls-1]
+		}
+		return sqldriver.RowsAffected(1), nil
+	}
+}
+
+// TestExecWithRetry tests retry decisions for each error class
+// TestExecWithRetry: 各エラー分類での再試行の判断をテストする関数
+func TestExecWithRetry(t *testing.T) {
+	testCases := []struct {
+		name          string
+		errs          []error
+		expectError   bool
+		expectedCalls int
+	}{
+		{name: "Success without retry", errs: nil, expectedCalls: 1},
+		{name: "Refused then success", errs: []error{dialError(syscall.ECONNREFUSED)}, expectedCalls: 2},
+		{name: "Admin shutdown twice then success", errs: []error{&pq.Error{Code: "57P01"}, &pq.Error{Code: "57P01"}}, expectedCalls: 3},
+		{name: "Gives up after max retries", errs: []error{dialError(syscall.ECONNRESET), dialError(syscall.ECONNRESET), dialError(syscall.ECONNRESET)}, expectError: true, expectedCalls: 3},
+		{name: "Constraint violation is not retried", errs: []error{&pq.Error{Code: "23505"}}, expectError: true, expectedCalls: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, fake := newRetryTestDriver(t)
+			calls := 0
+			fake.exec = failingExec(&calls, tc.errs...)
+
+			_, err := driver.ExecWithRetry(context.Background(), "UPDATE app.users SET is_active = true")
+			if tc.expectError != (err != nil) {
+				t.Errorf("Expected error=%v, got: %v", tc.expectError, err)
+			}
+			if calls != tc.expectedCalls {
+				t.Errorf("Expected %d calls, got: %d", tc.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+// TestExecWithRetryBadConn tests recovery from driver.ErrBadConn
+// TestExecWithRetryBadConn: driver.ErrBadConnからの回復をテストする関数
+func TestExecWithRetryBadConn(t *testing.T) {
+	driver, fake := newRetryTestDriver(t)
+
+	// database/sql retries ErrBadConn internally before returning it
+	// database/sqlは内部でErrBadConnを再試行してから返す
+	calls := 0
+	fake.exec = failingExec(&calls, sqldriver.ErrBadConn, sqldriver.ErrBadConn, sqldriver.ErrBadConn)
+
+	if _, err := driver.ExecWithRetry(context.Background(), "DELETE FROM app.sessions"); err != nil {
+		t.Errorf("Expected recovery from bad connections, got: %v", err)
+	}
+}
+
+// TestExecWithRetryContextCancelled tests that cancellation stops retrying
+// TestExecWithRetryContextCancelled: キャンセルで再試行が止まることをテストする関数
+func TestExecWithRetryContextCancelled(t *testing.T) {
+	driver, fake := newRetryTestDriver(t)
+	WithRetryPolicy(RetryPolicy{MaxRetries: 5, InitialBackoff: time.Hour})(driver)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	fake.exec = func(string, []sqldriver.NamedValue) (sqldriver.Result, error) {
+		calls++
+		cancel()
+		return nil, dialError(syscall.ECONNREFUSED)
+	}
+
+	if _, err := driver.ExecWithRetry(ctx, "DELETE FROM app.sessions"); err == nil {
+		t.Error("Expected error after cancellation, got none")
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call before cancellation, got: %d", calls)
+	}
+}
+
+// TestQueryRowWithRetry tests that a transient query failure is retried
+// TestQueryRowWithRetry: 一時的なクエリ失敗が再試行されることをテストする関数
+func TestQueryRowWithRetry(t *testing.T) {
+	driver, fake := newRetryTestDriver(t)
+
+	calls := 0
+	fake.query = func(string, []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		calls++
+		if calls == 1 {
+			return nil, &pq.Error{Code: "57P01"}
+		}
+		return &fakeRows{columns: []string{"n"}, values: [][]sqldriver.Value{{int64(7)}}}, nil
+	}
+
+	var n int64
+	if err := driver.QueryRowWithRetry(context.Background(), "SELECT 7").Scan(&n); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if n != 7 || calls != 2 {
+		t.Errorf("Expected value 7 after 2 calls, got value %d after %d calls", n, calls)
+	}
+}
+
+// TestQueryRowWithRetryReleasesFailedAttempts tests that each failed attempt is released before the next one starts
+// TestQueryRowWithRetryReleasesFailedAttempts: 失敗した各試行が次の試行の開始前に解放されることをテストする関数
+func TestQueryRowWithRetryReleasesFailedAttempts(t *testing.T) {
+	driver, fake := newRetryTestDriver(t)
+	updateTestConfig(driver, func(config *DatabaseConfig) { config.DefaultQueryTimeout = time.Minute })
+
+	var attempts []context.Context
+	fake.observe = func(ctx context.Context) {
+		attempts = append(attempts, ctx)
+	}
+	fake.query = func(string, []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		if len(attempts) < 3 {
+			return nil, &pq.Error{Code: "57P01"}
+		}
+		return &fakeRows{columns: []string{"n"}, values: [][]sqldriver.Value{{int64(7)}}}, nil
+	}
+
+	row := driver.QueryRowWithRetry(context.Background(), "SELECT 7")
+	if len(attempts) != 3 {
+		t.Fatalf("Expected 3 attempts, got: %d", len(attempts))
+	}
+	for i, ctx := range attempts[:2] {
+		if err := ctx.Err(); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected failed attempt %d to be released, got: %v", i+1, err)
+		}
+	}
+	if err := attempts[2].Err(); err != nil {
+		t.Errorf("Expected the returned attempt to stay live until Scan, got: %v", err)
+	}
+
+	var n int64
+	if err := row.Scan(&n); err != nil || n != 7 {
+		t.Fatalf("Expected value 7 without error, got: %d, %v", n, err)
+	}
+	if err := attempts[2].Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected Scan to release the returned attempt, got: %v", err)
+	}
+}
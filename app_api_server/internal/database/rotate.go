@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context" // context: コンテキスト、処理の文脈情報
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+)
+
+// RotateCredentials switches the driver to a new user and password without downtime
+// RotateCredentials: ダウンタイムなしでドライバーを新しいユーザー名とパスワードに切り替える関数
+// downtime: 停止時間
+// A second pool is opened and pinged with the new credentials, swapped in for GetDB, and the old
+// pool is drained in the background; on failure the original pool and configuration are untouched
+// 新しい認証情報で2つ目のプールを開いてpingし、GetDB用に入れ替えて、古いプールはバックグラウンドで段階的に閉じる
+// 失敗時は元のプールと設定を変更しない
+func (d *PostgreSQLDriver) RotateCredentials(ctx context.Context, newUser, newPassword string) (err error) {
+	ctx, span := d.startSpan(ctx, "db.RotateCredentials", "")
+	defer func() { endSpan(span, err) }()
+	defer func() {
+		// The new password may appear in the error as well: 新しいパスワードもエラーに含まれ得る
+		rotated := DatabaseConfig{Password: newPassword}
+		d.recordEvent(EventCredentialRotation, "user "+newUser, rotated.redactError(err))
+	}()
+
+	if _, err := d.usablePool(); err != nil {
+		return err
+	}
+	active := d.config.Load()
+	if active.AuthMethod != AuthMethodPassword {
+		return fmt.Errorf("credential rotation requires password authentication, got: %s", active.AuthMethod) // requires: 必要とする
+	}
+	if newUser == "" || newPassword == "" {
+		return fmt.Errorf("user and password cannot be empty") // empty: 空の
+	}
+
+	rotated := cloneConfig(active)
+	rotated.User = newUser
+	rotated.Password = newPassword
+
+	// Validate the new credentials on a second pool before touching the current one
+	// 現在のプールに触れる前に、2つ目のプールで新しい認証情報を検証する
+	err = d.swapPool(ctx, &rotated, func() {
+		if d.pendingConfig != nil {
+			// Keep a staged configuration from reverting the rotation
+			// 準備済みの設定でローテーションが元に戻らないようにする
+			d.pendingConfig.User = newUser
+			d.pendingConfig.Password = newPassword
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to validate new credentials: %w", err) // validate: 検証する
+	}
+
+	d.logger.Info(fmt.Sprintf("Database credentials rotated for: %s", rotated.Database), "database", rotated.Database) // rotated: ローテーションされた
+	return nil
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"      // context: コンテキスト
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"strings"      // strings: 文字列操作
+	"testing"      // testing: テストフレームワーク
+	"time"         // time: 時間操作
+)
+
+// TestRotateCredentials tests that rotation swaps the pool while in-flight work on the old pool finishes
+// TestRotateCredentials: ローテーションでプールが入れ替わり、古いプールの実行中の処理が完了することをテストする関数
+func TestRotateCredentials(t *testing.T) {
+	driver := newFakeConnectingDriver(t)
+	driver.now = fakeClock(time.Second)
+	driver.poolDrainDelay = 0
+	defer driver.Close()
+
+	var connectionStrings []string
+	driver.openDB = func(connectionString string) (*sql.DB, error) {
+		connectionStrings = append(connectionStrings, connectionString)
+		_, db := newFakeDB()
+		return db, nil
+	}
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected connect to succeed, got: %v", err)
+	}
+	original := driver.GetDB()
+
+	tx, err := original.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Expected transaction to begin, got: %v", err)
+	}
+
+	if err := driver.RotateCredentials(context.Background(), "app_v2", "new-secret"); err != nil {
+		t.Fatalf("Expected rotation to succeed, got: %v", err)
+	}
+
+	if driver.GetDB() == original {
+		t.Error("Expected GetDB to return the new pool")
+	}
+	if len(connectionStrings) != 2 || !strings.Contains(connectionStrings[1], "user=app_v2 password=new-secret ") {
+		t.Errorf("Expected the new pool to use the new credentials, got: %v", connectionStrings)
+	}
+	if config := driver.GetConfig(); config.User != "app_v2" || config.Password != "new-secret" {
+		t.Errorf("Expected config to hold the new credentials, got: %v", config)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Errorf("Expected in-flight transaction on the old pool to commit, got: %v", err)
+	}
+	waitFor(t, func() bool { return original.PingContext(context.Background()) != nil }) // old pool is closed: 古いプールは閉じられる
+
+	stats := driver.GetConnectionStats()
+	if stats.LastRotationTime.IsZero() || !stats.LastRotationTime.After(stats.LastConnectTime) {
+		t.Errorf("Expected rotation time after connect time, got: %s (connect %s)", stats.LastRotationTime, stats.LastConnectTime)
+	}
+
+	// Reconnect keeps using the rotated credentials
+	// 再接続でもローテーション後の認証情報を使い続ける
+	if err := driver.Reconnect(); err != nil {
+		t.Fatalf("Expected reconnect to succeed, got: %v", err)
+	}
+	if !strings.Contains(connectionStrings[2], "user=app_v2 password=new-secret ") {
+		t.Errorf("Expected reconnect to use the rotated credentials, got: %s", connectionStrings[2])
+	}
+}
+
+// TestRotateCredentialsFailureKeepsPool tests that a failed rotation leaves the original pool untouched
+// TestRotateCredentialsFailureKeepsPool: ローテーション失敗時に元のプールが変更されないことをテストする関数
+func TestRotateCredentialsFailureKeepsPool(t *testing.T) {
+	driver := newFakeConnectingDriver(t)
+	defer driver.Close()
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected connect to succeed, got: %v", err)
+	}
+	original := driver.GetDB()
+
+	errAuth := errors.New("password authentication failed for user app_v2")
+	driver.openDB = func(connectionString string) (*sql.DB, error) {
+		fake, db := newFakeDB()
+		fake.ping = func(context.Context) error { return errAuth }
+		return db, nil
+	}
+
+	err := driver.RotateCredentials(context.Background(), "app_v2", "wrong-secret")
+	if !errors.Is(err, errAuth) {
+		t.Errorf("Expected authentication error, got: %v", err)
+	}
+	if driver.GetDB() != original {
+		t.Error("Expected the original pool to be kept")
+	}
+	if err := original.PingContext(context.Background()); err != nil {
+		t.Errorf("Expected the original pool to stay open, got: %v", err)
+	}
+	if config := driver.GetConfig(); config.User != "testuser" || config.Password != "testpass" {
+		t.Errorf("Expected config to be unchanged, got: %v", config)
+	}
+	if !driver.GetConnectionStats().LastRotationTime.IsZero() {
+		t.Error("Expected no rotation time after a failed rotation")
+	}
+}
+
+// TestRotateCredentialsErrors tests rotation preconditions
+// TestRotateCredentialsErrors: ローテーションの前提条件をテストする関数
+func TestRotateCredentialsErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		connect  bool
+		method   string
+		user     string
+		password string
+		errorMsg string
+	}{
+		{"not connected", false, AuthMethodPassword, "u", "p", "not established"},
+		{"iam authentication", true, AuthMethodAWSIAM, "u", "p", "requires password authentication"},
+		{"empty user", true, AuthMethodPassword, "", "p", "cannot be empty"},
+		{"empty password", true, AuthMethodPassword, "u", "", "cannot be empty"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver := newFakeConnectingDriver(t)
+			defer driver.Close()
+			if tc.connect {
+				if err := driver.Connect(); err != nil {
+					t.Fatalf("Expected connect to succeed, got: %v", err)
+				}
+			}
+			updateTestConfig(driver, func(config *DatabaseConfig) { config.AuthMethod = tc.method })
+
+			err := driver.RotateCredentials(context.Background(), tc.user, tc.password)
+			if err == nil || !strings.Contains(err.Error(), tc.errorMsg) {
+				t.Errorf("Expected error containing %q, got: %v", tc.errorMsg, err)
+			}
+		})
+	}
+}
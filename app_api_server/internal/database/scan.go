@@ -0,0 +1,126 @@
+package database
+
+import (
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"reflect"      // reflect: リフレクション、構造体のフィールド解析
+	"sync"         // sync: 同期処理、型ごとのキャッシュ
+)
+
+// ErrNotFound is returned by ScanOne when the query produced no row; it also matches sql.ErrNoRows
+// ErrNotFound: クエリが行を返さなかった場合にScanOneが返すエラー、sql.ErrNoRowsとも一致する
+var ErrNotFound = errors.New("no rows found")
+
+// RowScanner is implemented by *sql.Row, *sql.Rows, *Row and *Rows
+// RowScanner: *sql.Row、*sql.Rows、*Row、*Rowsが実装するインターフェース
+type RowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// structFieldCache maps a struct type to the field index of each db-tagged column
+// structFieldCache: 構造体の型から、dbタグ付きカラムごとのフィールド番号への対応を保持するキャッシュ
+var structFieldCache sync.Map // map[reflect.Type]map[string][]int
+
+// ScanOne scans a single row into cols, or into dest itself when no cols are given
+// ScanOne: 1行をcolsへ、colsが指定されていない場合はdest自体へ読み取る関数
+// cols are usually pointers to fields of dest, e.g. ScanOne(row, &user, &user.ID, &user.Email)
+// colsは通常destのフィールドへのポインター（例: ScanOne(row, &user, &user.ID, &user.Email)）
+// sql.ErrNoRows becomes ErrNotFound; on any error *dest is reset so no partial row leaks out
+// sql.ErrNoRowsはErrNotFoundになる、エラー時は途中まで読んだ値が漏れないよう*destをゼロ値に戻す
+func ScanOne[T any](row RowScanner, dest *T, cols ...interface{}) error {
+	if len(cols) == 0 {
+		cols = []interface{}{dest}
+	}
+
+	err := row.Scan(cols...)
+	if err == nil {
+		return nil
+	}
+
+	var zero T
+	*dest = zero
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return err
+}
+
+// CollectRows calls scan for every row, closes rows and returns the collected values
+// CollectRows: 行ごとにscanを呼び出し、rowsを閉じて集めた値を返す関数
+// Zero rows give an empty, non-nil slice rather than ErrNotFound, so an empty list encodes as []
+// 0行の場合はErrNotFoundではなく空の（nilでない）スライスを返すため、空のリストは[]としてエンコードされる
+func CollectRows[T any](rows *sql.Rows, scan func(*sql.Rows) (T, error)) ([]T, error) {
+	defer rows.Close()
+
+	values := []T{}
+	for rows.Next() {
+		value, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// StructScan scans the current row into the flat struct dest points to, matching columns to `db:"col"` tags
+// StructScan: 現在の行を、destが指す平坦な構造体へ`db:"col"`タグでカラムを対応させて読み取る関数
+// flat: 平坦な（入れ子のない）
+// A column without a matching field is an error, fields without a column are left untouched,
+// and a NULL into a non-pointer field is an error (use a pointer or sql.Null* for nullable columns)
+// 対応するフィールドがないカラムはエラー、カラムのないフィールドは変更しない、
+// ポインターでないフィールドへのNULLはエラー（NULL許容のカラムにはポインターかsql.Null*を使う）
+func StructScan(rows *sql.Rows, dest interface{}) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("struct scan destination must be a non-nil pointer to a struct, got %T", dest)
+	}
+	value = value.Elem()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	fields := structFields(value.Type())
+	targets := make([]interface{}, len(columns))
+	for i, column := range columns {
+		index, ok := fields[column]
+		if !ok {
+			return fmt.Errorf("column %q has no matching db tag in %s", column, value.Type())
+		}
+		targets[i] = value.FieldByIndex(index).Addr().Interface()
+	}
+
+	if err := rows.Scan(targets...); err != nil {
+		return fmt.Errorf("struct scan into %s: %w", value.Type(), err)
+	}
+	return nil
+}
+
+// structFields returns the column-to-field mapping of t, building it once per type
+// structFields: tのカラムとフィールドの対応を返す関数、型ごとに一度だけ構築する
+// Only exported fields with a db tag are mapped; `db:"-"` skips a field
+// dbタグを持つ公開フィールドのみを対応させる、`db:"-"`はフィールドを除外する
+func structFields(t reflect.Type) map[string][]int {
+	if cached, ok := structFieldCache.Load(t); ok {
+		return cached.(map[string][]int)
+	}
+
+	fields := make(map[string][]int)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		column := field.Tag.Get("db")
+		if !field.IsExported() || column == "" || column == "-" {
+			continue
+		}
+		fields[column] = field.Index
+	}
+
+	cached, _ := structFieldCache.LoadOrStore(t, fields)
+	return cached.(map[string][]int)
+}
@@ -0,0 +1,287 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	"database/sql"                  // sql: データベース操作用パッケージ
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"strings"                       // strings: 文字列操作
+	"testing"                       // testing: テスト機能
+)
+
+// scanUser is a flat struct mapped with db tags
+// scanUser: dbタグで対応付けた平坦な構造体
+type scanUser struct {
+	ID       int64   `db:"id"`
+	Email    string  `db:"email"`
+	Nickname *string `db:"nickname"` // nullable: NULL許容
+	Internal string  `db:"-"`        // skipped: 対応付けない
+}
+
+// scanRows returns a fake query that answers every statement with columns and values
+// scanRows: すべての文にcolumnsとvaluesで応答するフェイクのクエリを返す関数
+func scanRows(columns []string, values [][]sqldriver.Value) func(string, []sqldriver.NamedValue) (sqldriver.Rows, error) {
+	return func(string, []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		return &fakeRows{columns: columns, values: values}, nil
+	}
+}
+
+// TestScanOne tests scanning into dest or explicit columns and the mapping of sql.ErrNoRows
+// TestScanOne: destまたは明示したカラムへの読み取りと、sql.ErrNoRowsの変換をテストする関数
+func TestScanOne(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Scalar", func(t *testing.T) {
+		driver, fake := newTestDriver(t)
+		fake.query = scanRows([]string{"count"}, [][]sqldriver.Value{{int64(42)}})
+		var count int64
+		if err := ScanOne(driver.QueryRowContext(ctx, "SELECT count(*) FROM users"), &count); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if count != 42 {
+			t.Errorf("Expected 42, got: %d", count)
+		}
+	})
+
+	t.Run("Columns", func(t *testing.T) {
+		driver, fake := newTestDriver(t)
+		fake.query = scanRows([]string{"id", "email"}, [][]sqldriver.Value{{int64(7), "a@example.com"}})
+		var user scanUser
+		if err := ScanOne(driver.QueryRowContext(ctx, "SELECT id, email FROM users"), &user, &user.ID, &user.Email); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if user.ID != 7 || user.Email != "a@example.com" {
+			t.Errorf("Expected user 7 a@example.com, got: %+v", user)
+		}
+	})
+
+	t.Run("No rows", func(t *testing.T) {
+		driver, fake := newTestDriver(t)
+		fake.query = scanRows([]string{"id"}, nil)
+		user := scanUser{ID: 99}
+		err := ScanOne(driver.QueryRowContext(ctx, "SELECT id FROM users"), &user, &user.ID)
+		if !errors.Is(err, ErrNotFound) || !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected ErrNotFound wrapping sql.ErrNoRows, got: %v", err)
+		}
+		if user.ID != 0 {
+			t.Errorf("Expected dest to be reset, got: %+v", user)
+		}
+	})
+
+	t.Run("NULL into a non-pointer", func(t *testing.T) {
+		driver, fake := newTestDriver(t)
+		fake.query = scanRows([]string{"id", "email"}, [][]sqldriver.Value{{int64(7), nil}})
+		var user scanUser
+		err := ScanOne(driver.QueryRowContext(ctx, "SELECT id, email FROM users"), &user, &user.ID, &user.Email)
+		if err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected a conversion error, got: %v", err)
+		}
+		if user.ID != 0 {
+			t.Errorf("Expected no partially scanned row, got: %+v", user)
+		}
+	})
+}
+
+// TestCollectRows tests collecting values, the empty result and scan errors
+// TestCollectRows: 値の収集、空の結果、読み取りエラーをテストする関数
+func TestCollectRows(t *testing.T) {
+	scanID := func(rows *sql.Rows) (int64, error) {
+		var id int64
+		err := rows.Scan(&id)
+		return id, err
+	}
+
+	testCases := []struct {
+		name         string
+		values       [][]sqldriver.Value
+		expected     []int64
+		expectError  bool
+		errorContent string
+	}{
+		{name: "Rows", values: [][]sqldriver.Value{{int64(1)}, {int64(2)}, {int64(3)}}, expected: []int64{1, 2, 3}},
+		{name: "Zero rows", values: nil, expected: []int64{}},
+		{name: "NULL into int64", values: [][]sqldriver.Value{{int64(1)}, {nil}}, expectError: true, errorContent: "NULL"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, fake := newTestDriver(t)
+			fake.query = scanRows([]string{"id"}, tc.values)
+			rows, err := driver.QueryContext(context.Background(), "SELECT id FROM users")
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			ids, err := CollectRows(rows.Rows, scanID)
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if ids == nil || len(ids) != len(tc.expected) {
+				t.Fatalf("Expected %v, got: %#v", tc.expected, ids)
+			}
+			for i := range ids {
+				if ids[i] != tc.expected[i] {
+					t.Errorf("Expected %v, got: %v", tc.expected, ids)
+				}
+			}
+		})
+	}
+}
+
+// TestStructScan tests tag mapping, NULL handling and unmatched columns
+// TestStructScan: タグの対応、NULLの扱い、対応のないカラムをテストする関数
+func TestStructScan(t *testing.T) {
+	testCases := []struct {
+		name         string
+		columns      []string
+		values       []sqldriver.Value
+		expected     scanUser
+		expectError  bool
+		errorContent string
+	}{
+		{
+			name: "All columns", columns: []string{"email", "id", "nickname"},
+			values:   []sqldriver.Value{"a@example.com", int64(7), "ann"},
+			expected: scanUser{ID: 7, Email: "a@example.com", Nickname: stringPointer("ann")},
+		},
+		{
+			name: "NULL into a pointer", columns: []string{"id", "nickname"},
+			values:   []sqldriver.Value{int64(7), nil},
+			expected: scanUser{ID: 7, Email: "kept"},
+		},
+		{
+			name: "NULL into a non-pointer", columns: []string{"id", "email"},
+			values:      []sqldriver.Value{int64(7), nil},
+			expectError: true, errorContent: `"email"`,
+		},
+		{
+			name: "Extra column", columns: []string{"id", "created_at"},
+			values:      []sqldriver.Value{int64(7), "2024-01-01"},
+			expectError: true, errorContent: `column "created_at" has no matching db tag`,
+		},
+		{
+			name: "Skipped field is not a column", columns: []string{"Internal"},
+			values:      []sqldriver.Value{"x"},
+			expectError: true, errorContent: "no matching db tag",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, fake := newTestDriver(t)
+			fake.query = scanRows(tc.columns, [][]sqldriver.Value{tc.values})
+			rows, err := driver.QueryContext(context.Background(), "SELECT * FROM users")
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			defer rows.Close()
+			rows.Next()
+
+			user := scanUser{Email: "kept"}
+			err = StructScan(rows.Rows, &user)
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if user.ID != tc.expected.ID || user.Email != tc.expected.Email || !equalStringPointers(user.Nickname, tc.expected.Nickname) {
+				t.Errorf("Expected %+v, got: %+v", tc.expected, user)
+			}
+		})
+	}
+}
+
+// TestStructScanInvalidDestination tests that only pointers to structs are accepted
+// TestStructScanInvalidDestination: 構造体へのポインターのみを受け付けることをテストする関数
+func TestStructScanInvalidDestination(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	fake.query = scanRows([]string{"id"}, [][]sqldriver.Value{{int64(1)}})
+	rows, err := driver.QueryContext(context.Background(), "SELECT id FROM users")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer rows.Close()
+	rows.Next()
+
+	var id int64
+	for _, dest := range []interface{}{scanUser{}, &id, (*scanUser)(nil)} {
+		if err := StructScan(rows.Rows, dest); err == nil || !strings.Contains(err.Error(), "pointer to a struct") {
+			t.Errorf("Expected destination error for %T, got: %v", dest, err)
+		}
+	}
+}
+
+// stringPointer returns a pointer to s
+// stringPointer: sへのポインターを返す関数
+func stringPointer(s string) *string { return &s }
+
+// equalStringPointers compares two optional strings by value
+// equalStringPointers: 2つの省略可能な文字列を値で比較する関数
+func equalStringPointers(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// benchmarkScanRows returns 100 rows matching scanUser
+// benchmarkScanRows: scanUserに対応する100行を返す関数
+func benchmarkScanRows() [][]sqldriver.Value {
+	values := make([][]sqldriver.Value, 100)
+	for i := range values {
+		values[i] = []sqldriver.Value{int64(i), "user@example.com", "nick"}
+	}
+	return values
+}
+
+// BenchmarkStructScan measures CollectRows with StructScan, which reuses the cached field mapping
+// BenchmarkStructScan: キャッシュしたフィールドの対応を再利用するStructScanとCollectRowsを計測するベンチマーク
+func BenchmarkStructScan(b *testing.B) {
+	driver, fake := newTestDriver(b)
+	fake.query = scanRows([]string{"id", "email", "nickname"}, benchmarkScanRows())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rows, err := driver.QueryContext(context.Background(), "SELECT id, email, nickname FROM users")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := CollectRows(rows.Rows, func(rows *sql.Rows) (scanUser, error) {
+			var user scanUser
+			err := StructScan(rows, &user)
+			return user, err
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkManualScan measures the hand-written rows.Scan loop StructScan replaces
+// BenchmarkManualScan: StructScanが置き換える手書きのrows.Scanループを計測するベンチマーク
+func BenchmarkManualScan(b *testing.B) {
+	driver, fake := newTestDriver(b)
+	fake.query = scanRows([]string{"id", "email", "nickname"}, benchmarkScanRows())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rows, err := driver.QueryContext(context.Background(), "SELECT id, email, nickname FROM users")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := CollectRows(rows.Rows, func(rows *sql.Rows) (scanUser, error) {
+			var user scanUser
+			err := rows.Scan(&user.ID, &user.Email, &user.Nickname)
+			return user, err
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context" // context: コンテキスト、処理の文脈情報
+	"errors"  // errors: エラー操作
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"strings" // strings: 文字列操作
+	"time"    // time: 時間操作機能
+
+	"github.com/lib/pq" // pq: PostgreSQLドライバー、配列型
+)
+
+// schemaCheckTimeout bounds each table existence query
+// schemaCheckTimeout: テーブル存在確認クエリ1回あたりの制限時間
+const schemaCheckTimeout = 10 * time.Second
+
+// ErrMissingTables is returned by EnsureSchema when expected tables do not exist
+// ErrMissingTables: 期待するテーブルが存在しない場合にEnsureSchemaが返すエラー
+var ErrMissingTables = errors.New("expected tables are missing")
+
+// missingTablesQuery returns the names in $2 that have no relation in schema $1, in the order given
+// missingTablesQuery: スキーマ$1に存在しない$2のテーブル名を、指定された順序で返すクエリ
+// pg_catalog is queried directly so the result does not depend on search_path or privileges
+// search_pathや権限に左右されないよう、pg_catalogを直接問い合わせる
+// relkind: r table, p partitioned table, v view, m materialized view, f foreign table
+// relkind: r テーブル、p パーティションテーブル、v ビュー、m マテリアライズドビュー、f 外部テーブル
+const missingTablesQuery = `
+	SELECT COALESCE(array_agg(t.name ORDER BY t.ord), '{}')
+	FROM unnest($2::text[]) WITH ORDINALITY AS t(name, ord)
+	WHERE NOT EXISTS (
+		SELECT 1 FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relname = t.name AND c.relkind IN ('r', 'p', 'v', 'm', 'f')
+	)`
+
+// missingTables returns the tables that do not exist in schema
+// missingTables: スキーマに存在しないテーブルを返す内部関数
+func (d *PostgreSQLDriver) missingTables(ctx context.Context, schema string, tables []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, schemaCheckTimeout)
+	defer cancel()
+
+	var missing pq.StringArray
+	if err := d.QueryRowContext(ctx, missingTablesQuery, schema, pq.Array(tables)).Scan(&missing); err != nil {
+		return nil, fmt.Errorf("failed to check tables in schema %s: %w", schema, err)
+	}
+	return missing, nil
+}
+
+// EnsureSchema checks in one query that every table exists in schema, listing all missing ones in the error
+// EnsureSchema: スキーマに全てのテーブルが存在することを1回のクエリで確認する関数、不足するテーブルを全てエラーに列挙する
+// Names are matched as stored, so unquoted identifiers are lower case: 名前は格納された形で照合されるため、引用符なしの識別子は小文字
+func (d *PostgreSQLDriver) EnsureSchema(ctx context.Context, schema string, tables ...string) error {
+	if len(tables) == 0 {
+		return nil
+	}
+
+	missing, err := d.missingTables(ctx, schema, tables)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w in schema %s: %s", ErrMissingTables, schema, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// HasTable reports whether a single table exists in schema
+// HasTable: スキーマに単一のテーブルが存在するかどうかを判定する関数
+func (d *PostgreSQLDriver) HasTable(ctx context.Context, schema, table string) (bool, error) {
+	missing, err := d.missingTables(ctx, schema, []string{table})
+	if err != nil {
+		return false, err
+	}
+	return len(missing) == 0, nil
+}
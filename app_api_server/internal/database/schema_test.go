@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"strings"                       // strings: 文字列操作
+	"testing"                       // testing: テスト機能
+	"time"                          // time: 時間操作機能
+)
+
+// TestEnsureSchema tests that all missing tables are reported from a single query
+// TestEnsureSchema: 不足する全てのテーブルが1回のクエリで報告されることをテストする関数
+func TestEnsureSchema(t *testing.T) {
+	testCases := []struct {
+		name        string
+		missing     string // missing: フェイクサーバーが返す配列リテラル
+		expectError bool
+	}{
+		{name: "All tables exist", missing: "{}"},
+		{name: "One table missing", missing: "{sessions}", expectError: true},
+		{name: "Several tables missing", missing: "{sessions,application_logs}", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, fake := newTestDriver(t)
+			var gotArgs []sqldriver.NamedValue
+			fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+				gotArgs = args
+				return &fakeRows{columns: []string{"missing"}, values: [][]sqldriver.Value{{tc.missing}}}, nil
+			}
+
+			err := driver.EnsureSchema(context.Background(), "app", "users", "sessions", "application_logs")
+			if len(fake.executed()) != 1 {
+				t.Errorf("Expected a single query, got: %v", fake.executed())
+			}
+			if len(gotArgs) != 2 || gotArgs[0].Value != "app" || gotArgs[1].Value != "{\"users\",\"sessions\",\"application_logs\"}" {
+				t.Errorf("Expected schema and table array arguments, got: %v", gotArgs)
+			}
+
+			if !tc.expectError {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrMissingTables) {
+				t.Fatalf("Expected ErrMissingTables, got: %v", err)
+			}
+			for _, table := range strings.Split(strings.Trim(tc.missing, "{}"), ",") {
+				if !strings.Contains(err.Error(), table) {
+					t.Errorf("Expected error to list %s, got: %v", table, err)
+				}
+			}
+			if strings.Contains(err.Error(), "users") {
+				t.Errorf("Expected existing table not to be listed, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestEnsureSchemaNoTables tests that an empty table list skips the query
+// TestEnsureSchemaNoTables: テーブルの指定がない場合にクエリを省略することをテストする関数
+func TestEnsureSchemaNoTables(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	if err := driver.EnsureSchema(context.Background(), "app"); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if len(fake.executed()) != 0 {
+		t.Errorf("Expected no query, got: %v", fake.executed())
+	}
+}
+
+// TestHasTable tests the single table check and its bounded timeout
+// TestHasTable: 単一テーブルの確認と制限時間をテストする関数
+func TestHasTable(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	missing := "{}"
+	fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		return &fakeRows{columns: []string{"missing"}, values: [][]sqldriver.Value{{missing}}}, nil
+	}
+
+	exists, err := driver.HasTable(context.Background(), "app", "users")
+	if err != nil || !exists {
+		t.Errorf("Expected users to exist, got: %v, %v", exists, err)
+	}
+
+	missing = "{users}"
+	exists, err = driver.HasTable(context.Background(), "app", "users")
+	if err != nil || exists {
+		t.Errorf("Expected users to be missing, got: %v, %v", exists, err)
+	}
+
+	// A slow server fails instead of hanging: 遅いサーバーは待ち続けずに失敗する
+	fake.query = nil
+	fake.ping = nil
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	time.Sleep(time.Millisecond)
+	if _, err := driver.HasTable(ctx, "app", "users"); err == nil {
+		t.Error("Expected error after the deadline")
+	}
+}
@@ -0,0 +1,260 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"io"           // io: 入出力、書き出し先
+	"strings"      // strings: 文字列操作
+)
+
+// Catalog queries used by ExportSchema; every one is ordered so the dump is deterministic
+// ExportSchemaが使うカタログクエリ、ダンプが決定的になるよう全て並び順を指定する
+// quote_ident quotes names only when PostgreSQL requires it: quote_identは必要な場合のみ名前を引用符で囲む
+const (
+	// exportSequencesQuery lists the sequences of schema $1, leaving out those behind identity columns
+	// exportSequencesQuery: スキーマ$1のシーケンスを列挙する、IDENTITYカラムのシーケンスは除く
+	exportSequencesQuery = `
+	SELECT quote_ident(n.nspname) || '.' || quote_ident(c.relname),
+		pg_catalog.format_type(s.seqtypid, NULL),
+		s.seqstart, s.seqincrement, s.seqmin, s.seqmax, s.seqcache, s.seqcycle
+	FROM pg_catalog.pg_sequence s
+	JOIN pg_catalog.pg_class c ON c.oid = s.seqrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = $1 AND NOT EXISTS (
+		SELECT 1 FROM pg_catalog.pg_depend dep
+		WHERE dep.classid = 'pg_catalog.pg_class'::regclass AND dep.objid = c.oid AND dep.deptype = 'i'
+	)
+	ORDER BY c.relname`
+
+	// exportTablesQuery lists the tables of schema $1 by name
+	// exportTablesQuery: スキーマ$1のテーブルを名前順に列挙する
+	exportTablesQuery = `
+	SELECT c.relname, quote_ident(n.nspname) || '.' || quote_ident(c.relname)
+	FROM pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = $1 AND c.relkind IN ('r', 'p')
+	ORDER BY c.relname`
+
+	// exportColumnsQuery lists the columns of every table of schema $1, in table order
+	// exportColumnsQuery: スキーマ$1の全テーブルのカラムをテーブル内の順序で列挙する
+	// attidentity: a ALWAYS, d BY DEFAULT; attgenerated: s STORED
+	exportColumnsQuery = `
+	SELECT c.relname, quote_ident(a.attname),
+		pg_catalog.format_type(a.atttypid, a.atttypmod),
+		a.attnotnull,
+		pg_catalog.pg_get_expr(ad.adbin, ad.adrelid),
+		a.attidentity::text,
+		a.attgenerated::text
+	FROM pg_catalog.pg_attribute a
+	JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	LEFT JOIN pg_catalog.pg_attrdef ad ON ad.adrelid = a.attrelid AND ad.adnum = a.attnum
+	WHERE n.nspname = $1 AND c.relkind IN ('r', 'p') AND a.attnum > 0 AND NOT a.attisdropped
+	ORDER BY c.relname, a.attnum`
+
+	// exportViewsQuery lists the views and materialized views of schema $1 by name
+	// exportViewsQuery: スキーマ$1のビューとマテリアライズドビューを名前順に列挙する
+	exportViewsQuery = `
+	SELECT quote_ident(n.nspname) || '.' || quote_ident(c.relname),
+		c.relkind = 'm',
+		pg_catalog.pg_get_viewdef(c.oid, true)
+	FROM pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = $1 AND c.relkind IN ('v', 'm')
+	ORDER BY c.relname`
+
+	// exportConstraintsQuery lists the table constraints of schema $1, foreign keys last so they follow the keys they reference
+	// exportConstraintsQuery: スキーマ$1のテーブル制約を列挙する、外部キーは参照先のキーの後になるよう最後に並べる
+	// contype: p primary key, u unique, c check, x exclusion, f foreign key
+	exportConstraintsQuery = `
+	SELECT quote_ident(n.nspname) || '.' || quote_ident(c.relname),
+		quote_ident(con.conname),
+		pg_catalog.pg_get_constraintdef(con.oid, true)
+	FROM pg_catalog.pg_constraint con
+	JOIN pg_catalog.pg_class c ON c.oid = con.conrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = $1 AND con.contype IN ('p', 'u', 'c', 'x', 'f') AND con.conislocal
+	ORDER BY con.contype = 'f', c.relname, con.conname`
+
+	// exportIndexesQuery lists the indexes of schema $1 that do not back a constraint
+	// exportIndexesQuery: スキーマ$1のインデックスのうち、制約に使われていないものを列挙する
+	exportIndexesQuery = `
+	SELECT pg_catalog.pg_get_indexdef(ix.indexrelid)
+	FROM pg_catalog.pg_index ix
+	JOIN pg_catalog.pg_class i ON i.oid = ix.indexrelid
+	JOIN pg_catalog.pg_class t ON t.oid = ix.indrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+	WHERE n.nspname = $1 AND NOT EXISTS (
+		SELECT 1 FROM pg_catalog.pg_constraint con
+		WHERE con.conindid = ix.indexrelid AND con.contype IN ('p', 'u', 'x')
+	)
+	ORDER BY t.relname, i.relname`
+)
+
+// exportColumn is a column as read for ExportSchema
+// exportColumn: ExportSchemaで読み取ったカラム
+type exportColumn struct {
+	name      string         // name: 引用符処理済みのカラム名
+	dataType  string         // data type: 修飾子付きのデータ型（character varying(255)など）
+	notNull   bool           // not null: NOT NULL制約
+	expr      sql.NullString // expr: デフォルト式、または生成カラムの式
+	identity  string         // identity: IDENTITYの種類（a、d、空）
+	generated string         // generated: 生成カラムの種類（s、空）
+}
+
+// definition renders the column as it appears in CREATE TABLE
+// definition: CREATE TABLE内でのカラム定義を組み立てる関数
+func (c exportColumn) definition() string {
+	def := c.name + " " + c.dataType
+	switch {
+	case c.generated == "s":
+		def += " GENERATED ALWAYS AS (" + c.expr.String + ") STORED"
+	case c.identity == "a":
+		def += " GENERATED ALWAYS AS IDENTITY"
+	case c.identity == "d":
+		def += " GENERATED BY DEFAULT AS IDENTITY"
+	case c.expr.Valid:
+		def += " DEFAULT " + c.expr.String
+	}
+	if c.notNull {
+		def += " NOT NULL"
+	}
+	return def
+}
+
+// ExportSchema writes the DDL of schema to w, rebuilt from pg_catalog without pg_dump
+// ExportSchema: pg_dumpを使わずにpg_catalogからスキーマのDDLを組み立ててwに書き出す関数
+// DDL: データ定義言語（CREATE文など）
+// Sequences, tables, views and materialized views are followed by constraints (foreign keys last) and the
+// remaining indexes, each group ordered by name so dumps of two environments can be diffed. Functions, triggers,
+// partitioning, ownership and privileges are not exported. Nothing is written when a catalog query fails
+// シーケンス、テーブル、ビュー、マテリアライズドビューの後に制約（外部キーは最後）と残りのインデックスを続け、
+// 環境間でダンプを比較できるよう各グループは名前順に並べる。関数、トリガー、パーティション、所有者、権限は出力しない。
+// カタログクエリが失敗した場合は何も書き出さない
+func (d *PostgreSQLDriver) ExportSchema(ctx context.Context, schema string, w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "-- Schema %s exported from pg_catalog\n", schema)
+
+	args := []interface{}{schema}
+	err := d.introspect(ctx, exportSequencesQuery, args, func(rows *sql.Rows) error {
+		var name, dataType string
+		var start, increment, minValue, maxValue, cache int64
+		var cycle bool
+		if err := rows.Scan(&name, &dataType, &start, &increment, &minValue, &maxValue, &cache, &cycle); err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "\nCREATE SEQUENCE %s AS %s START WITH %d INCREMENT BY %d MINVALUE %d MAXVALUE %d CACHE %d",
+			name, dataType, start, increment, minValue, maxValue, cache)
+		if cycle {
+			b.WriteString(" CYCLE")
+		}
+		b.WriteString(";\n")
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export sequences of schema %s: %w", schema, err)
+	}
+
+	if err := d.exportTables(ctx, schema, &b); err != nil {
+		return fmt.Errorf("failed to export tables of schema %s: %w", schema, err)
+	}
+
+	err = d.introspect(ctx, exportViewsQuery, args, func(rows *sql.Rows) error {
+		var name, definition string
+		var materialized bool
+		if err := rows.Scan(&name, &materialized, &definition); err != nil {
+			return err
+		}
+		definition = strings.TrimRight(strings.TrimSpace(definition), ";")
+		if materialized {
+			fmt.Fprintf(&b, "\nCREATE MATERIALIZED VIEW %s AS\n%s\nWITH NO DATA;\n", name, definition)
+		} else {
+			fmt.Fprintf(&b, "\nCREATE VIEW %s AS\n%s;\n", name, definition)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export views of schema %s: %w", schema, err)
+	}
+
+	err = d.introspect(ctx, exportConstraintsQuery, args, func(rows *sql.Rows) error {
+		var table, name, definition string
+		if err := rows.Scan(&table, &name, &definition); err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "\nALTER TABLE ONLY %s ADD CONSTRAINT %s %s;\n", table, name, definition)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export constraints of schema %s: %w", schema, err)
+	}
+
+	err = d.introspect(ctx, exportIndexesQuery, args, func(rows *sql.Rows) error {
+		var definition string
+		if err := rows.Scan(&definition); err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "\n%s;\n", definition)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export indexes of schema %s: %w", schema, err)
+	}
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// exportTables writes a CREATE TABLE statement for every table of schema
+// exportTables: スキーマの全テーブルのCREATE TABLE文を書き出す関数
+func (d *PostgreSQLDriver) exportTables(ctx context.Context, schema string, b *strings.Builder) error {
+	type exportTable struct {
+		name    string         // name: スキーマ修飾・引用符処理済みのテーブル名
+		columns []exportColumn // columns: テーブル内の順序のカラム
+	}
+	var tables []*exportTable
+	byName := map[string]*exportTable{} // by name: relnameからテーブルへの対応
+
+	args := []interface{}{schema}
+	err := d.introspect(ctx, exportTablesQuery, args, func(rows *sql.Rows) error {
+		var relname string
+		table := &exportTable{}
+		if err := rows.Scan(&relname, &table.name); err != nil {
+			return err
+		}
+		tables = append(tables, table)
+		byName[relname] = table
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = d.introspect(ctx, exportColumnsQuery, args, func(rows *sql.Rows) error {
+		var relname string
+		var column exportColumn
+		if err := rows.Scan(&relname, &column.name, &column.dataType, &column.notNull, &column.expr, &column.identity, &column.generated); err != nil {
+			return err
+		}
+		if table := byName[relname]; table != nil {
+			table.columns = append(table.columns, column)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		fmt.Fprintf(b, "\nCREATE TABLE %s (", table.name)
+		for i, column := range table.columns {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n    " + column.definition())
+		}
+		b.WriteString("\n);\n")
+	}
+	return nil
+}
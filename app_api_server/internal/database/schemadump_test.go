@@ -0,0 +1,119 @@
+package database
+
+import (
+	"bytes"                         // bytes: バイト列操作
+	"context"                       // context: コンテキスト
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"strings"                       // strings: 文字列操作
+	"testing"                       // testing: テスト機能
+)
+
+// exportCatalog answers the ExportSchema catalog queries with fixed rows
+// exportCatalog: ExportSchemaのカタログクエリに固定の行を返す関数
+func exportCatalog(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+	switch query {
+	case exportSequencesQuery:
+		return &fakeRows{
+			columns: []string{"name", "type", "start", "increment", "min", "max", "cache", "cycle"},
+			values:  [][]sqldriver.Value{{"app.invoice_number", "bigint", int64(1000), int64(1), int64(1), int64(9223372036854775807), int64(1), false}},
+		}, nil
+	case exportTablesQuery:
+		return &fakeRows{
+			columns: []string{"relname", "name"},
+			values:  [][]sqldriver.Value{{"sessions", "app.sessions"}, {"users", "app.users"}},
+		}, nil
+	case exportColumnsQuery:
+		return &fakeRows{
+			columns: []string{"relname", "name", "type", "notnull", "expr", "identity", "generated"},
+			values: [][]sqldriver.Value{
+				{"sessions", "id", "bigint", true, nil, "a", ""},
+				{"sessions", "user_id", "uuid", true, nil, "", ""},
+				{"users", "id", "uuid", true, "uuid_generate_v4()", "", ""},
+				{"users", "email", "character varying(255)", true, nil, "", ""},
+				{"users", "\"Display Name\"", "text", false, "(email)::text", "", "s"},
+			},
+		}, nil
+	case exportViewsQuery:
+		return &fakeRows{
+			columns: []string{"name", "materialized", "definition"},
+			values:  [][]sqldriver.Value{{"app.active_users", false, " SELECT id\n   FROM app.users;"}},
+		}, nil
+	case exportConstraintsQuery:
+		return &fakeRows{
+			columns: []string{"table", "name", "definition"},
+			values: [][]sqldriver.Value{
+				{"app.users", "users_pkey", "PRIMARY KEY (id)"},
+				{"app.sessions", "sessions_user_id_fkey", "FOREIGN KEY (user_id) REFERENCES app.users(id)"},
+			},
+		}, nil
+	case exportIndexesQuery:
+		return &fakeRows{
+			columns: []string{"definition"},
+			values:  [][]sqldriver.Value{{"CREATE INDEX idx_users_email ON app.users USING btree (email)"}},
+		}, nil
+	}
+	return nil, errors.New("unexpected query")
+}
+
+// TestExportSchema tests the order and rendering of the exported statements
+// TestExportSchema: 出力される文の順序と組み立てをテストする関数
+func TestExportSchema(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	fake.query = exportCatalog
+
+	var out bytes.Buffer
+	if err := driver.ExportSchema(context.Background(), "app", &out); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := `-- Schema app exported from pg_catalog
+
+CREATE SEQUENCE app.invoice_number AS bigint START WITH 1000 INCREMENT BY 1 MINVALUE 1 MAXVALUE 9223372036854775807 CACHE 1;
+
+CREATE TABLE app.sessions (
+    id bigint GENERATED ALWAYS AS IDENTITY NOT NULL,
+    user_id uuid NOT NULL
+);
+
+CREATE TABLE app.users (
+    id uuid DEFAULT uuid_generate_v4() NOT NULL,
+    email character varying(255) NOT NULL,
+    "Display Name" text GENERATED ALWAYS AS ((email)::text) STORED
+);
+
+CREATE VIEW app.active_users AS
+SELECT id
+   FROM app.users;
+
+ALTER TABLE ONLY app.users ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY app.sessions ADD CONSTRAINT sessions_user_id_fkey FOREIGN KEY (user_id) REFERENCES app.users(id);
+
+CREATE INDEX idx_users_email ON app.users USING btree (email);
+`
+	if got := out.String(); got != expected {
+		t.Errorf("Expected dump:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+// TestExportSchemaFailure tests that nothing is written when a catalog query fails
+// TestExportSchemaFailure: カタログクエリの失敗時に何も書き出さないことをテストする関数
+func TestExportSchemaFailure(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		if query == exportViewsQuery {
+			return nil, errors.New("permission denied for schema app")
+		}
+		return exportCatalog(query, args)
+	}
+
+	var out bytes.Buffer
+	err := driver.ExportSchema(context.Background(), "app", &out)
+	if err == nil || !strings.Contains(err.Error(), "failed to export views of schema app") {
+		t.Errorf("Expected a views export error, got: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected nothing written, got: %s", out.String())
+	}
+}
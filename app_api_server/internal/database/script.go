@@ -0,0 +1,261 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"slices"       // slices: スライス操作
+	"strings"      // strings: 文字列操作
+)
+
+// ScriptError reports which statement of a script failed
+// ScriptError: スクリプトのどの文が失敗したかを報告するエラー型
+type ScriptError struct {
+	Number    int    // number: 失敗した文の番号（1から数える）
+	Line      int    // line: 文が始まる行番号
+	Statement string // statement: 失敗した文の抜粋
+	Err       error  // err: 文のエラー
+}
+
+// Error implements the error interface
+// Error: errorインターフェースの実装
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("script statement %d (line %d) failed: %v: %s", e.Number, e.Line, e.Err, e.Statement)
+}
+
+// Unwrap returns the statement's error
+// Unwrap: 文のエラーを返す関数
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+// ScriptOption configures ExecScript
+// ScriptOption: ExecScriptを設定するオプション
+type ScriptOption func(*scriptSettings)
+
+// scriptSettings collects the options given to ExecScript
+// scriptSettings: ExecScriptに渡されたオプションを集める構造体
+type scriptSettings struct {
+	withoutTransaction bool // without transaction: トランザクションを使わずに実行する
+}
+
+// ScriptWithoutTransaction runs each statement on its own, for statements such as CREATE INDEX CONCURRENTLY
+// ScriptWithoutTransaction: CREATE INDEX CONCURRENTLYなどのために各文を個別に実行するオプション
+// Statements before a failure stay applied: 失敗より前の文は適用されたまま残る
+func ScriptWithoutTransaction() ScriptOption {
+	return func(s *scriptSettings) {
+		s.withoutTransaction = true
+	}
+}
+
+// scriptStatement is one statement split from a script
+// scriptStatement: スクリプトから分割された1つの文
+type scriptStatement struct {
+	text string // text: 文の本文（前置きのコメントと末尾のセミコロンを除く）
+	line int    // line: 文が始まる行番号
+}
+
+// ExecScript splits script into statements and executes them in order inside one transaction
+// ExecScript: スクリプトを文に分割し、1つのトランザクション内で順に実行する関数
+// The first failure stops the script and is returned as a *ScriptError; psql meta-commands are not supported
+// 最初の失敗でスクリプトを停止し*ScriptErrorとして返す、psqlのメタコマンドには対応しない
+// Transaction control statements (BEGIN, COMMIT, ...) are rejected unless ScriptWithoutTransaction is given
+// ScriptWithoutTransactionを指定しない場合、トランザクション制御文（BEGIN、COMMITなど）は拒否する
+func (d *PostgreSQLDriver) ExecScript(ctx context.Context, script string, opts ...ScriptOption) error {
+	var settings scriptSettings
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	statements := splitStatements(script)
+	if len(statements) == 0 {
+		return nil // nothing to execute: 実行する文なし
+	}
+
+	if settings.withoutTransaction {
+		for i, statement := range statements {
+			if _, err := d.ExecContext(ctx, statement.text); err != nil {
+				return newScriptError(i, statement, err)
+			}
+		}
+		return nil
+	}
+
+	for i, statement := range statements {
+		if isTransactionControl(statement.text) {
+			return newScriptError(i, statement, fmt.Errorf("transaction control is not allowed inside the script's transaction; use ScriptWithoutTransaction"))
+		}
+	}
+
+	return d.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		for i, statement := range statements {
+			stmtCtx := d.beforeStatement(ctx, OpExec, statement.text, nil)
+			start := d.now()
+			_, err := tx.ExecContext(stmtCtx, statement.text)
+			d.afterStatement(stmtCtx, OpExec, statement.text, d.now().Sub(start), err)
+			if err != nil {
+				return newScriptError(i, statement, err)
+			}
+		}
+		return nil
+	})
+}
+
+// newScriptError wraps err for the statement at index i
+// newScriptError: インデックスiの文に対するエラーを包む関数
+func newScriptError(i int, statement scriptStatement, err error) *ScriptError {
+	return &ScriptError{Number: i + 1, Line: statement.line, Statement: truncateQuery(statement.text), Err: err}
+}
+
+// splitStatements splits script on top-level semicolons
+// splitStatements: スクリプトを最上位のセミコロンで分割する関数
+// Semicolons inside string literals, quoted identifiers, dollar-quoted bodies and comments (including nested
+// block comments) do not end a statement. Leading comments are dropped and comment-only chunks are skipped.
+// 文字列リテラル、クォート識別子、ドル引用符の本文、コメント（入れ子のブロックコメントを含む）内のセミコロンでは文を区切らない。
+// 前置きのコメントは除き、コメントだけの部分は読み飛ばす
+// SQL-standard BEGIN ATOMIC function bodies are not recognized; use a dollar-quoted body instead
+// SQL標準のBEGIN ATOMIC形式の関数本体は認識しない、ドル引用符の本体を使うこと
+func splitStatements(script string) []scriptStatement {
+	var statements []scriptStatement
+	start, startLine := -1, 0 // start: 現在の文の本文の開始位置、-1なら未開始
+	line := 1
+
+	for i := 0; i < len(script); {
+		c := script[i]
+		end := i + 1
+		comment := false
+
+		switch {
+		case c == '\'' || c == '"':
+			end = skipQuoted(script, i, c, c == '\'' && isEscapeStringPrefix(script, i))
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			end = len(script)
+			if newline := strings.IndexByte(script[i:], '\n'); newline >= 0 {
+				end = i + newline
+			}
+			comment = true
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end = skipBlockComment(script, i)
+			comment = true
+		case c == '$' && (i == 0 || !isNameChar(script[i-1])):
+			end = skipDollarQuoted(script, i) // a $ inside an identifier never opens a body: 識別子中の$は本文を開始しない
+		case c == ';':
+			if start >= 0 {
+				statements = append(statements, scriptStatement{text: strings.TrimSpace(script[start:i]), line: startLine})
+				start = -1
+			}
+		}
+
+		if start < 0 && !comment && c != ';' && !isSpaceByte(c) {
+			start, startLine = i, line
+		}
+		line += strings.Count(script[i:end], "\n")
+		i = end
+	}
+
+	if start >= 0 {
+		statements = append(statements, scriptStatement{text: strings.TrimSpace(script[start:]), line: startLine})
+	}
+	return statements
+}
+
+// CheckScript dry-parses script without a database, reporting the first unterminated string literal, quoted
+// identifier, dollar-quoted body or block comment, and the first statement whose parentheses do not balance
+// CheckScript: データベースなしでスクリプトを試験的に解析する関数、閉じられていない最初の文字列リテラル、クォート識別子、
+// ドル引用符の本文、ブロックコメントと、括弧の対応しない最初の文を報告する
+// It catches what would make ExecScript split the script wrongly, not every syntax error PostgreSQL would report
+// ExecScriptがスクリプトを誤って分割する原因を検出するもので、PostgreSQLが報告する全ての構文エラーを検出するものではない
+func CheckScript(script string) error {
+	// The trailing newline closes nothing, so a construct reaching it is unterminated
+	// 末尾の改行は何も閉じないため、そこまで達した構文は閉じられていない
+	padded := script + "\n"
+	line := 1
+	depth, openLine := 0, 0 // depth: 現在の文の開いている括弧の数、openLine: 最も外側の括弧の行番号
+	for i := 0; i < len(padded); {
+		c := padded[i]
+		end := i + 1
+		unterminated := ""
+		switch {
+		case c == '\'':
+			end = skipQuoted(padded, i, c, isEscapeStringPrefix(padded, i))
+			unterminated = "string literal"
+		case c == '"':
+			end = skipQuoted(padded, i, c, false)
+			unterminated = "quoted identifier"
+		case c == '-' && strings.HasPrefix(padded[i:], "--"):
+			end = i + strings.IndexByte(padded[i:], '\n')
+		case c == '/' && strings.HasPrefix(padded[i:], "/*"):
+			end = skipBlockComment(padded, i)
+			unterminated = "block comment"
+		case c == '$' && (i == 0 || !isNameChar(padded[i-1])):
+			end = skipDollarQuoted(padded, i)
+			unterminated = "dollar-quoted body"
+		case c == '(':
+			if depth == 0 {
+				openLine = line
+			}
+			depth++
+		case c == ')':
+			if depth == 0 {
+				return fmt.Errorf("line %d: ) without a matching (", line)
+			}
+			depth--
+		case c == ';':
+			if depth > 0 {
+				return fmt.Errorf("line %d: ( is not closed before the end of the statement", openLine)
+			}
+		}
+		if unterminated != "" && end == len(padded) {
+			return fmt.Errorf("line %d: unterminated %s", line, unterminated)
+		}
+		line += strings.Count(padded[i:end], "\n")
+		i = end
+	}
+	if depth > 0 {
+		return fmt.Errorf("line %d: ( is not closed before the end of the script", openLine)
+	}
+	return nil
+}
+
+// skipBlockComment returns the index just past the block comment starting at start, honoring nesting
+// skipBlockComment: startから始まるブロックコメントの直後のインデックスを返す関数、入れ子に対応する
+func skipBlockComment(script string, start int) int {
+	depth := 0
+	for i := start; i+1 < len(script); i++ {
+		switch {
+		case script[i] == '/' && script[i+1] == '*':
+			depth++
+			i++
+		case script[i] == '*' && script[i+1] == '/':
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(script) // unterminated: 閉じられていない
+}
+
+// isSpaceByte reports whether c is ASCII whitespace
+// isSpaceByte: cがASCIIの空白文字かどうかを判定する関数
+func isSpaceByte(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+// isTransactionControl reports whether statement begins, ends or aborts a transaction
+// isTransactionControl: 文がトランザクションを開始・終了・中止するかどうかを判定する関数
+// ROLLBACK TO SAVEPOINT stays inside the transaction and is allowed: ROLLBACK TO SAVEPOINTはトランザクション内に留まるため許可する
+func isTransactionControl(statement string) bool {
+	words := strings.Fields(strings.ToUpper(statement))
+	if len(words) == 0 {
+		return false
+	}
+	switch words[0] {
+	case "BEGIN", "START", "COMMIT", "END", "ABORT":
+		return true
+	case "ROLLBACK":
+		return !slices.Contains(words, "TO")
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"reflect"                       // reflect: リフレクション、値の比較
+	"strings"                       // strings: 文字列操作
+	"testing"                       // testing: テスト機能
+)
+
+// TestSplitStatements tests statement boundaries around literals, dollar-quoted bodies and comments
+// TestSplitStatements: リテラル、ドル引用符の本体、コメントの周辺での文の区切りをテストする関数
+func TestSplitStatements(t *testing.T) {
+	testCases := []struct {
+		name     string
+		script   string
+		expected []string
+	}{
+		{name: "Empty", script: "", expected: nil},
+		{name: "Whitespace and semicolons only", script: " ;\n;\t; ", expected: nil},
+		{name: "Single without semicolon", script: "SELECT 1", expected: []string{"SELECT 1"}},
+		{name: "Several statements", script: "CREATE TABLE t (id int);\nINSERT INTO t VALUES (1);\n", expected: []string{"CREATE TABLE t (id int)", "INSERT INTO t VALUES (1)"}},
+		{name: "Semicolon in string literal", script: "INSERT INTO t VALUES ('a;b'); SELECT 2", expected: []string{"INSERT INTO t VALUES ('a;b')", "SELECT 2"}},
+		{name: "Doubled quote", script: "SELECT 'it''s; fine'; SELECT 2", expected: []string{"SELECT 'it''s; fine'", "SELECT 2"}},
+		{name: "Escape string", script: `SELECT E'a\';b'; SELECT 2`, expected: []string{`SELECT E'a\';b'`, "SELECT 2"}},
+		{name: "Backslash in standard string", script: `SELECT 'C:\'; SELECT 2`, expected: []string{`SELECT 'C:\'`, "SELECT 2"}},
+		{name: "Quoted identifier", script: `SELECT 1 AS "a;b"; SELECT 2`, expected: []string{`SELECT 1 AS "a;b"`, "SELECT 2"}},
+		{name: "Line comment", script: "SELECT 1; -- trailing; comment\nSELECT 2; -- done", expected: []string{"SELECT 1", "SELECT 2"}},
+		{name: "Comment inside a statement", script: "SELECT 1 -- one; really\n + 1; SELECT 2", expected: []string{"SELECT 1 -- one; really\n + 1", "SELECT 2"}},
+		{name: "Block comment", script: "/* header; */ SELECT /* ; */ 1; SELECT 2", expected: []string{"SELECT /* ; */ 1", "SELECT 2"}},
+		{name: "Nested block comment", script: "/* outer /* inner; */ still; */ SELECT 1; SELECT 2", expected: []string{"SELECT 1", "SELECT 2"}},
+		{name: "Comment-only chunk", script: "SELECT 1;\n-- just a note;\n/* and; another */;\nSELECT 2;", expected: []string{"SELECT 1", "SELECT 2"}},
+		{
+			name: "Function body",
+			script: `CREATE FUNCTION bump() RETURNS trigger AS $$
+BEGIN
+  NEW.updated_at := now();
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+SELECT 2;`,
+			expected: []string{"CREATE FUNCTION bump() RETURNS trigger AS $$\nBEGIN\n  NEW.updated_at := now();\n  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql", "SELECT 2"},
+		},
+		{
+			name:     "Tagged dollar quote containing $$",
+			script:   "DO $body$ BEGIN PERFORM 'x;$$;'; END $body$; SELECT 2",
+			expected: []string{"DO $body$ BEGIN PERFORM 'x;$$;'; END $body$", "SELECT 2"},
+		},
+		{name: "Positional parameter is not a dollar quote", script: "PREPARE q AS SELECT $1; SELECT 2", expected: []string{"PREPARE q AS SELECT $1", "SELECT 2"}},
+		{name: "Dollar inside an identifier", script: "SELECT a$b$c FROM t; SELECT 2", expected: []string{"SELECT a$b$c FROM t", "SELECT 2"}},
+		{name: "Unterminated string keeps the rest", script: "SELECT 1; SELECT 'oops; SELECT 2", expected: []string{"SELECT 1", "SELECT 'oops; SELECT 2"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, statement := range splitStatements(tc.script) {
+				got = append(got, statement.text)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected %q, got: %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestSplitStatementsLines tests that each statement reports the line its text starts on
+// TestSplitStatementsLines: 各文が本文の開始行を報告することをテストする関数
+func TestSplitStatementsLines(t *testing.T) {
+	script := "-- seed data\nSELECT 1;\n\n/* multi\nline */\nSELECT $$a\nb$$;\n  SELECT 3;"
+
+	var lines []int
+	for _, statement := range splitStatements(script) {
+		lines = append(lines, statement.line)
+	}
+	if expected := []int{2, 6, 8}; !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Expected lines %v, got: %v", expected, lines)
+	}
+}
+
+// TestCheckScript tests the dry parse: balanced scripts pass, and each unterminated construct is reported on its line
+// TestCheckScript: 試験的な解析をテストする関数、対応の取れたスクリプトは通り、閉じられていない各構文はその行で報告される
+func TestCheckScript(t *testing.T) {
+	testCases := []struct {
+		name         string
+		script       string
+		expectError  bool
+		errorContent string
+	}{
+		{name: "Empty", script: ""},
+		{name: "Statements", script: "CREATE TABLE t (id int, name text DEFAULT 'a;(b');\nINSERT INTO t VALUES (1, ')');"},
+		{name: "Literal closed at the end", script: "SELECT 'it''s'"},
+		{name: "Function body", script: "CREATE FUNCTION f() RETURNS int AS $$ SELECT (1; $$ LANGUAGE sql;"},
+		{name: "Parentheses in comments", script: "-- (\n/* ) */ SELECT 1;"},
+		{name: "Unterminated literal", script: "SELECT 1;\nSELECT 'oops;\nSELECT 2;", expectError: true, errorContent: "line 2: unterminated string literal"},
+		{name: "Unterminated doubled quote", script: "SELECT 'it''", expectError: true, errorContent: "line 1: unterminated string literal"},
+		{name: "Unterminated escape string", script: `SELECT E'a\'`, expectError: true, errorContent: "unterminated string literal"},
+		{name: "Unterminated identifier", script: `SELECT 1 AS "a`, expectError: true, errorContent: "unterminated quoted identifier"},
+		{name: "Unterminated block comment", script: "SELECT 1;\n/* note /* nested */", expectError: true, errorContent: "line 2: unterminated block comment"},
+		{name: "Unterminated dollar body", script: "DO $body$ BEGIN NULL; END $$;", expectError: true, errorContent: "unterminated dollar-quoted body"},
+		{name: "Unclosed parenthesis", script: "CREATE TABLE t (\n  id int;\nSELECT 1;", expectError: true, errorContent: "line 1: ( is not closed before the end of the statement"},
+		{name: "Unclosed at the end", script: "SELECT 1;\nSELECT (1", expectError: true, errorContent: "line 2: ( is not closed before the end of the script"},
+		{name: "Extra closing parenthesis", script: "SELECT 1;\nSELECT 1);", expectError: true, errorContent: "line 2: ) without a matching ("},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckScript(tc.script)
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestIsTransactionControl tests which statements count as transaction control
+// TestIsTransactionControl: どの文がトランザクション制御とみなされるかをテストする関数
+func TestIsTransactionControl(t *testing.T) {
+	testCases := map[string]bool{
+		"BEGIN":                     true,
+		"start transaction":         true,
+		"COMMIT":                    true,
+		"end":                       true,
+		"ROLLBACK":                  true,
+		"ROLLBACK TO SAVEPOINT s1":  false,
+		"rollback work to s1":       false,
+		"SAVEPOINT s1":              false,
+		"CREATE TABLE begin_log ()": false,
+	}
+	for statement, expected := range testCases {
+		if got := isTransactionControl(statement); got != expected {
+			t.Errorf("Expected %q to be %v, got: %v", statement, expected, got)
+		}
+	}
+}
+
+// TestExecScript tests transactional and non-transactional execution and the reported failing statement
+// TestExecScript: トランザクションあり・なしの実行と、報告される失敗した文をテストする関数
+func TestExecScript(t *testing.T) {
+	script := "CREATE TABLE t (id int);\n\nINSERT INTO t VALUES (1);\nINSERT INTO broken VALUES ('x;y');\nINSERT INTO t VALUES (2);"
+	failing := func(query string, args []sqldriver.NamedValue) (sqldriver.Result, error) {
+		if strings.Contains(query, "broken") {
+			return nil, errors.New(`relation "broken" does not exist`)
+		}
+		return sqldriver.RowsAffected(1), nil
+	}
+
+	testCases := []struct {
+		name     string
+		opts     []ScriptOption
+		expected []string
+	}{
+		{
+			name:     "Transactional",
+			expected: []string{"BEGIN", "CREATE TABLE t (id int)", "INSERT INTO t VALUES (1)", "INSERT INTO broken VALUES ('x;y')", "ROLLBACK"},
+		},
+		{
+			name:     "Without transaction",
+			opts:     []ScriptOption{ScriptWithoutTransaction()},
+			expected: []string{"CREATE TABLE t (id int)", "INSERT INTO t VALUES (1)", "INSERT INTO broken VALUES ('x;y')"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, fake := newTestDriver(t)
+			fake.exec = failing
+
+			err := driver.ExecScript(context.Background(), script, tc.opts...)
+			var scriptErr *ScriptError
+			if !errors.As(err, &scriptErr) {
+				t.Fatalf("Expected a *ScriptError, got: %v", err)
+			}
+			if scriptErr.Number != 3 || scriptErr.Line != 4 || scriptErr.Statement != "INSERT INTO broken VALUES ('x;y')" {
+				t.Errorf("Expected statement 3 on line 4, got: %+v", scriptErr)
+			}
+			if !strings.Contains(err.Error(), `relation "broken" does not exist`) {
+				t.Errorf("Expected the database error in the message, got: %v", err)
+			}
+			if got := fake.executed(); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected statements %q, got: %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestExecScriptRejectsTransactionControl tests that BEGIN/COMMIT are rejected before anything runs
+// TestExecScriptRejectsTransactionControl: BEGIN/COMMITが何も実行する前に拒否されることをテストする関数
+func TestExecScriptRejectsTransactionControl(t *testing.T) {
+	driver, fake := newTestDriver(t)
+
+	err := driver.ExecScript(context.Background(), "BEGIN;\nUPDATE t SET x = 1;\nCOMMIT;")
+	if err == nil || !strings.Contains(err.Error(), "ScriptWithoutTransaction") {
+		t.Errorf("Expected transaction control error, got: %v", err)
+	}
+	if statements := fake.executed(); len(statements) != 0 {
+		t.Errorf("Expected no statements, got: %v", statements)
+	}
+
+	if err := driver.ExecScript(context.Background(), "BEGIN;\nUPDATE t SET x = 1;\nCOMMIT;", ScriptWithoutTransaction()); err != nil {
+		t.Errorf("Expected transaction control to run without a transaction, got: %v", err)
+	}
+	if err := driver.ExecScript(context.Background(), "-- nothing to do\n"); err != nil {
+		t.Errorf("Expected an empty script to succeed, got: %v", err)
+	}
+}
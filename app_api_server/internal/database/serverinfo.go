@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ、NULLを許す型
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"strings"      // strings: 文字列操作
+)
+
+// serverInfoQuery reads every ServerInfo field in one round trip
+// serverInfoQuery: ServerInfoの全フィールドを1往復で読み込むクエリ
+// current_schema() is NULL when no search_path schema exists, and the address and port are NULL over a Unix socket
+// search_pathのスキーマが存在しない場合current_schema()はNULL、UNIXソケット接続ではアドレスとポートがNULLになる
+const serverInfoQuery = `SELECT current_setting('server_version_num'), current_database(), current_user, current_schema(),
+	host(inet_server_addr()), inet_server_port(), pg_backend_pid(),
+	COALESCE((SELECT ssl FROM pg_stat_ssl WHERE pid = pg_backend_pid()), false)`
+
+// ServerInfo describes the server and session a pool connection is attached to
+// ServerInfo: プールの接続がつながっているサーバーとセッションを表す構造体
+// session: セッション
+type ServerInfo struct {
+	ServerVersion   string `json:"server_version"`        // server version: サーバーのバージョン（"16.2"など）
+	CurrentDatabase string `json:"current_database"`      // current database: 接続中のデータベース
+	CurrentUser     string `json:"current_user"`          // current user: 有効なユーザー
+	CurrentSchema   string `json:"current_schema"`        // current schema: search_pathの先頭の存在するスキーマ
+	ServerAddr      string `json:"server_addr,omitempty"` // server address: inet_server_addr()、UNIXソケットでは空
+	ServerPort      int    `json:"server_port,omitempty"` // server port: inet_server_port()、UNIXソケットでは0
+	BackendPID      int    `json:"backend_pid"`           // backend PID: サーバー側のプロセスID
+	SSL             bool   `json:"ssl"`                   // ssl: 接続がSSLで暗号化されているか（pg_stat_ssl）
+}
+
+// String summarizes the information on one line, for CLI output and logs
+// String: CLIの出力やログ用に情報を1行にまとめる関数
+func (s ServerInfo) String() string {
+	parts := []string{
+		"PostgreSQL " + s.ServerVersion,
+		fmt.Sprintf("database %s as %s", s.CurrentDatabase, s.CurrentUser),
+	}
+	if s.CurrentSchema != "" {
+		parts = append(parts, "schema "+s.CurrentSchema)
+	}
+	if s.ServerAddr != "" {
+		parts = append(parts, fmt.Sprintf("server %s:%d", s.ServerAddr, s.ServerPort))
+	}
+	parts = append(parts, fmt.Sprintf("backend %d", s.BackendPID))
+	if s.SSL {
+		parts = append(parts, "ssl on")
+	} else {
+		parts = append(parts, "ssl off")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// GetServerInfo reports what a pool connection is actually attached to, in one round trip
+// GetServerInfo: プールの接続が実際につながっている先を1往復で報告する関数
+// Useful for telling environments apart when debugging: 複数環境のデバッグ時に接続先を見分けるのに役立つ
+// attached: つながっている
+func (d *PostgreSQLDriver) GetServerInfo(ctx context.Context) (info ServerInfo, err error) {
+	ctx, span := d.startSpan(ctx, "db.GetServerInfo", serverInfoQuery)
+	defer func() { endSpan(span, err) }()
+
+	db, err := d.usablePool()
+	if err != nil {
+		return info, err
+	}
+
+	var (
+		versionNum string
+		schema     sql.NullString // schema: search_path次第でNULL
+		addr       sql.NullString // addr: UNIXソケットではNULL
+		port       sql.NullInt64  // port: UNIXソケットではNULL
+	)
+	err = db.QueryRowContext(ctx, serverInfoQuery).Scan(
+		&versionNum, &info.CurrentDatabase, &info.CurrentUser, &schema, &addr, &port, &info.BackendPID, &info.SSL,
+	)
+	if err != nil {
+		return ServerInfo{}, fmt.Errorf("failed to query server info: %w", err)
+	}
+
+	num, err := parseServerVersionNum(versionNum)
+	if err != nil {
+		return ServerInfo{}, err
+	}
+	info.ServerVersion = formatServerVersion(num)
+	info.CurrentSchema = schema.String
+	info.ServerAddr = addr.String
+	info.ServerPort = int(port.Int64)
+	return info, nil
+}
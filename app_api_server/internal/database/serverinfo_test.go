@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"testing"                       // testing: テスト機能
+)
+
+// TestGetServerInfo tests that one query fills every field, including NULL address, port and schema
+// TestGetServerInfo: 1つのクエリで全フィールドが設定されること（NULLのアドレス、ポート、スキーマを含む）をテストする関数
+func TestGetServerInfo(t *testing.T) {
+	columns := []string{"server_version_num", "current_database", "current_user", "current_schema", "host", "inet_server_port", "pg_backend_pid", "ssl"}
+
+	testCases := []struct {
+		name     string
+		row      []sqldriver.Value
+		expected ServerInfo
+		summary  string
+	}{
+		{
+			name:     "TCP with SSL",
+			row:      []sqldriver.Value{"160002", "sift", "app_user", "app", "10.0.0.5", int64(5432), int64(4242), true},
+			expected: ServerInfo{ServerVersion: "16.2", CurrentDatabase: "sift", CurrentUser: "app_user", CurrentSchema: "app", ServerAddr: "10.0.0.5", ServerPort: 5432, BackendPID: 4242, SSL: true},
+			summary:  "PostgreSQL 16.2, database sift as app_user, schema app, server 10.0.0.5:5432, backend 4242, ssl on",
+		},
+		{
+			name:     "Unix socket without a schema",
+			row:      []sqldriver.Value{"130004", "sift", "postgres", nil, nil, nil, int64(7), false},
+			expected: ServerInfo{ServerVersion: "13.4", CurrentDatabase: "sift", CurrentUser: "postgres", BackendPID: 7},
+			summary:  "PostgreSQL 13.4, database sift as postgres, backend 7, ssl off",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, fake := newTestDriver(t)
+			fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+				if query != serverInfoQuery {
+					return nil, errors.New("unexpected query")
+				}
+				return &fakeRows{columns: columns, values: [][]sqldriver.Value{tc.row}}, nil
+			}
+
+			info, err := driver.GetServerInfo(context.Background())
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if info != tc.expected {
+				t.Errorf("Expected %+v, got: %+v", tc.expected, info)
+			}
+			if info.String() != tc.summary {
+				t.Errorf("Expected '%s', got: '%s'", tc.summary, info.String())
+			}
+			if statements := fake.executed(); len(statements) != 1 {
+				t.Errorf("Expected one round trip, got: %v", statements)
+			}
+		})
+	}
+}
+
+// TestGetServerInfoNotConnected tests that a driver without a pool returns ErrNotConnected
+// TestGetServerInfoNotConnected: プールのないドライバーがErrNotConnectedを返すことをテストする関数
+func TestGetServerInfoNotConnected(t *testing.T) {
+	driver, _ := newTestDriver(t)
+	driver.db = nil
+
+	if _, err := driver.GetServerInfo(context.Background()); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("Expected ErrNotConnected, got: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"strconv" // strconv: string conversion（文字列変換）
+	"strings" // strings: 文字列操作
+	"time"    // time: 時間操作機能
+)
+
+// sessionParameter is a run-time parameter sent in the connection string as a session default
+// sessionParameter: セッションのデフォルトとして接続文字列で送信されるrun-time parameter
+type sessionParameter struct {
+	key   string // key: パラメータ名
+	value string // value: 値
+}
+
+// sessionParameters returns the session defaults modeled as DatabaseConfig fields, in connection string order
+// sessionParameters: DatabaseConfigのフィールドとして持つセッションのデフォルトを接続文字列の順に返す関数
+// lib/pq sends keys it does not know as run-time parameters, so every new connection, including after Reconnect, gets them
+// lib/pqは未知のキーをrun-time parameterとして送信するため、Reconnect後を含む全ての新しい接続に適用される
+func (c *DatabaseConfig) sessionParameters() []sessionParameter {
+	var params []sessionParameter
+	if c.LockTimeout > 0 {
+		params = append(params, sessionParameter{"lock_timeout", durationMilliseconds(c.LockTimeout)})
+	}
+	if c.IdleInTransactionTimeout > 0 {
+		params = append(params, sessionParameter{"idle_in_transaction_session_timeout", durationMilliseconds(c.IdleInTransactionTimeout)})
+	}
+	if c.ReadOnly {
+		params = append(params, sessionParameter{"default_transaction_read_only", "on"})
+	}
+	return params
+}
+
+// durationMilliseconds renders d in whole milliseconds, rounding up so a sub-millisecond timeout is not sent as 0 (disabled)
+// durationMilliseconds: dをミリ秒単位の文字列にする関数、1ミリ秒未満が0（無効）にならないよう切り上げる
+func durationMilliseconds(d time.Duration) string {
+	milliseconds := (d + time.Millisecond - 1) / time.Millisecond
+	return strconv.FormatInt(int64(milliseconds), 10)
+}
+
+// durationSeconds renders d in whole seconds, rounding up so a sub-second setting is not sent as 0
+// durationSeconds: dを秒単位の文字列にする関数、1秒未満が0にならないよう切り上げる
+func durationSeconds(d time.Duration) string {
+	seconds := (d + time.Second - 1) / time.Second
+	return strconv.FormatInt(int64(seconds), 10)
+}
+
+// parseOnOffParameter parses a boolean run-time parameter written as on/off or true/false
+// parseOnOffParameter: on/offまたはtrue/falseで書かれた真偽値のrun-time parameterを解析する関数
+func parseOnOffParameter(key, value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "on", "true", "1":
+		return true, nil
+	case "off", "false", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid %s: %q is not on or off", key, value)
+}
+
+// readOnly reports whether the driver is in read-only mode
+// readOnly: ドライバーが読み取り専用モードかどうかを判定する関数
+func (d *PostgreSQLDriver) readOnly() bool {
+	return d.config.Load().ReadOnly
+}
+
+// parseMillisecondsParameter parses a timeout parameter written in milliseconds, as PostgreSQL does, or as a duration such as "5s"
+// parseMillisecondsParameter: PostgreSQLと同じミリ秒、または"5s"などの時間文字列で書かれたタイムアウトのパラメータを解析する関数
+func parseMillisecondsParameter(key, value string) (time.Duration, error) {
+	if milliseconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(milliseconds) * time.Millisecond, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %q is neither milliseconds nor a duration", key, value)
+	}
+	return d, nil
+}
+
+// parseSecondsParameter parses a libpq parameter written in whole seconds
+// parseSecondsParameter: 秒単位の整数で書かれたlibpqのパラメータを解析する関数
+func parseSecondsParameter(key, value string) (time.Duration, error) {
+	seconds, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %q is not a number of seconds", key, value)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"database/sql" // sql: データベース操作用パッケージ
+	"strings"      // strings: 文字列操作
+	"testing"      // testing: テスト機能
+	"time"         // time: 時間操作機能
+)
+
+// TestSessionTimeoutsConnectionString tests how the timeouts are rendered in both connection string forms
+// TestSessionTimeoutsConnectionString: 両方の接続文字列形式でのタイムアウトの出力をテストする関数
+func TestSessionTimeoutsConnectionString(t *testing.T) {
+	testCases := []struct {
+		name        string
+		lockTimeout time.Duration
+		idleTimeout time.Duration
+		parsedLock  time.Duration // parsed lock: URLから読み戻したlock_timeout
+		expected    []string
+		unexpected  []string
+	}{
+		{name: "Unset", unexpected: []string{"lock_timeout", "idle_in_transaction_session_timeout"}},
+		{name: "Whole milliseconds", lockTimeout: 5 * time.Second, idleTimeout: time.Minute, parsedLock: 5 * time.Second, expected: []string{"lock_timeout=5000", "idle_in_transaction_session_timeout=60000"}},
+		{name: "Rounded up", lockTimeout: 1500 * time.Microsecond, parsedLock: 2 * time.Millisecond, expected: []string{"lock_timeout=2"}, unexpected: []string{"idle_in_transaction_session_timeout"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &DatabaseConfig{Host: "localhost", Port: 5432, User: "user", Password: "pass", Database: "db", SSLMode: "disable",
+				LockTimeout: tc.lockTimeout, IdleInTransactionTimeout: tc.idleTimeout}
+
+			for _, built := range []string{config.BuildConnectionString(), config.BuildConnectionURL()} {
+				for _, expected := range tc.expected {
+					if !strings.Contains(built, expected) {
+						t.Errorf("Expected '%s' in %s", expected, built)
+					}
+				}
+				for _, unexpected := range tc.unexpected {
+					if strings.Contains(built, unexpected) {
+						t.Errorf("Expected no '%s' in %s", unexpected, built)
+					}
+				}
+			}
+
+			parsed, err := ConfigFromURL(config.BuildConnectionURL())
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if parsed.LockTimeout != tc.parsedLock {
+				t.Errorf("Expected lock timeout %s, got: %s", tc.parsedLock, parsed.LockTimeout)
+			}
+			if parsed.IdleInTransactionTimeout != tc.idleTimeout {
+				t.Errorf("Expected idle in transaction timeout %s, got: %s", tc.idleTimeout, parsed.IdleInTransactionTimeout)
+			}
+		})
+	}
+}
+
+// TestLoadDatabaseConfigSessionTimeouts tests reading the timeouts from the environment
+// TestLoadDatabaseConfigSessionTimeouts: 環境変数からのタイムアウトの読み込みをテストする関数
+func TestLoadDatabaseConfigSessionTimeouts(t *testing.T) {
+	testCases := []struct {
+		name         string
+		lockTimeout  string
+		idleTimeout  string
+		expectedLock time.Duration
+		expectedIdle time.Duration
+		expectError  bool
+	}{
+		{name: "Unset"},
+		{name: "Set", lockTimeout: "5s", idleTimeout: "1m", expectedLock: 5 * time.Second, expectedIdle: time.Minute},
+		{name: "Invalid lock timeout", lockTimeout: "soon", expectError: true},
+		{name: "Invalid idle timeout", idleTimeout: "10", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("DB_USER", "user")
+			t.Setenv("DB_PASSWORD", "pass")
+			t.Setenv("DB_NAME", "db")
+			t.Setenv("DB_LOCK_TIMEOUT", tc.lockTimeout)
+			t.Setenv("DB_IDLE_IN_TX_TIMEOUT", tc.idleTimeout)
+
+			config, err := LoadDatabaseConfig()
+			if tc.expectError {
+				if err == nil {
+					t.Error("Expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if config.LockTimeout != tc.expectedLock || config.IdleInTransactionTimeout != tc.expectedIdle {
+				t.Errorf("Expected %s/%s, got: %s/%s", tc.expectedLock, tc.expectedIdle, config.LockTimeout, config.IdleInTransactionTimeout)
+			}
+		})
+	}
+}
+
+// TestSessionTimeoutsValidation tests that negative timeouts and colliding options are rejected
+// TestSessionTimeoutsValidation: 負のタイムアウトと衝突するオプションが拒否されることをテストする関数
+func TestSessionTimeoutsValidation(t *testing.T) {
+	testCases := []struct {
+		name         string
+		edit         func(*DatabaseConfig)
+		errorContent string
+	}{
+		{name: "Negative lock timeout", edit: func(c *DatabaseConfig) { c.LockTimeout = -time.Second }, errorContent: "cannot be negative"},
+		{name: "Negative idle timeout", edit: func(c *DatabaseConfig) { c.IdleInTransactionTimeout = -time.Second }, errorContent: "cannot be negative"},
+		{name: "Option collision", edit: func(c *DatabaseConfig) { c.Options = map[string]string{"lock_timeout": "100"} }, errorContent: "conflicts with a DatabaseConfig field"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &DatabaseConfig{Host: "localhost", Port: 5432, User: "user", Password: "pass", Database: "db", SSLMode: "disable"}
+			tc.edit(config)
+
+			err := validateDatabaseConfig(config)
+			if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+				t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+			}
+		})
+	}
+}
+
+// TestSessionTimeoutsSurviveReconnect tests that a reconnected pool is opened with the same session defaults
+// TestSessionTimeoutsSurviveReconnect: 再接続したプールが同じセッションのデフォルトで開かれることをテストする関数
+func TestSessionTimeoutsSurviveReconnect(t *testing.T) {
+	driver, err := NewPostgreSQLDriverWithConfig(&DatabaseConfig{
+		Host: "localhost", Port: 5432, User: "user", Password: "pass", Database: "db", SSLMode: "disable",
+		LockTimeout: 3 * time.Second, IdleInTransactionTimeout: 30 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	var connectionStrings []string
+	driver.openDB = func(connectionString string) (*sql.DB, error) {
+		connectionStrings = append(connectionStrings, connectionString)
+		_, db := newFakeDB()
+		return db, nil
+	}
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer driver.Close()
+	if err := driver.Reconnect(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(connectionStrings) != 2 {
+		t.Fatalf("Expected 2 pools to be opened, got: %d", len(connectionStrings))
+	}
+	for _, connectionString := range connectionStrings {
+		if !strings.Contains(connectionString, "lock_timeout=3000") || !strings.Contains(connectionString, "idle_in_transaction_session_timeout=30000") {
+			t.Errorf("Expected both session timeouts, got: %s", connectionString)
+		}
+	}
+}
+
+// TestReadOnlyConfig tests the read-only session default in the connection string, URL and environment
+// TestReadOnlyConfig: 接続文字列、URL、環境変数での読み取り専用のセッションのデフォルトをテストする関数
+func TestReadOnlyConfig(t *testing.T) {
+	config := &DatabaseConfig{Host: "localhost", Port: 5432, User: "user", Password: "pass", Database: "db", SSLMode: "disable", ReadOnly: true}
+	if !strings.Contains(config.BuildConnectionString(), "default_transaction_read_only=on") {
+		t.Errorf("Expected the read-only session default, got: %s", config.BuildConnectionString())
+	}
+	parsed, err := ConfigFromURL(config.BuildConnectionURL())
+	if err != nil || !parsed.ReadOnly {
+		t.Errorf("Expected read-only mode to round-trip through the URL, got: %v (%v)", parsed, err)
+	}
+
+	config.ReadOnly = false
+	if strings.Contains(config.BuildConnectionString(), "default_transaction_read_only") {
+		t.Errorf("Expected no read-only session default, got: %s", config.BuildConnectionString())
+	}
+
+	testCases := []struct {
+		name        string
+		value       string
+		expected    bool
+		expectError bool
+	}{
+		{name: "Unset", value: ""},
+		{name: "Enabled", value: "true", expected: true},
+		{name: "Disabled", value: "false"},
+		{name: "Invalid", value: "reports", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("DB_USER", "user")
+			t.Setenv("DB_PASSWORD", "pass")
+			t.Setenv("DB_NAME", "db")
+			t.Setenv("DB_READ_ONLY", tc.value)
+
+			loaded, err := LoadDatabaseConfig()
+			if tc.expectError {
+				if err == nil {
+					t.Error("Expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if loaded.ReadOnly != tc.expected {
+				t.Errorf("Expected ReadOnly %v, got: %v", tc.expected, loaded.ReadOnly)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"sort"         // sort: 並べ替え
+	"strings"      // strings: 文字列操作
+	"time"         // time: 時間操作機能
+
+	"github.com/lib/pq" // pq: PostgreSQLドライバー、配列型
+)
+
+// sessionSettingsTimeout bounds the settings query Connect runs for WithSessionSettingsLog
+// sessionSettingsTimeout: WithSessionSettingsLogのためにConnectが実行する設定クエリの制限時間
+const sessionSettingsTimeout = 5 * time.Second
+
+// sessionSettingsQuery returns the current value of each setting in $1 that the server knows
+// sessionSettingsQuery: $1のうちサーバーが知っている設定の現在の値を返すクエリ
+// current_setting renders values as SHOW does, with units; pg_settings filters out unknown names
+// current_settingはSHOWと同じく単位付きで値を表す、pg_settingsで未知の名前を除外する
+// pg_settings keeps the mixed case of names such as TimeZone, so they are compared in lower case
+// pg_settingsはTimeZoneなどの名前の大文字小文字を保持するため、小文字で比較する
+const sessionSettingsQuery = `SELECT lower(name), current_setting(name) FROM pg_catalog.pg_settings WHERE lower(name) = ANY($1)`
+
+// clientOptionKeys are the Options keys lib/pq consumes itself instead of sending them to the server
+// clientOptionKeys: lib/pqがサーバーへ送らず自身で使うOptionsのキー
+var clientOptionKeys = map[string]bool{
+	"sslcert":                        true,
+	"sslkey":                         true,
+	"sslrootcert":                    true,
+	"sslinline":                      true,
+	"sslsni":                         true,
+	"krbsrvname":                     true,
+	"krbspn":                         true,
+	"binary_parameters":              true,
+	"disable_prepared_binary_result": true,
+
+	// Sent at startup but parsed into other settings rather than being one: 起動時に送るが、それ自体は設定ではなく他の設定に展開される
+	"options": true,
+}
+
+// WithSessionSettingsLog makes Connect log, at debug level, the values the server applied for the run-time parameters the configuration sets
+// WithSessionSettingsLog: 設定したrun-time parameterにサーバーが適用した値を、Connect時にデバッグレベルでログに出すオプション
+// applied: 適用した
+func WithSessionSettingsLog() DriverOption {
+	return func(d *PostgreSQLDriver) {
+		d.logSessionSettings = true
+	}
+}
+
+// runtimeParameterNames returns the run-time parameters the connection string sends to the server, sorted
+// runtimeParameterNames: 接続文字列がサーバーへ送るrun-time parameterの名前を並べ替えて返す関数
+func (c *DatabaseConfig) runtimeParameterNames() []string {
+	var names []string
+	if c.TimeZone != "" {
+		names = append(names, "timezone")
+	}
+	for _, param := range c.sessionParameters() {
+		names = append(names, param.key)
+	}
+	for key := range c.Options {
+		if key = strings.ToLower(key); !clientOptionKeys[key] {
+			names = append(names, key)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetSessionSettings returns the effective values of the named settings on a pool connection, keyed by lower-case name
+// GetSessionSettings: プールの接続で有効な、指定した設定の値を小文字の名前をキーとして返す関数
+// Without names it returns the run-time parameters the configuration sets. Names the server does not know are listed
+// in the error, which is returned together with the values that were found.
+// 名前を省略すると設定が送るrun-time parameterを返す。サーバーが知らない名前はエラーに列挙し、見つかった値と共に返す
+// effective: 有効な、実際に適用されている
+func (d *PostgreSQLDriver) GetSessionSettings(ctx context.Context, names ...string) (settings map[string]string, err error) {
+	ctx, span := d.startSpan(ctx, "db.GetSessionSettings", sessionSettingsQuery)
+	defer func() { endSpan(span, err) }()
+
+	db, err := d.usablePool()
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		d.mu.Lock()
+		names = d.config.Load().runtimeParameterNames()
+		d.mu.Unlock()
+	}
+	return querySessionSettings(ctx, db, names)
+}
+
+// querySessionSettings reads the named settings from db in one round trip
+// querySessionSettings: 指定した設定をdbから1往復で読み取る内部関数
+func querySessionSettings(ctx context.Context, db *sql.DB, names []string) (map[string]string, error) {
+	settings := make(map[string]string, len(names))
+	if len(names) == 0 {
+		return settings, nil
+	}
+	lowered := make([]string, len(names))
+	for i, name := range names {
+		lowered[i] = strings.ToLower(strings.TrimSpace(name)) // setting names are case-insensitive: 設定名は大文字小文字を区別しない
+	}
+
+	rows, err := db.QueryContext(ctx, sessionSettingsQuery, pq.Array(lowered))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session settings: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan session setting: %w", err)
+		}
+		settings[name] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read session settings: %w", err)
+	}
+
+	var unknown []string
+	for _, name := range lowered {
+		if _, ok := settings[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return settings, fmt.Errorf("unknown session setting(s): %s", strings.Join(unknown, ", "))
+	}
+	return settings, nil
+}
+
+// logEffectiveSettings logs the values the server applied for the configured run-time parameters
+// logEffectiveSettings: 設定したrun-time parameterにサーバーが適用した値をログに出す内部関数
+// A failure is only logged, so it never fails Connect: 失敗はログに出すだけで、Connectを失敗させない
+func (d *PostgreSQLDriver) logEffectiveSettings(ctx context.Context, db *sql.DB) {
+	d.mu.Lock()
+	names := d.config.Load().runtimeParameterNames()
+	d.mu.Unlock()
+	if len(names) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sessionSettingsTimeout)
+	defer cancel()
+	settings, err := querySessionSettings(ctx, db, names)
+	if err != nil {
+		d.logger.Debug(fmt.Sprintf("Could not read all effective session settings: %v", err), "error", err.Error())
+	}
+
+	pairs := make([]string, 0, len(settings))
+	keysAndValues := make([]interface{}, 0, 2*len(settings))
+	for _, name := range names {
+		if value, ok := settings[name]; ok {
+			pairs = append(pairs, name+"="+value)
+			keysAndValues = append(keysAndValues, name, value)
+		}
+	}
+	if len(pairs) > 0 {
+		d.logger.Debug("Effective session settings: "+strings.Join(pairs, ", "), keysAndValues...)
+	}
+}
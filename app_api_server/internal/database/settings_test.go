@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	"database/sql"                  // sql: データベース操作用パッケージ
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"reflect"                       // reflect: 値の比較
+	"strings"                       // strings: 文字列操作
+	"testing"                       // testing: テスト機能
+	"time"                          // time: 時間操作機能
+)
+
+// serverSettings answers sessionSettingsQuery from a fixed set of settings, like a server that knows only those
+// serverSettings: 固定の設定からsessionSettingsQueryに応答する関数、それらだけを知るサーバーのように振る舞う
+func serverSettings(known map[string]string) func(string, []sqldriver.NamedValue) (sqldriver.Rows, error) {
+	return func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		rows := &fakeRows{columns: []string{"name", "current_setting"}}
+		requested, _ := args[0].Value.(string) // an array literal such as {"a","b"}: {"a","b"}のような配列リテラル
+		for _, name := range strings.Split(strings.Trim(requested, "{}"), ",") {
+			name = strings.Trim(name, `"`)
+			if value, ok := known[name]; ok {
+				rows.values = append(rows.values, []sqldriver.Value{name, value})
+			}
+		}
+		return rows, nil
+	}
+}
+
+// TestRuntimeParameterNames tests which configured parameters count as server settings
+// TestRuntimeParameterNames: どの設定がサーバーの設定として扱われるかをテストする関数
+func TestRuntimeParameterNames(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   DatabaseConfig
+		expected []string
+	}{
+		{name: "Nothing set", config: DatabaseConfig{}, expected: nil},
+		{name: "Time zone and session defaults", config: DatabaseConfig{TimeZone: "UTC", LockTimeout: time.Second, ReadOnly: true},
+			expected: []string{"default_transaction_read_only", "lock_timeout", "timezone"}},
+		{name: "Options without client keys", config: DatabaseConfig{Options: map[string]string{
+			"search_path": "app", "Statement_Timeout": "30000", "sslrootcert": "/ca.pem", "options": "-c geqo=off",
+		}}, expected: []string{"search_path", "statement_timeout"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if names := tc.config.runtimeParameterNames(); !reflect.DeepEqual(names, tc.expected) {
+				t.Errorf("Expected %v, got: %v", tc.expected, names)
+			}
+		})
+	}
+}
+
+// TestGetSessionSettings tests reading settings and reporting the names the server does not know
+// TestGetSessionSettings: 設定の読み取りと、サーバーが知らない名前の報告をテストする関数
+func TestGetSessionSettings(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	updateTestConfig(driver, func(config *DatabaseConfig) { config.Options = map[string]string{"search_path": "app"} })
+	fake.query = serverSettings(map[string]string{"timezone": "UTC", "search_path": "app", "statement_timeout": "30s"})
+	ctx := context.Background()
+
+	testCases := []struct {
+		name         string
+		names        []string
+		expected     map[string]string
+		expectError  bool
+		errorContent string
+	}{
+		{name: "Named settings", names: []string{"statement_timeout", "TimeZone"},
+			expected: map[string]string{"statement_timeout": "30s", "timezone": "UTC"}},
+		{name: "Configured settings", names: nil,
+			expected: map[string]string{"search_path": "app", "timezone": "UTC"}},
+		{name: "Unknown settings", names: []string{"timezone", "no_such_setting", "statment_timeout"},
+			expected: map[string]string{"timezone": "UTC"}, expectError: true, errorContent: "no_such_setting, statment_timeout"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			settings, err := driver.GetSessionSettings(ctx, tc.names...)
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing %q, got: %v", tc.errorContent, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(settings, tc.expected) {
+				t.Errorf("Expected %v, got: %v", tc.expected, settings)
+			}
+		})
+	}
+}
+
+// TestConnectLogsSessionSettings tests that WithSessionSettingsLog logs the effective values at debug level
+// TestConnectLogsSessionSettings: WithSessionSettingsLogが有効な値をデバッグレベルでログに出すことをテストする関数
+func TestConnectLogsSessionSettings(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		driver := newFakeConnectingDriver(t)
+		logger := &capturingLogger{}
+		driver.logger = logger
+		driver.logSessionSettings = enabled
+		updateTestConfig(driver, func(config *DatabaseConfig) { config.LockTimeout = 5 * time.Second })
+		driver.openDB = func(string) (*sql.DB, error) {
+			fake, db := newFakeDB()
+			fake.query = serverSettings(map[string]string{"timezone": "UTC", "lock_timeout": "5s"})
+			return db, nil
+		}
+
+		if err := driver.Connect(); err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		entry, found := logger.find("Effective session settings")
+		if found != enabled {
+			t.Errorf("Expected the settings logged: %t, got: %t", enabled, found)
+			continue
+		}
+		if enabled && (entry.level != "debug" || !strings.Contains(entry.msg, "lock_timeout=5s, timezone=UTC")) {
+			t.Errorf("Expected a debug entry with both settings, got: %+v", entry)
+		}
+		driver.Close()
+	}
+}
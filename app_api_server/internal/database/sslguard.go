@@ -0,0 +1,69 @@
+package database
+
+import (
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"log"     // log: ログ出力機能
+	"os"      // os: operating system（オペレーティングシステム）、環境変数の読み込み
+	"slices"  // slices: スライス操作
+	"strconv" // strconv: string conversion（文字列変換）、真偽値の解析
+	"strings" // strings: 文字列操作
+)
+
+// defaultProductionEnvironments lists the APP_ENV values treated as production when DB_PRODUCTION_ENVS is unset
+// defaultProductionEnvironments: DB_PRODUCTION_ENVSが未設定の場合に本番とみなすAPP_ENVの値
+const defaultProductionEnvironments = "production"
+
+// insecureSSLModes are the sslmode values that may fall back to an unencrypted connection
+// insecureSSLModes: 暗号化されていない接続になりうるsslmodeの値
+// insecure: 安全でない
+var insecureSSLModes = []string{"disable", "allow", "prefer"}
+
+// ProductionEnvironment returns APP_ENV when it is one of the production environments
+// ProductionEnvironment: APP_ENVが本番環境の1つである場合にその値を返す関数
+// DB_PRODUCTION_ENVS overrides the comma-separated list, such as "production,staging"
+// DB_PRODUCTION_ENVSでカンマ区切りの一覧を上書きできる（"production,staging"など）
+func ProductionEnvironment() (string, bool) {
+	appEnv := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV")))
+	if appEnv == "" {
+		return "", false
+	}
+
+	list := os.Getenv("DB_PRODUCTION_ENVS")
+	if strings.TrimSpace(list) == "" {
+		list = defaultProductionEnvironments
+	}
+	for _, env := range strings.Split(list, ",") {
+		if strings.ToLower(strings.TrimSpace(env)) == appEnv {
+			return appEnv, true
+		}
+	}
+	return "", false
+}
+
+// checkProductionSSLMode rejects an insecure sslmode in production unless DB_ALLOW_INSECURE=true
+// checkProductionSSLMode: 本番環境ではDB_ALLOW_INSECURE=trueがない限り安全でないsslmodeを拒否する関数
+// Development and test environments, and an unset APP_ENV, are not checked
+// 開発・テスト環境やAPP_ENVが未設定の場合は確認しない
+func checkProductionSSLMode(sslMode string) error {
+	if !slices.Contains(insecureSSLModes, sslMode) {
+		return nil
+	}
+	appEnv, ok := ProductionEnvironment()
+	if !ok {
+		return nil
+	}
+
+	if value := strings.TrimSpace(os.Getenv("DB_ALLOW_INSECURE")); value != "" {
+		allow, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid DB_ALLOW_INSECURE: %q is not a boolean", value) // boolean: 真偽値
+		}
+		if allow {
+			log.Printf("Warning: sslmode=%s is allowed in %s because DB_ALLOW_INSECURE=true", sslMode, appEnv)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("sslmode=%s is not allowed when APP_ENV=%s because the connection may be unencrypted; "+
+		"use require, verify-ca or verify-full, or set DB_ALLOW_INSECURE=true to override", sslMode, appEnv) // override: 上書きする
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"strings" // strings: 文字列操作
+	"testing" // testing: テスト機能
+)
+
+// TestProductionSSLGuard tests every environment, sslmode and override combination
+// TestProductionSSLGuard: 環境、sslmode、上書き設定の各組み合わせをテストする関数
+func TestProductionSSLGuard(t *testing.T) {
+	environments := []struct {
+		appEnv         string
+		productionEnvs string // production envs: DB_PRODUCTION_ENVSの値
+		production     bool
+	}{
+		{appEnv: "", production: false},
+		{appEnv: "development", production: false},
+		{appEnv: "test", production: false},
+		{appEnv: "production", production: true},
+		{appEnv: "Production", production: true},
+		{appEnv: "staging", production: false},
+		{appEnv: "staging", productionEnvs: "production, staging", production: true},
+		{appEnv: "production", productionEnvs: "prod", production: false},
+	}
+	overrides := []struct {
+		value  string // value: DB_ALLOW_INSECUREの値
+		allows bool
+	}{
+		{value: "", allows: false},
+		{value: "false", allows: false},
+		{value: "true", allows: true},
+		{value: "1", allows: true},
+	}
+
+	for _, env := range environments {
+		for _, sslMode := range validSSLModes {
+			for _, override := range overrides {
+				name := "APP_ENV=" + env.appEnv + "/envs=" + env.productionEnvs + "/sslmode=" + sslMode + "/override=" + override.value
+				t.Run(name, func(t *testing.T) {
+					t.Setenv("APP_ENV", env.appEnv)
+					t.Setenv("DB_PRODUCTION_ENVS", env.productionEnvs)
+					t.Setenv("DB_ALLOW_INSECURE", override.value)
+					captureLog(t)
+
+					insecure := sslMode == "disable" || sslMode == "allow" || sslMode == "prefer"
+					expectError := env.production && insecure && !override.allows
+
+					config := &DatabaseConfig{Host: "localhost", Port: 5432, User: "user", Password: "password", Database: "db", SSLMode: sslMode}
+					err := validateDatabaseConfig(config)
+					if expectError {
+						if err == nil || !strings.Contains(err.Error(), "DB_ALLOW_INSECURE=true") {
+							t.Errorf("Expected an error explaining the override, got: %v", err)
+						}
+					} else if err != nil {
+						t.Errorf("Expected no error, got: %v", err)
+					}
+				})
+			}
+		}
+	}
+}
+
+// TestProductionSSLGuardOnLoad tests that LoadDatabaseConfig refuses an insecure sslmode in production
+// TestProductionSSLGuardOnLoad: LoadDatabaseConfigが本番環境で安全でないsslmodeを拒否することをテストする関数
+func TestProductionSSLGuardOnLoad(t *testing.T) {
+	testCases := []struct {
+		name         string
+		env          map[string]string
+		expectError  bool
+		errorContent string
+	}{
+		{name: "Production with disable", env: map[string]string{"APP_ENV": "production", "DB_SSL_MODE": "disable"}, expectError: true, errorContent: "sslmode=disable is not allowed when APP_ENV=production"},
+		{name: "Production with the default", env: map[string]string{"APP_ENV": "production"}},
+		{name: "Production with an override", env: map[string]string{"APP_ENV": "production", "DB_SSL_MODE": "disable", "DB_ALLOW_INSECURE": "true"}},
+		{name: "Invalid override", env: map[string]string{"APP_ENV": "production", "DB_SSL_MODE": "prefer", "DB_ALLOW_INSECURE": "yes please"}, expectError: true, errorContent: "invalid DB_ALLOW_INSECURE"},
+		{name: "Development with disable", env: map[string]string{"APP_ENV": "development", "DB_SSL_MODE": "disable"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, key := range []string{"APP_ENV", "DB_SSL_MODE", "DB_ALLOW_INSECURE", "DB_PRODUCTION_ENVS"} {
+				t.Setenv(key, tc.env[key])
+			}
+			t.Setenv("DB_USER", "user")
+			t.Setenv("DB_PASSWORD", "password")
+			t.Setenv("DB_NAME", "db")
+			captureLog(t)
+
+			_, err := LoadDatabaseConfig()
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
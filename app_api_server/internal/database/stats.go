@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"     // context: コンテキスト、処理の文脈情報
+	"fmt"         // fmt: format（フォーマット）、文字列フォーマット機能
+	"sync/atomic" // atomic: アトミック操作
+	"time"        // time: 時間操作機能
+)
+
+// ConnectionStats represents JSON-serializable connection pool statistics
+// ConnectionStats: JSONシリアライズ可能な接続プール統計を表す構造体
+// serializable: シリアライズ可能な
+type ConnectionStats struct {
+	// Fields mirrored from sql.DBStats
+	// sql.DBStatsから写したフィールド
+	MaxOpenConnections int           `json:"max_open_connections"` // maximum open: 最大接続数
+	OpenConnections    int           `json:"open_connections"`     // open: 開いている接続数
+	InUse              int           `json:"in_use"`               // in use: 使用中
+	Idle               int           `json:"idle"`                 // idle: アイドル
+	WaitCount          int64         `json:"wait_count"`           // wait count: 待機回数
+	WaitDuration       time.Duration `json:"wait_duration_ns"`     // wait duration: 待機時間の合計（ナノ秒）
+	MaxIdleClosed      int64         `json:"max_idle_closed"`      // closed by max idle: アイドル上限で閉じた数
+	MaxIdleTimeClosed  int64         `json:"max_idle_time_closed"` // closed by idle time: アイドル時間で閉じた数
+	MaxLifetimeClosed  int64         `json:"max_lifetime_closed"`  // closed by lifetime: 寿命で閉じた数
+
+	// Driver state and configuration
+	// ドライバーの状態と設定
+	Connected             bool          `json:"connected"`                   // connected: プールが開いているか
+	ConfiguredMaxOpen     int           `json:"configured_max_open"`         // configured: 設定された最大接続数
+	ConfiguredMaxIdle     int           `json:"configured_max_idle"`         // configured: 設定された最大アイドル数
+	ConfiguredMaxIdleTime time.Duration `json:"configured_max_idle_time_ns"` // configured: 設定された最大アイドル時間（ナノ秒、0は無制限）
+	ConfiguredMaxLifetime time.Duration `json:"configured_max_lifetime_ns"`  // configured: 設定された接続の寿命（ナノ秒）
+	LastConnectTime       time.Time     `json:"last_connect_time"`           // last connect: 最後の接続時刻
+	LastReconnectTime     time.Time     `json:"last_reconnect_time"`         // last reconnect: 最後の再接続時刻
+	TotalReconnects       int64         `json:"total_reconnects"`            // total reconnects: 再接続の累計
+	LastRotationTime      time.Time     `json:"last_rotation_time"`          // last rotation: 最後の認証情報ローテーション時刻
+	CircuitState          CircuitState  `json:"circuit_state"`               // circuit state: Reconnectのサーキットブレーカーの状態
+	ReconnectFailures     int           `json:"reconnect_failures"`          // reconnect failures: 連続した再接続失敗の回数
+	ReconnectRecoveries   int64         `json:"reconnect_recoveries"`        // reconnect recoveries: *WithReconnect系が再接続と再試行で回復した文の数
+	PoolExhaustions       int64         `json:"pool_exhaustions"`            // pool exhaustions: 取得タイムアウトでErrPoolExhaustedを返した回数
+	SampledAt             time.Time     `json:"sampled_at"`                  // sampled at: 統計を取得した時刻、StatsSinceのレート計算に使う
+}
+
+// GetConnectionStats returns database connection statistics
+// GetConnectionStats: データベース接続統計を返す関数
+// Safe to call before Connect: 接続前でも安全に呼び出せる
+func (d *PostgreSQLDriver) GetConnectionStats() ConnectionStats {
+	stats := ConnectionStats{
+		SampledAt: d.now(),
+	}
+
+	db := d.pool()
+	if db != nil {
+		dbStats := db.Stats()
+		stats.MaxOpenConnections = dbStats.MaxOpenConnections
+		stats.OpenConnections = dbStats.OpenConnections
+		stats.InUse = dbStats.InUse
+		stats.Idle = dbStats.Idle
+		stats.WaitCount = dbStats.WaitCount
+		stats.WaitDuration = dbStats.WaitDuration
+		stats.MaxIdleClosed = dbStats.MaxIdleClosed
+		stats.MaxIdleTimeClosed = dbStats.MaxIdleTimeClosed
+		stats.MaxLifetimeClosed = dbStats.MaxLifetimeClosed
+		stats.Connected = true
+	}
+
+	config := d.config.Load()
+	stats.ConfiguredMaxOpen = config.MaxOpenConns
+	stats.ConfiguredMaxIdle = config.MaxIdleConns
+	stats.ConfiguredMaxIdleTime = config.ConnMaxIdleTime
+	stats.ConfiguredMaxLifetime = config.ConnMaxLifetime
+	d.mu.Lock()
+	stats.LastConnectTime = d.lastConnectTime
+	stats.LastReconnectTime = d.lastReconnectTime
+	stats.TotalReconnects = d.totalReconnects
+	stats.LastRotationTime = d.lastRotationTime
+	stats.CircuitState = d.breaker.state
+	stats.ReconnectFailures = d.breaker.failures
+	d.mu.Unlock()
+	stats.ReconnectRecoveries = atomic.LoadInt64(&d.reconnectRecoveries)
+	stats.PoolExhaustions = atomic.LoadInt64(&d.poolExhaustions)
+
+	return stats
+}
+
+// poolSnapshot holds the pool figures written by the stats logger
+// poolSnapshot: 統計ロガーが出力するプールの数値を保持する構造体
+// figures: 数値（複数形）
+type poolSnapshot struct {
+	connected    bool          // connected: 接続済み
+	open         int           // open: 開いている接続数
+	inUse        int           // in use: 使用中
+	idle         int           // idle: アイドル
+	waitCount    int64         // wait count: 待機回数
+	waitDuration time.Duration // wait duration: 待機時間
+}
+
+// snapshotOf extracts the logged figures from ConnectionStats
+// snapshotOf: ConnectionStatsからログ出力用の数値を取り出す関数
+// extracts: 取り出す
+func snapshotOf(stats ConnectionStats) poolSnapshot {
+	return poolSnapshot{
+		connected:    stats.Connected,
+		open:         stats.OpenConnections,
+		inUse:        stats.InUse,
+		idle:         stats.Idle,
+		waitCount:    stats.WaitCount,
+		waitDuration: stats.WaitDuration,
+	}
+}
+
+// String formats the snapshot as a single log line
+// String: スナップショットを1行のログ文字列に整形する関数
+func (s poolSnapshot) String() string {
+	if !s.connected {
+		return "Database pool stats: not connected"
+	}
+	return fmt.Sprintf("Database pool stats: open=%d in_use=%d idle=%d wait_count=%d wait_duration=%s",
+		s.open, s.inUse, s.idle, s.waitCount, s.waitDuration)
+}
+
+// StartStatsLogger periodically logs pool statistics until ctx is cancelled or the driver is closed
+// StartStatsLogger: ctxのキャンセルまたはドライバーのCloseまで定期的にプール統計をログ出力する関数
+// periodically: 定期的に、cancelled: キャンセルされた
+// Identical consecutive snapshots are skipped; the returned channel is closed when logging stops
+// 連続する同一のスナップショットは省略し、停止時に戻り値のチャネルを閉じる
+// Each tick also runs the pool pressure check and records the heartbeat checked by Liveness
+// 各周期でプール逼迫チェックも実行し、Livenessが確認する生存信号を記録する
+func (d *PostgreSQLDriver) StartStatsLogger(ctx context.Context, interval time.Duration) <-chan struct{} {
+	stopped := make(chan struct{}) // stopped: 停止済み通知
+	closing := d.closeSignal()
+
+	if interval <= 0 {
+		d.logger.Warn(fmt.Sprintf("stats logger not started, interval must be positive: %s", interval), "interval", interval) // positive: 正の
+		close(stopped)
+		return stopped
+	}
+
+	generation := d.startWatchdog(interval)
+	go func() {
+		defer close(stopped)
+		defer d.stopWatchdog(generation)
+
+		ticker := time.NewTicker(interval) // ticker: 定期的なタイマー
+		defer ticker.Stop()
+
+		var previous *poolSnapshot // previous: 前回のスナップショット
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-closing:
+				return
+			case <-ticker.C:
+				// select picks randomly among ready cases, so re-check closing before logging
+				// selectは準備済みのケースから無作為に選ぶため、ログ出力前に停止通知を再確認する
+				select {
+				case <-closing:
+					return
+				default:
+				}
+				previous = d.logStatsIfChanged(previous)
+				d.checkPoolPressure()
+				d.beatWatchdog(generation) // heartbeat for Liveness: Liveness用の生存信号
+			}
+		}
+	}()
+
+	return stopped
+}
+
+// logStatsIfChanged logs the current snapshot unless it equals previous, and returns the current one
+// logStatsIfChanged: 前回と異なる場合のみ現在のスナップショットをログ出力し、現在値を返す関数
+func (d *PostgreSQLDriver) logStatsIfChanged(previous *poolSnapshot) *poolSnapshot {
+	current := snapshotOf(d.GetConnectionStats())
+	if previous != nil && *previous == current {
+		return previous // identical: 同一のため省略
+	}
+
+	d.logger.Info(current.String())
+	return &current
+}
@@ -0,0 +1,193 @@
+package database
+
+import (
+	"context"       // context: コンテキスト
+	"database/sql"  // sql: データベース操作用パッケージ
+	"encoding/json" // json: JSONエンコード
+	"sort"          // sort: ソート
+	"strings"       // strings: 文字列操作
+	"testing"       // testing: テスト機能
+	"time"          // time: 時間操作機能
+)
+
+// newFakeConnectingDriver creates a driver whose Connect opens fake pools
+// newFakeConnectingDriver: Connectでフェイクプールを開くドライバーを作成するテスト用関数
+func newFakeConnectingDriver(t *testing.T) *PostgreSQLDriver {
+	t.Helper()
+
+	driver, err := NewPostgreSQLDriverWithConfig(&DatabaseConfig{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "testuser",
+		Password: "testpass",
+		Database: "testdb",
+		SSLMode:  "disable",
+	}, WithResolver(fakeResolver{}))
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+	driver.openDB = func(string) (*sql.DB, error) {
+		_, db := newFakeDB()
+		return db, nil
+	}
+	return driver
+}
+
+// TestConnectionStatsJSONShape tests the JSON field names of ConnectionStats
+// TestConnectionStatsJSONShape: ConnectionStatsのJSONフィールド名をテストする関数
+func TestConnectionStatsJSONShape(t *testing.T) {
+	driver := newFakeConnectingDriver(t)
+
+	encoded, err := json.Marshal(driver.GetConnectionStats())
+	if err != nil {
+		t.Fatalf("Expected stats to marshal, got: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Expected valid JSON, got: %v", err)
+	}
+
+	expected := []string{
+		"circuit_state", "configured_max_idle", "configured_max_idle_time_ns", "configured_max_lifetime_ns", "configured_max_open", "connected", "idle", "in_use",
+		"last_connect_time", "last_reconnect_time", "last_rotation_time", "max_idle_closed", "max_idle_time_closed",
+		"max_lifetime_closed", "max_open_connections", "open_connections", "pool_exhaustions", "reconnect_failures", "reconnect_recoveries",
+		"sampled_at", "total_reconnects",
+		"wait_count", "wait_duration_ns",
+	}
+	var keys []string
+	for key := range decoded {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if strings.Join(keys, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected JSON keys %v, got: %v", expected, keys)
+	}
+}
+
+// TestConnectionStatsReconnectCounters tests counter updates across Connect and Reconnect
+// TestConnectionStatsReconnectCounters: ConnectとReconnectでのカウンター更新をテストする関数
+func TestConnectionStatsReconnectCounters(t *testing.T) {
+	driver := newFakeConnectingDriver(t)
+	driver.now = fakeClock(time.Second)
+
+	stats := driver.GetConnectionStats()
+	if stats.Connected || stats.TotalReconnects != 0 || !stats.LastConnectTime.IsZero() {
+		t.Errorf("Expected empty stats before Connect, got: %+v", stats)
+	}
+	if stats.ConfiguredMaxOpen != defaultMaxOpenConns || stats.ConfiguredMaxIdle != defaultMaxIdleConns {
+		t.Errorf("Expected configured pool sizes before Connect, got: %+v", stats)
+	}
+	if stats.ConfiguredMaxIdleTime != 0 {
+		t.Errorf("Expected unlimited idle time by default, got: %s", stats.ConfiguredMaxIdleTime)
+	}
+
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected no error on Connect, got: %v", err)
+	}
+	defer driver.Close()
+
+	stats = driver.GetConnectionStats()
+	if !stats.Connected || stats.LastConnectTime.IsZero() {
+		t.Errorf("Expected connected stats after Connect, got: %+v", stats)
+	}
+	if stats.MaxOpenConnections != defaultMaxOpenConns {
+		t.Errorf("Expected MaxOpenConnections %d, got: %d", defaultMaxOpenConns, stats.MaxOpenConnections)
+	}
+	if stats.TotalReconnects != 0 || !stats.LastReconnectTime.IsZero() {
+		t.Errorf("Expected no reconnects after Connect, got: %+v", stats)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := driver.Reconnect(); err != nil {
+			t.Fatalf("Expected no error on Reconnect, got: %v", err)
+		}
+	}
+
+	stats = driver.GetConnectionStats()
+	if stats.TotalReconnects != 2 {
+		t.Errorf("Expected 2 reconnects, got: %d", stats.TotalReconnects)
+	}
+	if !stats.LastReconnectTime.Equal(stats.LastConnectTime) {
+		t.Errorf("Expected last reconnect time %v to equal last connect time %v", stats.LastReconnectTime, stats.LastConnectTime)
+	}
+}
+
+// waitStopped waits for a stats logger to stop or fails the test
+// waitStopped: 統計ロガーの停止を待ち、停止しなければテストを失敗させる関数
+func waitStopped(t *testing.T, stopped <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Expected stats logger to stop within 1s")
+	}
+}
+
+// TestStatsLoggerNotConnected tests that the logger is safe before Connect and skips repeats
+// TestStatsLoggerNotConnected: 接続前でも安全で、同一出力を省略することをテストする関数
+func TestStatsLoggerNotConnected(t *testing.T) {
+	logs := captureLog(t)
+	driver := newFakeConnectingDriver(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := driver.StartStatsLogger(ctx, 2*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	cancel()
+	waitStopped(t, stopped)
+
+	if count := strings.Count(logs.String(), "not connected"); count != 1 {
+		t.Errorf("Expected 'not connected' to be logged once, got %d times: %q", count, logs.String())
+	}
+}
+
+// TestStatsLoggerStopsOnClose tests that Close stops a running logger
+// TestStatsLoggerStopsOnClose: Closeで実行中のロガーが停止することをテストする関数
+func TestStatsLoggerStopsOnClose(t *testing.T) {
+	logs := captureLog(t)
+	driver := newFakeConnectingDriver(t)
+	if err := driver.Connect(); err != nil {
+		t.Fatalf("Expected no error on Connect, got: %v", err)
+	}
+
+	stopped := driver.StartStatsLogger(context.Background(), 2*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	driver.Close()
+	waitStopped(t, stopped)
+
+	if count := strings.Count(logs.String(), "Database pool stats: open="); count != 1 {
+		t.Errorf("Expected one stats line for an unchanged pool, got %d: %q", count, logs.String())
+	}
+}
+
+// TestStatsLoggerInvalidInterval tests that a non-positive interval does not start the logger
+// TestStatsLoggerInvalidInterval: 0以下の間隔ではロガーが開始されないことをテストする関数
+func TestStatsLoggerInvalidInterval(t *testing.T) {
+	captureLog(t)
+	driver := newFakeConnectingDriver(t)
+
+	waitStopped(t, driver.StartStatsLogger(context.Background(), 0))
+}
+
+// TestLogStatsIfChanged tests that only changed snapshots are logged
+// TestLogStatsIfChanged: 変化したスナップショットのみがログ出力されることをテストする関数
+func TestLogStatsIfChanged(t *testing.T) {
+	logs := captureLog(t)
+	driver, _ := newTestDriver(t)
+
+	previous := driver.logStatsIfChanged(nil)
+	previous = driver.logStatsIfChanged(previous)
+	if count := strings.Count(logs.String(), "open=0"); count != 1 {
+		t.Errorf("Expected identical snapshot to be skipped, got %d lines: %q", count, logs.String())
+	}
+
+	// Opening a connection changes the snapshot
+	// opening: 開く、changes: 変える
+	driver.IsConnected()
+	driver.logStatsIfChanged(previous)
+	if !strings.Contains(logs.String(), "open=1") {
+		t.Errorf("Expected changed snapshot to be logged, got: %q", logs.String())
+	}
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"time" // time: 時間操作機能
+)
+
+// StatsDelta is the growth of the cumulative counters between two ConnectionStats, with per-second rates
+// StatsDelta: 2つのConnectionStats間の累積カウンターの増加分と毎秒のレート
+// cumulative: 累積の、growth: 増加、rate: レート、単位時間あたりの量
+// After a counter reset the pool counters hold the new absolute values and Reset is set
+// カウンターがリセットされた場合、プールのカウンターは新しい絶対値となりResetが設定される
+type StatsDelta struct {
+	Elapsed           time.Duration `json:"elapsed_ns"`           // elapsed: 経過時間（ナノ秒）
+	WaitCount         int64         `json:"wait_count"`           // wait count: 待機回数の増加
+	WaitDuration      time.Duration `json:"wait_duration_ns"`     // wait duration: 待機時間の増加（ナノ秒）
+	MaxIdleClosed     int64         `json:"max_idle_closed"`      // closed by max idle: アイドル上限で閉じた数の増加
+	MaxIdleTimeClosed int64         `json:"max_idle_time_closed"` // closed by idle time: アイドル時間で閉じた数の増加
+	MaxLifetimeClosed int64         `json:"max_lifetime_closed"`  // closed by lifetime: 寿命で閉じた数の増加
+	Reconnects        int64         `json:"reconnects"`           // reconnects: 再接続の増加
+	Reset             bool          `json:"reset"`                // reset: プールが入れ替わりカウンターが0から再開した
+
+	// Rates over Elapsed, zero when Elapsed is unknown
+	// Elapsedあたりのレート、Elapsedが不明な場合はゼロ
+	WaitCountPerSecond         float64 `json:"wait_count_per_second"`           // 毎秒の待機回数
+	WaitSecondsPerSecond       float64 `json:"wait_seconds_per_second"`         // 毎秒の待機時間（秒）、1は常に1接続分待っている状態
+	MaxIdleClosedPerSecond     float64 `json:"max_idle_closed_per_second"`      // 毎秒アイドル上限で閉じた数
+	MaxIdleTimeClosedPerSecond float64 `json:"max_idle_time_closed_per_second"` // 毎秒アイドル時間で閉じた数
+	MaxLifetimeClosedPerSecond float64 `json:"max_lifetime_closed_per_second"`  // 毎秒寿命で閉じた数
+	ReconnectsPerSecond        float64 `json:"reconnects_per_second"`           // 毎秒の再接続数
+}
+
+// StatsSince returns the counter growth and rates from prev, taken earlier by GetConnectionStats, to now
+// StatsSince: 以前にGetConnectionStatsで取得したprevから現在までのカウンターの増加分とレートを返す関数
+func (d *PostgreSQLDriver) StatsSince(prev ConnectionStats) StatsDelta {
+	return ComputeStatsDelta(prev, d.GetConnectionStats())
+}
+
+// ComputeStatsDelta returns the counter growth and rates between two snapshots, using their SampledAt times
+// ComputeStatsDelta: 2つのスナップショット間のカウンターの増加分とレートをSampledAtの時刻から計算する関数
+// It is a pure function, so the stats logger and metrics collectors can keep their own previous snapshot
+// 純粋関数のため、統計ロガーやメトリクスコレクターがそれぞれ前回のスナップショットを保持して使える
+func ComputeStatsDelta(prev, current ConnectionStats) StatsDelta {
+	var elapsed time.Duration
+	if !prev.SampledAt.IsZero() && !current.SampledAt.IsZero() {
+		elapsed = current.SampledAt.Sub(prev.SampledAt)
+	}
+	return diffStats(prev, current, elapsed)
+}
+
+// diffStats computes the delta between two snapshots taken elapsed apart
+// diffStats: elapsedの間隔で取得された2つのスナップショット間の増加分を計算する関数
+func diffStats(prev, current ConnectionStats, elapsed time.Duration) StatsDelta {
+	// A Reconnect or a credential rotation opens a new pool whose counters start from zero,
+	// even when they have already grown past the old values
+	// Reconnectや認証情報のローテーションは新しいプールを開き、カウンターは0から再開する
+	// （既に古い値を超えている場合も含む）
+	reset := current.TotalReconnects != prev.TotalReconnects ||
+		!current.LastRotationTime.Equal(prev.LastRotationTime) ||
+		current.WaitCount < prev.WaitCount ||
+		current.WaitDuration < prev.WaitDuration ||
+		current.MaxIdleClosed < prev.MaxIdleClosed ||
+		current.MaxIdleTimeClosed < prev.MaxIdleTimeClosed ||
+		current.MaxLifetimeClosed < prev.MaxLifetimeClosed
+
+	poolDelta := func(previous, current int64) int64 {
+		if reset {
+			return current
+		}
+		return current - previous
+	}
+
+	delta := StatsDelta{
+		Elapsed:           elapsed,
+		WaitCount:         poolDelta(prev.WaitCount, current.WaitCount),
+		WaitDuration:      time.Duration(poolDelta(int64(prev.WaitDuration), int64(current.WaitDuration))),
+		MaxIdleClosed:     poolDelta(prev.MaxIdleClosed, current.MaxIdleClosed),
+		MaxIdleTimeClosed: poolDelta(prev.MaxIdleTimeClosed, current.MaxIdleTimeClosed),
+		MaxLifetimeClosed: poolDelta(prev.MaxLifetimeClosed, current.MaxLifetimeClosed),
+		Reconnects:        counterDelta(prev.TotalReconnects, current.TotalReconnects),
+		Reset:             reset,
+	}
+
+	if elapsed > 0 {
+		seconds := elapsed.Seconds()
+		delta.WaitCountPerSecond = float64(delta.WaitCount) / seconds
+		delta.WaitSecondsPerSecond = delta.WaitDuration.Seconds() / seconds
+		delta.MaxIdleClosedPerSecond = float64(delta.MaxIdleClosed) / seconds
+		delta.MaxIdleTimeClosedPerSecond = float64(delta.MaxIdleTimeClosed) / seconds
+		delta.MaxLifetimeClosedPerSecond = float64(delta.MaxLifetimeClosed) / seconds
+		delta.ReconnectsPerSecond = float64(delta.Reconnects) / seconds
+	}
+	return delta
+}
+
+// counterDelta returns the growth of a cumulative counter
+// counterDelta: 累積カウンターの増加分を返す関数
+// A smaller value means the counter restarted from zero: 値が小さくなった場合はカウンターが0から再開したとみなす
+func counterDelta(previous, current int64) int64 {
+	if current < previous {
+		return current
+	}
+	return current - previous
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"testing" // testing: テスト機能
+	"time"    // time: 時間操作機能
+)
+
+// TestComputeStatsDelta tests differences, rates and counter resets between two snapshots
+// TestComputeStatsDelta: 2つのスナップショット間の差分、レート、カウンターのリセットをテストする関数
+func TestComputeStatsDelta(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rotated := start.Add(-time.Hour)
+	prev := ConnectionStats{
+		SampledAt:         start,
+		WaitCount:         100,
+		WaitDuration:      2 * time.Second,
+		MaxIdleClosed:     5,
+		MaxIdleTimeClosed: 7,
+		MaxLifetimeClosed: 40,
+		TotalReconnects:   2,
+		LastRotationTime:  rotated,
+	}
+
+	testCases := []struct {
+		name     string
+		current  ConnectionStats
+		expected StatsDelta
+	}{
+		{
+			name: "Counters grow",
+			current: ConnectionStats{
+				SampledAt: start.Add(10 * time.Second), WaitCount: 150, WaitDuration: 7 * time.Second,
+				MaxIdleClosed: 5, MaxIdleTimeClosed: 17, MaxLifetimeClosed: 60, TotalReconnects: 2, LastRotationTime: rotated,
+			},
+			expected: StatsDelta{
+				Elapsed: 10 * time.Second, WaitCount: 50, WaitDuration: 5 * time.Second, MaxIdleTimeClosed: 10, MaxLifetimeClosed: 20,
+				WaitCountPerSecond: 5, WaitSecondsPerSecond: 0.5, MaxIdleTimeClosedPerSecond: 1, MaxLifetimeClosedPerSecond: 2,
+			},
+		},
+		{
+			name: "Counter went backwards",
+			current: ConnectionStats{
+				SampledAt: start.Add(10 * time.Second), WaitCount: 30, WaitDuration: 3 * time.Second,
+				MaxIdleClosed: 5, MaxIdleTimeClosed: 7, MaxLifetimeClosed: 40, TotalReconnects: 2, LastRotationTime: rotated,
+			},
+			expected: StatsDelta{
+				Elapsed: 10 * time.Second, WaitCount: 30, WaitDuration: 3 * time.Second, MaxIdleClosed: 5, MaxIdleTimeClosed: 7,
+				MaxLifetimeClosed: 40, Reset: true, WaitCountPerSecond: 3, WaitSecondsPerSecond: 0.3,
+				MaxIdleClosedPerSecond: 0.5, MaxIdleTimeClosedPerSecond: 0.7, MaxLifetimeClosedPerSecond: 4,
+			},
+		},
+		{
+			name: "Reconnect resets counters that already grew past the old values",
+			current: ConnectionStats{
+				SampledAt: start.Add(5 * time.Second), WaitCount: 120, WaitDuration: 2 * time.Second,
+				MaxIdleClosed: 5, MaxIdleTimeClosed: 7, MaxLifetimeClosed: 40, TotalReconnects: 3, LastRotationTime: rotated,
+			},
+			expected: StatsDelta{
+				Elapsed: 5 * time.Second, WaitCount: 120, WaitDuration: 2 * time.Second, MaxIdleClosed: 5, MaxIdleTimeClosed: 7,
+				MaxLifetimeClosed: 40, Reconnects: 1, Reset: true, WaitCountPerSecond: 24, WaitSecondsPerSecond: 0.4,
+				MaxIdleClosedPerSecond: 1, MaxIdleTimeClosedPerSecond: 1.4, MaxLifetimeClosedPerSecond: 8, ReconnectsPerSecond: 0.2,
+			},
+		},
+		{
+			name: "Credential rotation resets counters",
+			current: ConnectionStats{
+				SampledAt: start.Add(time.Second), WaitCount: 100, WaitDuration: 2 * time.Second,
+				MaxIdleClosed: 5, MaxIdleTimeClosed: 7, MaxLifetimeClosed: 40, TotalReconnects: 2, LastRotationTime: start,
+			},
+			expected: StatsDelta{
+				Elapsed: time.Second, WaitCount: 100, WaitDuration: 2 * time.Second, MaxIdleClosed: 5, MaxIdleTimeClosed: 7,
+				MaxLifetimeClosed: 40, Reset: true, WaitCountPerSecond: 100, WaitSecondsPerSecond: 2,
+				MaxIdleClosedPerSecond: 5, MaxIdleTimeClosedPerSecond: 7, MaxLifetimeClosedPerSecond: 40,
+			},
+		},
+		{
+			name: "Unknown elapsed time has no rates",
+			current: ConnectionStats{
+				WaitCount: 110, WaitDuration: 2 * time.Second,
+				MaxIdleClosed: 5, MaxIdleTimeClosed: 7, MaxLifetimeClosed: 40, TotalReconnects: 2, LastRotationTime: rotated,
+			},
+			expected: StatsDelta{WaitCount: 10},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ComputeStatsDelta(prev, tc.current); got != tc.expected {
+				t.Errorf("Expected %+v, got: %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestStatsSince tests that StatsSince compares against the driver's current stats
+// TestStatsSince: StatsSinceがドライバーの現在の統計と比較することをテストする関数
+func TestStatsSince(t *testing.T) {
+	driver, _ := newTestDriver(t)
+	driver.now = fakeClock(2 * time.Second)
+
+	prev := driver.GetConnectionStats()
+	delta := driver.StatsSince(prev)
+	if delta.Elapsed != 2*time.Second {
+		t.Errorf("Expected an elapsed time of 2s, got: %s", delta.Elapsed)
+	}
+	if delta.Reset || delta.WaitCount != 0 {
+		t.Errorf("Expected no growth on an idle pool, got: %+v", delta)
+	}
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"fmt"  // fmt: format（フォーマット）、文字列フォーマット機能
+	"sync" // sync: 同期処理
+	"time" // time: 時間操作機能
+)
+
+// TimestampedStats is one sample of the stats history
+// TimestampedStats: 統計履歴の1件のサンプル
+// timestamped: タイムスタンプ付きの
+type TimestampedStats struct {
+	Time  time.Time       `json:"time"`  // time: サンプリング時刻
+	Stats ConnectionStats `json:"stats"` // stats: その時点の接続統計
+	Delta StatsDelta      `json:"delta"` // delta: 直前のサンプルからの増加分
+}
+
+// statsHistory is the ring buffer filled by the stats sampler
+// statsHistory: 統計サンプラーが書き込むリングバッファ
+// ring buffer: 固定長の循環バッファ
+type statsHistory struct {
+	mu      sync.Mutex             // mu: mutex（相互排他ロック）、以下を保護
+	samples []TimestampedStats     // samples: 固定長のバッファ、容量を超えると古いものから上書きする
+	next    int                    // next: 次に書き込む位置
+	count   int                    // count: 保持しているサンプル数
+	stop    chan struct{}          // stop: 動作中のサンプラーを止めるチャネル、nilならサンプラーなし
+	source  func() ConnectionStats // source: 統計の取得元（テストで差し替え可能、nilならGetConnectionStats）
+}
+
+// EnableStatsHistory samples ConnectionStats every interval into a ring buffer of capacity samples
+// EnableStatsHistory: interval毎にConnectionStatsをサンプリングし、capacity件のリングバッファに保持する関数
+// Calling it again restarts sampling with an empty buffer; sampling stops on Close
+// 再度呼び出すと空のバッファでサンプリングをやり直し、Closeでサンプリングを停止する
+func (d *PostgreSQLDriver) EnableStatsHistory(interval time.Duration, capacity int) error {
+	if interval <= 0 {
+		return fmt.Errorf("stats history interval must be positive: %s", interval)
+	}
+	if capacity <= 0 {
+		return fmt.Errorf("stats history capacity must be positive: %d", capacity)
+	}
+
+	closing := d.closeSignal()
+	h := &d.statsHistory
+	h.mu.Lock()
+	if h.stop != nil {
+		close(h.stop)
+	}
+	stop := make(chan struct{})
+	h.stop = stop
+	h.samples = make([]TimestampedStats, capacity)
+	h.next = 0
+	h.count = 0
+	h.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval) // ticker: 定期的なタイマー
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-closing:
+				return
+			case <-ticker.C:
+				d.recordStatsSample(stop)
+			}
+		}
+	}()
+	return nil
+}
+
+// recordStatsSample appends the current stats unless the sampler was replaced
+// recordStatsSample: サンプラーが入れ替えられていなければ現在の統計を追加する関数
+func (d *PostgreSQLDriver) recordStatsSample(stop chan struct{}) {
+	h := &d.statsHistory
+	h.mu.Lock()
+	source := h.source
+	h.mu.Unlock()
+	if source == nil {
+		source = d.GetConnectionStats
+	}
+
+	// Read the stats outside the lock: ロックの外で統計を取得する
+	sample := TimestampedStats{Time: d.now(), Stats: source()}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.stop != stop {
+		return // replaced or restarted: 入れ替えまたは再開済み
+	}
+	if h.count > 0 {
+		previous := h.samples[(h.next-1+len(h.samples))%len(h.samples)]
+		sample.Delta = statsDelta(previous, sample)
+	}
+	h.samples[h.next] = sample
+	h.next = (h.next + 1) % len(h.samples)
+	if h.count < len(h.samples) {
+		h.count++
+	}
+}
+
+// statsDelta computes the counter growth between two samples
+// statsDelta: 2つのサンプル間のカウンターの増加分を計算する関数
+func statsDelta(previous, current TimestampedStats) StatsDelta {
+	return diffStats(previous.Stats, current.Stats, current.Time.Sub(previous.Time))
+}
+
+// GetStatsHistory returns the recorded samples, oldest first
+// GetStatsHistory: 記録されたサンプルを古い順に返す関数
+// oldest first: 古い順
+func (d *PostgreSQLDriver) GetStatsHistory() []TimestampedStats {
+	h := &d.statsHistory
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	history := make([]TimestampedStats, 0, h.count)
+	if h.count == 0 {
+		return history
+	}
+	start := (h.next - h.count + len(h.samples)) % len(h.samples)
+	for i := 0; i < h.count; i++ {
+		history = append(history, h.samples[(start+i)%len(h.samples)])
+	}
+	return history
+}
+
+// GetStatsRange returns the recorded samples taken between from and to, inclusive
+// GetStatsRange: fromからtoまで（両端を含む）に取得されたサンプルを古い順に返す関数
+// inclusive: 両端を含む
+func (d *PostgreSQLDriver) GetStatsRange(from, to time.Time) []TimestampedStats {
+	var samples []TimestampedStats
+	for _, sample := range d.GetStatsHistory() {
+		if sample.Time.Before(from) || sample.Time.After(to) {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	return samples
+}
@@ -0,0 +1,175 @@
+package database
+
+import (
+	"sync/atomic" // atomic: アトミック操作
+	"testing"     // testing: テスト機能
+	"time"        // time: 時間操作機能
+)
+
+// newHistoryTestDriver enables a stats history that only samples when the test calls sample
+// newHistoryTestDriver: テストがsampleを呼んだときだけサンプリングする統計履歴を有効にする関数
+func newHistoryTestDriver(t *testing.T, capacity int, source func() ConnectionStats) (*PostgreSQLDriver, func()) {
+	t.Helper()
+
+	driver, _ := newTestDriver(t)
+	driver.now = fakeClock(time.Second)
+	driver.statsHistory.source = source
+	// An hour-long interval never ticks during the test: 1時間の間隔はテスト中にティックしない
+	if err := driver.EnableStatsHistory(time.Hour, capacity); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	t.Cleanup(func() { driver.Close() })
+
+	sample := func() { driver.recordStatsSample(driver.statsHistory.stop) }
+	return driver, sample
+}
+
+// TestStatsHistoryRingBuffer tests that the history keeps the newest samples up to capacity
+// TestStatsHistoryRingBuffer: 履歴が容量までの最新サンプルを保持することをテストする関数
+func TestStatsHistoryRingBuffer(t *testing.T) {
+	var waits int64
+	driver, sample := newHistoryTestDriver(t, 3, func() ConnectionStats {
+		waits += 2
+		return ConnectionStats{WaitCount: waits, WaitDuration: time.Duration(waits) * time.Millisecond}
+	})
+
+	if history := driver.GetStatsHistory(); len(history) != 0 {
+		t.Errorf("Expected empty history before sampling, got: %d samples", len(history))
+	}
+
+	for i := 0; i < 5; i++ {
+		sample()
+	}
+
+	history := driver.GetStatsHistory()
+	if len(history) != 3 {
+		t.Fatalf("Expected history bounded to 3 samples, got: %d", len(history))
+	}
+	for i, entry := range history {
+		if expected := int64(6 + 2*i); entry.Stats.WaitCount != expected {
+			t.Errorf("Expected sample %d to have wait count %d, got: %d", i, expected, entry.Stats.WaitCount)
+		}
+		if entry.Delta.WaitCount != 2 || entry.Delta.WaitDuration != 2*time.Millisecond {
+			t.Errorf("Expected a delta of 2 waits and 2ms, got: %+v", entry.Delta)
+		}
+		if entry.Delta.Elapsed != time.Second {
+			t.Errorf("Expected an elapsed time of 1s, got: %s", entry.Delta.Elapsed)
+		}
+		if i > 0 && !entry.Time.After(history[i-1].Time) {
+			t.Errorf("Expected samples oldest first, got: %v", history)
+		}
+	}
+}
+
+// TestStatsHistoryDelta tests the first sample and a counter that restarted with a new pool
+// TestStatsHistoryDelta: 最初のサンプルと新しいプールで再開したカウンターの増加分をテストする関数
+func TestStatsHistoryDelta(t *testing.T) {
+	counts := []int64{10, 15, 3}
+	reconnects := []int64{0, 0, 1}
+	var calls int
+	driver, sample := newHistoryTestDriver(t, 10, func() ConnectionStats {
+		stats := ConnectionStats{WaitCount: counts[calls], TotalReconnects: reconnects[calls]}
+		calls++
+		return stats
+	})
+	for range counts {
+		sample()
+	}
+
+	history := driver.GetStatsHistory()
+	expected := []StatsDelta{
+		{},
+		{Elapsed: time.Second, WaitCount: 5, WaitCountPerSecond: 5},
+		{Elapsed: time.Second, WaitCount: 3, Reconnects: 1, Reset: true, WaitCountPerSecond: 3, ReconnectsPerSecond: 1},
+	}
+	for i := range expected {
+		if history[i].Delta != expected[i] {
+			t.Errorf("Expected delta %d to be %+v, got: %+v", i, expected[i], history[i].Delta)
+		}
+	}
+}
+
+// TestGetStatsRange tests that only samples within the range are returned
+// TestGetStatsRange: 範囲内のサンプルのみが返されることをテストする関数
+func TestGetStatsRange(t *testing.T) {
+	driver, sample := newHistoryTestDriver(t, 10, func() ConnectionStats { return ConnectionStats{} })
+	for i := 0; i < 5; i++ {
+		sample()
+	}
+
+	history := driver.GetStatsHistory()
+	samples := driver.GetStatsRange(history[1].Time, history[3].Time)
+	if len(samples) != 3 {
+		t.Fatalf("Expected 3 samples in the inclusive range, got: %d", len(samples))
+	}
+	if !samples[0].Time.Equal(history[1].Time) || !samples[2].Time.Equal(history[3].Time) {
+		t.Errorf("Expected samples 1 to 3, got: %v", samples)
+	}
+
+	future := history[4].Time.Add(time.Minute)
+	if samples := driver.GetStatsRange(future, future.Add(time.Minute)); len(samples) != 0 {
+		t.Errorf("Expected no samples after the last one, got: %d", len(samples))
+	}
+}
+
+// TestStatsHistorySamplingStopsOnClose tests that the ticker samples until Close
+// TestStatsHistorySamplingStopsOnClose: Closeまでティッカーがサンプリングすることをテストする関数
+func TestStatsHistorySamplingStopsOnClose(t *testing.T) {
+	driver, _ := newTestDriver(t)
+	var calls int64
+	driver.statsHistory.source = func() ConnectionStats {
+		return ConnectionStats{WaitCount: atomic.AddInt64(&calls, 1)}
+	}
+	if err := driver.EnableStatsHistory(2*time.Millisecond, 4); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt64(&calls) < 6 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if history := driver.GetStatsHistory(); len(history) != 4 {
+		t.Errorf("Expected a full history of 4 samples, got: %d", len(history))
+	}
+
+	driver.Close()
+	time.Sleep(10 * time.Millisecond) // let an in-flight sample finish: 実行中のサンプリングの完了を待つ
+	stoppedAt := atomic.LoadInt64(&calls)
+	time.Sleep(20 * time.Millisecond)
+	if got := atomic.LoadInt64(&calls); got != stoppedAt {
+		t.Errorf("Expected sampling to stop on Close, got %d more samples", got-stoppedAt)
+	}
+	if history := driver.GetStatsHistory(); len(history) != 4 {
+		t.Errorf("Expected the history to remain readable after Close, got: %d samples", len(history))
+	}
+}
+
+// TestEnableStatsHistoryValidation tests that non-positive arguments are rejected
+// TestEnableStatsHistoryValidation: 正でない引数が拒否されることをテストする関数
+func TestEnableStatsHistoryValidation(t *testing.T) {
+	testCases := []struct {
+		name        string
+		interval    time.Duration
+		capacity    int
+		expectError bool
+	}{
+		{name: "Valid", interval: time.Second, capacity: 60, expectError: false},
+		{name: "Zero interval", interval: 0, capacity: 60, expectError: true},
+		{name: "Negative capacity", interval: time.Second, capacity: -1, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, _ := newTestDriver(t)
+			defer driver.Close()
+
+			err := driver.EnableStatsHistory(tc.interval, tc.capacity)
+			if tc.expectError && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
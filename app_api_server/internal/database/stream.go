@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+)
+
+// streamCursorName names the server-side cursor opened by Stream; each stream has its own transaction, so one name is enough
+// streamCursorName: Streamが開くサーバー側カーソルの名前、ストリームごとに専用のトランザクションを使うため固定名でよい
+const streamCursorName = "sift_stream_cursor"
+
+// StreamOption configures Stream
+// StreamOption: Streamを設定するオプション
+type StreamOption func(*streamSettings)
+
+// streamSettings collects the options given to Stream
+// streamSettings: Streamに渡されたオプションを集める構造体
+type streamSettings struct {
+	batchSize int // batch size: カーソルから1回に取得する行数、0ならカーソルを使わない
+}
+
+// StreamBatchSize reads the result through a server-side cursor, fetching n rows at a time
+// StreamBatchSize: サーバー側カーソル経由で結果をn行ずつ取得するオプション
+// Memory stays flat for very large scans because only n rows are buffered at once
+// 一度にn行しか保持しないため、非常に大きな走査でもメモリ使用量が一定に保たれる
+func StreamBatchSize(n int) StreamOption {
+	return func(s *streamSettings) {
+		s.batchSize = n
+	}
+}
+
+// Stream executes query and calls fn for each row, stopping at the first error from fn or at context cancellation
+// Stream: クエリを実行して行ごとにfnを呼び出し、fnの最初のエラーまたはコンテキストの取り消しで停止する関数
+// The rows are always closed; fn must not keep rows after it returns
+// 行は必ず閉じられる、fnは戻った後にrowsを保持してはならない
+// With StreamBatchSize the query runs inside a transaction as DECLARE ... CURSOR and FETCH batches
+// StreamBatchSizeを指定するとクエリはトランザクション内でDECLARE ... CURSORとFETCHの繰り返しとして実行される
+func (d *PostgreSQLDriver) Stream(ctx context.Context, query string, args []interface{}, fn func(*sql.Rows) error, opts ...StreamOption) error {
+	var settings streamSettings
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	if settings.batchSize < 0 {
+		return fmt.Errorf("stream batch size must not be negative, got %d", settings.batchSize)
+	}
+
+	if settings.batchSize == 0 {
+		rows, err := d.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		_, err = streamRows(ctx, rows.Rows, fn)
+		return err
+	}
+
+	return d.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		return d.streamCursor(ctx, tx, query, args, settings.batchSize, fn)
+	})
+}
+
+// streamCursor declares a cursor for query and feeds fn batch by batch until the cursor is exhausted
+// streamCursor: クエリのカーソルを宣言し、カーソルが尽きるまでバッチごとにfnへ行を渡す関数
+// exhausted: 使い果たした
+func (d *PostgreSQLDriver) streamCursor(ctx context.Context, tx *sql.Tx, query string, args []interface{}, batchSize int, fn func(*sql.Rows) error) error {
+	declare := fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR %s", streamCursorName, query)
+	declareCtx := d.beforeStatement(ctx, OpExec, declare, args)
+	start := d.now()
+	_, err := tx.ExecContext(declareCtx, declare, args...)
+	d.afterStatement(declareCtx, OpExec, declare, d.now().Sub(start), err)
+	if err != nil {
+		return fmt.Errorf("failed to declare stream cursor: %w", err) // declare: 宣言する
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM %s", batchSize, streamCursorName)
+	for {
+		fetchCtx := d.beforeStatement(ctx, OpQuery, fetch, nil)
+		start := d.now()
+		rows, err := tx.QueryContext(fetchCtx, fetch)
+		d.afterStatement(fetchCtx, OpQuery, fetch, d.now().Sub(start), err)
+		if err != nil {
+			return fmt.Errorf("failed to fetch from stream cursor: %w", err) // fetch: 取得する
+		}
+		count, err := streamRows(ctx, rows, fn)
+		if err != nil {
+			return err
+		}
+		// A short batch means the cursor is exhausted: 取得数が指定より少なければカーソルは尽きている
+		if count < batchSize {
+			break
+		}
+	}
+
+	// Commit would close the cursor as well; closing it explicitly frees it first
+	// コミットでもカーソルは閉じられるが、明示的に閉じて先に解放する
+	if _, err := tx.ExecContext(ctx, "CLOSE "+streamCursorName); err != nil {
+		return fmt.Errorf("failed to close stream cursor: %w", err)
+	}
+	return nil
+}
+
+// streamRows calls fn for each row and closes rows, returning the number of rows visited
+// streamRows: 行ごとにfnを呼び出してrowsを閉じ、処理した行数を返す関数
+// visited: 処理した、訪れた
+func streamRows(ctx context.Context, rows *sql.Rows, fn func(*sql.Rows) error) (int, error) {
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+		count++
+		if err := fn(rows); err != nil {
+			return count, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+	// database/sql ends the iteration quietly when the context is canceled: コンテキストが取り消されるとdatabase/sqlは黙って反復を終えることがある
+	return count, ctx.Err()
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	"database/sql"                  // sql: データベース操作用パッケージ
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"strings"                       // strings: 文字列操作
+	"testing"                       // testing: テスト機能
+)
+
+// numberRows returns n single-column rows holding 1..n
+// numberRows: 1からnまでの値を持つ1カラムの行をn行返す関数
+func numberRows(n int) [][]sqldriver.Value {
+	values := make([][]sqldriver.Value, n)
+	for i := range values {
+		values[i] = []sqldriver.Value{int64(i + 1)}
+	}
+	return values
+}
+
+// serveCursor makes query answer each FETCH FORWARD with the next batch of values
+// serveCursor: FETCH FORWARDごとに次のバッチの値を返すquery関数を作る
+func serveCursor(values [][]sqldriver.Value, batchSize int) func(string, []sqldriver.NamedValue) (sqldriver.Rows, error) {
+	return func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		n := min(batchSize, len(values))
+		batch := values[:n]
+		values = values[n:]
+		return &fakeRows{columns: []string{"n"}, values: batch}, nil
+	}
+}
+
+// TestStream tests row iteration, error propagation and cancellation with and without a cursor
+// TestStream: カーソルの有無それぞれで行の反復、エラーの伝播、取り消しをテストする関数
+// propagation: 伝播
+func TestStream(t *testing.T) {
+	errStop := errors.New("stop here")
+
+	testCases := []struct {
+		name          string
+		batchSize     int
+		stopAt        int64 // stop at: fnがエラーを返す値、0なら返さない
+		cancelAt      int64 // cancel at: コンテキストを取り消す値、0なら取り消さない
+		expectedCalls int
+		expectedError error
+	}{
+		{name: "All rows", expectedCalls: 5},
+		{name: "Error from fn stops the stream", stopAt: 2, expectedCalls: 2, expectedError: errStop},
+		{name: "Cancellation stops the stream", cancelAt: 3, expectedCalls: 3, expectedError: context.Canceled},
+		{name: "All rows through a cursor", batchSize: 2, expectedCalls: 5},
+		{name: "Error from fn stops the cursor", batchSize: 2, stopAt: 3, expectedCalls: 3, expectedError: errStop},
+		{name: "Cancellation stops the cursor", batchSize: 2, cancelAt: 1, expectedCalls: 1, expectedError: context.Canceled},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, fake := newTestDriver(t)
+			if tc.batchSize > 0 {
+				fake.query = serveCursor(numberRows(5), tc.batchSize)
+			} else {
+				fake.query = func(string, []sqldriver.NamedValue) (sqldriver.Rows, error) {
+					return &fakeRows{columns: []string{"n"}, values: numberRows(5)}, nil
+				}
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			calls := 0
+			err := driver.Stream(ctx, "SELECT n FROM numbers", nil, func(rows *sql.Rows) error {
+				calls++
+				var n int64
+				if err := rows.Scan(&n); err != nil {
+					return err
+				}
+				if n == tc.cancelAt {
+					cancel()
+				}
+				if n == tc.stopAt {
+					return errStop
+				}
+				return nil
+			}, StreamBatchSize(tc.batchSize))
+
+			if !errors.Is(err, tc.expectedError) {
+				t.Errorf("Expected error %v, got: %v", tc.expectedError, err)
+			}
+			if calls != tc.expectedCalls {
+				t.Errorf("Expected %d calls, got: %d", tc.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+// TestStreamCursorStatements tests the DECLARE/FETCH/CLOSE sequence and the rollback on error
+// TestStreamCursorStatements: DECLARE/FETCH/CLOSEの順序とエラー時のロールバックをテストする関数
+func TestStreamCursorStatements(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	fake.query = serveCursor(numberRows(4), 2)
+
+	err := driver.Stream(context.Background(), "SELECT n FROM numbers WHERE n > $1", []interface{}{0}, func(*sql.Rows) error { return nil }, StreamBatchSize(2))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []string{
+		"BEGIN",
+		"DECLARE sift_stream_cursor NO SCROLL CURSOR FOR SELECT n FROM numbers WHERE n > $1",
+		"FETCH FORWARD 2 FROM sift_stream_cursor",
+		"FETCH FORWARD 2 FROM sift_stream_cursor",
+		"FETCH FORWARD 2 FROM sift_stream_cursor", // an empty batch ends an exact multiple: ちょうど割り切れる場合は空のバッチで終了する
+		"CLOSE sift_stream_cursor",
+		"COMMIT",
+	}
+	if got := strings.Join(fake.executed(), "\n"); got != strings.Join(expected, "\n") {
+		t.Errorf("Expected statements:\n%s\ngot:\n%s", strings.Join(expected, "\n"), got)
+	}
+
+	fake.query = func(string, []sqldriver.NamedValue) (sqldriver.Rows, error) {
+		return nil, errors.New("cursor lost")
+	}
+	err = driver.Stream(context.Background(), "SELECT 1", nil, func(*sql.Rows) error { return nil }, StreamBatchSize(2))
+	if err == nil || !strings.Contains(err.Error(), "cursor lost") {
+		t.Errorf("Expected fetch error, got: %v", err)
+	}
+	if statements := fake.executed(); statements[len(statements)-1] != "ROLLBACK" {
+		t.Errorf("Expected the transaction to roll back, got: %v", statements)
+	}
+}
+
+// TestStreamInvalidBatchSize tests that a negative batch size is rejected before querying
+// TestStreamInvalidBatchSize: 負のバッチサイズがクエリ実行前に拒否されることをテストする関数
+func TestStreamInvalidBatchSize(t *testing.T) {
+	driver, fake := newTestDriver(t)
+
+	err := driver.Stream(context.Background(), "SELECT 1", nil, func(*sql.Rows) error { return nil }, StreamBatchSize(-1))
+	if err == nil || !strings.Contains(err.Error(), "must not be negative") {
+		t.Errorf("Expected batch size error, got: %v", err)
+	}
+	if statements := fake.executed(); len(statements) != 0 {
+		t.Errorf("Expected no statements, got: %v", statements)
+	}
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context" // context: コンテキスト、処理の文脈情報
+	"time"    // time: 時間操作機能
+
+	"go.opentelemetry.io/otel/attribute" // attribute: スパン属性
+	"go.opentelemetry.io/otel/codes"     // codes: スパンのステータスコード
+	"go.opentelemetry.io/otel/trace"     // trace: OpenTelemetryトレースAPI
+)
+
+// tracerName identifies spans created by this package
+// tracerName: このパッケージが作成するスパンの識別名
+// identifies: 識別する
+const tracerName = "api/internal/database"
+
+// WithTracing enables OpenTelemetry spans for driver operations
+// WithTracing: ドライバー操作のOpenTelemetryスパンを有効にするオプション
+// When provider is nil tracing stays disabled: providerがnilの場合は無効のまま
+func WithTracing(provider trace.TracerProvider) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		if provider != nil {
+			d.tracer = provider.Tracer(tracerName)
+		}
+	}
+}
+
+// startSpan starts a client span for the operation, or does nothing when tracing is disabled
+// startSpan: 操作用のクライアントスパンを開始する関数、トレース無効時は何もしない
+// Arguments are never recorded: 引数は記録しない
+func (d *PostgreSQLDriver) startSpan(ctx context.Context, operation, query string) (context.Context, trace.Span) {
+	if d.tracer == nil {
+		return ctx, nil // no-op fast path: 割り当てなしの高速経路
+	}
+	return startClientSpan(ctx, d.tracer, d.config.Load().Database, operation, query)
+}
+
+// startClientSpan starts a client span with the database attributes
+// startClientSpan: データベースの属性を持つクライアントスパンを開始する関数
+func startClientSpan(ctx context.Context, tracer trace.Tracer, database, operation, query string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.name", database),
+	}
+	if query != "" {
+		attrs = append(attrs, attribute.String("db.statement", truncateQuery(query)))
+	}
+
+	return tracer.Start(ctx, operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endSpan records the outcome of the operation and ends the span
+// endSpan: 操作の結果を記録してスパンを終了する関数
+// outcome: 結果
+func endSpan(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TracingHook creates a client span named after the operation around every statement
+// TracingHook: 全ての文の前後に操作名のクライアントスパンを作成するフック
+// The driver runs one for the tracer set by WithTracing; a nil Tracer does nothing
+// ドライバーはWithTracingで設定したトレーサー用に1つ実行する、Tracerがnilの場合は何もしない
+type TracingHook struct {
+	Tracer   trace.Tracer // tracer: トレーサー
+	Database string       // database: db.name属性の値
+}
+
+// tracingSpanKey carries the span started by TracingHook.Before to After
+// tracingSpanKey: TracingHook.Beforeで開始したスパンをAfterへ渡すコンテキストのキー
+// A key of its own keeps After from ending a span some other hook put in the context
+// 専用のキーにより、他のフックがコンテキストに入れたスパンをAfterで終了させない
+type tracingSpanKey struct{}
+
+// Before implements StatementHook
+func (h TracingHook) Before(ctx context.Context, op, query string, args []interface{}) context.Context {
+	if h.Tracer == nil {
+		return ctx
+	}
+	ctx, span := startClientSpan(ctx, h.Tracer, h.Database, op, query)
+	return context.WithValue(ctx, tracingSpanKey{}, span)
+}
+
+// After implements StatementHook
+func (h TracingHook) After(ctx context.Context, op, query string, duration time.Duration, err error) {
+	if h.Tracer == nil {
+		return
+	}
+	if span, ok := ctx.Value(tracingSpanKey{}).(trace.Span); ok {
+		endSpan(span, err)
+	}
+}
@@ -0,0 +1,200 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	"database/sql"                  // sql: データベース操作用パッケージ
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"strings"                       // strings: 文字列操作
+	"testing"                       // testing: テスト機能
+
+	"go.opentelemetry.io/otel/attribute"           // attribute: スパン属性
+	"go.opentelemetry.io/otel/codes"               // codes: ステータスコード
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"  // sdktrace: OpenTelemetry SDK
+	"go.opentelemetry.io/otel/sdk/trace/tracetest" // tracetest: スパン記録用テスト補助
+)
+
+// newTracedTestDriver creates a fake-backed driver with a span recorder
+// newTracedTestDriver: スパンレコーダー付きのフェイクドライバーを作成するテスト用関数
+func newTracedTestDriver(t *testing.T) (*PostgreSQLDriver, *fakeDB, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	driver, fake := newTestDriver(t)
+	WithTracing(provider)(driver)
+	return driver, fake, recorder
+}
+
+// spanAttribute returns the string value of the attribute with key, if present
+// spanAttribute: 指定キーの属性値を返すテスト用関数
+func spanAttribute(attrs []attribute.KeyValue, key string) (string, bool) {
+	for _, attr := range attrs {
+		if string(attr.Key) == key {
+			return attr.Value.AsString(), true
+		}
+	}
+	return "", false
+}
+
+// TestTracingQuerySpans tests span names and attributes for query helpers
+// TestTracingQuerySpans: クエリヘルパーのスパン名と属性をテストする関数
+func TestTracingQuerySpans(t *testing.T) {
+	driver, _, recorder := newTracedTestDriver(t)
+	ctx := context.Background()
+
+	if _, err := driver.ExecContext(ctx, "DELETE FROM app.sessions WHERE user_id = $1", "secret-user-id"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	rows, err := driver.QueryContext(ctx, "SELECT id FROM app.users")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	rows.Close()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got: %d", len(spans))
+	}
+
+	expectedNames := []string{"db.Exec", "db.Query"}
+	for i, span := range spans {
+		if span.Name() != expectedNames[i] {
+			t.Errorf("Expected span name '%s', got: '%s'", expectedNames[i], span.Name())
+		}
+		if system, _ := spanAttribute(span.Attributes(), "db.system"); system != "postgresql" {
+			t.Errorf("Expected db.system 'postgresql', got: '%s'", system)
+		}
+		if name, _ := spanAttribute(span.Attributes(), "db.name"); name != "testdb" {
+			t.Errorf("Expected db.name 'testdb', got: '%s'", name)
+		}
+	}
+
+	statement, _ := spanAttribute(spans[0].Attributes(), "db.statement")
+	if statement != "DELETE FROM app.sessions WHERE user_id = $1" {
+		t.Errorf("Unexpected db.statement: '%s'", statement)
+	}
+
+	// Arguments must never be recorded
+	// arguments: 引数、recorded: 記録される
+	for _, attr := range spans[0].Attributes() {
+		if strings.Contains(attr.Value.Emit(), "secret-user-id") {
+			t.Errorf("Expected arguments to be omitted, found in attribute %s", attr.Key)
+		}
+	}
+}
+
+// TestTracingErrorStatus tests that failed statements mark the span as error
+// TestTracingErrorStatus: 失敗した文がスパンをエラーとしてマークすることをテストする関数
+func TestTracingErrorStatus(t *testing.T) {
+	driver, fake, recorder := newTracedTestDriver(t)
+	fake.exec = func(query string, args []sqldriver.NamedValue) (sqldriver.Result, error) {
+		return nil, errors.New("relation does not exist")
+	}
+
+	if _, err := driver.ExecContext(context.Background(), "DELETE FROM missing"); err == nil {
+		t.Fatal("Expected error, got none")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got: %d", len(spans))
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Errorf("Expected error status, got: %v", spans[0].Status().Code)
+	}
+}
+
+// TestTracingTransactionAndHealthCheck tests spans for the transaction helper and health check
+// TestTracingTransactionAndHealthCheck: トランザクションヘルパーとヘルスチェックのスパンをテストする関数
+func TestTracingTransactionAndHealthCheck(t *testing.T) {
+	driver, _, recorder := newTracedTestDriver(t)
+	ctx := context.Background()
+
+	if err := driver.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error { return nil }); err != nil {
+		t.Fatalf("Expected no error from transaction, got: %v", err)
+	}
+	if _, err := driver.HealthCheck(ctx); err != nil {
+		t.Fatalf("Expected no error from health check, got: %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got: %d", len(spans))
+	}
+	if spans[0].Name() != "db.Transaction" || spans[1].Name() != "db.HealthCheck" {
+		t.Errorf("Unexpected span names: '%s', '%s'", spans[0].Name(), spans[1].Name())
+	}
+}
+
+// TestTracingConnectFailure tests that a failed Connect produces an error span
+// TestTracingConnectFailure: 接続失敗時にエラースパンが作成されることをテストする関数
+func TestTracingConnectFailure(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	driver, err := NewPostgreSQLDriverWithConfig(&DatabaseConfig{
+		Host:     "127.0.0.1",
+		Port:     1, // nothing listens here: 何も待ち受けていないポート
+		User:     "testuser",
+		Password: "testpass",
+		Database: "testdb",
+		SSLMode:  "disable",
+	}, WithTracing(provider))
+	if err != nil {
+		t.Fatalf("Failed to create driver: %v", err)
+	}
+
+	if err := driver.Connect(); err == nil {
+		driver.Close()
+		t.Fatal("Expected connection to fail, got none")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "db.Connect" {
+		t.Fatalf("Expected a single db.Connect span, got: %d spans", len(spans))
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Errorf("Expected error status, got: %v", spans[0].Status().Code)
+	}
+}
+
+// TestTracingDisabledZeroAllocations tests the no-op path allocates nothing
+// TestTracingDisabledZeroAllocations: トレース無効時にメモリ割り当てがないことをテストする関数
+// allocations: メモリ割り当て（複数形）
+func TestTracingDisabledZeroAllocations(t *testing.T) {
+	driver, _ := newTestDriver(t)
+	ctx := context.Background()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, span := driver.startSpan(ctx, "db.Exec", "SELECT 1")
+		endSpan(span, nil)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected 0 allocations without a tracer provider, got: %v", allocs)
+	}
+}
+
+// BenchmarkStartSpanDisabled measures span overhead without a tracer provider
+// BenchmarkStartSpanDisabled: トレーサー無しのスパン処理のオーバーヘッドを計測するベンチマーク
+func BenchmarkStartSpanDisabled(b *testing.B) {
+	driver, err := NewPostgreSQLDriverWithConfig(&DatabaseConfig{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "testuser",
+		Password: "testpass",
+		Database: "testdb",
+		SSLMode:  "disable",
+	})
+	if err != nil {
+		b.Fatalf("Failed to create driver: %v", err)
+	}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, span := driver.startSpan(ctx, "db.Exec", "SELECT 1")
+		endSpan(span, nil)
+	}
+}
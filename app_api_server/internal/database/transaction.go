@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"slices"       // slices: スライス操作
+)
+
+// supportedIsolationLevels lists the isolation levels PostgreSQL and lib/pq accept
+// supportedIsolationLevels: PostgreSQLとlib/pqが受け付ける分離レベルの一覧
+// isolation level: 分離レベル
+var supportedIsolationLevels = []sql.IsolationLevel{
+	sql.LevelDefault,
+	sql.LevelReadUncommitted, // behaves as READ COMMITTED in PostgreSQL: PostgreSQLではREAD COMMITTEDとして動作する
+	sql.LevelReadCommitted,
+	sql.LevelRepeatableRead,
+	sql.LevelSerializable,
+}
+
+// TxOption configures a transaction started by WithTransaction
+// TxOption: WithTransactionで開始するトランザクションを設定するオプション
+type TxOption func(*txSettings)
+
+// txSettings collects the transaction options and the first invalid combination
+// txSettings: トランザクションのオプションと最初の無効な組み合わせを集める構造体
+type txSettings struct {
+	options      sql.TxOptions // options: BeginTxに渡すオプション
+	isolationSet bool          // isolation set: 分離レベルが指定された
+	err          error         // err: 無効な組み合わせ
+}
+
+// TxIsolation sets the isolation level, such as sql.LevelRepeatableRead or sql.LevelSerializable
+// TxIsolation: 分離レベルを設定するオプション（sql.LevelRepeatableReadやsql.LevelSerializableなど）
+func TxIsolation(level sql.IsolationLevel) TxOption {
+	return func(s *txSettings) {
+		if s.isolationSet && s.options.Isolation != level && s.err == nil {
+			s.err = fmt.Errorf("conflicting isolation levels %s and %s", s.options.Isolation, level) // conflicting: 矛盾する
+		}
+		s.options.Isolation = level
+		s.isolationSet = true
+	}
+}
+
+// TxReadOnly begins a READ ONLY transaction
+// TxReadOnly: READ ONLYのトランザクションを開始するオプション
+func TxReadOnly() TxOption {
+	return func(s *txSettings) {
+		s.options.ReadOnly = true
+	}
+}
+
+// buildTxSettings applies opts and rejects combinations PostgreSQL does not support before anything is begun
+// buildTxSettings: optsを適用し、開始前にPostgreSQLが対応しない組み合わせを拒否する関数
+func buildTxSettings(opts []TxOption) (txSettings, error) {
+	var settings txSettings
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	if settings.err != nil {
+		return settings, settings.err
+	}
+	if !slices.Contains(supportedIsolationLevels, settings.options.Isolation) {
+		return settings, fmt.Errorf("isolation level %s is not supported by PostgreSQL", settings.options.Isolation)
+	}
+	return settings, nil
+}
+
+// WithTransaction runs fn inside a transaction, committing on success and rolling back on error or panic
+// WithTransaction: トランザクション内でfnを実行し、成功時はコミット、エラーやパニック時はロールバックする関数
+// committing: コミットする、rolling back: ロールバックする
+// Without options the server default (READ COMMITTED) is used; a read-only driver always begins READ ONLY
+// オプションがない場合はサーバーのデフォルト（READ COMMITTED）を使う、読み取り専用のドライバーは常にREAD ONLYで開始する
+// fn receives a context carrying tx (see TxFromContext); when ctx already carries one, fn joins it
+// and the outermost WithTransaction commits or rolls back
+// fnはtxを持つコンテキストを受け取る（TxFromContextを参照）、ctxが既にトランザクションを持つ場合fnはそれに参加し、
+// 最も外側のWithTransactionがコミットまたはロールバックする
+func (d *PostgreSQLDriver) WithTransaction(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, opts ...TxOption) (err error) {
+	settings, err := buildTxSettings(opts)
+	if err != nil {
+		return fmt.Errorf("invalid transaction options: %w", err)
+	}
+
+	// Options cannot change a transaction that has already begun: 開始済みのトランザクションの設定は変更できない
+	if tx, ok := TxFromContext(ctx); ok {
+		if len(opts) > 0 {
+			return fmt.Errorf("transaction options cannot be applied to the enclosing transaction") // enclosing: 外側の
+		}
+		return fn(ctx, tx)
+	}
+
+	db, err := d.connectedPool()
+	if err != nil {
+		return err
+	}
+
+	ctx = d.beforeStatement(ctx, OpTransaction, "", nil)
+	start := d.now()
+	defer func() { d.afterStatement(ctx, OpTransaction, "", d.now().Sub(start), err) }()
+
+	// A read-only driver begins read-only transactions: 読み取り専用のドライバーは読み取り専用のトランザクションを開始する
+	var txOptions *sql.TxOptions
+	if len(opts) > 0 || d.readOnly() {
+		txOptions = &settings.options
+		txOptions.ReadOnly = txOptions.ReadOnly || d.readOnly()
+	}
+	tx, err := db.BeginTx(ctx, txOptions)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err) // begin: 開始する
+	}
+
+	// Roll back if fn panics, then re-panic
+	// panics: パニックする、re-panic: 再度パニックさせる
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(ContextWithTx(ctx, tx), tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rollbackErr) // rollback: ロールバック
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err) // commit: コミット、確定
+	}
+	return nil
+}
+
+// WithSerializableRetry runs fn in a transaction, retrying the whole transaction on serialization failures
+// WithSerializableRetry: トランザクション内でfnを実行し、直列化の失敗時はトランザクション全体を再試行する関数
+// The isolation level defaults to SERIALIZABLE when opts do not set one; retries follow the driver's retry policy,
+// so fn must be safe to run more than once; it cannot be nested in another transaction
+// optsで分離レベルを指定しない場合はSERIALIZABLEを使う、再試行はドライバーのリトライポリシーに従うため、fnは複数回実行しても安全であること
+// 他のトランザクションの中では使用できない
+func (d *PostgreSQLDriver) WithSerializableRetry(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, opts ...TxOption) error {
+	settings, err := buildTxSettings(opts)
+	if err != nil {
+		return fmt.Errorf("invalid transaction options: %w", err)
+	}
+	if !settings.isolationSet {
+		opts = append([]TxOption{TxIsolation(sql.LevelSerializable)}, opts...)
+	}
+
+	return d.retryWhen(ctx, "serialization failure", IsSerializationFailure, func() error {
+		return d.WithTransaction(ctx, fn, opts...)
+	})
+}
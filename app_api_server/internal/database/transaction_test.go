@@ -0,0 +1,178 @@
+package database
+
+import (
+	"context"      // context: コンテキスト
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"reflect"      // reflect: リフレクション、値の比較
+	"strings"      // strings: 文字列操作
+	"testing"      // testing: テスト機能
+	"time"         // time: 時間操作機能
+
+	"github.com/lib/pq" // pq: PostgreSQLドライバー、エラー型
+)
+
+// TestWithTransaction tests commit, rollback and panic handling of the transaction helper
+// TestWithTransaction: トランザクションヘルパーのコミット、ロールバック、パニック処理をテストする関数
+func TestWithTransaction(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	testCases := []struct {
+		name        string
+		fn          func(ctx context.Context, tx *sql.Tx) error
+		expectPanic bool
+		expectErr   error
+		expected    []string // expected: 期待される文の並び
+	}{
+		{
+			name:     "Commit on success",
+			fn:       func(ctx context.Context, tx *sql.Tx) error { _, err := tx.Exec("INSERT INTO t VALUES (1)"); return err },
+			expected: []string{"BEGIN", "INSERT INTO t VALUES (1)", "COMMIT"},
+		},
+		{
+			name:      "Rollback on error",
+			fn:        func(ctx context.Context, tx *sql.Tx) error { return errBoom },
+			expectErr: errBoom,
+			expected:  []string{"BEGIN", "ROLLBACK"},
+		},
+		{
+			name:        "Rollback on panic",
+			fn:          func(ctx context.Context, tx *sql.Tx) error { panic("boom") },
+			expectPanic: true,
+			expected:    []string{"BEGIN", "ROLLBACK"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, fake := newTestDriver(t)
+
+			func() {
+				defer func() {
+					if p := recover(); (p != nil) != tc.expectPanic {
+						t.Errorf("Expected panic=%v, got: %v", tc.expectPanic, p)
+					}
+				}()
+
+				err := driver.WithTransaction(context.Background(), tc.fn)
+				if !errors.Is(err, tc.expectErr) {
+					t.Errorf("Expected error %v, got: %v", tc.expectErr, err)
+				}
+			}()
+
+			if got := fake.executed(); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected statements %v, got: %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestWithTransactionReadOnly tests that a read-only driver begins read-only transactions
+// TestWithTransactionReadOnly: 読み取り専用のドライバーが読み取り専用のトランザクションを開始することをテストする関数
+func TestWithTransactionReadOnly(t *testing.T) {
+	for _, readOnly := range []bool{false, true} {
+		driver, fake := newTestDriver(t)
+		updateTestConfig(driver, func(config *DatabaseConfig) { config.ReadOnly = readOnly })
+
+		if err := driver.WithTransaction(context.Background(), func(ctx context.Context, tx *sql.Tx) error { return nil }); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if len(fake.txOptions) != 1 || fake.txOptions[0].ReadOnly != readOnly {
+			t.Errorf("Expected a transaction with ReadOnly=%v, got: %+v", readOnly, fake.txOptions)
+		}
+	}
+}
+
+// TestWithTransactionOptions tests the isolation and read-only options and the up-front validation
+// TestWithTransactionOptions: 分離レベルと読み取り専用のオプション、および事前の検証をテストする関数
+func TestWithTransactionOptions(t *testing.T) {
+	testCases := []struct {
+		name              string
+		opts              []TxOption
+		expectedIsolation sql.IsolationLevel
+		expectedReadOnly  bool
+		expectError       bool
+		errorContent      string
+	}{
+		{name: "Default", expectedIsolation: sql.LevelDefault},
+		{name: "Repeatable read", opts: []TxOption{TxIsolation(sql.LevelRepeatableRead)}, expectedIsolation: sql.LevelRepeatableRead},
+		{name: "Serializable read only", opts: []TxOption{TxIsolation(sql.LevelSerializable), TxReadOnly()}, expectedIsolation: sql.LevelSerializable, expectedReadOnly: true},
+		{name: "Same level twice", opts: []TxOption{TxIsolation(sql.LevelSerializable), TxIsolation(sql.LevelSerializable)}, expectedIsolation: sql.LevelSerializable},
+		{name: "Conflicting levels", opts: []TxOption{TxIsolation(sql.LevelRepeatableRead), TxIsolation(sql.LevelSerializable)}, expectError: true, errorContent: "conflicting isolation levels"},
+		{name: "Snapshot is not supported", opts: []TxOption{TxIsolation(sql.LevelSnapshot)}, expectError: true, errorContent: "not supported by PostgreSQL"},
+		{name: "Linearizable is not supported", opts: []TxOption{TxIsolation(sql.LevelLinearizable)}, expectError: true, errorContent: "not supported by PostgreSQL"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, fake := newTestDriver(t)
+
+			err := driver.WithTransaction(context.Background(), func(ctx context.Context, tx *sql.Tx) error { return nil }, tc.opts...)
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				if len(fake.executed()) != 0 {
+					t.Errorf("Expected nothing to be begun, got: %v", fake.executed())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if len(fake.txOptions) != 1 {
+				t.Fatalf("Expected 1 transaction, got: %d", len(fake.txOptions))
+			}
+			got := fake.txOptions[0]
+			if sql.IsolationLevel(got.Isolation) != tc.expectedIsolation || got.ReadOnly != tc.expectedReadOnly {
+				t.Errorf("Expected %s read-only=%v, got: %s read-only=%v", tc.expectedIsolation, tc.expectedReadOnly, sql.IsolationLevel(got.Isolation), got.ReadOnly)
+			}
+		})
+	}
+}
+
+// TestWithSerializableRetry tests that serialization failures rerun the whole transaction at SERIALIZABLE by default
+// TestWithSerializableRetry: 直列化の失敗でトランザクション全体がデフォルトのSERIALIZABLEで再実行されることをテストする関数
+func TestWithSerializableRetry(t *testing.T) {
+	testCases := []struct {
+		name              string
+		opts              []TxOption
+		failures          int // failures: 直列化の失敗を返す回数
+		expectedIsolation sql.IsolationLevel
+		expectedAttempts  int
+		expectError       bool
+	}{
+		{name: "Defaults to serializable", failures: 1, expectedIsolation: sql.LevelSerializable, expectedAttempts: 2},
+		{name: "Keeps an explicit level", opts: []TxOption{TxIsolation(sql.LevelRepeatableRead)}, failures: 1, expectedIsolation: sql.LevelRepeatableRead, expectedAttempts: 2},
+		{name: "Gives up after the retry policy", failures: 5, expectedIsolation: sql.LevelSerializable, expectedAttempts: 3, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, fake := newTestDriver(t)
+			driver.retryPolicy = RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond}
+			captureLog(t)
+
+			attempts := 0
+			err := driver.WithSerializableRetry(context.Background(), func(ctx context.Context, tx *sql.Tx) error {
+				attempts++
+				if attempts <= tc.failures {
+					return &pq.Error{Code: "40001"}
+				}
+				return nil
+			}, tc.opts...)
+
+			if (err != nil) != tc.expectError || (tc.expectError && !IsSerializationFailure(err)) {
+				t.Errorf("Expected error=%v, got: %v", tc.expectError, err)
+			}
+			if attempts != tc.expectedAttempts || len(fake.txOptions) != tc.expectedAttempts {
+				t.Errorf("Expected %d attempts, got: %d (%d transactions)", tc.expectedAttempts, attempts, len(fake.txOptions))
+			}
+			for _, opts := range fake.txOptions {
+				if sql.IsolationLevel(opts.Isolation) != tc.expectedIsolation {
+					t.Errorf("Expected isolation %s, got: %s", tc.expectedIsolation, sql.IsolationLevel(opts.Isolation))
+				}
+			}
+		})
+	}
+}
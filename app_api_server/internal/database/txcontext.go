@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+)
+
+// Querier is the subset of *sql.DB and *sql.Tx used by repositories
+// Querier: リポジトリが使う*sql.DBと*sql.Txの共通部分のインターフェース
+// subset: 部分集合
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// txContextKey is the context key under which ContextWithTx stores a transaction
+// txContextKey: ContextWithTxがトランザクションを格納するコンテキストのキー
+type txContextKey struct{}
+
+// ContextWithTx returns a copy of ctx carrying tx, so that calls further down can share the transaction
+// ContextWithTx: txを持つctxのコピーを返す関数、下位の呼び出しがトランザクションを共有できるようにする
+func ContextWithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext returns the transaction carried by ctx, if any
+// TxFromContext: ctxが持つトランザクションを返す関数（存在する場合）
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*sql.Tx)
+	return tx, ok && tx != nil
+}
+
+// Querier returns the transaction carried by ctx, or the connection pool when there is none
+// Querier: ctxが持つトランザクション、なければ接続プールを返す関数
+// Repository methods that query through it join a caller's WithTransaction without any signature change
+// これを経由してクエリを実行するリポジトリのメソッドは、シグネチャを変えずに呼び出し元のWithTransactionに参加する
+func (d *PostgreSQLDriver) Querier(ctx context.Context) (Querier, error) {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx, nil
+	}
+	return d.connectedPool()
+}
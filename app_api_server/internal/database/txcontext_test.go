@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"      // context: コンテキスト
+	"database/sql" // sql: データベース操作用パッケージ
+	"reflect"      // reflect: リフレクション、値の比較
+	"strings"      // strings: 文字列操作
+	"testing"      // testing: テスト機能
+)
+
+// insertAuditEntry stands in for a repository method that queries through Querier
+// insertAuditEntry: Querier経由でクエリを実行するリポジトリのメソッドの代わり
+func insertAuditEntry(ctx context.Context, driver *PostgreSQLDriver, entry string) error {
+	q, err := driver.Querier(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = q.ExecContext(ctx, "INSERT INTO audit VALUES ($1)", entry)
+	return err
+}
+
+// TestTransactionPropagation tests that nested repository calls and nested WithTransaction share one transaction
+// TestTransactionPropagation: 入れ子のリポジトリ呼び出しと入れ子のWithTransactionが1つのトランザクションを共有することをテストする関数
+func TestTransactionPropagation(t *testing.T) {
+	driver, fake := newTestDriver(t)
+
+	err := driver.WithTransaction(context.Background(), func(ctx context.Context, tx *sql.Tx) error {
+		if q, err := driver.Querier(ctx); err != nil || q != Querier(tx) {
+			t.Errorf("Expected Querier to return the transaction, got: %v (%v)", q, err)
+		}
+		if err := insertAuditEntry(ctx, driver, "outer"); err != nil {
+			return err
+		}
+		return driver.WithTransaction(ctx, func(ctx context.Context, inner *sql.Tx) error {
+			if inner != tx {
+				t.Error("Expected the nested WithTransaction to join the outer transaction")
+			}
+			return insertAuditEntry(ctx, driver, "inner")
+		})
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []string{"BEGIN", "INSERT INTO audit VALUES ($1)", "INSERT INTO audit VALUES ($1)", "COMMIT"}
+	if got := fake.executed(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected statements %v, got: %v", expected, got)
+	}
+}
+
+// TestTransactionPropagationNestedOptions tests that options cannot be applied to a joined transaction
+// TestTransactionPropagationNestedOptions: 参加したトランザクションにはオプションを適用できないことをテストする関数
+func TestTransactionPropagationNestedOptions(t *testing.T) {
+	driver, fake := newTestDriver(t)
+
+	err := driver.WithTransaction(context.Background(), func(ctx context.Context, tx *sql.Tx) error {
+		return driver.WithTransaction(ctx, func(context.Context, *sql.Tx) error { return nil }, TxReadOnly())
+	})
+	if err == nil || !strings.Contains(err.Error(), "enclosing transaction") {
+		t.Errorf("Expected enclosing transaction error, got: %v", err)
+	}
+
+	expected := []string{"BEGIN", "ROLLBACK"}
+	if got := fake.executed(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected statements %v, got: %v", expected, got)
+	}
+}
+
+// TestQuerierWithoutTransaction tests that Querier falls back to the pool outside a transaction
+// TestQuerierWithoutTransaction: トランザクション外ではQuerierが接続プールを返すことをテストする関数
+func TestQuerierWithoutTransaction(t *testing.T) {
+	driver, fake := newTestDriver(t)
+	ctx := context.Background()
+
+	if _, ok := TxFromContext(ctx); ok {
+		t.Error("Expected no transaction in a plain context")
+	}
+	if q, err := driver.Querier(ctx); err != nil || q != Querier(driver.db) {
+		t.Errorf("Expected Querier to return the pool, got: %v (%v)", q, err)
+	}
+	if err := insertAuditEntry(ctx, driver, "standalone"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got := fake.executed(); !reflect.DeepEqual(got, []string{"INSERT INTO audit VALUES ($1)"}) {
+		t.Errorf("Expected a single statement without BEGIN, got: %v", got)
+	}
+
+	driver.db = nil
+	if _, err := driver.Querier(ctx); err == nil || !strings.Contains(err.Error(), "not established") {
+		t.Errorf("Expected connection error, got: %v", err)
+	}
+}
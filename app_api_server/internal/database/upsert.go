@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"slices"       // slices: スライス操作
+	"strconv"      // strconv: string conversion（文字列変換）、プレースホルダー番号の生成
+	"strings"      // strings: 文字列操作
+)
+
+// UpsertOptions configures the ON CONFLICT target, the updated columns and RETURNING for Upsert
+// UpsertOptions: UpsertのON CONFLICTの対象、更新するカラム、RETURNINGを設定する構造体
+type UpsertOptions struct {
+	ConflictColumns []string // conflict columns: 競合対象のカラム（必須、例: "email"）
+	UpdateColumns   []string // update columns: 競合時にEXCLUDEDから更新するカラム、空なら競合対象以外の挿入カラムすべて
+	DoNothing       bool     // do nothing: 競合時は更新せずにスキップする
+	Returning       []string // returning: 挿入または更新した行から返すカラム、destへ読み取る
+}
+
+// Upsert inserts one row or, when it conflicts on opts.ConflictColumns, updates it instead
+// Upsert: 1行を挿入し、opts.ConflictColumnsで競合した場合は代わりに更新する関数
+// It returns the number of rows inserted or updated, which is 0 when DoNothing skipped the row
+// 挿入または更新した行数を返す、DoNothingで行がスキップされた場合は0
+// With opts.Returning the returned columns are scanned into dest, which is left untouched when nothing was written
+// opts.Returningを指定すると返されたカラムをdestへ読み取る、何も書き込まれなかった場合destは変更しない
+func (d *PostgreSQLDriver) Upsert(ctx context.Context, table string, columns []string, values []interface{}, opts UpsertOptions, dest ...interface{}) (int64, error) {
+	if err := validateUpsert(table, columns, values, opts, dest); err != nil {
+		return 0, err
+	}
+	query := buildUpsert(table, columns, opts)
+
+	if len(opts.Returning) == 0 {
+		result, err := d.ExecContext(ctx, query, values...)
+		if err != nil {
+			return 0, err
+		}
+		return result.RowsAffected()
+	}
+
+	err := d.QueryRowContext(ctx, query, values...).Scan(dest...)
+	if errors.Is(err, sql.ErrNoRows) && opts.DoNothing {
+		return 0, nil // the conflicting row was skipped: 競合した行はスキップされた
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// validateUpsert checks the arguments before the statement is built
+// validateUpsert: 文を構築する前に引数を検証する関数
+// Update columns must be inserted too, so the two lists cannot drift apart
+// 更新するカラムは挿入するカラムにも含まれている必要があり、2つの一覧がずれることを防ぐ
+func validateUpsert(table string, columns []string, values []interface{}, opts UpsertOptions, dest []interface{}) error {
+	if table == "" {
+		return fmt.Errorf("upsert table is required")
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("upsert requires at least one column")
+	}
+	if len(values) != len(columns) {
+		return fmt.Errorf("upsert has %d values, expected %d", len(values), len(columns))
+	}
+	if len(opts.ConflictColumns) == 0 {
+		return fmt.Errorf("upsert requires at least one conflict column")
+	}
+	if len(dest) != len(opts.Returning) {
+		return fmt.Errorf("upsert returns %d columns but %d destinations were given", len(opts.Returning), len(dest))
+	}
+	if opts.DoNothing {
+		return nil
+	}
+
+	for _, column := range opts.UpdateColumns {
+		if !slices.Contains(columns, column) {
+			return fmt.Errorf("upsert update column %q is not one of the inserted columns", column)
+		}
+	}
+	if len(upsertUpdateColumns(columns, opts)) == 0 {
+		return fmt.Errorf("upsert has no columns to update; every inserted column is a conflict column (use DoNothing)")
+	}
+	return nil
+}
+
+// upsertUpdateColumns returns opts.UpdateColumns, defaulting to the inserted columns outside the conflict target
+// upsertUpdateColumns: opts.UpdateColumnsを返す関数、未指定なら競合対象以外の挿入カラム
+func upsertUpdateColumns(columns []string, opts UpsertOptions) []string {
+	if len(opts.UpdateColumns) > 0 {
+		return opts.UpdateColumns
+	}
+	var update []string
+	for _, column := range columns {
+		if !slices.Contains(opts.ConflictColumns, column) {
+			update = append(update, column)
+		}
+	}
+	return update
+}
+
+// buildUpsert builds the INSERT ... ON CONFLICT statement with one placeholder per column
+// buildUpsert: カラムごとに1つのプレースホルダーを持つINSERT ... ON CONFLICT文を構築する関数
+func buildUpsert(table string, columns []string, opts UpsertOptions) string {
+	placeholders := make([]string, len(columns))
+	for i := range columns {
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+	}
+
+	var b strings.Builder
+	b.WriteString("INSERT INTO " + quoteQualifiedIdentifier(table) + " (" + quoteIdentifiers(columns) + ") VALUES (" + strings.Join(placeholders, ",") + ")")
+	if opts.DoNothing {
+		b.WriteString(conflictClause(ConflictDoNothing, opts.ConflictColumns, nil))
+	} else {
+		b.WriteString(conflictClause(ConflictDoUpdate, opts.ConflictColumns, upsertUpdateColumns(columns, opts)))
+	}
+	if len(opts.Returning) > 0 {
+		b.WriteString(" RETURNING " + quoteIdentifiers(opts.Returning))
+	}
+	return b.String()
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"                       // context: コンテキスト
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"strings"                       // strings: 文字列操作
+	"testing"                       // testing: テスト機能
+)
+
+// TestBuildUpsert tests the generated INSERT ... ON CONFLICT statements
+// TestBuildUpsert: 生成されるINSERT ... ON CONFLICT文をテストする関数
+func TestBuildUpsert(t *testing.T) {
+	columns := []string{"email", "first_name", "last_name"}
+
+	testCases := []struct {
+		name     string
+		opts     UpsertOptions
+		expected string
+	}{
+		{
+			name:     "Update every other column by default",
+			opts:     UpsertOptions{ConflictColumns: []string{"email"}},
+			expected: `INSERT INTO "app"."users" ("email", "first_name", "last_name") VALUES ($1,$2,$3) ON CONFLICT ("email") DO UPDATE SET "first_name" = EXCLUDED."first_name", "last_name" = EXCLUDED."last_name"`,
+		},
+		{
+			name:     "Explicit update columns with RETURNING",
+			opts:     UpsertOptions{ConflictColumns: []string{"email"}, UpdateColumns: []string{"last_name"}, Returning: []string{"id", "created_at"}},
+			expected: `INSERT INTO "app"."users" ("email", "first_name", "last_name") VALUES ($1,$2,$3) ON CONFLICT ("email") DO UPDATE SET "last_name" = EXCLUDED."last_name" RETURNING "id", "created_at"`,
+		},
+		{
+			name:     "Do nothing",
+			opts:     UpsertOptions{ConflictColumns: []string{"email"}, DoNothing: true},
+			expected: `INSERT INTO "app"."users" ("email", "first_name", "last_name") VALUES ($1,$2,$3) ON CONFLICT ("email") DO NOTHING`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := buildUpsert("app.users", columns, tc.opts); got != tc.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestUpsertValidation tests that invalid arguments are rejected before any statement runs
+// TestUpsertValidation: 無効な引数が文の実行前に拒否されることをテストする関数
+func TestUpsertValidation(t *testing.T) {
+	var id string
+	testCases := []struct {
+		name         string
+		table        string
+		columns      []string
+		values       []interface{}
+		opts         UpsertOptions
+		dest         []interface{}
+		errorContent string
+	}{
+		{name: "Missing table", columns: []string{"email"}, values: []interface{}{"a"}, opts: UpsertOptions{ConflictColumns: []string{"email"}}, errorContent: "table is required"},
+		{name: "No columns", table: "users", opts: UpsertOptions{ConflictColumns: []string{"email"}}, errorContent: "at least one column"},
+		{name: "Value count mismatch", table: "users", columns: []string{"email", "name"}, values: []interface{}{"a"}, opts: UpsertOptions{ConflictColumns: []string{"email"}}, errorContent: "has 1 values, expected 2"},
+		{name: "Empty conflict target", table: "users", columns: []string{"email"}, values: []interface{}{"a"}, opts: UpsertOptions{DoNothing: true}, errorContent: "at least one conflict column"},
+		{name: "Update column not inserted", table: "users", columns: []string{"email", "name"}, values: []interface{}{"a", "b"}, opts: UpsertOptions{ConflictColumns: []string{"email"}, UpdateColumns: []string{"nickname"}}, errorContent: `"nickname" is not one of the inserted columns`},
+		{name: "Nothing to update", table: "users", columns: []string{"email"}, values: []interface{}{"a"}, opts: UpsertOptions{ConflictColumns: []string{"email"}}, errorContent: "no columns to update"},
+		{name: "Destination count mismatch", table: "users", columns: []string{"email"}, values: []interface{}{"a"}, opts: UpsertOptions{ConflictColumns: []string{"email"}, DoNothing: true, Returning: []string{"id", "email"}}, dest: []interface{}{&id}, errorContent: "2 columns but 1 destinations"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver, fake := newTestDriver(t)
+
+			_, err := driver.Upsert(context.Background(), tc.table, tc.columns, tc.values, tc.opts, tc.dest...)
+			if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+				t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+			}
+			if statements := fake.executed(); len(statements) != 0 {
+				t.Errorf("Expected no statements, got: %v", statements)
+			}
+		})
+	}
+}
+
+// TestUpsertExecution tests the affected row count and scanning RETURNING values, including a skipped row
+// TestUpsertExecution: 影響行数とRETURNINGの値の読み取り（スキップされた行を含む）をテストする関数
+func TestUpsertExecution(t *testing.T) {
+	ctx := context.Background()
+	columns := []string{"email", "first_name"}
+	values := []interface{}{"a@example.com", "Ann"}
+
+	t.Run("Without RETURNING", func(t *testing.T) {
+		driver, fake := newTestDriver(t)
+		var gotArgs []sqldriver.NamedValue
+		fake.exec = func(query string, args []sqldriver.NamedValue) (sqldriver.Result, error) {
+			gotArgs = args
+			return sqldriver.RowsAffected(1), nil
+		}
+
+		affected, err := driver.Upsert(ctx, "app.users", columns, values, UpsertOptions{ConflictColumns: []string{"email"}})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if affected != 1 || len(gotArgs) != 2 || gotArgs[0].Value != "a@example.com" {
+			t.Errorf("Expected 1 row written with the email as $1, got: %d rows, args %v", affected, gotArgs)
+		}
+	})
+
+	t.Run("RETURNING", func(t *testing.T) {
+		driver, fake := newTestDriver(t)
+		fake.query = func(string, []sqldriver.NamedValue) (sqldriver.Rows, error) {
+			return &fakeRows{columns: []string{"id"}, values: [][]sqldriver.Value{{"user-1"}}}, nil
+		}
+
+		var id string
+		affected, err := driver.Upsert(ctx, "app.users", columns, values, UpsertOptions{ConflictColumns: []string{"email"}, Returning: []string{"id"}}, &id)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if affected != 1 || id != "user-1" {
+			t.Errorf("Expected 1 row with id user-1, got: %d rows, id %q", affected, id)
+		}
+	})
+
+	t.Run("Skipped by DO NOTHING", func(t *testing.T) {
+		driver, fake := newTestDriver(t)
+		fake.query = func(string, []sqldriver.NamedValue) (sqldriver.Rows, error) {
+			return &fakeRows{columns: []string{"id"}}, nil
+		}
+
+		id := "unchanged"
+		affected, err := driver.Upsert(ctx, "app.users", columns, values, UpsertOptions{ConflictColumns: []string{"email"}, DoNothing: true, Returning: []string{"id"}}, &id)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if affected != 0 || id != "unchanged" {
+			t.Errorf("Expected no row and an untouched destination, got: %d rows, id %q", affected, id)
+		}
+	})
+}
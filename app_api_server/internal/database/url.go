@@ -0,0 +1,229 @@
+package database
+
+import (
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"net"     // net: ネットワーク、ホストとポートの結合
+	"net/url" // url: URLの構築と解析
+	"strconv" // strconv: string conversion（文字列変換）
+	"strings" // strings: 文字列操作
+	"time"    // time: 時間操作機能
+)
+
+// defaultPostgresPort is the port assumed when a URL does not name one
+// defaultPostgresPort: URLにポートがない場合に使用するポート番号
+const defaultPostgresPort = 5432
+
+// connectTimeoutSeconds renders ConnectTimeout in whole seconds, rounding up so a sub-second timeout is not sent as 0 (no limit)
+// connectTimeoutSeconds: ConnectTimeoutを秒単位の文字列にする関数、1秒未満が0（無制限）にならないよう切り上げる
+// rounding up: 切り上げ
+func (c *DatabaseConfig) connectTimeoutSeconds() string {
+	return durationSeconds(c.ConnectTimeout)
+}
+
+// connectionParameters returns the query parameters of the URL form, including Options
+// connectionParameters: URL形式で使用するクエリパラメータを返す関数
+func (c *DatabaseConfig) connectionParameters() url.Values {
+	params := url.Values{}
+	params.Set("sslmode", c.SSLMode)
+	if c.ConnectTimeout > 0 {
+		params.Set("connect_timeout", c.connectTimeoutSeconds())
+	}
+	if c.TimeZone != "" {
+		params.Set("timezone", c.TimeZone)
+	}
+	if c.TargetSessionAttrs != "" {
+		params.Set("target_session_attrs", c.TargetSessionAttrs)
+	}
+	for _, param := range c.sessionParameters() {
+		params.Set(param.key, param.value)
+	}
+	if c.FallbackApplicationName != "" {
+		params.Set("fallback_application_name", c.FallbackApplicationName)
+	}
+	for _, param := range c.keepaliveParameters() {
+		params.Set(param.key, param.value)
+	}
+	for key, value := range c.Options {
+		params.Set(key, value)
+	}
+	return params
+}
+
+// BuildConnectionURL builds a postgres:// URL for tools such as golang-migrate and psql
+// BuildConnectionURL: golang-migrateやpsqlなどのツール向けにpostgres:// URLを構築する関数
+// The user, password and database name are escaped: ユーザー名、パスワード、データベース名はエスケープされる
+func (c *DatabaseConfig) BuildConnectionURL() string {
+	// A host list becomes host1:port1,host2:port2 as libpq expects
+	// ホストのリストはlibpqが期待するhost1:port1,host2:port2の形式にする
+	hosts := c.hosts()
+	for i, host := range hosts {
+		if bare, ok := ipv6Literal(host); ok {
+			host = bare // JoinHostPort adds the brackets: JoinHostPortが角括弧を付ける
+		}
+		hosts[i] = net.JoinHostPort(host, strconv.Itoa(c.hostPort(i)))
+	}
+
+	connectionURL := url.URL{
+		Scheme:   "postgres",
+		Host:     strings.Join(hosts, ","),
+		Path:     "/" + c.Database,
+		RawQuery: c.connectionParameters().Encode(),
+	}
+	if c.Password != "" {
+		connectionURL.User = url.UserPassword(c.User, c.Password)
+	} else if c.User != "" {
+		connectionURL.User = url.User(c.User)
+	}
+	return connectionURL.String()
+}
+
+// RedactedConnectionURL builds the connection URL with the password masked, for logging
+// RedactedConnectionURL: ログ出力用にパスワードを伏せ字にした接続URLを構築する関数
+func (c *DatabaseConfig) RedactedConnectionURL() string {
+	redacted := c.Redacted()
+	built := redacted.BuildConnectionURL()
+	if c.Password == "" {
+		return built
+	}
+
+	// Show the mask unescaped so it reads like the other redacted output
+	// 他の伏せ字出力と同じく読めるよう、伏せ字をエスケープせずに表示する
+	escapedMask := strings.TrimPrefix(url.UserPassword("", redactedMask).String(), ":")
+	return strings.Replace(built, ":"+escapedMask+"@", ":"+redactedMask+"@", 1)
+}
+
+// ConfigFromURL parses a postgres:// or postgresql:// URL into a DatabaseConfig
+// ConfigFromURL: postgres://またはpostgresql:// URLをDatabaseConfigに解析する関数
+// parses: 解析する
+// Omitted parts use the same defaults as LoadDatabaseConfig: 省略された部分はLoadDatabaseConfigと同じデフォルト値を使う
+func ConfigFromURL(rawURL string) (*DatabaseConfig, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection URL: %w", err) // invalid: 無効な
+	}
+	if parsed.Scheme != "postgres" && parsed.Scheme != "postgresql" {
+		return nil, fmt.Errorf("invalid connection URL scheme %q: expected postgres or postgresql", parsed.Scheme) // scheme: スキーム
+	}
+
+	config := &DatabaseConfig{
+		Host:       parsed.Hostname(),
+		Port:       defaultPostgresPort,
+		Database:   strings.TrimPrefix(parsed.Path, "/"),
+		SSLMode:    "require", // default: secure SSL mode
+		TimeZone:   defaultTimeZone,
+		AuthMethod: AuthMethodPassword,
+	}
+	if config.Host == "" {
+		config.Host = "localhost"
+	}
+	if portStr := parsed.Port(); portStr != "" {
+		config.Port, err = strconv.Atoi(portStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port number: %v", err)
+		}
+	}
+	if strings.Contains(parsed.Host, ",") {
+		if err := parseURLHostList(config, parsed.Host); err != nil {
+			return nil, err
+		}
+	}
+	if parsed.User != nil {
+		config.User = parsed.User.Username()
+		config.Password, _ = parsed.User.Password()
+	}
+
+	for key, values := range parsed.Query() {
+		value := values[len(values)-1] // the last occurrence wins, as in libpq: libpqと同様に最後の値を優先する
+		switch key {
+		case "sslmode":
+			config.SSLMode = strings.ToLower(value)
+		case "connect_timeout":
+			seconds, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid connect_timeout: %v", err)
+			}
+			config.ConnectTimeout = time.Duration(seconds) * time.Second
+		case "timezone":
+			config.TimeZone = value
+		case "target_session_attrs":
+			config.TargetSessionAttrs = strings.ToLower(value)
+		case "lock_timeout":
+			if config.LockTimeout, err = parseMillisecondsParameter(key, value); err != nil {
+				return nil, err
+			}
+		case "default_transaction_read_only":
+			if config.ReadOnly, err = parseOnOffParameter(key, value); err != nil {
+				return nil, err
+			}
+		case "idle_in_transaction_session_timeout":
+			if config.IdleInTransactionTimeout, err = parseMillisecondsParameter(key, value); err != nil {
+				return nil, err
+			}
+		case "fallback_application_name":
+			config.FallbackApplicationName = value
+		case "keepalives":
+			keepalives, err := parseOnOffParameter(key, value)
+			if err != nil {
+				return nil, err
+			}
+			config.DisableKeepalives = !keepalives
+		case "keepalives_idle":
+			if config.KeepalivesIdle, err = parseSecondsParameter(key, value); err != nil {
+				return nil, err
+			}
+		case "keepalives_interval":
+			if config.KeepalivesInterval, err = parseSecondsParameter(key, value); err != nil {
+				return nil, err
+			}
+		case "keepalives_count":
+			if config.KeepalivesCount, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("invalid %s: %v", key, err)
+			}
+		default:
+			if reservedOptionKeys[key] {
+				// host, user and the like belong in the URL itself
+				// hostやuserなどはURL本体に書く
+				return nil, fmt.Errorf("unsupported connection URL parameter: %s", key) // unsupported: 未対応の
+			}
+			if config.Options == nil {
+				config.Options = map[string]string{}
+			}
+			config.Options[key] = value
+		}
+	}
+
+	return config, nil
+}
+
+// parseURLHostList fills Host and Ports from a host1:port1,host2:port2 URL authority
+// parseURLHostList: host1:port1,host2:port2形式のURLのホスト部からHostとPortsを設定する関数
+// Hosts without a port use the default port: ポートのないホストはデフォルトのポートを使う
+func parseURLHostList(config *DatabaseConfig, hostList string) error {
+	var hosts []string
+	var ports []int
+	for _, entry := range strings.Split(hostList, ",") {
+		host, portStr := entry, ""
+		if strings.HasPrefix(entry, "[") || strings.Count(entry, ":") == 1 {
+			var err error
+			if host, portStr, err = net.SplitHostPort(entry); err != nil {
+				host = strings.TrimSuffix(strings.TrimPrefix(entry, "["), "]") // bracketed without a port: ポートなしの角括弧付き
+				portStr = ""
+			}
+		}
+
+		port := defaultPostgresPort
+		if portStr != "" {
+			var err error
+			if port, err = strconv.Atoi(portStr); err != nil {
+				return fmt.Errorf("invalid port number: %v", err)
+			}
+		}
+		hosts = append(hosts, host)
+		ports = append(ports, port)
+	}
+
+	config.Host = strings.Join(hosts, ",")
+	config.Port = ports[0]
+	config.Ports = ports
+	return nil
+}
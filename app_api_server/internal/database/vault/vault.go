@@ -0,0 +1,140 @@
+// Package vault issues dynamic database credentials from HashiCorp Vault's database secrets engine
+// vault: HashiCorp Vaultのデータベースシークレットエンジンから動的なデータベース認証情報を発行するパッケージ
+// dynamic: 動的な、secrets engine: シークレットエンジン
+// It implements database.CredentialProvider over Vault's HTTP API so no Vault SDK is needed
+// VaultのHTTP APIでdatabase.CredentialProviderを実装するため、Vault SDKは不要
+package vault
+
+import (
+	"context"       // context: コンテキスト、処理の文脈情報
+	"encoding/json" // json: JSONのエンコード・デコード
+	"fmt"           // fmt: format（フォーマット）、文字列フォーマット機能
+	"io"            // io: 入出力
+	"net/http"      // http: HTTPクライアント
+	"net/url"       // url: URLのパスエスケープ
+	"os"            // os: operating system（オペレーティングシステム）、環境変数の読み込み
+	"strings"       // strings: 文字列操作
+	"time"          // time: 時間操作機能
+
+	"api/internal/database" // database: データベースドライバー
+)
+
+// defaultMount is the path where the database secrets engine is mounted
+// defaultMount: データベースシークレットエンジンのマウント先のデフォルト値
+const defaultMount = "database"
+
+// Config holds the Vault connection settings
+// Config: Vaultへの接続設定を保持する構造体
+type Config struct {
+	Address string // address: VaultのURL（VAULT_ADDR）
+	Token   string // token: Vaultのトークン（VAULT_TOKEN）
+	Role    string // role: 認証情報を発行するロール名（VAULT_ROLE）
+	Mount   string // mount: シークレットエンジンのマウント先（VAULT_DB_MOUNT、デフォルトは"database"）
+}
+
+// LoadConfig reads the Vault settings from VAULT_ADDR, VAULT_TOKEN, VAULT_ROLE and VAULT_DB_MOUNT
+// LoadConfig: VAULT_ADDR、VAULT_TOKEN、VAULT_ROLE、VAULT_DB_MOUNTからVault設定を読み込む関数
+func LoadConfig() (*Config, error) {
+	config := &Config{
+		Address: os.Getenv("VAULT_ADDR"),
+		Token:   os.Getenv("VAULT_TOKEN"),
+		Role:    os.Getenv("VAULT_ROLE"),
+		Mount:   os.Getenv("VAULT_DB_MOUNT"),
+	}
+
+	if config.Address == "" {
+		return nil, fmt.Errorf("VAULT_ADDR environment variable is required") // required: 必要な
+	}
+	if config.Token == "" {
+		return nil, fmt.Errorf("VAULT_TOKEN environment variable is required")
+	}
+	if config.Role == "" {
+		return nil, fmt.Errorf("VAULT_ROLE environment variable is required")
+	}
+	if config.Mount == "" {
+		config.Mount = defaultMount
+	}
+	return config, nil
+}
+
+// Provider issues credentials from a Vault database role
+// Provider: Vaultのデータベースロールから認証情報を発行する構造体
+type Provider struct {
+	config Config       // config: Vault設定
+	client *http.Client // client: HTTPクライアント
+}
+
+// NewProvider creates a provider for config using client (http.DefaultClient when nil)
+// NewProvider: clientを使うconfig用の取得元を作成するファクトリー関数（nilの場合はhttp.DefaultClient）
+func NewProvider(config Config, client *http.Client) *Provider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if config.Mount == "" {
+		config.Mount = defaultMount
+	}
+	return &Provider{config: config, client: client}
+}
+
+// NewProviderFromEnv creates a provider configured by the VAULT_* environment variables
+// NewProviderFromEnv: VAULT_*環境変数で設定された取得元を作成するファクトリー関数
+func NewProviderFromEnv() (*Provider, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Vault configuration: %w", err) // failed: 失敗した
+	}
+	return NewProvider(*config, nil), nil
+}
+
+// credsResponse is the subset of Vault's creds response used here
+// credsResponse: ここで使用するVaultの認証情報レスポンスの一部
+type credsResponse struct {
+	LeaseDuration int `json:"lease_duration"` // lease duration: リース期間（秒）
+	Data          struct {
+		Username string `json:"username"` // username: ユーザー名
+		Password string `json:"password"` // password: パスワード
+	} `json:"data"`
+	Errors []string `json:"errors"` // errors: Vaultが返すエラーメッセージ
+}
+
+// GetCredentials requests a new lease from <mount>/creds/<role>
+// GetCredentials: <mount>/creds/<role>から新しいリースを要求する関数
+// Implements database.CredentialProvider: database.CredentialProviderを実装する
+func (p *Provider) GetCredentials(ctx context.Context) (string, string, time.Duration, error) {
+	endpoint := strings.TrimRight(p.config.Address, "/") + "/v1/" +
+		strings.Trim(p.config.Mount, "/") + "/creds/" + url.PathEscape(p.config.Role)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to build Vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", p.config.Token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to reach Vault: %w", err) // reach: 到達する
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20)) // 1 MiB is far above a creds response: 認証情報のレスポンスには十分な上限
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to read Vault response: %w", err)
+	}
+
+	var creds credsResponse
+	if err := json.Unmarshal(body, &creds); err != nil && resp.StatusCode == http.StatusOK {
+		return "", "", 0, fmt.Errorf("failed to decode Vault response: %w", err) // decode: デコードする
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", 0, fmt.Errorf("vault returned %s for role %s: %s", resp.Status, p.config.Role, strings.Join(creds.Errors, "; "))
+	}
+	if creds.Data.Username == "" || creds.Data.Password == "" {
+		return "", "", 0, fmt.Errorf("vault response for role %s has no credentials", p.config.Role)
+	}
+
+	return creds.Data.Username, creds.Data.Password, time.Duration(creds.LeaseDuration) * time.Second, nil
+}
+
+// Provider is used through database.WithCredentialProvider
+// Providerはdatabase.WithCredentialProviderを通して使用する
+var _ database.CredentialProvider = (*Provider)(nil)
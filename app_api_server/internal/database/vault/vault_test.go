@@ -0,0 +1,121 @@
+package vault
+
+import (
+	"context"           // context: コンテキスト
+	"net/http"          // http: HTTPサーバー
+	"net/http/httptest" // httptest: テスト用HTTPサーバー
+	"os"                // os: 環境変数操作
+	"strings"           // strings: 文字列操作
+	"testing"           // testing: テストフレームワーク
+	"time"              // time: 時間操作
+)
+
+// TestGetCredentials tests reading a lease from the creds endpoint
+// TestGetCredentials: credsエンドポイントからリースを読み取る処理をテストする関数
+func TestGetCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/db-prod/creds/app-rw" {
+			t.Errorf("Expected creds path, got: %s", r.URL.Path)
+		}
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			t.Errorf("Expected Vault token header, got: %s", r.Header.Get("X-Vault-Token"))
+		}
+		w.Write([]byte(`{"lease_id":"db-prod/creds/app-rw/abc","lease_duration":3600,"data":{"username":"v-app-rw-abc","password":"A1a-secret"}}`))
+	}))
+	defer server.Close()
+
+	provider := NewProvider(Config{Address: server.URL + "/", Token: "s.token", Role: "app-rw", Mount: "db-prod"}, server.Client())
+	user, password, ttl, err := provider.GetCredentials(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if user != "v-app-rw-abc" || password != "A1a-secret" {
+		t.Errorf("Expected leased credentials, got: %s %s", user, password)
+	}
+	if ttl != time.Hour {
+		t.Errorf("Expected 1h lease, got: %s", ttl)
+	}
+}
+
+// TestGetCredentialsErrors tests error responses from Vault
+// TestGetCredentialsErrors: Vaultのエラーレスポンスをテストする関数
+func TestGetCredentialsErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   int
+		body     string
+		errorMsg string
+	}{
+		{"permission denied", http.StatusForbidden, `{"errors":["permission denied"]}`, "permission denied"},
+		{"unknown role", http.StatusBadRequest, `{"errors":["unknown role: app-rw"]}`, "unknown role"},
+		{"malformed body", http.StatusOK, `not json`, "failed to decode"},
+		{"missing data", http.StatusOK, `{"lease_duration":60,"data":{}}`, "has no credentials"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			provider := NewProvider(Config{Address: server.URL, Token: "s.token", Role: "app-rw"}, server.Client())
+			_, _, _, err := provider.GetCredentials(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tc.errorMsg) {
+				t.Errorf("Expected error containing %q, got: %v", tc.errorMsg, err)
+			}
+		})
+	}
+}
+
+// TestLoadConfig tests reading the VAULT_* environment variables
+// TestLoadConfig: VAULT_*環境変数の読み込みをテストする関数
+func TestLoadConfig(t *testing.T) {
+	envVars := []string{"VAULT_ADDR", "VAULT_TOKEN", "VAULT_ROLE", "VAULT_DB_MOUNT"}
+	originalValues := make(map[string]string)
+	for _, envVar := range envVars {
+		originalValues[envVar] = os.Getenv(envVar)
+	}
+	defer func() {
+		for _, envVar := range envVars {
+			os.Setenv(envVar, originalValues[envVar])
+		}
+	}()
+
+	testCases := []struct {
+		name        string
+		envValues   map[string]string
+		expectError bool
+	}{
+		{"complete", map[string]string{"VAULT_ADDR": "https://vault:8200", "VAULT_TOKEN": "s.token", "VAULT_ROLE": "app-rw"}, false},
+		{"missing address", map[string]string{"VAULT_TOKEN": "s.token", "VAULT_ROLE": "app-rw"}, true},
+		{"missing token", map[string]string{"VAULT_ADDR": "https://vault:8200", "VAULT_ROLE": "app-rw"}, true},
+		{"missing role", map[string]string{"VAULT_ADDR": "https://vault:8200", "VAULT_TOKEN": "s.token"}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, envVar := range envVars {
+				os.Unsetenv(envVar)
+			}
+			for key, value := range tc.envValues {
+				os.Setenv(key, value)
+			}
+
+			config, err := LoadConfig()
+			if tc.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if config.Mount != defaultMount {
+				t.Errorf("Expected default mount %s, got: %s", defaultMount, config.Mount)
+			}
+		})
+	}
+}
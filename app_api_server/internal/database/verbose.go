@@ -0,0 +1,96 @@
+package database
+
+import (
+	"fmt"         // fmt: format（フォーマット）、文字列フォーマット機能
+	"sync/atomic" // atomic: アトミック操作、ロックなしの期限管理
+	"time"        // time: 時間操作機能
+)
+
+// defaultVerboseLogMaxWindow caps how long verbose query logging may be enabled at once
+// defaultVerboseLogMaxWindow: 詳細クエリログを一度に有効化できる時間の上限のデフォルト値
+// caps: 上限を設ける
+const defaultVerboseLogMaxWindow = 30 * time.Minute
+
+// WithVerboseLogMaxWindow sets the hard cap on a verbose query logging window
+// WithVerboseLogMaxWindow: 詳細クエリログの有効期間の上限を設定するオプション
+func WithVerboseLogMaxWindow(window time.Duration) DriverOption {
+	return func(d *PostgreSQLDriver) {
+		if window > 0 {
+			d.verboseMaxWindow = window
+		}
+	}
+}
+
+// VerboseLoggingState describes an active verbose query logging window
+// VerboseLoggingState: 有効な詳細クエリログの期間を表す構造体
+type VerboseLoggingState struct {
+	Until     time.Time     `json:"until"`        // until: 期限
+	Remaining time.Duration `json:"remaining_ns"` // remaining: 残り時間（ナノ秒）
+	EnabledBy string        `json:"enabled_by"`   // enabled by: 有効化した操作者
+}
+
+// EnableVerboseQueryLogging logs every statement until now+window and returns that deadline
+// EnableVerboseQueryLogging: 現在時刻+windowまで全ての文をログ出力し、その期限を返す関数
+// deadline: 期限
+// A later call replaces the deadline, so overlapping toggles extend or shorten the window.
+// actor names who asked for it and is recorded in the event log with the deadline
+// 後の呼び出しは期限を置き換えるため、重複した切り替えで期間の延長・短縮ができる
+// actorは要求した操作者を表し、期限と共にイベントログに記録する
+func (d *PostgreSQLDriver) EnableVerboseQueryLogging(window time.Duration, actor string) (time.Time, error) {
+	if actor == "" {
+		return time.Time{}, fmt.Errorf("verbose logging requires an actor for the audit log") // actor: 操作者
+	}
+	if window <= 0 {
+		return time.Time{}, fmt.Errorf("verbose logging window must be positive: %s", window)
+	}
+	if window > d.verboseMaxWindow {
+		return time.Time{}, fmt.Errorf("verbose logging window %s exceeds maximum %s", window, d.verboseMaxWindow) // exceeds: 超える
+	}
+
+	deadline := d.now().Add(window)
+	d.verboseActor.Store(&actor)
+	atomic.StoreInt64(&d.verboseUntil, deadline.UnixNano())
+	d.recordEvent(EventVerboseLoggingEnabled, fmt.Sprintf("actor=%s until=%s", actor, deadline.Format(time.RFC3339)), nil)
+	d.logger.Info(fmt.Sprintf("Verbose query logging enabled by %s until %s", actor, deadline.Format(time.RFC3339)), "actor", actor, "until", deadline)
+	return deadline, nil
+}
+
+// DisableVerboseQueryLogging ends the verbose query logging window immediately, recording actor in the event log
+// DisableVerboseQueryLogging: 詳細クエリログの有効期間を即座に終了し、actorをイベントログに記録する関数
+func (d *PostgreSQLDriver) DisableVerboseQueryLogging(actor string) {
+	if atomic.SwapInt64(&d.verboseUntil, 0) != 0 {
+		d.recordEvent(EventVerboseLoggingDisabled, "actor="+actor, nil)
+		d.logger.Info(fmt.Sprintf("Verbose query logging disabled by %s", actor), "actor", actor)
+	}
+}
+
+// VerboseQueryLogging reports whether verbose query logging is active and how long remains
+// VerboseQueryLogging: 詳細クエリログが有効かどうかと残り時間を返す関数
+// remains: 残る
+func (d *PostgreSQLDriver) VerboseQueryLogging() (bool, time.Duration) {
+	until := atomic.LoadInt64(&d.verboseUntil)
+	if until == 0 {
+		return false, 0 // never enabled or disabled: 未設定または無効化済み
+	}
+
+	remaining := time.Unix(0, until).Sub(d.now())
+	if remaining <= 0 {
+		return false, 0 // deadline passed: 期限切れ
+	}
+	return true, remaining
+}
+
+// VerboseLoggingState returns the active verbose query logging window, or nil when there is none
+// VerboseLoggingState: 有効な詳細クエリログの期間を返す関数、有効でない場合はnil
+func (d *PostgreSQLDriver) VerboseLoggingState() *VerboseLoggingState {
+	until := atomic.LoadInt64(&d.verboseUntil)
+	active, remaining := d.VerboseQueryLogging()
+	if !active {
+		return nil
+	}
+	state := &VerboseLoggingState{Until: time.Unix(0, until), Remaining: remaining}
+	if actor := d.verboseActor.Load(); actor != nil {
+		state.EnabledBy = *actor
+	}
+	return state
+}
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"context" // context: コンテキスト
+	"strings" // strings: 文字列操作
+	"testing" // testing: テスト機能
+	"time"    // time: 時間操作機能
+)
+
+// TestVerboseQueryLoggingWindow tests that statements are logged only inside the window
+// TestVerboseQueryLoggingWindow: 有効期間内のみ文がログ出力されることをテストする関数
+func TestVerboseQueryLoggingWindow(t *testing.T) {
+	logs := captureLog(t)
+	driver, _ := newTestDriver(t)
+
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	driver.now = func() time.Time { return current }
+
+	exec := func(query string) {
+		t.Helper()
+		if _, err := driver.ExecContext(context.Background(), query); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+
+	exec("SELECT 'before'")
+	if _, err := driver.EnableVerboseQueryLogging(10*time.Minute, "alice"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	current = current.Add(5 * time.Minute)
+	exec("SELECT 'during'")
+	if active, remaining := driver.VerboseQueryLogging(); !active || remaining != 5*time.Minute {
+		t.Errorf("Expected active with 5m remaining, got: %v, %s", active, remaining)
+	}
+
+	current = current.Add(5 * time.Minute)
+	exec("SELECT 'after'")
+	if active, _ := driver.VerboseQueryLogging(); active {
+		t.Error("Expected verbose logging to expire at the deadline")
+	}
+
+	output := logs.String()
+	if strings.Contains(output, "'before'") || strings.Contains(output, "'after'") {
+		t.Errorf("Expected statements outside the window not to be logged, got: %q", output)
+	}
+	if !strings.Contains(output, "SELECT 'during'") || !strings.Contains(output, "verbose_test.go:") {
+		t.Errorf("Expected statement and caller inside the window to be logged, got: %q", output)
+	}
+}
+
+// TestVerboseQueryLoggingSlowStatement tests that a slow statement inside the window gets both the warning and the verbose line
+// TestVerboseQueryLoggingSlowStatement: 有効期間内の遅い文が警告と詳細ログの両方を出力することをテストする関数
+func TestVerboseQueryLoggingSlowStatement(t *testing.T) {
+	logs := captureLog(t)
+	driver, _ := newTestDriver(t)
+	updateTestConfig(driver, func(config *DatabaseConfig) { config.SlowQueryThreshold = 10 * time.Millisecond })
+	driver.now = fakeClock(250 * time.Millisecond)
+
+	if _, err := driver.EnableVerboseQueryLogging(10*time.Minute, "alice"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, err := driver.ExecContext(context.Background(), "SELECT 'slow'"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	output := logs.String()
+	for _, expected := range []string{"slow query took 250ms", "Query took 250ms"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain '%s', got: %q", expected, output)
+		}
+	}
+}
+
+// TestVerboseQueryLoggingOverlappingToggles tests that later toggles replace the deadline
+// TestVerboseQueryLoggingOverlappingToggles: 後の切り替えが期限を置き換えることをテストする関数
+func TestVerboseQueryLoggingOverlappingToggles(t *testing.T) {
+	captureLog(t)
+	driver, _ := newTestDriver(t)
+
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	driver.now = func() time.Time { return current }
+
+	testCases := []struct {
+		name      string
+		window    time.Duration
+		remaining time.Duration // remaining: 切り替え直後の残り時間
+	}{
+		{name: "Initial window", window: 10 * time.Minute, remaining: 10 * time.Minute},
+		{name: "Extend", window: 20 * time.Minute, remaining: 20 * time.Minute},
+		{name: "Shorten", window: time.Minute, remaining: time.Minute},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			current = current.Add(30 * time.Second)
+			if _, err := driver.EnableVerboseQueryLogging(tc.window, "alice"); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if active, remaining := driver.VerboseQueryLogging(); !active || remaining != tc.remaining {
+				t.Errorf("Expected active with %s remaining, got: %v, %s", tc.remaining, active, remaining)
+			}
+		})
+	}
+
+	driver.DisableVerboseQueryLogging("alice")
+	if active, _ := driver.VerboseQueryLogging(); active {
+		t.Error("Expected verbose logging to be disabled")
+	}
+}
+
+// TestVerboseQueryLoggingLimits tests the window validation and the configurable cap
+// TestVerboseQueryLoggingLimits: 期間の検証と設定可能な上限をテストする関数
+func TestVerboseQueryLoggingLimits(t *testing.T) {
+	testCases := []struct {
+		name        string
+		opts        []DriverOption
+		window      time.Duration
+		expectError bool
+	}{
+		{name: "Zero window", window: 0, expectError: true},
+		{name: "Negative window", window: -time.Minute, expectError: true},
+		{name: "At default cap", window: 30 * time.Minute, expectError: false},
+		{name: "Above default cap", window: 31 * time.Minute, expectError: true},
+		{name: "Above custom cap", opts: []DriverOption{WithVerboseLogMaxWindow(5 * time.Minute)}, window: 10 * time.Minute, expectError: true},
+		{name: "Raised custom cap", opts: []DriverOption{WithVerboseLogMaxWindow(time.Hour)}, window: 45 * time.Minute, expectError: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			captureLog(t)
+			driver, err := NewPostgreSQLDriverWithConfig(&DatabaseConfig{
+				Host:     "localhost",
+				Port:     5432,
+				User:     "testuser",
+				Password: "testpass",
+				Database: "testdb",
+				SSLMode:  "disable",
+			}, tc.opts...)
+			if err != nil {
+				t.Fatalf("Failed to create driver: %v", err)
+			}
+
+			_, err = driver.EnableVerboseQueryLogging(tc.window, "alice")
+			if tc.expectError && err == nil {
+				t.Errorf("Expected error for test case '%s', but got none", tc.name)
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Expected no error for test case '%s', got: %v", tc.name, err)
+			}
+			if active, _ := driver.VerboseQueryLogging(); active == tc.expectError {
+				t.Errorf("Expected active=%v, got: %v", !tc.expectError, active)
+			}
+		})
+	}
+}
+
+// TestVerboseQueryLoggingAudit tests the events recorded for each toggle and the state reported while a window is open
+// TestVerboseQueryLoggingAudit: 切り替えごとに記録されるイベントと、期間中に報告される状態をテストする関数
+func TestVerboseQueryLoggingAudit(t *testing.T) {
+	captureLog(t)
+	driver, _ := newTestDriver(t)
+
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	driver.now = func() time.Time { return current }
+
+	if _, err := driver.EnableVerboseQueryLogging(10*time.Minute, ""); err == nil {
+		t.Error("Expected an error without an actor")
+	}
+	if state := driver.VerboseLoggingState(); state != nil {
+		t.Errorf("Expected no state before enabling, got: %+v", state)
+	}
+
+	if _, err := driver.EnableVerboseQueryLogging(10*time.Minute, "alice"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	current = current.Add(4 * time.Minute)
+	expected := &VerboseLoggingState{Until: time.Date(2024, 1, 1, 0, 10, 0, 0, time.UTC), Remaining: 6 * time.Minute, EnabledBy: "alice"}
+	if state := driver.VerboseLoggingState(); state == nil || !state.Until.Equal(expected.Until) || state.Remaining != expected.Remaining || state.EnabledBy != expected.EnabledBy {
+		t.Errorf("Expected %+v, got: %+v", expected, state)
+	}
+	if status, _ := driver.HealthCheck(context.Background()); status.VerboseLogging == nil || status.VerboseLogging.EnabledBy != "alice" {
+		t.Errorf("Expected the health status to report the window, got: %+v", status.VerboseLogging)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // no network in unit tests: ユニットテストではネットワークを使わない
+	if report := driver.Diagnose(ctx); report.VerboseLogging == nil || report.VerboseLogging.Remaining != 6*time.Minute {
+		t.Errorf("Expected the diagnosis report to carry the window, got: %+v", report.VerboseLogging)
+	}
+
+	driver.DisableVerboseQueryLogging("bob")
+	driver.DisableVerboseQueryLogging("carol") // already off, not recorded: 既に無効のため記録しない
+	if state := driver.VerboseLoggingState(); state != nil {
+		t.Errorf("Expected no state after disabling, got: %+v", state)
+	}
+
+	var details []string
+	for _, event := range driver.Events(0) {
+		switch event.Type {
+		case EventVerboseLoggingEnabled, EventVerboseLoggingDisabled:
+			details = append(details, string(event.Type)+" "+event.Detail)
+		}
+	}
+	expectedDetails := []string{
+		"verbose_logging_enabled actor=alice until=2024-01-01T00:10:00Z",
+		"verbose_logging_disabled actor=bob",
+	}
+	if strings.Join(details, "\n") != strings.Join(expectedDetails, "\n") {
+		t.Errorf("Expected events %q, got: %q", expectedDetails, details)
+	}
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"      // context: コンテキスト、処理の文脈情報
+	"database/sql" // sql: データベース操作用パッケージ
+	"errors"       // errors: エラー操作
+	"fmt"          // fmt: format（フォーマット）、文字列フォーマット機能
+	"strconv"      // strconv: string conversion（文字列変換）
+	"strings"      // strings: 文字列操作
+)
+
+// ErrServerVersionTooOld is returned by Connect when the server is older than MinServerVersion
+// ErrServerVersionTooOld: サーバーがMinServerVersionより古い場合にConnectが返すエラー
+var ErrServerVersionTooOld = errors.New("server version is older than the required minimum")
+
+// parseServerVersionNum parses the integer form reported by SHOW server_version_num, e.g. 130004 or 90624
+// parseServerVersionNum: SHOW server_version_numが報告する整数形式（130004や90624など）を解析する関数
+func parseServerVersionNum(value string) (int, error) {
+	num, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || num < 10000 {
+		return 0, fmt.Errorf("invalid server_version_num %q", value)
+	}
+	return num, nil
+}
+
+// formatServerVersion renders a server_version_num as a dotted version
+// formatServerVersion: server_version_numをドット区切りのバージョンに整形する関数
+// Since PostgreSQL 10 the number is major*10000+minor; before that major*10000+minor*100+patch
+// PostgreSQL 10以降はmajor*10000+minor、それ以前はmajor*10000+minor*100+patch
+func formatServerVersion(num int) string {
+	if num >= 100000 {
+		return fmt.Sprintf("%d.%d", num/10000, num%10000)
+	}
+	return fmt.Sprintf("%d.%d.%d", num/10000, num/100%100, num%100)
+}
+
+// parseMinServerVersion converts a dotted version such as "13", "13.2" or "9.6" to the server_version_num scale
+// parseMinServerVersion: "13"、"13.2"、"9.6"などのドット区切りのバージョンをserver_version_numの尺度に変換する関数
+// scale: 尺度
+func parseMinServerVersion(version string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(version), ".")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid minimum server version %q", version)
+	}
+
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || n > 99 {
+			return 0, fmt.Errorf("invalid minimum server version %q", version)
+		}
+		numbers[i] = n
+	}
+
+	major, minor, patch := numbers[0], numbers[1], numbers[2]
+	if major >= 10 {
+		if len(parts) == 3 {
+			return 0, fmt.Errorf("invalid minimum server version %q: PostgreSQL 10 and later use major.minor", version)
+		}
+		return major*10000 + minor, nil
+	}
+	if major < 7 {
+		return 0, fmt.Errorf("invalid minimum server version %q", version)
+	}
+	return major*10000 + minor*100 + patch, nil
+}
+
+// queryServerVersionNum asks the server for its server_version_num
+// queryServerVersionNum: サーバーにserver_version_numを問い合わせる関数
+func queryServerVersionNum(ctx context.Context, db *sql.DB) (int, error) {
+	var value string
+	if err := db.QueryRowContext(ctx, "SHOW server_version_num").Scan(&value); err != nil {
+		return 0, fmt.Errorf("failed to query server version: %w", err)
+	}
+	return parseServerVersionNum(value)
+}
+
+// checkServerVersion enforces config.MinServerVersion on a freshly opened pool
+// checkServerVersion: 新しく開いたプールに対してconfig.MinServerVersionを適用する関数
+// enforces: 適用する、強制する
+// It returns the server version, or 0 when no minimum is configured: サーバーのバージョンを返し、最小値が未設定なら0を返す
+func checkServerVersion(ctx context.Context, db *sql.DB, config *DatabaseConfig) (int, error) {
+	if config.MinServerVersion == "" {
+		return 0, nil // check skipped: チェックを省略
+	}
+
+	required, err := parseMinServerVersion(config.MinServerVersion)
+	if err != nil {
+		return 0, err
+	}
+	found, err := queryServerVersionNum(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	if found < required {
+		return 0, fmt.Errorf("%w: found PostgreSQL %s, MinServerVersion requires %s",
+			ErrServerVersionTooOld, formatServerVersion(found), config.MinServerVersion)
+	}
+	return found, nil
+}
+
+// GetServerVersion returns the server version, such as "13.4", recorded at connect or queried on first use
+// GetServerVersion: 接続時に記録された、または初回使用時に問い合わせたサーバーのバージョン（"13.4"など）を返す関数
+// diagnostics: 診断
+func (d *PostgreSQLDriver) GetServerVersion() (string, error) {
+	d.mu.Lock()
+	num := d.serverVersionNum
+	d.mu.Unlock()
+	if num != 0 {
+		return formatServerVersion(num), nil
+	}
+
+	db, err := d.usablePool()
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.pingTimeout)
+	defer cancel()
+	num, err = queryServerVersionNum(ctx, db)
+	if err != nil {
+		return "", err
+	}
+
+	d.mu.Lock()
+	d.serverVersionNum = num
+	d.mu.Unlock()
+	return formatServerVersion(num), nil
+}
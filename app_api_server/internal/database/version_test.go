@@ -0,0 +1,209 @@
+package database
+
+import (
+	"database/sql"                  // sql: データベース操作用パッケージ
+	sqldriver "database/sql/driver" // sqldriver: SQLドライバーインターフェース
+	"errors"                        // errors: エラー操作
+	"strings"                       // strings: 文字列操作
+	"testing"                       // testing: テスト機能
+)
+
+// TestParseServerVersionNum tests parsing of SHOW server_version_num output
+// TestParseServerVersionNum: SHOW server_version_numの出力の解析をテストする関数
+func TestParseServerVersionNum(t *testing.T) {
+	testCases := []struct {
+		name        string
+		value       string
+		expected    int
+		formatted   string
+		expectError bool
+	}{
+		{name: "PostgreSQL 15", value: "150004", expected: 150004, formatted: "15.4"},
+		{name: "PostgreSQL 10", value: "100023", expected: 100023, formatted: "10.23"},
+		{name: "PostgreSQL 9.6", value: "90624", expected: 90624, formatted: "9.6.24"},
+		{name: "surrounding whitespace", value: " 130012\n", expected: 130012, formatted: "13.12"},
+		{name: "empty", value: "", expectError: true},
+		{name: "dotted version", value: "13.4", expectError: true},
+		{name: "too small", value: "42", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			num, err := parseServerVersionNum(tc.value)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %q, got: %d", tc.value, num)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if num != tc.expected {
+				t.Errorf("Expected %d, got: %d", tc.expected, num)
+			}
+			if formatted := formatServerVersion(num); formatted != tc.formatted {
+				t.Errorf("Expected formatted version %s, got: %s", tc.formatted, formatted)
+			}
+		})
+	}
+}
+
+// TestParseMinServerVersion tests conversion of MinServerVersion to the server_version_num scale
+// TestParseMinServerVersion: MinServerVersionのserver_version_numの尺度への変換をテストする関数
+func TestParseMinServerVersion(t *testing.T) {
+	testCases := []struct {
+		name        string
+		version     string
+		expected    int
+		expectError bool
+	}{
+		{name: "major only", version: "13", expected: 130000},
+		{name: "major and minor", version: "13.0", expected: 130000},
+		{name: "minor release", version: "14.2", expected: 140002},
+		{name: "pre-10 major", version: "9.6", expected: 90600},
+		{name: "pre-10 patch", version: "9.6.3", expected: 90603},
+		{name: "empty", version: "", expectError: true},
+		{name: "not a number", version: "thirteen", expectError: true},
+		{name: "three parts after 10", version: "13.0.1", expectError: true},
+		{name: "too many parts", version: "9.6.3.1", expectError: true},
+		{name: "negative", version: "-13", expectError: true},
+		{name: "ancient", version: "6.5", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			num, err := parseMinServerVersion(tc.version)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %q, got: %d", tc.version, num)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if num != tc.expected {
+				t.Errorf("Expected %d, got: %d", tc.expected, num)
+			}
+		})
+	}
+}
+
+// newVersionTestDriver creates a driver whose fake server reports serverVersionNum
+// newVersionTestDriver: フェイクサーバーがserverVersionNumを報告するテスト用ドライバーを作成する関数
+func newVersionTestDriver(t *testing.T, minServerVersion, serverVersionNum string) *PostgreSQLDriver {
+	t.Helper()
+
+	driver := newFakeConnectingDriver(t)
+	updateTestConfig(driver, func(config *DatabaseConfig) { config.MinServerVersion = minServerVersion })
+	driver.openDB = func(string) (*sql.DB, error) {
+		fake, db := newFakeDB()
+		fake.query = func(query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+			return &fakeRows{
+				columns: []string{"server_version_num"},
+				values:  [][]sqldriver.Value{{serverVersionNum}},
+			}, nil
+		}
+		return db, nil
+	}
+	return driver
+}
+
+// TestConnectMinServerVersion tests that Connect enforces MinServerVersion
+// TestConnectMinServerVersion: ConnectがMinServerVersionを適用することをテストする関数
+func TestConnectMinServerVersion(t *testing.T) {
+	testCases := []struct {
+		name             string
+		minServerVersion string
+		serverVersionNum string
+		expectError      bool
+	}{
+		{name: "newer server", minServerVersion: "13.0", serverVersionNum: "150004"},
+		{name: "exact minimum", minServerVersion: "13.0", serverVersionNum: "130000"},
+		{name: "older server", minServerVersion: "13.0", serverVersionNum: "120017", expectError: true},
+		{name: "older minor release", minServerVersion: "14.5", serverVersionNum: "140004", expectError: true},
+		{name: "pre-10 server", minServerVersion: "10", serverVersionNum: "90624", expectError: true},
+		{name: "check skipped", minServerVersion: "", serverVersionNum: "90624"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver := newVersionTestDriver(t, tc.minServerVersion, tc.serverVersionNum)
+			defer driver.Close()
+
+			err := driver.Connect()
+			if tc.expectError {
+				if !errors.Is(err, ErrServerVersionTooOld) {
+					t.Fatalf("Expected ErrServerVersionTooOld, got: %v", err)
+				}
+				found := formatServerVersion(mustParseServerVersionNum(t, tc.serverVersionNum))
+				if !strings.Contains(err.Error(), found) || !strings.Contains(err.Error(), tc.minServerVersion) {
+					t.Errorf("Expected error to name %s and %s, got: %v", found, tc.minServerVersion, err)
+				}
+				if driver.IsOpen() {
+					t.Error("Expected no pool after a rejected server version")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestGetServerVersion tests the version recorded at connect and the lazy lookup when the check is skipped
+// TestGetServerVersion: 接続時に記録されたバージョンと、チェック省略時の遅延取得をテストする関数
+func TestGetServerVersion(t *testing.T) {
+	t.Run("not connected", func(t *testing.T) {
+		driver := newVersionTestDriver(t, "", "150004")
+		if _, err := driver.GetServerVersion(); err == nil {
+			t.Error("Expected error before Connect")
+		}
+	})
+
+	for _, minServerVersion := range []string{"13.0", ""} {
+		t.Run("min "+minServerVersion, func(t *testing.T) {
+			driver := newVersionTestDriver(t, minServerVersion, "150004")
+			if err := driver.Connect(); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			defer driver.Close()
+
+			version, err := driver.GetServerVersion()
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if version != "15.4" {
+				t.Errorf("Expected version 15.4, got: %s", version)
+			}
+		})
+	}
+}
+
+// TestValidateMinServerVersion tests that an unparsable MinServerVersion is rejected at construction
+// TestValidateMinServerVersion: 解析できないMinServerVersionが生成時に拒否されることをテストする関数
+func TestValidateMinServerVersion(t *testing.T) {
+	_, err := NewPostgreSQLDriverWithConfig(&DatabaseConfig{
+		Host:             "localhost",
+		Port:             5432,
+		User:             "testuser",
+		Password:         "testpass",
+		Database:         "testdb",
+		SSLMode:          "disable",
+		MinServerVersion: "latest",
+	})
+	if err == nil {
+		t.Error("Expected error for invalid MinServerVersion")
+	}
+}
+
+func mustParseServerVersionNum(t *testing.T, value string) int {
+	t.Helper()
+	num, err := parseServerVersionNum(value)
+	if err != nil {
+		t.Fatalf("Expected valid server_version_num, got: %v", err)
+	}
+	return num
+}
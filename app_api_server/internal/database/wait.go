@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context" // context: コンテキスト、処理の文脈情報
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"time"    // time: 時間操作機能
+)
+
+// WaitOptions controls how WaitForDatabase polls the database
+// WaitOptions: WaitForDatabaseがデータベースを確認する方法を制御する構造体
+// Zero fields use the defaults below: 0のフィールドは以下のデフォルト値を使う
+type WaitOptions struct {
+	InitialBackoff time.Duration  // initial backoff: 最初の待機時間（デフォルト500ms）
+	MaxBackoff     time.Duration  // max backoff: 待機時間の上限（デフォルト5秒）
+	AttemptTimeout time.Duration  // attempt timeout: 1回の接続とpingの制限時間（デフォルト5秒）
+	LogInterval    time.Duration  // log interval: 待機中の進捗をログ出力する間隔（デフォルト10秒）
+	Logger         Logger         // logger: ログの出力先、nilの場合は標準ロガー
+	DriverOptions  []DriverOption // driver options: 接続に使うドライバーのオプション（aws-iamの場合のWithAuthTokenProviderなど）
+}
+
+// WaitOptions defaults
+// WaitOptionsのデフォルト値
+const (
+	defaultWaitInitialBackoff = 500 * time.Millisecond
+	defaultWaitMaxBackoff     = 5 * time.Second
+	defaultWaitAttemptTimeout = 5 * time.Second
+	defaultWaitLogInterval    = 10 * time.Second
+)
+
+// withDefaults fills the zero fields
+// withDefaults: 0のフィールドをデフォルト値で埋める関数
+func (o WaitOptions) withDefaults() WaitOptions {
+	if o.InitialBackoff <= 0 {
+		o.InitialBackoff = defaultWaitInitialBackoff
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = defaultWaitMaxBackoff
+	}
+	if o.AttemptTimeout <= 0 {
+		o.AttemptTimeout = defaultWaitAttemptTimeout
+	}
+	if o.LogInterval <= 0 {
+		o.LogInterval = defaultWaitLogInterval
+	}
+	o.Logger = loggerOrDefault(o.Logger)
+	return o
+}
+
+// WaitForDatabase blocks until the database accepts a connect and ping, or ctx ends
+// WaitForDatabase: データベースが接続とpingを受け付けるか、ctxが終了するまで待機する関数
+// Failures such as a refused connection are retried with backoff; an authentication failure is returned
+// at once because retrying cannot fix it. Nothing is kept open once it returns
+// 接続拒否などの失敗はバックオフしながら再試行する、認証の失敗は再試行しても解決しないため即座に返す
+// 戻った時点で開いたままの接続はない
+func WaitForDatabase(ctx context.Context, config *DatabaseConfig, opts WaitOptions) error {
+	driver, err := NewPostgreSQLDriverWithConfig(config, append([]DriverOption{WithLogger(opts.Logger)}, opts.DriverOptions...)...)
+	if err != nil {
+		return err
+	}
+	target := fmt.Sprintf("%s:%d/%s", config.Host, config.Port, config.Database)
+
+	return waitForDatabase(ctx, target, opts, func(ctx context.Context) error {
+		db, _, err := driver.openPool(ctx)
+		if err != nil {
+			return err
+		}
+		return db.Close()
+	})
+}
+
+// waitForDatabase runs ping until it succeeds, fails authentication or ctx ends
+// waitForDatabase: pingが成功するか、認証に失敗するか、ctxが終了するまで実行する関数
+func waitForDatabase(ctx context.Context, target string, opts WaitOptions, ping func(ctx context.Context) error) error {
+	opts = opts.withDefaults()
+	start := time.Now()
+	var lastLogged time.Time // last logged: 最後に進捗をログ出力した時刻
+	backoff := opts.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, opts.AttemptTimeout)
+		err := ping(attemptCtx)
+		cancel()
+		if err == nil {
+			elapsed := time.Since(start).Round(time.Millisecond)
+			opts.Logger.Info(fmt.Sprintf("Database %s is ready after %d attempt(s) in %s", target, attempt, elapsed),
+				"target", target, "attempts", attempt, "elapsed", elapsed)
+			return nil
+		}
+		if IsAuthenticationError(err) {
+			return fmt.Errorf("database %s rejected the credentials, not retrying: %w", target, err)
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("database %s was not ready after %d attempt(s): %w", target, attempt, err)
+		}
+
+		// Report progress at the configured cadence rather than on every attempt
+		// 毎回ではなく設定された間隔で進捗を報告する
+		if lastLogged.IsZero() || time.Since(lastLogged) >= opts.LogInterval {
+			lastLogged = time.Now()
+			opts.Logger.Info(fmt.Sprintf("Waiting for database %s (attempt %d, %s elapsed): %v", target, attempt, time.Since(start).Round(time.Second), err),
+				"target", target, "attempt", attempt, "error", err.Error())
+		}
+
+		// Wait for the backoff unless the context ends first
+		// コンテキストが先に終了しない限りバックオフ時間だけ待つ
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("database %s was not ready after %d attempt(s): %w", target, attempt, err)
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > opts.MaxBackoff {
+			backoff = opts.MaxBackoff
+		}
+	}
+}
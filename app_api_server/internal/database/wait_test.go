@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context" // context: コンテキスト
+	"errors"  // errors: エラー操作
+	"fmt"     // fmt: フォーマット
+	"strings" // strings: 文字列操作
+	"syscall" // syscall: システムコールのエラー番号
+	"testing" // testing: テスト機能
+	"time"    // time: 時間操作機能
+
+	"github.com/lib/pq" // pq: PostgreSQLドライバー、エラー型
+)
+
+// TestWaitForDatabaseClassification tests that authentication failures fail fast and refused connections are retried
+// TestWaitForDatabaseClassification: 認証の失敗は即座に失敗し、接続拒否は再試行されることをテストする関数
+func TestWaitForDatabaseClassification(t *testing.T) {
+	refused := fmt.Errorf("dial tcp 127.0.0.1:5432: %w", syscall.ECONNREFUSED)
+	authFailed := &pq.Error{Code: "28P01", Message: `password authentication failed for user "testuser"`}
+
+	testCases := []struct {
+		name             string
+		failures         []error // failures: 成功するまでに返すエラー
+		expectError      bool
+		errorContent     string
+		expectedAttempts int
+	}{
+		{name: "Ready at once", expectedAttempts: 1},
+		{name: "Refused then ready", failures: []error{refused, refused}, expectedAttempts: 3},
+		{name: "Authentication failure", failures: []error{authFailed, authFailed}, expectError: true, errorContent: "rejected the credentials, not retrying", expectedAttempts: 1},
+		{name: "Refused then authentication failure", failures: []error{refused, authFailed}, expectError: true, errorContent: "password authentication failed", expectedAttempts: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			attempts := 0
+			ping := func(context.Context) error {
+				attempts++
+				if attempts <= len(tc.failures) {
+					return tc.failures[attempts-1]
+				}
+				return nil
+			}
+			opts := WaitOptions{InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond, Logger: &capturingLogger{}}
+
+			err := waitForDatabase(context.Background(), "localhost:5432/testdb", opts, ping)
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				if !IsAuthenticationError(err) {
+					t.Errorf("Expected the authentication error to stay detectable, got: %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+			if attempts != tc.expectedAttempts {
+				t.Errorf("Expected %d attempts, got: %d", tc.expectedAttempts, attempts)
+			}
+		})
+	}
+}
+
+// TestWaitForDatabaseContextExpiry tests that waiting stops when ctx ends and the last failure is returned
+// TestWaitForDatabaseContextExpiry: ctxの終了で待機を止め、最後の失敗を返すことをテストする関数
+func TestWaitForDatabaseContextExpiry(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	logger := &capturingLogger{}
+	opts := WaitOptions{InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, LogInterval: time.Hour, Logger: logger}
+
+	err := waitForDatabase(ctx, "localhost:5432/testdb", opts, func(context.Context) error { return syscall.ECONNREFUSED })
+	if !errors.Is(err, syscall.ECONNREFUSED) || !strings.Contains(err.Error(), "was not ready after") {
+		t.Errorf("Expected the last connection error, got: %v", err)
+	}
+
+	// The progress is logged on the first failure and then only every LogInterval
+	// 進捗は最初の失敗時と、その後はLogInterval毎にのみログ出力される
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.entries) != 1 || !strings.Contains(logger.entries[0].msg, "Waiting for database localhost:5432/testdb (attempt 1") {
+		t.Errorf("Expected a single progress entry, got: %+v", logger.entries)
+	}
+}
+
+// TestWaitForDatabaseInvalidConfig tests that an invalid configuration is rejected without waiting
+// TestWaitForDatabaseInvalidConfig: 無効な設定が待機せずに拒否されることをテストする関数
+func TestWaitForDatabaseInvalidConfig(t *testing.T) {
+	err := WaitForDatabase(context.Background(), &DatabaseConfig{Host: "localhost", Port: 5432}, WaitOptions{})
+	if err == nil || !strings.Contains(err.Error(), "invalid database configuration") {
+		t.Errorf("Expected an invalid configuration error, got: %v", err)
+	}
+}
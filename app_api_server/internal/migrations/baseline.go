@@ -0,0 +1,179 @@
+package migrations
+
+import (
+	"context" // context: コンテキスト
+	"errors"  // errors: エラー操作
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"io/fs"   // fs: file system（ファイルシステム）、マイグレーションファイルの読み込み
+	"path"    // path: 読み込み元の中のファイルのパス
+	"regexp"  // regexp: CREATE TABLEとDROP TABLEの検出
+	"slices"  // slices: スライス操作
+	"strings" // strings: 文字列操作
+
+	"api/internal/database" // database: テーブルの存在確認
+)
+
+// ErrAlreadyVersioned is returned by Baseline when the database already records a version and force is not set
+// ErrAlreadyVersioned: データベースに既にバージョンが記録されており、forceが指定されていない場合にBaselineが返すエラー
+var ErrAlreadyVersioned = errors.New("database already records a migration version")
+
+// Table patterns over a migration file with its -- comments removed; a name may be schema-qualified and quoted
+// コメント（--）を除いたマイグレーションファイルに対するテーブルのパターン、名前はスキーマ修飾と引用符を含みうる
+var (
+	tableNamePattern   = `((?:"[^"]+"|[A-Za-z_][A-Za-z0-9_$]*)(?:\s*\.\s*(?:"[^"]+"|[A-Za-z_][A-Za-z0-9_$]*))?)`
+	createTablePattern = regexp.MustCompile(`(?i)\bCREATE\s+(?:UNLOGGED\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?` + tableNamePattern)
+	dropTablePattern   = regexp.MustCompile(`(?i)\bDROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?` + tableNamePattern)
+	sqlCommentPattern  = regexp.MustCompile(`--[^\n]*`)
+)
+
+// Table is a table a migration creates; an empty Schema means the connection's current schema
+// Table: マイグレーションが作成するテーブル、Schemaが空の場合は接続の現在のスキーマ
+type Table struct {
+	Schema string `json:"schema,omitempty"` // schema: スキーマ
+	Name   string `json:"name"`             // name: テーブル名
+}
+
+// String returns the table as schema.name, or name alone when unqualified
+// String: テーブルをschema.name、修飾なしの場合はnameのみで返す関数
+func (t Table) String() string {
+	if t.Schema == "" {
+		return t.Name
+	}
+	return t.Schema + "." + t.Name
+}
+
+// parseTable splits a possibly qualified name, folding unquoted parts to lower case as PostgreSQL does
+// parseTable: 修飾されうる名前を分割する関数、PostgreSQLと同じく引用符のない部分は小文字にする
+func parseTable(name string) Table {
+	var parts []string
+	for _, part := range strings.Split(name, ".") {
+		part = strings.TrimSpace(part)
+		if unquoted, ok := strings.CutPrefix(part, `"`); ok {
+			parts = append(parts, strings.TrimSuffix(unquoted, `"`))
+		} else {
+			parts = append(parts, strings.ToLower(part))
+		}
+	}
+	if len(parts) == 1 {
+		return Table{Name: parts[0]}
+	}
+	return Table{Schema: parts[0], Name: parts[1]}
+}
+
+// KeyTables lists the tables the up migrations up to version leave behind: each CREATE TABLE, less the ones a later
+// migration drops
+// KeyTables: version以下のupマイグレーションが残すテーブルを列挙する関数、各CREATE TABLEから後のマイグレーションが削除したものを除く
+func KeyTables(source Source, migrations []Migration, version uint) ([]Table, error) {
+	var tables []Table
+	for _, migration := range migrations {
+		if migration.Version > version {
+			break
+		}
+		contents, err := fs.ReadFile(source.fsys, path.Join(source.root, migration.File))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", migration.File, err)
+		}
+		script := sqlCommentPattern.ReplaceAllString(string(contents), "")
+		for _, match := range createTablePattern.FindAllStringSubmatch(script, -1) {
+			if table := parseTable(match[1]); !slices.Contains(tables, table) {
+				tables = append(tables, table)
+			}
+		}
+		for _, match := range dropTablePattern.FindAllStringSubmatch(script, -1) {
+			dropped := parseTable(match[1])
+			tables = slices.DeleteFunc(tables, func(t Table) bool { return t == dropped })
+		}
+	}
+	return tables, nil
+}
+
+// BaselineResult reports what Baseline recorded
+// BaselineResult: Baselineが記録した内容の報告
+type BaselineResult struct {
+	Version       uint     `json:"version"`        // version: 記録したバージョン
+	Previous      uint     `json:"previous"`       // previous: 置き換えたバージョン、0は未記録
+	PreviousDirty bool     `json:"previous_dirty"` // previous dirty: 置き換えたバージョンがdirtyだったか
+	Missing       []string `json:"missing"`        // missing: 存在しなかったテーブル
+}
+
+// Baseline records version in target without running any SQL, adopting a schema built before the migrations existed;
+// driver reaches the same database and checks its tables
+// Baseline: SQLを実行せずにtargetにversionを記録する関数、マイグレーション導入前に構築したスキーマを取り込む。driverは同じデータベースでテーブルを確認する
+// It refuses a database that already records a version unless force is set, then checks the KeyTables with
+// EnsureSchema; missing tables are reported in the result, not treated as errors. The checksums of the baselined
+// migrations are recorded so later edits show up as drift
+// forceが指定されない限り既にバージョンを記録したデータベースを拒否し、次にKeyTablesをEnsureSchemaで確認する。
+// 存在しないテーブルはエラーとせずに結果で報告する。取り込んだマイグレーションのチェックサムを記録し、後の編集をドリフトとして検出する
+func Baseline(ctx context.Context, driver *database.PostgreSQLDriver, target Database, source Source, list []Migration, version uint, force bool) (BaselineResult, error) {
+	result := BaselineResult{Version: version, Missing: []string{}}
+	if err := CheckTarget(list, version); err != nil {
+		return result, err
+	}
+
+	m, err := Open(target, source)
+	if err != nil {
+		return result, err
+	}
+	defer m.Close()
+	result.Previous, result.PreviousDirty, err = CurrentVersion(m)
+	if err != nil {
+		return result, err
+	}
+	if (result.Previous > 0 || result.PreviousDirty) && !force {
+		return result, fmt.Errorf("%w (version %d); use up to apply the rest, or pass --force to overwrite it", ErrAlreadyVersioned, result.Previous)
+	}
+
+	result.Missing, err = missingKeyTables(ctx, driver, source, list, version)
+	if err != nil {
+		return result, err
+	}
+	if err := m.Force(int(version)); err != nil {
+		return result, fmt.Errorf("failed to record version %d: %w", version, err)
+	}
+	return result, recordChecksums(ctx, target, between(list, 0, version), nil)
+}
+
+// missingKeyTables returns the KeyTables up to version that do not exist, checking each schema with EnsureSchema
+// missingKeyTables: version以下のKeyTablesのうち存在しないものを返す関数、スキーマごとにEnsureSchemaで確認する
+func missingKeyTables(ctx context.Context, driver *database.PostgreSQLDriver, source Source, list []Migration, version uint) ([]string, error) {
+	tables, err := KeyTables(source, list, version)
+	if err != nil {
+		return nil, err
+	}
+	var current string
+	if err := driver.QueryRowContext(ctx, "SELECT current_schema()").Scan(&current); err != nil {
+		return nil, fmt.Errorf("failed to read the current schema: %w", err)
+	}
+
+	var schemas []string
+	bySchema := map[string][]string{}
+	for _, table := range tables {
+		schema := table.Schema
+		if schema == "" {
+			schema = current
+		}
+		if _, ok := bySchema[schema]; !ok {
+			schemas = append(schemas, schema)
+		}
+		bySchema[schema] = append(bySchema[schema], table.Name)
+	}
+
+	missing := []string{}
+	for _, schema := range schemas {
+		err := driver.EnsureSchema(ctx, schema, bySchema[schema]...)
+		if errors.Is(err, database.ErrMissingTables) {
+			for _, name := range bySchema[schema] {
+				if ok, err := driver.HasTable(ctx, schema, name); err != nil {
+					return nil, err
+				} else if !ok {
+					missing = append(missing, schema+"."+name)
+				}
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return missing, nil
+}
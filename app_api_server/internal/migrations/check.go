@@ -0,0 +1,168 @@
+package migrations
+
+import (
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"io/fs"   // fs: file system（ファイルシステム）、読み込み元のディレクトリの読み込み
+	"path"    // path: 読み込み元の中のファイルのパス
+	"sort"    // sort: 問題の並べ替え
+	"strconv" // strconv: string conversion（文字列変換）
+
+	"api/internal/database" // database: SQLの試験的な解析
+)
+
+// Exit codes the check command adds to ExitPending and ExitDirty
+// checkコマンドがExitPendingとExitDirtyに加える終了コード
+const (
+	ExitDrift   = 3 // drift: 適用済みのマイグレーションが適用後に変更された
+	ExitInvalid = 4 // invalid: マイグレーションのディレクトリに問題がある
+)
+
+// Problem is one thing wrong with a migrations directory
+// Problem: マイグレーションのディレクトリの1つの問題
+type Problem struct {
+	File    string `json:"file"`    // file: 問題のあるファイル
+	Message string `json:"message"` // message: 問題の説明
+}
+
+// String describes the problem as file: message
+// String: 問題をfile: messageの形で説明する関数
+func (p Problem) String() string {
+	return p.File + ": " + p.Message
+}
+
+// migrationPair is the up and down files of one version, as CheckSource finds them
+// migrationPair: CheckSourceが見つけた1つのバージョンのupとdownのファイル
+type migrationPair struct {
+	up, down           string // up, down: ファイル名、ない場合は空
+	upTitle, downTitle string // up title, down title: ファイル名のタイトル
+}
+
+// CheckSource validates source without a database and returns its problems in file order: files misnamed, a version
+// used twice, up files whose versions do not increase in file name order, an up without a down or a down without an
+// up, a pair whose titles differ, a gap in sequential versions, and SQL that database.CheckScript cannot parse
+// CheckSource: データベースなしでsourceを検証し、問題をファイル順に返す関数。名前の誤ったファイル、2回使われたバージョン、
+// ファイル名順にバージョンが増加しないupファイル、downのないupまたはupのないdown、タイトルの異なる組、連番のバージョンの欠番、
+// database.CheckScriptが解析できないSQLを報告する
+// Gaps are only problems for sequential versions; timestamp versions always leave them
+// 欠番は連番のバージョンの場合のみ問題とする、タイムスタンプのバージョンには常に欠番がある
+func CheckSource(source Source) ([]Problem, error) {
+	entries, err := fs.ReadDir(source.fsys, source.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	problems := []Problem{}
+	pairs := map[uint]*migrationPair{}
+	sequential := true
+	var last uint       // last: ファイル名順で最大のupのバージョン
+	var lastFile string // last file: そのupファイル
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(name)
+		if match == nil {
+			if path.Ext(name) == ".sql" {
+				problems = append(problems, Problem{File: name, Message: "not named {version}_{title}.up.sql or {version}_{title}.down.sql"})
+			}
+			continue
+		}
+		parsed, err := strconv.ParseUint(match[1], 10, 0)
+		if err != nil {
+			problems = append(problems, Problem{File: name, Message: fmt.Sprintf("invalid version %s: %v", match[1], err)})
+			continue
+		}
+		version := uint(parsed)
+		if len(match[1]) != sequentialDigits {
+			sequential = false
+		}
+
+		pair := pairs[version]
+		if pair == nil {
+			pair = &migrationPair{}
+			pairs[version] = pair
+		}
+		file, title := &pair.up, &pair.upTitle
+		if match[3] == "down" {
+			file, title = &pair.down, &pair.downTitle
+		}
+		if *file != "" {
+			problems = append(problems, Problem{File: name, Message: fmt.Sprintf("version %d is already used by %s", version, *file)})
+			continue
+		}
+		*file, *title = name, match[2]
+
+		if match[3] == "up" {
+			if version < last {
+				problems = append(problems, Problem{File: name, Message: fmt.Sprintf("version %d sorts after version %d (%s) by file name; pad the versions to one width", version, last, lastFile)})
+			} else {
+				last, lastFile = version, name
+			}
+		}
+
+		if path.Ext(name) == ".sql" {
+			contents, err := fs.ReadFile(source.fsys, path.Join(source.root, name))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+			}
+			if err := database.CheckScript(string(contents)); err != nil {
+				problems = append(problems, Problem{File: name, Message: fmt.Sprintf("does not parse: %v", err)})
+			}
+		}
+	}
+
+	versions := make([]uint, 0, len(pairs))
+	for version := range pairs {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	for i, version := range versions {
+		pair := pairs[version]
+		switch {
+		case pair.up == "":
+			problems = append(problems, Problem{File: pair.down, Message: "has no up migration"})
+		case pair.down == "":
+			problems = append(problems, Problem{File: pair.up, Message: "has no down migration"})
+		case pair.upTitle != pair.downTitle:
+			problems = append(problems, Problem{File: pair.down, Message: "title does not match the up migration " + pair.up})
+		}
+		if !sequential || i == 0 || version == versions[i-1]+1 {
+			continue
+		}
+		file := pair.up
+		if file == "" {
+			file = pair.down
+		}
+		missing := fmt.Sprintf("version %d is missing before it", version-1)
+		if version-1 > versions[i-1]+1 {
+			missing = fmt.Sprintf("versions %d to %d are missing before it", versions[i-1]+1, version-1)
+		}
+		problems = append(problems, Problem{File: file, Message: missing})
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].File < problems[j].File })
+	return problems, nil
+}
+
+// CheckCode returns what check exits with for the status: ExitDrift, ExitDirty, ExitPending or ExitUpToDate, in that
+// order of precedence
+// CheckCode: 状態に対してcheckが返す終了コードを返す関数、ExitDrift、ExitDirty、ExitPending、ExitUpToDateの順の優先度
+func (s Status) CheckCode() int {
+	if s.Modified > 0 {
+		return ExitDrift
+	}
+	return s.ExitCode()
+}
+
+// Versions lists the versions of the migrations in state, in version order
+// Versions: stateのマイグレーションのバージョンをバージョン順に列挙する関数
+func (s Status) Versions(state State) []uint {
+	versions := []uint{}
+	for _, migration := range s.Migrations {
+		if migration.State == state {
+			versions = append(versions, migration.Version)
+		}
+	}
+	return versions
+}
@@ -0,0 +1,105 @@
+package migrations
+
+import (
+	"reflect" // reflect: 値の比較
+	"testing" // testing: テスト機能
+)
+
+// TestCheckSource tests the source-only validation against a valid fixture set and one broken set per problem
+// TestCheckSource: 正しいフィクスチャの組と、問題ごとに1つの壊れた組に対するデータベースなしの検証をテストする関数
+func TestCheckSource(t *testing.T) {
+	testCases := []struct {
+		name     string
+		dir      string
+		expected []Problem
+	}{
+		{name: "Valid", dir: "testdata/check/valid", expected: []Problem{}},
+		{name: "Timestamp versions leave gaps", dir: "testdata/check/timestamps", expected: []Problem{}},
+		{name: "Up without a down", dir: "testdata/check/missing_down", expected: []Problem{
+			{File: "000002_add_check_items_note.up.sql", Message: "has no down migration"},
+		}},
+		{name: "Down without an up", dir: "testdata/check/missing_up", expected: []Problem{
+			{File: "000002_add_check_items_note.down.sql", Message: "has no up migration"},
+		}},
+		{name: "Titles differ", dir: "testdata/check/title_mismatch", expected: []Problem{
+			{File: "000001_create_check_entries.down.sql", Message: "title does not match the up migration 000001_create_check_items.up.sql"},
+		}},
+		{name: "Version used twice", dir: "testdata/check/duplicate", expected: []Problem{
+			{File: "1_create_check_entries.down.sql", Message: "version 1 is already used by 000001_create_check_items.down.sql"},
+			{File: "1_create_check_entries.up.sql", Message: "version 1 is already used by 000001_create_check_items.up.sql"},
+		}},
+		{name: "Versions out of file name order", dir: "testdata/check/unordered", expected: []Problem{
+			{File: "9_create_check_items.up.sql", Message: "version 9 sorts after version 10 (10_add_check_items_note.up.sql) by file name; pad the versions to one width"},
+		}},
+		{name: "Gap in sequential versions", dir: "testdata/check/gap", expected: []Problem{
+			{File: "000004_add_check_items_note.up.sql", Message: "versions 2 to 3 are missing before it"},
+		}},
+		{name: "SQL that does not parse", dir: "testdata/check/unparseable", expected: []Problem{
+			{File: "000001_create_check_items.up.sql", Message: "does not parse: line 3: unterminated string literal"},
+			{File: "000002_add_check_items_note.up.sql", Message: "does not parse: line 1: ( is not closed before the end of the statement"},
+		}},
+		{name: "Misnamed file", dir: "testdata/check/misnamed", expected: []Problem{
+			{File: "000002_add_check_items_note.sql", Message: "not named {version}_{title}.up.sql or {version}_{title}.down.sql"},
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			problems, err := CheckSource(Directory(tc.dir))
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(problems, tc.expected) {
+				t.Errorf("Expected %v, got: %v", tc.expected, problems)
+			}
+		})
+	}
+
+	// The application's own migrations pass: アプリケーション自体のマイグレーションは問題がない
+	if problems, err := CheckSource(Embedded()); err != nil || len(problems) > 0 {
+		t.Errorf("Expected the embedded migrations to pass, got: %v, %v", problems, err)
+	}
+	if _, err := CheckSource(Directory("testdata/check/missing")); err == nil {
+		t.Errorf("Expected an error for a missing directory")
+	}
+}
+
+// TestStatusCheckCode tests check's exit code for a status, drift taking precedence over dirty and pending
+// TestStatusCheckCode: 状態に対するcheckの終了コードをテストする関数、変更はdirtyと未適用より優先する
+func TestStatusCheckCode(t *testing.T) {
+	list, err := Load(Directory("testdata/gaps"))
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	modified := map[uint]string{3: "changed"}
+
+	testCases := []struct {
+		name             string
+		version          uint
+		dirty            bool
+		recorded         map[uint]string
+		expected         int
+		expectedPending  []uint
+		expectedModified []uint
+	}{
+		{name: "Up to date", version: 8, expected: ExitUpToDate, expectedPending: []uint{}, expectedModified: []uint{}},
+		{name: "Pending", version: 3, expected: ExitPending, expectedPending: []uint{5, 8}, expectedModified: []uint{}},
+		{name: "Dirty", version: 5, dirty: true, expected: ExitDirty, expectedPending: []uint{8}, expectedModified: []uint{}},
+		{name: "Drift", version: 5, dirty: true, recorded: modified, expected: ExitDrift, expectedPending: []uint{8}, expectedModified: []uint{3}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := NewStatus(list, tc.version, tc.dirty, tc.recorded)
+			if code := status.CheckCode(); code != tc.expected {
+				t.Errorf("Expected exit code %d, got: %d", tc.expected, code)
+			}
+			if pending := status.Versions(StatePending); !reflect.DeepEqual(pending, tc.expectedPending) {
+				t.Errorf("Expected pending %v, got: %v", tc.expectedPending, pending)
+			}
+			if changed := status.Versions(StateModified); !reflect.DeepEqual(changed, tc.expectedModified) {
+				t.Errorf("Expected modified %v, got: %v", tc.expectedModified, changed)
+			}
+		})
+	}
+}
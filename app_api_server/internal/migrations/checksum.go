@@ -0,0 +1,190 @@
+package migrations
+
+import (
+	"context"       // context: コンテキスト
+	"crypto/sha256" // sha256: マイグレーションファイルのチェックサム
+	"database/sql"  // sql: 未記録の時間のNULL
+	"encoding/hex"  // hex: チェックサムの16進数表記
+	"errors"        // errors: エラー操作
+	"fmt"           // fmt: format（フォーマット）、文字列フォーマット機能
+	"strings"       // strings: 文字列操作
+	"time"          // time: マイグレーションにかかった時間
+
+	"github.com/lib/pq" // pq: テーブル名のクォート
+)
+
+// DefaultChecksumsTable records the checksum of each migration applied through sift-migrate or the server
+// DefaultChecksumsTable: sift-migrateまたはサーバーが適用した各マイグレーションのチェックサムを記録するテーブル
+// It sits beside schema_migrations and is created the first time a checksum is recorded
+// schema_migrationsと同じスキーマに置き、最初にチェックサムを記録する時に作成する
+const DefaultChecksumsTable = "sift_migration_checksums"
+
+// ErrChecksumDrift is returned when an applied migration's file no longer matches the checksum recorded when it ran
+// ErrChecksumDrift: 適用済みのマイグレーションのファイルが、実行時に記録したチェックサムと一致しなくなった場合のエラー
+var ErrChecksumDrift = errors.New("migration modified after apply")
+
+// Drift is an applied migration whose file changed since it was applied
+// Drift: 適用後にファイルが変更された適用済みのマイグレーション
+type Drift struct {
+	Migration
+	Recorded string `json:"recorded"` // recorded: 適用時に記録したチェックサム
+}
+
+// checksum returns the hex SHA-256 of a migration file's contents
+// checksum: マイグレーションファイルの内容のSHA-256を16進数で返す関数
+func checksum(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
+
+// checksumsTable returns target's checksums table, quoted for SQL
+// checksumsTable: targetのチェックサムのテーブルをSQL用にクォートして返す関数
+func (target Database) checksumsTable() string {
+	if target.ChecksumsTable != "" {
+		return pq.QuoteIdentifier(target.ChecksumsTable)
+	}
+	return pq.QuoteIdentifier(DefaultChecksumsTable)
+}
+
+// ReadChecksums returns the recorded checksums by version; a table not created yet means none were recorded
+// ReadChecksums: 記録されたチェックサムをバージョンごとに返す関数、テーブルがまだ作成されていなければ記録なし
+func ReadChecksums(ctx context.Context, target Database) (map[uint]string, error) {
+	recorded := map[uint]string{}
+	var exists bool
+	if err := target.DB.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", target.checksumsTable()).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up the checksums table: %w", err)
+	}
+	if !exists {
+		return recorded, nil
+	}
+
+	rows, err := target.DB.QueryContext(ctx, "SELECT version, checksum FROM "+target.checksumsTable())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration checksums: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int64
+		var sum string
+		if err := rows.Scan(&version, &sum); err != nil {
+			return nil, fmt.Errorf("failed to read migration checksums: %w", err)
+		}
+		recorded[uint(version)] = sum
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read migration checksums: %w", err)
+	}
+	return recorded, nil
+}
+
+// FindDrift returns the migrations up to version whose checksum differs from the one recorded
+// FindDrift: version以下のマイグレーションのうち、チェックサムが記録と異なるものを返す関数
+// Migrations without a recorded checksum, such as ones applied before tracking began, are not drift
+// 記録されたチェックサムがないマイグレーション（記録を始める前に適用したものなど）はドリフトとみなさない
+func FindDrift(migrations []Migration, version uint, recorded map[uint]string) []Drift {
+	var drift []Drift
+	for _, migration := range migrations {
+		if migration.Version > version {
+			break
+		}
+		if sum, ok := recorded[migration.Version]; ok && sum != migration.Checksum {
+			drift = append(drift, Drift{Migration: migration, Recorded: sum})
+		}
+	}
+	return drift
+}
+
+// checkDrift reads the recorded checksums and refuses when any migration up to version was modified after apply
+// checkDrift: 記録されたチェックサムを読み、version以下のマイグレーションが適用後に変更されていれば拒否する関数
+func checkDrift(ctx context.Context, target Database, migrations []Migration, version uint) error {
+	recorded, err := ReadChecksums(ctx, target)
+	if err != nil {
+		return err
+	}
+	drift := FindDrift(migrations, version, recorded)
+	if len(drift) == 0 {
+		return nil
+	}
+	files := make([]string, len(drift))
+	for i, d := range drift {
+		files[i] = d.File
+	}
+	return fmt.Errorf("%w: %s", ErrChecksumDrift, strings.Join(files, ", "))
+}
+
+// refuseDrift is checkDrift for the commands that apply migrations, naming the flag that overrides it
+// refuseDrift: マイグレーションを適用するコマンド用のcheckDrift、上書きするフラグを案内する
+func refuseDrift(ctx context.Context, target Database, migrations []Migration, version uint) error {
+	err := checkDrift(ctx, target, migrations, version)
+	if errors.Is(err, ErrChecksumDrift) {
+		return fmt.Errorf("%w; restore the files, or pass --ignore-checksum-drift to apply anyway", err)
+	}
+	return err
+}
+
+// recordChecksums stores the checksum of each migration with how long it took from timings, creating the table the
+// first time; a migration missing from timings, such as a baselined one, records no duration
+// recordChecksums: 各マイグレーションのチェックサムをtimingsのかかった時間と共に保存する関数、初回はテーブルを作成する。
+// ベースラインなどtimingsにないマイグレーションは時間を記録しない
+func recordChecksums(ctx context.Context, target Database, migrations []Migration, timings map[uint]time.Duration) error {
+	if len(migrations) == 0 {
+		return nil
+	}
+	table := target.checksumsTable()
+	_, err := target.DB.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+table+` (
+		version BIGINT PRIMARY KEY,
+		checksum CHAR(64) NOT NULL,
+		applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		duration_ms BIGINT
+	)`)
+	if err == nil {
+		// Tables created before durations were recorded gain the column: 時間を記録する前に作成したテーブルに列を追加する
+		_, err = target.DB.ExecContext(ctx, `ALTER TABLE `+table+` ADD COLUMN IF NOT EXISTS duration_ms BIGINT`)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create the checksums table: %w", err)
+	}
+	for _, migration := range migrations {
+		var duration sql.NullInt64
+		if elapsed, ok := timings[migration.Version]; ok {
+			duration = sql.NullInt64{Int64: elapsed.Milliseconds(), Valid: true}
+		}
+		_, err := target.DB.ExecContext(ctx, `INSERT INTO `+table+` (version, checksum, duration_ms) VALUES ($1, $2, $3)
+			ON CONFLICT (version) DO UPDATE SET checksum = EXCLUDED.checksum, applied_at = CURRENT_TIMESTAMP, duration_ms = EXCLUDED.duration_ms`,
+			int64(migration.Version), migration.Checksum, duration)
+		if err != nil {
+			return fmt.Errorf("failed to record the checksum of migration %d: %w", migration.Version, err)
+		}
+	}
+	return nil
+}
+
+// forgetChecksums removes the checksums of reverted migrations, so applying them again records their files afresh
+// forgetChecksums: 元に戻したマイグレーションのチェックサムを削除する関数、再適用時にファイルを改めて記録する
+func forgetChecksums(ctx context.Context, target Database, migrations []Migration) error {
+	recorded, err := ReadChecksums(ctx, target)
+	if err != nil {
+		return err
+	}
+	for _, migration := range migrations {
+		if _, ok := recorded[migration.Version]; !ok {
+			continue
+		}
+		if _, err := target.DB.ExecContext(ctx, "DELETE FROM "+target.checksumsTable()+" WHERE version = $1", int64(migration.Version)); err != nil {
+			return fmt.Errorf("failed to remove the checksum of migration %d: %w", migration.Version, err)
+		}
+	}
+	return nil
+}
+
+// between returns the migrations above from and at most to, in version order
+// between: fromより大きくto以下のマイグレーションをバージョン順に返す関数
+func between(migrations []Migration, from, to uint) []Migration {
+	var selected []Migration
+	for _, migration := range migrations {
+		if migration.Version > from && migration.Version <= to {
+			selected = append(selected, migration)
+		}
+	}
+	return selected
+}
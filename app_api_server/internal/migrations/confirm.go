@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"bufio"   // bufio: 回答の1行の読み込み
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"io"      // io: 入出力
+	"os"      // os: operating system（オペレーティングシステム）、端末の判定
+	"strings" // strings: 文字列操作
+)
+
+// Interactive reports whether f is a terminal a person can answer a prompt on
+// Interactive: fが人がプロンプトに答えられる端末かどうかを返す関数
+func Interactive(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Confirm writes prompt to out and reports whether the line read from in is expected
+// Confirm: promptをoutに書き出し、inから読んだ行がexpectedと一致するかを返す関数
+// Surrounding spaces are ignored; anything else, including no answer, is a refusal
+// 前後の空白は無視する、それ以外の違いは回答がない場合も含めて拒否とみなす
+func Confirm(in io.Reader, out io.Writer, prompt, expected string) (bool, error) {
+	if _, err := fmt.Fprint(out, prompt); err != nil {
+		return false, err
+	}
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("failed to read the answer: %w", err)
+	}
+	return strings.TrimSpace(answer) == expected, nil
+}
@@ -0,0 +1,222 @@
+package migrations
+
+import (
+	"errors"        // errors: エラー操作
+	"fmt"           // fmt: format（フォーマット）、文字列フォーマット機能
+	"io/fs"         // fs: file system（ファイルシステム）、ディレクトリの項目
+	"os"            // os: operating system（オペレーティングシステム）、実際のファイルシステム
+	"path/filepath" // filepath: パス操作
+	"regexp"        // regexp: ファイル名とテーブル名の検証
+	"sort"          // sort: テンプレート名の並べ替え
+	"strconv"       // strconv: string conversion（文字列変換）
+	"strings"       // strings: 文字列操作
+	"text/template" // template: マイグレーションファイルのテンプレート
+	"time"          // time: タイムスタンプの番号
+)
+
+// FileSystem is the part of a file system Create needs, so tests can use an in-memory one
+// FileSystem: Createが必要とするファイルシステムの機能、テストではメモリ上の実装を使える
+type FileSystem interface {
+	ReadDir(name string) ([]fs.DirEntry, error)                  // ReadDir: ディレクトリの項目を読む
+	CreateFile(name string, data []byte, perm fs.FileMode) error // CreateFile: 新しいファイルを作る、既に存在すればエラー
+}
+
+// OSFileSystem is the real file system; CreateFile never overwrites an existing file
+// OSFileSystem: 実際のファイルシステム、CreateFileは既存のファイルを上書きしない
+type OSFileSystem struct{}
+
+// ReadDir reads the directory with os.ReadDir
+// ReadDir: os.ReadDirでディレクトリを読む関数
+func (OSFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+// CreateFile writes data to a new file, failing with fs.ErrExist when the file is already there
+// CreateFile: 新しいファイルにdataを書き込む関数、ファイルが既にあればfs.ErrExistで失敗する
+func (OSFileSystem) CreateFile(name string, data []byte, perm fs.FileMode) error {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Numbering is how Create picks the version of a new migration
+// Numbering: Createが新しいマイグレーションのバージョンを決める方法
+type Numbering string
+
+// Numbering schemes
+// 番号の付け方
+const (
+	NumberingTimestamp  Numbering = "timestamp"  // timestamp: UTCの20060102150405形式
+	NumberingSequential Numbering = "sequential" // sequential: 最大のバージョン+1、6桁で0埋め
+)
+
+// timestampLayout is the UTC layout of timestamp versions
+// timestampLayout: タイムスタンプのバージョンのUTCレイアウト
+const timestampLayout = "20060102150405"
+
+// sequentialDigits is the zero padding of sequential versions, golang-migrate's default
+// sequentialDigits: 連番のバージョンの0埋めの桁数、golang-migrateのデフォルト
+const sequentialDigits = 6
+
+// DefaultSchema is the application schema the templates create tables in
+// DefaultSchema: テンプレートがテーブルを作成するアプリケーションのスキーマ
+const DefaultSchema = "app"
+
+// migrationFilePattern matches up and down migration file names
+// migrationFilePattern: upとdownのマイグレーションファイル名に一致する正規表現
+var migrationFilePattern = regexp.MustCompile(`^([0-9]+)_(.*)\.(up|down)\.[^.]+$`)
+
+// identifierPattern matches the unquoted table names templates accept
+// identifierPattern: テンプレートが受け付ける引用符なしのテーブル名に一致する正規表現
+var identifierPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// nonSlugPattern matches the runs of characters Slugify replaces with one underscore
+// nonSlugPattern: Slugifyが1つのアンダースコアに置き換える文字の並びに一致する正規表現
+var nonSlugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// migrationTemplate is the up and down file contents of one template
+// migrationTemplate: 1つのテンプレートのupとdownのファイルの内容
+type migrationTemplate struct {
+	up        *template.Template // up: upファイルの内容
+	down      *template.Template // down: downファイルの内容
+	needTable bool               // need table: --tableが必要か
+}
+
+// templates are the named templates; "" is the blank pair used without --template
+// templates: 名前付きのテンプレート、""は--templateを指定しない場合の空のファイル
+var templates = map[string]migrationTemplate{
+	"": {
+		up:   template.Must(template.New("up").Parse("-- {{.Name}}\n")),
+		down: template.Must(template.New("down").Parse("-- Revert {{.Name}}\n")),
+	},
+	"create_table": {
+		up: template.Must(template.New("up").Parse(`-- Create table {{.Schema}}.{{.Table}}
+CREATE TABLE {{.Schema}}.{{.Table}} (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+`)),
+		down:      template.Must(template.New("down").Parse("DROP TABLE IF EXISTS {{.Schema}}.{{.Table}};\n")),
+		needTable: true,
+	},
+}
+
+// TemplateNames lists the names --template accepts
+// TemplateNames: --templateが受け付ける名前の一覧を返す関数
+func TemplateNames() []string {
+	var names []string
+	for name := range templates {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CreateOptions describes the migration to generate
+// CreateOptions: 生成するマイグレーションの設定
+type CreateOptions struct {
+	Name      string    // name: マイグレーションの名前、Slugifyで整形する
+	Numbering Numbering // numbering: 番号の付け方、空の場合はタイムスタンプ
+	Template  string    // template: テンプレート名、空の場合は空のファイル
+	Table     string    // table: create_tableテンプレートのテーブル名
+	Now       time.Time // now: タイムスタンプの番号に使う時刻、ゼロ値の場合は現在時刻
+}
+
+// Slugify lowercases name and joins its words with underscores, as golang-migrate file names expect
+// Slugify: nameを小文字にし、単語をアンダースコアでつなぐ関数、golang-migrateのファイル名の形式に合わせる
+func Slugify(name string) string {
+	return strings.Trim(nonSlugPattern.ReplaceAllString(strings.ToLower(name), "_"), "_")
+}
+
+// Create writes {version}_{slug}.up.sql and .down.sql into dir and returns their paths
+// Create: dirに{version}_{slug}.up.sqlと.down.sqlを書き込み、それらのパスを返す関数
+// It refuses a version or name the directory already has, so two contributors cannot collide silently
+// ディレクトリに既にあるバージョンや名前は拒否するため、2人の変更が気付かれずに衝突することはない
+func Create(fsys FileSystem, dir string, opts CreateOptions) ([]string, error) {
+	slug := Slugify(opts.Name)
+	if slug == "" {
+		return nil, fmt.Errorf("migration name %q has no letters or digits", opts.Name)
+	}
+	tmpl, ok := templates[opts.Template]
+	if !ok {
+		return nil, fmt.Errorf("unknown template %q (available: %s)", opts.Template, strings.Join(TemplateNames(), ", "))
+	}
+	if tmpl.needTable && !identifierPattern.MatchString(opts.Table) {
+		return nil, fmt.Errorf("template %s needs --table with a lowercase table name, got %q", opts.Template, opts.Table)
+	}
+	if !tmpl.needTable && opts.Table != "" {
+		return nil, errors.New("--table is only used with --template create_table")
+	}
+
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+	versions := map[uint64]string{}
+	var latest uint64
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		if match[2] == slug {
+			return nil, fmt.Errorf("a migration named %s already exists: %s", slug, entry.Name())
+		}
+		versions[version] = entry.Name()
+		latest = max(latest, version)
+	}
+
+	var version string
+	switch opts.Numbering {
+	case NumberingTimestamp, "":
+		now := opts.Now
+		if now.IsZero() {
+			now = time.Now()
+		}
+		version = now.UTC().Format(timestampLayout)
+	case NumberingSequential:
+		version = fmt.Sprintf("%0*d", sequentialDigits, latest+1)
+	default:
+		return nil, fmt.Errorf("unknown numbering %q (available: %s, %s)", opts.Numbering, NumberingTimestamp, NumberingSequential)
+	}
+	number, _ := strconv.ParseUint(version, 10, 64)
+	if other, ok := versions[number]; ok {
+		return nil, fmt.Errorf("version %s is already used by %s; wait a second or use sequential numbering", version, other)
+	}
+	if number < latest {
+		// golang-migrate would never apply a version below one already applied
+		// golang-migrateは適用済みのバージョンより小さいバージョンを適用しない
+		return nil, fmt.Errorf("version %s is before the latest version %d; check the clock or use sequential numbering", version, latest)
+	}
+
+	data := struct{ Name, Schema, Table string }{Name: strings.ReplaceAll(slug, "_", " "), Schema: DefaultSchema, Table: opts.Table}
+	var paths []string
+	for _, part := range []struct {
+		direction string
+		template  *template.Template
+	}{{"up", tmpl.up}, {"down", tmpl.down}} {
+		var content strings.Builder
+		if err := part.template.Execute(&content, data); err != nil {
+			return paths, fmt.Errorf("failed to render the %s migration: %w", part.direction, err)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%s_%s.%s.sql", version, slug, part.direction))
+		if err := fsys.CreateFile(path, []byte(content.String()), 0o644); err != nil {
+			return paths, fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
@@ -0,0 +1,122 @@
+package migrations
+
+import (
+	"io/fs"          // fs: file system（ファイルシステム）
+	"reflect"        // reflect: 値の比較
+	"strings"        // strings: 文字列操作
+	"testing"        // testing: テスト機能
+	"testing/fstest" // fstest: メモリ上のファイルシステム
+	"time"           // time: 時間操作機能
+)
+
+// memoryFileSystem is an in-memory FileSystem, so the tests never touch the real tree
+// memoryFileSystem: メモリ上のFileSystem、テストが実際のツリーに触れないようにする
+type memoryFileSystem struct {
+	files fstest.MapFS // files: ファイルのパスと内容
+}
+
+func (m memoryFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(m.files, name)
+}
+
+func (m memoryFileSystem) CreateFile(name string, data []byte, perm fs.FileMode) error {
+	if _, ok := m.files[name]; ok {
+		return fs.ErrExist
+	}
+	m.files[name] = &fstest.MapFile{Data: data, Mode: perm}
+	return nil
+}
+
+// TestCreate tests the generated file names, the duplicate refusals and the templates
+// TestCreate: 生成するファイル名、重複の拒否、テンプレートをテストする関数
+func TestCreate(t *testing.T) {
+	now := time.Date(2026, 10, 16, 18, 30, 5, 0, time.FixedZone("JST", 9*60*60))
+	existing := fstest.MapFS{
+		"migrations/000001_create_users.up.sql":      {},
+		"migrations/000001_create_users.down.sql":    {},
+		"migrations/000002_add_users_index.down.sql": {},
+		"migrations/README.md":                       {},
+	}
+
+	testCases := []struct {
+		name         string
+		files        fstest.MapFS
+		opts         CreateOptions
+		expected     []string
+		expectError  bool
+		errorContent string
+	}{
+		{name: "UTC timestamp", files: existing, opts: CreateOptions{Name: "Add Orders", Now: now},
+			expected: []string{"migrations/20261016093005_add_orders.up.sql", "migrations/20261016093005_add_orders.down.sql"}},
+		{name: "Sequential after a lone down file", files: existing, opts: CreateOptions{Name: "add-orders!", Numbering: NumberingSequential},
+			expected: []string{"migrations/000003_add_orders.up.sql", "migrations/000003_add_orders.down.sql"}},
+		{name: "Sequential in an empty directory", files: fstest.MapFS{"migrations": {Mode: fs.ModeDir}},
+			opts:     CreateOptions{Name: "init", Numbering: NumberingSequential},
+			expected: []string{"migrations/000001_init.up.sql", "migrations/000001_init.down.sql"}},
+		{name: "Duplicate name", files: existing, opts: CreateOptions{Name: "Create users", Now: now},
+			expectError: true, errorContent: "a migration named create_users already exists"},
+		{name: "Timestamp collision", files: fstest.MapFS{"migrations/20261016093005_other.up.sql": {}}, opts: CreateOptions{Name: "mine", Now: now},
+			expectError: true, errorContent: "version 20261016093005 is already used by 20261016093005_other.up.sql"},
+		{name: "Timestamp before the latest version", files: fstest.MapFS{"migrations/20300101000000_future.up.sql": {}}, opts: CreateOptions{Name: "mine", Now: now},
+			expectError: true, errorContent: "before the latest version 20300101000000"},
+		{name: "Name without letters", files: existing, opts: CreateOptions{Name: "--"}, expectError: true, errorContent: "has no letters or digits"},
+		{name: "Unknown numbering", files: existing, opts: CreateOptions{Name: "x", Numbering: "date"}, expectError: true, errorContent: `unknown numbering "date"`},
+		{name: "Unknown template", files: existing, opts: CreateOptions{Name: "x", Template: "view"}, expectError: true, errorContent: "available: create_table"},
+		{name: "Template without table", files: existing, opts: CreateOptions{Name: "x", Template: "create_table"}, expectError: true, errorContent: "needs --table"},
+		{name: "Table name with a quote", files: existing, opts: CreateOptions{Name: "x", Template: "create_table", Table: `orders"`},
+			expectError: true, errorContent: "needs --table"},
+		{name: "Table without template", files: existing, opts: CreateOptions{Name: "x", Table: "orders"}, expectError: true, errorContent: "only used with --template"},
+		{name: "Missing directory", files: fstest.MapFS{}, opts: CreateOptions{Name: "x"}, expectError: true, errorContent: "failed to read migrations directory"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := fstest.MapFS{}
+			for name, file := range tc.files {
+				files[name] = file
+			}
+			paths, err := Create(memoryFileSystem{files: files}, "migrations", tc.opts)
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				if len(files) != len(tc.files) {
+					t.Errorf("Expected no file to be created, got: %v", paths)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(paths, tc.expected) {
+				t.Errorf("Expected %v, got: %v", tc.expected, paths)
+			}
+			for _, path := range tc.expected {
+				if _, ok := files[path]; !ok {
+					t.Errorf("Expected %s to be written", path)
+				}
+			}
+		})
+	}
+}
+
+// TestCreateTableTemplate tests the create_table skeleton in the app schema
+// TestCreateTableTemplate: appスキーマのcreate_tableの雛形をテストする関数
+func TestCreateTableTemplate(t *testing.T) {
+	files := fstest.MapFS{"migrations": {Mode: fs.ModeDir}}
+	paths, err := Create(memoryFileSystem{files: files}, "migrations",
+		CreateOptions{Name: "create orders", Numbering: NumberingSequential, Template: "create_table", Table: "orders"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	up := string(files[paths[0]].Data)
+	for _, expected := range []string{"CREATE TABLE app.orders (", "created_at TIMESTAMP WITH TIME ZONE", "updated_at TIMESTAMP WITH TIME ZONE"} {
+		if !strings.Contains(up, expected) {
+			t.Errorf("Expected the up file to contain '%s', got: %s", expected, up)
+		}
+	}
+	if down := string(files[paths[1]].Data); down != "DROP TABLE IF EXISTS app.orders;\n" {
+		t.Errorf("Expected the down file to drop app.orders, got: %s", down)
+	}
+}
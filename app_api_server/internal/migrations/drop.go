@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"slices"  // slices: スライス操作
+	"strings" // strings: 文字列操作
+)
+
+// DropEnvironments are the APP_ENV values migrate drop runs in without an explicit override
+// DropEnvironments: 明示的な上書きなしでmigrate dropを実行できるAPP_ENVの値
+var DropEnvironments = []string{"test", "development"}
+
+// CheckDropAllowed refuses to drop unless appEnv is one of DropEnvironments or override is set
+// CheckDropAllowed: appEnvがDropEnvironmentsの1つでないか、overrideが指定されていない限り削除を拒否する関数
+// An unset APP_ENV is refused too, since a production host may simply not set it
+// 本番のホストが単に設定していない場合もあるため、APP_ENVが未設定の場合も拒否する
+func CheckDropAllowed(appEnv string, override bool) error {
+	env := strings.ToLower(strings.TrimSpace(appEnv))
+	if override || slices.Contains(DropEnvironments, env) {
+		return nil
+	}
+	if env == "" {
+		env = "unset"
+	}
+	return fmt.Errorf("drop runs only when APP_ENV is %s (APP_ENV is %s); pass --i-know-what-im-doing to override",
+		strings.Join(DropEnvironments, " or "), env)
+}
+
+// Drop drops every table in the database's current schema, including the migrations table
+// Drop: データベースの現在のスキーマの全てのテーブルを、マイグレーションのテーブルも含めて削除する関数
+func Drop(target Database, source Source) error {
+	m, err := Open(target, source)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Drop(); err != nil {
+		return fmt.Errorf("failed to drop the database: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package migrations
+
+import (
+	"bytes"   // bytes: ダンプの組み立て
+	"context" // context: コンテキスト
+	"errors"  // errors: エラー操作
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"strings" // strings: 文字列操作
+
+	"api/internal/database" // database: スキーマの出力
+)
+
+// DefaultDumpPath is the committed schema dump, relative to the application directory
+// DefaultDumpPath: アプリケーションのディレクトリからの相対パスで表した、コミットするスキーマのダンプ
+const DefaultDumpPath = "schema.sql"
+
+// ErrSchemaDrift is returned by CheckDump when the committed dump no longer matches the database
+// ErrSchemaDrift: コミットしたダンプがデータベースと一致しなくなった場合にCheckDumpが返すエラー
+var ErrSchemaDrift = errors.New("schema dump out of date")
+
+// Dump returns the DDL of schema as exported by ExportSchema, headed by the schema_migrations version it reflects
+// Dump: ExportSchemaが出力したschemaのDDLを、反映しているschema_migrationsのバージョンを先頭に付けて返す関数
+// The dump holds nothing that changes between runs, so it can be committed and diffed; a dirty database is refused,
+// since its schema matches no version
+// ダンプは実行ごとに変わる内容を含まないため、コミットして差分を比較できる。dirtyなデータベースのスキーマは
+// どのバージョンにも一致しないため拒否する
+func Dump(ctx context.Context, driver *database.PostgreSQLDriver, schema string) ([]byte, error) {
+	version, dirty, err := driver.GetMigrationVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, fmt.Errorf("migration %d left the database dirty; fix the schema and force the version before dumping", version)
+	}
+
+	var dump bytes.Buffer
+	dump.WriteString(dumpHeader(version))
+	if err := driver.ExportSchema(ctx, schema, &dump); err != nil {
+		return nil, err
+	}
+	return dump.Bytes(), nil
+}
+
+// dumpHeader returns the comment lines that start a dump
+// dumpHeader: ダンプの先頭のコメント行を返す関数
+func dumpHeader(version uint) string {
+	return fmt.Sprintf("-- Generated by sift-migrate dump; do not edit\n-- schema_migrations version: %d\n\n", version)
+}
+
+// CheckDump compares the committed dump with a fresh one and returns ErrSchemaDrift naming the first line that differs
+// CheckDump: コミットしたダンプと新しいダンプを比較し、最初に異なる行を示すErrSchemaDriftを返す関数
+func CheckDump(committed, current []byte) error {
+	if bytes.Equal(committed, current) {
+		return nil
+	}
+	committedLines := strings.Split(string(committed), "\n")
+	currentLines := strings.Split(string(current), "\n")
+	line := 0
+	for line < len(committedLines) && line < len(currentLines) && committedLines[line] == currentLines[line] {
+		line++
+	}
+	return fmt.Errorf("%w at line %d:\n  committed: %s\n  database:  %s", ErrSchemaDrift, line+1, lineAt(committedLines, line), lineAt(currentLines, line))
+}
+
+// lineAt returns lines[i] quoted, or a marker past the end
+// lineAt: lines[i]を引用符付きで返す関数、末尾を越えた場合は目印を返す
+func lineAt(lines []string, i int) string {
+	if i >= len(lines) {
+		return "(end of file)"
+	}
+	return fmt.Sprintf("%q", lines[i])
+}
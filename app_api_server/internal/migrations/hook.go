@@ -0,0 +1,189 @@
+package migrations
+
+import (
+	"context"       // context: コンテキスト
+	"fmt"           // fmt: format（フォーマット）、文字列フォーマット機能
+	"io"            // io: 入出力、実行ファイルの出力先
+	"os"            // os: operating system（オペレーティングシステム）、フックの読み込みと環境変数
+	"os/exec"       // exec: 実行ファイルのフックの起動
+	"path/filepath" // filepath: 拡張子の判定
+
+	"api/internal/database" // database: SQLのフックの実行
+)
+
+// Hook phases: when a hook runs relative to the migrations
+// フックの段階: マイグレーションに対してフックを実行する時点
+const (
+	PreHook  = "pre"  // pre: 最初のマイグレーションを適用する前
+	PostHook = "post" // post: 最後のマイグレーションを適用した後
+)
+
+// Hook is a script run around up: a .sql file executed with ExecScript, or any other executable file
+// Hook: upの前後に実行するスクリプト、ExecScriptで実行する.sqlファイル、またはそれ以外の実行ファイル
+type Hook struct {
+	Phase string // phase: PreHookまたはPostHook
+	Path  string // path: フックのファイルのパス
+	SQL   bool   // sql: .sqlファイルか、falseの場合は実行ファイル
+}
+
+// HookError reports a hook that failed, so the caller can tell a failed hook from a failed migration
+// HookError: 失敗したフックを報告するエラー型、呼び出し元はマイグレーションの失敗と区別できる
+type HookError struct {
+	Phase string // phase: 失敗したフックの段階
+	Path  string // path: 失敗したフックのファイルのパス
+	Err   error  // err: フックのエラー
+}
+
+// Error implements the error interface
+// Error: errorインターフェースの実装
+func (e *HookError) Error() string {
+	return fmt.Sprintf("%s-hook %s failed: %v", e.Phase, e.Path, e.Err)
+}
+
+// Unwrap returns the hook's error
+// Unwrap: フックのエラーを返す関数
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// ParseHook checks that path is a .sql file or an executable file and returns it as the hook for phase
+// ParseHook: pathが.sqlファイルまたは実行ファイルであることを確認し、phaseのフックとして返す関数
+func ParseHook(phase, path string) (*Hook, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s-hook: %w", phase, err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s-hook: %s is not a file", phase, path)
+	}
+	hook := &Hook{Phase: phase, Path: path, SQL: filepath.Ext(path) == ".sql"}
+	if !hook.SQL && info.Mode().Perm()&0o111 == 0 {
+		return nil, fmt.Errorf("%s-hook: %s is neither a .sql file nor executable", phase, path)
+	}
+	return hook, nil
+}
+
+// HookEnv returns the environment an executable hook runs with: MIGRATE_HOOK set to phase, and the PG* variables for
+// config so psql and pg_dump connect to the same database
+// HookEnv: 実行ファイルのフックの環境変数を返す関数、MIGRATE_HOOKにphaseを設定し、psqlとpg_dumpが同じデータベースに
+// 接続するようconfigのPG*変数を設定する
+// A host list is passed as the comma-separated PGHOST and PGPORT lists libpq accepts
+// ホストのリストはlibpqが受け付けるカンマ区切りのPGHOSTとPGPORTのリストとして渡す
+func HookEnv(config *database.DatabaseConfig, phase string) []string {
+	env := []string{"MIGRATE_HOOK=" + phase}
+	if config == nil {
+		return env
+	}
+	hosts, ports := config.HostPortLists()
+	env = append(env,
+		"PGHOST="+hosts,
+		"PGUSER="+config.User,
+		"PGPASSWORD="+config.Password,
+		"PGDATABASE="+config.Database,
+		"PGSSLMODE="+config.SSLMode,
+	)
+	if ports != "" {
+		env = append(env, "PGPORT="+ports)
+	}
+	if config.TargetSessionAttrs != "" {
+		env = append(env, "PGTARGETSESSIONATTRS="+config.TargetSessionAttrs) // the same host the driver picks: ドライバーと同じホストを選ぶ
+	}
+	return env
+}
+
+// checkHookAuth refuses an executable hook when config does not authenticate with its static password
+// checkHookAuth: configが静的なパスワードで認証しない場合に実行ファイルのフックを拒否する関数
+// An IAM token or a provider's credentials are never in config, so PGPASSWORD would be empty or stale
+// IAMトークンや取得元の認証情報はconfigに含まれないため、PGPASSWORDが空または古くなる
+func checkHookAuth(hook *Hook, config *database.DatabaseConfig) error {
+	if hook == nil || hook.SQL || config == nil {
+		return nil
+	}
+	if method := config.AuthMethod; method != "" && method != database.AuthMethodPassword {
+		return fmt.Errorf("%s-hook: executable hooks only get PGPASSWORD and cannot authenticate with auth method %s; use a .sql hook instead", hook.Phase, method)
+	}
+	return nil
+}
+
+// Run runs the hook: a .sql file in one transaction on driver, or the executable with env added to the process's
+// environment and its output written to out
+// Run: フックを実行する関数、.sqlファイルはdriverの1つのトランザクションで、実行ファイルはプロセスの環境変数にenvを加えて実行し、出力をoutに書き込む
+// Any failure is returned as a *HookError: 失敗は全て*HookErrorとして返す
+func (h *Hook) Run(ctx context.Context, driver *database.PostgreSQLDriver, env []string, out io.Writer) error {
+	var err error
+	if h.SQL {
+		var script []byte
+		if script, err = os.ReadFile(h.Path); err == nil {
+			err = driver.ExecScript(ctx, string(script))
+		}
+	} else {
+		cmd := exec.CommandContext(ctx, h.Path)
+		cmd.Env = append(os.Environ(), env...)
+		cmd.Stdout, cmd.Stderr = out, out
+		err = cmd.Run()
+	}
+	if err != nil {
+		return &HookError{Phase: h.Phase, Path: h.Path, Err: err}
+	}
+	return nil
+}
+
+// Hooks are the hooks around one up run
+// Hooks: 1回のupの前後に実行するフック
+type Hooks struct {
+	Pre        *Hook                    // pre: 最初のマイグレーションの前に実行するフック、nilの場合はなし
+	Post       *Hook                    // post: 最後のマイグレーションの後に実行するフック、nilの場合はなし
+	PostAlways bool                     // post always: マイグレーションを適用しなかった場合もPostを実行する
+	Config     *database.DatabaseConfig // config: 実行ファイルのフックに渡す接続設定
+	Out        io.Writer                // out: 実行ファイルのフックの出力先
+}
+
+// Around runs apply between the hooks: Pre only when pending is set, and Post only when apply reports a migration
+// applied, or always with PostAlways
+// Around: フックの間でapplyを実行する関数、Preはpendingの場合のみ、Postはapplyがマイグレーションを適用したと報告した場合、
+// またはPostAlwaysの場合のみ実行する
+// An executable hook that cannot authenticate stops before anything runs. A failed Pre stops before apply and a failed
+// apply skips Post; both hook failures are *HookError
+// 認証できない実行ファイルのフックは何も実行する前に止まる。Preが失敗するとapplyの前に止まり、applyが失敗するとPostは
+// 実行しない。フックの失敗はどちらも*HookError
+func (h Hooks) Around(ctx context.Context, driver *database.PostgreSQLDriver, pending bool, apply func() (applied bool, err error)) error {
+	for _, hook := range []*Hook{h.Pre, h.Post} {
+		if err := checkHookAuth(hook, h.Config); err != nil {
+			return err
+		}
+	}
+	if h.Pre != nil && pending {
+		if err := h.Pre.Run(ctx, driver, HookEnv(h.Config, PreHook), h.Out); err != nil {
+			return fmt.Errorf("%w; no migration was applied", err)
+		}
+	}
+	applied, err := apply()
+	if err != nil {
+		return err
+	}
+	if h.Post != nil && (applied || h.PostAlways) {
+		if err := h.Post.Run(ctx, driver, HookEnv(h.Config, PostHook), h.Out); err != nil {
+			return fmt.Errorf("%w; the migrations applied stay applied", err)
+		}
+	}
+	return nil
+}
+
+// HasPending reports whether any of targets, on base's pool, has a migration above its recorded version
+// HasPending: baseの接続プール上で、targetsのいずれかに記録されたバージョンより新しいマイグレーションがあるかを返す関数
+func HasPending(ctx context.Context, base Database, targets []Target) (bool, error) {
+	for _, target := range targets {
+		list, err := Load(target.Source)
+		if err != nil {
+			return false, err
+		}
+		status, err := ReadStatus(ctx, target.On(base), list)
+		if err != nil {
+			return false, fmt.Errorf("target %s: %w", target.Name, err)
+		}
+		if status.Pending > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
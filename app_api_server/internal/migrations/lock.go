@@ -0,0 +1,92 @@
+package migrations
+
+import (
+	"context"             // context: コンテキスト
+	"database/sql/driver" // driver: 接続の破棄を指示するErrBadConn
+	"errors"              // errors: エラー操作
+	"fmt"                 // fmt: format（フォーマット）、文字列フォーマット機能
+	"hash/fnv"            // fnv: ロックのキーを導出するハッシュ
+	"time"                // time: 時間操作機能
+)
+
+// DefaultLockWait is how long a command waits for another run's migration lock by default
+// DefaultLockWait: コマンドが別の実行のマイグレーションのロックをデフォルトで待つ時間
+const DefaultLockWait = 2 * time.Minute
+
+// LockWaitUsage is the --lock-wait flag usage shared by the commands that change the database
+// LockWaitUsage: データベースを変更するコマンドで共有する--lock-waitフラグの説明
+const LockWaitUsage = "how long to wait for another migration run to finish (0 gives up at once)"
+
+// lockPollInterval is how often a waiting run retries the lock
+// lockPollInterval: 待機中の実行がロックを再試行する間隔
+const lockPollInterval = 250 * time.Millisecond
+
+// MinOpenConns is the fewest open connections a pool needs to run migrations under the lock: the lock's own connection,
+// the one golang-migrate runs on, and one for the checksums recorded while golang-migrate still holds its connection
+// MinOpenConns: ロックの下でマイグレーションを実行するのに必要な接続数の下限。ロック専用の接続、golang-migrateが
+// 実行する接続、golang-migrateが接続を保持したまま記録するチェックサム用の接続
+const MinOpenConns = 3
+
+// ErrMigrationInProgress is returned when another run still holds the migration lock after the wait
+// ErrMigrationInProgress: 待機後も別の実行がマイグレーションのロックを保持している場合のエラー
+var ErrMigrationInProgress = errors.New("another migration is in progress")
+
+// LockKey returns the advisory lock key for the migrations of databaseName
+// LockKey: databaseNameのマイグレーションに使うアドバイザリーロックのキーを返す関数
+// The prefix keeps the key apart from the lock golang-migrate takes around each run
+// 接頭辞により、golang-migrateが各実行で取るロックのキーと重ならないようにする
+func LockKey(databaseName string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte("sift_manage_app migrations:" + databaseName))
+	return int64(hash.Sum64())
+}
+
+// WithLock runs fn while holding the migration lock for target's database, waiting up to wait for another run to release it
+// WithLock: targetのデータベースのマイグレーションのロックを保持してfnを実行する関数、別の実行の解放をwaitまで待つ
+// The lock is a session-level pg_advisory_lock on a connection of its own, so it goes away with the connection if the process dies
+// ロックは専用の接続でのセッション単位のpg_advisory_lockのため、プロセスが終了した場合は接続と共に解放される
+// A pool limited below MinOpenConns is refused up front, since fn would otherwise wait forever for a connection
+// MinOpenConns未満に制限されたプールは、fnが接続を永久に待つことになるため最初に拒否する
+func WithLock(target Database, wait time.Duration, fn func() error) error {
+	if limit := target.DB.Stats().MaxOpenConnections; limit > 0 && limit < MinOpenConns {
+		return fmt.Errorf("migrations need at least %d open connections, the pool allows %d (raise DB_MAX_OPEN_CONNS)", MinOpenConns, limit)
+	}
+	ctx := context.Background()
+	conn, err := target.DB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get a connection for the migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	var name string
+	if err := conn.QueryRowContext(ctx, "SELECT current_database()").Scan(&name); err != nil {
+		return fmt.Errorf("failed to read the database name: %w", err)
+	}
+	key := LockKey(name)
+
+	// pg_try_advisory_lock is retried rather than pg_advisory_lock blocking, so the wait has a bound
+	// 待機に上限を設けるため、pg_advisory_lockで待つ代わりにpg_try_advisory_lockを再試行する
+	deadline := time.Now().Add(wait)
+	for {
+		var locked bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+			return fmt.Errorf("failed to take the migration lock: %w", err)
+		}
+		if locked {
+			break
+		}
+		if !time.Now().Before(deadline) {
+			return ErrMigrationInProgress
+		}
+		time.Sleep(lockPollInterval)
+	}
+
+	defer func() {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", key); err != nil {
+			// A connection back in the pool must not keep the lock, so it is discarded instead
+			// プールに戻る接続がロックを保持したままにならないよう、代わりに接続を破棄する
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}()
+	return fn()
+}
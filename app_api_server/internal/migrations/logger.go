@@ -0,0 +1,98 @@
+package migrations
+
+import (
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"regexp"  // regexp: golang-migrateのログの解析
+	"strconv" // strconv: string conversion（文字列変換）、バージョンの解析
+	"strings" // strings: 文字列操作
+	"time"    // time: 時間操作機能
+
+	"api/internal/database" // database: ログの出力先のインターフェース
+
+	"github.com/golang-migrate/migrate/v4" // migrate: マイグレーション機能
+)
+
+// migrationLogPattern matches the {version}/{u|d} {name} string golang-migrate logs each migration as
+// migrationLogPattern: golang-migrateが各マイグレーションをログに出す{version}/{u|d} {name}の文字列に一致する正規表現
+var migrationLogPattern = regexp.MustCompile(`^([0-9]+)/([ud]) (.*)$`)
+
+// migrateLogger implements golang-migrate's Logger on top of a database.Logger, and keeps how long each up migration
+// took so the time can be recorded with its checksum
+// migrateLogger: database.Logger上にgolang-migrateのLoggerを実装する型、各upマイグレーションにかかった時間を
+// チェックサムと共に記録できるよう保持する
+type migrateLogger struct {
+	logger  database.Logger        // logger: 出力先、nilの場合はログを出さずに時間のみ保持する
+	verbose bool                   // verbose: golang-migrateの詳細なログを出す
+	timings map[uint]time.Duration // timings: upマイグレーションごとにかかった時間
+}
+
+// newMigrateLogger returns the logger golang-migrate runs with on target
+// newMigrateLogger: targetでgolang-migrateが使うロガーを返す関数
+func newMigrateLogger(target Database) *migrateLogger {
+	return &migrateLogger{logger: target.Logger, verbose: target.Verbose, timings: map[uint]time.Duration{}}
+}
+
+// Verbose reports whether golang-migrate should log its debug output
+// Verbose: golang-migrateがデバッグ出力をログに出すべきかを返す関数
+func (l *migrateLogger) Verbose() bool {
+	return l.verbose && l.logger != nil
+}
+
+// Printf logs each finished migration at info level with its version, direction and elapsed time, and every other
+// line at debug level
+// Printf: 完了した各マイグレーションをバージョン、向き、経過時間と共にinfoレベルで、それ以外の行をdebugレベルでログに出す関数
+func (l *migrateLogger) Printf(format string, v ...interface{}) {
+	version, direction, name, elapsed, ok := parseMigrationLog(v)
+	if ok && direction == DirectionUp {
+		l.timings[version] = elapsed
+	}
+	if l.logger == nil {
+		return
+	}
+	if !ok {
+		l.logger.Debug("golang-migrate: " + strings.TrimSuffix(fmt.Sprintf(format, v...), "\n"))
+		return
+	}
+	l.logger.Info(fmt.Sprintf("Migrated %s %d %s in %s", direction, version, name, elapsed.Round(time.Millisecond)),
+		"version", version, "direction", string(direction), "name", name, "elapsed", elapsed)
+}
+
+// migrationTimings returns how long each up migration m applied took, nil when m was not opened with Open
+// migrationTimings: mが適用した各upマイグレーションにかかった時間を返す関数、mがOpenで開かれていない場合はnil
+func migrationTimings(m *migrate.Migrate) map[uint]time.Duration {
+	if l, ok := m.Log.(*migrateLogger); ok {
+		return l.timings
+	}
+	return nil
+}
+
+// parseMigrationLog reads the arguments of golang-migrate's line for a finished migration: the migration's log string
+// followed by the time spent reading and running it
+// parseMigrationLog: golang-migrateの完了したマイグレーションの行の引数を読む関数、マイグレーションのログ文字列と、
+// 続けて読み込みと実行にかかった時間
+func parseMigrationLog(v []interface{}) (version uint, direction Direction, name string, elapsed time.Duration, ok bool) {
+	if len(v) < 2 {
+		return 0, "", "", 0, false
+	}
+	logString, isString := v[0].(string)
+	match := migrationLogPattern.FindStringSubmatch(logString)
+	if !isString || match == nil {
+		return 0, "", "", 0, false
+	}
+	for _, arg := range v[1:] {
+		d, isDuration := arg.(time.Duration)
+		if !isDuration {
+			return 0, "", "", 0, false
+		}
+		elapsed += d
+	}
+	parsed, err := strconv.ParseUint(match[1], 10, 0)
+	if err != nil {
+		return 0, "", "", 0, false
+	}
+	direction = DirectionUp
+	if match[2] == "d" {
+		direction = DirectionDown
+	}
+	return uint(parsed), direction, match[3], elapsed, true
+}
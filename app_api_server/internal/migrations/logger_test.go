@@ -0,0 +1,204 @@
+package migrations
+
+import (
+	"strings" // strings: 文字列操作
+	"sync"    // sync: 記録した行の排他制御
+	"testing" // testing: テスト機能
+	"time"    // time: 時間操作機能
+
+	"github.com/golang-migrate/migrate/v4"                        // migrate: マイグレーション機能
+	stubdb "github.com/golang-migrate/migrate/v4/database/stub"   // stubdb: メモリ上のデータベースドライバー
+	"github.com/golang-migrate/migrate/v4/source"                 // source: ソースドライバーのマイグレーション
+	stubsource "github.com/golang-migrate/migrate/v4/source/stub" // stubsource: メモリ上のソースドライバー
+)
+
+// logLine is one call recordingLogger received
+// logLine: recordingLoggerが受け取った1回の呼び出し
+type logLine struct {
+	level         string        // level: ログレベル
+	msg           string        // msg: メッセージ
+	keysAndValues []interface{} // keys and values: キーと値の組
+}
+
+// recordingLogger records every call for assertions
+// recordingLogger: 検証のために全ての呼び出しを記録するロガー
+// golang-migrate logs from its reading goroutine and its run loop at once, so lines is guarded by mu
+// golang-migrateは読み込みのゴルーチンと実行ループから同時にログを出すため、linesはmuで保護する
+type recordingLogger struct {
+	mu    sync.Mutex // mu: mutex（相互排他ロック）、linesを保護
+	lines []logLine
+}
+
+func (l *recordingLogger) Debug(msg string, kv ...interface{}) { l.record("debug", msg, kv) }
+func (l *recordingLogger) Info(msg string, kv ...interface{})  { l.record("info", msg, kv) }
+func (l *recordingLogger) Warn(msg string, kv ...interface{})  { l.record("warn", msg, kv) }
+func (l *recordingLogger) Error(msg string, kv ...interface{}) { l.record("error", msg, kv) }
+
+func (l *recordingLogger) record(level, msg string, kv []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, logLine{level: level, msg: msg, keysAndValues: kv})
+}
+
+// at returns the messages logged at level, in order
+// at: levelで出力されたメッセージを順に返す関数
+func (l *recordingLogger) at(level string) []string {
+	var messages []string
+	for _, line := range l.recorded() {
+		if line.level == level {
+			messages = append(messages, line.msg)
+		}
+	}
+	return messages
+}
+
+// recorded returns a copy of the lines recorded so far
+// recorded: これまでに記録した行のコピーを返す関数
+func (l *recordingLogger) recorded() []logLine {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]logLine(nil), l.lines...)
+}
+
+// openStub opens golang-migrate on an in-memory source of two fixture migrations and a stub database, logging like Open
+// openStub: 2つのフィクスチャのマイグレーションを持つメモリ上のソースとスタブのデータベースでgolang-migrateを開く関数、Openと同じくログを出す
+func openStub(t *testing.T, target Database) *migrate.Migrate {
+	t.Helper()
+	sourceDriver, err := stubsource.WithInstance(nil, &stubsource.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open the stub source: %v", err)
+	}
+	migrations := sourceDriver.(*stubsource.Stub).Migrations
+	for _, migration := range []*source.Migration{
+		{Version: 1, Identifier: "CREATE TABLE items (id BIGINT)", Direction: source.Up},
+		{Version: 1, Identifier: "DROP TABLE items", Direction: source.Down},
+		{Version: 2, Identifier: "ALTER TABLE items ADD COLUMN name TEXT", Direction: source.Up},
+		{Version: 2, Identifier: "ALTER TABLE items DROP COLUMN name", Direction: source.Down},
+	} {
+		migrations.Append(migration)
+	}
+	databaseDriver, err := stubdb.WithInstance(nil, &stubdb.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open the stub database: %v", err)
+	}
+	m, err := migrate.NewWithInstance("stub", sourceDriver, "stub", databaseDriver)
+	if err != nil {
+		t.Fatalf("Failed to open golang-migrate: %v", err)
+	}
+	m.Log = newMigrateLogger(target)
+	return m
+}
+
+// TestMigrateLogger tests the lines logged for two fixture migrations applied and one reverted, and the timings kept
+// TestMigrateLogger: 2つのフィクスチャのマイグレーションの適用と1つの取り消しで出力される行と、保持する時間をテストする関数
+func TestMigrateLogger(t *testing.T) {
+	testCases := []struct {
+		name        string
+		verbose     bool
+		expectDebug bool
+	}{
+		{name: "Each migration's time only", verbose: false, expectDebug: false},
+		{name: "Verbose adds golang-migrate's output", verbose: true, expectDebug: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := &recordingLogger{}
+			m := openStub(t, Database{Logger: logger, Verbose: tc.verbose})
+			if err := m.Up(); err != nil {
+				t.Fatalf("Expected up to succeed, got: %v", err)
+			}
+			if err := m.Steps(-1); err != nil {
+				t.Fatalf("Expected one step down to succeed, got: %v", err)
+			}
+
+			info := logger.at("info")
+			expected := []string{"Migrated up 1 1.up.stub in ", "Migrated up 2 2.up.stub in ", "Migrated down 2 2.down.stub in "}
+			if len(info) != len(expected) {
+				t.Fatalf("Expected %d info lines, got: %v", len(expected), info)
+			}
+			for i, prefix := range expected {
+				if !strings.HasPrefix(info[i], prefix) {
+					t.Errorf("Expected info line %d to start with %q, got: %q", i, prefix, info[i])
+				}
+			}
+			var first logLine
+			for _, line := range logger.recorded() {
+				if line.level == "info" {
+					first = line
+					break
+				}
+			}
+			if len(first.keysAndValues) != 8 || first.keysAndValues[1] != uint(1) || first.keysAndValues[3] != "up" {
+				t.Errorf("Expected version and direction pairs, got: %v", first.keysAndValues)
+			}
+			if _, isDuration := first.keysAndValues[7].(time.Duration); !isDuration {
+				t.Errorf("Expected the elapsed time as a duration, got: %v", first.keysAndValues[7])
+			}
+
+			debug := logger.at("debug")
+			if (len(debug) > 0) != tc.expectDebug {
+				t.Errorf("Expected debug lines %v, got: %v", tc.expectDebug, debug)
+			}
+			if tc.expectDebug && !strings.Contains(strings.Join(debug, "\n"), "golang-migrate: Read and execute 1/u 1.up.stub") {
+				t.Errorf("Expected golang-migrate's verbose output, got: %v", debug)
+			}
+
+			// Only up migrations are timed for the tracking table: 追跡テーブル用に時間を保持するのはupマイグレーションのみ
+			timings := migrationTimings(m)
+			if _, ok := timings[1]; !ok || len(timings) != 2 {
+				t.Errorf("Expected timings for versions 1 and 2, got: %v", timings)
+			}
+		})
+	}
+}
+
+// TestMigrateLoggerWithoutLogger tests that timings are kept and nothing is logged without a logger
+// TestMigrateLoggerWithoutLogger: ロガーがない場合に何も出力せず時間を保持することをテストする関数
+func TestMigrateLoggerWithoutLogger(t *testing.T) {
+	m := openStub(t, Database{Verbose: true})
+	if m.Log.Verbose() {
+		t.Errorf("Expected no verbose output without a logger")
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Expected up to succeed, got: %v", err)
+	}
+	if timings := migrationTimings(m); len(timings) != 2 {
+		t.Errorf("Expected timings for both migrations, got: %v", timings)
+	}
+}
+
+// TestParseMigrationLog tests reading golang-migrate's finished-migration lines and ignoring the others
+// TestParseMigrationLog: golang-migrateの完了したマイグレーションの行の読み込みと、それ以外の行の無視をテストする関数
+func TestParseMigrationLog(t *testing.T) {
+	testCases := []struct {
+		name              string
+		args              []interface{}
+		expectOK          bool
+		expectedVersion   uint
+		expectedDirection Direction
+		expectedName      string
+		expectedElapsed   time.Duration
+	}{
+		{name: "Finished line", args: []interface{}{"3/u create_users", 2 * time.Millisecond}, expectOK: true,
+			expectedVersion: 3, expectedDirection: DirectionUp, expectedName: "create_users", expectedElapsed: 2 * time.Millisecond},
+		{name: "Verbose finished line", args: []interface{}{"12/d add_index", time.Millisecond, 4 * time.Millisecond}, expectOK: true,
+			expectedVersion: 12, expectedDirection: DirectionDown, expectedName: "add_index", expectedElapsed: 5 * time.Millisecond},
+		{name: "Start buffering", args: []interface{}{"3/u create_users"}},
+		{name: "Closing", args: nil},
+		{name: "Error", args: []interface{}{"no change", time.Millisecond}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			version, direction, name, elapsed, ok := parseMigrationLog(tc.args)
+			if ok != tc.expectOK {
+				t.Fatalf("Expected ok %v, got: %v", tc.expectOK, ok)
+			}
+			if version != tc.expectedVersion || direction != tc.expectedDirection || name != tc.expectedName || elapsed != tc.expectedElapsed {
+				t.Errorf("Expected %d %s %s %s, got: %d %s %s %s", tc.expectedVersion, tc.expectedDirection, tc.expectedName, tc.expectedElapsed,
+					version, direction, name, elapsed)
+			}
+		})
+	}
+}
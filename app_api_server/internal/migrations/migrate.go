@@ -0,0 +1,194 @@
+package migrations
+
+import (
+	"context"       // context: コンテキスト
+	"database/sql"  // sql: ドライバーの接続プール
+	"errors"        // errors: エラー操作
+	"fmt"           // fmt: format（フォーマット）、文字列フォーマット機能
+	"path/filepath" // filepath: マイグレーションのディレクトリの絶対パス化
+
+	"github.com/golang-migrate/migrate/v4"                    // migrate: マイグレーション機能
+	migratedb "github.com/golang-migrate/migrate/v4/database" // migratedb: golang-migrateのデータベースドライバーのインターフェース
+	"github.com/golang-migrate/migrate/v4/database/postgres"  // postgres: PostgreSQLデータベース対応
+	"github.com/golang-migrate/migrate/v4/source"             // source: ソース機能のインターフェース
+	"github.com/golang-migrate/migrate/v4/source/file"        // file: ファイルソース機能
+	"github.com/golang-migrate/migrate/v4/source/iofs"        // iofs: io/fsのソース機能、埋め込みのマイグレーション用
+
+	"api/internal/database" // database: データベース設定と接続
+)
+
+// NilVersion is the version golang-migrate's force takes to record that no migration has been applied
+// NilVersion: マイグレーションが1つも適用されていないことを記録するためにgolang-migrateのforceが受け付けるバージョン
+const NilVersion = -1
+
+// LoadEnv loads envFile into the environment, or DB_ENV_FILE or .env as the server does when envFile is empty
+// LoadEnv: envFileを環境変数に読み込む関数、envFileが空の場合はサーバーと同じくDB_ENV_FILEまたは.envを読む
+// A file named explicitly must exist: 明示的に指定したファイルは存在しなければならない
+func LoadEnv(envFile string) error {
+	if envFile != "" {
+		return database.LoadDotEnvStrict(envFile)
+	}
+	return database.LoadDotEnv()
+}
+
+// LoadConfig resolves the connection configuration with the flags over the environment; call LoadEnv first
+// LoadConfig: フラグを環境変数より優先して接続設定を解決する関数、先にLoadEnvを呼ぶ
+// Every sift-migrate subcommand loads its configuration this way, as the server and dbcheck do
+// サーバーやdbcheckと同じく、sift-migrateの全てのサブコマンドがこの方法で設定を読み込む
+func LoadConfig(flags *database.DatabaseConfigFlags) (*database.DatabaseConfig, error) {
+	config, err := flags.Resolve(true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database configuration: %w", err)
+	}
+	return config, nil
+}
+
+// Database is the connection golang-migrate runs on
+// Database: golang-migrateが実行に使う接続
+type Database struct {
+	DB              *sql.DB // db: ドライバーの接続プール
+	MigrationsTable string  // migrations table: バージョンを記録するテーブル、空の場合はschema_migrations
+	ChecksumsTable  string  // checksums table: チェックサムを記録するテーブル、空の場合はDefaultChecksumsTable
+
+	// Timeouts are set on the connection golang-migrate runs on and reset when it closes, so the pool can be shared
+	// with application traffic
+	// timeouts: golang-migrateが実行する接続に設定し、閉じる際に戻すため、アプリケーションの通信と共有するプールでも使える
+	Timeouts Timeouts
+
+	Logger  database.Logger // logger: 各マイグレーションのバージョン、向き、経過時間の出力先、nilの場合は出力しない
+	Verbose bool            // verbose: golang-migrateの詳細なログもLoggerにdebugレベルで出力する
+}
+
+// Connect connects a driver with config, so migrations use the same connection settings as the server
+// Connect: configでドライバーを接続する関数、マイグレーションはサーバーと同じ接続設定を使う
+// SSL settings, pgpass and credential providers all apply because golang-migrate never builds its own connection
+// golang-migrateが独自の接続を作らないため、SSLの設定、pgpass、CredentialProviderが全て適用される
+// opts configure the driver, such as database.WithLogger; the caller closes the returned driver
+// optsはdatabase.WithLoggerなどドライバーを設定する、戻り値のドライバーは呼び出し元が閉じる
+func Connect(config *database.DatabaseConfig, opts ...database.DriverOption) (*database.PostgreSQLDriver, error) {
+	driver, err := database.NewPostgreSQLDriverWithConfig(config, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := driver.Connect(); err != nil {
+		return nil, err
+	}
+	return driver, nil
+}
+
+// Open opens golang-migrate on the migrations in source and one connection taken from target's pool
+// Open: sourceのマイグレーションと、targetの接続プールから取り出した1つの接続でgolang-migrateを開く関数
+// Embedded migrations are read through iofs and a directory through file://
+// 埋め込みのマイグレーションはiofs、ディレクトリはfile://で読む
+// Closing the returned instance returns the connection and leaves the pool open for its driver
+// 戻り値のインスタンスを閉じると接続を返却し、接続プールはドライバーのために開いたままにする
+func Open(target Database, source Source) (*migrate.Migrate, error) {
+	sourceName, sourceDriver, err := openSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	conn, err := target.DB.Conn(ctx)
+	if err != nil {
+		sourceDriver.Close()
+		return nil, fmt.Errorf("failed to get a connection for migrations: %w", err)
+	}
+	if err := target.Timeouts.apply(ctx, conn); err != nil {
+		conn.Close()
+		sourceDriver.Close()
+		return nil, err
+	}
+	// WithConnection, unlike WithInstance, does not close the pool when golang-migrate closes
+	// WithInstanceと異なり、WithConnectionはgolang-migrateを閉じても接続プールを閉じない
+	databaseDriver, err := postgres.WithConnection(ctx, conn, &postgres.Config{MigrationsTable: target.MigrationsTable})
+	if err != nil {
+		conn.Close()
+		sourceDriver.Close()
+		return nil, fmt.Errorf("failed to prepare the database for migrations: %w", err)
+	}
+	var instance migratedb.Driver = databaseDriver
+	if target.Timeouts != (Timeouts{}) {
+		instance = timeoutConn{Driver: databaseDriver, conn: conn, timeouts: target.Timeouts}
+	}
+
+	m, err := migrate.NewWithInstance(sourceName, sourceDriver, "postgres", instance)
+	if err != nil {
+		instance.Close()
+		sourceDriver.Close()
+		return nil, fmt.Errorf("failed to open %s: %w", source, err)
+	}
+	m.Log = newMigrateLogger(target)
+	return m, nil
+}
+
+// openSource opens golang-migrate's source driver for source
+// openSource: sourceに対するgolang-migrateのソースドライバーを開く関数
+func openSource(s Source) (string, source.Driver, error) {
+	if s.Path == "" {
+		driver, err := iofs.New(s.fsys, s.root)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read %s: %w", s, err)
+		}
+		return "iofs", driver, nil
+	}
+
+	absDir, err := filepath.Abs(s.Path)
+	if err != nil {
+		return "", nil, fmt.Errorf("migrations directory: %w", err)
+	}
+	driver, err := (&file.File{}).Open("file://" + filepath.ToSlash(absDir))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read migrations in %s: %w", s, err)
+	}
+	return "file", driver, nil
+}
+
+// CurrentVersion reports the applied version, 0 when no migration has run yet
+// CurrentVersion: 適用済みのバージョンを返す関数、まだ一度もマイグレーションが実行されていない場合は0
+func CurrentVersion(m *migrate.Migrate) (uint, bool, error) {
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read migration version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// CheckForceVersion refuses to force a version that has no migration in the directory unless allowMissing is set
+// CheckForceVersion: allowMissingが指定されない限り、ディレクトリにマイグレーションがないバージョンの強制設定を拒否する関数
+// NilVersion is always allowed, since it names no migration: NilVersionはマイグレーションを指さないため常に許可する
+func CheckForceVersion(migrations []Migration, version int, allowMissing bool) error {
+	if version < NilVersion {
+		return fmt.Errorf("invalid version %d: use %d to record that no migration has been applied", version, NilVersion)
+	}
+	if version == NilVersion || allowMissing {
+		return nil
+	}
+	if _, ok := Find(migrations, uint(version)); !ok {
+		return fmt.Errorf("version %d is not in the migrations directory; check the version or pass --allow-missing", version)
+	}
+	return nil
+}
+
+// Force records version in target without running any migration, clearing the dirty flag, and returns the version it replaced
+// Force: マイグレーションを実行せずにversionをtargetに記録してdirtyフラグを消す関数、置き換えたバージョンを返す
+// Check the version with CheckForceVersion first: 先にCheckForceVersionでバージョンを確認する
+func Force(target Database, source Source, version int) (before uint, dirty bool, err error) {
+	m, err := Open(target, source)
+	if err != nil {
+		return 0, false, err
+	}
+	defer m.Close()
+
+	before, dirty, err = CurrentVersion(m)
+	if err != nil {
+		return 0, false, err
+	}
+	if err := m.Force(version); err != nil {
+		return before, dirty, fmt.Errorf("failed to force version %d: %w", version, err)
+	}
+	return before, dirty, nil
+}
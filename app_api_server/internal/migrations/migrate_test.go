@@ -0,0 +1,110 @@
+package migrations
+
+import (
+	"flag"          // flag: コマンドラインフラグ
+	"os"            // os: operating system（オペレーティングシステム）、pgpassファイル
+	"path/filepath" // filepath: ファイルパス操作
+	"reflect"       // reflect: 値の比較
+	"strings"       // strings: 文字列操作
+	"testing"       // testing: テスト機能
+
+	"api/internal/database" // database: データベース設定
+)
+
+// TestCheckForceVersion tests which versions force accepts against the fixture directory
+// TestCheckForceVersion: フィクスチャのディレクトリに対してforceが受け付けるバージョンをテストする関数
+func TestCheckForceVersion(t *testing.T) {
+	migrations, err := Load(Directory("testdata/basic"))
+	if err != nil {
+		t.Fatalf("Failed to load fixture: %v", err)
+	}
+
+	testCases := []struct {
+		name         string
+		version      int
+		allowMissing bool
+		expectError  bool
+		errorContent string
+	}{
+		{name: "Version in the directory", version: 2},
+		{name: "Nil version", version: NilVersion},
+		{name: "Missing version refused", version: 3, expectError: true, errorContent: "version 3 is not in the migrations directory"},
+		{name: "Zero refused", version: 0, expectError: true, errorContent: "pass --allow-missing"},
+		{name: "Missing version allowed", version: 3, allowMissing: true},
+		{name: "Below nil version", version: -2, allowMissing: true, expectError: true, errorContent: "invalid version -2"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckForceVersion(migrations, tc.version, tc.allowMissing)
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestLoadConfigMatchesServer tests that the migrate commands resolve DB_* variables exactly as the server does
+// TestLoadConfigMatchesServer: migrateコマンドがサーバーと全く同じようにDB_*環境変数を解決することをテストする関数
+func TestLoadConfigMatchesServer(t *testing.T) {
+	pgpass := filepath.Join(t.TempDir(), "pgpass")
+	if err := os.WriteFile(pgpass, []byte("db.internal:5432:sift_app_db:sift_user:from_pgpass\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write pgpass file: %v", err)
+	}
+
+	base := map[string]string{
+		"DB_HOST":     "db.internal",
+		"DB_PORT":     "5432",
+		"DB_USER":     "sift_user",
+		"DB_PASSWORD": "secret",
+		"DB_NAME":     "sift_app_db",
+	}
+	testCases := []struct {
+		name         string
+		env          map[string]string
+		expectError  bool
+		errorContent string
+	}{
+		{name: "Defaults", env: map[string]string{}},
+		{name: "SSL settings", env: map[string]string{"DB_SSL_MODE": "verify-full", "DB_OPTIONS": "sslrootcert=/etc/ssl/root.crt application_name=migrate"}},
+		{name: "pgpass file", env: map[string]string{"DB_PASSWORD": "", "DB_USE_PGPASS": "true", "PGPASSFILE": pgpass}},
+		{name: "PG* fallback", env: map[string]string{"DB_HOST": "", "PGHOST": "replica.internal", "DB_PORT": "", "PGPORT": "6432"}},
+		{name: "Session settings", env: map[string]string{"DB_TIMEZONE": "UTC", "DB_LOCK_TIMEOUT": "5s", "DB_TARGET_SESSION_ATTRS": "read-write"}},
+		{name: "Missing password", env: map[string]string{"DB_PASSWORD": ""}, expectError: true, errorContent: "DB_PASSWORD"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, key := range []string{"DB_ENV_FILE", "PGHOST", "PGPORT", "PGUSER", "PGPASSWORD", "PGDATABASE", "PGSSLMODE", "PGPASSFILE", "DB_USE_PGPASS", "DB_AUTH_METHOD", "APP_ENV"} {
+				t.Setenv(key, "")
+			}
+			for key, value := range base {
+				t.Setenv(key, value)
+			}
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			server, serverErr := database.LoadDatabaseConfig()
+			migrate, err := LoadConfig(database.FlagSet(flag.NewFlagSet("migrate", flag.ContinueOnError)))
+			if tc.expectError {
+				if err == nil || serverErr == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected both to fail with '%s', got: %v (server: %v)", tc.errorContent, err, serverErr)
+				}
+				return
+			}
+			if err != nil || serverErr != nil {
+				t.Fatalf("Expected no error, got: %v (server: %v)", err, serverErr)
+			}
+			if !reflect.DeepEqual(migrate, server) {
+				t.Errorf("Expected the server's configuration %+v, got: %+v", server, migrate)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Package migrations holds the application's migrations, embedded in the binary, and the logic behind each
+// sift-migrate subcommand as plain functions: configuration loading, reading golang-migrate migration sources,
+// comparing them with the database's version, tracking the checksum of each applied file, moving the schema up and
+// down, generating new migration files, seeding, and opening golang-migrate itself
+// migrations: バイナリに埋め込んだアプリケーションのマイグレーションと、sift-migrateの各サブコマンドの処理を通常の関数として持つパッケージ。
+// 設定の読み込み、golang-migrateのマイグレーションの読み込み元の読み込み、データベースのバージョンとの比較、
+// 適用した各ファイルのチェックサムの記録、スキーマの移動、新しいマイグレーションファイルの生成、シード、golang-migrate自体を開く処理を持つ
+package migrations
+
+import (
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"io/fs"   // fs: file system（ファイルシステム）、読み込み元のディレクトリの読み込み
+	"os"      // os: operating system（オペレーティングシステム）、環境変数
+	"path"    // path: 読み込み元の中のファイルのパス
+	"regexp"  // regexp: ファイル名の解析
+	"sort"    // sort: バージョン順の並べ替え
+	"strconv" // strconv: string conversion（文字列変換）
+	"strings" // strings: 文字列操作
+)
+
+// upFilePattern matches golang-migrate's {version}_{title}.up.{extension} file names
+// upFilePattern: golang-migrateの{version}_{title}.up.{extension}形式のファイル名に一致する正規表現
+var upFilePattern = regexp.MustCompile(`^([0-9]+)_(.*)\.up\.[^.]+$`)
+
+// Migration is one up migration found in the migrations directory
+// Migration: マイグレーションのディレクトリで見つかった1つのupマイグレーション
+type Migration struct {
+	Version     uint   `json:"version"`     // version: バージョン
+	Description string `json:"description"` // description: ファイル名から得た説明
+	File        string `json:"file"`        // file: upマイグレーションのファイル名
+	Checksum    string `json:"checksum"`    // checksum: upマイグレーションのファイルのSHA-256
+}
+
+// ResolvePath picks the directory migrate create writes to from flagValue, then MIGRATIONS_PATH, then DefaultPath
+// ResolvePath: flagValue、MIGRATIONS_PATH、DefaultPathの順にmigrate createが書き込むディレクトリを選ぶ関数
+// The directory must exist, so a typo fails here instead of creating files nobody reads
+// 打ち間違いで誰も読まないファイルを作らないよう、ディレクトリが存在しなければエラーにする
+func ResolvePath(flagValue string) (string, error) {
+	path := flagValue
+	if path == "" {
+		path = os.Getenv("MIGRATIONS_PATH")
+	}
+	if path == "" {
+		path = DefaultPath
+	}
+	if err := checkDirectory(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Load returns the up migrations in source in version order
+// Load: sourceのupマイグレーションをバージョン順に返す関数
+// Other files, such as down migrations and READMEs, are skipped; two up files with one version are an error, as in golang-migrate
+// downマイグレーションやREADMEなど他のファイルは読み飛ばす、golang-migrateと同じく同じバージョンのupファイルが2つあればエラー
+func Load(source Source) ([]Migration, error) {
+	entries, err := fs.ReadDir(source.fsys, source.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	var migrations []Migration
+	seen := map[uint]string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := upFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		if other, ok := seen[uint(version)]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, other, entry.Name()) // duplicate: 重複した
+		}
+		seen[uint(version)] = entry.Name()
+		contents, err := fs.ReadFile(source.fsys, path.Join(source.root, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		migrations = append(migrations, Migration{
+			Version:     uint(version),
+			Description: strings.ReplaceAll(match[2], "_", " "),
+			File:        entry.Name(),
+			Checksum:    checksum(contents),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Find returns the migration with version, if the directory has one
+// Find: 指定したバージョンのマイグレーションがあれば返す関数
+func Find(migrations []Migration, version uint) (Migration, bool) {
+	for _, migration := range migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
@@ -0,0 +1,94 @@
+package migrations
+
+import (
+	"os"            // os: operating system（オペレーティングシステム）、一時ファイルの作成
+	"path/filepath" // filepath: パス操作
+	"reflect"       // reflect: 値の比較
+	"strings"       // strings: 文字列操作
+	"testing"       // testing: テスト機能
+)
+
+// TestLoad tests reading the fixture directories
+// TestLoad: フィクスチャのディレクトリの読み込みをテストする関数
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name         string
+		dir          string
+		expected     []Migration
+		expectError  bool
+		errorContent string
+	}{
+		{name: "Up files in version order", dir: "testdata/basic", expected: []Migration{
+			{Version: 1, Description: "create users", File: "000001_create_users.up.sql"},
+			{Version: 2, Description: "add users index", File: "000002_add_users_index.up.sql"},
+			{Version: 10, Description: "create sessions", File: "000010_create_sessions.up.sql"},
+		}},
+		{name: "Empty directory", dir: "testdata/empty", expected: nil},
+		{name: "Duplicate version", dir: "testdata/duplicate", expectError: true, errorContent: "duplicate migration version 1"},
+		{name: "Missing directory", dir: "testdata/missing", expectError: true, errorContent: "failed to read migrations directory"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			migrations, err := Load(Directory(tc.dir))
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			// Checksums are compared in TestLoadChecksums: チェックサムはTestLoadChecksumsで比較する
+			for i := range migrations {
+				if len(migrations[i].Checksum) != 64 {
+					t.Errorf("Expected a SHA-256 checksum for %s, got: %q", migrations[i].File, migrations[i].Checksum)
+				}
+				migrations[i].Checksum = ""
+			}
+			if !reflect.DeepEqual(migrations, tc.expected) {
+				t.Errorf("Expected %+v, got: %+v", tc.expected, migrations)
+			}
+		})
+	}
+}
+
+// TestResolvePath tests the flag > MIGRATIONS_PATH > default precedence of migrate create's directory
+// TestResolvePath: migrate createのディレクトリのフラグ > MIGRATIONS_PATH > デフォルトの優先順位をテストする関数
+func TestResolvePath(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "000001_init.up.sql")
+	if err := os.WriteFile(file, []byte("SELECT 1;"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	testCases := []struct {
+		name         string
+		flagValue    string
+		env          string
+		expected     string
+		expectError  bool
+		errorContent string
+	}{
+		{name: "Flag wins", flagValue: "testdata/basic", env: "testdata/empty", expected: "testdata/basic"},
+		{name: "Environment", env: "testdata/empty", expected: "testdata/empty"},
+		{name: "Default must exist", expectError: true, errorContent: "migrations directory: stat internal/migrations/sql"},
+		{name: "Not a directory", flagValue: file, expectError: true, errorContent: "is not a directory"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("MIGRATIONS_PATH", tc.env)
+			path, err := ResolvePath(tc.flagValue)
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				return
+			}
+			if err != nil || path != tc.expected {
+				t.Errorf("Expected %s, got: %s, %v", tc.expected, path, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package migrations
+
+import (
+	"context" // context: コンテキスト
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"time"    // time: 時間操作機能
+
+	"github.com/golang-migrate/migrate/v4" // migrate: マイグレーション機能
+)
+
+// Approve is called with a plan before it runs; an error stops the move before anything changes
+// Approve: 計画の実行前に呼ばれる関数、エラーを返すと何も変更せずに移動を中止する
+type Approve func(Plan) error
+
+// openClean opens golang-migrate on target and returns the current version, refusing a dirty database
+// openClean: targetでgolang-migrateを開いて現在のバージョンを返す関数、dirtyなデータベースは拒否する
+func openClean(target Database, source Source) (*migrate.Migrate, uint, error) {
+	m, err := Open(target, source)
+	if err != nil {
+		return nil, 0, err
+	}
+	from, dirty, err := CurrentVersion(m)
+	if err != nil {
+		m.Close()
+		return nil, 0, err
+	}
+	if dirty {
+		m.Close()
+		return nil, 0, fmt.Errorf("database is dirty at version %d; fix the schema and run sift-migrate force first", from)
+	}
+	return m, from, nil
+}
+
+// Goto moves target to version to along the plan from its current version, once approve accepts the plan
+// Goto: approveが計画を受け入れた後、targetを現在のバージョンから計画に沿ってバージョンtoへ移動する関数
+// list holds the migrations in source; a plan with nothing to run is returned without calling approve
+// listはsourceのマイグレーション、実行するものがない計画はapproveを呼ばずに返す
+// Moving up refuses checksum drift unless ignoreDrift is set, like Up
+// 上げる場合は、Upと同じくignoreDriftが指定されない限りチェックサムのドリフトを拒否する
+func Goto(target Database, source Source, list []Migration, to uint, ignoreDrift bool, approve Approve) (Plan, error) {
+	m, from, err := openClean(target, source)
+	if err != nil {
+		return Plan{}, err
+	}
+	defer m.Close()
+
+	plan, err := NewPlan(list, from, to)
+	if err != nil || plan.Direction == DirectionNone {
+		return plan, err
+	}
+	if plan.Direction == DirectionUp && !ignoreDrift {
+		if err := refuseDrift(context.Background(), target, list, from); err != nil {
+			return plan, err
+		}
+	}
+	if err := approve(plan); err != nil {
+		return plan, err
+	}
+	if err := target.Timeouts.explain(m.Migrate(to)); err != nil {
+		return plan, fmt.Errorf("failed to migrate to version %d: %w", to, err)
+	}
+	return plan, trackChecksums(target, plan, migrationTimings(m))
+}
+
+// trackChecksums records the checksums and timings of the migrations plan applied, or forgets those of the ones it reverted
+// trackChecksums: planが適用したマイグレーションのチェックサムと時間を記録する、または元に戻したもののチェックサムを削除する関数
+func trackChecksums(target Database, plan Plan, timings map[uint]time.Duration) error {
+	if plan.Direction == DirectionDown {
+		return forgetChecksums(context.Background(), target, plan.Migrations)
+	}
+	return recordChecksums(context.Background(), target, plan.Migrations, timings)
+}
+
+// UpSteps applies the steps oldest pending migrations in target, or all of them when steps is 0
+// UpSteps: targetの古い方からsteps個の未適用のマイグレーションを適用する関数、stepsが0の場合は全て
+// Running out of pending migrations is not an error: the plan returned lists the ones applied.
+// Like Up, it refuses checksum drift unless ignoreDrift is set
+// 未適用のマイグレーションが尽きてもエラーにはせず、返す計画に適用したものを列挙する。
+// Upと同じく、ignoreDriftが指定されない限りチェックサムのドリフトを拒否する
+func UpSteps(target Database, source Source, list []Migration, steps int, ignoreDrift bool) (Plan, error) {
+	m, from, err := openClean(target, source)
+	if err != nil {
+		return Plan{}, err
+	}
+	defer m.Close()
+	if !ignoreDrift {
+		if err := refuseDrift(context.Background(), target, list, from); err != nil {
+			return Plan{From: from, To: from, Direction: DirectionNone}, err
+		}
+	}
+
+	plan, err := UpPlan(list, from, steps)
+	if err != nil || plan.Direction == DirectionNone {
+		return plan, err
+	}
+	if err := target.Timeouts.explain(m.Steps(len(plan.Migrations))); err != nil {
+		return plan, fmt.Errorf("failed to migrate up to version %d: %w", plan.To, err)
+	}
+	return plan, trackChecksums(target, plan, migrationTimings(m))
+}
+
+// Down reverts the steps newest applied migrations in target, or all of them when steps is 0, once approve accepts the plan
+// Down: approveが計画を受け入れた後、targetの新しい方からsteps個の適用済みマイグレーションを元に戻す関数、stepsが0の場合は全て
+func Down(target Database, source Source, list []Migration, steps int, approve Approve) (Plan, error) {
+	m, from, err := openClean(target, source)
+	if err != nil {
+		return Plan{}, err
+	}
+	defer m.Close()
+
+	plan, err := DownPlan(list, from, steps)
+	if err != nil || plan.Direction == DirectionNone {
+		return plan, err
+	}
+	if err := approve(plan); err != nil {
+		return plan, err
+	}
+	if err := target.Timeouts.explain(m.Steps(-len(plan.Migrations))); err != nil {
+		return plan, fmt.Errorf("failed to migrate down to version %d: %w", plan.To, err)
+	}
+	return plan, trackChecksums(target, plan, migrationTimings(m))
+}
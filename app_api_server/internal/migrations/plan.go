@@ -0,0 +1,147 @@
+package migrations
+
+import (
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"strings" // strings: 文字列操作
+)
+
+// Direction is which way a plan moves the schema
+// Direction: 計画がスキーマを動かす向き
+type Direction string
+
+// Plan directions
+// 計画の向き
+const (
+	DirectionUp   Direction = "up"   // up: マイグレーションを適用する
+	DirectionDown Direction = "down" // down: マイグレーションを元に戻す
+	DirectionNone Direction = "none" // none: 既に目標のバージョン
+)
+
+// Plan lists the migrations golang-migrate's Migrate runs to move from one version to another
+// Plan: golang-migrateのMigrateがあるバージョンから別のバージョンへ移るために実行するマイグレーションの一覧
+type Plan struct {
+	From       uint        `json:"from"`       // from: 現在のバージョン、0は未実行
+	To         uint        `json:"to"`         // to: 目標のバージョン
+	Direction  Direction   `json:"direction"`  // direction: 向き
+	Migrations []Migration `json:"migrations"` // migrations: 実行順のマイグレーション、downの場合は元に戻す順
+}
+
+// CheckTarget refuses a target version that has no migration in the directory, before anything connects
+// CheckTarget: ディレクトリにマイグレーションがない目標のバージョンを、接続する前に拒否する関数
+func CheckTarget(migrations []Migration, to uint) error {
+	if _, ok := Find(migrations, to); !ok {
+		return fmt.Errorf("version %d is not in the migrations directory", to)
+	}
+	return nil
+}
+
+// NewPlan works out the migrations to apply or revert to move from version from to version to
+// NewPlan: バージョンfromからバージョンtoへ移るために適用または元に戻すマイグレーションを求める関数
+// Moving up applies each migration above from up to to in version order; moving down reverts each migration
+// from from down to just above to, newest first
+// upの場合はfromより上からtoまでをバージョン順に適用し、downの場合はfromからtoの直前までを新しい順に元に戻す
+func NewPlan(migrations []Migration, from, to uint) (Plan, error) {
+	if err := CheckTarget(migrations, to); err != nil {
+		return Plan{}, err
+	}
+	if _, ok := Find(migrations, from); from > 0 && !ok {
+		return Plan{}, fmt.Errorf("the database's version %d is not in the migrations directory", from)
+	}
+
+	plan := Plan{From: from, To: to, Direction: DirectionNone}
+	switch {
+	case to > from:
+		plan.Direction = DirectionUp
+		for _, migration := range migrations {
+			if migration.Version > from && migration.Version <= to {
+				plan.Migrations = append(plan.Migrations, migration)
+			}
+		}
+	case to < from:
+		plan.Direction = DirectionDown
+		for i := len(migrations) - 1; i >= 0; i-- {
+			if migrations[i].Version > to && migrations[i].Version <= from {
+				plan.Migrations = append(plan.Migrations, migrations[i])
+			}
+		}
+	}
+	return plan, nil
+}
+
+// UpPlan works out the steps oldest migrations above version from to apply; steps 0 applies every pending one
+// UpPlan: バージョンfromより上の古い方からsteps個の適用するマイグレーションを求める関数、stepsが0の場合は未適用の全て
+// Fewer than steps are planned when fewer are pending: 未適用のものがsteps個より少ない場合はその数だけを計画する
+func UpPlan(migrations []Migration, from uint, steps int) (Plan, error) {
+	if steps < 0 {
+		return Plan{}, fmt.Errorf("invalid number of steps %d", steps)
+	}
+	if _, ok := Find(migrations, from); from > 0 && !ok {
+		return Plan{}, fmt.Errorf("the database's version %d is not in the migrations directory", from)
+	}
+
+	plan := Plan{From: from, To: from, Direction: DirectionNone}
+	for _, migration := range migrations {
+		if migration.Version <= from {
+			continue
+		}
+		if steps > 0 && len(plan.Migrations) == steps {
+			break
+		}
+		plan.Migrations = append(plan.Migrations, migration)
+		plan.To = migration.Version
+	}
+	if len(plan.Migrations) > 0 {
+		plan.Direction = DirectionUp
+	}
+	return plan, nil
+}
+
+// DownPlan works out the steps newest migrations at or below version from to revert; steps 0 reverts them all
+// DownPlan: バージョンfrom以下の新しい方からsteps個の元に戻すマイグレーションを求める関数、stepsが0の場合は全て
+// To is the version left afterwards, 0 when nothing remains applied
+// Toは元に戻した後のバージョン、何も適用されていない状態になる場合は0
+func DownPlan(migrations []Migration, from uint, steps int) (Plan, error) {
+	if steps < 0 {
+		return Plan{}, fmt.Errorf("invalid number of steps %d", steps)
+	}
+	if _, ok := Find(migrations, from); from > 0 && !ok {
+		return Plan{}, fmt.Errorf("the database's version %d is not in the migrations directory", from)
+	}
+
+	plan := Plan{From: from, Direction: DirectionNone}
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if migrations[i].Version > from {
+			continue
+		}
+		if steps > 0 && len(plan.Migrations) == steps {
+			plan.To = migrations[i].Version
+			break
+		}
+		plan.Migrations = append(plan.Migrations, migrations[i])
+	}
+	if len(plan.Migrations) == 0 {
+		plan.To = from
+		return plan, nil
+	}
+	plan.Direction = DirectionDown
+	return plan, nil
+}
+
+// String describes the plan, one migration per line
+// String: 計画を1行に1つのマイグレーションで説明する関数
+func (p Plan) String() string {
+	if p.Direction == DirectionNone {
+		return fmt.Sprintf("database is already at version %d\n", p.To)
+	}
+
+	verb := "apply"
+	if p.Direction == DirectionDown {
+		verb = "revert"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "migrate %s from version %d to %d:\n", p.Direction, p.From, p.To)
+	for _, migration := range p.Migrations {
+		fmt.Fprintf(&b, "  %s %d %s\n", verb, migration.Version, migration.Description)
+	}
+	return b.String()
+}
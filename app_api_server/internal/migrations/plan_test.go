@@ -0,0 +1,213 @@
+package migrations
+
+import (
+	"reflect" // reflect: 値の比較
+	"strings" // strings: 文字列操作
+	"testing" // testing: テスト機能
+)
+
+// TestNewPlan tests the direction and the migrations to run against the fixture directories
+// TestNewPlan: フィクスチャのディレクトリに対して向きと実行するマイグレーションをテストする関数
+func TestNewPlan(t *testing.T) {
+	testCases := []struct {
+		name              string
+		dir               string
+		from              uint
+		to                uint
+		expectedDirection Direction
+		expectedVersions  []uint
+		expectError       bool
+		errorContent      string
+	}{
+		{name: "Up from nothing", dir: "testdata/basic", from: 0, to: 2, expectedDirection: DirectionUp, expectedVersions: []uint{1, 2}},
+		{name: "Up over a gap", dir: "testdata/gaps", from: 3, to: 8, expectedDirection: DirectionUp, expectedVersions: []uint{5, 8}},
+		{name: "Down newest first", dir: "testdata/gaps", from: 8, to: 3, expectedDirection: DirectionDown, expectedVersions: []uint{8, 5}},
+		{name: "Down one step", dir: "testdata/basic", from: 10, to: 2, expectedDirection: DirectionDown, expectedVersions: []uint{10}},
+		{name: "Already there", dir: "testdata/basic", from: 2, to: 2, expectedDirection: DirectionNone},
+		{name: "Unknown target", dir: "testdata/gaps", from: 3, to: 4, expectError: true, errorContent: "version 4 is not in the migrations directory"},
+		{name: "Zero target", dir: "testdata/basic", from: 1, to: 0, expectError: true, errorContent: "version 0 is not in the migrations directory"},
+		{name: "Unknown current version", dir: "testdata/basic", from: 5, to: 10, expectError: true, errorContent: "the database's version 5"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			migrations, err := Load(Directory(tc.dir))
+			if err != nil {
+				t.Fatalf("Failed to load fixture: %v", err)
+			}
+			plan, err := NewPlan(migrations, tc.from, tc.to)
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if plan.Direction != tc.expectedDirection {
+				t.Errorf("Expected direction %s, got: %s", tc.expectedDirection, plan.Direction)
+			}
+			var versions []uint
+			for _, migration := range plan.Migrations {
+				versions = append(versions, migration.Version)
+			}
+			if !reflect.DeepEqual(versions, tc.expectedVersions) {
+				t.Errorf("Expected versions %v, got: %v", tc.expectedVersions, versions)
+			}
+		})
+	}
+}
+
+// TestUpPlan tests the migrations up applies for a number of steps, or all of them
+// TestUpPlan: upがステップ数分、または全て適用するマイグレーションをテストする関数
+func TestUpPlan(t *testing.T) {
+	testCases := []struct {
+		name              string
+		from              uint
+		steps             int
+		expectedDirection Direction
+		expectedTo        uint
+		expectedVersions  []uint
+		expectError       bool
+		errorContent      string
+	}{
+		{name: "One step", from: 0, steps: 1, expectedDirection: DirectionUp, expectedTo: 3, expectedVersions: []uint{3}},
+		{name: "Two steps over a gap", from: 3, steps: 2, expectedDirection: DirectionUp, expectedTo: 8, expectedVersions: []uint{5, 8}},
+		{name: "All", from: 0, steps: 0, expectedDirection: DirectionUp, expectedTo: 8, expectedVersions: []uint{3, 5, 8}},
+		{name: "More steps than pending", from: 5, steps: 3, expectedDirection: DirectionUp, expectedTo: 8, expectedVersions: []uint{8}},
+		{name: "Nothing pending", from: 8, steps: 1, expectedDirection: DirectionNone, expectedTo: 8},
+		{name: "Negative steps", from: 0, steps: -1, expectError: true, errorContent: "invalid number of steps -1"},
+		{name: "Unknown current version", from: 4, steps: 1, expectError: true, errorContent: "the database's version 4"},
+	}
+
+	migrations, err := Load(Directory("testdata/gaps"))
+	if err != nil {
+		t.Fatalf("Failed to load fixture: %v", err)
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan, err := UpPlan(migrations, tc.from, tc.steps)
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if plan.Direction != tc.expectedDirection || plan.To != tc.expectedTo {
+				t.Errorf("Expected %s to %d, got: %s to %d", tc.expectedDirection, tc.expectedTo, plan.Direction, plan.To)
+			}
+			var versions []uint
+			for _, migration := range plan.Migrations {
+				versions = append(versions, migration.Version)
+			}
+			if !reflect.DeepEqual(versions, tc.expectedVersions) {
+				t.Errorf("Expected versions %v, got: %v", tc.expectedVersions, versions)
+			}
+		})
+	}
+}
+
+// TestDownPlan tests the migrations down reverts for a number of steps, or all of them
+// TestDownPlan: downがステップ数分、または全て元に戻すマイグレーションをテストする関数
+func TestDownPlan(t *testing.T) {
+	testCases := []struct {
+		name              string
+		from              uint
+		steps             int
+		expectedDirection Direction
+		expectedTo        uint
+		expectedVersions  []uint
+		expectError       bool
+		errorContent      string
+	}{
+		{name: "One step", from: 8, steps: 1, expectedDirection: DirectionDown, expectedTo: 5, expectedVersions: []uint{8}},
+		{name: "Two steps over a gap", from: 8, steps: 2, expectedDirection: DirectionDown, expectedTo: 3, expectedVersions: []uint{8, 5}},
+		{name: "All", from: 5, steps: 0, expectedDirection: DirectionDown, expectedTo: 0, expectedVersions: []uint{5, 3}},
+		{name: "More steps than applied", from: 3, steps: 4, expectedDirection: DirectionDown, expectedTo: 0, expectedVersions: []uint{3}},
+		{name: "Nothing applied", from: 0, steps: 1, expectedDirection: DirectionNone, expectedTo: 0},
+		{name: "Negative steps", from: 8, steps: -1, expectError: true, errorContent: "invalid number of steps -1"},
+		{name: "Unknown current version", from: 4, steps: 1, expectError: true, errorContent: "the database's version 4"},
+	}
+
+	migrations, err := Load(Directory("testdata/gaps"))
+	if err != nil {
+		t.Fatalf("Failed to load fixture: %v", err)
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan, err := DownPlan(migrations, tc.from, tc.steps)
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if plan.Direction != tc.expectedDirection || plan.To != tc.expectedTo {
+				t.Errorf("Expected %s to %d, got: %s to %d", tc.expectedDirection, tc.expectedTo, plan.Direction, plan.To)
+			}
+			var versions []uint
+			for _, migration := range plan.Migrations {
+				versions = append(versions, migration.Version)
+			}
+			if !reflect.DeepEqual(versions, tc.expectedVersions) {
+				t.Errorf("Expected versions %v, got: %v", tc.expectedVersions, versions)
+			}
+		})
+	}
+}
+
+// TestPlanString tests the printed plan
+// TestPlanString: 表示する計画をテストする関数
+func TestPlanString(t *testing.T) {
+	migrations, err := Load(Directory("testdata/gaps"))
+	if err != nil {
+		t.Fatalf("Failed to load fixture: %v", err)
+	}
+	plan, err := NewPlan(migrations, 8, 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	expected := "migrate down from version 8 to 3:\n  revert 8 create invoices\n  revert 5 add orders status\n"
+	if plan.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, plan.String())
+	}
+}
+
+// TestConfirm tests that only the expected answer confirms
+// TestConfirm: 期待する回答のみが確認とみなされることをテストする関数
+func TestConfirm(t *testing.T) {
+	testCases := []struct {
+		name     string
+		answer   string
+		expected bool
+	}{
+		{name: "Expected answer", answer: "yes\n", expected: true},
+		{name: "Surrounding spaces", answer: "  yes \n", expected: true},
+		{name: "Answer without newline", answer: "yes", expected: true},
+		{name: "Other answer", answer: "y\n", expected: false},
+		{name: "No answer", answer: "", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out strings.Builder
+			ok, err := Confirm(strings.NewReader(tc.answer), &out, "Continue? ", "yes")
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if ok != tc.expected {
+				t.Errorf("Expected %v, got: %v", tc.expected, ok)
+			}
+			if out.String() != "Continue? " {
+				t.Errorf("Expected the prompt to be written, got: %q", out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package migrations
+
+import (
+	"context"       // context: コンテキスト
+	"database/sql"  // sql: シードのトランザクション
+	"errors"        // errors: エラー操作
+	"fmt"           // fmt: format（フォーマット）、文字列フォーマット機能
+	"os"            // os: operating system（オペレーティングシステム）、シードファイルの読み込み
+	"path/filepath" // filepath: ファイルパス操作
+	"slices"        // slices: スライス操作
+	"sort"          // sort: 名前順の並べ替え
+	"strings"       // strings: 文字列操作
+
+	"api/internal/database" // database: スクリプトの実行と管理者の作成
+)
+
+// DefaultSeedsPath is the seeds directory used when neither --seeds-path nor SEEDS_PATH is given and it exists
+// DefaultSeedsPath: --seeds-pathとSEEDS_PATHのどちらも指定されず、存在する場合に使うシードのディレクトリ
+const DefaultSeedsPath = "seeds"
+
+// SeedOptions chooses what Seed runs
+// SeedOptions: Seedが実行する内容の設定
+type SeedOptions struct {
+	Dir   string                         // dir: シードファイルのディレクトリ、空の場合はファイルを実行しない
+	Only  string                         // only: このシードファイルのみを実行する、拡張子の.sqlは省略可
+	Admin *database.AdminBootstrapConfig // admin: 存在を保証する管理者、nilまたはOnly指定時は作成しない
+}
+
+// SeedResult reports what Seed ran
+// SeedResult: Seedが実行した内容の報告
+type SeedResult struct {
+	Files []string `json:"files"`           // files: 名前順に実行したシードファイル
+	Admin string   `json:"admin,omitempty"` // admin: 存在を確認した管理者のメールアドレス
+}
+
+// CheckSeedAllowed refuses to seed a production environment (see database.ProductionEnvironment) unless force is set
+// CheckSeedAllowed: forceが指定されない限り本番環境（database.ProductionEnvironmentを参照）へのシードを拒否する関数
+func CheckSeedAllowed(force bool) error {
+	if env, ok := database.ProductionEnvironment(); ok && !force {
+		return fmt.Errorf("seed does not run when APP_ENV is %s; pass --force to override", env)
+	}
+	return nil
+}
+
+// ResolveSeedsPath returns the seeds directory: flagValue, then SEEDS_PATH, then DefaultSeedsPath when it exists
+// ResolveSeedsPath: シードのディレクトリを返す関数、flagValue、次にSEEDS_PATH、次に存在する場合はDefaultSeedsPath
+// It returns "" when none is given and DefaultSeedsPath does not exist: どれも指定されずDefaultSeedsPathもない場合は""を返す
+func ResolveSeedsPath(flagValue string) (string, error) {
+	path := flagValue
+	if path == "" {
+		path = strings.TrimSpace(os.Getenv("SEEDS_PATH"))
+	}
+	if path == "" {
+		if info, err := os.Stat(DefaultSeedsPath); err != nil || !info.IsDir() {
+			return "", nil
+		}
+		return DefaultSeedsPath, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("seeds directory: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("seeds directory: %s is not a directory", path)
+	}
+	return path, nil
+}
+
+// SeedFiles lists the .sql files in dir in name order
+// SeedFiles: dirの.sqlファイルを名前順に一覧にする関数
+func SeedFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seeds directory: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), ".sql") {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// selectSeeds returns the files Seed runs: every one in dir, or the one named only
+// selectSeeds: Seedが実行するファイルを返す関数、dirの全て、またはonlyという名前の1つ
+func selectSeeds(dir, only string) ([]string, error) {
+	if dir == "" {
+		if only != "" {
+			return nil, errors.New("--only needs a seeds directory")
+		}
+		return nil, nil
+	}
+	files, err := SeedFiles(dir)
+	if err != nil || only == "" {
+		return files, err
+	}
+	name := only
+	if !strings.HasSuffix(name, ".sql") {
+		name += ".sql"
+	}
+	if !slices.Contains(files, name) {
+		return nil, fmt.Errorf("seed %s is not in %s", name, dir)
+	}
+	return []string{name}, nil
+}
+
+// Seed runs the seed files opts selects in name order, then ensures opts.Admin exists, all in one transaction
+// Seed: optsが選んだシードファイルを名前順に実行し、次にopts.Adminの存在を保証する関数、全てを1つのトランザクションで行う
+// A failure rolls every seed back, so a partial seed never persists; seeds are not versioned like migrations,
+// so each file should be safe to run again (INSERT ... ON CONFLICT DO NOTHING)
+// 失敗すると全てのシードをロールバックするため、途中までのシードは残らない。シードはマイグレーションと異なり
+// バージョン管理されないため、各ファイルは再実行しても安全に書く（INSERT ... ON CONFLICT DO NOTHING）
+func Seed(ctx context.Context, driver *database.PostgreSQLDriver, opts SeedOptions) (SeedResult, error) {
+	files, err := selectSeeds(opts.Dir, opts.Only)
+	if err != nil {
+		return SeedResult{}, err
+	}
+
+	result := SeedResult{Files: []string{}}
+	err = driver.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		for _, name := range files {
+			script, err := os.ReadFile(filepath.Join(opts.Dir, name))
+			if err != nil {
+				return fmt.Errorf("failed to read seed %s: %w", name, err)
+			}
+			// ExecScript joins the transaction in ctx: ExecScriptはctxのトランザクションに参加する
+			if err := driver.ExecScript(ctx, string(script)); err != nil {
+				return fmt.Errorf("seed %s failed: %w", name, err)
+			}
+			result.Files = append(result.Files, name)
+		}
+		if opts.Admin != nil && opts.Only == "" {
+			if err := database.EnsureAdminUser(ctx, driver, *opts.Admin); err != nil {
+				return err
+			}
+			result.Admin = opts.Admin.Email
+		}
+		return nil
+	})
+	if err != nil {
+		return SeedResult{Files: []string{}}, fmt.Errorf("%w; no seed was kept", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,86 @@
+package migrations
+
+import (
+	"embed" // embed: SQLファイルのバイナリへの埋め込み
+	"fmt"   // fmt: format（フォーマット）、文字列フォーマット機能
+	"io/fs" // fs: file system（ファイルシステム）
+	"os"    // os: operating system（オペレーティングシステム）、ディレクトリと環境変数
+)
+
+// embedded holds the application's migrations, so the binary needs no migrations directory beside it
+// embedded: アプリケーションのマイグレーション、バイナリの横にマイグレーションのディレクトリが不要になる
+//
+//go:embed sql/*.sql
+var embedded embed.FS
+
+// embeddedRoot is the directory of the SQL files inside embedded
+// embeddedRoot: embedded内のSQLファイルのディレクトリ
+const embeddedRoot = "sql"
+
+// DefaultPath is the source directory of the embedded migrations, relative to the module root; sift-migrate create writes there
+// DefaultPath: 埋め込むマイグレーションのソースディレクトリ、モジュールのルートからの相対パス。sift-migrate createはここに書き込む
+const DefaultPath = "internal/migrations/" + embeddedRoot
+
+// PathUsage describes the --migrations-path flag of the subcommands that read migrations
+// PathUsage: マイグレーションを読むサブコマンドの--migrations-pathフラグの説明
+const PathUsage = "directory of the migration files (default $MIGRATIONS_PATH, then the migrations embedded in the binary)"
+
+// Source is where migration files are read from: the embedded set or a directory on disk
+// Source: マイグレーションファイルの読み込み元、埋め込みのファイルまたはディスク上のディレクトリ
+type Source struct {
+	fsys fs.FS  // fsys: ファイルの読み込み元
+	root string // root: fsys内のディレクトリ
+	Path string // path: ディスク上のディレクトリ、埋め込みの場合は空
+}
+
+// Embedded returns the migrations built into the binary
+// Embedded: バイナリに組み込まれたマイグレーションを返す関数
+func Embedded() Source {
+	return Source{fsys: embedded, root: embeddedRoot}
+}
+
+// Directory returns the migrations in a directory on disk
+// Directory: ディスク上のディレクトリのマイグレーションを返す関数
+func Directory(path string) Source {
+	return Source{fsys: os.DirFS(path), root: ".", Path: path}
+}
+
+// String names the source in messages
+// String: メッセージ用に読み込み元の名前を返す関数
+func (s Source) String() string {
+	if s.Path == "" {
+		return "embedded migrations"
+	}
+	return s.Path
+}
+
+// ResolveSource picks the directory from flagValue, then MIGRATIONS_PATH, and otherwise the embedded migrations
+// ResolveSource: flagValue、MIGRATIONS_PATHの順にディレクトリを選び、どちらもなければ埋め込みのマイグレーションを選ぶ関数
+// The file-path source is only a fallback for an explicit choice, so the binary behaves the same wherever it runs
+// ディスク上のディレクトリは明示的に指定した場合のみ使うため、バイナリはどこで実行しても同じように動く
+func ResolveSource(flagValue string) (Source, error) {
+	path := flagValue
+	if path == "" {
+		path = os.Getenv("MIGRATIONS_PATH")
+	}
+	if path == "" {
+		return Embedded(), nil
+	}
+	if err := checkDirectory(path); err != nil {
+		return Source{}, err
+	}
+	return Directory(path), nil
+}
+
+// checkDirectory fails unless path is an existing directory
+// checkDirectory: pathが存在するディレクトリでなければエラーを返す関数
+func checkDirectory(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("migrations directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("migrations path %s is not a directory", path)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	"io/fs"   // fs: file system（ファイルシステム）
+	"strings" // strings: 文字列操作
+	"testing" // testing: テスト機能
+)
+
+// TestEmbeddedMigrations tests that the embedded set is not empty and that every up has a matching down
+// TestEmbeddedMigrations: 埋め込みのマイグレーションが空でなく、全てのupに対応するdownがあることをテストする関数
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := Load(Embedded())
+	if err != nil {
+		t.Fatalf("Failed to load the embedded migrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Expected embedded migrations, got none")
+	}
+
+	entries, err := fs.ReadDir(embedded, embeddedRoot)
+	if err != nil {
+		t.Fatalf("Failed to read the embedded directory: %v", err)
+	}
+	files := map[string]bool{}
+	for _, entry := range entries {
+		files[entry.Name()] = true
+	}
+	for _, migration := range migrations {
+		down := strings.Replace(migration.File, ".up.", ".down.", 1)
+		if !files[down] {
+			t.Errorf("Expected %s for %s", down, migration.File)
+		}
+		delete(files, migration.File)
+		delete(files, down)
+	}
+	for name := range files {
+		t.Errorf("Expected every embedded file to belong to an up migration, got: %s", name)
+	}
+}
+
+// TestResolveSource tests the flag > MIGRATIONS_PATH > embedded precedence
+// TestResolveSource: フラグ > MIGRATIONS_PATH > 埋め込みの優先順位をテストする関数
+func TestResolveSource(t *testing.T) {
+	testCases := []struct {
+		name         string
+		flagValue    string
+		env          string
+		expected     string
+		expectError  bool
+		errorContent string
+	}{
+		{name: "Flag wins", flagValue: "testdata/basic", env: "testdata/empty", expected: "testdata/basic"},
+		{name: "Environment", env: "testdata/empty", expected: "testdata/empty"},
+		{name: "Embedded by default", expected: "embedded migrations"},
+		{name: "Explicit path must exist", env: "testdata/missing", expectError: true, errorContent: "migrations directory: stat testdata/missing"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("MIGRATIONS_PATH", tc.env)
+			source, err := ResolveSource(tc.flagValue)
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContent) {
+					t.Errorf("Expected error containing '%s', got: %v", tc.errorContent, err)
+				}
+				return
+			}
+			if err != nil || source.String() != tc.expected {
+				t.Errorf("Expected %s, got: %s, %v", tc.expected, source, err)
+			}
+		})
+	}
+}
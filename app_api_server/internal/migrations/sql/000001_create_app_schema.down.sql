@@ -0,0 +1,4 @@
+-- Drop the first tables; the schema and extension stay for init.sql
+-- 最初のテーブルを削除する、スキーマと拡張機能はinit.sqlのために残す
+DROP TABLE IF EXISTS app.sessions;
+DROP TABLE IF EXISTS app.users;
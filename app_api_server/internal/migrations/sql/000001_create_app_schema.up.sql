@@ -0,0 +1,33 @@
+-- Create the application schema and its first tables
+-- アプリケーションのスキーマと最初のテーブルを作成する
+-- IF NOT EXISTS keeps this a no-op on databases initialized by scripts/postgres/init.sql
+-- scripts/postgres/init.sqlで初期化したデータベースでは何もしないようIF NOT EXISTSを付ける
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";  -- uuid: 汎用一意識別子、ossp: UUID生成機能
+
+CREATE SCHEMA IF NOT EXISTS app;
+
+CREATE TABLE IF NOT EXISTS app.users (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    email VARCHAR(255) UNIQUE NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    first_name VARCHAR(100),
+    last_name VARCHAR(100),
+    is_active BOOLEAN DEFAULT TRUE,
+    is_verified BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_email ON app.users(email);
+CREATE INDEX IF NOT EXISTS idx_users_active ON app.users(is_active);
+
+CREATE TABLE IF NOT EXISTS app.sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES app.users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(255) UNIQUE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON app.sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_token ON app.sessions(token_hash);
@@ -0,0 +1,155 @@
+package migrations
+
+import (
+	"context" // context: コンテキスト
+	"errors"  // errors: エラー操作
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+	"os"      // os: operating system（オペレーティングシステム）、環境変数
+	"strconv" // strconv: string conversion（文字列変換）
+	"strings" // strings: 文字列操作
+
+	"api/internal/database" // database: データベース接続とマイグレーションのバージョン確認
+)
+
+// StartupOptions chooses what the server does about the schema before it serves
+// StartupOptions: サーバーがサービス開始前にスキーマに対して行う処理を選ぶ設定
+type StartupOptions struct {
+	AutoMigrate         bool // AUTO_MIGRATE: 未適用のマイグレーションを起動時に適用する
+	SkipCheck           bool // SKIP_MIGRATION_CHECK: 自動適用しない場合のバージョン確認を省略する
+	IgnoreChecksumDrift bool // IGNORE_CHECKSUM_DRIFT: 自動適用の際、チェックサムのドリフトを拒否せず警告のみにする
+
+	Timeouts Timeouts // MIGRATE_LOCK_TIMEOUT, MIGRATE_STATEMENT_TIMEOUT: 自動適用の接続のタイムアウト、sift-migrateと同じ
+}
+
+// LoadStartupOptions reads AUTO_MIGRATE, SKIP_MIGRATION_CHECK and IGNORE_CHECKSUM_DRIFT, all defaulting to false,
+// and the timeouts as LoadTimeouts does
+// LoadStartupOptions: AUTO_MIGRATE、SKIP_MIGRATION_CHECK、IGNORE_CHECKSUM_DRIFT（いずれもデフォルトはfalse）と、
+// LoadTimeoutsと同じくタイムアウトを読み込む関数
+func LoadStartupOptions() (StartupOptions, error) {
+	autoMigrate, err := envBool("AUTO_MIGRATE")
+	if err != nil {
+		return StartupOptions{}, err
+	}
+	skipCheck, err := envBool("SKIP_MIGRATION_CHECK")
+	if err != nil {
+		return StartupOptions{}, err
+	}
+	ignoreDrift, err := envBool("IGNORE_CHECKSUM_DRIFT")
+	if err != nil {
+		return StartupOptions{}, err
+	}
+	timeouts, err := LoadTimeouts()
+	if err != nil {
+		return StartupOptions{}, err
+	}
+	return StartupOptions{AutoMigrate: autoMigrate, SkipCheck: skipCheck, IgnoreChecksumDrift: ignoreDrift, Timeouts: timeouts}, nil
+}
+
+// envBool parses the boolean variable key, treating an unset one as false
+// envBool: 真偽値の環境変数keyを解析する関数、未設定の場合はfalse
+func envBool(key string) (bool, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %q is not a boolean", key, value)
+	}
+	return enabled, nil
+}
+
+// Latest returns the newest version in migrations, or 0 when there is none
+// Latest: migrationsの最新のバージョンを返す関数、1つもない場合は0
+func Latest(migrations []Migration) uint {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// PrepareSchema brings the schema up to date or checks it, as opts chooses, before the server starts
+// PrepareSchema: optsの選択に従い、サーバーの起動前にスキーマを最新にする、または確認する関数
+// With AutoMigrate it applies the pending migrations in source under the migration lock, logging each version,
+// and refuses checksum drift unless IgnoreChecksumDrift is set;
+// otherwise it refuses a schema behind the newest migration in source unless SkipCheck is set
+// AutoMigrateの場合はマイグレーションのロックの下でsourceの未適用のマイグレーションを適用し、各バージョンを記録する。
+// IgnoreChecksumDriftが指定されない限り、チェックサムのドリフトは拒否する。
+// それ以外の場合、SkipCheckが指定されない限り、sourceの最新のマイグレーションより古いスキーマを拒否する
+func PrepareSchema(ctx context.Context, driver *database.PostgreSQLDriver, source Source, opts StartupOptions, logf func(format string, args ...any)) error {
+	if !opts.AutoMigrate && opts.SkipCheck {
+		logf("skipping the migration check (SKIP_MIGRATION_CHECK)")
+		return nil
+	}
+	list, err := Load(source)
+	if err != nil {
+		return err
+	}
+	if !opts.AutoMigrate {
+		return driver.CheckMigrationVersion(ctx, Latest(list))
+	}
+
+	// A blocked ALTER would hang startup without the lock timeout: ロックのタイムアウトがないと、待たされたALTERが起動を止める
+	target := Database{DB: driver.GetDB(), Timeouts: opts.Timeouts}
+	return WithLock(target, DefaultLockWait, func() error {
+		return applyPending(target, source, list, opts.IgnoreChecksumDrift, logf)
+	})
+}
+
+// applyPending applies the migrations in list newer than target's version one at a time, logging each
+// applyPending: targetのバージョンより新しいlistのマイグレーションを1つずつ適用し、それぞれを記録する関数
+// A dirty schema is refused, since only a person can tell how far the failed migration got,
+// and so is checksum drift unless ignoreDrift is set, in which case it is only logged
+// 失敗したマイグレーションがどこまで進んだかは人にしか判断できないため、dirtyなスキーマは拒否する。
+// チェックサムのドリフトもignoreDriftが指定されない限り拒否し、指定された場合は記録のみ行う
+func applyPending(target Database, source Source, list []Migration, ignoreDrift bool, logf func(format string, args ...any)) error {
+	m, err := Open(target, source)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	from, dirty, err := CurrentVersion(m)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("%w: migration %d did not complete; fix the schema and run sift-migrate force before starting", database.ErrMigrationDirty, from)
+	}
+
+	ctx := context.Background()
+	if err := checkDrift(ctx, target, list, from); err != nil {
+		if !ignoreDrift || !errors.Is(err, ErrChecksumDrift) {
+			return startupDriftError(err)
+		}
+		logf("warning: %v", err)
+	}
+
+	applied := 0
+	for _, migration := range list {
+		if migration.Version <= from {
+			continue
+		}
+		if err := target.Timeouts.explain(m.Migrate(migration.Version)); err != nil {
+			return fmt.Errorf("migration %d failed: %w", migration.Version, err)
+		}
+		if err := recordChecksums(ctx, target, []Migration{migration}, migrationTimings(m)); err != nil {
+			return err
+		}
+		logf("applied migration %d %s", migration.Version, migration.Description)
+		applied++
+	}
+	if applied == 0 {
+		logf("schema is up to date at version %d", from)
+	}
+	return nil
+}
+
+// startupDriftError names the variable that lets startup go on despite checksum drift
+// startupDriftError: チェックサムのドリフトがあっても起動を続けるための環境変数を案内する関数
+func startupDriftError(err error) error {
+	if errors.Is(err, ErrChecksumDrift) {
+		return fmt.Errorf("%w; restore the files, or set IGNORE_CHECKSUM_DRIFT=true to start anyway", err)
+	}
+	return err
+}
@@ -0,0 +1,147 @@
+package migrations
+
+import (
+	"context"        // context: コンテキスト
+	"fmt"            // fmt: format（フォーマット）、文字列フォーマット機能
+	"io"             // io: 入出力
+	"strings"        // strings: 文字列操作
+	"text/tabwriter" // tabwriter: 表の列揃え
+)
+
+// State is where one migration stands relative to the database
+// State: データベースに対する1つのマイグレーションの状態
+type State string
+
+// Migration states
+// マイグレーションの状態
+const (
+	StateApplied State = "applied" // applied: 適用済み
+	StatePending State = "pending" // pending: 未適用
+	StateDirty   State = "dirty"   // dirty: 途中で失敗した
+	// modified: 適用後にファイルが変更された
+	StateModified State = "modified after apply"
+)
+
+// Exit codes of the status command, so CI can gate on them
+// statusコマンドの終了コード、CIで判定に使える
+const (
+	ExitUpToDate = 0 // up to date: 最新
+	ExitPending  = 1 // pending: 未適用のマイグレーションがある
+	ExitDirty    = 2 // dirty: データベースがdirty
+)
+
+// MigrationStatus is one migration and its state
+// MigrationStatus: 1つのマイグレーションとその状態
+type MigrationStatus struct {
+	Migration
+	State State `json:"state"` // state: 状態
+}
+
+// Status compares the migrations directory with the version recorded in the database
+// Status: マイグレーションのディレクトリとデータベースに記録されたバージョンの比較結果
+// golang-migrate records only the latest version, so every migration up to it counts as applied
+// golang-migrateは最新のバージョンのみを記録するため、そのバージョンまでのマイグレーションを全て適用済みとみなす
+type Status struct {
+	Version    uint              `json:"version"`    // version: データベースのバージョン、0は未実行
+	Dirty      bool              `json:"dirty"`      // dirty: 最後のマイグレーションが途中で失敗したか
+	Migrations []MigrationStatus `json:"migrations"` // migrations: バージョン順のマイグレーション
+	Applied    int               `json:"applied"`    // applied: 適用済みの数
+	Pending    int               `json:"pending"`    // pending: 未適用の数
+	Modified   int               `json:"modified"`   // modified: 適用後に変更された数
+	Unknown    bool              `json:"unknown"`    // unknown: データベースのバージョンがディレクトリにない
+}
+
+// NewStatus works out the state of each migration from the database's version and dirty flag, and the checksums recorded
+// when the migrations were applied; an applied migration whose file no longer matches is StateModified
+// NewStatus: データベースのバージョンとdirtyフラグ、適用時に記録したチェックサムから各マイグレーションの状態を求める関数、
+// ファイルが一致しなくなった適用済みのマイグレーションはStateModified
+func NewStatus(migrations []Migration, version uint, dirty bool, recorded map[uint]string) Status {
+	status := Status{Version: version, Dirty: dirty, Migrations: make([]MigrationStatus, 0, len(migrations))}
+	for _, migration := range migrations {
+		state := StatePending
+		sum, tracked := recorded[migration.Version]
+		switch {
+		case dirty && migration.Version == version:
+			state = StateDirty
+		case migration.Version <= version && tracked && sum != migration.Checksum:
+			state = StateModified
+			status.Applied++
+			status.Modified++
+		case migration.Version <= version:
+			state = StateApplied
+			status.Applied++
+		default:
+			status.Pending++
+		}
+		status.Migrations = append(status.Migrations, MigrationStatus{Migration: migration, State: state})
+	}
+	if _, ok := Find(migrations, version); version > 0 && !ok {
+		status.Unknown = true
+	}
+	return status
+}
+
+// ReadStatus reads the version and the checksums recorded in target and compares them with migrations
+// ReadStatus: targetに記録されたバージョンとチェックサムを読み、migrationsと比較する関数
+func ReadStatus(ctx context.Context, target Database, migrations []Migration) (Status, error) {
+	version, dirty, err := ReadVersion(ctx, target)
+	if err != nil {
+		return Status{}, err
+	}
+	recorded, err := ReadChecksums(ctx, target)
+	if err != nil {
+		return Status{}, err
+	}
+	return NewStatus(migrations, version, dirty, recorded), nil
+}
+
+// ExitCode returns ExitDirty, ExitPending or ExitUpToDate, in that order of precedence
+// ExitCode: ExitDirty、ExitPending、ExitUpToDateの順の優先度で終了コードを返す関数
+func (s Status) ExitCode() int {
+	switch {
+	case s.Dirty:
+		return ExitDirty
+	case s.Pending > 0:
+		return ExitPending
+	}
+	return ExitUpToDate
+}
+
+// Summary describes the status in one line
+// Summary: 状態を1行で説明する関数
+func (s Status) Summary() string {
+	var summary string
+	switch {
+	case s.Dirty:
+		summary = fmt.Sprintf("database is dirty at version %d; fix the schema and force the version", s.Version)
+	case s.Pending > 0:
+		summary = fmt.Sprintf("database is at version %d with %d pending migration(s)", s.Version, s.Pending)
+	default:
+		summary = fmt.Sprintf("database is up to date at version %d", s.Version)
+	}
+	summary += fmt.Sprintf(" (%d applied, %d pending)", s.Applied, s.Pending)
+	if s.Unknown {
+		summary += fmt.Sprintf("; version %d is not in the migrations directory", s.Version)
+	}
+	if s.Modified > 0 {
+		summary += fmt.Sprintf("; %d applied migration(s) modified after apply", s.Modified)
+	}
+	return summary
+}
+
+// WriteText writes a version/description/state table followed by the summary line
+// WriteText: バージョン、説明、状態の表と、続けて要約の行を書き出す関数
+func (s Status) WriteText(w io.Writer) error {
+	var b strings.Builder
+	table := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "VERSION\tDESCRIPTION\tSTATE")
+	for _, migration := range s.Migrations {
+		fmt.Fprintf(table, "%d\t%s\t%s\n", migration.Version, migration.Description, migration.State)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(&b, "\n%s\n", s.Summary())
+	_, err := io.WriteString(w, b.String())
+	return err
+}
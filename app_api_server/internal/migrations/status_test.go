@@ -0,0 +1,99 @@
+package migrations
+
+import (
+	"encoding/json" // json: JSON出力の確認
+	"reflect"       // reflect: 値の比較
+	"strings"       // strings: 文字列操作
+	"testing"       // testing: テスト機能
+)
+
+// TestNewStatus tests the states, counts, exit code and summary for database versions against the basic fixture
+// TestNewStatus: basicフィクスチャに対するデータベースのバージョンごとの状態、件数、終了コード、要約をテストする関数
+func TestNewStatus(t *testing.T) {
+	migrations, err := Load(Directory("testdata/basic"))
+	if err != nil {
+		t.Fatalf("Failed to load fixture: %v", err)
+	}
+
+	testCases := []struct {
+		name            string
+		version         uint
+		dirty           bool
+		recorded        map[uint]string
+		expectedStates  []State
+		expectedExit    int
+		expectedSummary string
+	}{
+		{name: "Nothing applied", version: 0, expectedStates: []State{StatePending, StatePending, StatePending},
+			expectedExit: ExitPending, expectedSummary: "database is at version 0 with 3 pending migration(s) (0 applied, 3 pending)"},
+		{name: "Partly applied", version: 2, expectedStates: []State{StateApplied, StateApplied, StatePending},
+			expectedExit: ExitPending, expectedSummary: "database is at version 2 with 1 pending migration(s) (2 applied, 1 pending)"},
+		{name: "Up to date", version: 10, expectedStates: []State{StateApplied, StateApplied, StateApplied},
+			expectedExit: ExitUpToDate, expectedSummary: "database is up to date at version 10 (3 applied, 0 pending)"},
+		{name: "Dirty", version: 2, dirty: true, expectedStates: []State{StateApplied, StateDirty, StatePending},
+			expectedExit: ExitDirty, expectedSummary: "database is dirty at version 2; fix the schema and force the version (1 applied, 1 pending)"},
+		{name: "Version missing from the directory", version: 5, expectedStates: []State{StateApplied, StateApplied, StatePending},
+			expectedExit: ExitPending, expectedSummary: "(2 applied, 1 pending); version 5 is not in the migrations directory"},
+		{name: "Modified after apply", version: 10, recorded: map[uint]string{1: migrations[0].Checksum, 2: strings.Repeat("0", 64)},
+			expectedStates: []State{StateApplied, StateModified, StateApplied},
+			expectedExit:   ExitUpToDate, expectedSummary: "(3 applied, 0 pending); 1 applied migration(s) modified after apply"},
+		{name: "Checksum of a pending migration is not compared", version: 1, recorded: map[uint]string{2: strings.Repeat("0", 64)},
+			expectedStates: []State{StateApplied, StatePending, StatePending},
+			expectedExit:   ExitPending, expectedSummary: "(1 applied, 2 pending)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := NewStatus(migrations, tc.version, tc.dirty, tc.recorded)
+
+			var states []State
+			for _, migration := range status.Migrations {
+				states = append(states, migration.State)
+			}
+			if !reflect.DeepEqual(states, tc.expectedStates) {
+				t.Errorf("Expected states %v, got: %v", tc.expectedStates, states)
+			}
+			if code := status.ExitCode(); code != tc.expectedExit {
+				t.Errorf("Expected exit code %d, got: %d", tc.expectedExit, code)
+			}
+			if summary := status.Summary(); !strings.Contains(summary, tc.expectedSummary) {
+				t.Errorf("Expected summary containing '%s', got: %s", tc.expectedSummary, summary)
+			}
+		})
+	}
+}
+
+// TestStatusOutput tests the text table and the JSON shape
+// TestStatusOutput: テキストの表とJSONの形式をテストする関数
+func TestStatusOutput(t *testing.T) {
+	migrations, err := Load(Directory("testdata/basic"))
+	if err != nil {
+		t.Fatalf("Failed to load fixture: %v", err)
+	}
+	status := NewStatus(migrations, 1, false, nil)
+
+	var text strings.Builder
+	if err := status.WriteText(&text); err != nil {
+		t.Fatalf("Failed to write text: %v", err)
+	}
+	expected := "VERSION  DESCRIPTION      STATE\n" +
+		"1        create users     applied\n" +
+		"2        add users index  pending\n" +
+		"10       create sessions  pending\n" +
+		"\n" +
+		"database is at version 1 with 2 pending migration(s) (1 applied, 2 pending)\n"
+	if text.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, text.String())
+	}
+
+	encoded, err := json.Marshal(status)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	for _, field := range []string{`"version":1`, `"dirty":false`, `"applied":1`, `"pending":2`, `"modified":0`,
+		`{"version":2,"description":"add users index","file":"000002_add_users_index.up.sql","checksum":"` + migrations[1].Checksum + `","state":"pending"}`} {
+		if !strings.Contains(string(encoded), field) {
+			t.Errorf("Expected %s in the JSON, got: %s", field, encoded)
+		}
+	}
+}
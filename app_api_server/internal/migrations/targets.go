@@ -0,0 +1,202 @@
+package migrations
+
+import (
+	"context"       // context: コンテキスト
+	"database/sql"  // sql: バージョンの読み込み
+	"errors"        // errors: エラー操作
+	"fmt"           // fmt: format（フォーマット）、文字列フォーマット機能
+	"path/filepath" // filepath: 規約によるディレクトリのパス
+	"regexp"        // regexp: ターゲット名の検証
+	"strings"       // strings: 文字列操作
+
+	"github.com/lib/pq" // pq: テーブル名のクォート
+)
+
+// DefaultTarget names the application's migrations: --migrations-path (or the embedded ones) recorded in schema_migrations
+// DefaultTarget: アプリケーションのマイグレーションの名前、schema_migrationsに記録する--migrations-path（または埋め込み）のマイグレーション
+const DefaultTarget = "app"
+
+// DefaultMigrationsTable is the table golang-migrate records the default target's version in
+// DefaultMigrationsTable: golang-migrateがデフォルトのターゲットのバージョンを記録するテーブル
+const DefaultMigrationsTable = "schema_migrations"
+
+// TargetUsage describes the --target flag: --targetフラグの説明
+const TargetUsage = "migration target to use: " + DefaultTarget + " (the default), or one declared in $MIGRATION_TARGETS"
+
+// targetNamePattern keeps a target name usable in a table name: ターゲット名をテーブル名に使える形に限定する
+var targetNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Target is a set of migrations versioned on its own: a source and the table recording its version
+// Target: 独立してバージョン管理するマイグレーションの組、読み込み元とそのバージョンを記録するテーブル
+type Target struct {
+	Name            string `json:"name"`             // name: ターゲット名
+	Source          Source `json:"-"`                // source: マイグレーションファイルの読み込み元
+	MigrationsTable string `json:"migrations_table"` // migrations table: バージョンを記録するテーブル
+}
+
+// On returns base, which supplies the pool and timeouts, with the target's version and checksums tables
+// On: 接続プールとタイムアウトを与えるbaseに、ターゲットのバージョンとチェックサムのテーブルを設定して返す関数
+// Each target other than DefaultTarget keeps its checksums in <name>_sift_migration_checksums
+// DefaultTarget以外の各ターゲットは<name>_sift_migration_checksumsにチェックサムを記録する
+func (t Target) On(base Database) Database {
+	base.MigrationsTable = t.MigrationsTable
+	base.ChecksumsTable = ""
+	if t.Name != DefaultTarget {
+		base.ChecksumsTable = t.Name + "_" + DefaultChecksumsTable
+	}
+	return base
+}
+
+// ParseTargets returns DefaultTarget on base followed by the targets spec declares, in declaration order
+// ParseTargets: base上のDefaultTargetと、続けてspecが宣言するターゲットを宣言順に返す関数
+// spec is MIGRATION_TARGETS: comma-separated entries of name, name=dir or name=dir:table. A bare name reads
+// <base>/<name> and records in <name>_schema_migrations
+// specはMIGRATION_TARGETS、name、name=dir、name=dir:tableのいずれかをカンマで区切る。名前のみの場合は
+// <base>/<name>を読み、<name>_schema_migrationsに記録する
+func ParseTargets(spec string, base Source) ([]Target, error) {
+	targets := []Target{{Name: DefaultTarget, Source: base, MigrationsTable: DefaultMigrationsTable}}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, location, _ := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		dir, table, _ := strings.Cut(location, ":")
+		dir, table = strings.TrimSpace(dir), strings.TrimSpace(table)
+
+		switch {
+		case !targetNamePattern.MatchString(name):
+			return nil, fmt.Errorf("invalid MIGRATION_TARGETS entry %q: a target name is lower case letters, digits and _", entry)
+		case name == DefaultTarget:
+			return nil, fmt.Errorf("invalid MIGRATION_TARGETS entry %q: %s is the --migrations-path target", entry, DefaultTarget)
+		}
+		for _, declared := range targets {
+			if declared.Name == name {
+				return nil, fmt.Errorf("invalid MIGRATION_TARGETS: target %s is declared twice", name)
+			}
+		}
+
+		if dir == "" {
+			if base.Path == "" {
+				return nil, fmt.Errorf("target %s needs a directory: declare %s=dir in MIGRATION_TARGETS, or set --migrations-path to read %s under it", name, name, name)
+			}
+			dir = filepath.Join(base.Path, name)
+		}
+		if table == "" {
+			table = name + "_" + DefaultMigrationsTable
+		}
+		targets = append(targets, Target{Name: name, Source: Directory(dir), MigrationsTable: table})
+	}
+	return targets, nil
+}
+
+// SelectTargets picks the target called name, DefaultTarget when name is empty, or every target with all
+// SelectTargets: nameという名前のターゲット、nameが空の場合はDefaultTarget、allの場合は全てのターゲットを選ぶ関数
+// The directories of the targets picked must exist: 選んだターゲットのディレクトリは存在しなければならない
+func SelectTargets(targets []Target, name string, all bool) ([]Target, error) {
+	var selected []Target
+	switch {
+	case all && name != "":
+		return nil, errors.New("pass either --target or --all, not both")
+	case all:
+		selected = targets
+	default:
+		if name == "" {
+			name = DefaultTarget
+		}
+		for _, target := range targets {
+			if target.Name == name {
+				selected = []Target{target}
+			}
+		}
+		if selected == nil {
+			return nil, fmt.Errorf("unknown target %q: the targets are %s; declare others in MIGRATION_TARGETS", name, strings.Join(targetNames(targets), ", "))
+		}
+	}
+	for _, target := range selected {
+		if target.Source.Path == "" {
+			continue
+		}
+		if err := checkDirectory(target.Source.Path); err != nil {
+			return nil, fmt.Errorf("target %s: %w", target.Name, err)
+		}
+	}
+	return selected, nil
+}
+
+// targetNames lists the names of targets in order: targetsの名前を順に列挙する関数
+func targetNames(targets []Target) []string {
+	names := make([]string, len(targets))
+	for i, target := range targets {
+		names[i] = target.Name
+	}
+	return names
+}
+
+// migrationsTable returns target's version table quoted for SQL, a schema-qualified name quoted part by part as
+// golang-migrate reads it
+// migrationsTable: targetのバージョンのテーブルをSQL用にクォートして返す関数、スキーマ修飾された名前はgolang-migrateと同じく部分ごとにクォートする
+func (target Database) migrationsTable() string {
+	name := target.MigrationsTable
+	if name == "" {
+		name = DefaultMigrationsTable
+	}
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = pq.QuoteIdentifier(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// ReadVersion reads the version and dirty flag recorded in target's version table without creating it; a table not
+// created yet reports version 0
+// ReadVersion: targetのバージョンのテーブルを作成せずに、記録されたバージョンとdirtyフラグを読む関数、テーブルがまだ作成されていなければバージョン0
+func ReadVersion(ctx context.Context, target Database) (version uint, dirty bool, err error) {
+	var exists bool
+	if err := target.DB.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", target.migrationsTable()).Scan(&exists); err != nil {
+		return 0, false, fmt.Errorf("failed to look up the migrations table: %w", err)
+	}
+	if !exists {
+		return 0, false, nil
+	}
+	var current int64
+	err = target.DB.QueryRowContext(ctx, "SELECT version, dirty FROM "+target.migrationsTable()+" LIMIT 1").Scan(&current, &dirty)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return 0, false, nil
+	case err != nil:
+		return 0, false, fmt.Errorf("failed to read migration version: %w", err)
+	case current < 0:
+		return 0, false, nil
+	}
+	return uint(current), dirty, nil
+}
+
+// TargetResult is where one target stood before and after UpTargets
+// TargetResult: UpTargetsの前後の1つのターゲットのバージョン
+type TargetResult struct {
+	Target string `json:"target"` // target: ターゲット名
+	From   uint   `json:"from"`   // from: 適用前のバージョン
+	To     uint   `json:"to"`     // to: 適用後のバージョン
+}
+
+// UpTargets runs Up on each target in order over base's pool, stopping at the first that fails
+// UpTargets: baseの接続プールで各ターゲットに順にUpを実行する関数、最初に失敗したところで止まる
+// The results cover the targets that ran, the failed one last; the error names it and the targets left unrun
+// 結果は実行したターゲットを含み、失敗したものが最後になる。エラーはそのターゲットと実行しなかったターゲットを示す
+func UpTargets(base Database, targets []Target, ignoreDrift bool) ([]TargetResult, error) {
+	results := []TargetResult{}
+	for i, target := range targets {
+		from, to, err := Up(target.On(base), target.Source, ignoreDrift)
+		results = append(results, TargetResult{Target: target.Name, From: from, To: to})
+		if err != nil {
+			err = fmt.Errorf("target %s failed: %w", target.Name, err)
+			if rest := targets[i+1:]; len(rest) > 0 {
+				err = fmt.Errorf("%w; not run: %s", err, strings.Join(targetNames(rest), ", "))
+			}
+			return results, err
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,3 @@
+-- Fixture for the baseline tests: ベースラインのテスト用のフィクスチャ
+CREATE TABLE baseline_accounts (id BIGINT PRIMARY KEY);
+CREATE TABLE IF NOT EXISTS "Baseline_Audit" (id BIGINT PRIMARY KEY);
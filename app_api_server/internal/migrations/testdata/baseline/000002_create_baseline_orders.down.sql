@@ -0,0 +1 @@
+DROP TABLE IF EXISTS migrate_baseline_test.baseline_orders;
@@ -0,0 +1,4 @@
+-- Fixture for the baseline tests: ベースラインのテスト用のフィクスチャ
+-- CREATE TABLE baseline_commented is only a comment: CREATE TABLE baseline_commentedはコメントにすぎない
+CREATE UNLOGGED TABLE migrate_baseline_test.Baseline_Orders (id BIGINT PRIMARY KEY);
+DROP TABLE IF EXISTS "Baseline_Audit";
@@ -0,0 +1,2 @@
+-- Fixture for the baseline tests: ベースラインのテスト用のフィクスチャ
+CREATE TABLE baseline_invoices (id BIGINT PRIMARY KEY);
@@ -0,0 +1 @@
+CREATE TABLE sessions (id SERIAL PRIMARY KEY);
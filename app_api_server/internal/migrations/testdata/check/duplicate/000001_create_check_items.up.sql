@@ -0,0 +1 @@
+CREATE TABLE check_items (id BIGSERIAL PRIMARY KEY);
@@ -0,0 +1 @@
+CREATE TABLE check_entries (id BIGSERIAL PRIMARY KEY);
@@ -0,0 +1 @@
+ALTER TABLE check_items DROP COLUMN note;
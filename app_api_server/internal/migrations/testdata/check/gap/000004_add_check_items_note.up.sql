@@ -0,0 +1 @@
+ALTER TABLE check_items ADD COLUMN note TEXT;
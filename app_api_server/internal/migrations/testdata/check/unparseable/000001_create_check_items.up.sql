@@ -0,0 +1,4 @@
+CREATE TABLE check_items (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL DEFAULT 'unnamed
+);
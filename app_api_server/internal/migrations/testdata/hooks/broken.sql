@@ -0,0 +1,2 @@
+-- Fixture hook that fails, the table does not exist: 失敗するフックのフィクスチャ、テーブルが存在しない
+INSERT INTO missing_hook_markers (phase) VALUES ('pre');
@@ -0,0 +1,2 @@
+-- Fixture post-hook for the hook tests: フックのテスト用のポストフックのフィクスチャ
+INSERT INTO hook_markers (phase) VALUES ('post');
@@ -0,0 +1,2 @@
+-- Fixture pre-hook for the hook tests: フックのテスト用のプレフックのフィクスチャ
+INSERT INTO hook_markers (phase) VALUES ('pre');
@@ -0,0 +1,2 @@
+-- Fixture seed for the seed tests: シードのテスト用のフィクスチャ
+INSERT INTO seed_fixture (name) VALUES ('green'), ('blue') ON CONFLICT (name) DO NOTHING;
@@ -0,0 +1,3 @@
+-- Fixture seed that fails, so the one before it must be rolled back
+-- 失敗するフィクスチャのシード、前のシードはロールバックされなければならない
+INSERT INTO seed_fixture_missing (name) VALUES ('blue');
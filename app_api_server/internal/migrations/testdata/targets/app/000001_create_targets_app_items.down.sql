@@ -0,0 +1 @@
+DROP TABLE IF EXISTS targets_app_items;
@@ -0,0 +1,2 @@
+-- Fixture for the targets integration test: ターゲットの統合テスト用のフィクスチャ
+CREATE TABLE targets_app_items (id BIGINT PRIMARY KEY);
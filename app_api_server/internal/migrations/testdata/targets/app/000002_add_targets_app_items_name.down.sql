@@ -0,0 +1 @@
+ALTER TABLE targets_app_items DROP COLUMN name;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS targets_audit_events;
@@ -0,0 +1,2 @@
+-- Fixture for the targets integration test, versioned apart from app: appとは別にバージョン管理するターゲットの統合テスト用のフィクスチャ
+CREATE TABLE targets_audit_events (id BIGINT PRIMARY KEY);
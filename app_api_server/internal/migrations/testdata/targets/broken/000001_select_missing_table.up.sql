@@ -0,0 +1,3 @@
+-- Fixture for the targets integration test; it fails, so the targets after it do not run
+-- ターゲットの統合テスト用のフィクスチャ、失敗するため後のターゲットは実行されない
+SELECT id FROM targets_missing_table;
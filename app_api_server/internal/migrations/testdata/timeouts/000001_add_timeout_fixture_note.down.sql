@@ -0,0 +1 @@
+ALTER TABLE migrate_timeout_fixture DROP COLUMN note;
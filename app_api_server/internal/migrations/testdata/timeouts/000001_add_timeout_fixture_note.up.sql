@@ -0,0 +1,3 @@
+-- Fixture for the timeout integration test; the test holds a lock on the table
+-- タイムアウトの統合テスト用のフィクスチャ、テストはテーブルのロックを保持する
+ALTER TABLE migrate_timeout_fixture ADD COLUMN note TEXT;
@@ -0,0 +1,5 @@
+-- Fixture for the migrate up integration test
+CREATE TABLE migrate_up_fixture (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL
+);
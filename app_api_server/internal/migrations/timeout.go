@@ -0,0 +1,155 @@
+package migrations
+
+import (
+	"context"             // context: コンテキスト
+	"database/sql"        // sql: 設定を適用する接続
+	"database/sql/driver" // driver: 戻せなかった接続の破棄を指示するErrBadConn
+	"errors"              // errors: エラー操作
+	"fmt"                 // fmt: format（フォーマット）、文字列フォーマット機能
+	"os"                  // os: operating system（オペレーティングシステム）、環境変数
+	"strings"             // strings: 文字列操作
+	"time"                // time: 時間操作機能
+
+	migratedb "github.com/golang-migrate/migrate/v4/database" // migratedb: golang-migrateのデータベースのエラー型
+	"github.com/lib/pq"                                       // pq: PostgreSQLのエラー型
+)
+
+// DefaultLockTimeout is how long a migration statement waits for a table lock before failing, unless configured
+// DefaultLockTimeout: 設定されない場合に、マイグレーションの文が失敗するまでテーブルのロックを待つ時間
+// A statement queued behind a lock blocks every later query on the table, so it is better to fail fast and retry
+// ロック待ちの文はテーブルへの後続の全てのクエリを待たせるため、早く失敗して再試行する方がよい
+const DefaultLockTimeout = 30 * time.Second
+
+// Flag usages shared by the commands that run migrations
+// マイグレーションを実行するコマンドで共有するフラグの説明
+const (
+	LockTimeoutUsage      = "how long a migration waits for a lock before failing (default $MIGRATE_LOCK_TIMEOUT, then 30s; 0 leaves the server setting)"
+	StatementTimeoutUsage = "how long a migration statement may run (default $MIGRATE_STATEMENT_TIMEOUT, then 0: the server setting)"
+)
+
+// SQLSTATE codes of a cancelled migration statement
+// キャンセルされたマイグレーションの文のSQLSTATEコード
+const (
+	sqlStateLockNotAvailable = "55P03" // lock_not_available: lock_timeoutによるキャンセル
+	sqlStateQueryCanceled    = "57014" // query_canceled: statement_timeoutによるキャンセル
+)
+
+// Timeouts are the lock_timeout and statement_timeout set on the connection a migration runs on
+// Timeouts: マイグレーションを実行する接続に設定するlock_timeoutとstatement_timeout
+type Timeouts struct {
+	Lock      time.Duration // lock: lock_timeout、0の場合はサーバーの設定のまま
+	Statement time.Duration // statement: statement_timeout、0の場合はサーバーの設定のまま
+}
+
+// LoadTimeouts reads MIGRATE_LOCK_TIMEOUT and MIGRATE_STATEMENT_TIMEOUT, defaulting to DefaultLockTimeout and no statement limit
+// LoadTimeouts: MIGRATE_LOCK_TIMEOUTとMIGRATE_STATEMENT_TIMEOUTを読み込む関数、デフォルトはDefaultLockTimeoutと文の制限なし
+func LoadTimeouts() (Timeouts, error) {
+	timeouts := Timeouts{Lock: DefaultLockTimeout}
+	var err error
+	if timeouts.Lock, err = envDuration("MIGRATE_LOCK_TIMEOUT", timeouts.Lock); err != nil {
+		return Timeouts{}, err
+	}
+	if timeouts.Statement, err = envDuration("MIGRATE_STATEMENT_TIMEOUT", timeouts.Statement); err != nil {
+		return Timeouts{}, err
+	}
+	return timeouts, nil
+}
+
+// envDuration parses the duration variable key, returning fallback when it is unset
+// envDuration: 時間の環境変数keyを解析する関数、未設定の場合はfallbackを返す
+func envDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s: %q is not a non-negative duration such as 30s", key, value)
+	}
+	return d, nil
+}
+
+// apply sets each non-zero timeout on conn before golang-migrate runs on it; 0 leaves the server setting
+// apply: golang-migrateが実行する前に0でない各タイムアウトをconnに設定する関数、0の場合はサーバーの設定のまま
+func (t Timeouts) apply(ctx context.Context, conn *sql.Conn) error {
+	settings := []struct {
+		name  string
+		value time.Duration
+	}{{"lock_timeout", t.Lock}, {"statement_timeout", t.Statement}}
+	for _, setting := range settings {
+		if setting.value <= 0 {
+			continue
+		}
+		// Rounded up, so a sub-millisecond timeout is not sent as 0 (disabled)
+		// 1ミリ秒未満のタイムアウトが0（無効）として送られないよう切り上げる
+		milliseconds := (setting.value + time.Millisecond - 1) / time.Millisecond
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("SET %s = %d", setting.name, milliseconds)); err != nil {
+			return fmt.Errorf("failed to set %s for the migrations: %w", setting.name, err)
+		}
+	}
+	return nil
+}
+
+// reset restores the server setting of each timeout apply set on conn, so the connection goes back to the pool clean
+// reset: applyがconnに設定した各タイムアウトをサーバーの設定に戻す関数、接続はそのままの状態でプールに戻る
+func (t Timeouts) reset(ctx context.Context, conn *sql.Conn) error {
+	settings := []struct {
+		name  string
+		value time.Duration
+	}{{"lock_timeout", t.Lock}, {"statement_timeout", t.Statement}}
+	for _, setting := range settings {
+		if setting.value <= 0 {
+			continue
+		}
+		if _, err := conn.ExecContext(ctx, "RESET "+setting.name); err != nil {
+			return fmt.Errorf("failed to reset %s after the migrations: %w", setting.name, err)
+		}
+	}
+	return nil
+}
+
+// timeoutConn is golang-migrate's database driver for a connection with timeouts applied; Close resets them first
+// timeoutConn: タイムアウトを設定した接続に対するgolang-migrateのデータベースドライバー、Closeは先にタイムアウトを戻す
+type timeoutConn struct {
+	migratedb.Driver
+	conn     *sql.Conn // conn: タイムアウトを設定した接続
+	timeouts Timeouts  // timeouts: 設定したタイムアウト
+}
+
+// Close resets the timeouts and closes the driver, returning the connection to the pool
+// Close: タイムアウトを戻してドライバーを閉じ、接続をプールに返却する関数
+// A connection whose reset failed is discarded rather than returned with the timeouts still set
+// 戻せなかった接続は、タイムアウトが残ったまま返却せずに破棄する
+func (c timeoutConn) Close() error {
+	if err := c.timeouts.reset(context.Background(), c.conn); err != nil {
+		c.conn.Raw(func(any) error { return driver.ErrBadConn })
+	}
+	return c.Driver.Close()
+}
+
+// explain adds the timeout that cancelled a migration statement to err, with a hint to retry
+// explain: マイグレーションの文をキャンセルしたタイムアウトを再試行の案内と共にerrに加える関数
+// golang-migrate reports the PostgreSQL error as a database.Error that does not unwrap, so OrigErr is read directly
+// golang-migrateはPostgreSQLのエラーを展開できないdatabase.Errorとして報告するため、OrigErrを直接読む
+func (t Timeouts) explain(err error) error {
+	cause := err
+	var migrateErr migratedb.Error
+	var migrateErrPtr *migratedb.Error
+	switch {
+	case errors.As(err, &migrateErr):
+		cause = migrateErr.OrigErr
+	case errors.As(err, &migrateErrPtr):
+		cause = migrateErrPtr.OrigErr
+	}
+	var pqErr *pq.Error
+	if !errors.As(cause, &pqErr) {
+		return err
+	}
+	switch {
+	case pqErr.Code == sqlStateLockNotAvailable && t.Lock > 0:
+		return fmt.Errorf("%w; blocked for %s waiting for a lock another session holds: retry when the table is quieter, or raise --lock-timeout", err, t.Lock)
+	case pqErr.Code == sqlStateQueryCanceled && t.Statement > 0:
+		return fmt.Errorf("%w; the statement ran past the %s statement timeout: retry, or raise --statement-timeout", err, t.Statement)
+	}
+	return err
+}
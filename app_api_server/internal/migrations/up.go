@@ -0,0 +1,71 @@
+package migrations
+
+import (
+	"context" // context: コンテキスト
+	"errors"  // errors: エラー操作
+	"fmt"     // fmt: format（フォーマット）、文字列フォーマット機能
+
+	"github.com/golang-migrate/migrate/v4" // migrate: マイグレーション機能
+)
+
+// Up applies every pending migration in source to target
+// Up: sourceの未適用のマイグレーションを全てtargetに適用する関数
+// It returns the version before and after; migrate.ErrNoChange counts as success with both equal
+// 適用前後のバージョンを返す、migrate.ErrNoChangeは両者が等しい成功として扱う
+// An applied migration modified since it ran stops Up with ErrChecksumDrift unless ignoreDrift is set;
+// the checksum of each migration applied is recorded
+// 実行後に変更された適用済みのマイグレーションがあれば、ignoreDriftが指定されない限りErrChecksumDriftで中止する。
+// 適用した各マイグレーションのチェックサムを記録する
+func Up(target Database, source Source, ignoreDrift bool) (before, after uint, err error) {
+	list, err := Load(source)
+	if err != nil {
+		return 0, 0, err
+	}
+	m, err := Open(target, source)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer m.Close()
+
+	before, _, err = CurrentVersion(m)
+	if err != nil {
+		return 0, 0, err
+	}
+	ctx := context.Background()
+	if !ignoreDrift {
+		if err := refuseDrift(ctx, target, list, before); err != nil {
+			return before, before, err
+		}
+	}
+
+	err = target.Timeouts.explain(m.Up())
+	if errors.Is(err, migrate.ErrNoChange) {
+		return before, before, nil
+	}
+	if err != nil {
+		var dirty migrate.ErrDirty
+		if errors.As(err, &dirty) {
+			return before, before, fmt.Errorf("migration %d failed in an earlier run and left the database dirty; fix the schema and force the version: %w", dirty.Version, err)
+		}
+		// A failed migration leaves the database dirty at its version; the ones before it were applied
+		// 失敗したマイグレーションはそのバージョンでデータベースをdirtyのままにする、それより前のものは適用済み
+		// Their checksums are still recorded, or the next run would report them as drift
+		// 次の実行でドリフトとして報告されないよう、それらのチェックサムは記録する
+		if failed, isDirty, versionErr := CurrentVersion(m); versionErr == nil && isDirty {
+			if recordErr := recordChecksums(ctx, target, between(list, before, failed-1), migrationTimings(m)); recordErr != nil {
+				return before, failed, fmt.Errorf("migration %d failed: %w (recording the checksums of the earlier migrations also failed: %v)", failed, err, recordErr)
+			}
+			return before, failed, fmt.Errorf("migration %d failed: %w", failed, err)
+		}
+		return before, before, fmt.Errorf("migrating up from version %d failed: %w", before, err)
+	}
+
+	after, _, err = CurrentVersion(m)
+	if err != nil {
+		return before, 0, err
+	}
+	if err := recordChecksums(ctx, target, between(list, before, after), migrationTimings(m)); err != nil {
+		return before, after, err
+	}
+	return before, after, nil
+}
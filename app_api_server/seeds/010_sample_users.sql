@@ -0,0 +1,12 @@
+-- Sample users for local development; every one signs in with the password sift-dev-password
+-- ローカル開発用のサンプルユーザー、全員のパスワードはsift-dev-password
+-- The admin user comes from ADMIN_EMAIL and ADMIN_PASSWORD instead (see sift-migrate seed -h)
+-- 管理者はこのファイルではなくADMIN_EMAILとADMIN_PASSWORDから作成する（sift-migrate seed -hを参照）
+-- ON CONFLICT keeps the file safe to run again: ON CONFLICTにより再実行しても安全
+INSERT INTO app.users (email, password_hash, first_name, last_name, is_active, is_verified)
+VALUES
+    ('alice@example.com', '$2a$10$h6nmN33MXGpAmAbcT3LrguX4PvaKbgGXM9B7/7h7X5o793p4Biuxm', 'Alice', 'Sample', TRUE, TRUE),
+    ('bob@example.com', '$2a$10$h6nmN33MXGpAmAbcT3LrguX4PvaKbgGXM9B7/7h7X5o793p4Biuxm', 'Bob', 'Sample', TRUE, TRUE),
+    ('carol@example.com', '$2a$10$h6nmN33MXGpAmAbcT3LrguX4PvaKbgGXM9B7/7h7X5o793p4Biuxm', 'Carol', 'Sample', TRUE, FALSE),
+    ('dave@example.com', '$2a$10$h6nmN33MXGpAmAbcT3LrguX4PvaKbgGXM9B7/7h7X5o793p4Biuxm', 'Dave', 'Sample', FALSE, TRUE)
+ON CONFLICT (email) DO NOTHING;